@@ -0,0 +1,165 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gm
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/utl"
+)
+
+// BlockedGrid partitions the nodes of a grid into rectangular blocks (subdomains) with halo layers;
+// e.g. to prepare distributed computations where each block is handled by one process.
+//
+//   Each block owns the nodes within a range of indices along each direction. The halo of a block
+//   holds the nodes owned by other blocks within the given width around the block, including the
+//   corner regions; e.g. for stencils with diagonal neighbours. The values at the halo nodes are
+//   copied from their owners by Exchange (in one process; i.e. a stand-in for message passing)
+//
+//   The local numbering of a block lists the owned nodes first, in the order of the grid, and then
+//   the halo nodes (see GridBlock)
+type BlockedGrid struct {
+	Grid   *Grid        // grid
+	Halo   int          // width of halo layers
+	Blocks []*GridBlock // blocks
+	owner  []int        // [nnodes] block owning each node
+	ownLoc []int        // [nnodes] local index of each node in its owner block
+}
+
+// GridBlock holds the data of one block of BlockedGrid
+type GridBlock struct {
+	Start  []int       // [3] first indices (m,n,p) of owned nodes
+	End    []int       // [3] one past the last indices (m,n,p) of owned nodes
+	Nodes  []int       // [nlocal] grid ids of local nodes: owned nodes followed by halo nodes
+	Nowned int         // number of owned nodes; i.e. Nodes[:Nowned]
+	G2L    map[int]int // maps grid id to local index
+}
+
+// NewBlockedGrid partitions a grid into blocks
+//   grid    -- grid
+//   nblocks -- [ndim] number of blocks along each direction; the nodes are split evenly
+//   halo    -- width of halo layers (≥ 0)
+func NewBlockedGrid(grid *Grid, nblocks []int, halo int) (o *BlockedGrid) {
+	ndim := grid.Ndim()
+	if len(nblocks) != ndim {
+		chk.Panic("len(nblocks) must be equal to ndim = %d. %d is invalid\n", ndim, len(nblocks))
+	}
+	if halo < 0 {
+		chk.Panic("width of halo layers must not be negative. %d is invalid\n", halo)
+	}
+	npts := []int{grid.Npts(0), grid.Npts(1), 1}
+	nb := []int{nblocks[0], nblocks[1], 1}
+	if ndim == 3 {
+		npts[2], nb[2] = grid.Npts(2), nblocks[2]
+	}
+	for dim := 0; dim < 3; dim++ {
+		if nb[dim] < 1 || nb[dim] > npts[dim] {
+			chk.Panic("number of blocks along direction %d must be in [1, %d]. %d is invalid\n", dim, npts[dim], nb[dim])
+		}
+	}
+	o = &BlockedGrid{Grid: grid, Halo: halo}
+	o.owner = make([]int, grid.Size())
+	o.ownLoc = make([]int, grid.Size())
+
+	// owned nodes
+	for c := 0; c < nb[2]; c++ {
+		for b := 0; b < nb[1]; b++ {
+			for a := 0; a < nb[0]; a++ {
+				blk := &GridBlock{Start: make([]int, 3), End: make([]int, 3), G2L: make(map[int]int)}
+				for dim, k := range []int{a, b, c} {
+					blk.Start[dim] = k * npts[dim] / nb[dim]
+					blk.End[dim] = (k + 1) * npts[dim] / nb[dim]
+				}
+				forEachNode(grid, blk.Start, blk.End, func(I int) {
+					o.owner[I], o.ownLoc[I] = len(o.Blocks), len(blk.Nodes)
+					blk.G2L[I] = len(blk.Nodes)
+					blk.Nodes = append(blk.Nodes, I)
+				})
+				blk.Nowned = len(blk.Nodes)
+				o.Blocks = append(o.Blocks, blk)
+			}
+		}
+	}
+
+	// halo nodes
+	for _, blk := range o.Blocks {
+		lo, hi := make([]int, 3), make([]int, 3)
+		for dim := 0; dim < 3; dim++ {
+			lo[dim], hi[dim] = blk.Start[dim], blk.End[dim]
+			if dim < ndim {
+				lo[dim], hi[dim] = utl.Imax(lo[dim]-halo, 0), utl.Imin(hi[dim]+halo, npts[dim])
+			}
+		}
+		forEachNode(grid, lo, hi, func(I int) {
+			if _, ok := blk.G2L[I]; !ok {
+				blk.G2L[I] = len(blk.Nodes)
+				blk.Nodes = append(blk.Nodes, I)
+			}
+		})
+	}
+	return
+}
+
+// Owner returns the block owning a node and the local index of the node in that block
+func (o *BlockedGrid) Owner(I int) (block, local int) {
+	return o.owner[I], o.ownLoc[I]
+}
+
+// Scatter copies the values at all nodes of the grid to the local arrays of the blocks (owned
+// and halo nodes)
+//   u     -- [nnodes] values at all nodes
+//   local -- [nblocks][nlocal] values at the local nodes of each block
+func (o *BlockedGrid) Scatter(u []float64) (local [][]float64) {
+	if len(u) != o.Grid.Size() {
+		chk.Panic("size of u must be equal to the number of nodes. %d != %d\n", len(u), o.Grid.Size())
+	}
+	local = make([][]float64, len(o.Blocks))
+	for b, blk := range o.Blocks {
+		local[b] = make([]float64, len(blk.Nodes))
+		for l, I := range blk.Nodes {
+			local[b][l] = u[I]
+		}
+	}
+	return
+}
+
+// Gather copies the values at the owned nodes of the blocks to an array with all nodes
+//   local -- [nblocks][nowned or nlocal] values at the local nodes of each block
+//   u     -- [nnodes] values at all nodes
+func (o *BlockedGrid) Gather(local [][]float64) (u []float64) {
+	u = make([]float64, o.Grid.Size())
+	for b, blk := range o.Blocks {
+		for l, I := range blk.Nodes[:blk.Nowned] {
+			u[I] = local[b][l]
+		}
+	}
+	return
+}
+
+// Exchange updates the halo values of all blocks with the values of their owners (halo exchange)
+//   local -- [nblocks][nlocal] values at the local nodes of each block
+func (o *BlockedGrid) Exchange(local [][]float64) {
+	for b, blk := range o.Blocks {
+		if len(local[b]) != len(blk.Nodes) {
+			chk.Panic("size of local array of block %d must be equal to %d. %d is invalid\n", b, len(blk.Nodes), len(local[b]))
+		}
+		for l := blk.Nowned; l < len(blk.Nodes); l++ {
+			I := blk.Nodes[l]
+			local[b][l] = local[o.owner[I]][o.ownLoc[I]]
+		}
+	}
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// forEachNode calls fcn for the nodes with indices in [lo, hi) in the order of the grid
+func forEachNode(grid *Grid, lo, hi []int, fcn func(I int)) {
+	for p := lo[2]; p < hi[2]; p++ {
+		for n := lo[1]; n < hi[1]; n++ {
+			for m := lo[0]; m < hi[0]; m++ {
+				fcn(grid.IndexMNPtoI(m, n, p))
+			}
+		}
+	}
+}
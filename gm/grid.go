@@ -7,6 +7,7 @@ package gm
 import (
 	"math"
 
+	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/la"
 	"github.com/cpmech/gosl/utl"
 )
@@ -35,6 +36,7 @@ type Grid struct {
 	xmax []float64      // max physical coordinates [3]
 	edge [][]int        // ids of points on edges: [edge0, edge1, edge2, edge3]
 	face [][]int        // ids of points on faces: [face0, face1, face2, face3, face4, face5]
+	rot  float64        // rotation angle (2D) of physical coordinates about xmin (see RectGenRotated)
 }
 
 // RectGenUniform generates uniform coordinates of a rectangular grid
@@ -51,6 +53,7 @@ func (o *Grid) RectGenUniform(xmin, xmax []float64, npts []int) {
 
 	// input
 	o.ndim = len(xmin)
+	o.rot = 0
 
 	// 2D grid
 	if o.ndim == 2 {
@@ -125,6 +128,101 @@ func (o *Grid) RectGenUniform(xmin, xmax []float64, npts []int) {
 	o.boundaries()
 }
 
+// RectGenRotated generates a uniform rectangular 2D grid rotated by θ about xmin; e.g. for
+// problems aligned to a tilted axis
+//
+//   {x} = {xmin} + [R(θ)] ⋅ ({xl} - {xmin})
+//
+//   where {xl} are the local (unrotated) coordinates of the grid generated by RectGenUniform.
+//   The logical (m,n) structure and the spacings are not modified; thus, the FDM stencils remain
+//   aligned with the grid lines
+//
+//  xmin -- [2] min x-y values in the local frame; also the centre of rotation
+//  xmax -- [2] max x-y values in the local frame
+//  npts -- [2] number of points along each direction
+//  θ    -- rotation angle (counter-clockwise, in radians)
+//
+//  NOTE: (1) Node, X, Meshgrid2d and the bases return rotated (physical) values; whereas Xmin,
+//            Xmax, Xlen and Coords refer to the local frame (see ToLocal and ToPhysical)
+//        (2) anisotropic coefficients (e.g. kx, ky) are given in the local frame
+func (o *Grid) RectGenRotated(xmin, xmax []float64, npts []int, θ float64) {
+	if len(xmin) != 2 {
+		chk.Panic("rotated grids are available in 2D only\n")
+	}
+	o.RectGenUniform(xmin, xmax, npts)
+	o.rot = θ
+	c, s := math.Cos(θ), math.Sin(θ)
+	rotate := func(v la.Vector) la.Vector { return []float64{c*v[0] - s*v[1], s*v[0] + c*v[1]} }
+	for n := 0; n < o.npts[1]; n++ {
+		for m := 0; m < o.npts[0]; m++ {
+			mtr := o.mtr[0][n][m]
+			mtr.X = o.ToPhysical(mtr.X)
+			mtr.CovG0, mtr.CovG1 = rotate(mtr.CovG0), rotate(mtr.CovG1)
+			mtr.CntG0, mtr.CntG1 = rotate(mtr.CntG0), rotate(mtr.CntG1)
+		}
+	}
+}
+
+// Rotation returns the rotation angle of the grid (see RectGenRotated); zero if not rotated
+func (o *Grid) Rotation() float64 {
+	return o.rot
+}
+
+// ToLocal converts physical coordinates to the local (unrotated) frame of the grid
+//   NOTE: a copy of x is returned if the grid is not rotated
+func (o *Grid) ToLocal(x la.Vector) (xl la.Vector) {
+	xl = x.GetCopy()
+	if o.rot != 0 {
+		c, s := math.Cos(o.rot), math.Sin(o.rot)
+		dx, dy := x[0]-o.xmin[0], x[1]-o.xmin[1]
+		xl[0] = o.xmin[0] + c*dx + s*dy
+		xl[1] = o.xmin[1] - s*dx + c*dy
+	}
+	return
+}
+
+// ToPhysical converts coordinates in the local (unrotated) frame of the grid to physical coordinates
+//   NOTE: a copy of xl is returned if the grid is not rotated
+func (o *Grid) ToPhysical(xl la.Vector) (x la.Vector) {
+	x = xl.GetCopy()
+	if o.rot != 0 {
+		c, s := math.Cos(o.rot), math.Sin(o.rot)
+		dx, dy := xl[0]-o.xmin[0], xl[1]-o.xmin[1]
+		x[0] = o.xmin[0] + c*dx - s*dy
+		x[1] = o.xmin[1] + s*dx + c*dy
+	}
+	return
+}
+
+// NewUniformGridBySize creates a uniform rectangular grid with spacing as close as possible to
+// the target spacing along each direction
+//
+//   ndiv[i] = max(1, round((xmax[i] - xmin[i]) / h[i]))   and   Δx[i] = (xmax[i] - xmin[i]) / ndiv[i]
+//
+//  xmin -- [ndim] min x-y-z values
+//  xmax -- [ndim] max x-y-z values
+//  h    -- [ndim] target spacing along each direction
+//  NOTE: the actual spacing is adjusted to fit the domain exactly; see Npts and Xlen
+func NewUniformGridBySize(xmin, xmax, h []float64) (o *Grid) {
+	ndim := len(xmin)
+	if ndim < 2 || ndim > 3 || len(xmax) != ndim || len(h) != ndim {
+		chk.Panic("xmin, xmax and h must have the same length (2 or 3)\n")
+	}
+	npts := make([]int, ndim)
+	for i := 0; i < ndim; i++ {
+		if h[i] <= 0 {
+			chk.Panic("target spacing must be positive. h[%d]=%g is invalid\n", i, h[i])
+		}
+		if xmax[i] <= xmin[i] {
+			chk.Panic("xmax must be greater than xmin. xmin[%d]=%g, xmax[%d]=%g is invalid\n", i, xmin[i], i, xmax[i])
+		}
+		npts[i] = utl.Imax(1, int(math.Floor((xmax[i]-xmin[i])/h[i]+0.5))) + 1
+	}
+	o = new(Grid)
+	o.RectGenUniform(xmin, xmax, npts)
+	return
+}
+
 // RectSet2d sets rectangular grid with given coordinates
 //
 //     -1 ≤ u ≤ +1
@@ -598,6 +696,19 @@ func (o *Grid) X(m, n, p int) la.Vector {
 	return o.mtr[p][n][m].X
 }
 
+// Coords returns the physical coordinates of the points along direction idim of a rectangular
+// grid; e.g. the (non-uniform) coordinates of stretched grids
+//   NOTE: the coordinates of rotated grids are given in the local frame (see RectGenRotated)
+func (o *Grid) Coords(idim int) (X la.Vector) {
+	X = la.NewVector(o.npts[idim])
+	for i := 0; i < o.npts[idim]; i++ {
+		idx := []int{0, 0, 0}
+		idx[idim] = i
+		X[i] = o.ToLocal(o.mtr[idx[2]][idx[1]][idx[0]].X)[idim]
+	}
+	return
+}
+
 // CovarBasis returns the [k] covariant basis g_{k} = d{x}/d{u[k]} [@ point m,n,p]
 func (o *Grid) CovarBasis(m, n, p, k int) la.Vector {
 	if k == 0 {
@@ -704,6 +815,76 @@ func (o *Grid) Node(I int) (x la.Vector) {
 	return o.mtr[p][n][m].X
 }
 
+// Spacings returns the distances from node I to its backward and forward neighbours along dim;
+// e.g. to build the three-point stencils of stretched grids
+//   NOTE: at the first (last) node along dim, hMinus (hPlus) is the distance to the only neighbour;
+//         i.e. the neighbour is mirrored as with the ghost nodes of natural boundaries
+func (o *Grid) Spacings(dim, I int) (hMinus, hPlus float64) {
+	if o.npts[dim] < 2 {
+		chk.Panic("Spacings requires at least 2 points along direction %d\n", dim)
+	}
+	m, n, p := o.IndexItoMNP(I)
+	dist := func(i int) float64 { // distance to node i along dim
+		idx := []int{m, n, p}
+		idx[dim] = i
+		x, y := o.mtr[p][n][m].X, o.mtr[idx[2]][idx[1]][idx[0]].X
+		sum := 0.0
+		for k := 0; k < len(x); k++ {
+			sum += (y[k] - x[k]) * (y[k] - x[k])
+		}
+		return math.Sqrt(sum)
+	}
+	i := []int{m, n, p}[dim]
+	if i > 0 {
+		hMinus = dist(i - 1)
+	}
+	if i < o.npts[dim]-1 {
+		hPlus = dist(i + 1)
+	}
+	if i == 0 {
+		hMinus = hPlus
+	}
+	if i == o.npts[dim]-1 {
+		hPlus = hMinus
+	}
+	return
+}
+
+// ControlVolume returns the area (2D) or volume (3D) of the control volume around node I; i.e. the
+// cell bounded by the mid-points between I and its neighbours (and the boundaries of the grid)
+//
+//   V[I] = J[I] ⋅ Π Δu[dim]     with     Δu[dim] = (u[i+1] - u[i-1]) / 2
+//
+//   where J = √det(g_ij) is the Jacobian of the mapping and Δu[dim] are the sizes of the control
+//   volume in the reference space; i.e. halved at the first and last nodes along dim. Thus, the
+//   volumes of rectangular (possibly stretched or rotated) grids are exact and the sum over all
+//   nodes is equal to the area (volume) of the domain
+//
+//   NOTE: the Jacobian at the node is used for curvilinear grids (e.g. SetTransfinite2d); i.e. the
+//         volumes are approximated by the midpoint rule in the reference space
+func (o *Grid) ControlVolume(I int) (vol float64) {
+	m, n, p := o.IndexItoMNP(I)
+	vol = math.Sqrt(o.mtr[p][n][m].DetCovGmat)
+	idx := []int{m, n, p}
+	for dim := 0; dim < o.ndim; dim++ {
+		i := idx[dim]
+		u := func(j int) float64 {
+			jdx := []int{m, n, p}
+			jdx[dim] = j
+			return o.mtr[jdx[2]][jdx[1]][jdx[0]].U[dim]
+		}
+		du := 0.0
+		if i > 0 {
+			du += (u(i) - u(i-1)) / 2.0
+		}
+		if i < o.npts[dim]-1 {
+			du += (u(i+1) - u(i)) / 2.0
+		}
+		vol *= du
+	}
+	return
+}
+
 // MapMeshgrid2d maps vector V into 2D meshgrid using node indices conversion IndexMNPtoI()
 //  vv[ny][nx] -- mapped values: vv[n][m] ⇐ V[I] (see also Meshgrid2d)
 func (o *Grid) MapMeshgrid2d(v la.Vector) (V [][]float64) {
@@ -863,6 +1044,58 @@ func (o *Grid) Boundary(tag int) []int {
 	return o.EdgeGivenTag(tag)
 }
 
+// NodeKind defines the classification of nodes of a grid according to the boundaries they touch
+type NodeKind int
+
+const (
+
+	// InteriorNode defines nodes not on the boundary
+	InteriorNode NodeKind = iota
+
+	// FaceNode defines nodes on one face (3D only)
+	FaceNode
+
+	// EdgeNode defines nodes on one edge (2D) or on the intersection of two faces (3D)
+	EdgeNode
+
+	// CornerNode defines nodes on the intersection of two edges (2D) or three faces (3D)
+	CornerNode
+)
+
+// ClassifyNode returns the classification of a node and the tags of the boundaries (edges in 2D
+// or faces in 3D) it touches
+//   I -- node index; see IndexMNPtoI()
+//   Output:
+//     kind -- InteriorNode, FaceNode, EdgeNode or CornerNode
+//     tags -- tags of edges or faces touched by the node sorted in ascending order; see
+//             EdgeGivenTag() and FaceGivenTag() [nil for interior nodes]
+//   NOTE: the corners of a 2D grid touch two edges; e.g. node 0 touches tags 10 and 20
+func (o *Grid) ClassifyNode(I int) (kind NodeKind, tags []int) {
+	if I < 0 || I >= o.Size() {
+		chk.Panic("node %d is out of range [0, %d)\n", I, o.Size())
+	}
+	m, n, p := o.IndexItoMNP(I)
+	idx := []int{m, n, p}
+	base := 10 // first tag along x in 2D
+	if o.ndim == 3 {
+		base = 100
+	}
+	for i := 0; i < o.ndim; i++ {
+		tag := base * (i + 1)
+		switch idx[i] {
+		case 0:
+			tags = append(tags, tag)
+		case o.npts[i] - 1:
+			tags = append(tags, tag+1)
+		}
+	}
+	kind = NodeKind(len(tags))
+	if o.ndim == 2 && kind > InteriorNode {
+		kind++ // edge ⇒ EdgeNode; two edges ⇒ CornerNode
+	}
+	return
+}
+
 // UnitNormal computes the unit normal vector at an edge or face defined by "tag" and
 // at a node specified by index "I".
 //
@@ -948,6 +1181,7 @@ func (o *Grid) UnitNormal(N la.Vector, tag, I int) {
 // auxiliary ///////////////////////////////////////////////////////////////////////////////////////
 
 func (o *Grid) limits() {
+	o.rot = 0
 	o.umin = []float64{+math.MaxFloat64, +math.MaxFloat64, +math.MaxFloat64}
 	o.umax = []float64{-math.MaxFloat64, -math.MaxFloat64, -math.MaxFloat64}
 	o.xmin = []float64{+math.MaxFloat64, +math.MaxFloat64, +math.MaxFloat64}
@@ -0,0 +1,125 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gm
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// GridExchangeData holds the data of structured grids in files (json); e.g. grids generated by
+// external mesh generators
+//
+//   Example (2D grid with 3×2 points):
+//
+//     {"ndim":2, "npts":[3,2], "x":[0,0, 0.5,0, 2,0, 0,1, 0.5,1, 2,1]}
+//
+//   NOTE: the coordinates of node I = m + n⋅npts[0] + p⋅npts[0]⋅npts[1] are x[I⋅ndim:(I+1)⋅ndim]
+//         (see Grid.IndexMNPtoI)
+type GridExchangeData struct {
+	Ndim int       `json:"ndim"` // space dimension
+	Npts []int     `json:"npts"` // number of points along each direction [ndim]
+	X    []float64 `json:"x"`    // coordinates of all nodes [nnodes*ndim]
+}
+
+// WriteStructured writes the grid to a json file (see GridExchangeData and LoadStructuredGrid)
+func (o *Grid) WriteStructured(dirout, fnkey string) {
+	dat := GridExchangeData{Ndim: o.ndim, Npts: o.npts[:o.ndim]}
+	for I := 0; I < o.Size(); I++ {
+		dat.X = append(dat.X, o.Node(I)...)
+	}
+	b, err := json.Marshal(dat)
+	if err != nil {
+		chk.Panic("%v\n", err)
+	}
+	io.WriteBytesToFileVD(dirout, fnkey+".json", b)
+}
+
+// LoadStructuredGrid allocates a grid with the data from a json file (see GridExchangeData)
+//
+//   The coordinates must define a rectangular (possibly stretched) grid; i.e. the coordinates
+//   along each direction are the same on all lines of nodes. The grid is then set with RectSet2d
+//   or RectSet3d
+//
+//   NOTE: curvilinear grids are not supported (the metrics would require the derivatives of the
+//         mapping); use SetTransfinite2d or SetNurbsSurf2d instead
+func LoadStructuredGrid(filename string) (o *Grid) {
+
+	// read data
+	b := io.ReadFile(filename)
+	var dat GridExchangeData
+	err := json.Unmarshal(b, &dat)
+	if err != nil {
+		chk.Panic("cannot parse grid file %q: %v\n", filename, err)
+	}
+
+	// check
+	if dat.Ndim != 2 && dat.Ndim != 3 {
+		chk.Panic("space dimension must be 2 or 3. ndim = %d is invalid\n", dat.Ndim)
+	}
+	if len(dat.Npts) != dat.Ndim {
+		chk.Panic("number of entries in npts must be equal to ndim. %d != %d\n", len(dat.Npts), dat.Ndim)
+	}
+	nnodes := 1
+	for _, n := range dat.Npts {
+		if n < 2 {
+			chk.Panic("number of points along each direction must be at least 2. npts = %v is invalid\n", dat.Npts)
+		}
+		nnodes *= n
+	}
+	if len(dat.X) != nnodes*dat.Ndim {
+		chk.Panic("number of coordinates must be equal to nnodes*ndim = %d. %d is invalid\n", nnodes*dat.Ndim, len(dat.X))
+	}
+
+	// coordinates along each direction
+	npts := []int{dat.Npts[0], dat.Npts[1], 1}
+	if dat.Ndim == 3 {
+		npts[2] = dat.Npts[2]
+	}
+	coord := func(m, n, p, dim int) float64 {
+		I := m + n*npts[0] + p*npts[0]*npts[1]
+		return dat.X[I*dat.Ndim+dim]
+	}
+	X := make([][]float64, dat.Ndim)
+	for dim := 0; dim < dat.Ndim; dim++ {
+		X[dim] = make([]float64, npts[dim])
+		for i := 0; i < npts[dim]; i++ {
+			idx := []int{0, 0, 0}
+			idx[dim] = i
+			X[dim][i] = coord(idx[0], idx[1], idx[2], dim)
+			if i > 0 && X[dim][i] <= X[dim][i-1] {
+				chk.Panic("coordinates along direction %d in file %q must be strictly increasing: x[%d] = %g ≤ x[%d] = %g\n", dim, filename, i, X[dim][i], i-1, X[dim][i-1])
+			}
+		}
+	}
+
+	// check that the grid is rectangular
+	for p := 0; p < npts[2]; p++ {
+		for n := 0; n < npts[1]; n++ {
+			for m := 0; m < npts[0]; m++ {
+				idx := []int{m, n, p}
+				for dim := 0; dim < dat.Ndim; dim++ {
+					x := X[dim][idx[dim]]
+					tol := 1e-12 * (1 + math.Abs(X[dim][npts[dim]-1]-X[dim][0]))
+					if math.Abs(coord(m, n, p, dim)-x) > tol {
+						chk.Panic("grid in file %q is not rectangular: x[%d] = %g != %g at node (%d,%d,%d)\n", filename, dim, coord(m, n, p, dim), x, m, n, p)
+					}
+				}
+			}
+		}
+	}
+
+	// grid
+	o = new(Grid)
+	if dat.Ndim == 2 {
+		o.RectSet2d(X[0], X[1])
+	} else {
+		o.RectSet3d(X[0], X[1], X[2])
+	}
+	return
+}
@@ -0,0 +1,129 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gm
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+)
+
+// StaggeredGrid implements the layout of a uniform 2D staggered (MAC) grid on a rectangle
+//
+//   Scalar quantities (e.g. pressure) live at the centres of the nx × ny cells; the x-component
+//   of vector quantities (e.g. velocity u) lives at the (nx+1) × ny vertical faces and the
+//   y-component (e.g. v) lives at the nx × (ny+1) horizontal faces:
+//
+//      +---v---+---v---+
+//      |       |       |
+//      u   p   u   p   u      cell (i,j) has u-faces (i,j) and (i+1,j) on the left and right
+//      |       |       |      and v-faces (i,j) and (i,j+1) at the bottom and top
+//      +---v---+---v---+
+//
+//   The x-index runs faster in all numberings; e.g. the cell (i,j) has index i + j⋅nx
+type StaggeredGrid struct {
+	xmin []float64 // [2] min coordinates
+	xmax []float64 // [2] max coordinates
+	nx   int       // number of cells along x
+	ny   int       // number of cells along y
+	dx   float64   // cell size along x
+	dy   float64   // cell size along y
+}
+
+// NewStaggeredGrid creates a new uniform 2D staggered grid
+//  xmin -- [2] min x-y values
+//  xmax -- [2] max x-y values
+//  nx   -- number of cells along x
+//  ny   -- number of cells along y
+func NewStaggeredGrid(xmin, xmax []float64, nx, ny int) (o *StaggeredGrid) {
+	if len(xmin) != 2 || len(xmax) != 2 {
+		chk.Panic("staggered grid works in 2D only. len(xmin)=%d, len(xmax)=%d\n", len(xmin), len(xmax))
+	}
+	if nx < 1 || ny < 1 {
+		chk.Panic("number of cells must be at least 1. nx=%d, ny=%d is invalid\n", nx, ny)
+	}
+	o = new(StaggeredGrid)
+	o.xmin = []float64{xmin[0], xmin[1]}
+	o.xmax = []float64{xmax[0], xmax[1]}
+	o.nx, o.ny = nx, ny
+	o.dx = (xmax[0] - xmin[0]) / float64(nx)
+	o.dy = (xmax[1] - xmin[1]) / float64(ny)
+	return
+}
+
+// Nx returns the number of cells along x
+func (o *StaggeredGrid) Nx() int {
+	return o.nx
+}
+
+// Ny returns the number of cells along y
+func (o *StaggeredGrid) Ny() int {
+	return o.ny
+}
+
+// Dx returns the cell size along x
+func (o *StaggeredGrid) Dx() float64 {
+	return o.dx
+}
+
+// Dy returns the cell size along y
+func (o *StaggeredGrid) Dy() float64 {
+	return o.dy
+}
+
+// Ncells returns the number of cells (scalar unknowns)
+func (o *StaggeredGrid) Ncells() int {
+	return o.nx * o.ny
+}
+
+// NfacesX returns the number of vertical faces (x-components)
+func (o *StaggeredGrid) NfacesX() int {
+	return (o.nx + 1) * o.ny
+}
+
+// NfacesY returns the number of horizontal faces (y-components)
+func (o *StaggeredGrid) NfacesY() int {
+	return o.nx * (o.ny + 1)
+}
+
+// CellIndex returns the index of cell (i,j); 0 ≤ i < nx and 0 ≤ j < ny
+func (o *StaggeredGrid) CellIndex(i, j int) int {
+	return i + j*o.nx
+}
+
+// FaceXindex returns the index of vertical face (i,j); 0 ≤ i ≤ nx and 0 ≤ j < ny
+func (o *StaggeredGrid) FaceXindex(i, j int) int {
+	return i + j*(o.nx+1)
+}
+
+// FaceYindex returns the index of horizontal face (i,j); 0 ≤ i < nx and 0 ≤ j ≤ ny
+func (o *StaggeredGrid) FaceYindex(i, j int) int {
+	return i + j*o.nx
+}
+
+// CellCentre returns the coordinates of the centre of cell (i,j)
+func (o *StaggeredGrid) CellCentre(i, j int) la.Vector {
+	return []float64{o.xmin[0] + (float64(i)+0.5)*o.dx, o.xmin[1] + (float64(j)+0.5)*o.dy}
+}
+
+// FaceXcentre returns the coordinates of the centre of vertical face (i,j)
+func (o *StaggeredGrid) FaceXcentre(i, j int) la.Vector {
+	return []float64{o.xmin[0] + float64(i)*o.dx, o.xmin[1] + (float64(j)+0.5)*o.dy}
+}
+
+// FaceYcentre returns the coordinates of the centre of horizontal face (i,j)
+func (o *StaggeredGrid) FaceYcentre(i, j int) la.Vector {
+	return []float64{o.xmin[0] + (float64(i)+0.5)*o.dx, o.xmin[1] + float64(j)*o.dy}
+}
+
+// CellGrid returns a (node-based) grid with the nodes at the centres of cells; e.g. for plotting
+//  NOTE: there must be at least 2 cells along each direction
+func (o *StaggeredGrid) CellGrid() (g *Grid) {
+	if o.nx < 2 || o.ny < 2 {
+		chk.Panic("CellGrid requires at least 2 cells along each direction. nx=%d, ny=%d\n", o.nx, o.ny)
+	}
+	g = new(Grid)
+	g.RectGenUniform(o.CellCentre(0, 0), o.CellCentre(o.nx-1, o.ny-1), []int{o.nx, o.ny})
+	return
+}
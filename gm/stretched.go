@@ -0,0 +1,104 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gm
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+// StretchedCoords returns the coordinates of points clustered according to a preset
+//
+//   With s = i/(npts-1) ∈ [0, 1] and L = xmax - xmin:
+//
+//     "uniform"   -- x = xmin + L⋅s
+//     "geometric" -- the spacings grow by the ratio β: hᵢ₊₁ = β⋅hᵢ (clustering at xmin if β > 1)
+//     "log"       -- x = xmin⋅(xmax/xmin)ˢ; i.e. uniform in log(x); e.g. for radial problems
+//                    (clustering at xmin). Requires 0 < xmin < xmax; β is ignored
+//     "tanh"      -- x = xmin + L⋅(1 + tanh(β⋅(2s-1))/tanh(β))/2; i.e. symmetric clustering at
+//                    both ends with strength β > 0; e.g. for boundary layers
+//
+//   Input:
+//     preset -- name of preset (see above)
+//     xmin   -- min coordinate
+//     xmax   -- max coordinate
+//     npts   -- number of points (≥ 2)
+//     β      -- parameter of preset
+//   Output:
+//     X -- [npts] coordinates; X[0] = xmin and X[npts-1] = xmax
+func StretchedCoords(preset string, xmin, xmax float64, npts int, β float64) (X []float64) {
+	if npts < 2 {
+		chk.Panic("number of points must be at least 2. npts=%d is invalid\n", npts)
+	}
+	if xmax <= xmin {
+		chk.Panic("xmax must be greater than xmin. xmin=%g, xmax=%g is invalid\n", xmin, xmax)
+	}
+	L := xmax - xmin
+	X = make([]float64, npts)
+	for i := 0; i < npts; i++ {
+		s := float64(i) / float64(npts-1)
+		switch preset {
+		case "uniform":
+			X[i] = xmin + L*s
+		case "geometric":
+			if β <= 0 {
+				chk.Panic("ratio of geometric stretching must be positive. β=%g is invalid\n", β)
+			}
+			if β == 1 {
+				X[i] = xmin + L*s
+			} else {
+				X[i] = xmin + L*(math.Pow(β, float64(i))-1)/(math.Pow(β, float64(npts-1))-1)
+			}
+		case "log":
+			if xmin <= 0 {
+				chk.Panic("logarithmic stretching requires xmin > 0. xmin=%g is invalid\n", xmin)
+			}
+			X[i] = xmin * math.Pow(xmax/xmin, s)
+		case "tanh":
+			if β <= 0 {
+				chk.Panic("strength of tanh stretching must be positive. β=%g is invalid\n", β)
+			}
+			X[i] = xmin + L*(1+math.Tanh(β*(2*s-1))/math.Tanh(β))/2
+		default:
+			chk.Panic("stretching preset %q is invalid. options: \"uniform\", \"geometric\", \"log\" or \"tanh\"\n", preset)
+		}
+	}
+	X[0], X[npts-1] = xmin, xmax // exact ends
+	return
+}
+
+// NewStretchedGrid creates a rectangular grid with coordinates clustered according to presets
+// (see StretchedCoords); i.e. with fine resolution where needed
+//  xmin    -- [ndim] min x-y-z values
+//  xmax    -- [ndim] max x-y-z values
+//  npts    -- [ndim] number of points along each direction
+//  presets -- [ndim] stretching presets; e.g. {"tanh", "uniform"}
+//  β       -- [ndim] parameters of presets [may be nil if not needed]
+//  NOTE: the coordinates along each direction are available with Coords
+func NewStretchedGrid(xmin, xmax []float64, npts []int, presets []string, β []float64) (o *Grid) {
+	ndim := len(xmin)
+	if ndim < 2 || ndim > 3 || len(xmax) != ndim || len(npts) != ndim || len(presets) != ndim {
+		chk.Panic("xmin, xmax, npts and presets must have the same length (2 or 3)\n")
+	}
+	if β != nil && len(β) != ndim {
+		chk.Panic("len(β) must be equal to %d. %d is invalid\n", ndim, len(β))
+	}
+	coords := make([][]float64, ndim)
+	for i := 0; i < ndim; i++ {
+		b := 0.0
+		if β != nil {
+			b = β[i]
+		}
+		coords[i] = StretchedCoords(presets[i], xmin[i], xmax[i], npts[i], b)
+	}
+	o = new(Grid)
+	if ndim == 2 {
+		o.RectSet2d(coords[0], coords[1])
+		return
+	}
+	o.RectSet3d(coords[0], coords[1], coords[2])
+	return
+}
@@ -0,0 +1,64 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gm
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func TestBlockedGrid01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("BlockedGrid01. blocks and halo exchange")
+
+	// 5x4 grid split into 2x2 blocks
+	//
+	//   15  16  17 | 18  19
+	//   10  11  12 | 13  14
+	//   -----------+-------
+	//    5   6   7 |  8   9
+	//    0   1   2 |  3   4
+	//
+	g := new(Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{4, 3}, []int{5, 4})
+	bg := NewBlockedGrid(g, []int{2, 2}, 1)
+	chk.Int(tst, "nblocks", len(bg.Blocks), 4)
+	for b, blk := range bg.Blocks {
+		io.Pforan("block %d: owned = %v, halo = %v\n", b, blk.Nodes[:blk.Nowned], blk.Nodes[blk.Nowned:])
+	}
+	chk.Ints(tst, "owned 0", bg.Blocks[0].Nodes[:bg.Blocks[0].Nowned], []int{0, 1, 5, 6})
+	chk.Ints(tst, "halo 0", bg.Blocks[0].Nodes[bg.Blocks[0].Nowned:], []int{2, 7, 10, 11, 12})
+	chk.Ints(tst, "owned 3", bg.Blocks[3].Nodes[:bg.Blocks[3].Nowned], []int{12, 13, 14, 17, 18, 19})
+	chk.Ints(tst, "halo 3", bg.Blocks[3].Nodes[bg.Blocks[3].Nowned:], []int{6, 7, 8, 9, 11, 16})
+	for I := 0; I < g.Size(); I++ {
+		b, l := bg.Owner(I)
+		chk.Int(tst, io.Sf("node %d", I), bg.Blocks[b].Nodes[l], I)
+	}
+
+	// exchange
+	u := make([]float64, g.Size())
+	for I := range u {
+		u[I] = float64(I*I) + 0.5
+	}
+	correct := bg.Scatter(u)
+	local := bg.Scatter(u)
+	for b, blk := range bg.Blocks {
+		for l := blk.Nowned; l < len(blk.Nodes); l++ {
+			local[b][l] = -1
+		}
+	}
+	bg.Exchange(local)
+	for b := range bg.Blocks {
+		chk.Array(tst, io.Sf("local %d", b), 1e-17, local[b], correct[b])
+	}
+	chk.Array(tst, "gathered", 1e-17, bg.Gather(local), u)
+
+	// invalid number of blocks
+	defer chk.RecoverTstPanicIsOK(tst)
+	NewBlockedGrid(g, []int{6, 1}, 1)
+}
@@ -1213,3 +1213,214 @@ func TestGrid14(tst *testing.T) {
 		plt.Save("/tmp/gosl/gm", "grid14")
 	}
 }
+
+func TestGrid15(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Grid15. ClassifyNode")
+
+	// 2D
+	g := new(Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{3, 2}, []int{4, 3})
+	corners := map[int][]int{0: {10, 20}, 3: {11, 20}, 8: {10, 21}, 11: {11, 21}}
+	for I := 0; I < g.Size(); I++ {
+		kind, tags := g.ClassifyNode(I)
+		io.Pforan("node %2d: kind = %d, tags = %v\n", I, kind, tags)
+		if correct, ok := corners[I]; ok {
+			chk.Int(tst, io.Sf("kind @ %d", I), int(kind), int(CornerNode))
+			chk.Ints(tst, io.Sf("tags @ %d", I), tags, correct)
+			continue
+		}
+		switch I {
+		case 5, 6:
+			chk.Int(tst, io.Sf("kind @ %d", I), int(kind), int(InteriorNode))
+			chk.Ints(tst, io.Sf("tags @ %d", I), tags, nil)
+		default:
+			chk.Int(tst, io.Sf("kind @ %d", I), int(kind), int(EdgeNode))
+			chk.Int(tst, io.Sf("len(tags) @ %d", I), len(tags), 1)
+		}
+	}
+	_, tags := g.ClassifyNode(4)
+	chk.Ints(tst, "tags @ 4", tags, []int{10})
+	_, tags = g.ClassifyNode(10)
+	chk.Ints(tst, "tags @ 10", tags, []int{21})
+
+	// 3D
+	g.RectGenUniform([]float64{0, 0, 0}, []float64{1, 1, 1}, []int{3, 3, 3})
+	for _, c := range []struct {
+		m, n, p int
+		kind    NodeKind
+		tags    []int
+	}{
+		{1, 1, 1, InteriorNode, nil},
+		{1, 1, 0, FaceNode, []int{300}},
+		{2, 1, 0, EdgeNode, []int{101, 300}},
+		{2, 2, 0, CornerNode, []int{101, 201, 300}},
+		{0, 0, 2, CornerNode, []int{100, 200, 301}},
+	} {
+		I := g.IndexMNPtoI(c.m, c.n, c.p)
+		kind, tags := g.ClassifyNode(I)
+		chk.Int(tst, io.Sf("kind @ %d", I), int(kind), int(c.kind))
+		chk.Ints(tst, io.Sf("tags @ %d", I), tags, c.tags)
+	}
+
+	// node out of range
+	defer chk.RecoverTstPanicIsOK(tst)
+	g.ClassifyNode(27)
+}
+
+func TestGrid16(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Grid16. uniform grid by target spacing")
+
+	// 2D: ndiv = round(2/0.3) = 7 and round(1/0.26) = 4
+	g := NewUniformGridBySize([]float64{0, -1}, []float64{2, 0}, []float64{0.3, 0.26})
+	chk.Int(tst, "npts0", g.Npts(0), 8)
+	chk.Int(tst, "npts1", g.Npts(1), 5)
+	chk.Float64(tst, "xmax", 1e-15, g.Xmax(0), 2)
+	chk.Float64(tst, "ymin", 1e-15, g.Xmin(1), -1)
+	for dim, h := range []float64{0.3, 0.26} {
+		ndiv := g.Npts(dim) - 1
+		dx := g.Xlen(dim) / float64(ndiv)
+		io.Pforan("dim %d: ndiv = %d, Δx = %v\n", dim, ndiv, dx)
+		for _, n := range []int{ndiv - 1, ndiv + 1} { // neighbouring divisions are not closer to h
+			if math.Abs(g.Xlen(dim)/float64(n)-h) < math.Abs(dx-h) {
+				tst.Errorf("spacing with %d divisions is closer to h than with %d\n", n, ndiv)
+				return
+			}
+		}
+	}
+
+	// 3D with exact fit and h larger than the domain
+	g = NewUniformGridBySize([]float64{0, 0, 0}, []float64{1, 1, 0.1}, []float64{0.25, 0.1, 1})
+	chk.Ints(tst, "npts", []int{g.Npts(0), g.Npts(1), g.Npts(2)}, []int{5, 11, 2})
+	chk.Array(tst, "X", 1e-15, g.Coords(0), []float64{0, 0.25, 0.5, 0.75, 1})
+
+	// invalid spacing
+	defer chk.RecoverTstPanicIsOK(tst)
+	NewUniformGridBySize([]float64{0, 0}, []float64{1, 1}, []float64{0.1, 0})
+}
+
+func TestGrid17(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Grid17. rotated grid")
+
+	// square rotated by 90° about (1,0)
+	g := new(Grid)
+	g.RectGenRotated([]float64{1, 0}, []float64{3, 1}, []int{3, 2}, math.Pi/2)
+	chk.Float64(tst, "rotation", 1e-15, g.Rotation(), math.Pi/2)
+	chk.Array(tst, "x(1,0)", 1e-15, g.Node(g.IndexMNPtoI(1, 0, 0)), []float64{1, 1})
+	chk.Array(tst, "x(2,1)", 1e-15, g.Node(g.IndexMNPtoI(2, 1, 0)), []float64{0, 2})
+	chk.Array(tst, "local x(2,1)", 1e-15, g.ToLocal(g.Node(g.IndexMNPtoI(2, 1, 0))), []float64{3, 1})
+	chk.Array(tst, "X", 1e-15, g.Coords(0), []float64{1, 2, 3})
+	chk.Array(tst, "Y", 1e-15, g.Coords(1), []float64{0, 1})
+	chk.Float64(tst, "xlen", 1e-15, g.Xlen(0), 2)
+
+	// normals are rotated
+	N := la.NewVector(2)
+	g.UnitNormal(N, 11, g.IndexMNPtoI(2, 0, 0))
+	chk.Array(tst, "N(11)", 1e-15, N, []float64{0, 1})
+	g.UnitNormal(N, 20, g.IndexMNPtoI(1, 0, 0))
+	chk.Array(tst, "N(20)", 1e-15, N, []float64{1, 0})
+
+	// round trip
+	x := []float64{-0.3, 1.7}
+	chk.Array(tst, "ToPhysical(ToLocal(x))", 1e-15, g.ToPhysical(g.ToLocal(x)), x)
+
+	// regenerating the grid removes the rotation
+	g.RectGenUniform([]float64{1, 0}, []float64{3, 1}, []int{3, 2})
+	chk.Float64(tst, "rotation (uniform)", 1e-15, g.Rotation(), 0)
+	chk.Array(tst, "x(2,1) (uniform)", 1e-15, g.Node(g.IndexMNPtoI(2, 1, 0)), []float64{3, 1})
+}
+
+func TestGrid18(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Grid18. spacings of stretched grid")
+
+	X := []float64{0, 0.1, 0.3, 0.7, 1.5}
+	Y := []float64{-1, 0, 2}
+	g := new(Grid)
+	g.RectSet2d(X, Y)
+	for I := 0; I < g.Size(); I++ {
+		m, n, _ := g.IndexItoMNP(I)
+		for dim, i := range []int{m, n} {
+			x := [][]float64{X, Y}[dim]
+			hm, hp := g.Spacings(dim, I)
+			io.Pforan("node %2d, dim %d: h⁻ = %v, h⁺ = %v\n", I, dim, hm, hp)
+			if i > 0 {
+				chk.Float64(tst, io.Sf("h⁻(%d,%d)", I, dim), 1e-15, hm, x[i]-x[i-1])
+			}
+			if i < len(x)-1 {
+				chk.Float64(tst, io.Sf("h⁺(%d,%d)", I, dim), 1e-15, hp, x[i+1]-x[i])
+			}
+		}
+	}
+
+	// borders: mirrored neighbour
+	hm, hp := g.Spacings(0, g.IndexMNPtoI(0, 1, 0))
+	chk.Float64(tst, "h⁻ = h⁺ (left)", 1e-15, hm, hp)
+	hm, hp = g.Spacings(1, g.IndexMNPtoI(3, 2, 0))
+	chk.Float64(tst, "h⁻ (top)", 1e-15, hm, 2)
+	chk.Float64(tst, "h⁺ = h⁻ (top)", 1e-15, hp, hm)
+
+	// uniform 3D grid
+	g.RectGenUniform([]float64{0, 0, 0}, []float64{1, 2, 3}, []int{3, 5, 4})
+	hm, hp = g.Spacings(2, g.IndexMNPtoI(1, 2, 1))
+	chk.Float64(tst, "h⁻ (z)", 1e-15, hm, 1)
+	chk.Float64(tst, "h⁺ (z)", 1e-15, hp, 1)
+}
+
+func TestGrid19(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Grid19. control volumes")
+
+	// stretched grid
+	X := []float64{0, 0.1, 0.3, 0.7, 1.5}
+	Y := []float64{-1, 0, 2}
+	g := new(Grid)
+	g.RectSet2d(X, Y)
+	mid := func(x []float64, i int) (h float64) {
+		if i > 0 {
+			h += (x[i] - x[i-1]) / 2
+		}
+		if i < len(x)-1 {
+			h += (x[i+1] - x[i]) / 2
+		}
+		return
+	}
+	sum := 0.0
+	for I := 0; I < g.Size(); I++ {
+		m, n, _ := g.IndexItoMNP(I)
+		vol := g.ControlVolume(I)
+		chk.Float64(tst, io.Sf("V[%d]", I), 1e-15, vol, mid(X, m)*mid(Y, n))
+		sum += vol
+	}
+	chk.Float64(tst, "ΣV", 1e-14, sum, 1.5*3)
+
+	// uniform 3D and rotated 2D grids
+	g.RectGenUniform([]float64{0, 0, 0}, []float64{1, 2, 3}, []int{3, 5, 4})
+	sum = 0.0
+	for I := 0; I < g.Size(); I++ {
+		sum += g.ControlVolume(I)
+	}
+	chk.Float64(tst, "V (interior, 3D)", 1e-15, g.ControlVolume(g.IndexMNPtoI(1, 2, 1)), 0.5*0.5*1)
+	chk.Float64(tst, "V (corner, 3D)", 1e-15, g.ControlVolume(0), 0.25*0.25*0.5)
+	chk.Float64(tst, "ΣV (3D)", 1e-14, sum, 6)
+	g.RectGenRotated([]float64{1, 0}, []float64{3, 1}, []int{3, 2}, math.Pi/3)
+	chk.Float64(tst, "V (rotated)", 1e-15, g.ControlVolume(1), 1*0.5)
+
+	// curvilinear grid: quarter of ring with r ∈ [1, 2] ⇒ area = 3π/4
+	trf := FactoryTfinite.Surf2dQuarterRing(1, 2)
+	R := utl.LinSpace(-1, 1, 21)
+	g.SetTransfinite2d(trf, R, R)
+	sum = 0.0
+	for I := 0; I < g.Size(); I++ {
+		sum += g.ControlVolume(I)
+	}
+	io.Pforan("ΣV (ring) = %v (3π/4 = %v)\n", sum, 3*math.Pi/4)
+	chk.Float64(tst, "ΣV (ring)", 1e-12, sum, 3*math.Pi/4)
+}
@@ -0,0 +1,64 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gm
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func TestGridFile01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("GridFile01. write and load structured grids")
+
+	// 2D stretched grid
+	g := new(Grid)
+	g.RectSet2d([]float64{-1, 0, 0.3, 2}, []float64{0.5, 1, 3})
+	g.WriteStructured("/tmp/gosl/gm", "gridfile01a")
+	h := LoadStructuredGrid(filepath.Join("/tmp/gosl/gm", "gridfile01a.json"))
+	chk.Int(tst, "ndim", h.Ndim(), 2)
+	chk.Ints(tst, "npts", []int{h.Npts(0), h.Npts(1)}, []int{4, 3})
+	for I := 0; I < g.Size(); I++ {
+		chk.Array(tst, io.Sf("x @ %d", I), 1e-15, h.Node(I), g.Node(I))
+	}
+	chk.Ints(tst, "edge 3", h.Edge(3), g.Edge(3))
+	chk.Float64(tst, "xlen", 1e-15, h.Xlen(0), 3)
+
+	// 3D uniform grid
+	g.RectGenUniform([]float64{0, 0, 0}, []float64{1, 2, 3}, []int{3, 4, 2})
+	g.WriteStructured("/tmp/gosl/gm", "gridfile01b")
+	h = LoadStructuredGrid(filepath.Join("/tmp/gosl/gm", "gridfile01b.json"))
+	chk.Int(tst, "ndim", h.Ndim(), 3)
+	chk.Int(tst, "size", h.Size(), 24)
+	for I := 0; I < g.Size(); I++ {
+		chk.Array(tst, io.Sf("x @ %d", I), 1e-15, h.Node(I), g.Node(I))
+	}
+	chk.Ints(tst, "face 5", h.Face(5), g.Face(5))
+}
+
+func TestGridFile02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("GridFile02. panic on curvilinear grid")
+
+	defer chk.RecoverTstPanicIsOK(tst)
+	io.WriteStringToFileD("/tmp/gosl/gm", "gridfile02.json", `{"ndim":2, "npts":[2,2], "x":[0,0, 1,0, 0,1, 1.2,1]}`)
+	LoadStructuredGrid(filepath.Join("/tmp/gosl/gm", "gridfile02.json"))
+}
+
+func TestGridFile03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("GridFile03. panic on non-monotone coordinates")
+
+	// x = {0, 2, 1, 3}: the last coordinate is greater than the first one
+	defer chk.RecoverTstPanicIsOK(tst)
+	io.WriteStringToFileD("/tmp/gosl/gm", "gridfile03.json", `{"ndim":2, "npts":[4,2], "x":[0,0, 2,0, 1,0, 3,0, 0,1, 2,1, 1,1, 3,1]}`)
+	LoadStructuredGrid(filepath.Join("/tmp/gosl/gm", "gridfile03.json"))
+}
@@ -0,0 +1,43 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gm
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+func TestStaggered01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Staggered01. MAC grid layout")
+
+	sg := NewStaggeredGrid([]float64{-1, 0}, []float64{1, 3}, 4, 3)
+	chk.Int(tst, "ncells", sg.Ncells(), 12)
+	chk.Int(tst, "nfacesX", sg.NfacesX(), 15)
+	chk.Int(tst, "nfacesY", sg.NfacesY(), 16)
+	chk.Float64(tst, "dx", 1e-15, sg.Dx(), 0.5)
+	chk.Float64(tst, "dy", 1e-15, sg.Dy(), 1)
+
+	// indices
+	chk.Int(tst, "cell(3,2)", sg.CellIndex(3, 2), 11)
+	chk.Int(tst, "faceX(4,2)", sg.FaceXindex(4, 2), 14)
+	chk.Int(tst, "faceY(3,3)", sg.FaceYindex(3, 3), 15)
+
+	// coordinates
+	chk.Array(tst, "cell(1,2)", 1e-15, sg.CellCentre(1, 2), []float64{-0.25, 2.5})
+	chk.Array(tst, "faceX(1,2)", 1e-15, sg.FaceXcentre(1, 2), []float64{-0.5, 2.5})
+	chk.Array(tst, "faceY(1,2)", 1e-15, sg.FaceYcentre(1, 2), []float64{-0.25, 2})
+
+	// grid with nodes at cell centres
+	g := sg.CellGrid()
+	chk.Int(tst, "grid size", g.Size(), sg.Ncells())
+	for j := 0; j < sg.Ny(); j++ {
+		for i := 0; i < sg.Nx(); i++ {
+			chk.Array(tst, "node", 1e-15, g.Node(sg.CellIndex(i, j)), sg.CellCentre(i, j))
+		}
+	}
+}
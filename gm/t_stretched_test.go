@@ -0,0 +1,74 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func TestStretched01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Stretched01. presets of stretching")
+
+	// tanh: symmetric clustering at both ends
+	n := 11
+	X := StretchedCoords("tanh", 1, 3, n, 2)
+	io.Pforan("X (tanh) = %v\n", X)
+	chk.Float64(tst, "X[0]", 1e-17, X[0], 1)
+	chk.Float64(tst, "X[n-1]", 1e-17, X[n-1], 3)
+	chk.Float64(tst, "X[mid]", 1e-15, X[n/2], 2)
+	for i := 0; i < n; i++ {
+		chk.Float64(tst, "symmetry", 1e-15, X[i]-1, 3-X[n-1-i])
+	}
+	hEnd, hMid := X[1]-X[0], X[n/2+1]-X[n/2]
+	io.Pforan("h(end) = %v, h(mid) = %v\n", hEnd, hMid)
+	for i := 1; i < n/2; i++ { // spacings grow towards the centre
+		if X[i]-X[i-1] >= X[i+1]-X[i] {
+			tst.Errorf("tanh spacing should increase towards the centre. i=%d\n", i)
+		}
+	}
+	if hEnd > hMid/3 {
+		tst.Errorf("nodes should be clustered near the ends\n")
+	}
+
+	// geometric: constant ratio of spacings
+	X = StretchedCoords("geometric", 0, 1, 6, 1.5)
+	for i := 1; i < 5; i++ {
+		chk.Float64(tst, "ratio", 1e-13, (X[i+1]-X[i])/(X[i]-X[i-1]), 1.5)
+	}
+	chk.Array(tst, "geometric(β=1) == uniform", 1e-15, StretchedCoords("geometric", 0, 1, 6, 1), StretchedCoords("uniform", 0, 1, 6, 0))
+
+	// log: uniform in log(x)
+	X = StretchedCoords("log", 0.1, 10, 5, 0)
+	chk.Array(tst, "log", 1e-14, X, []float64{0.1, math.Sqrt(0.1), 1, math.Sqrt(10), 10})
+
+	// invalid preset
+	defer chk.RecoverTstPanicIsOK(tst)
+	StretchedCoords("cosine", 0, 1, 5, 0)
+}
+
+func TestStretched02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Stretched02. stretched grid")
+
+	g := NewStretchedGrid([]float64{0, 1}, []float64{2, 3}, []int{7, 5}, []string{"tanh", "log"}, []float64{1.5, 0})
+	chk.Int(tst, "ndim", g.Ndim(), 2)
+	chk.Int(tst, "size", g.Size(), 35)
+	X, Y := g.Coords(0), g.Coords(1)
+	chk.Array(tst, "X", 1e-15, X, StretchedCoords("tanh", 0, 2, 7, 1.5))
+	chk.Array(tst, "Y", 1e-15, Y, StretchedCoords("log", 1, 3, 5, 0))
+	for I := 0; I < g.Size(); I++ {
+		m, n, _ := g.IndexItoMNP(I)
+		chk.Array(tst, io.Sf("x @ %d", I), 1e-15, g.Node(I), []float64{X[m], Y[n]})
+	}
+	chk.Float64(tst, "xmax", 1e-15, g.Xmax(0), 2)
+	chk.Float64(tst, "ymin", 1e-15, g.Xmin(1), 1)
+}
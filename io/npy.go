@@ -0,0 +1,123 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package io
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+// npyMagic holds the first bytes of NumPy (.npy) files
+const npyMagic = "\x93NUMPY"
+
+// WriteNpyD writes a NumPy (.npy, version 1.0) file with float64 (little-endian) values in C
+// order (last index runs faster) after creating a directory; e.g. for post-processing in Python
+//
+//   import numpy as np
+//   a = np.load(filename)
+//
+//  dirout -- directory for output. will be created
+//  fn     -- filename; e.g. "solution.npy"
+//  shape  -- dimensions of the array; e.g. {ny, nx}
+//  data   -- [Π shape] values
+func WriteNpyD(dirout, fn string, shape []int, data []float64) {
+	n := 1
+	dims := make([]string, len(shape))
+	for i, d := range shape {
+		n *= d
+		dims[i] = strconv.Itoa(d)
+	}
+	if n != len(data) {
+		chk.Panic("size of data must be equal to the product of dimensions %v. %d != %d\n", shape, len(data), n)
+	}
+	tuple := strings.Join(dims, ", ")
+	if len(shape) == 1 {
+		tuple += ","
+	}
+	header := Sf("{'descr': '<f8', 'fortran_order': False, 'shape': (%s), }", tuple)
+	total := len(npyMagic) + 4 + len(header) + 1 // magic, version, header length, header and '\n'
+	if pad := total % 64; pad != 0 {
+		header += strings.Repeat(" ", 64-pad)
+	}
+	header += "\n"
+	if len(header) > math.MaxUint16 {
+		chk.Panic("header of npy file is too long\n")
+	}
+	var buf bytes.Buffer
+	buf.WriteString(npyMagic)
+	buf.Write([]byte{1, 0})
+	binary.Write(&buf, binary.LittleEndian, uint16(len(header)))
+	buf.WriteString(header)
+	binary.Write(&buf, binary.LittleEndian, data)
+	WriteFileD(dirout, fn, &buf)
+}
+
+// ReadNpy reads a NumPy (.npy) file with float64 (little-endian) values in C order; e.g. written
+// by WriteNpyD or by numpy.save
+//  Output:
+//   shape -- dimensions of the array
+//   data  -- [Π shape] values
+func ReadNpy(fn string) (shape []int, data []float64) {
+
+	// magic and version
+	b := ReadFile(fn)
+	if len(b) < 10 || string(b[:6]) != npyMagic {
+		chk.Panic("file <%s> is not a npy file\n", fn)
+	}
+	var hlen, start int
+	switch b[6] {
+	case 1:
+		hlen, start = int(binary.LittleEndian.Uint16(b[8:10])), 10
+	case 2, 3:
+		if len(b) < 12 {
+			chk.Panic("file <%s> is not a npy file\n", fn)
+		}
+		hlen, start = int(binary.LittleEndian.Uint32(b[8:12])), 12
+	default:
+		chk.Panic("version %d of npy file <%s> is not supported\n", b[6], fn)
+	}
+	if len(b) < start+hlen {
+		chk.Panic("header of npy file <%s> is truncated\n", fn)
+	}
+	header := string(b[start : start+hlen])
+
+	// header
+	descr := regexp.MustCompile(`'descr':\s*'([^']*)'`).FindStringSubmatch(header)
+	if descr == nil || descr[1] != "<f8" {
+		chk.Panic("npy file <%s> must have dtype '<f8'. header = %s\n", fn, header)
+	}
+	if !strings.Contains(header, "'fortran_order': False") {
+		chk.Panic("npy file <%s> must be in C order. header = %s\n", fn, header)
+	}
+	tuple := regexp.MustCompile(`'shape':\s*\(([^)]*)\)`).FindStringSubmatch(header)
+	if tuple == nil {
+		chk.Panic("cannot find shape in header of npy file <%s>. header = %s\n", fn, header)
+	}
+	n := 1
+	for _, s := range strings.Split(tuple[1], ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			d := Atoi(s)
+			shape = append(shape, d)
+			n *= d
+		}
+	}
+
+	// data
+	body := b[start+hlen:]
+	if len(body) != 8*n {
+		chk.Panic("size of data in npy file <%s> is incorrect. %d != %d\n", fn, len(body), 8*n)
+	}
+	data = make([]float64, n)
+	for i := range data {
+		data[i] = math.Float64frombits(binary.LittleEndian.Uint64(body[8*i:]))
+	}
+	return
+}
@@ -0,0 +1,39 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package io
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+func Test_npy01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("npy01")
+
+	// round trip
+	data := []float64{1, -2.5, 3e-300, 4, 5, 6.125}
+	WriteNpyD("/tmp/gosl/io", "npy01.npy", []int{2, 3}, data)
+	shape, res := ReadNpy("/tmp/gosl/io/npy01.npy")
+	chk.Ints(tst, "shape", shape, []int{2, 3})
+	chk.Array(tst, "data", 1e-15, res, data)
+
+	// header is aligned to 64 bytes
+	b := ReadFile("/tmp/gosl/io/npy01.npy")
+	Pforan("header = %q\n", string(b[10:len(b)-48]))
+	chk.Int(tst, "header length", (len(b)-8*6)%64, 0)
+
+	// 1D
+	WriteNpyD("/tmp/gosl/io", "npy01b.npy", []int{3}, []float64{7, 8, 9})
+	shape, res = ReadNpy("/tmp/gosl/io/npy01b.npy")
+	chk.Ints(tst, "shape (1D)", shape, []int{3})
+	chk.Array(tst, "data (1D)", 1e-15, res, []float64{7, 8, 9})
+
+	// wrong size
+	defer chk.RecoverTstPanicIsOK(tst)
+	WriteNpyD("/tmp/gosl/io", "npy01c.npy", []int{2, 2}, data)
+}
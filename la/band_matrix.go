@@ -0,0 +1,157 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/utl"
+)
+
+// BandMatrix implements a square banded matrix using the (LAPACK) band storage format
+//
+//   The diagonals are stored in the rows of a column-major array with Ldab = 2⋅kl + ku + 1 rows;
+//   the first kl rows are reserved for the fill-in created by the LU factorisation with partial
+//   pivoting (see SolveBanded). Example with n = 5, kl = 1 and ku = 2:
+//
+//         ┌                     ┐                 ┌                     ┐
+//         │ a00 a01 a02         │                 │  *   *   *   +   +  │  ← fill-in
+//         │ a10 a11 a12 a13     │                 │  *   *  a02 a13 a24 │
+//     A = │     a21 a22 a23 a24 │   ⇒   Data =    │  *  a01 a12 a23 a34 │
+//         │         a32 a33 a34 │                 │ a00 a11 a22 a33 a44 │
+//         │             a43 a44 │                 │ a10 a21 a32 a43  *  │
+//         └                     ┘                 └                     ┘
+//
+//     Data[kl+ku+i-j + j⋅Ldab] = A[i][j]   for   max(0, j-ku) ≤ i ≤ min(n-1, j+kl)
+//
+type BandMatrix struct {
+	N    int       // dimension
+	Kl   int       // number of sub-diagonals (lower bandwidth)
+	Ku   int       // number of super-diagonals (upper bandwidth)
+	Ldab int       // leading dimension of Data: 2⋅kl + ku + 1
+	Data []float64 // [Ldab⋅n] band storage (column-major)
+}
+
+// NewBandMatrix allocates a new (zero) banded matrix
+//  n  -- dimension
+//  kl -- number of sub-diagonals
+//  ku -- number of super-diagonals
+func NewBandMatrix(n, kl, ku int) (o *BandMatrix) {
+	if n < 1 || kl < 0 || ku < 0 {
+		chk.Panic("dimension must be positive and bandwidths must be non-negative. n=%d, kl=%d, ku=%d is invalid\n", n, kl, ku)
+	}
+	o = new(BandMatrix)
+	o.N, o.Kl, o.Ku = n, kl, ku
+	o.Ldab = 2*kl + ku + 1
+	o.Data = make([]float64, o.Ldab*n)
+	return
+}
+
+// Set sets value of A[i][j]; (i,j) must be within the band
+func (o *BandMatrix) Set(i, j int, val float64) {
+	if i-j > o.Kl || j-i > o.Ku {
+		chk.Panic("entry (%d,%d) is outside the band with kl=%d and ku=%d\n", i, j, o.Kl, o.Ku)
+	}
+	o.Data[o.Kl+o.Ku+i-j+j*o.Ldab] = val
+}
+
+// Get returns A[i][j]; zero if (i,j) is outside the band
+func (o *BandMatrix) Get(i, j int) float64 {
+	if i-j > o.Kl || j-i > o.Ku {
+		return 0
+	}
+	return o.Data[o.Kl+o.Ku+i-j+j*o.Ldab]
+}
+
+// ToBand converts a square sparse matrix to band storage
+//   kl -- number of sub-diagonals; e.g. from BandwidthStats
+//   ku -- number of super-diagonals; e.g. from BandwidthStats
+//   NOTE: panics if there are non-zero entries outside the band
+func (o *CCMatrix) ToBand(kl, ku int) (b *BandMatrix) {
+	if o.m != o.n {
+		chk.Panic("matrix must be square. %d != %d\n", o.m, o.n)
+	}
+	b = NewBandMatrix(o.n, kl, ku)
+	for j := 0; j < o.n; j++ {
+		for p := o.p[j]; p < o.p[j+1]; p++ {
+			i := o.i[p]
+			if o.x[p] != 0 && (i-j > kl || j-i > ku) {
+				chk.Panic("entry (%d,%d) = %g is outside the band with kl=%d and ku=%d\n", i, j, o.x[p], kl, ku)
+			}
+			if i-j <= kl && j-i <= ku {
+				b.Data[kl+ku+i-j+j*b.Ldab] += o.x[p] // duplicates are summed up
+			}
+		}
+	}
+	return
+}
+
+// SolveBanded solves a banded linear system using the LU factorisation with partial pivoting
+//
+//   Given:  A ⋅ x = b    find x   such that   x = A⁻¹ ⋅ b
+//
+//   The cost is O(n⋅kl⋅(kl+ku)) instead of O(n³); thus, this function is efficient for
+//   narrow-banded matrices; e.g. from 1D problems or thin 2D domains
+//
+//   NOTE: A is replaced by its factorisation if preserveA is false
+func SolveBanded(x Vector, A *BandMatrix, b Vector, preserveA bool) {
+
+	// check
+	n, kl := A.N, A.Kl
+	if len(x) != n || len(b) != n {
+		chk.Panic("sizes of vectors must be equal to %d. len(x)=%d, len(b)=%d\n", n, len(x), len(b))
+	}
+	a := A
+	if preserveA {
+		a = &BandMatrix{N: n, Kl: A.Kl, Ku: A.Ku, Ldab: A.Ldab, Data: make([]float64, len(A.Data))}
+		copy(a.Data, A.Data)
+	}
+	kv := a.Kl + a.Ku // upper bandwidth of U
+	at := func(i, j int) *float64 { return &a.Data[kv+i-j+j*a.Ldab] }
+	for j := 0; j < n; j++ { // clear fill-in rows
+		for r := 0; r < kl; r++ {
+			a.Data[r+j*a.Ldab] = 0
+		}
+	}
+
+	// factorisation (similar to LAPACK's dgbtf2) and forward substitution
+	copy(x, b)
+	for j := 0; j < n; j++ {
+		km := utl.Imin(kl, n-1-j)
+		jend := utl.Imin(j+kv, n-1)
+		p := j
+		for i := j + 1; i <= j+km; i++ {
+			if math.Abs(*at(i, j)) > math.Abs(*at(p, j)) {
+				p = i
+			}
+		}
+		if *at(p, j) == 0 {
+			chk.Panic("matrix is singular\n")
+		}
+		if p != j {
+			for c := j; c <= jend; c++ {
+				*at(j, c), *at(p, c) = *at(p, c), *at(j, c)
+			}
+			x[j], x[p] = x[p], x[j]
+		}
+		for i := j + 1; i <= j+km; i++ {
+			l := *at(i, j) / *at(j, j)
+			*at(i, j) = l
+			for c := j + 1; c <= jend; c++ {
+				*at(i, c) -= l * *at(j, c)
+			}
+			x[i] -= l * x[j]
+		}
+	}
+
+	// back substitution
+	for i := n - 1; i >= 0; i-- {
+		for c := i + 1; c <= utl.Imin(i+kv, n-1); c++ {
+			x[i] -= *at(i, c) * x[c]
+		}
+		x[i] /= *at(i, i)
+	}
+}
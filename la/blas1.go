@@ -7,6 +7,7 @@ package la
 import (
 	"math"
 
+	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/la/oblas"
 )
 
@@ -89,4 +90,104 @@ func VecScaleAbs(scale Vector, a, m float64, x Vector) {
 	}
 }
 
+// Dot returns the dot product between two vectors (with length check):
+//   s := x・y
+func Dot(x, y Vector) (res float64) {
+	if len(x) != len(y) {
+		chk.Panic("vectors must have the same length. %d != %d\n", len(x), len(y))
+	}
+	return VecDot(x, y)
+}
+
+// Axpy adds a scaled vector to another vector (with length check):
+//   y += α⋅x   ⇒   y[i] += α⋅x[i]
+func Axpy(α float64, x, y Vector) {
+	n := len(x)
+	if len(y) != n {
+		chk.Panic("vectors must have the same length. %d != %d\n", n, len(y))
+	}
+	cutoff := 150
+	if n > cutoff {
+		oblas.Daxpy(n, α, x, 1, y, 1)
+		return
+	}
+	for i := 0; i < n; i++ {
+		y[i] += α * x[i]
+	}
+}
+
+// Scal scales a vector:
+//   x := α⋅x   ⇒   x[i] := α⋅x[i]
+func Scal(α float64, x Vector) {
+	n := len(x)
+	cutoff := 150
+	if n > cutoff {
+		oblas.Dscal(n, α, x, 1)
+		return
+	}
+	for i := 0; i < n; i++ {
+		x[i] *= α
+	}
+}
+
+// DotInc returns the dot product between n strided components of two vectors (with length check):
+//   s := Σ x[i⋅incx]・y[i⋅incy]   for  i = 0..n-1
+//   NOTE: the increments must be positive; e.g. incx = ncol selects a column of a row-major matrix
+func DotInc(n int, x Vector, incx int, y Vector, incy int) (res float64) {
+	checkInc("x", n, len(x), incx)
+	checkInc("y", n, len(y), incy)
+	cutoff := 150
+	if n > cutoff {
+		return oblas.Ddot(n, x, incx, y, incy)
+	}
+	for i := 0; i < n; i++ {
+		res += x[i*incx] * y[i*incy]
+	}
+	return
+}
+
+// AxpyInc adds n strided components of a scaled vector to another vector (with length check):
+//   y[i⋅incy] += α⋅x[i⋅incx]   for  i = 0..n-1
+//   NOTE: the increments must be positive
+func AxpyInc(n int, α float64, x Vector, incx int, y Vector, incy int) {
+	checkInc("x", n, len(x), incx)
+	checkInc("y", n, len(y), incy)
+	cutoff := 150
+	if n > cutoff {
+		oblas.Daxpy(n, α, x, incx, y, incy)
+		return
+	}
+	for i := 0; i < n; i++ {
+		y[i*incy] += α * x[i*incx]
+	}
+}
+
+// ScalInc scales n strided components of a vector (with length check):
+//   x[i⋅incx] := α⋅x[i⋅incx]   for  i = 0..n-1
+//   NOTE: the increment must be positive
+func ScalInc(n int, α float64, x Vector, incx int) {
+	checkInc("x", n, len(x), incx)
+	cutoff := 150
+	if n > cutoff {
+		oblas.Dscal(n, α, x, incx)
+		return
+	}
+	for i := 0; i < n; i++ {
+		x[i*incx] *= α
+	}
+}
+
+// checkInc checks the number of components and the increment of a strided vector
+func checkInc(name string, n, length, inc int) {
+	if n < 0 {
+		chk.Panic("number of components must not be negative. n=%d is invalid\n", n)
+	}
+	if inc < 1 {
+		chk.Panic("increment of %s must be positive. inc%s=%d is invalid\n", name, name, inc)
+	}
+	if n > 0 && length < 1+(n-1)*inc {
+		chk.Panic("vector %s is too short for %d components with increment %d. %d < %d\n", name, n, inc, length, 1+(n-1)*inc)
+	}
+}
+
 // complex /////////////////////////////////////////////////////////////////////////////////////////
@@ -8,6 +8,7 @@ import (
 	"math"
 
 	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
 	"github.com/cpmech/gosl/la/oblas"
 )
 
@@ -26,26 +27,26 @@ func DenSolve(x Vector, A *Matrix, b Vector, preserveA bool) {
 	oblas.Dgesv(A.M, 1, a.Data, A.M, ipiv, x, A.M)
 }
 
+// NotPosDefError indicates that the Cholesky factorisation failed because the matrix is not
+// positive-definite; e.g. due to an error in the signs of an operator
+type NotPosDefError struct {
+	Pivot int     // index of row (and column) where the factorisation broke down
+	Value float64 // offending (non-positive) pivot; i.e. a[k][k] - Σ L[k][j]² for j < k
+}
+
+// Error returns the error message
+func (o *NotPosDefError) Error() string {
+	return io.Sf("Cholesky factorization failed due to non positive-definite matrix: pivot %d is %g", o.Pivot, o.Value)
+}
+
 // Cholesky returns the Cholesky decomposition of a symmetric positive-definite matrix
 //
 //   a = L * trans(L)
 //
+//   NOTE: panics (with the message of NotPosDefError) if the matrix is not positive-definite
 func Cholesky(L, a *Matrix) {
-	for j := 0; j < a.M; j++ { // loop over columns
-		for i := j; i < a.M; i++ { // loop over lower diagonal rows (including diagonal)
-			amsum := a.Get(i, j)
-			for k := 0; k < j; k++ {
-				amsum -= L.Get(i, k) * L.Get(j, k)
-			}
-			if i == j {
-				if amsum <= 0.0 {
-					chk.Panic("Cholesky factorization failed due to non positive-definite matrix")
-				}
-				L.Set(i, j, math.Sqrt(amsum))
-			} else {
-				L.Set(i, j, amsum/L.Get(j, j))
-			}
-		}
+	if err := cholesky(L, a); err != nil {
+		chk.Panic("%v", err)
 	}
 }
 
@@ -53,12 +54,17 @@ func Cholesky(L, a *Matrix) {
 //
 //        x := inv(a) * b
 //
+//   Output:
+//     err -- *NotPosDefError if the matrix is not positive-definite; x is not modified in this case
+//
 //   NOTE: this function uses Cholesky decomposition and should be used for small systems
-func SolveRealLinSysSPD(x Vector, a *Matrix, b Vector) {
+func SolveRealLinSysSPD(x Vector, a *Matrix, b Vector) (err error) {
 
 	// Cholesky factorisation
 	L := NewMatrix(a.M, a.M)
-	Cholesky(L, a)
+	if e := cholesky(L, a); e != nil {
+		return e
+	}
 
 	// solve L*y = b storing y in x
 	for i := 0; i < a.M; i++ {
@@ -77,6 +83,7 @@ func SolveRealLinSysSPD(x Vector, a *Matrix, b Vector) {
 		}
 		x[i] = bmsum / L.Get(i, i)
 	}
+	return
 }
 
 // SolveTwoRealLinSysSPD solves two linear systems with real numbres and Symmetric-Positive-Definite (SPD) matrices
@@ -114,3 +121,207 @@ func SolveTwoRealLinSysSPD(x, X Vector, a *Matrix, b, B Vector) {
 		X[i] = Bmsum / L.Get(i, i)
 	}
 }
+
+// SolveBlockTridiag solves a block-tridiagonal linear system using the block Thomas algorithm
+//
+//   ┌                          ┐ ┌      ┐   ┌      ┐
+//   │ B0  C0                   │ │  x0  │   │  d0  │
+//   │ A1  B1  C1               │ │  x1  │   │  d1  │
+//   │     A2  B2  C2           │ │  x2  │ = │  d2  │
+//   │          ⋱   ⋱   ⋱       │ │  ⋮   │   │  ⋮   │
+//   │             An   Bn      │ │  xn  │   │  dn  │
+//   └                          ┘ └      ┘   └      ┘
+//
+//   Input:
+//     A -- [nb] sub-diagonal (m×m) blocks; A[0] is ignored [may be nil]
+//     B -- [nb] diagonal (m×m) blocks
+//     C -- [nb] super-diagonal (m×m) blocks; C[nb-1] is ignored [may be nil]
+//     d -- [nb] right-hand side (m) vectors
+//   Output:
+//     x -- [nb] solution (m) vectors
+//
+//   NOTE: (1) the blocks are not inverted; instead, the (modified) diagonal blocks are factorised
+//             by the LU decomposition with partial pivoting. The algorithm is stable if, e.g.,
+//             the matrix is block diagonally dominant
+//         (2) this function is intended for small blocks; e.g. systems of PDEs in 1D with
+//             m degrees of freedom per node. The input blocks and vectors are not modified
+func SolveBlockTridiag(x []Vector, A, B, C []*Matrix, d []Vector) {
+
+	// check
+	nb := len(B)
+	if nb < 1 {
+		chk.Panic("the number of blocks must be at least 1\n")
+	}
+	if len(A) != nb || len(C) != nb || len(d) != nb || len(x) != nb {
+		chk.Panic("the numbers of blocks must be equal. len(A)=%d, len(B)=%d, len(C)=%d, len(d)=%d, len(x)=%d\n", len(A), nb, len(C), len(d), len(x))
+	}
+	m := B[0].M
+
+	// forward elimination:
+	//   M_i  = B_i - A_i⋅C'_{i-1}
+	//   C'_i = M_i⁻¹⋅C_i
+	//   d'_i = M_i⁻¹⋅(d_i - A_i⋅d'_{i-1})
+	cp := make([]*Matrix, nb) // modified super-diagonal blocks
+	dp := make([]Vector, nb)  // modified right-hand side
+	mat := NewMatrix(m, m)
+	piv := make([]int, m)
+	for i := 0; i < nb; i++ {
+		B[i].CopyInto(mat, 1)
+		dp[i] = d[i].GetCopy()
+		if i > 0 {
+			MatMatMulAdd(mat, -1, A[i], cp[i-1])
+			MatVecMulAdd(dp[i], -1, A[i], dp[i-1])
+		}
+		denLUfactor(mat, piv)
+		denLUsolve(dp[i], mat, piv)
+		if i < nb-1 {
+			cp[i] = NewMatrix(m, m)
+			col := NewVector(m)
+			for j := 0; j < m; j++ {
+				for k := 0; k < m; k++ {
+					col[k] = C[i].Get(k, j)
+				}
+				denLUsolve(col, mat, piv)
+				for k := 0; k < m; k++ {
+					cp[i].Set(k, j, col[k])
+				}
+			}
+		}
+	}
+
+	// back substitution: x_i = d'_i - C'_i⋅x_{i+1}
+	copy(x[nb-1], dp[nb-1])
+	for i := nb - 2; i >= 0; i-- {
+		copy(x[i], dp[i])
+		MatVecMulAdd(x[i], -1, cp[i], x[i+1])
+	}
+}
+
+// DenseLU holds the LU factorisation (with partial pivoting) of a square dense matrix: P⋅A = L⋅U
+//  NOTE: the factorisation can be used to solve both A⋅x = b and Aᵀ⋅x = b; e.g. in adjoint methods
+type DenseLU struct {
+	lu  *Matrix // L (unit lower; below the diagonal) and U
+	piv []int   // row permutations
+}
+
+// FactorizeLU computes the LU factorisation (with partial pivoting) of a square dense matrix
+//   a -- [m][m] matrix; not modified
+//   NOTE: this function is intended for small to moderate matrices (non-blocked algorithm)
+func FactorizeLU(a *Matrix) (o *DenseLU) {
+	if a.M != a.N {
+		chk.Panic("matrix must be square. %d != %d\n", a.M, a.N)
+	}
+	o = new(DenseLU)
+	o.lu = a.GetCopy()
+	o.piv = make([]int, a.M)
+	denLUfactor(o.lu, o.piv)
+	return
+}
+
+// Solve solves A⋅x = b
+func (o *DenseLU) Solve(x, b Vector) {
+	copy(x, b)
+	denLUsolve(x, o.lu, o.piv)
+}
+
+// SolveTranspose solves Aᵀ⋅x = b using the same factorisation
+//
+//   Aᵀ = Uᵀ⋅Lᵀ⋅P  ⇒  solve Uᵀ⋅y = b, then Lᵀ⋅z = y, then x = Pᵀ⋅z
+//
+func (o *DenseLU) SolveTranspose(x, b Vector) {
+	copy(x, b)
+	m := o.lu.M
+	for i := 0; i < m; i++ {
+		for j := 0; j < i; j++ {
+			x[i] -= o.lu.Get(j, i) * x[j]
+		}
+		x[i] /= o.lu.Get(i, i)
+	}
+	for i := m - 2; i >= 0; i-- {
+		for j := i + 1; j < m; j++ {
+			x[i] -= o.lu.Get(j, i) * x[j]
+		}
+	}
+	for k := m - 1; k >= 0; k-- {
+		if o.piv[k] != k {
+			x[k], x[o.piv[k]] = x[o.piv[k]], x[k]
+		}
+	}
+}
+
+// denLUfactor computes the LU factorisation (with partial pivoting) of a small dense matrix in place
+//   a   -- [m][m] matrix; replaced by L (unit lower; below the diagonal) and U
+//   piv -- [m] row permutations: row i was interchanged with row piv[i]
+func denLUfactor(a *Matrix, piv []int) {
+	m := a.M
+	for k := 0; k < m; k++ {
+		p := k
+		for i := k + 1; i < m; i++ {
+			if math.Abs(a.Get(i, k)) > math.Abs(a.Get(p, k)) {
+				p = i
+			}
+		}
+		if a.Get(p, k) == 0 {
+			chk.Panic("matrix is singular\n")
+		}
+		piv[k] = p
+		if p != k {
+			for j := 0; j < m; j++ {
+				tmp := a.Get(k, j)
+				a.Set(k, j, a.Get(p, j))
+				a.Set(p, j, tmp)
+			}
+		}
+		for i := k + 1; i < m; i++ {
+			l := a.Get(i, k) / a.Get(k, k)
+			a.Set(i, k, l)
+			for j := k + 1; j < m; j++ {
+				a.Add(i, j, -l*a.Get(k, j))
+			}
+		}
+	}
+}
+
+// denLUsolve solves a⋅x = b using the factorisation computed by denLUfactor
+//   x -- [m] right-hand side b on input; solution x on output
+func denLUsolve(x Vector, a *Matrix, piv []int) {
+	m := a.M
+	for k := 0; k < m; k++ {
+		if piv[k] != k {
+			x[k], x[piv[k]] = x[piv[k]], x[k]
+		}
+	}
+	for i := 1; i < m; i++ {
+		for j := 0; j < i; j++ {
+			x[i] -= a.Get(i, j) * x[j]
+		}
+	}
+	for i := m - 1; i >= 0; i-- {
+		for j := i + 1; j < m; j++ {
+			x[i] -= a.Get(i, j) * x[j]
+		}
+		x[i] /= a.Get(i, i)
+	}
+}
+
+// cholesky computes the Cholesky decomposition a = L * trans(L) and returns an error if the
+// matrix is not positive-definite
+func cholesky(L, a *Matrix) *NotPosDefError {
+	for j := 0; j < a.M; j++ { // loop over columns
+		for i := j; i < a.M; i++ { // loop over lower diagonal rows (including diagonal)
+			amsum := a.Get(i, j)
+			for k := 0; k < j; k++ {
+				amsum -= L.Get(i, k) * L.Get(j, k)
+			}
+			if i == j {
+				if amsum <= 0.0 {
+					return &NotPosDefError{Pivot: j, Value: amsum}
+				}
+				L.Set(i, j, math.Sqrt(amsum))
+			} else {
+				L.Set(i, j, amsum/L.Get(j, j))
+			}
+		}
+	}
+	return nil
+}
@@ -161,6 +161,25 @@ func NewEquations(n int, kx []int) (o *Equations) {
 	return
 }
 
+// ReorderUnknowns changes the numbering of the u-system; e.g. to use red-black ordering
+//   utof -- [Nu] new UtoF map; must be a permutation of the current UtoF
+//   NOTE: this function must be called before assembling the partitioned system
+func (o *Equations) ReorderUnknowns(utof []int) {
+	if len(utof) != o.Nu {
+		chk.Panic("len(utof) must be equal to Nu. %d != %d\n", len(utof), o.Nu)
+	}
+	ftou := make([]int, o.N)
+	utl.IntFill(ftou, -1)
+	for i, I := range utof {
+		if I < 0 || I >= o.N || o.FtoU[I] < 0 || ftou[I] >= 0 {
+			chk.Panic("utof must be a permutation of the unknown equations. equation %d is invalid\n", I)
+		}
+		ftou[I] = i
+	}
+	o.UtoF = append(o.UtoF[:0], utof...)
+	o.FtoU = ftou
+}
+
 // Alloc allocates the A matrices in triplet format (sparse format)
 //  INPUT:
 //    nnz -- total number of nonzeros in each part [nnz(Auu), nnz(Auk), nnz(Aku), nnz(Akk)]
@@ -250,6 +269,45 @@ func (o *Equations) GetAmat() (A *Triplet) {
 	return
 }
 
+// GetRow returns the non-zero entries of row I of the full A matrix (e.g. for debugging)
+//  INPUT:
+//   I -- equation number in the FULL system
+//  OUTPUT:
+//   cols -- column numbers (in the FULL system) sorted in ascending order
+//   vals -- corresponding values; repeated entries are summed up
+//  NOTE: the rows of known equations are only available if the "kparts" have been allocated
+func (o *Equations) GetRow(I int) (cols []int, vals []float64) {
+	row := make(map[int]float64)
+	collect := func(t *Triplet, i int, colMap []int) {
+		for k := 0; k < t.pos; k++ {
+			if t.i[k] == i {
+				row[colMap[t.j[k]]] += t.x[k]
+			}
+		}
+	}
+	if i := o.FtoU[I]; i >= 0 {
+		collect(o.Auu, i, o.UtoF)
+		collect(o.Auk, i, o.KtoF)
+	} else {
+		if o.Aku == nil {
+			chk.Panic("cannot get row %d of known equation because Aku and Akk have not been allocated\n", I)
+		}
+		i = o.FtoK[I]
+		collect(o.Aku, i, o.UtoF)
+		collect(o.Akk, i, o.KtoF)
+	}
+	cols = make([]int, 0, len(row))
+	for J := range row {
+		cols = append(cols, J)
+	}
+	sort.Ints(cols)
+	vals = make([]float64, len(cols))
+	for k, J := range cols {
+		vals[k] = row[J]
+	}
+	return
+}
+
 // JoinVector joins uknown with known parts of vector
 //  INPUT:
 //   bu, bk -- partitioned vectors; e.g. o.Bu, and o.Bk or o.Xu, o.Xk
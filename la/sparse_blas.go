@@ -4,7 +4,14 @@
 
 package la
 
-import "github.com/cpmech/gosl/chk"
+import (
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/utl"
+)
 
 // --------------------------------------------------------------------------------------------------
 // matrix-matrix ------------------------------------------------------------------------------------
@@ -40,6 +47,52 @@ func SpMatVecMul(v Vector, α float64, a *CCMatrix, u Vector) {
 	}
 }
 
+// SpMatVecMulCSR returns the (sparse) matrix-vector multiplication (scaled) with a CSR matrix:
+//  v := α * a * u  =>  vi = α * aij * uj
+func SpMatVecMulCSR(v Vector, α float64, a *CSRMatrix, u Vector) {
+	var sum float64
+	for i := 0; i < a.m; i++ {
+		sum = 0
+		for k := a.p[i]; k < a.p[i+1]; k++ {
+			sum += a.x[k] * u[a.j[k]]
+		}
+		v[i] = α * sum
+	}
+}
+
+// SpMatVecMulCSRpar returns the (sparse) matrix-vector multiplication (scaled) with a CSR matrix
+// computed in parallel by splitting the rows among goroutines:
+//  v := α * a * u  =>  vi = α * aij * uj
+//  nworkers -- number of goroutines; if nworkers < 1, runtime.NumCPU() is used
+//  NOTE: each row is summed by a single goroutine in the same order as in SpMatVecMulCSR;
+//        thus, the results are (bitwise) identical to the serial version
+func SpMatVecMulCSRpar(v Vector, α float64, a *CSRMatrix, u Vector, nworkers int) {
+	if nworkers < 1 {
+		nworkers = runtime.NumCPU()
+	}
+	nworkers = utl.Imin(nworkers, a.m)
+	if nworkers < 2 {
+		SpMatVecMulCSR(v, α, a, u)
+		return
+	}
+	wg := new(sync.WaitGroup)
+	wg.Add(nworkers)
+	for w := 0; w < nworkers; w++ {
+		start, end := (w*a.m)/nworkers, ((w+1)*a.m)/nworkers
+		go func() {
+			for i := start; i < end; i++ {
+				sum := 0.0
+				for k := a.p[i]; k < a.p[i+1]; k++ {
+					sum += a.x[k] * u[a.j[k]]
+				}
+				v[i] = α * sum
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+}
+
 // SpMatVecMulAdd returns the (sparse) matrix-vector multiplication with addition (scaled):
 //  v += α * a * u  =>  vi += α * aij * uj
 func SpMatVecMulAdd(v Vector, α float64, a *CCMatrix, u Vector) {
@@ -360,6 +413,58 @@ func SpMatAddMatC(C *CCMatrixC, R *CCMatrix, α, β, γ float64, a *CCMatrix, μ
 // triplet-triplet ----------------------------------------------------------------------------------
 // --------------------------------------------------------------------------------------------------
 
+// SpMatMatMul computes the product of two sparse matrices in column-compressed format:
+//  c := a * b    c_ij = a_ik * b_kj
+//  NOTE: the columns of c are computed one at a time with a dense accumulator (Gustavson's
+//        algorithm); thus, the cost is proportional to the number of multiplications and the
+//        number of columns. The row indices of c are sorted and entries are not dropped
+func SpMatMatMul(a, b *CCMatrix) (c *CCMatrix) {
+	if a.n != b.m {
+		chk.Panic("number of columns of 'a' (%dx%d) must be equal to the number of rows of 'b' (%dx%d)", a.m, a.n, b.m, b.n)
+	}
+	c = new(CCMatrix)
+	c.m, c.n = a.m, b.n
+	c.p = make([]int, c.n+1)
+	acc := make([]float64, a.m) // dense accumulator of column j
+	mark := make([]int, a.m)    // mark[i] == j+1 if row i is in column j of c
+	var rows []int
+	for j := 0; j < b.n; j++ {
+		rows = rows[:0]
+		for kb := b.p[j]; kb < b.p[j+1]; kb++ {
+			k, bkj := b.i[kb], b.x[kb]
+			for ka := a.p[k]; ka < a.p[k+1]; ka++ {
+				i := a.i[ka]
+				if mark[i] != j+1 {
+					mark[i] = j + 1
+					acc[i] = 0
+					rows = append(rows, i)
+				}
+				acc[i] += a.x[ka] * bkj
+			}
+		}
+		sort.Ints(rows)
+		for _, i := range rows {
+			c.i = append(c.i, i)
+			c.x = append(c.x, acc[i])
+		}
+		c.p[j+1] = len(c.i)
+	}
+	c.nnz = len(c.i)
+	return
+}
+
+// GalerkinProduct computes the Galerkin (triple) product of sparse matrices; e.g. the coarse-grid
+// operator of (algebraic) multigrid methods with the restriction r and the prolongation p:
+//  c := r * a * p
+//  NOTE: the product a * p is computed first; i.e. the intermediate matrix has the (smaller)
+//        number of columns of p. See SpMatMatMul
+func GalerkinProduct(r, a, p *CCMatrix) (c *CCMatrix) {
+	if a.m != a.n || r.n != a.m || p.m != a.n {
+		chk.Panic("matrices r (%dx%d), a (%dx%d) and p (%dx%d) are not compatible; a must be square", r.m, r.n, a.m, a.n, p.m, p.n)
+	}
+	return SpMatMatMul(r, SpMatMatMul(a, p))
+}
+
 // SpTriAdd adds two matrices in Triplet format:
 //   c := α*a + β*b
 //   NOTE: the output 'c' triplet must be able to hold all nonzeros of 'a' and 'b'
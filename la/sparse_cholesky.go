@@ -0,0 +1,184 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+// SpCholesky holds the sparse Cholesky factorisation of a symmetric positive-definite matrix
+//
+//   A = L ⋅ Lᵀ
+//
+//   The factor L is computed once (see CholeskyFactor) and can then be used to solve many systems
+//   with different right-hand sides. The factor is stored in column-compressed form with the
+//   diagonal entry at the beginning of each column
+type SpCholesky struct {
+	n      int       // dimension
+	lp, li []int     // pointers and row indices of L (len(lp)=n+1, len(li)=nnz(L))
+	lx     []float64 // values of L
+}
+
+// CholeskyFactor computes the sparse Cholesky factorisation of a symmetric positive-definite matrix
+//
+//   The up-looking algorithm is used: the rows of L are computed one after another using the
+//   elimination tree of A to find their non-zero patterns (see T. A. Davis, Direct Methods for
+//   Sparse Linear Systems, SIAM, 2006)
+//
+//   Input:
+//     a -- symmetric positive-definite matrix; only the upper triangle (including the diagonal) is
+//          used; thus, the full matrix (or just its upper triangle) may be given
+//   Output:
+//     o -- factorisation; see Solve and Nnz
+//
+//   NOTE: no fill-reducing ordering is applied; see ReverseCuthillMcKee and SpPermute
+func CholeskyFactor(a *CCMatrix) (o *SpCholesky) {
+
+	// check
+	if a.m != a.n {
+		chk.Panic("matrix must be square. %d != %d\n", a.m, a.n)
+	}
+
+	// elimination tree and column counts of L
+	n := a.n
+	parent := cholEtree(a)
+	counts := make([]int, n)
+	stack := make([]int, n)
+	mark := make([]int, n)
+	for k := 0; k < n; k++ {
+		counts[k]++ // diagonal
+		for top := cholReach(a, k, parent, stack, mark); top < n; top++ {
+			counts[stack[top]]++
+		}
+	}
+
+	// allocate L
+	o = new(SpCholesky)
+	o.n = n
+	o.lp = make([]int, n+1)
+	for k := 0; k < n; k++ {
+		o.lp[k+1] = o.lp[k] + counts[k]
+	}
+	o.li = make([]int, o.lp[n])
+	o.lx = make([]float64, o.lp[n])
+
+	// compute rows of L
+	next := make([]int, n) // next free position in each column
+	copy(next, o.lp[:n])
+	x := make([]float64, n) // dense workspace with row k of L
+	for i := 0; i < n; i++ {
+		mark[i] = 0
+	}
+	for k := 0; k < n; k++ {
+		top := cholReach(a, k, parent, stack, mark)
+		d := 0.0
+		for p := a.p[k]; p < a.p[k+1]; p++ {
+			if i := a.i[p]; i < k {
+				x[i] += a.x[p]
+			} else if i == k {
+				d += a.x[p]
+			}
+		}
+		for ; top < n; top++ {
+			i := stack[top]
+			lki := x[i] / o.lx[o.lp[i]]
+			x[i] = 0
+			for p := o.lp[i] + 1; p < next[i]; p++ {
+				x[o.li[p]] -= o.lx[p] * lki
+			}
+			d -= lki * lki
+			o.li[next[i]] = k
+			o.lx[next[i]] = lki
+			next[i]++
+		}
+		if d <= 0 {
+			chk.Panic("%v\n", &NotPosDefError{Pivot: k, Value: d})
+		}
+		o.li[next[k]] = k
+		o.lx[next[k]] = math.Sqrt(d)
+		next[k]++
+	}
+	return
+}
+
+// Solve solves A⋅x = b using the factorisation
+//   NOTE: x and b may be the same vector
+func (o *SpCholesky) Solve(x, b Vector) {
+	if len(x) != o.n || len(b) != o.n {
+		chk.Panic("sizes of vectors must be equal to %d. len(x)=%d, len(b)=%d\n", o.n, len(x), len(b))
+	}
+	copy(x, b)
+	for j := 0; j < o.n; j++ { // L⋅y = b
+		x[j] /= o.lx[o.lp[j]]
+		for p := o.lp[j] + 1; p < o.lp[j+1]; p++ {
+			x[o.li[p]] -= o.lx[p] * x[j]
+		}
+	}
+	for j := o.n - 1; j >= 0; j-- { // Lᵀ⋅x = y
+		for p := o.lp[j] + 1; p < o.lp[j+1]; p++ {
+			x[j] -= o.lx[p] * x[o.li[p]]
+		}
+		x[j] /= o.lx[o.lp[j]]
+	}
+}
+
+// Nnz returns the number of non-zeros in L (including the diagonal); i.e. nnz(tril(A)) plus fill-in
+func (o *SpCholesky) Nnz() int {
+	return o.lp[o.n]
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// cholEtree computes the elimination tree of a symmetric matrix using its upper triangle
+//   parent[i] = -1 indicates a root
+func cholEtree(a *CCMatrix) (parent []int) {
+	n := a.n
+	parent = make([]int, n)
+	ancestor := make([]int, n)
+	for k := 0; k < n; k++ {
+		parent[k] = -1
+		ancestor[k] = -1
+		for p := a.p[k]; p < a.p[k+1]; p++ {
+			for i := a.i[p]; i != -1 && i < k; {
+				inext := ancestor[i]
+				ancestor[i] = k
+				if inext == -1 {
+					parent[i] = k
+				}
+				i = inext
+			}
+		}
+	}
+	return
+}
+
+// cholReach computes the non-zero pattern of row k of L (excluding the diagonal), which is
+// stored in stack[top:n] in topological order. mark must be zero except for k-values of
+// previous steps (mark[i] == k+1 indicates that i has been visited in step k)
+func cholReach(a *CCMatrix, k int, parent, stack, mark []int) (top int) {
+	n := a.n
+	top = n
+	mark[k] = k + 1
+	for p := a.p[k]; p < a.p[k+1]; p++ {
+		i := a.i[p]
+		if i > k {
+			continue
+		}
+		length := 0
+		for ; mark[i] != k+1; i = parent[i] { // path up the tree
+			stack[length] = i
+			length++
+			mark[i] = k + 1
+		}
+		for length > 0 { // push path onto stack
+			top--
+			length--
+			stack[top] = stack[length]
+		}
+	}
+	return
+}
@@ -0,0 +1,200 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/utl"
+)
+
+// EigGenSymmShiftInvert finds the eigenvalues of the symmetric generalised eigenvalue problem
+// closest to a target σ (interior eigenvalues) using the shift-invert mode
+//
+//   A ⋅ x = λ ⋅ M ⋅ x    ⇒    (A - σ⋅M)⁻¹ ⋅ M ⋅ x = μ ⋅ x    with    μ = 1 / (λ - σ)
+//
+//   The matrix A - σ⋅M is factorised once (umfpack) and the eigenvalues λ closest to σ, which
+//   correspond to the largest |μ|, are found by subspace iteration with the Rayleigh-Ritz
+//   procedure in the M-inner product. The size of the subspace is max(2⋅nev, nev+8)
+//
+//   Input:
+//     A   -- [n][n] symmetric matrix
+//     M   -- [n][n] symmetric positive-definite matrix [may be nil ⇒ identity]
+//     σ   -- target (shift); must not be an eigenvalue
+//     nev -- number of eigenvalues
+//   Output:
+//     λ -- [nev] eigenvalues closest to σ in ascending order
+//     X -- [n][nev] eigenvectors (columns) normalised such that Xᵀ⋅M⋅X = I
+//
+//   NOTE: panics if the residuals ‖A⋅x - λ⋅M⋅x‖ are not reduced below 1e-10⋅‖A⋅x‖ after 500
+//         iterations; e.g. if there are many eigenvalues at similar distances from σ
+func EigGenSymmShiftInvert(A, M *Triplet, σ float64, nev int) (λ Vector, X *Matrix) {
+
+	// check
+	n := A.m
+	if A.n != n || (M != nil && (M.m != n || M.n != n)) {
+		chk.Panic("matrices must be square with the same dimensions\n")
+	}
+	if nev < 1 || nev > n {
+		chk.Panic("number of eigenvalues must be in [1, %d]. nev=%d is invalid\n", n, nev)
+	}
+
+	// matrices
+	a := A.ToMatrix(nil)
+	var m *CCMatrix
+	mul := func(y Vector, x Vector) { // y := M⋅x
+		if m == nil {
+			copy(y, x)
+			return
+		}
+		SpMatVecMul(y, 1, m, x)
+	}
+	K := new(Triplet) // A - σ⋅M
+	if M == nil {
+		K.Init(n, n, A.pos+n)
+		for i := 0; i < n; i++ {
+			K.Put(i, i, -σ)
+		}
+	} else {
+		m = M.ToMatrix(nil)
+		K.Init(n, n, A.pos+M.pos)
+		for k := 0; k < M.pos; k++ {
+			K.Put(M.i[k], M.j[k], -σ*M.x[k])
+		}
+	}
+	for k := 0; k < A.pos; k++ {
+		K.Put(A.i[k], A.j[k], A.x[k])
+	}
+	solver := NewSparseSolver("umfpack")
+	defer solver.Free()
+	solver.Init(K, false, false, "", "", nil)
+	solver.Fact()
+
+	// initial subspace
+	p := utl.Imin(n, utl.Imax(2*nev, nev+8))
+	rnd := rand.New(rand.NewSource(1234))
+	Q := make([]Vector, p)
+	for j := 0; j < p; j++ {
+		Q[j] = NewVector(n)
+		for i := 0; i < n; i++ {
+			Q[j][i] = rnd.Float64() - 0.5
+		}
+	}
+
+	// iterations
+	Y := make([]Vector, p)
+	for j := 0; j < p; j++ {
+		Y[j] = NewVector(n)
+	}
+	mx, ax := NewVector(n), NewVector(n)
+	ritz := NewVector(p)
+	Z := NewMatrix(p, p)
+	Ab := NewMatrix(p, p)
+	for it := 0; it < 500; it++ {
+
+		// Y := (A - σ⋅M)⁻¹ ⋅ M ⋅ Q and M-orthonormalisation
+		for j := 0; j < p; j++ {
+			mul(mx, Q[j])
+			solver.Solve(Y[j], mx, false)
+		}
+		eigMorthonormalise(Y, mul, mx, rnd)
+
+		// Rayleigh-Ritz: Ā = Yᵀ⋅A⋅Y with Yᵀ⋅M⋅Y = I
+		for j := 0; j < p; j++ {
+			SpMatVecMul(ax, 1, a, Y[j])
+			for i := 0; i <= j; i++ {
+				v := VecDot(Y[i], ax)
+				Ab.Set(i, j, v)
+				Ab.Set(j, i, v)
+			}
+		}
+		scale := Ab.Largest(1) // Jacobi uses an absolute tolerance
+		for k := range Ab.Data {
+			Ab.Data[k] /= scale
+		}
+		Jacobi(Z, ritz, Ab)
+		ritz.Apply(scale, ritz)
+
+		// sort by distance to σ and update subspace
+		idx := utl.IntRange(p)
+		sort.Slice(idx, func(r, s int) bool { return math.Abs(ritz[idx[r]]-σ) < math.Abs(ritz[idx[s]]-σ) })
+		for j := 0; j < p; j++ {
+			Q[j].Fill(0)
+			for k := 0; k < p; k++ {
+				z := Z.Get(k, idx[j])
+				for i := 0; i < n; i++ {
+					Q[j][i] += z * Y[k][i]
+				}
+			}
+		}
+
+		// check convergence
+		converged := true
+		for j := 0; j < nev && converged; j++ {
+			l := ritz[idx[j]]
+			SpMatVecMul(ax, 1, a, Q[j])
+			mul(mx, Q[j])
+			res := 0.0
+			for i := 0; i < n; i++ {
+				res += (ax[i] - l*mx[i]) * (ax[i] - l*mx[i])
+			}
+			converged = math.Sqrt(res) <= 1e-10*(ax.Norm()+math.Abs(l)*mx.Norm())
+		}
+		if converged {
+			order := utl.IntRange(nev) // columns of Q (sorted by distance) in ascending order of λ
+			sort.Slice(order, func(r, s int) bool { return ritz[idx[order[r]]] < ritz[idx[order[s]]] })
+			λ = NewVector(nev)
+			X = NewMatrix(n, nev)
+			for c, j := range order {
+				λ[c] = ritz[idx[j]]
+				for i := 0; i < n; i++ {
+					X.Set(i, c, Q[j][i])
+				}
+			}
+			return
+		}
+	}
+	chk.Panic("shift-invert subspace iteration did not converge after 500 iterations\n")
+	return
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// eigMorthonormalise orthonormalises the vectors Y with respect to the M-inner product using the
+// modified Gram-Schmidt method (twice). Dependent vectors are replaced by random vectors
+//   mul -- computes y := M⋅x
+//   mx  -- workspace
+func eigMorthonormalise(Y []Vector, mul func(y, x Vector), mx Vector, rnd *rand.Rand) {
+	for j := range Y {
+		for trial := 0; ; trial++ {
+			mul(mx, Y[j])
+			norm0 := math.Sqrt(VecDot(Y[j], mx))
+			for pass := 0; pass < 2; pass++ {
+				for i := 0; i < j; i++ {
+					mul(mx, Y[i])
+					d := VecDot(Y[j], mx)
+					for k := range Y[j] {
+						Y[j][k] -= d * Y[i][k]
+					}
+				}
+			}
+			mul(mx, Y[j])
+			norm := math.Sqrt(VecDot(Y[j], mx))
+			if norm > 1e-10*norm0 && norm > 0 {
+				Y[j].Apply(1/norm, Y[j])
+				break
+			}
+			if trial > 10 {
+				chk.Panic("cannot orthonormalise subspace\n")
+			}
+			for i := range Y[j] {
+				Y[j][i] = rnd.Float64() - 0.5
+			}
+		}
+	}
+}
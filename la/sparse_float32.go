@@ -0,0 +1,277 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+// Triplet32 is a simple representation of a sparse matrix in single precision, where the indices
+// and values of this matrix are stored directly. The indices are stored as int32 as well; thus,
+// the memory is half of the memory of Triplet
+type Triplet32 struct {
+	m, n     int       // matrix dimension (rows, columns)
+	pos, max int       // current position and max number of entries allowed (non-zeros, including repetitions)
+	i, j     []int32   // indices for each x values (size=max)
+	x        []float32 // values for each i, j (size=max)
+}
+
+// CCMatrix32 represents a sparse matrix in single precision using the "column-compressed format"
+type CCMatrix32 struct {
+	m, n int       // matrix dimension (rows, columns)
+	nnz  int       // number of non-zeros
+	p, i []int32   // pointers and row indices (len(p)=n+1, len(i)=nnz)
+	x    []float32 // values (len(x)=nnz)
+}
+
+// NewTriplet32 returns a new Triplet32
+func NewTriplet32(m, n, max int) (o *Triplet32) {
+	if m > math.MaxInt32 || n > math.MaxInt32 {
+		chk.Panic("dimensions of matrix must not exceed %d. m=%d, n=%d\n", math.MaxInt32, m, n)
+	}
+	o = new(Triplet32)
+	o.m, o.n, o.pos, o.max = m, n, 0, max
+	o.i = make([]int32, max)
+	o.j = make([]int32, max)
+	o.x = make([]float32, max)
+	return
+}
+
+// Put inserts an element to a pre-allocated triplet matrix
+func (o *Triplet32) Put(i, j int, x float32) {
+	if o.pos >= o.max {
+		chk.Panic("cannot put item because max number of items has been exceeded (pos = %d, max = %d)", o.pos, o.max)
+	}
+	o.i[o.pos], o.j[o.pos], o.x[o.pos] = int32(i), int32(j), x
+	o.pos++
+}
+
+// Start (re)starts index for inserting items using the Put command
+func (o *Triplet32) Start() {
+	o.pos = 0
+}
+
+// Len returns the number of items just inserted in the triplet
+func (o *Triplet32) Len() int {
+	return o.pos
+}
+
+// ToMatrix32 converts the triplet to column-compressed form. Duplicates are summed and, within
+// each column, the rows appear in the order they were first put into the triplet
+func (o *Triplet32) ToMatrix32() (a *CCMatrix32) {
+	if o.pos < 1 {
+		chk.Panic("conversion can only be made for non-empty triplets. error: (pos = %d)", o.pos)
+	}
+
+	// count entries per column (with duplicates)
+	a = new(CCMatrix32)
+	a.m, a.n = o.m, o.n
+	start := make([]int, o.n+1)
+	for k := 0; k < o.pos; k++ {
+		start[o.j[k]+1]++
+	}
+	for j := 0; j < o.n; j++ {
+		start[j+1] += start[j]
+	}
+
+	// fill columns, summing duplicates
+	ii := make([]int32, o.pos)
+	xx := make([]float32, o.pos)
+	cnt := make([]int, o.n)  // number of unique entries in each column
+	last := make([]int, o.m) // position of row i in current column
+	for r := 0; r < o.m; r++ {
+		last[r] = -1
+	}
+	order := make([]int, o.pos) // triplet entries sorted by column (stable)
+	pos := make([]int, o.n)
+	copy(pos, start[:o.n])
+	for k := 0; k < o.pos; k++ {
+		order[pos[o.j[k]]] = k
+		pos[o.j[k]]++
+	}
+	for j := 0; j < o.n; j++ {
+		for q := start[j]; q < start[j+1]; q++ {
+			k := order[q]
+			r := o.i[k]
+			if last[r] >= start[j] {
+				xx[last[r]] += o.x[k]
+				continue
+			}
+			last[r] = start[j] + cnt[j]
+			ii[last[r]] = r
+			xx[last[r]] = o.x[k]
+			cnt[j]++
+		}
+	}
+
+	// compress
+	a.p = make([]int32, o.n+1)
+	for j := 0; j < o.n; j++ {
+		a.p[j+1] = a.p[j] + int32(cnt[j])
+	}
+	a.nnz = int(a.p[o.n])
+	a.i = make([]int32, a.nnz)
+	a.x = make([]float32, a.nnz)
+	for j := 0; j < o.n; j++ {
+		copy(a.i[a.p[j]:a.p[j+1]], ii[start[j]:start[j]+cnt[j]])
+		copy(a.x[a.p[j]:a.p[j+1]], xx[start[j]:start[j]+cnt[j]])
+	}
+	return
+}
+
+// ToDense converts a column-compressed matrix (single precision) to dense form (double precision)
+func (o *CCMatrix32) ToDense() (res *Matrix) {
+	res = NewMatrix(o.m, o.n)
+	for j := 0; j < o.n; j++ {
+		for p := o.p[j]; p < o.p[j+1]; p++ {
+			res.Add(int(o.i[p]), j, float64(o.x[p]))
+		}
+	}
+	return
+}
+
+// Bytes returns the memory used by the pointers, indices and values (see CCMatrix.Bytes)
+func (o *CCMatrix32) Bytes() int {
+	return 4*len(o.p) + 8*o.nnz
+}
+
+// SpMatVecMul32 returns the (sparse) matrix-vector multiplication (scaled/single precision):
+//  v := α * a * u  =>  vi = α * aij * uj
+//  NOTE: dense vector v will be first initialised with zeros
+func SpMatVecMul32(v []float32, α float32, a *CCMatrix32, u []float32) {
+	for i := range v {
+		v[i] = 0
+	}
+	SpMatVecMulAdd32(v, α, a, u)
+}
+
+// SpMatVecMulAdd32 returns the (sparse) matrix-vector multiplication with addition (scaled/single precision):
+//  v += α * a * u  =>  vi += α * aij * uj
+func SpMatVecMulAdd32(v []float32, α float32, a *CCMatrix32, u []float32) {
+	for j := 0; j < a.n; j++ {
+		for k := a.p[j]; k < a.p[j+1]; k++ {
+			v[a.i[k]] += α * a.x[k] * u[j]
+		}
+	}
+}
+
+// SpBiCGStab32 solves a⋅x = b in single precision using the BiCGStab method with the Jacobi
+// (diagonal) preconditioner; e.g. for large systems on memory-constrained machines
+//
+//   Input:
+//    x     -- initial values of x
+//    a     -- square matrix with non-zero diagonal; a may be non-symmetric
+//    b     -- right-hand side vector
+//    tol   -- tolerance on the residual norm relative to the norm of b: ‖b - a⋅x‖ ≤ tol⋅‖b‖
+//    maxIt -- maximum number of iterations
+//   Output:
+//    x   -- the solution
+//    nit -- number of iterations performed
+//
+//   NOTE: (1) the vectors are stored in single precision but the dot products and norms are
+//             accumulated in double precision
+//         (2) the recursive residual is used in the stopping criterion; thus, tol should not be
+//             smaller than about 1e-6 (single precision) times the condition number of a
+func SpBiCGStab32(x []float32, a *CCMatrix32, b []float32, tol float64, maxIt int) (nit int) {
+
+	// check
+	if a.m != a.n {
+		chk.Panic("matrix must be square. %d != %d\n", a.m, a.n)
+	}
+	if len(x) != a.n || len(b) != a.n {
+		chk.Panic("vectors must have length equal to %d. len(x)=%d, len(b)=%d\n", a.n, len(x), len(b))
+	}
+
+	// auxiliary
+	n := a.n
+	dot := func(u, v []float32) (res float64) {
+		for i := range u {
+			res += float64(u[i]) * float64(v[i])
+		}
+		return
+	}
+	norm := func(u []float32) float64 { return math.Sqrt(dot(u, u)) }
+	dinv := make([]float32, n)
+	for j := 0; j < n; j++ {
+		for k := a.p[j]; k < a.p[j+1]; k++ {
+			if int(a.i[k]) == j {
+				dinv[j] += a.x[k]
+			}
+		}
+		if dinv[j] == 0 {
+			chk.Panic("diagonal entry %d is zero\n", j)
+		}
+		dinv[j] = 1 / dinv[j]
+	}
+	precond := func(z, r []float32) {
+		for i := range r {
+			z[i] = dinv[i] * r[i]
+		}
+	}
+
+	// initial residual: r = b - a⋅x
+	r := make([]float32, n)
+	copy(r, b)
+	SpMatVecMulAdd32(r, -1, a, x)
+	bnorm := norm(b)
+	if bnorm == 0 {
+		bnorm = 1
+	}
+	if norm(r) <= tol*bnorm {
+		return
+	}
+
+	// iterations
+	rhat := make([]float32, n)
+	copy(rhat, r)
+	p := make([]float32, n)
+	v := make([]float32, n)
+	y := make([]float32, n)
+	s := make([]float32, n)
+	z := make([]float32, n)
+	t := make([]float32, n)
+	ρ, α, ω := 1.0, 1.0, 1.0
+	for nit = 1; nit <= maxIt; nit++ {
+		ρnew := dot(rhat, r)
+		if ρnew == 0 {
+			chk.Panic("BiCGStab broke down at iteration %d (ρ = 0)\n", nit)
+		}
+		β := (ρnew / ρ) * (α / ω)
+		for i := 0; i < n; i++ {
+			p[i] = r[i] + float32(β)*(p[i]-float32(ω)*v[i])
+		}
+		precond(y, p)
+		SpMatVecMul32(v, 1, a, y)
+		α = ρnew / dot(rhat, v)
+		for i := 0; i < n; i++ {
+			s[i] = r[i] - float32(α)*v[i]
+		}
+		if norm(s) <= tol*bnorm {
+			for i := 0; i < n; i++ {
+				x[i] += float32(α) * y[i]
+			}
+			return
+		}
+		precond(z, s)
+		SpMatVecMul32(t, 1, a, z)
+		tt := dot(t, t)
+		if tt == 0 {
+			chk.Panic("BiCGStab broke down at iteration %d (‖t‖ = 0)\n", nit)
+		}
+		ω = dot(t, s) / tt
+		for i := 0; i < n; i++ {
+			x[i] += float32(α)*y[i] + float32(ω)*z[i]
+			r[i] = s[i] - float32(ω)*t[i]
+		}
+		if norm(r) <= tol*bnorm {
+			return
+		}
+		ρ = ρnew
+	}
+	chk.Panic("BiCGStab did not converge after %d iterations\n", maxIt)
+	return
+}
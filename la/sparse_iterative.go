@@ -0,0 +1,437 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+// SpProjGaussSeidel solves the linear complementarity problem defined by a (sparse) matrix and
+// a lower bound using the projected Gauss-Seidel (or SOR) method:
+//
+//   Find x such that:   x ≥ lower   and   a⋅x - b ≥ 0   with   (x - lower)ᵀ⋅(a⋅x - b) = 0
+//
+//   Iterations:
+//                                        bi - Σ_{j≠i} aij⋅xj
+//     xi := max( lower_i , (1-ω)⋅xi + ω ⋅ ——————————————————— )
+//                                               aii
+//
+//   Input:
+//    x     -- initial values of x [will be projected onto the lower bound]
+//    a     -- matrix with non-zero diagonal (e.g. an M-matrix)
+//    b     -- right-hand side vector
+//    lower -- lower bound
+//    ω     -- relaxation factor; ω = 1 gives Gauss-Seidel and 1 < ω < 2 gives SOR
+//    tol   -- tolerance on the maximum change of x between iterations
+//    maxIt -- maximum number of iterations
+//   Output:
+//    x   -- the solution
+//    nit -- number of iterations performed
+//
+//   NOTE: the iterations do not change if both a and b are multiplied by -1; thus, the conditions
+//         above hold for the system scaled such that the diagonal of a is positive. For example,
+//         a may be the (negative definite) discrete Laplacian
+//
+func SpProjGaussSeidel(x Vector, a *CCMatrix, b, lower Vector, ω, tol float64, maxIt int) (nit int) {
+
+	// check
+	if a.m != a.n {
+		chk.Panic("matrix must be square. %d != %d\n", a.m, a.n)
+	}
+	if len(x) != a.n || len(b) != a.n || len(lower) != a.n {
+		chk.Panic("vectors must have length equal to %d. len(x)=%d, len(b)=%d, len(lower)=%d\n", a.n, len(x), len(b), len(lower))
+	}
+
+	// row-compressed structure and diagonal
+	rp, rj, rx := spRowCompressed(a)
+	diag := spDiagonal(a.m, rp, rj, rx)
+
+	// initial values
+	for i := 0; i < a.m; i++ {
+		x[i] = math.Max(x[i], lower[i])
+	}
+
+	// iterations
+	var sum, xnew, δ float64
+	for nit = 1; nit <= maxIt; nit++ {
+		δ = 0
+		for i := 0; i < a.m; i++ {
+			sum = b[i]
+			for k := rp[i]; k < rp[i+1]; k++ {
+				if rj[k] != i {
+					sum -= rx[k] * x[rj[k]]
+				}
+			}
+			xnew = math.Max(lower[i], x[i]+ω*(sum/diag[i]-x[i]))
+			δ = math.Max(δ, math.Abs(xnew-x[i]))
+			x[i] = xnew
+		}
+		if δ < tol {
+			return
+		}
+	}
+	chk.Panic("projected Gauss-Seidel did not converge after %d iterations\n", maxIt)
+	return
+}
+
+// Preconditioner defines preconditioners M for iterative solvers; e.g. ConjGrad
+//
+//   Apply computes:  z := M⁻¹ ⋅ r
+//
+type Preconditioner interface {
+	Apply(z, r Vector)
+}
+
+// IterMonitor is called by iterative solvers after each iteration; e.g. to record the history of
+// residuals (see ConjGradMonitor and GmresMonitor)
+//   nit      -- iteration number: 1, 2, ...
+//   residual -- residual norm relative to the norm of b: ‖b - a⋅x‖/‖b‖
+type IterMonitor func(nit int, residual float64)
+
+// ConjGrad solves a⋅x = b using the (preconditioned) conjugate gradient method
+//
+//   Input:
+//    x     -- initial values of x; i.e. the initial guess (not reset); e.g. the solution of the
+//             previous step of a transient or nonlinear analysis (warm start)
+//    a     -- symmetric and definite matrix (e.g. negative definite discrete Laplacian)
+//    b     -- right-hand side vector
+//    pc    -- preconditioner [may be nil]; must be symmetric with the same definiteness as a
+//    tol   -- tolerance on the residual norm relative to the norm of b: ‖b - a⋅x‖ ≤ tol⋅‖b‖
+//    maxIt -- maximum number of iterations
+//   Output:
+//    x   -- the solution
+//    nit -- number of iterations performed
+//
+func ConjGrad(x Vector, a *CCMatrix, b Vector, pc Preconditioner, tol float64, maxIt int) (nit int) {
+	return ConjGradMonitor(x, a, b, pc, tol, maxIt, nil)
+}
+
+// ConjGradMonitor solves a⋅x = b using the (preconditioned) conjugate gradient method and calls
+// monitor after each iteration (see ConjGrad)
+//   monitor -- called with the norm of the (recursively updated) residual [may be nil]
+func ConjGradMonitor(x Vector, a *CCMatrix, b Vector, pc Preconditioner, tol float64, maxIt int, monitor IterMonitor) (nit int) {
+
+	// check
+	if a.m != a.n {
+		chk.Panic("matrix must be square. %d != %d\n", a.m, a.n)
+	}
+	if len(x) != a.n || len(b) != a.n {
+		chk.Panic("vectors must have length equal to %d. len(x)=%d, len(b)=%d\n", a.n, len(x), len(b))
+	}
+
+	// initial residual: r = b - a⋅x
+	n := a.n
+	r := NewVector(n)
+	z := NewVector(n)
+	p := NewVector(n)
+	q := NewVector(n)
+	copy(r, b)
+	SpMatVecMulAdd(r, -1, a, x)
+	bnorm := b.Norm()
+	if bnorm == 0 {
+		bnorm = 1
+	}
+	if r.Norm() <= tol*bnorm {
+		return
+	}
+
+	// iterations
+	var α, β, ρ, ρold float64
+	for nit = 1; nit <= maxIt; nit++ {
+		if pc == nil {
+			copy(z, r)
+		} else {
+			pc.Apply(z, r)
+		}
+		ρ = VecDot(r, z)
+		if nit == 1 {
+			copy(p, z)
+		} else {
+			β = ρ / ρold
+			Scal(β, p)
+			Axpy(1, z, p)
+		}
+		SpMatVecMul(q, 1, a, p)
+		α = ρ / VecDot(p, q)
+		Axpy(α, p, x)
+		Axpy(-α, q, r)
+		rnorm := r.Norm()
+		if monitor != nil {
+			monitor(nit, rnorm/bnorm)
+		}
+		if rnorm <= tol*bnorm {
+			return
+		}
+		ρold = ρ
+	}
+	chk.Panic("conjugate gradient did not converge after %d iterations\n", maxIt)
+	return
+}
+
+// PrecondSSOR implements the symmetric successive over-relaxation (SSOR) preconditioner
+//
+//   Given a = L + D + U (strictly lower, diagonal and strictly upper parts):
+//
+//             1
+//   M = ——————————— ⋅ (D + ω⋅L) ⋅ D⁻¹ ⋅ (D + ω⋅U)
+//        ω ⋅ (2-ω)
+//
+//   Thus, z = M⁻¹⋅r is computed with one forward and one backward Gauss-Seidel (SOR) sweep.
+//   If a is symmetric, M is symmetric and can be used with ConjGrad. 0 < ω < 2
+type PrecondSSOR struct {
+	ω    float64   // relaxation factor
+	n    int       // dimension
+	rp   []int     // [n+1] row pointers
+	rj   []int     // [nnz] column indices
+	rx   []float64 // [nnz] values
+	diag []float64 // [n] diagonal
+}
+
+// NewPrecondSSOR returns a new SSOR preconditioner for matrix a with relaxation factor ω
+func NewPrecondSSOR(a *CCMatrix, ω float64) (o *PrecondSSOR) {
+	if a.m != a.n {
+		chk.Panic("matrix must be square. %d != %d\n", a.m, a.n)
+	}
+	if ω <= 0 || ω >= 2 {
+		chk.Panic("relaxation factor must be in (0, 2). ω = %g is invalid\n", ω)
+	}
+	o = new(PrecondSSOR)
+	o.ω = ω
+	o.n = a.n
+	o.rp, o.rj, o.rx = spRowCompressed(a)
+	o.diag = spDiagonal(a.m, o.rp, o.rj, o.rx)
+	return
+}
+
+// Apply computes z := M⁻¹ ⋅ r
+func (o *PrecondSSOR) Apply(z, r Vector) {
+
+	// forward sweep: (D + ω⋅L)⋅y = r
+	var sum float64
+	for i := 0; i < o.n; i++ {
+		sum = r[i]
+		for k := o.rp[i]; k < o.rp[i+1]; k++ {
+			if o.rj[k] < i {
+				sum -= o.ω * o.rx[k] * z[o.rj[k]]
+			}
+		}
+		z[i] = sum / o.diag[i]
+	}
+
+	// backward sweep: (D + ω⋅U)⋅z = ω⋅(2-ω)⋅D⋅y
+	c := o.ω * (2.0 - o.ω)
+	for i := o.n - 1; i >= 0; i-- {
+		sum = c * o.diag[i] * z[i]
+		for k := o.rp[i]; k < o.rp[i+1]; k++ {
+			if o.rj[k] > i {
+				sum -= o.ω * o.rx[k] * z[o.rj[k]]
+			}
+		}
+		z[i] = sum / o.diag[i]
+	}
+}
+
+// Gmres solves a⋅x = b using the restarted generalised minimal residual method GMRES(m) with
+// right preconditioning; e.g. for non-symmetric matrices
+//
+//   a⋅M⁻¹⋅y = b   with   x = M⁻¹⋅y
+//
+//   Thus, the residual minimised by the method is the true residual b - a⋅x
+//
+//   Input:
+//    x       -- initial values of x; i.e. the initial guess (not reset; see ConjGrad)
+//    a       -- square matrix
+//    b       -- right-hand side vector
+//    pc      -- preconditioner [may be nil]
+//    tol     -- tolerance on the residual norm relative to the norm of b: ‖b - a⋅x‖ ≤ tol⋅‖b‖
+//    maxIt   -- maximum number of iterations (total number of matrix-vector products)
+//    restart -- number of iterations m before restarting; i.e. the dimension of the Krylov subspace
+//   Output:
+//    x   -- the solution
+//    nit -- number of iterations performed
+//
+func Gmres(x Vector, a *CCMatrix, b Vector, pc Preconditioner, tol float64, maxIt, restart int) (nit int) {
+	return GmresMonitor(x, a, b, pc, tol, maxIt, restart, nil)
+}
+
+// GmresMonitor solves a⋅x = b using the restarted GMRES(m) method and calls monitor after each
+// iteration (see Gmres)
+//   monitor -- called with the norm of the residual given by the least-squares problem of the
+//              Arnoldi process (no extra matrix-vector products) [may be nil]
+func GmresMonitor(x Vector, a *CCMatrix, b Vector, pc Preconditioner, tol float64, maxIt, restart int, monitor IterMonitor) (nit int) {
+
+	// check
+	if a.m != a.n {
+		chk.Panic("matrix must be square. %d != %d\n", a.m, a.n)
+	}
+	if len(x) != a.n || len(b) != a.n {
+		chk.Panic("vectors must have length equal to %d. len(x)=%d, len(b)=%d\n", a.n, len(x), len(b))
+	}
+	if restart < 1 {
+		chk.Panic("restart must be at least 1. restart = %d is invalid\n", restart)
+	}
+
+	// workspace
+	n, m := a.n, restart
+	V := make([]Vector, m+1) // orthonormal basis of the Krylov subspace
+	Z := make([]Vector, m)   // preconditioned basis vectors: Z[j] = M⁻¹⋅V[j]
+	for j := 0; j < m; j++ {
+		V[j] = NewVector(n)
+		Z[j] = NewVector(n)
+	}
+	V[m] = NewVector(n)
+	H := NewMatrix(m+1, m) // Hessenberg matrix (rotated into upper triangular form)
+	cs := make([]float64, m)
+	sn := make([]float64, m)
+	g := make([]float64, m+1)
+	y := make([]float64, m)
+	r := NewVector(n)
+	bnorm := b.Norm()
+	if bnorm == 0 {
+		bnorm = 1
+	}
+
+	// cycles
+	for {
+
+		// residual: r = b - a⋅x
+		copy(r, b)
+		SpMatVecMulAdd(r, -1, a, x)
+		β := r.Norm()
+		if β <= tol*bnorm {
+			return
+		}
+		if nit >= maxIt {
+			chk.Panic("GMRES did not converge after %d iterations. residual = %g\n", maxIt, β/bnorm)
+		}
+		for i := 0; i < n; i++ {
+			V[0][i] = r[i] / β
+		}
+		for i := range g {
+			g[i] = 0
+		}
+		g[0] = β
+
+		// Arnoldi process with modified Gram-Schmidt
+		k := 0
+		for j := 0; j < m && nit < maxIt; j++ {
+			nit++
+			k = j + 1
+			if pc == nil {
+				copy(Z[j], V[j])
+			} else {
+				pc.Apply(Z[j], V[j])
+			}
+			w := V[j+1]
+			SpMatVecMul(w, 1, a, Z[j])
+			for i := 0; i <= j; i++ {
+				hij := VecDot(w, V[i])
+				H.Set(i, j, hij)
+				Axpy(-hij, V[i], w)
+			}
+			hnext := w.Norm()
+			H.Set(j+1, j, hnext)
+			if hnext > 0 {
+				Scal(1/hnext, w)
+			}
+
+			// Givens rotations
+			for i := 0; i < j; i++ {
+				hi, hk := H.Get(i, j), H.Get(i+1, j)
+				H.Set(i, j, cs[i]*hi+sn[i]*hk)
+				H.Set(i+1, j, -sn[i]*hi+cs[i]*hk)
+			}
+			hjj := H.Get(j, j)
+			den := math.Hypot(hjj, hnext)
+			cs[j], sn[j] = hjj/den, hnext/den
+			H.Set(j, j, den)
+			H.Set(j+1, j, 0)
+			g[j+1] = -sn[j] * g[j]
+			g[j] = cs[j] * g[j]
+			if monitor != nil {
+				monitor(nit, math.Abs(g[j+1])/bnorm)
+			}
+			if math.Abs(g[j+1]) <= tol*bnorm || hnext == 0 {
+				break
+			}
+		}
+
+		// update: x += Z⋅y with H[0:k][0:k]⋅y = g[0:k]
+		for i := k - 1; i >= 0; i-- {
+			y[i] = g[i]
+			for l := i + 1; l < k; l++ {
+				y[i] -= H.Get(i, l) * y[l]
+			}
+			y[i] /= H.Get(i, i)
+		}
+		for i := 0; i < k; i++ {
+			Axpy(y[i], Z[i], x)
+		}
+	}
+}
+
+// PrecondJacobi implements the Jacobi (diagonal) preconditioner M = D
+type PrecondJacobi struct {
+	diag []float64 // [n] diagonal
+}
+
+// NewPrecondJacobi returns a new Jacobi preconditioner for matrix a
+func NewPrecondJacobi(a *CCMatrix) (o *PrecondJacobi) {
+	if a.m != a.n {
+		chk.Panic("matrix must be square. %d != %d\n", a.m, a.n)
+	}
+	rp, rj, rx := spRowCompressed(a)
+	return &PrecondJacobi{spDiagonal(a.m, rp, rj, rx)}
+}
+
+// Apply computes z := M⁻¹ ⋅ r
+func (o *PrecondJacobi) Apply(z, r Vector) {
+	for i, d := range o.diag {
+		z[i] = r[i] / d
+	}
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// spRowCompressed returns the row-compressed structure of a; i.e. the transpose of the column-compressed data
+func spRowCompressed(a *CCMatrix) (rp, rj []int, rx []float64) {
+	rp = make([]int, a.m+1)
+	for k := 0; k < a.p[a.n]; k++ {
+		rp[a.i[k]+1]++
+	}
+	for i := 0; i < a.m; i++ {
+		rp[i+1] += rp[i]
+	}
+	rj = make([]int, a.p[a.n])
+	rx = make([]float64, a.p[a.n])
+	pos := make([]int, a.m)
+	copy(pos, rp)
+	for j := 0; j < a.n; j++ {
+		for k := a.p[j]; k < a.p[j+1]; k++ {
+			rj[pos[a.i[k]]] = j
+			rx[pos[a.i[k]]] = a.x[k]
+			pos[a.i[k]]++
+		}
+	}
+	return
+}
+
+// spDiagonal returns the diagonal of a row-compressed matrix. Panics if a diagonal term is zero
+func spDiagonal(m int, rp, rj []int, rx []float64) (diag []float64) {
+	diag = make([]float64, m)
+	for i := 0; i < m; i++ {
+		for k := rp[i]; k < rp[i+1]; k++ {
+			if rj[k] == i {
+				diag[i] += rx[k]
+			}
+		}
+		if diag[i] == 0 {
+			chk.Panic("diagonal of matrix must be non-zero. a[%d,%d] = 0\n", i, i)
+		}
+	}
+	return
+}
@@ -6,8 +6,10 @@ package la
 
 import (
 	"bytes"
+	"encoding/binary"
 	"math"
 	"math/cmplx"
+	"sort"
 	"strings"
 
 	"github.com/cpmech/gosl/chk"
@@ -31,6 +33,14 @@ type CCMatrix struct {
 	x    []float64 // values (len(x)=nnz)
 }
 
+// CSRMatrix represents a sparse matrix using the so-called "compressed sparse row (CSR) format".
+type CSRMatrix struct {
+	m, n int       // matrix dimension (rows, columns)
+	nnz  int       // number of non-zeros
+	p, j []int     // pointers and column indices (len(p)=m+1, len(j)=nnz)
+	x    []float64 // values (len(x)=nnz)
+}
+
 // NewTriplet returns a new Triplet. This is a wrapper to new(Triplet) followed by Init()
 func NewTriplet(m, n, max int) (o *Triplet) {
 	o = new(Triplet)
@@ -181,6 +191,158 @@ func (o *CCMatrix) WriteSmat(dirout, fnkey string, tol float64) {
 	io.WriteFileVD(dirout, fnkey+".smat", &bfa, &bfb)
 }
 
+// PETSc file class identifiers (see WritePETScBinary)
+const (
+	petscMatClassID = 1211216 // MAT_FILE_CLASSID
+	petscVecClassID = 1211214 // VEC_FILE_CLASSID
+)
+
+// WritePETScBinary writes a sparse matrix and (optionally) a vector to a file in the binary format
+// of PETSc (MatLoad and VecLoad); e.g. to solve the system with external (parallel) solvers
+//
+//   Matrix (AIJ):  int32 MAT_FILE_CLASSID (1211216), m, n, nnz
+//                  int32 [m]   number of non-zeros in each row
+//                  int32 [nnz] column indices (row by row; sorted within each row)
+//                  float64 [nnz] values
+//   Vector:        int32 VEC_FILE_CLASSID (1211214), n
+//                  float64 [n] values
+//
+//   All numbers are big-endian and the indices are zero-based; thus, the matrix and the vector
+//   are loaded from the same viewer with MatLoad followed by VecLoad (in petsc4py: A.load(viewer)
+//   and b.load(viewer))
+//
+//   filename -- full path to file
+//   a        -- sparse matrix; all stored entries are written (including zeros)
+//   b        -- right-hand side vector [may be nil ⇒ matrix only]
+func WritePETScBinary(filename string, a *CCMatrix, b Vector) {
+
+	// check
+	nnz := a.p[a.n]
+	if nnz > math.MaxInt32 {
+		chk.Panic("number of non-zeros exceeds the 32-bit indices of PETSc. nnz = %d\n", nnz)
+	}
+	if b != nil && len(b) != a.m {
+		chk.Panic("size of vector must be equal to the number of rows of the matrix. %d != %d\n", len(b), a.m)
+	}
+
+	// compressed rows (columns sorted within each row)
+	rowPtr := make([]int, a.m+1)
+	for k := 0; k < nnz; k++ {
+		rowPtr[a.i[k]+1]++
+	}
+	for i := 0; i < a.m; i++ {
+		rowPtr[i+1] += rowPtr[i]
+	}
+	cols := make([]int32, nnz)
+	vals := make([]float64, nnz)
+	pos := make([]int, a.m)
+	copy(pos, rowPtr[:a.m])
+	for j := 0; j < a.n; j++ {
+		for k := a.p[j]; k < a.p[j+1]; k++ {
+			cols[pos[a.i[k]]] = int32(j)
+			vals[pos[a.i[k]]] = a.x[k]
+			pos[a.i[k]]++
+		}
+	}
+	nrow := make([]int32, a.m)
+	for i := 0; i < a.m; i++ {
+		nrow[i] = int32(rowPtr[i+1] - rowPtr[i])
+	}
+
+	// write
+	var buf bytes.Buffer
+	put := func(data interface{}) {
+		binary.Write(&buf, binary.BigEndian, data)
+	}
+	put([]int32{petscMatClassID, int32(a.m), int32(a.n), int32(nnz)})
+	put(nrow)
+	put(cols)
+	put(vals)
+	if b != nil {
+		put([]int32{petscVecClassID, int32(len(b))})
+		put([]float64(b))
+	}
+	io.WriteBytesToFile(filename, buf.Bytes())
+}
+
+// ToCSR converts a sparse matrix in triplet form to compressed-row (CSR) form. Duplicates are summed
+// and, within each row, the columns appear in the order they were first put into the triplet
+func (o *Triplet) ToCSR() (a *CSRMatrix) {
+	if o.pos < 1 {
+		chk.Panic("conversion can only be made for non-empty triplets. error: (pos = %d)", o.pos)
+	}
+
+	// count entries per row (with duplicates)
+	a = new(CSRMatrix)
+	a.m, a.n = o.m, o.n
+	a.p = make([]int, o.m+1)
+	for k := 0; k < o.pos; k++ {
+		a.p[o.i[k]+1]++
+	}
+	for i := 0; i < o.m; i++ {
+		a.p[i+1] += a.p[i]
+	}
+
+	// fill rows, summing duplicates
+	a.j = make([]int, o.pos)
+	a.x = make([]float64, o.pos)
+	cnt := make([]int, o.m)     // number of unique entries in each row
+	last := make([]int, o.n)    // position of column j in current row
+	order := make([]int, o.pos) // triplet entries sorted by row (stable)
+	pos := make([]int, o.m)
+	copy(pos, a.p[:o.m])
+	for k := 0; k < o.pos; k++ {
+		order[pos[o.i[k]]] = k
+		pos[o.i[k]]++
+	}
+	for c := 0; c < o.n; c++ {
+		last[c] = -1
+	}
+	for i := 0; i < o.m; i++ {
+		start := a.p[i]
+		for q := a.p[i]; q < a.p[i+1]; q++ {
+			k := order[q]
+			c := o.j[k]
+			if last[c] >= start {
+				a.x[last[c]] += o.x[k]
+				continue
+			}
+			last[c] = start + cnt[i]
+			a.j[last[c]] = c
+			a.x[last[c]] = o.x[k]
+			cnt[i]++
+		}
+	}
+
+	// compress
+	nnz := 0
+	for i := 0; i < o.m; i++ {
+		start := a.p[i]
+		a.p[i] = nnz
+		for q := start; q < start+cnt[i]; q++ {
+			a.j[nnz] = a.j[q]
+			a.x[nnz] = a.x[q]
+			nnz++
+		}
+	}
+	a.p[o.m] = nnz
+	a.nnz = nnz
+	a.j = a.j[:nnz]
+	a.x = a.x[:nnz]
+	return
+}
+
+// ToDense converts a compressed-row matrix to dense form
+func (o *CSRMatrix) ToDense() (res *Matrix) {
+	res = NewMatrix(o.m, o.n)
+	for i := 0; i < o.m; i++ {
+		for k := o.p[i]; k < o.p[i+1]; k++ {
+			res.Add(i, o.j[k], o.x[k])
+		}
+	}
+	return
+}
+
 // ToDense converts a column-compressed matrix to dense form
 func (o *CCMatrix) ToDense() (res *Matrix) {
 	res = NewMatrix(o.m, o.n)
@@ -192,6 +354,83 @@ func (o *CCMatrix) ToDense() (res *Matrix) {
 	return
 }
 
+// Bytes returns the memory used by the pointers, indices and values; e.g. to compare with
+// CCMatrix32. The unused capacity of the arrays is not counted
+func (o *CCMatrix) Bytes() int {
+	return 8*(o.n+1) + 16*o.p[o.n]
+}
+
+// Triplets returns the entries of the matrix in coordinate (COO) form; e.g. to feed external
+// solvers or to write custom formats
+//   Output:
+//     rows, cols -- [nnz] row and column indices sorted by column and then by row
+//     vals       -- [nnz] values; duplicate entries are summed up (canonical form)
+//   NOTE: explicitly stored zeros are kept
+func (o *CCMatrix) Triplets() (rows, cols []int, vals []float64) {
+	nnz := o.p[o.n]
+	rows = make([]int, 0, nnz)
+	cols = make([]int, 0, nnz)
+	vals = make([]float64, 0, nnz)
+	sum := make([]float64, o.m)
+	used := make([]bool, o.m)
+	var list []int
+	for j := 0; j < o.n; j++ {
+		list = list[:0]
+		for p := o.p[j]; p < o.p[j+1]; p++ {
+			i := o.i[p]
+			if !used[i] {
+				used[i] = true
+				list = append(list, i)
+			}
+			sum[i] += o.x[p]
+		}
+		sort.Ints(list)
+		for _, i := range list {
+			rows = append(rows, i)
+			cols = append(cols, j)
+			vals = append(vals, sum[i])
+			sum[i], used[i] = 0, false
+		}
+	}
+	return
+}
+
+// BandwidthStats computes the bandwidth of the matrix directly from the compressed indices
+//
+//   lower   -- lower bandwidth: max(i - j) for all non-zero entries A[i][j] with i > j
+//   upper   -- upper bandwidth: max(j - i) for all non-zero entries A[i][j] with j > i
+//   average -- average (half) bandwidth: mean over all columns of the largest distance |i - j|
+//              between the non-zero entries of a column and the diagonal
+//
+//   NOTE: explicitly stored zeros are counted as non-zero entries
+func (o *CCMatrix) BandwidthStats() (lower, upper int, average float64) {
+	if o.n == 0 {
+		return
+	}
+	sum := 0
+	for j := 0; j < o.n; j++ {
+		dmax := 0
+		for p := o.p[j]; p < o.p[j+1]; p++ {
+			d := o.i[p] - j
+			if d > lower {
+				lower = d
+			}
+			if -d > upper {
+				upper = -d
+			}
+			if d < 0 {
+				d = -d
+			}
+			if d > dmax {
+				dmax = d
+			}
+		}
+		sum += dmax
+	}
+	average = float64(sum) / float64(o.n)
+	return
+}
+
 // Set sets column-compressed matrix directly
 func (o *CCMatrix) Set(m, n int, Ap, Ai []int, Ax []float64) {
 	if len(Ap)-1 != n {
@@ -208,6 +447,103 @@ func (o *CCMatrix) Set(m, n int, Ap, Ai []int, Ax []float64) {
 	o.p, o.i, o.x = Ap, Ai, Ax
 }
 
+// Clone returns a deep copy of the matrix; i.e. the pointers, indices and values are not shared
+func (o *CCMatrix) Clone() (res *CCMatrix) {
+	res = &CCMatrix{m: o.m, n: o.n, nnz: o.nnz}
+	res.p = append([]int{}, o.p...)
+	res.i = append([]int{}, o.i...)
+	res.x = append([]float64{}, o.x...)
+	return
+}
+
+// ScaleInPlace multiplies all values by alpha: A := alpha⋅A
+func (o *CCMatrix) ScaleInPlace(alpha float64) {
+	for k := range o.x {
+		o.x[k] *= alpha
+	}
+}
+
+// Symmetrize computes the diagonal scaling that symmetrizes a square matrix by the similarity
+// transform S = D⋅A⋅D⁻¹, if possible; e.g. to solve near-symmetric systems, such as some
+// advection-diffusion discretisations, by the conjugate gradient method (see ConjGrad)
+//
+//   S is symmetric if  d_i⋅A_ij/d_j = d_j⋅A_ji/d_i ; i.e.  (d_i/d_j)² = A_ji/A_ij
+//
+//   Thus, D exists if A_ij and A_ji are both zero or have the same sign and if the products of the
+//   ratios A_ji/A_ij around the cycles of the graph of A are equal to one. The scaling is computed
+//   by a breadth-first traversal of the graph with d = 1 at the first node of each component
+//
+//   Output:
+//     d  -- [n] diagonal of D (positive) [nil if not ok]
+//     ok -- D exists (within a relative tolerance of 1e-10)
+//
+//   NOTE: the solution of A⋅x = b is x = D⁻¹⋅y with S⋅y = D⋅b (see SpDiagSimilarity)
+func Symmetrize(a *CCMatrix) (d Vector, ok bool) {
+	if a.m != a.n {
+		chk.Panic("matrix must be square. %d != %d\n", a.m, a.n)
+	}
+
+	// off-diagonal entries and graph
+	n := a.n
+	rows, cols, vals := a.Triplets()
+	val := make(map[int]float64, len(vals))
+	adj := make([][]int, n)
+	for k, v := range vals {
+		i, j := rows[k], cols[k]
+		if i == j || v == 0 {
+			continue
+		}
+		val[i*n+j] = v
+		adj[i] = append(adj[i], j)
+	}
+
+	// traversal
+	d = NewVector(n)
+	queue := make([]int, 0, n)
+	for root := 0; root < n; root++ {
+		if d[root] > 0 {
+			continue
+		}
+		d[root] = 1
+		queue = append(queue[:0], root)
+		for len(queue) > 0 {
+			i := queue[0]
+			queue = queue[1:]
+			for _, j := range adj[i] {
+				aij, aji := val[i*n+j], val[j*n+i]
+				if aij*aji <= 0 {
+					return nil, false
+				}
+				dj := d[i] * math.Sqrt(aij/aji) // (d_i/d_j)² = A_ji/A_ij
+				if d[j] == 0 {
+					d[j] = dj
+					queue = append(queue, j)
+					continue
+				}
+				if math.Abs(d[j]-dj) > 1e-10*d[j] {
+					return nil, false
+				}
+			}
+		}
+	}
+	return d, true
+}
+
+// SpDiagSimilarity returns the similarity transform S = D⋅A⋅D⁻¹ with a diagonal matrix D; i.e.
+// S_ij = d_i⋅A_ij/d_j (see Symmetrize)
+func SpDiagSimilarity(a *CCMatrix, d Vector) (s *CCMatrix) {
+	if len(d) != a.m || a.m != a.n {
+		chk.Panic("matrix must be square and len(d) must be equal to %d. %d is invalid\n", a.m, len(d))
+	}
+	s = a.Clone()
+	for j := 0; j < s.n; j++ {
+		for p := s.p[j]; p < s.p[j+1]; p++ {
+			s.x[p] *= d[s.i[p]] / d[j]
+		}
+	}
+	return
+}
+
 // complex /////////////////////////////////////////////////////////////////////////////////////////
 
 // TripletC is a simple representation of a sparse matrix, where the indices and values
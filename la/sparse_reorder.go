@@ -0,0 +1,355 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+// ReverseCuthillMcKee computes a permutation that reduces the bandwidth of a sparse matrix
+//
+//   The graph of the symmetric structure of A + Aᵀ is traversed in breadth-first order starting
+//   from a pseudo-peripheral node of each connected component (George-Liu algorithm); neighbours
+//   are visited in increasing order of degree. The resulting ordering is then reversed.
+//
+//   Input:
+//     a -- square matrix
+//   Output:
+//     perm -- [n] permutation such that new index i corresponds to the old index perm[i]; i.e.
+//             the reordered matrix is B[i][j] = A[perm[i]][perm[j]] (see SpPermute)
+func ReverseCuthillMcKee(a *CCMatrix) (perm []int) {
+
+	// check
+	if a.m != a.n {
+		chk.Panic("matrix must be square. %d != %d\n", a.m, a.n)
+	}
+
+	// adjacency lists of symmetric structure
+	n := a.n
+	adj := spAdjacency(a)
+
+	// Cuthill-McKee ordering of each connected component
+	visited := make([]bool, n)
+	perm = make([]int, 0, n)
+	for len(perm) < n {
+
+		// unvisited node with minimum degree
+		root := -1
+		for i := 0; i < n; i++ {
+			if !visited[i] && (root < 0 || len(adj[i]) < len(adj[root])) {
+				root = i
+			}
+		}
+		root = rcmPseudoPeripheral(adj, visited, root)
+
+		// breadth-first traversal
+		head := len(perm)
+		visited[root] = true
+		perm = append(perm, root)
+		for ; head < len(perm); head++ {
+			first := len(perm)
+			for _, j := range adj[perm[head]] {
+				if !visited[j] {
+					visited[j] = true
+					perm = append(perm, j)
+				}
+			}
+			next := perm[first:]
+			sort.SliceStable(next, func(k, l int) bool { return len(adj[next[k]]) < len(adj[next[l]]) })
+		}
+	}
+
+	// reverse
+	for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+		perm[i], perm[j] = perm[j], perm[i]
+	}
+	return
+}
+
+// NestedDissection computes a fill-reducing permutation for sparse direct solvers (e.g. the
+// Cholesky factorisation of 2D and 3D Laplacians); see A. George, Nested dissection of a regular
+// finite element mesh, SIAM J Numer Anal, 10(2), 1973
+//
+//   The nodes are recursively split into two parts by a separator (a set of nodes whose removal
+//   disconnects the parts); the parts are numbered first and the separator last. Thus, the fill-in
+//   is confined to the blocks of the parts and the separators; with O(n⋅log n) non-zeros in the
+//   factor of 2D grids instead of O(n^1.5) with the natural (or banded) ordering
+//
+//   Separators:
+//     npts = nil -- graph-based: the middle level of the level structure (breadth-first traversal)
+//                   rooted at a pseudo-peripheral node of the symmetric structure of A + Aᵀ
+//     npts ≠ nil -- grid-aware: the middle line (plane) of the box across the direction with the
+//                   largest number of points; for structured operators (e.g. 5-, 9- or 7-point
+//                   stencils) with lexicographic numbering I = m + n⋅npts[0] + p⋅npts[0]⋅npts[1]
+//
+//   Input:
+//     a    -- square matrix
+//     npts -- [ndim] number of points along each direction of the grid [may be nil ⇒ graph-based]
+//   Output:
+//     perm -- [n] permutation such that new index i corresponds to the old index perm[i]; i.e.
+//             the reordered matrix is B[i][j] = A[perm[i]][perm[j]] (see SpPermute)
+//
+//   NOTE: the grid-aware ordering does not inspect the entries; thus, the stencil must not couple
+//         nodes farther than one point apart along each direction
+func NestedDissection(a *CCMatrix, npts []int) (perm []int) {
+
+	// check
+	if a.m != a.n {
+		chk.Panic("matrix must be square. %d != %d\n", a.m, a.n)
+	}
+	n := a.n
+	perm = make([]int, 0, n)
+
+	// grid-aware
+	if npts != nil {
+		if len(npts) < 1 || len(npts) > 3 {
+			chk.Panic("number of directions of grid must be 1, 2 or 3. len(npts) = %d is invalid\n", len(npts))
+		}
+		sz := []int{1, 1, 1}
+		copy(sz, npts)
+		if sz[0]*sz[1]*sz[2] != n {
+			chk.Panic("number of grid points must be equal to the dimension of the matrix. %d != %d\n", sz[0]*sz[1]*sz[2], n)
+		}
+		ndGrid(&perm, sz, [3]int{0, 0, 0}, [3]int{sz[0], sz[1], sz[2]})
+		return
+	}
+
+	// graph-based
+	adj := spAdjacency(a)
+	excluded := make([]bool, n)
+	for i := range excluded {
+		excluded[i] = true
+	}
+	nodes := make([]int, n)
+	for i := 0; i < n; i++ {
+		nodes[i] = i
+	}
+	ndGraph(&perm, adj, excluded, nodes)
+	return
+}
+
+// SpPermute returns the symmetrically permuted matrix B = P⋅A⋅Pᵀ in triplet form
+//
+//   B[i][j] = A[perm[i]][perm[j]]
+//
+//   Input:
+//     a    -- square matrix
+//     perm -- [n] permutation; e.g. from ReverseCuthillMcKee
+//   Output:
+//     b -- permuted matrix; e.g. to be used with SparseSolver or ToMatrix
+func SpPermute(a *CCMatrix, perm []int) (b *Triplet) {
+	if a.m != a.n || len(perm) != a.n {
+		chk.Panic("matrix must be square and len(perm) must be equal to n. m=%d, n=%d, len(perm)=%d\n", a.m, a.n, len(perm))
+	}
+	iperm := make([]int, a.n)
+	for i, k := range perm {
+		iperm[k] = i
+	}
+	b = NewTriplet(a.n, a.n, a.nnz)
+	for j := 0; j < a.n; j++ {
+		for p := a.p[j]; p < a.p[j+1]; p++ {
+			b.Put(iperm[a.i[p]], iperm[j], a.x[p])
+		}
+	}
+	return
+}
+
+// VecPermute permutes vector: res[i] = v[perm[i]]
+//   NOTE: res and v must not be the same vector
+func VecPermute(res, v Vector, perm []int) {
+	for i, k := range perm {
+		res[i] = v[k]
+	}
+}
+
+// VecPermuteInv applies the inverse permutation: res[perm[i]] = v[i]
+//   NOTE: res and v must not be the same vector
+func VecPermuteInv(res, v Vector, perm []int) {
+	for i, k := range perm {
+		res[k] = v[i]
+	}
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// spAdjacency returns the adjacency lists of the symmetric structure of A + Aᵀ (without diagonal
+// and duplicates)
+func spAdjacency(a *CCMatrix) (adj [][]int) {
+	n := a.n
+	adj = make([][]int, n)
+	for j := 0; j < n; j++ {
+		for p := a.p[j]; p < a.p[j+1]; p++ {
+			if i := a.i[p]; i != j {
+				adj[i] = append(adj[i], j)
+				adj[j] = append(adj[j], i)
+			}
+		}
+	}
+	mark := make([]int, n)
+	for i := 0; i < n; i++ {
+		mark[i] = -1
+	}
+	for i := 0; i < n; i++ {
+		k := 0
+		for _, j := range adj[i] {
+			if mark[j] != i {
+				mark[j] = i
+				adj[i][k] = j
+				k++
+			}
+		}
+		adj[i] = adj[i][:k]
+	}
+	return
+}
+
+// ndLeafSize is the number of nodes below which nested dissection stops
+const ndLeafSize = 8
+
+// ndGrid appends the nested dissection ordering of the box [lo, hi) of the grid to perm
+func ndGrid(perm *[]int, npts []int, lo, hi [3]int) {
+	size := [3]int{hi[0] - lo[0], hi[1] - lo[1], hi[2] - lo[2]}
+	if size[0] <= 0 || size[1] <= 0 || size[2] <= 0 {
+		return
+	}
+	dim := 0
+	for d := 1; d < 3; d++ {
+		if size[d] > size[dim] {
+			dim = d
+		}
+	}
+	if size[0]*size[1]*size[2] <= ndLeafSize || size[dim] < 3 {
+		for p := lo[2]; p < hi[2]; p++ {
+			for n := lo[1]; n < hi[1]; n++ {
+				for m := lo[0]; m < hi[0]; m++ {
+					*perm = append(*perm, m+n*npts[0]+p*npts[0]*npts[1])
+				}
+			}
+		}
+		return
+	}
+	mid := lo[dim] + size[dim]/2
+	hiA, loB, loS, hiS := hi, lo, lo, hi
+	hiA[dim], loB[dim] = mid, mid+1
+	loS[dim], hiS[dim] = mid, mid+1
+	ndGrid(perm, npts, lo, hiA)
+	ndGrid(perm, npts, loB, hi)
+	ndGrid(perm, npts, loS, hiS) // separator: numbered last; ordered as a leaf or dissected further
+}
+
+// ndGraph appends the nested dissection ordering of the sub-graph with the given nodes to perm.
+// Excluded nodes (outside of the sub-graph) are ignored; excluded is restored on return
+func ndGraph(perm *[]int, adj [][]int, excluded []bool, nodes []int) {
+	if len(nodes) <= ndLeafSize {
+		*perm = append(*perm, nodes...)
+		return
+	}
+	for _, i := range nodes {
+		excluded[i] = false
+	}
+
+	// level structure rooted at a pseudo-peripheral node
+	root := nodes[0]
+	for _, i := range nodes {
+		if len(adj[i]) < len(adj[root]) {
+			root = i
+		}
+	}
+	root = rcmPseudoPeripheral(adj, excluded, root)
+	level := make(map[int]int, len(nodes))
+	level[root] = 0
+	queue := []int{root}
+	for head := 0; head < len(queue); head++ {
+		i := queue[head]
+		for _, j := range adj[i] {
+			if _, ok := level[j]; !ok && !excluded[j] {
+				level[j] = level[i] + 1
+				queue = append(queue, j)
+			}
+		}
+	}
+	depth := level[queue[len(queue)-1]] + 1
+	for _, i := range nodes {
+		excluded[i] = true
+	}
+
+	// disconnected sub-graph: split into the component of root and the remaining nodes
+	if len(queue) < len(nodes) {
+		var rest []int
+		for _, i := range nodes {
+			if _, ok := level[i]; !ok {
+				rest = append(rest, i)
+			}
+		}
+		ndGraph(perm, adj, excluded, queue)
+		ndGraph(perm, adj, excluded, rest)
+		return
+	}
+
+	// too few levels for a separator
+	if depth < 3 {
+		*perm = append(*perm, nodes...)
+		return
+	}
+
+	// separator: middle level
+	var partA, partB, sep []int
+	mid := depth / 2
+	for _, i := range queue {
+		switch {
+		case level[i] < mid:
+			partA = append(partA, i)
+		case level[i] > mid:
+			partB = append(partB, i)
+		default:
+			sep = append(sep, i)
+		}
+	}
+	ndGraph(perm, adj, excluded, partA)
+	ndGraph(perm, adj, excluded, partB)
+	*perm = append(*perm, sep...)
+}
+
+// rcmPseudoPeripheral finds a pseudo-peripheral node in the (unvisited) component containing root
+func rcmPseudoPeripheral(adj [][]int, visited []bool, root int) int {
+	last, depth := rcmLastLevel(adj, visited, root)
+	for {
+		c := last[0]
+		for _, k := range last {
+			if len(adj[k]) < len(adj[c]) {
+				c = k
+			}
+		}
+		lastC, depthC := rcmLastLevel(adj, visited, c)
+		if depthC <= depth {
+			return root
+		}
+		root, last, depth = c, lastC, depthC
+	}
+}
+
+// rcmLastLevel returns the nodes in the last level of the level structure rooted at root and the
+// number of levels (eccentricity of root). Visited nodes are ignored
+func rcmLastLevel(adj [][]int, visited []bool, root int) (last []int, depth int) {
+	seen := map[int]bool{root: true}
+	level := []int{root}
+	for len(level) > 0 {
+		last = level
+		depth++
+		var next []int
+		for _, i := range level {
+			for _, j := range adj[i] {
+				if !visited[j] && !seen[j] {
+					seen[j] = true
+					next = append(next, j)
+				}
+			}
+		}
+		level = next
+	}
+	return
+}
@@ -5,6 +5,9 @@
 package la
 
 import (
+	"math"
+	"math/rand"
+
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/mpi"
 )
@@ -113,3 +116,48 @@ func SpSolveC(A *TripletC, b VectorC) (x VectorC) {
 	o.Solve(x, b, false) // x := inv(A) * b
 	return
 }
+
+// TraceInvEstimate estimates the trace of the inverse of a factorised matrix with Hutchinson's
+// stochastic estimator; e.g. for uncertainty quantification (the sum of variances)
+//
+//                     1   N
+//   trace(A⁻¹) ≈ T = ——— Σ  zₖᵀ⋅A⁻¹⋅zₖ     with random Rademacher vectors zₖ (entries ±1)
+//                     N  k=1
+//
+//   Input:
+//     factor     -- sparse solver with the factorised matrix A (Init and Fact already called)
+//     n          -- dimension of A
+//     numSamples -- number N of random vectors (≥ 2)
+//     seed       -- seed of the random number generator
+//   Output:
+//     trace  -- estimate T
+//     stdErr -- standard error of T given by the sample variance; i.e. s/√N
+//
+//   NOTE: each sample requires one back-substitution with the factors. The variance of the
+//         estimator is 2 Σ_{i≠j} (A⁻¹)ᵢⱼ² (for symmetric matrices); i.e. the estimate is exact
+//         for diagonal matrices
+func TraceInvEstimate(factor SparseSolver, n, numSamples int, seed int64) (trace, stdErr float64) {
+	if numSamples < 2 {
+		chk.Panic("number of samples must be at least 2. %d is invalid\n", numSamples)
+	}
+	rnd := rand.New(rand.NewSource(seed))
+	z, x := NewVector(n), NewVector(n)
+	var sum, sum2 float64
+	for k := 0; k < numSamples; k++ {
+		for i := 0; i < n; i++ {
+			z[i] = 1
+			if rnd.Intn(2) == 0 {
+				z[i] = -1
+			}
+		}
+		factor.Solve(x, z, false)
+		t := VecDot(z, x)
+		sum += t
+		sum2 += t * t
+	}
+	N := float64(numSamples)
+	trace = sum / N
+	variance := math.Max((sum2-N*trace*trace)/(N-1), 0)
+	stdErr = math.Sqrt(variance / N)
+	return
+}
@@ -0,0 +1,156 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// laplacian1d returns the 1D Laplacian (second-difference matrix) with n interior nodes
+func laplacian1d(n int) (t *Triplet) {
+	t = NewTriplet(n, n, 3*n)
+	for i := 0; i < n; i++ {
+		t.Put(i, i, -2)
+		if i > 0 {
+			t.Put(i, i-1, 1)
+		}
+		if i < n-1 {
+			t.Put(i, i+1, 1)
+		}
+	}
+	return
+}
+
+func TestBandMatrix01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("BandMatrix01. storage and conversion")
+
+	a := NewBandMatrix(5, 1, 2)
+	chk.Int(tst, "ldab", a.Ldab, 5)
+	a.Set(0, 0, 1)
+	a.Set(1, 0, 2)
+	a.Set(0, 2, 3)
+	a.Set(4, 4, 4)
+	chk.Float64(tst, "a00", 1e-17, a.Get(0, 0), 1)
+	chk.Float64(tst, "a10", 1e-17, a.Get(1, 0), 2)
+	chk.Float64(tst, "a02", 1e-17, a.Get(0, 2), 3)
+	chk.Float64(tst, "a44", 1e-17, a.Get(4, 4), 4)
+	chk.Float64(tst, "a40 (outside)", 1e-17, a.Get(4, 0), 0)
+	chk.Float64(tst, "a03 (outside)", 1e-17, a.Get(0, 3), 0)
+
+	// from sparse matrix
+	t := NewTriplet(4, 4, 8)
+	t.Put(0, 0, 4)
+	t.Put(1, 0, -1)
+	t.Put(0, 1, 2)
+	t.Put(1, 1, 5)
+	t.Put(1, 1, 1) // duplicate
+	t.Put(2, 1, 3)
+	t.Put(3, 3, 7)
+	t.Put(2, 3, 6)
+	c := t.ToMatrix(nil)
+	kl, ku, _ := c.BandwidthStats()
+	b := c.ToBand(kl, ku)
+	dense := t.ToDense()
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			chk.Float64(tst, io.Sf("b%d%d", i, j), 1e-17, b.Get(i, j), dense.Get(i, j))
+		}
+	}
+
+	// entry outside the band
+	defer chk.RecoverTstPanicIsOK(tst)
+	c.ToBand(0, 1)
+}
+
+func TestBandMatrix02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("BandMatrix02. 1D Laplacian: banded and sparse solvers")
+
+	n := 100
+	t := laplacian1d(n)
+	b := NewVector(n)
+	for i := 0; i < n; i++ {
+		b[i] = float64(i%7) - 3.0
+	}
+	a := t.ToMatrix(nil).ToBand(1, 1)
+	x := NewVector(n)
+	SolveBanded(x, a, b, true)
+	xs := SpSolve(t, b)
+	chk.Array(tst, "banded == sparse", 1e-10, x, xs)
+
+	// factorisation replaces A
+	SolveBanded(x, a, b, false)
+	chk.Array(tst, "banded == sparse (not preserved)", 1e-10, x, xs)
+}
+
+func TestBandMatrix03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("BandMatrix03. non-symmetric matrix with pivoting")
+
+	// small diagonal entries require row interchanges
+	n, kl, ku := 8, 2, 1
+	a := NewBandMatrix(n, kl, ku)
+	A := NewMatrix(n, n)
+	for j := 0; j < n; j++ {
+		for i := j - ku; i <= j+kl; i++ {
+			if i < 0 || i >= n {
+				continue
+			}
+			v := 1.0 + float64((3*i+5*j)%4)
+			if i == j {
+				v = 0.01 * float64(j+1)
+			}
+			a.Set(i, j, v)
+			A.Set(i, j, v)
+		}
+	}
+	b := NewVector(n)
+	for i := 0; i < n; i++ {
+		b[i] = float64(i + 1)
+	}
+	x := NewVector(n)
+	SolveBanded(x, a, b, true)
+	xd := NewVector(n)
+	DenSolve(xd, A, b, true)
+	io.Pforan("x = %v\n", x)
+	chk.Array(tst, "banded == dense", 1e-11, x, xd)
+
+	// residual
+	r := NewVector(n)
+	MatVecMul(r, 1, A, x)
+	chk.Array(tst, "A⋅x == b", 1e-12, r, b)
+}
+
+// benchmarks ///////////////////////////////////////////////////////////////////////////////////////
+
+func BenchmarkSolveBanded(b *testing.B) {
+	n := 10000
+	a := laplacian1d(n).ToMatrix(nil).ToBand(1, 1)
+	rhs := NewVector(n)
+	rhs.Fill(1)
+	x := NewVector(n)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SolveBanded(x, a, rhs, true)
+	}
+}
+
+func BenchmarkSolveSparse1D(b *testing.B) {
+	n := 10000
+	t := laplacian1d(n)
+	rhs := NewVector(n)
+	rhs.Fill(1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SpSolve(t, rhs)
+	}
+}
@@ -72,3 +72,152 @@ func TestBlas1tst02(tst *testing.T) {
 		chk.Float64(tst, "u⋅v", 1e-15, VecDot(u, v), dot)
 	}
 }
+
+func TestBlas1tst03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Blas1tst03. (real) Dot, Axpy and Scal")
+
+	for _, n := range []int{0, 1, 7, 150, 151, 300} {
+
+		// vectors
+		x := NewVector(n)
+		y := NewVector(n)
+		for i := 0; i < n; i++ {
+			x[i] = 1.0 + float64(i)/3.0
+			y[i] = math.Sin(float64(i))
+		}
+
+		// naive loops
+		α := -1.5
+		dot := 0.0
+		axpy := NewVector(n)
+		scal := NewVector(n)
+		for i := 0; i < n; i++ {
+			dot += x[i] * y[i]
+			axpy[i] = y[i] + α*x[i]
+			scal[i] = α * x[i]
+		}
+
+		// check
+		chk.Float64(tst, io.Sf("n=%3d: x・y", n), 1e-12, Dot(x, y), dot)
+		Axpy(α, x, y)
+		chk.Array(tst, io.Sf("n=%3d: y += α⋅x", n), 1e-15, y, axpy)
+		Scal(α, x)
+		chk.Array(tst, io.Sf("n=%3d: x := α⋅x", n), 1e-15, x, scal)
+	}
+}
+
+func TestBlas1tst04(tst *testing.T) {
+	//verbose()
+	chk.PrintTitle("Blas1tst04. panic in Dot: length mismatch")
+	defer chk.RecoverTstPanicIsOK(tst)
+	Dot(NewVector(3), NewVector(2))
+}
+
+func TestBlas1tst05(tst *testing.T) {
+	//verbose()
+	chk.PrintTitle("Blas1tst05. panic in Axpy: length mismatch")
+	defer chk.RecoverTstPanicIsOK(tst)
+	Axpy(1, NewVector(3), NewVector(4))
+}
+
+func TestBlas1tst06(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Blas1tst06. (real) DotInc, AxpyInc and ScalInc (strided)")
+
+	for _, n := range []int{0, 1, 7, 150, 151, 300} {
+
+		// vectors: x with increment 2 and y with increment 3
+		incx, incy := 2, 3
+		x := NewVector(1 + 2*n)
+		y := NewVector(1 + 3*n)
+		for i := range x {
+			x[i] = 1.0 + float64(i)/3.0
+		}
+		for i := range y {
+			y[i] = math.Sin(float64(i))
+		}
+
+		// naive loops
+		α := -1.5
+		dot := 0.0
+		axpy := y.GetCopy()
+		scal := x.GetCopy()
+		for i := 0; i < n; i++ {
+			dot += x[i*incx] * y[i*incy]
+			axpy[i*incy] += α * x[i*incx]
+			scal[i*incx] *= α
+		}
+
+		// check (the components between the strides are unchanged)
+		chk.Float64(tst, io.Sf("n=%3d: x・y", n), 1e-12, DotInc(n, x, incx, y, incy), dot)
+		AxpyInc(n, α, x, incx, y, incy)
+		chk.Array(tst, io.Sf("n=%3d: y += α⋅x", n), 1e-15, y, axpy)
+		ScalInc(n, α, x, incx)
+		chk.Array(tst, io.Sf("n=%3d: x := α⋅x", n), 1e-15, x, scal)
+	}
+}
+
+func TestBlas1tst07(tst *testing.T) {
+	//verbose()
+	chk.PrintTitle("Blas1tst07. panic in DotInc: vector too short")
+	defer chk.RecoverTstPanicIsOK(tst)
+	DotInc(3, NewVector(5), 2, NewVector(6), 3)
+}
+
+func TestBlas1tst08(tst *testing.T) {
+	//verbose()
+	chk.PrintTitle("Blas1tst08. panic in ScalInc: invalid increment")
+	defer chk.RecoverTstPanicIsOK(tst)
+	ScalInc(3, 2, NewVector(3), 0)
+}
+
+// benchmarks ///////////////////////////////////////////////////////////////////////////////////////
+
+var benchBlas1res float64
+
+func benchBlas1vectors(n int) (x, y Vector) {
+	x = NewVector(n)
+	y = NewVector(n)
+	for i := 0; i < n; i++ {
+		x[i] = float64(i)
+		y[i] = float64(n - i)
+	}
+	return
+}
+
+func BenchmarkDot(b *testing.B) {
+	x, y := benchBlas1vectors(1000)
+	for i := 0; i < b.N; i++ {
+		benchBlas1res = Dot(x, y)
+	}
+}
+
+func BenchmarkDotNaive(b *testing.B) {
+	x, y := benchBlas1vectors(1000)
+	for i := 0; i < b.N; i++ {
+		res := 0.0
+		for k := 0; k < len(x); k++ {
+			res += x[k] * y[k]
+		}
+		benchBlas1res = res
+	}
+}
+
+func BenchmarkAxpy(b *testing.B) {
+	x, y := benchBlas1vectors(1000)
+	for i := 0; i < b.N; i++ {
+		Axpy(1e-9, x, y)
+	}
+}
+
+func BenchmarkAxpyNaive(b *testing.B) {
+	x, y := benchBlas1vectors(1000)
+	for i := 0; i < b.N; i++ {
+		for k := 0; k < len(x); k++ {
+			y[k] += 1e-9 * x[k]
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
 )
 
 func calcLLt(L *Matrix) (LLt *Matrix) {
@@ -147,3 +148,151 @@ func TestSPDsolve02(tst *testing.T) {
 	})
 	chk.Array(tst, "X = inv(a) * B", 1e-13, X, []float64{0, 4, 7, -1, 8})
 }
+
+func TestSPDsolve03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("TestSPDsolve 03. indefinite matrix")
+
+	// pivots: 4, 5 - 1 = 4 and 1 - 1.5² = -1.25
+	a := NewMatrixDeep2([][]float64{
+		{4, 2, 0},
+		{2, 5, 3},
+		{0, 3, 1},
+	})
+	x := make([]float64, 3)
+	err := SolveRealLinSysSPD(x, a, []float64{1, 2, 3})
+	if err == nil {
+		tst.Errorf("error should have been returned\n")
+		return
+	}
+	io.Pforan("%v\n", err)
+	e, ok := err.(*NotPosDefError)
+	if !ok {
+		tst.Errorf("error should be of type *NotPosDefError\n")
+		return
+	}
+	chk.Int(tst, "pivot", e.Pivot, 2)
+	chk.Float64(tst, "value", 1e-15, e.Value, -1.25)
+	chk.Array(tst, "x (not modified)", 1e-15, x, nil)
+	chk.String(tst, err.Error(), "Cholesky factorization failed due to non positive-definite matrix: pivot 2 is -1.25")
+
+	// sparse factorisation fails at the same pivot
+	defer chk.RecoverTstPanicIsOK(tst)
+	t := NewTriplet(3, 3, 7)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			if a.Get(i, j) != 0 {
+				t.Put(i, j, a.Get(i, j))
+			}
+		}
+	}
+	CholeskyFactor(t.ToMatrix(nil))
+}
+
+func TestBlockTridiag01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("BlockTridiag01. block-tridiagonal system with 2x2 blocks")
+
+	// blocks; diagonal blocks have zero (0,0) entry ⇒ pivoting is required
+	nb, m := 6, 2
+	A := make([]*Matrix, nb)
+	B := make([]*Matrix, nb)
+	C := make([]*Matrix, nb)
+	d := make([]Vector, nb)
+	x := make([]Vector, nb)
+	for i := 0; i < nb; i++ {
+		s := float64(i + 1)
+		A[i] = NewMatrixDeep2([][]float64{{-1, 0.5}, {0.2, -1}})
+		B[i] = NewMatrixDeep2([][]float64{{0, 4 + s}, {5 + s, 1}})
+		C[i] = NewMatrixDeep2([][]float64{{-1, 0.1 * s}, {0.3, -2}})
+		d[i] = NewVectorSlice([]float64{s, 1 - s})
+		x[i] = NewVector(m)
+	}
+	A[0], C[nb-1] = nil, nil
+
+	// solve
+	SolveBlockTridiag(x, A, B, C, d)
+
+	// dense system
+	N := nb * m
+	K := NewMatrix(N, N)
+	b := NewVector(N)
+	for i := 0; i < nb; i++ {
+		for r := 0; r < m; r++ {
+			b[i*m+r] = d[i][r]
+			for c := 0; c < m; c++ {
+				K.Set(i*m+r, i*m+c, B[i].Get(r, c))
+				if i > 0 {
+					K.Set(i*m+r, (i-1)*m+c, A[i].Get(r, c))
+				}
+				if i < nb-1 {
+					K.Set(i*m+r, (i+1)*m+c, C[i].Get(r, c))
+				}
+			}
+		}
+	}
+	xref := NewVector(N)
+	DenSolve(xref, K, b, false)
+
+	// check
+	for i := 0; i < nb; i++ {
+		io.Pforan("x%d = %v\n", i, x[i])
+		chk.Array(tst, io.Sf("x%d", i), 1e-14, x[i], xref[i*m:(i+1)*m])
+	}
+}
+
+func TestBlockTridiag02(tst *testing.T) {
+	//verbose()
+	chk.PrintTitle("BlockTridiag02. panic on singular block")
+	defer chk.RecoverTstPanicIsOK(tst)
+	x := []Vector{NewVector(2)}
+	B := []*Matrix{NewMatrixDeep2([][]float64{{1, 2}, {2, 4}})}
+	SolveBlockTridiag(x, []*Matrix{nil}, B, []*Matrix{nil}, []Vector{NewVectorSlice([]float64{1, 1})})
+}
+
+func TestDenseLU01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("DenseLU01. solve with A and Aᵀ using the same factorisation")
+
+	// 1D advection-diffusion operator (upwind): non-symmetric
+	n := 8
+	pe := 3.0 // cell Péclet number
+	a := NewMatrix(n, n)
+	for i := 0; i < n; i++ {
+		a.Set(i, i, 2+pe)
+		if i > 0 {
+			a.Set(i, i-1, -1-pe)
+		}
+		if i < n-1 {
+			a.Set(i, i+1, -1)
+		}
+	}
+	a.Set(0, 0, 0) // requires pivoting
+	b := NewVectorMapped(n, func(i int) float64 { return float64(i*i) - 3 })
+
+	// factorise
+	lu := FactorizeLU(a)
+
+	// A⋅x = b
+	x := NewVector(n)
+	xref := NewVector(n)
+	lu.Solve(x, b)
+	DenSolve(xref, a, b, true)
+	io.Pforan("x  = %v\n", x)
+	chk.Array(tst, "x", 1e-13, x, xref)
+
+	// Aᵀ⋅x = b
+	lu.SolveTranspose(x, b)
+	DenSolve(xref, a.GetTranspose(), b, false)
+	io.Pforan("xᵀ = %v\n", x)
+	chk.Array(tst, "xᵀ", 1e-13, x, xref)
+
+	// the transposed solution differs from the normal solution
+	lu.Solve(xref, b)
+	if x.NormDiff(xref) < 1e-3 {
+		tst.Errorf("solutions with A and Aᵀ should differ for non-symmetric operators\n")
+	}
+}
@@ -143,6 +143,38 @@ func TestEqs02(tst *testing.T) {
 	chk.Array(tst, "vk=split(b)_k", 1e-17, vk, []float64{102, 104})
 }
 
+func TestEqs02b(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Eqs02b. GetRow")
+
+	// equations with known x2 and x4
+	e := NewEquations(5, []int{4, 2})
+	e.Alloc(nil, true, true)
+	e.Start()
+	e.Put(1, 0, 21)
+	e.Put(1, 2, 23)
+	e.Put(1, 1, 22)
+	e.Put(1, 2, 1) // repeated
+	e.Put(2, 4, 35)
+	e.Put(2, 1, 32)
+
+	// unknown equation
+	cols, vals := e.GetRow(1)
+	chk.Ints(tst, "cols(1)", cols, []int{0, 1, 2})
+	chk.Array(tst, "vals(1)", 1e-17, vals, []float64{21, 22, 24})
+
+	// known equation
+	cols, vals = e.GetRow(2)
+	chk.Ints(tst, "cols(2)", cols, []int{1, 4})
+	chk.Array(tst, "vals(2)", 1e-17, vals, []float64{32, 35})
+
+	// empty row
+	cols, vals = e.GetRow(3)
+	chk.Int(tst, "len(cols(3))", len(cols), 0)
+	chk.Int(tst, "len(vals(3))", len(vals), 0)
+}
+
 func TestEqs03(tst *testing.T) {
 
 	//verbose()
@@ -447,3 +479,19 @@ func TestEqs06(tst *testing.T) {
 	e.JoinVector(bRef, buRef, bkRef)
 	chk.Array(tst, "{b}", 1e-12, bRef, b)
 }
+
+func TestEqs07(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Eqs07. ReorderUnknowns")
+
+	e := NewEquations(6, []int{0, 3})
+	e.ReorderUnknowns([]int{4, 1, 5, 2})
+	chk.Ints(tst, "UtoF", e.UtoF, []int{4, 1, 5, 2})
+	chk.Ints(tst, "FtoU", e.FtoU, []int{-1, 1, 3, -1, 0, 2})
+	chk.Ints(tst, "KtoF", e.KtoF, []int{0, 3})
+
+	// known equation cannot be in the u-system
+	defer chk.RecoverTstPanicIsOK(tst)
+	e.ReorderUnknowns([]int{4, 1, 5, 3})
+}
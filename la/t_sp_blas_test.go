@@ -5,6 +5,7 @@
 package la
 
 import (
+	"math"
 	"testing"
 
 	"github.com/cpmech/gosl/chk"
@@ -547,3 +548,184 @@ func TestSpBlas11(tst *testing.T) {
 	SpMatMatTrMul(b4, 1, a4)
 	chk.Deep2(tst, "b4", 1e-17, b4.GetDeep2(), [][]float64{{5}})
 }
+
+func TestSpBlas12(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpBlas12. CSR: ToCSR and SpMatVecMulCSR")
+
+	// small matrix with duplicates
+	t := NewTriplet(3, 4, 8)
+	t.Put(0, 2, 1)
+	t.Put(0, 0, 2)
+	t.Put(2, 1, 3)
+	t.Put(0, 2, 4) // duplicate
+	t.Put(1, 3, 5)
+	t.Put(2, 1, 6) // duplicate
+	t.Put(2, 3, 7)
+	a := t.ToCSR()
+	chk.Int(tst, "nnz", a.nnz, 5)
+	chk.Ints(tst, "p", a.p, []int{0, 2, 3, 5})
+	chk.Ints(tst, "j", a.j, []int{2, 0, 3, 1, 3})
+	chk.Array(tst, "x", 1e-17, a.x, []float64{5, 2, 5, 9, 7})
+	chk.Deep2(tst, "dense", 1e-17, a.ToDense().GetDeep2(), t.ToDense().GetDeep2())
+
+	// CSR and CSC matrix-vector multiplications give the same results (2D Laplacian)
+	lap := laplacian2d(10)
+	csr := lap.ToCSR()
+	csc := lap.ToMatrix(nil)
+	u := NewVector(100)
+	for i := 0; i < 100; i++ {
+		u[i] = float64(i%9) - 4.0
+	}
+	vr := NewVector(100)
+	vc := NewVector(100)
+	SpMatVecMulCSR(vr, -2, csr, u)
+	SpMatVecMul(vc, -2, csc, u)
+	io.Pforan("v = %v\n", vr)
+	chk.Array(tst, "CSR == CSC", 1e-15, vr, vc)
+}
+
+func TestSpBlas13(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpBlas13. parallel SpMatVecMulCSR")
+
+	a := laplacian2dRect(37, 23).ToCSR()
+	n := 37 * 23
+	u := NewVector(n)
+	for i := 0; i < n; i++ {
+		u[i] = math.Sin(float64(i)) + 1.0/float64(i+1)
+	}
+	vs := NewVector(n)
+	SpMatVecMulCSR(vs, -0.3, a, u)
+	for _, nworkers := range []int{0, 1, 2, 3, 7, 2 * n} {
+		vp := NewVector(n)
+		SpMatVecMulCSRpar(vp, -0.3, a, u, nworkers)
+		io.Pforan("nworkers = %d\n", nworkers)
+		for i := 0; i < n; i++ {
+			if vp[i] != vs[i] {
+				tst.Errorf("nworkers=%d: parallel result is not identical to serial: v[%d] = %v != %v\n", nworkers, i, vp[i], vs[i])
+				return
+			}
+		}
+	}
+}
+
+func TestSpBlas14(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpBlas14. sparse matrix-matrix product and Galerkin coarse operator")
+
+	// product of rectangular matrices compared with dense product
+	var ta, tb Triplet
+	ta.Init(4, 3, 6)
+	ta.Put(0, 0, 1)
+	ta.Put(1, 2, -2)
+	ta.Put(2, 1, 3)
+	ta.Put(3, 0, 0.5)
+	ta.Put(3, 2, 4)
+	ta.Put(3, 2, 1) // repeated entry
+	tb.Init(3, 2, 4)
+	tb.Put(0, 1, 2)
+	tb.Put(1, 0, -1)
+	tb.Put(2, 0, 3)
+	tb.Put(2, 1, 1)
+	c := SpMatMatMul(ta.ToMatrix(nil), tb.ToMatrix(nil))
+	cref := NewMatrix(4, 2)
+	MatMatMul(cref, 1, ta.ToDense(), tb.ToDense())
+	chk.Deep2(tst, "c = a⋅b", 1e-15, c.ToDense().GetDeep2(), cref.GetDeep2())
+
+	// 1D Laplacian with homogeneous Dirichlet conditions: interior nodes of fine and coarse grids
+	laplacian1d := func(n int, h float64) *CCMatrix {
+		var t Triplet
+		t.Init(n, n, 3*n)
+		for i := 0; i < n; i++ {
+			t.Put(i, i, -2/(h*h))
+			if i > 0 {
+				t.Put(i, i-1, 1/(h*h))
+			}
+			if i < n-1 {
+				t.Put(i, i+1, 1/(h*h))
+			}
+		}
+		return t.ToMatrix(nil)
+	}
+	nc := 7
+	nf := 2*nc + 1
+	H := 1.0 / float64(nc+1)
+	h := H / 2
+
+	// full-weighting restriction and linear interpolation (P = 2 Rᵀ); coarse node I is fine node 2I+1
+	var tr, tp Triplet
+	tr.Init(nc, nf, 3*nc)
+	tp.Init(nf, nc, 3*nc)
+	for I := 0; I < nc; I++ {
+		for d, w := range []float64{0.25, 0.5, 0.25} {
+			tr.Put(I, 2*I+d, w)
+			tp.Put(2*I+d, I, 2*w)
+		}
+	}
+	Ac := GalerkinProduct(tr.ToMatrix(nil), laplacian1d(nf, h), tp.ToMatrix(nil))
+	io.Pforan("Ac = \n%v\n", Ac.ToDense().Print("%8.2f"))
+	chk.Int(tst, "nnz", len(Ac.i), 3*nc-2)
+	chk.Deep2(tst, "R⋅A⋅P = A(H)", 1e-12, Ac.ToDense().GetDeep2(), laplacian1d(nc, H).ToDense().GetDeep2())
+
+	// with R = Pᵀ, the coarse operator is 2⋅A(H)
+	var tpt Triplet
+	tpt.Init(nc, nf, 3*nc)
+	for I := 0; I < nc; I++ {
+		for d, w := range []float64{0.5, 1, 0.5} {
+			tpt.Put(I, 2*I+d, w)
+		}
+	}
+	Ac2 := GalerkinProduct(tpt.ToMatrix(nil), laplacian1d(nf, h), tp.ToMatrix(nil))
+	Ac2.ScaleInPlace(0.5)
+	chk.Deep2(tst, "Pᵀ⋅A⋅P = 2⋅A(H)", 1e-12, Ac2.ToDense().GetDeep2(), laplacian1d(nc, H).ToDense().GetDeep2())
+}
+
+// benchmarks ///////////////////////////////////////////////////////////////////////////////////////
+
+func BenchmarkSpMatVecMulCSC(b *testing.B) {
+	a := laplacian2d(100).ToMatrix(nil)
+	u := NewVector(100 * 100)
+	v := NewVector(100 * 100)
+	u.Fill(1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SpMatVecMul(v, 1, a, u)
+	}
+}
+
+func BenchmarkSpMatVecMulCSR(b *testing.B) {
+	a := laplacian2d(100).ToCSR()
+	u := NewVector(100 * 100)
+	v := NewVector(100 * 100)
+	u.Fill(1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SpMatVecMulCSR(v, 1, a, u)
+	}
+}
+
+func BenchmarkSpMatVecMulCSRpar(b *testing.B) {
+	a := laplacian2d(500).ToCSR()
+	u := NewVector(500 * 500)
+	v := NewVector(500 * 500)
+	u.Fill(1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SpMatVecMulCSRpar(v, 1, a, u, 0)
+	}
+}
+
+func BenchmarkSpMatVecMulCSRlarge(b *testing.B) {
+	a := laplacian2d(500).ToCSR()
+	u := NewVector(500 * 500)
+	v := NewVector(500 * 500)
+	u.Fill(1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SpMatVecMulCSR(v, 1, a, u)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func TestSpCholesky01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpCholesky01. factorise once and solve many")
+
+	// SPD matrix: negative of 2D Laplacian
+	t := laplacian2dRect(7, 5)
+	for k := 0; k < t.pos; k++ {
+		t.x[k] = -t.x[k]
+	}
+	a := t.ToMatrix(nil)
+	n := a.n
+
+	// factorise
+	chol := CholeskyFactor(a)
+	io.Pforan("nnz(tril(A)) = %d, nnz(L) = %d\n", (a.nnz+n)/2, chol.Nnz())
+	if chol.Nnz() < (a.nnz+n)/2 {
+		tst.Errorf("nnz(L) must be at least nnz(tril(A))\n")
+	}
+
+	// L⋅Lᵀ = A
+	ad := a.ToDense()
+	L := NewMatrix(n, n)
+	for j := 0; j < n; j++ {
+		for p := chol.lp[j]; p < chol.lp[j+1]; p++ {
+			L.Set(chol.li[p], j, chol.lx[p])
+		}
+	}
+	Ld := NewMatrix(n, n)
+	Cholesky(Ld, ad)
+	chk.Deep2(tst, "L", 1e-14, L.GetDeep2(), Ld.GetDeep2())
+
+	// multiple right-hand sides
+	for k := 0; k < 3; k++ {
+		b := NewVector(n)
+		for i := 0; i < n; i++ {
+			b[i] = float64((i*(k+3))%7) - 3
+		}
+		x := NewVector(n)
+		chol.Solve(x, b)
+		xref := NewVector(n)
+		SolveRealLinSysSPD(xref, ad, b)
+		chk.Array(tst, io.Sf("x%d", k), 1e-13, x, xref)
+		chol.Solve(b, b) // in-place
+		chk.Array(tst, io.Sf("x%d (in-place)", k), 1e-13, b, xref)
+	}
+
+	// no fill-in for tridiagonal matrix
+	tri := NewTriplet(10, 10, 28)
+	for i := 0; i < 10; i++ {
+		tri.Put(i, i, 2)
+		if i > 0 {
+			tri.Put(i, i-1, -1)
+			tri.Put(i-1, i, -1)
+		}
+	}
+	chk.Int(tst, "nnz(L) tridiagonal", CholeskyFactor(tri.ToMatrix(nil)).Nnz(), 19)
+
+	// non positive-definite
+	defer chk.RecoverTstPanicIsOK(tst)
+	CholeskyFactor(laplacian2dRect(3, 3).ToMatrix(nil))
+}
@@ -0,0 +1,104 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func TestSpEigen01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpEigen01. shift-invert: interior eigenvalues of 2D Laplacian")
+
+	// -∇²u = λ⋅c⋅u on the unit square with u = 0 on the boundary (boundary nodes eliminated)
+	// with 13×13 interior nodes and M = c⋅I
+	nx := 13
+	h := 1.0 / float64(nx+1)
+	n := nx * nx
+	c := 2.0
+	A := new(Triplet)
+	A.Init(n, n, 5*n)
+	M := new(Triplet)
+	M.Init(n, n, n)
+	for j := 0; j < nx; j++ {
+		for i := 0; i < nx; i++ {
+			I := i + j*nx
+			A.Put(I, I, 4/(h*h))
+			if i > 0 {
+				A.Put(I, I-1, -1/(h*h))
+			}
+			if i < nx-1 {
+				A.Put(I, I+1, -1/(h*h))
+			}
+			if j > 0 {
+				A.Put(I, I-nx, -1/(h*h))
+			}
+			if j < nx-1 {
+				A.Put(I, I+nx, -1/(h*h))
+			}
+			M.Put(I, I, c)
+		}
+	}
+
+	// analytic eigenvalues: (λj + λk) / c with λj = 4/h² sin²(jπh/2)
+	var all []float64
+	for j := 1; j <= nx; j++ {
+		for k := 1; k <= nx; k++ {
+			λj := 4 / (h * h) * math.Pow(math.Sin(float64(j)*math.Pi*h/2), 2)
+			λk := 4 / (h * h) * math.Pow(math.Sin(float64(k)*math.Pi*h/2), 2)
+			all = append(all, (λj+λk)/c)
+		}
+	}
+
+	// the nev analytic eigenvalues closest to σ
+	σ := 400.0
+	nev := 6
+	sort.Slice(all, func(r, s int) bool { return math.Abs(all[r]-σ) < math.Abs(all[s]-σ) })
+	correct := append([]float64{}, all[:nev]...)
+	sort.Float64s(correct)
+
+	// shift-invert
+	λ, X := EigGenSymmShiftInvert(A, M, σ, nev)
+	io.Pforan("λ       = %v\n", λ)
+	io.Pforan("correct = %v\n", correct)
+	chk.Array(tst, "λ", 1e-9, λ, correct)
+
+	// eigenvectors: A⋅x = λ⋅M⋅x and Xᵀ⋅M⋅X = I
+	a := A.ToMatrix(nil)
+	ax := NewVector(n)
+	x := NewVector(n)
+	for k := 0; k < nev; k++ {
+		for i := 0; i < n; i++ {
+			x[i] = X.Get(i, k)
+		}
+		SpMatVecMul(ax, 1, a, x)
+		λmx := NewVector(n)
+		λmx.Apply(λ[k]*c, x)
+		chk.Array(tst, io.Sf("A⋅x%d", k), 1e-7, ax, λmx)
+		for l := 0; l < nev; l++ {
+			dot := 0.0
+			for i := 0; i < n; i++ {
+				dot += X.Get(i, k) * c * X.Get(i, l)
+			}
+			if l == k {
+				chk.Float64(tst, "xᵀ⋅M⋅x", 1e-12, dot, 1)
+			} else {
+				chk.Float64(tst, "xᵀ⋅M⋅y", 1e-10, dot, 0)
+			}
+		}
+	}
+
+	// standard problem (M = I)
+	λ, _ = EigGenSymmShiftInvert(A, nil, c*σ, nev)
+	for k := 0; k < nev; k++ {
+		chk.Float64(tst, io.Sf("λ%d (M = I)", k), 1e-9*c*σ, λ[k], c*correct[k])
+	}
+}
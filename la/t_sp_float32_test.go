@@ -0,0 +1,94 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func TestSpFloat32a(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpFloat32a. single precision triplet and column-compressed matrix")
+
+	// matrix with repeated entries (given in random order)
+	var t Triplet
+	t.Init(3, 4, 8)
+	t32 := NewTriplet32(3, 4, 8)
+	for _, e := range []struct {
+		i, j int
+		x    float64
+	}{{2, 3, 1.5}, {0, 0, 1}, {1, 2, -2}, {0, 0, 2}, {2, 0, 4}, {1, 1, 0.25}, {2, 3, -0.5}, {0, 3, 7}} {
+		t.Put(e.i, e.j, e.x)
+		t32.Put(e.i, e.j, float32(e.x))
+	}
+	chk.Int(tst, "len", t32.Len(), 8)
+	a := t.ToMatrix(nil)
+	a32 := t32.ToMatrix32()
+	chk.Int(tst, "nnz", a32.nnz, 6)
+	chk.Deep2(tst, "a32", 1e-15, a32.ToDense().GetDeep2(), a.ToDense().GetDeep2())
+	io.Pforan("bytes: float64 = %d, float32 = %d\n", a.Bytes(), a32.Bytes())
+	chk.Int(tst, "bytes(a)", a.Bytes(), 8*5+16*6)
+	chk.Int(tst, "bytes(a32)", a32.Bytes(), 4*5+8*6)
+
+	// matrix-vector multiplication
+	u := []float64{1, -1, 2, 0.5}
+	u32 := []float32{1, -1, 2, 0.5}
+	v := NewVector(3)
+	v32 := make([]float32, 3)
+	SpMatVecMul(v, 2, a, u)
+	SpMatVecMul32(v32, 2, a32, u32)
+	for i := 0; i < 3; i++ {
+		chk.Float64(tst, io.Sf("v%d", i), 1e-15, float64(v32[i]), v[i])
+	}
+
+	// restart
+	t32.Start()
+	chk.Int(tst, "len after Start", t32.Len(), 0)
+}
+
+func TestSpFloat32b(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpFloat32b. single precision BiCGStab")
+
+	// non-symmetric convection-diffusion matrix (1D): -u'' + c u'
+	n := 50
+	h := 1.0 / float64(n+1)
+	c := 20.0
+	t := new(Triplet)
+	t.Init(n, n, 3*n)
+	t32 := NewTriplet32(n, n, 3*n)
+	put := func(i, j int, x float64) {
+		t.Put(i, j, x)
+		t32.Put(i, j, float32(x))
+	}
+	b := NewVector(n)
+	b32 := make([]float32, n)
+	for i := 0; i < n; i++ {
+		put(i, i, 2/(h*h))
+		if i > 0 {
+			put(i, i-1, -1/(h*h)-c/(2*h))
+		}
+		if i < n-1 {
+			put(i, i+1, -1/(h*h)+c/(2*h))
+		}
+		b[i] = 1
+		b32[i] = 1
+	}
+
+	// reference and single precision solutions
+	x := SpSolve(t, b)
+	x32 := make([]float32, n)
+	nit := SpBiCGStab32(x32, t32.ToMatrix32(), b32, 1e-6, 200)
+	io.Pforan("nit = %d\n", nit)
+	xmax := x.Largest(1) // the error is about 1e-7 (single precision) times the condition number
+	for i := 0; i < n; i++ {
+		chk.Float64(tst, io.Sf("x%d", i), 1e-4*xmax, float64(x32[i]), x[i])
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func TestSpProjGaussSeidel01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpProjGaussSeidel01. projected Gauss-Seidel")
+
+	// 1D Laplacian with -u'' = 0 and u(0) = u(1) = 0 (boundaries eliminated)
+	n := 5
+	t := NewTriplet(n, n, 3*n)
+	for i := 0; i < n; i++ {
+		t.Put(i, i, 2)
+		if i > 0 {
+			t.Put(i, i-1, -1)
+		}
+		if i < n-1 {
+			t.Put(i, i+1, -1)
+		}
+	}
+	a := t.ToMatrix(nil)
+	b := NewVector(n)
+
+	// inactive constraint ⇒ zero solution
+	x := NewVector(n)
+	lower := []float64{-1, -1, -1, -1, -1}
+	nit := SpProjGaussSeidel(x, a, b, lower, 1, 1e-15, 1000)
+	io.Pforan("nit = %v\n", nit)
+	chk.Array(tst, "x (inactive)", 1e-14, x, []float64{0, 0, 0, 0, 0})
+
+	// obstacle at the middle node ⇒ piecewise linear solution
+	lower = []float64{-1, -1, 3, -1, -1}
+	nit = SpProjGaussSeidel(x, a, b, lower, 1.5, 1e-15, 1000)
+	io.Pforan("nit = %v\n", nit)
+	chk.Array(tst, "x (active)", 1e-14, x, []float64{1, 2, 3, 2, 1})
+
+	// the same with negative definite matrix
+	t.Start()
+	for i := 0; i < n; i++ {
+		t.Put(i, i, -2)
+		if i > 0 {
+			t.Put(i, i-1, 1)
+		}
+		if i < n-1 {
+			t.Put(i, i+1, 1)
+		}
+	}
+	a = t.ToMatrix(nil)
+	x.Fill(0)
+	SpProjGaussSeidel(x, a, b, lower, 1.5, 1e-15, 1000)
+	chk.Array(tst, "x (negative)", 1e-14, x, []float64{1, 2, 3, 2, 1})
+}
+
+// laplacian2d returns the (negative definite) 2D Laplacian with n×n interior nodes and Dirichlet boundaries
+func laplacian2d(n int) (t *Triplet) {
+	return laplacian2dRect(n, n)
+}
+
+// laplacian2dRect returns the 2D Laplacian with nx×ny interior nodes numbered along x first
+func laplacian2dRect(nx, ny int) (t *Triplet) {
+	N := nx * ny
+	t = NewTriplet(N, N, 5*N)
+	for j := 0; j < ny; j++ {
+		for i := 0; i < nx; i++ {
+			I := i + j*nx
+			t.Put(I, I, -4)
+			if i > 0 {
+				t.Put(I, I-1, 1)
+			}
+			if i < nx-1 {
+				t.Put(I, I+1, 1)
+			}
+			if j > 0 {
+				t.Put(I, I-nx, 1)
+			}
+			if j < ny-1 {
+				t.Put(I, I+nx, 1)
+			}
+		}
+	}
+	return
+}
+
+func TestConjGrad01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("ConjGrad01. CG and SSOR-preconditioned CG")
+
+	// 2D Laplacian
+	n := 15
+	t := laplacian2d(n)
+	a := t.ToMatrix(nil)
+	b := NewVector(n * n)
+	for i := 0; i < n*n; i++ {
+		b[i] = float64(1+i%7) - 3.5
+	}
+
+	// direct solution
+	xref := NewVector(n * n)
+	DenSolve(xref, t.ToDense(), b, false)
+
+	// plain CG
+	x := NewVector(n * n)
+	nitCG := ConjGrad(x, a, b, nil, 1e-12, 1000)
+	io.Pforan("CG:      nit = %d\n", nitCG)
+	chk.Array(tst, "x(CG)", 1e-10, x, xref)
+
+	// SSOR-preconditioned CG
+	x.Fill(0)
+	pc := NewPrecondSSOR(a, 1.5)
+	nitPCG := ConjGrad(x, a, b, pc, 1e-12, 1000)
+	io.Pforan("SSOR-CG: nit = %d\n", nitPCG)
+	chk.Array(tst, "x(SSOR-CG)", 1e-10, x, xref)
+	if nitPCG >= nitCG {
+		tst.Errorf("SSOR-preconditioned CG should take fewer iterations than CG: %d ≥ %d\n", nitPCG, nitCG)
+	}
+
+	// preconditioner is symmetric: M⁻¹ = (M⁻¹)ᵀ
+	N := n * n
+	Minv := NewMatrix(N, N)
+	e := NewVector(N)
+	z := NewVector(N)
+	for j := 0; j < N; j++ {
+		e.Fill(0)
+		e[j] = 1
+		pc.Apply(z, e)
+		for i := 0; i < N; i++ {
+			Minv.Set(i, j, z[i])
+		}
+	}
+	chk.Deep2(tst, "M⁻¹ == M⁻¹ᵀ", 1e-14, Minv.GetDeep2(), Minv.GetTranspose().GetDeep2())
+}
+
+func TestConjGrad02(tst *testing.T) {
+	//verbose()
+	chk.PrintTitle("ConjGrad02. panic on ω")
+	defer chk.RecoverTstPanicIsOK(tst)
+	NewPrecondSSOR(laplacian2d(2).ToMatrix(nil), 2)
+}
+
+func TestConjGrad03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("ConjGrad03. warm start from previous solution")
+
+	// system and solution with a slightly different right-hand side (e.g. previous time step)
+	n := 15
+	N := n * n
+	a := laplacian2d(n).ToMatrix(nil)
+	b := NewVector(N)
+	bold := NewVector(N)
+	for i := 0; i < N; i++ {
+		b[i] = float64(1+i%7) - 3.5
+		bold[i] = b[i] * (1 + 1e-6*float64(i%3))
+	}
+	xold := NewVector(N)
+	ConjGrad(xold, a, bold, nil, 1e-12, 1000)
+
+	// cold and warm starts
+	x := NewVector(N)
+	nitCold := ConjGrad(x, a, b, nil, 1e-8, 1000)
+	xcold := x.GetCopy()
+	copy(x, xold)
+	nitWarm := ConjGrad(x, a, b, nil, 1e-8, 1000)
+	io.Pforan("cold: nit = %d\n", nitCold)
+	io.Pforan("warm: nit = %d\n", nitWarm)
+	chk.Array(tst, "x(warm)", 1e-6, x, xcold)
+	if 2*nitWarm > nitCold {
+		tst.Errorf("warm start should take far fewer iterations: %d vs %d\n", nitWarm, nitCold)
+	}
+
+	// exact guess: no iterations
+	nit := ConjGrad(xcold, a, b, nil, 1e-8, 1000)
+	chk.Int(tst, "nit(exact guess)", nit, 0)
+
+	// GMRES
+	x.Fill(0)
+	nitCold = Gmres(x, a, b, nil, 1e-8, 1000, 30)
+	copy(x, xold)
+	nitWarm = Gmres(x, a, b, nil, 1e-8, 1000, 30)
+	io.Pforan("GMRES: cold nit = %d, warm nit = %d\n", nitCold, nitWarm)
+	chk.Array(tst, "x(warm GMRES)", 1e-6, x, xcold)
+	if nitWarm >= nitCold {
+		tst.Errorf("warm start should take fewer GMRES iterations: %d ≥ %d\n", nitWarm, nitCold)
+	}
+}
+
+func TestGmres01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Gmres01. restarted GMRES with non-symmetric matrix")
+
+	// 2D Laplacian plus upwind convection along x
+	n := 12
+	N := n * n
+	lap := laplacian2d(n).ToDense()
+	t := NewTriplet(N, N, 7*N)
+	for i := 0; i < N; i++ {
+		for j := 0; j < N; j++ {
+			if lap.Get(i, j) != 0 {
+				t.Put(i, j, lap.Get(i, j))
+			}
+		}
+		t.Put(i, i, -0.8)
+		if i%n > 0 {
+			t.Put(i, i-1, 0.8)
+		}
+	}
+	a := t.ToMatrix(nil)
+	b := NewVector(N)
+	for i := 0; i < N; i++ {
+		b[i] = float64(1+i%5) - 2.5
+	}
+
+	// direct solution
+	xref := NewVector(N)
+	DenSolve(xref, t.ToDense(), b, false)
+
+	// full and restarted GMRES, without and with preconditioner
+	x := NewVector(N)
+	nitFull := Gmres(x, a, b, nil, 1e-12, 1000, N)
+	io.Pforan("GMRES:        nit = %d\n", nitFull)
+	chk.Array(tst, "x(GMRES)", 1e-10, x, xref)
+	x.Fill(0)
+	nit := Gmres(x, a, b, nil, 1e-12, 2000, 10)
+	io.Pforan("GMRES(10):    nit = %d\n", nit)
+	chk.Array(tst, "x(GMRES(10))", 1e-10, x, xref)
+	if nit < nitFull {
+		tst.Errorf("restarted GMRES should not take fewer iterations than full GMRES: %d < %d\n", nit, nitFull)
+	}
+	x.Fill(0)
+	nit = Gmres(x, a, b, NewPrecondSSOR(a, 1), 1e-12, 1000, 10)
+	io.Pforan("SSOR-GMRES:   nit = %d\n", nit)
+	chk.Array(tst, "x(SSOR-GMRES)", 1e-10, x, xref)
+	x.Fill(0)
+	nit = Gmres(x, a, b, NewPrecondJacobi(a), 1e-12, 1000, 10)
+	io.Pforan("Jacobi-GMRES: nit = %d\n", nit)
+	chk.Array(tst, "x(Jacobi-GMRES)", 1e-10, x, xref)
+
+	// not converged
+	defer chk.RecoverTstPanicIsOK(tst)
+	x.Fill(0)
+	Gmres(x, a, b, nil, 1e-12, 5, 10)
+}
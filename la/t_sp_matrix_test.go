@@ -5,7 +5,9 @@
 package la
 
 import (
+	"encoding/binary"
 	"math"
+	"os"
 	"testing"
 
 	"github.com/cpmech/gosl/chk"
@@ -118,6 +120,39 @@ func TestSpMatrix01(tst *testing.T) {
 	})
 }
 
+func TestSpMatrix03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpMatrix03. BandwidthStats")
+
+	// unsymmetric matrix
+	//   1 0 0 5
+	//   2 1 0 0
+	//   0 0 1 0
+	//   0 3 0 1
+	t := NewTriplet(4, 4, 7)
+	t.Put(0, 0, 1)
+	t.Put(0, 3, 5)
+	t.Put(1, 0, 2)
+	t.Put(1, 1, 1)
+	t.Put(2, 2, 1)
+	t.Put(3, 1, 3)
+	t.Put(3, 3, 1)
+	lower, upper, average := t.ToMatrix(nil).BandwidthStats()
+	chk.Int(tst, "lower", lower, 2)
+	chk.Int(tst, "upper", upper, 3)
+	chk.Float64(tst, "average", 1e-15, average, 1.5)
+
+	// 2D Laplacian on n×n grid (lexicographic ordering) ⇒ bandwidth = n
+	for _, n := range []int{3, 10, 25} {
+		lower, upper, average = laplacian2d(n).ToMatrix(nil).BandwidthStats()
+		io.Pforan("n = %2d: lower = %2d, upper = %2d, average = %g\n", n, lower, upper, average)
+		chk.Int(tst, "lower", lower, n)
+		chk.Int(tst, "upper", upper, n)
+		chk.Float64(tst, "average", 1e-15, average, float64(n))
+	}
+}
+
 func TestSpMatrix02(tst *testing.T) {
 
 	//verbose()
@@ -154,3 +189,204 @@ func TestSpMatrix02(tst *testing.T) {
 	chk.Deep2(tst, "Kaug", 1.0e-17, Kaug.GetDeep2(), Cor)
 	chk.Deep2(tst, "Laug", 1.0e-17, Laug.GetDeep2(), Cor)
 }
+
+func TestSpMatrix04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpMatrix04. Triplets (COO form)")
+
+	// matrix with duplicates
+	//   1 0 0 5
+	//   2 4 0 0
+	//   0 0 1 0
+	//   0 3 0 7
+	t := NewTriplet(4, 4, 9)
+	t.Put(0, 0, 1)
+	t.Put(0, 3, 5)
+	t.Put(1, 0, 2)
+	t.Put(1, 1, 1)
+	t.Put(2, 2, 1)
+	t.Put(3, 1, 3)
+	t.Put(3, 3, 6)
+	t.Put(1, 1, 3) // duplicate
+	t.Put(3, 3, 1) // duplicate
+	a := t.ToMatrix(nil)
+	rows, cols, vals := a.Triplets()
+	io.Pforan("rows = %v\n", rows)
+	io.Pforan("cols = %v\n", cols)
+	io.Pforan("vals = %v\n", vals)
+	chk.Ints(tst, "rows", rows, []int{0, 1, 1, 3, 2, 0, 3})
+	chk.Ints(tst, "cols", cols, []int{0, 0, 1, 1, 2, 3, 3})
+	chk.Array(tst, "vals", 1e-17, vals, []float64{1, 2, 4, 3, 1, 5, 7})
+
+	// reconstruct
+	r := NewTriplet(4, 4, len(vals))
+	for k := range vals {
+		r.Put(rows[k], cols[k], vals[k])
+	}
+	chk.Deep2(tst, "reconstructed", 1e-17, r.ToDense().GetDeep2(), a.ToDense().GetDeep2())
+
+	// duplicates and unsorted rows in column-compressed data
+	var b CCMatrix
+	b.Set(3, 2, []int{0, 3, 4}, []int{2, 0, 2, 1}, []float64{1, 2, 3, 4})
+	rows, cols, vals = b.Triplets()
+	chk.Ints(tst, "rows", rows, []int{0, 2, 1})
+	chk.Ints(tst, "cols", cols, []int{0, 0, 1})
+	chk.Array(tst, "vals", 1e-17, vals, []float64{2, 4, 4})
+}
+
+func TestSpMatrix05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpMatrix05. Clone and ScaleInPlace")
+
+	a := laplacian2d(4).ToMatrix(nil)
+	A := a.ToDense().GetDeep2()
+	b := a.Clone()
+	b.ScaleInPlace(-0.5)
+	chk.Deep2(tst, "a (unchanged)", 1e-17, a.ToDense().GetDeep2(), A)
+	B := b.ToDense()
+	for i := range A {
+		for j := range A[i] {
+			chk.Float64(tst, io.Sf("b%d%d", i, j), 1e-17, B.Get(i, j), -0.5*A[i][j])
+		}
+	}
+
+	// changing the structure of the clone does not affect the original
+	b.i[0], b.x[0] = 1, 123
+	chk.Deep2(tst, "a (unchanged)", 1e-17, a.ToDense().GetDeep2(), A)
+}
+
+func TestSpMatrix06(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpMatrix06. Symmetrize and SpDiagSimilarity")
+
+	// 2D advection-diffusion (central differences): non-symmetric but symmetrizable
+	n := 10
+	N := n * n
+	px, py := 0.4, 0.2
+	t := NewTriplet(N, N, 5*N)
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			I := i + j*n
+			t.Put(I, I, 4)
+			if i > 0 {
+				t.Put(I, I-1, -(1 + px))
+			}
+			if i < n-1 {
+				t.Put(I, I+1, -(1 - px))
+			}
+			if j > 0 {
+				t.Put(I, I-n, -(1 + py))
+			}
+			if j < n-1 {
+				t.Put(I, I+n, -(1 - py))
+			}
+		}
+	}
+	a := t.ToMatrix(nil)
+	d, ok := Symmetrize(a)
+	if !ok {
+		tst.Errorf("matrix should be symmetrizable\n")
+		return
+	}
+
+	// symmetric transformed matrix
+	s := SpDiagSimilarity(a, d)
+	S := s.ToDense()
+	chk.Deep2(tst, "S == Sᵀ", 1e-14, S.GetDeep2(), S.GetTranspose().GetDeep2())
+
+	// solve A⋅x = b with CG: S⋅y = D⋅b and x = D⁻¹⋅y
+	b := NewVector(N)
+	for i := 0; i < N; i++ {
+		b[i] = float64(1+i%5) - 2.5
+	}
+	xref := NewVector(N)
+	DenSolve(xref, t.ToDense(), b, false)
+	db := NewVector(N)
+	for i := 0; i < N; i++ {
+		db[i] = d[i] * b[i]
+	}
+	y := NewVector(N)
+	nit := ConjGrad(y, s, db, nil, 1e-12, 1000)
+	io.Pforan("nit = %d\n", nit)
+	x := NewVector(N)
+	for i := 0; i < N; i++ {
+		x[i] = y[i] / d[i]
+	}
+	chk.Array(tst, "x", 1e-10, x, xref)
+
+	// inconsistent ratios around a cycle
+	c := NewTriplet(3, 3, 9)
+	c.Put(0, 0, 5)
+	c.Put(0, 1, 2)
+	c.Put(1, 0, 2) // ⇒ d1 = d0
+	c.Put(1, 2, 3)
+	c.Put(2, 1, 1) // ⇒ d2 = √3⋅d1
+	c.Put(0, 2, 1)
+	c.Put(2, 0, 1) // ⇒ d2 = d0
+	if _, ok = Symmetrize(c.ToMatrix(nil)); ok {
+		tst.Errorf("matrix with inconsistent ratios should not be symmetrizable\n")
+	}
+
+	// one-sided entry
+	e := NewTriplet(2, 2, 3)
+	e.Put(0, 0, 1)
+	e.Put(1, 1, 1)
+	e.Put(0, 1, 1)
+	if _, ok = Symmetrize(e.ToMatrix(nil)); ok {
+		tst.Errorf("matrix with one-sided entry should not be symmetrizable\n")
+	}
+}
+
+func TestSpMatrix07(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpMatrix07. PETSc binary format")
+
+	//   2 0 1     (the entries of row 0 are put in reverse order; 2 = 1.5 + 0.5)
+	//   0 3 0
+	//   4 0 5
+	t := NewTriplet(3, 3, 6)
+	t.Put(0, 2, 1)
+	t.Put(0, 0, 1.5)
+	t.Put(1, 1, 3)
+	t.Put(2, 0, 4)
+	t.Put(0, 0, 0.5)
+	t.Put(2, 2, 5)
+	b := []float64{-1, 0.25, 7}
+	os.MkdirAll("/tmp/gosl/la", 0777)
+	fn := "/tmp/gosl/la/petsc07.bin"
+	WritePETScBinary(fn, t.ToMatrix(nil), b)
+
+	// read back
+	buf := io.ReadFile(fn)
+	chk.Int(tst, "file size", len(buf), 4*4+4*3+4*5+8*5+4*2+8*3)
+	pos := 0
+	ints := func(n int) (res []int) {
+		for k := 0; k < n; k++ {
+			res = append(res, int(int32(binary.BigEndian.Uint32(buf[pos:]))))
+			pos += 4
+		}
+		return
+	}
+	floats := func(n int) (res []float64) {
+		for k := 0; k < n; k++ {
+			res = append(res, math.Float64frombits(binary.BigEndian.Uint64(buf[pos:])))
+			pos += 8
+		}
+		return
+	}
+	chk.Ints(tst, "matrix header", ints(4), []int{1211216, 3, 3, 5})
+	chk.Ints(tst, "nnz per row", ints(3), []int{2, 1, 2})
+	chk.Ints(tst, "column indices", ints(5), []int{0, 2, 1, 0, 2})
+	chk.Array(tst, "values", 1e-15, floats(5), []float64{2, 1, 3, 4, 5})
+	chk.Ints(tst, "vector header", ints(2), []int{1211214, 3})
+	chk.Array(tst, "vector", 1e-15, floats(3), b)
+	chk.Int(tst, "end of file", pos, len(buf))
+
+	// matrix only
+	WritePETScBinary(fn, t.ToMatrix(nil), nil)
+	chk.Int(tst, "file size (matrix only)", len(io.ReadFile(fn)), 4*4+4*3+4*5+8*5)
+}
@@ -0,0 +1,164 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func TestSpReorder01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpReorder01. Reverse Cuthill-McKee")
+
+	// 2D Laplacian on 30×6 grid numbered along the longest direction first
+	a := laplacian2dRect(30, 6).ToMatrix(nil)
+	lower, upper, _ := a.BandwidthStats()
+	io.Pforan("natural: lower = %d, upper = %d\n", lower, upper)
+	chk.Int(tst, "natural: upper", upper, 30)
+
+	// reorder
+	perm := ReverseCuthillMcKee(a)
+	sorted := append([]int{}, perm...)
+	sort.Ints(sorted)
+	for i := 0; i < len(sorted); i++ {
+		if sorted[i] != i {
+			tst.Errorf("perm is not a permutation\n")
+			return
+		}
+	}
+	b := SpPermute(a, perm)
+	lowerB, upperB, _ := b.ToMatrix(nil).BandwidthStats()
+	io.Pforan("RCM:     lower = %d, upper = %d\n", lowerB, upperB)
+	if lowerB >= lower || upperB >= upper {
+		tst.Errorf("RCM should reduce the bandwidth: (%d, %d) ≥ (%d, %d)\n", lowerB, upperB, lower, upper)
+	}
+
+	// solve permuted system and unpermute solution
+	n := a.n
+	xref := NewVectorMapped(n, func(i int) float64 { return float64(i%7) - 3 })
+	rhs := NewVector(n)
+	SpMatVecMul(rhs, 1, a, xref)
+	bp := NewVector(n)
+	VecPermute(bp, rhs, perm)
+	xp := SpSolve(b, bp)
+	x := NewVector(n)
+	VecPermuteInv(x, xp, perm)
+	chk.Array(tst, "x", 1e-12, x, xref)
+}
+
+func TestSpReorder02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpReorder02. RCM with disconnected components")
+
+	// two chains {0,2,4} and {1,3}
+	//   0-2-4   1-3
+	t := NewTriplet(5, 5, 13)
+	for i := 0; i < 5; i++ {
+		t.Put(i, i, 2)
+	}
+	for _, e := range [][]int{{0, 2}, {2, 4}, {1, 3}} {
+		t.Put(e[0], e[1], -1)
+		t.Put(e[1], e[0], -1)
+	}
+	a := t.ToMatrix(nil)
+	perm := ReverseCuthillMcKee(a)
+	io.Pforan("perm = %v\n", perm)
+	chk.Ints(tst, "perm", perm, []int{3, 1, 4, 2, 0})
+	lower, upper, _ := SpPermute(a, perm).ToMatrix(nil).BandwidthStats()
+	chk.Int(tst, "lower", lower, 1)
+	chk.Int(tst, "upper", upper, 1)
+}
+
+func TestSpReorder03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpReorder03. nested dissection")
+
+	// checks permutation and solution of permuted system by Cholesky factorisation
+	solve := func(a *CCMatrix, perm []int) (nnz int) {
+		sorted := append([]int{}, perm...)
+		sort.Ints(sorted)
+		for i := 0; i < len(sorted); i++ {
+			if sorted[i] != i {
+				tst.Errorf("perm is not a permutation\n")
+				return
+			}
+		}
+		n := a.n
+		xref := NewVectorMapped(n, func(i int) float64 { return float64(i%7) - 3 })
+		rhs := NewVector(n)
+		SpMatVecMul(rhs, 1, a, xref)
+		bp := NewVector(n)
+		VecPermute(bp, rhs, perm)
+		chol := CholeskyFactor(SpPermute(a, perm).ToMatrix(nil))
+		xp := NewVector(n)
+		chol.Solve(xp, bp)
+		x := NewVector(n)
+		VecPermuteInv(x, xp, perm)
+		chk.Array(tst, "x", 1e-10, x, xref)
+		return chol.Nnz()
+	}
+
+	// 2D Laplacian (positive-definite)
+	nx, ny := 31, 25
+	a := laplacian2dRect(nx, ny).ToMatrix(nil)
+	a.ScaleInPlace(-1)
+	natural := make([]int, a.n)
+	for i := range natural {
+		natural[i] = i
+	}
+	nnzNat := solve(a, natural)
+	nnzRcm := solve(a, ReverseCuthillMcKee(a))
+	nnzGraph := solve(a, NestedDissection(a, nil))
+	nnzGrid := solve(a, NestedDissection(a, []int{nx, ny}))
+	io.Pforan("nnz(L): natural = %d, RCM = %d, ND (graph) = %d, ND (grid) = %d\n", nnzNat, nnzRcm, nnzGraph, nnzGrid)
+	if nnzGraph >= nnzNat || nnzGrid >= nnzNat {
+		tst.Errorf("nested dissection should reduce the fill-in: %d or %d ≥ %d\n", nnzGraph, nnzGrid, nnzNat)
+	}
+	if nnzGrid >= nnzRcm {
+		tst.Errorf("grid-aware nested dissection should reduce the fill-in compared with RCM: %d ≥ %d\n", nnzGrid, nnzRcm)
+	}
+
+	// disconnected components (see SpReorder02) and small matrices
+	t := NewTriplet(5, 5, 13)
+	for i := 0; i < 5; i++ {
+		t.Put(i, i, 2)
+	}
+	for _, e := range [][]int{{0, 2}, {2, 4}, {1, 3}} {
+		t.Put(e[0], e[1], -1)
+		t.Put(e[1], e[0], -1)
+	}
+	solve(t.ToMatrix(nil), NestedDissection(t.ToMatrix(nil), nil))
+
+	// 3D grid-aware
+	n3 := 6
+	t = NewTriplet(n3*n3*n3, n3*n3*n3, 7*n3*n3*n3)
+	for p := 0; p < n3; p++ {
+		for n := 0; n < n3; n++ {
+			for m := 0; m < n3; m++ {
+				I := m + n*n3 + p*n3*n3
+				t.Put(I, I, 6)
+				for _, idx := range [][]int{{m - 1, n, p}, {m + 1, n, p}, {m, n - 1, p}, {m, n + 1, p}, {m, n, p - 1}, {m, n, p + 1}} {
+					if idx[0] >= 0 && idx[0] < n3 && idx[1] >= 0 && idx[1] < n3 && idx[2] >= 0 && idx[2] < n3 {
+						t.Put(I, idx[0]+idx[1]*n3+idx[2]*n3*n3, -1)
+					}
+				}
+			}
+		}
+	}
+	a3 := t.ToMatrix(nil)
+	nnzNat = solve(a3, natural[:a3.n])
+	nnzGrid = solve(a3, NestedDissection(a3, []int{n3, n3, n3}))
+	io.Pforan("3D nnz(L): natural = %d, ND (grid) = %d\n", nnzNat, nnzGrid)
+	if nnzGrid >= nnzNat {
+		tst.Errorf("nested dissection should reduce the fill-in in 3D: %d ≥ %d\n", nnzGrid, nnzNat)
+	}
+}
@@ -5,9 +5,11 @@
 package la
 
 import (
+	"math"
 	"testing"
 
 	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
 )
 
 func TestSpSolver01(tst *testing.T) {
@@ -128,3 +130,52 @@ func TestSpSolver02(tst *testing.T) {
 	chk.ArrayC(tst, "x", 1e-3, x, xCorrect)
 	TestSolverResidualC(tst, A.ToDense(), x, b, 1e-12)
 }
+
+func TestSpSolver03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpSolver03. stochastic estimate of trace of inverse")
+
+	// matrix: 2D Laplacian (negative definite)
+	nx, ny := 8, 6
+	n := nx * ny
+	A := laplacian2dRect(nx, ny)
+
+	// exact
+	Ai := NewMatrix(n, n)
+	MatInv(Ai, A.ToDense(), false)
+	exact := 0.0
+	for i := 0; i < n; i++ {
+		exact += Ai.Get(i, i)
+	}
+
+	// estimate
+	solver := NewSparseSolver("umfpack")
+	defer solver.Free()
+	solver.Init(A, false, false, "", "", nil)
+	solver.Fact()
+	trace, stdErr := TraceInvEstimate(solver, n, 400, 1234)
+	io.Pforan("trace(A⁻¹) = %g, estimate = %g ± %g\n", exact, trace, stdErr)
+	if math.Abs(trace-exact) > 3*stdErr {
+		tst.Errorf("estimate %g should be within 3 standard errors (%g) of %g\n", trace, stdErr, exact)
+	}
+	if stdErr > 0.05*math.Abs(exact) {
+		tst.Errorf("standard error %g is too large\n", stdErr)
+	}
+
+	// the estimate is exact for diagonal matrices
+	D := new(Triplet)
+	D.Init(n, n, n)
+	exact = 0
+	for i := 0; i < n; i++ {
+		D.Put(i, i, float64(i+1))
+		exact += 1 / float64(i+1)
+	}
+	solverD := NewSparseSolver("umfpack")
+	defer solverD.Free()
+	solverD.Init(D, false, false, "", "", nil)
+	solverD.Fact()
+	trace, stdErr = TraceInvEstimate(solverD, n, 5, 1)
+	chk.Float64(tst, "trace(D⁻¹)", 1e-13, trace, exact)
+	chk.Float64(tst, "stdErr", 1e-13, stdErr, 0)
+}
@@ -0,0 +1,100 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/la"
+)
+
+// BcSet collects boundary conditions of different kinds per edge or face tag; e.g. to configure a
+// problem from input data with a single list (see FdmLaplacian.ApplyBcs)
+//
+//   "dirichlet" -- prescribed value u = u({x},t)               (essential condition)
+//   "neumann"   -- prescribed flux density qn = k ∂u/∂n         (natural condition; see AddNbc)
+//   "robin"     -- a⋅u + b⋅∂u/∂n = c({x},t)                     (mixed condition; see RobinBcs)
+type BcSet struct {
+	items []*bcSetItem // conditions in the order they were added
+}
+
+// bcSetItem holds one condition of BcSet
+type bcSetItem struct {
+	tag   int     // edge or face tag
+	kind  string  // "dirichlet", "neumann" or "robin"
+	value fun.Svs // u, qn or c
+	a, b  float64 // coefficients of Robin condition
+}
+
+// NewBcSet returns a new empty set of boundary conditions
+func NewBcSet() (o *BcSet) {
+	return new(BcSet)
+}
+
+// Add adds a boundary condition to an edge or face
+//   tag    -- edge or face tag in grid
+//   kind   -- "dirichlet", "neumann" or "robin"
+//   params -- "dirichlet": "u" constant value [optional if fvalue is given]
+//             "neumann":   "qn" constant flux density [optional if fvalue is given]
+//             "robin":     "a" and "b" coefficients (b ≠ 0) and "c" constant RHS [optional if
+//                          fvalue is given]
+//   fvalue -- function giving u, qn or c (replaces the constant value) [optional]
+func (o *BcSet) Add(tag int, kind string, params dbf.Params, fvalue fun.Svs) {
+	item := &bcSetItem{tag: tag, kind: kind, value: fvalue}
+	var name string
+	switch kind {
+	case "dirichlet":
+		name = "u"
+	case "neumann":
+		name = "qn"
+	case "robin":
+		name = "c"
+		err := params.ConnectSet([]*float64{&item.a, &item.b}, []string{"a", "b"}, "BcSet")
+		if err != "" {
+			chk.Panic(err)
+		}
+		if item.b == 0 {
+			chk.Panic("coefficient b of Robin condition must not be zero\n")
+		}
+	default:
+		chk.Panic("kind of boundary condition %q is invalid. options: \"dirichlet\", \"neumann\" or \"robin\"\n", kind)
+	}
+	if fvalue == nil {
+		var cvalue float64
+		err := params.Connect(&cvalue, name, "BcSet")
+		if err != "" {
+			chk.Panic(err)
+		}
+		item.value = func(x la.Vector, t float64) float64 { return cvalue }
+	}
+	o.items = append(o.items, item)
+}
+
+// ApplyBcs replaces all boundary conditions of the operator by the conditions in a set
+//   NOTE: (1) the essential conditions take precedence at nodes shared with other conditions;
+//             e.g. corners between "dirichlet" and "neumann" edges
+//         (2) the equations are partitioned again by the next Assemble
+func (o *FdmLaplacian) ApplyBcs(bcs *BcSet) {
+	o.EssenBcs = NewBoundaryCondsGrid(o.Grid, 1)
+	o.NaturBcs = NewBoundaryCondsGrid(o.Grid, 1)
+	o.RobinBcs = NewRobinBcsGrid(o.Grid)
+	o.bcsReady = false
+	for _, item := range bcs.items {
+		switch item.kind {
+		case "dirichlet":
+			o.AddEbc(item.tag, 0, item.value)
+		case "neumann":
+			o.AddNbc(item.tag, 0, item.value)
+		case "robin":
+			a, b := item.a, item.b
+			o.RobinBcs.SetInGridFunc(item.tag,
+				func(x la.Vector, t float64) float64 { return a },
+				func(x la.Vector, t float64) float64 { return b },
+				item.value,
+			)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/la"
+)
+
+// FdmBlock holds the rows of [Auu] of FdmLaplacian corresponding to the owned nodes of a block of
+// gm.BlockedGrid, in the local numbering of the block (see FdmLaplacian.AssembleBlocks)
+type FdmBlock struct {
+	Block *gm.GridBlock // block
+	A     *la.Triplet   // [nowned][nlocal] rows of owned nodes; empty rows at nodes with prescribed values
+}
+
+// AssembleBlocks assembles the rows of [Auu] of each block of a blocked grid; i.e. the matrices of
+// the product {y} = [Auu]⋅{x} computed block by block after the halo exchange (see FdmBlock.MatVec)
+//
+//   The row of an owned node I refers to the local indices of I and its neighbours; thus, the
+//   halos must be at least 1 node wide. The columns of nodes with prescribed values are not
+//   included (they correspond to [Auk]) and the rows of these nodes are empty
+//
+//   NOTE: (1) the entries are computed in the same order as in Assemble; thus, the products of
+//             FdmBlock.MatVec are exactly equal to the ones computed with the triplet Eqs.Auu
+//         (2) Eqs is created (but not allocated) if the operator has not been assembled yet
+func (o *FdmLaplacian) AssembleBlocks(bg *gm.BlockedGrid) (blocks []*FdmBlock) {
+	if o.Grid.Ndim() != 2 {
+		chk.Panic("AssembleBlocks works in 2D only\n")
+	}
+	if bg.Grid != o.Grid {
+		chk.Panic("the blocked grid must be based on the grid of the operator\n")
+	}
+	if bg.Halo < 1 {
+		chk.Panic("the width of halo layers must be at least 1. %d is invalid\n", bg.Halo)
+	}
+	nmol := o.molSize() // check options
+	if !o.bcsReady {
+		o.initEqs()
+	}
+	blocks = make([]*FdmBlock, len(bg.Blocks))
+	for b, blk := range bg.Blocks {
+		A := la.NewTriplet(blk.Nowned, len(blk.Nodes), nmol*blk.Nowned)
+		for k, I := range blk.Nodes[:blk.Nowned] {
+			if o.Eqs.FtoU[I] < 0 {
+				continue
+			}
+			o.stencil2d(I, func(_, J int, value float64) {
+				if o.Eqs.FtoU[J] >= 0 {
+					A.Put(k, blk.G2L[J], value)
+				}
+			})
+		}
+		blocks[b] = &FdmBlock{Block: blk, A: A}
+	}
+	return
+}
+
+// MatVec computes the rows of the product {y} = [Auu]⋅{x} corresponding to the owned nodes
+//   y -- [nowned] results at owned nodes; zero at nodes with prescribed values
+//   x -- [nlocal] values at local nodes with updated halos (see gm.BlockedGrid.Exchange); the
+//        values at nodes with prescribed values are ignored
+func (o *FdmBlock) MatVec(y, x la.Vector) {
+	la.SpTriMatVecMul(y, o.A, x)
+}
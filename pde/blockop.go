@@ -0,0 +1,338 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// BlockOperator implements a coupled multi-field operator built from FDM sub-operators defined on
+// the same grid (2D); e.g. thermo-elastic or reaction-diffusion systems with two fields
+//
+//    ┌          ┐ ┌   ┐   ┌    ┐
+//    │ L00  L01 │ │ u │   │ s0 │
+//    │          │ │   │ = │    │
+//    │ L10  L11 │ │ v │   │ s1 │
+//    └          ┘ └   ┘   └    ┘
+//
+//  The diagonal blocks hold the boundary conditions (essential, natural and Robin) and the source
+//  terms of each field. The off-diagonal blocks are coupling operators; e.g. a linear coupling
+//  c⋅v is given by an operator with kx = ky = 0 and kr = -c (see FdmLaplacian). Their boundary
+//  conditions and sources are ignored; however, their Robin conditions modify the diagonal.
+//
+//  Alternatively, the local (reaction) coupling of the fields at each node is given by a dense
+//  [nfields][nfields] matrix R({x}) (see Kr and Reaction); i.e. the term -R⋅{u} with {u} = (u,v)
+//  at each node is added to the equations; e.g. the diagonal of block (i,j) is -R_ij
+//
+//  NOTE: equations are numbered as I + field⋅nnodes; i.e. the blocks of the full system are the
+//        matrices of the sub-operators
+type BlockOperator struct {
+	Blocks   [][]*FdmLaplacian               // [nfields][nfields] sub-operators; off-diagonal blocks may be nil
+	Grid     *gm.Grid                        // grid
+	Eqs      *la.Equations                   // equations of the coupled system
+	Kr       *la.Matrix                      // [nfields][nfields] reaction coupling matrix R (constant) [may be nil]
+	Reaction func(R *la.Matrix, x la.Vector) // computes R at {x} [may be nil ⇒ Kr is used]
+
+	// results of SolveIterative
+	Residuals []float64 // [nfields] residual norms of fields relative to the norms of the RHS of fields
+}
+
+// NewBlockOperator creates a new block operator
+//   blocks -- [nfields][nfields] sub-operators on the same grid; the diagonal ones must not be nil
+func NewBlockOperator(blocks [][]*FdmLaplacian) (o *BlockOperator) {
+	nf := len(blocks)
+	if nf < 1 {
+		chk.Panic("at least one field is required\n")
+	}
+	o = &BlockOperator{Blocks: blocks}
+	for i := 0; i < nf; i++ {
+		if len(blocks[i]) != nf {
+			chk.Panic("blocks must be a square array. len(blocks[%d]) = %d != %d\n", i, len(blocks[i]), nf)
+		}
+		if blocks[i][i] == nil {
+			chk.Panic("diagonal block %d must not be nil\n", i)
+		}
+	}
+	o.Grid = blocks[0][0].Grid
+	if o.Grid.Ndim() != 2 {
+		chk.Panic("BlockOperator works in 2D only\n")
+	}
+	for i := 0; i < nf; i++ {
+		for j := 0; j < nf; j++ {
+			if blocks[i][j] != nil && blocks[i][j].Grid != o.Grid {
+				chk.Panic("all blocks must be defined on the same grid. block (%d,%d) is invalid\n", i, j)
+			}
+			if blocks[i][j] != nil {
+				blocks[i][j].checkDouble("BlockOperator")
+			}
+		}
+	}
+	return
+}
+
+// Assemble assembles the coupled system
+//  reactions -- prepare for computation of RHS
+func (o *BlockOperator) Assemble(reactions bool) {
+
+	// equations
+	nf, nn := len(o.Blocks), o.Grid.Size()
+	var kx []int
+	for i := 0; i < nf; i++ {
+		for _, I := range o.Blocks[i][i].EssenBcs.Nodes() {
+			kx = append(kx, I+i*nn)
+		}
+	}
+	o.Eqs = la.NewEquations(nf*nn, kx)
+	nmol := 0
+	for i := 0; i < nf; i++ {
+		for j := 0; j < nf; j++ {
+			if o.Blocks[i][j] != nil {
+				nmol = utl.Imax(nmol, o.Blocks[i][j].molSize())
+			}
+		}
+	}
+	if o.Kr != nil || o.Reaction != nil {
+		nmol++
+	}
+	nmol *= nf
+	o.Eqs.Alloc([]int{nmol * o.Eqs.Nu, nmol * o.Eqs.Nu, nmol * o.Eqs.Nk, nmol * o.Eqs.Nk}, reactions, true)
+
+	// blocks
+	o.Eqs.Start()
+	for i := 0; i < nf; i++ {
+		for j := 0; j < nf; j++ {
+			op := o.Blocks[i][j]
+			if op == nil {
+				continue
+			}
+			for I := 0; I < nn; I++ {
+				op.stencil2d(I, func(I, J int, value float64) {
+					o.Eqs.Put(I+i*nn, J+j*nn, value)
+				})
+			}
+		}
+	}
+
+	// reaction coupling
+	if o.Kr == nil && o.Reaction == nil {
+		return
+	}
+	R := o.Kr
+	if o.Reaction != nil {
+		R = la.NewMatrix(nf, nf)
+	}
+	if R.M != nf || R.N != nf {
+		chk.Panic("reaction coupling matrix must be [%d][%d]. [%d][%d] is invalid\n", nf, nf, R.M, R.N)
+	}
+	for I := 0; I < nn; I++ {
+		if o.Reaction != nil {
+			o.Reaction(R, o.Grid.Node(I))
+		}
+		for i := 0; i < nf; i++ {
+			for j := 0; j < nf; j++ {
+				if v := R.Get(i, j); v != 0 {
+					o.Eqs.Put(I+i*nn, I+j*nn, -v)
+				}
+			}
+		}
+	}
+}
+
+// SolveSteady solves the coupled steady problem
+//   Output:
+//     u -- [nfields⋅nnodes] solution; see Field
+//     f -- [nfields⋅nnodes] right-hand side (with reactions) if reactions == true
+func (o *BlockOperator) SolveSteady(reactions bool) (u, f []float64) {
+	if o.Eqs == nil {
+		chk.Panic("operator must be assembled before calling SolveSteady\n")
+	}
+	o.Eqs.SolveOnce(o.calcXk, o.calcBu)
+	u = make([]float64, o.Eqs.N)
+	o.Eqs.JoinVector(u, o.Eqs.Xu, o.Eqs.Xk)
+	if reactions {
+		f = make([]float64, o.Eqs.N)
+		if o.Eqs.Nk > 0 { // need to calc Bu again because it was modified
+			for i, I := range o.Eqs.UtoF {
+				o.Eqs.Bu[i] = o.calcBu(I, 0)
+			}
+		}
+		o.Eqs.JoinVector(f, o.Eqs.Bu, o.Eqs.Bk)
+	}
+	return
+}
+
+// SolveIterative solves the coupled steady problem with the restarted GMRES method (see
+// FdmLaplacian.SolveIterative) with convergence checked on the sub-residual of each field
+//
+//   ‖{r}_f‖ ≤ tol_f ⋅ ‖{b}_f‖     for all fields f
+//
+//   where {r}_f and {b}_f are the parts of the residual and the right-hand side of the u-system
+//   corresponding to field f; e.g. fields with disparate scales (temperature and displacements)
+//   are solved with their own relative tolerances
+//
+//   Input:
+//     opts -- options [may be nil ⇒ defaults]; Tol or FieldTol (per field), MaxIt, Precond,
+//             Restart and Guess ([nfields⋅nnodes]) are used. Method must be "" or "gmres"
+//   Output:
+//     u   -- [nfields⋅nnodes] solution; see Field
+//     nit -- total number of iterations
+//
+//   NOTE: (1) the rows of each field are divided by ‖{b}_f‖; i.e. the fields have the same scale
+//             in the residual minimised by GMRES. The tolerance of GMRES is then tightened after
+//             each solve by the ratios of the sub-residuals of the fields that have not converged.
+//             The tolerance min_f(tol_f)/√nfields is sufficient for all fields; this is the limit
+//             of the tightening
+//         (2) panics if the method does not converge after MaxIt iterations
+//         (3) the final relative residuals of fields are given in Residuals
+func (o *BlockOperator) SolveIterative(opts *SolveOptions) (u []float64, nit int) {
+
+	// options
+	if o.Eqs == nil {
+		chk.Panic("operator must be assembled before calling SolveIterative\n")
+	}
+	var opt SolveOptions
+	if opts != nil {
+		opt = *opts
+	}
+	nf, nn := len(o.Blocks), o.Grid.Size()
+	if opt.Tol <= 0 {
+		opt.Tol = 1e-10
+	}
+	tols := make([]float64, nf)
+	for f := range tols {
+		tols[f] = opt.Tol
+	}
+	if opt.FieldTol != nil {
+		if len(opt.FieldTol) != nf {
+			chk.Panic("number of field tolerances must be equal to the number of fields. %d != %d\n", len(opt.FieldTol), nf)
+		}
+		copy(tols, opt.FieldTol)
+	}
+	if opt.MaxIt <= 0 {
+		opt.MaxIt = 10 * o.Eqs.Nu
+	}
+	if opt.Restart <= 0 {
+		opt.Restart = 30
+	}
+	if opt.Method != "" && opt.Method != "gmres" {
+		chk.Panic("method %q is invalid. BlockOperator.SolveIterative works with \"gmres\" only\n", opt.Method)
+	}
+	if opt.Guess != nil && len(opt.Guess) != o.Eqs.N {
+		chk.Panic("size of initial guess must be equal to nfields⋅nnodes = %d. %d is invalid\n", o.Eqs.N, len(opt.Guess))
+	}
+
+	// right-hand side: {bu} = {s} - [Auk]⋅{xk}
+	bu := la.NewVector(o.Eqs.Nu)
+	xk := la.NewVector(o.Eqs.Nk)
+	for i, I := range o.Eqs.UtoF {
+		bu[i] = o.calcBu(I, 0)
+	}
+	for i, I := range o.Eqs.KtoF {
+		xk[i] = o.calcXk(I, 0)
+	}
+	if o.Eqs.Nk > 0 {
+		la.SpMatVecMulAdd(bu, -1, o.Eqs.Auk.ToMatrix(nil), xk)
+	}
+	xu := la.NewVector(o.Eqs.Nu)
+	if opt.Guess != nil {
+		for i, I := range o.Eqs.UtoF {
+			xu[i] = opt.Guess[I]
+		}
+	}
+
+	// scaled system: the rows of each field are divided by the norm of the RHS of the field
+	field := make([]int, o.Eqs.Nu) // field of each equation
+	bnorm := make([]float64, nf)
+	for i, I := range o.Eqs.UtoF {
+		field[i] = I / nn
+		bnorm[field[i]] += bu[i] * bu[i]
+	}
+	sq := math.Sqrt(float64(nf)) // norm of the scaled RHS (if all fields have non-zero RHS)
+	τmin, τ := math.Inf(1), 0.0  // sufficient (smallest) and initial (largest) tolerances
+	for f := 0; f < nf; f++ {
+		bnorm[f] = math.Sqrt(bnorm[f])
+		if bnorm[f] == 0 {
+			bnorm[f] = 1
+		}
+		τmin = math.Min(τmin, tols[f]/sq)
+		τ = math.Max(τ, tols[f]/sq)
+	}
+	rows, cols, vals := o.Eqs.Auu.ToMatrix(nil).Triplets()
+	t := la.NewTriplet(o.Eqs.Nu, o.Eqs.Nu, len(vals))
+	for k, v := range vals {
+		t.Put(rows[k], cols[k], v/bnorm[field[rows[k]]])
+	}
+	a := t.ToMatrix(nil)
+	for i := range bu {
+		bu[i] /= bnorm[field[i]]
+	}
+	var pc la.Preconditioner
+	switch opt.Precond {
+	case "none":
+	case "", "jacobi":
+		pc = la.NewPrecondJacobi(a)
+	case "ssor":
+		pc = la.NewPrecondSSOR(a, 1)
+	default:
+		chk.Panic("preconditioner %q is invalid. options: \"none\", \"jacobi\" or \"ssor\"\n", opt.Precond)
+	}
+
+	// solve and tighten the tolerance until all fields converge
+	r := la.NewVector(o.Eqs.Nu)
+	o.Residuals = make([]float64, nf)
+	for {
+		nit += la.Gmres(xu, a, bu, pc, τ, opt.MaxIt-nit, opt.Restart)
+		copy(r, bu)
+		la.SpMatVecMulAdd(r, -1, a, xu)
+		for f := range o.Residuals {
+			o.Residuals[f] = 0
+		}
+		for i, v := range r {
+			o.Residuals[field[i]] += v * v
+		}
+		factor := 1.0
+		for f := 0; f < nf; f++ {
+			o.Residuals[f] = math.Sqrt(o.Residuals[f])
+			if o.Residuals[f] > tols[f] {
+				factor = math.Min(factor, 0.5*tols[f]/o.Residuals[f])
+			}
+		}
+		if factor == 1 || τ <= τmin {
+			break
+		}
+		τ = math.Max(τ*factor, τmin)
+	}
+	u = make([]float64, o.Eqs.N)
+	o.Eqs.JoinVector(u, xu, xk)
+	return
+}
+
+// Field returns the part of a vector of the coupled system corresponding to a field
+//   u     -- [nfields⋅nnodes] vector; e.g. the solution
+//   field -- index of field
+//   uf    -- [nnodes] values of field (slice of u; not a copy)
+func (o *BlockOperator) Field(u []float64, field int) (uf []float64) {
+	nn := o.Grid.Size()
+	return u[field*nn : (field+1)*nn]
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// calcXk calculates known {u} values (CalcXk in la.Equations)
+func (o *BlockOperator) calcXk(I int, t float64) float64 {
+	nn := o.Grid.Size()
+	return o.Blocks[I/nn][I/nn].calcXk(I%nn, t)
+}
+
+// calcBu calculates RHS vector (CalcBu in la.Equations)
+func (o *BlockOperator) calcBu(I int, t float64) float64 {
+	nn := o.Grid.Size()
+	return o.Blocks[I/nn][I/nn].calcBu(I%nn, t)
+}
@@ -5,6 +5,7 @@
 package pde
 
 import (
+	"math"
 	"sort"
 
 	"github.com/cpmech/gosl/chk"
@@ -26,6 +27,31 @@ type BoundaryConds struct {
 	n2i  []int       // [nnodesTotal] maps node ID to position in fcns and tags; -1 means not set
 }
 
+// BcNodeData holds the data of a node with prescribed boundary condition (e.g. for debugging)
+type BcNodeData struct {
+	Node  int       // node index
+	Dof   int       // index of "degree-of-freedom"
+	Tags  []int     // tags used to set the boundary condition at node
+	X     la.Vector // coordinates of node
+	Value float64   // value of boundary condition
+}
+
+// GridMismatchError indicates that boundary conditions were created with a grid different from the
+// grid of the operator (see BoundaryConds.CheckGrid and FdmLaplacian.SetBcs)
+type GridMismatchError struct {
+	Kind   string // kind of conditions; e.g. "essential" [may be empty]
+	Reason string // description of the difference
+}
+
+// Error returns the error message
+func (o *GridMismatchError) Error() string {
+	what := "boundary conditions"
+	if o.Kind != "" {
+		what = o.Kind + " " + what
+	}
+	return io.Sf("%s were created for another grid: %s", what, o.Reason)
+}
+
 // NewBoundaryCondsGrid returns a new structure using Grid
 func NewBoundaryCondsGrid(grid *gm.Grid, ndof int) (o *BoundaryConds) {
 	o = new(BoundaryConds)
@@ -46,6 +72,19 @@ func NewBoundaryCondsMesh(mesh *msh.Mesh, ndof int) (o *BoundaryConds) {
 	return
 }
 
+// CheckGrid checks whether the conditions were created for the given grid or for a grid with the
+// same number of points and coordinates; e.g. a grid generated again with the same data
+//   err -- *GridMismatchError if the grids are different (or the conditions use a mesh)
+func (o *BoundaryConds) CheckGrid(grid *gm.Grid) (err error) {
+	if o.grid == nil {
+		return &GridMismatchError{"", "the conditions were created for a mesh"}
+	}
+	if reason := gridDifference(o.grid, grid); reason != "" {
+		return &GridMismatchError{"", reason}
+	}
+	return nil
+}
+
 // Has tells whether node has prescribed boundary condition or not
 func (o *BoundaryConds) Has(node int) bool {
 	return o.n2i[node] >= 0
@@ -68,6 +107,10 @@ func (o *BoundaryConds) NormalGrid(N la.Vector, node int) {
 		return
 	}
 	tt := o.tags[o.n2i[node]]
+	if len(tt) == 0 { // e.g. interior node
+		N.Fill(0)
+		return
+	}
 	o.grid.UnitNormal(N, tt[0], node)
 	if len(tt) > 1 {
 		Ntmp := la.NewVector(o.grid.Ndim())
@@ -110,18 +153,204 @@ func (o *BoundaryConds) AddUsingTag(tag, dof int, cvalue float64, fvalue fun.Svs
 	}
 
 	// set
+	o.set(nodes, dof, f, []int{tag})
+}
+
+// AddUsingTagWhere sets boundary condition on the part of an edge or face (from grid or mesh)
+// where a predicate is true; e.g. to split one edge into essential and natural portions
+//   tag    -- edge or face tag
+//   dof    -- index of "degree-of-freedom"; e.g. 0⇒horizontal displacement, 1⇒vertical displacement
+//   cvalue -- constant value [optional]; or
+//   fvalue -- function value [optional]
+//   where  -- predicate selecting nodes by their coordinates; e.g. x[0] ≤ 1
+//   NOTE: the selected nodes keep the tag; e.g. to compute normals
+func (o *BoundaryConds) AddUsingTagWhere(tag, dof int, cvalue float64, fvalue fun.Svs, where func(x la.Vector) bool) {
+
+	// use or create function
+	f := fvalue
+	if fvalue == nil {
+		f = func(x la.Vector, t float64) float64 { return cvalue }
+	}
+
+	// selected nodes
+	var nodes []int
+	if o.grid != nil {
+		for _, I := range o.grid.Boundary(tag) {
+			if where(o.grid.Node(I)) {
+				nodes = append(nodes, I)
+			}
+		}
+	}
+	if o.mesh != nil {
+		for _, I := range o.mesh.Boundary(tag) {
+			if where(o.mesh.Verts[I].X) {
+				nodes = append(nodes, I)
+			}
+		}
+	}
+
+	// check
+	if nodes == nil {
+		chk.Panic("cannot find nodes with tag=%d satisfying the predicate\n", tag)
+	}
+
+	// set
+	o.set(nodes, dof, f, []int{tag})
+}
+
+// AddUsingNodes sets boundary condition using a list of nodes; e.g. interior nodes (internal boundaries)
+//   nodes  -- indices of nodes; e.g. from grid.IndexMNPtoI
+//   dof    -- index of "degree-of-freedom"; e.g. 0⇒horizontal displacement, 1⇒vertical displacement
+//   cvalue -- constant value [optional]; or
+//   fvalue -- function value [optional]
+//   NOTE: these nodes have no tags
+func (o *BoundaryConds) AddUsingNodes(nodes []int, dof int, cvalue float64, fvalue fun.Svs) {
+
+	// use or create function
+	f := fvalue
+	if fvalue == nil {
+		f = func(x la.Vector, t float64) float64 { return cvalue }
+	}
+
+	// check
 	for _, n := range nodes {
-		if o.n2i[n] < 0 { // new
-			o.n2i[n] = len(o.fcns)
-			ff := make([]fun.Svs, o.ndof)
-			ff[dof] = f
-			o.fcns = append(o.fcns, ff)
-			o.tags = append(o.tags, []int{tag})
-		} else { // existent
-			o.fcns[o.n2i[n]][dof] = f
-			o.tags[o.n2i[n]] = append(o.tags[o.n2i[n]], tag)
+		if n < 0 || n >= len(o.n2i) {
+			chk.Panic("node %d is out of range [0, %d)\n", n, len(o.n2i))
+		}
+	}
+
+	// set
+	o.set(nodes, dof, f, nil)
+}
+
+// SetAtPoint sets boundary condition at the node nearest to a point given by its coordinates
+// (e.g. a point-probe condition) [grid only]
+//   x      -- coordinates of point
+//   dof    -- index of "degree-of-freedom"
+//   cvalue -- constant value [optional]; or
+//   fvalue -- function value [optional]
+//   tol    -- maximum distance between x and the nearest node
+//   node   -- returns the index of the node
+//   NOTE: the node may be an interior node and has no tags (see AddUsingNodes)
+func (o *BoundaryConds) SetAtPoint(x la.Vector, dof int, cvalue float64, fvalue fun.Svs, tol float64) (node int) {
+
+	// check
+	if o.grid == nil {
+		chk.Panic("SetAtPoint requires a grid\n")
+	}
+	if len(x) != o.grid.Ndim() {
+		chk.Panic("size of x must be equal to the space dimension. %d != %d\n", len(x), o.grid.Ndim())
+	}
+
+	// find nearest node
+	node = -1
+	dmin := math.Inf(1)
+	for I := 0; I < o.grid.Size(); I++ {
+		d := 0.0
+		for i, xi := range o.grid.Node(I) {
+			d += (xi - x[i]) * (xi - x[i])
+		}
+		if d < dmin {
+			node, dmin = I, d
 		}
 	}
+	dmin = math.Sqrt(dmin)
+	if dmin > tol {
+		chk.Panic("cannot find node near x=%v: the nearest node %d is at a distance %g > tol=%g\n", x, node, dmin, tol)
+	}
+
+	// set
+	o.AddUsingNodes([]int{node}, dof, cvalue, fvalue)
+	return
+}
+
+// Update updates the prescribed values of all nodes with given tag, without changing the set of nodes
+//   tag    -- edge or face tag used in AddUsingTag
+//   dof    -- index of "degree-of-freedom"
+//   cvalue -- constant value [optional]; or
+//   fvalue -- function value [optional]
+//   NOTE: corner nodes shared with other tags also receive the new value
+func (o *BoundaryConds) Update(tag, dof int, cvalue float64, fvalue fun.Svs) {
+
+	// use or create function
+	f := fvalue
+	if fvalue == nil {
+		f = func(x la.Vector, t float64) float64 { return cvalue }
+	}
+
+	// update
+	found := false
+	for _, i := range o.n2i {
+		if i < 0 || o.fcns[i][dof] == nil {
+			continue
+		}
+		for _, t := range o.tags[i] {
+			if t == tag {
+				o.fcns[i][dof] = f
+				found = true
+				break
+			}
+		}
+	}
+
+	// check
+	if !found {
+		chk.Panic("cannot find nodes with tag=%d and dof=%d\n", tag, dof)
+	}
+}
+
+// SetTotalFlux sets a natural boundary condition given the total flux over an edge or face [grid only]
+//   tag       -- edge or face tag
+//   dof       -- index of "degree-of-freedom"
+//   totalFlux -- total flux over the edge (2D) or face (3D); e.g. heat in watts
+//   NOTE: the total flux is distributed evenly; i.e. the flux density is totalFlux divided by the
+//         length (2D) or area (3D) of the edge or face. Thus, the trapezoidal integration of the
+//         flux density over the edge or face recovers totalFlux
+func (o *BoundaryConds) SetTotalFlux(tag, dof int, totalFlux float64) {
+	if o.grid == nil {
+		chk.Panic("SetTotalFlux requires a grid\n")
+	}
+	measure := 1.0 // length or area
+	normal := tag/10 - 1
+	if o.grid.Ndim() == 3 {
+		normal = tag/100 - 1
+	}
+	if normal < 0 || normal >= o.grid.Ndim() || tag%10 > 1 {
+		chk.Panic("tag %d is invalid\n", tag)
+	}
+	for dim := 0; dim < o.grid.Ndim(); dim++ {
+		if dim != normal {
+			measure *= o.grid.Xlen(dim)
+		}
+	}
+	o.AddUsingTag(tag, dof, totalFlux/measure, nil)
+}
+
+// SetSymmetryPlane sets the symmetry condition (zero normal flux) on an edge or face [grid only]
+//   tag -- edge or face tag of the symmetry plane
+//   dof -- index of "degree-of-freedom"
+//   NOTE: (1) this is equivalent to a homogeneous natural condition; e.g. to solve symmetric
+//             problems on half or a quarter of the domain
+//         (2) the nodes with conditions already set (e.g. corners shared with other edges) are
+//             not modified; thus, the symmetry planes should be set after the other conditions
+func (o *BoundaryConds) SetSymmetryPlane(tag, dof int) {
+	if o.grid == nil {
+		chk.Panic("SetSymmetryPlane requires a grid\n")
+	}
+	normal := tag/10 - 1
+	if o.grid.Ndim() == 3 {
+		normal = tag/100 - 1
+	}
+	if normal < 0 || normal >= o.grid.Ndim() || tag%10 > 1 {
+		chk.Panic("tag %d is invalid\n", tag)
+	}
+	var nodes []int
+	for _, n := range o.grid.Boundary(tag) {
+		if o.n2i[n] < 0 {
+			nodes = append(nodes, n)
+		}
+	}
+	o.set(nodes, dof, func(x la.Vector, t float64) float64 { return 0 }, []int{tag})
 }
 
 // Nodes returns (unique/sorted) list of nodes with prescribed boundary conditions
@@ -179,3 +408,83 @@ func (o *BoundaryConds) Print() (l string) {
 	}
 	return
 }
+
+// DumpNodes returns the data of all nodes with prescribed boundary conditions @ time t
+//  NOTE: (1) the results are sorted by node index and then by dof
+//        (2) this is useful to check function-based boundary conditions; e.g. at corners
+func (o *BoundaryConds) DumpNodes(t float64) (list []*BcNodeData) {
+	for _, n := range o.Nodes() {
+		for dof := 0; dof < o.ndof; dof++ {
+			tags, val, available := o.Value(n, dof, t)
+			if !available {
+				continue
+			}
+			var x la.Vector
+			if o.grid != nil {
+				x = o.grid.Node(n)
+			} else {
+				x = o.mesh.Verts[n].X
+			}
+			list = append(list, &BcNodeData{Node: n, Dof: dof, Tags: tags, X: x.GetCopy(), Value: val})
+		}
+	}
+	return
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// set sets function f for given nodes and dof and appends tags
+func (o *BoundaryConds) set(nodes []int, dof int, f fun.Svs, tags []int) {
+	for _, n := range nodes {
+		if o.n2i[n] < 0 { // new
+			o.n2i[n] = len(o.fcns)
+			ff := make([]fun.Svs, o.ndof)
+			ff[dof] = f
+			o.fcns = append(o.fcns, ff)
+			o.tags = append(o.tags, append([]int{}, tags...))
+		} else { // existent
+			o.fcns[o.n2i[n]][dof] = f
+			o.tags[o.n2i[n]] = append(o.tags[o.n2i[n]], tags...)
+		}
+	}
+}
+
+// gridDifference describes the difference between the grid a of conditions and the grid b of an
+// operator; i.e. the number of points or the coordinates of nodes [empty if equivalent]
+func gridDifference(a, b *gm.Grid) (reason string) {
+	if a == b {
+		return
+	}
+	npts := func(g *gm.Grid) (l string) {
+		for dim := 0; dim < g.Ndim(); dim++ {
+			if dim > 0 {
+				l += "×"
+			}
+			l += io.Sf("%d", g.Npts(dim))
+		}
+		return
+	}
+	if a.Ndim() != b.Ndim() || a.Size() != b.Size() || npts(a) != npts(b) {
+		return io.Sf("the grid of the conditions has %s points but the grid of the operator has %s points", npts(a), npts(b))
+	}
+	for I := 0; I < a.Size(); I++ {
+		xa, xb := a.Node(I), b.Node(I)
+		for dim := 0; dim < a.Ndim(); dim++ {
+			if math.Abs(xa[dim]-xb[dim]) > 1e-12*(1+math.Abs(xb[dim])) {
+				return io.Sf("the coordinates of node %d are different: %v != %v", I, xa, xb)
+			}
+		}
+	}
+	return
+}
+
+// checkBcsGrid checks the grids of the essential, natural and Robin conditions of an operator
+func checkBcsGrid(grid *gm.Grid, essen, natur *BoundaryConds, robin *RobinBcs) (err error) {
+	for i, bcs := range []*BoundaryConds{essen, natur} {
+		if err = bcs.CheckGrid(grid); err != nil {
+			err.(*GridMismatchError).Kind = []string{"essential", "natural"}[i]
+			return
+		}
+	}
+	return robin.CheckGrid(grid)
+}
@@ -0,0 +1,134 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+)
+
+// ProblemCache holds a problem "template" with the FDM Laplacian on a uniform 2D grid; i.e. the
+// grid, the assembled operator and the factorisation of [Auu] are re-used by parameter sweeps in
+// which only the values of essential boundary conditions or the source term change
+//
+//   The data is invalidated as follows:
+//     geometry (xmin, xmax, npts) changed                    ⇒ new grid, operator and factorisation
+//     operator name, coefficients or tags of edges changed   ⇒ new operator and factorisation
+//     values of boundary conditions or source changed        ⇒ new RHS only
+//
+//   Example:
+//
+//     cache := new(ProblemCache)
+//     for _, v := range values {
+//         op := cache.Get("laplacian", params, xmin, xmax, npts, []int{10, 11})
+//         op.Source = ... // optional
+//         u := cache.Solve([]float64{0, v})
+//     }
+//     cache.Free()
+type ProblemCache struct {
+	Ngrids int // number of grids generated
+	Nassem int // number of operators assembled
+	Nfact  int // number of factorisations of [Auu]
+
+	// data
+	grid    *gm.Grid      // cached grid
+	op      *FdmLaplacian // cached operator
+	tags    []int         // tags of edges with essential conditions
+	gridKey string        // key of grid (geometry)
+	opKey   string        // key of operator (structural parameters)
+}
+
+// Get returns the operator (assembled) with the given parameters; cached data are re-used if the
+// parameters are unchanged
+//   opName -- "laplacian" (5-point stencil) or "laplacian9" (compact 9-point stencil; see Mehrstellen)
+//   params -- coefficients; see NewFdmLaplacian
+//   xmin   -- [2] min coordinates of the domain
+//   xmax   -- [2] max coordinates of the domain
+//   npts   -- [2] number of points along each direction
+//   tags   -- tags of edges with essential conditions; e.g. {10, 11, 20, 21}. The values are given
+//             to Solve
+//   NOTE: (1) the Source function (or SetSourceVector) of the returned operator may be changed
+//             without invalidating the cache; however, the coefficients must not be modified
+//             directly: call Get instead
+//         (2) the factorisation of the previous operator is released if a new one is created; the
+//             source function of the previous operator is kept
+func (o *ProblemCache) Get(opName string, params dbf.Params, xmin, xmax []float64, npts, tags []int) (op *FdmLaplacian) {
+
+	// check
+	if opName != "laplacian" && opName != "laplacian9" {
+		chk.Panic("operator name %q is invalid. options: \"laplacian\" or \"laplacian9\"\n", opName)
+	}
+	if len(xmin) != 2 || len(xmax) != 2 || len(npts) != 2 {
+		chk.Panic("ProblemCache works in 2D only. len(xmin)=%d, len(xmax)=%d and len(npts)=%d are invalid\n", len(xmin), len(xmax), len(npts))
+	}
+
+	// grid
+	gridKey := io.Sf("%v|%v|%v", xmin, xmax, npts)
+	if o.grid == nil || gridKey != o.gridKey {
+		o.grid = new(gm.Grid)
+		o.grid.RectGenUniform(xmin, xmax, npts)
+		o.gridKey = gridKey
+		o.opKey = ""
+		o.Ngrids++
+	}
+
+	// operator
+	opKey := io.Sf("%s|%v|", opName, tags)
+	for _, p := range params {
+		opKey += io.Sf("%s=%g;", p.N, p.V)
+	}
+	if o.op != nil && opKey == o.opKey {
+		return o.op
+	}
+	var source fun.Svs
+	if o.op != nil {
+		source = o.op.Source // keep the source of the previous operator
+		o.op.Free()
+	}
+	o.op = NewFdmLaplacian(params, o.grid, source)
+	o.op.Mehrstellen = opName == "laplacian9"
+	for _, tag := range tags {
+		o.op.AddEbc(tag, 0, nil)
+	}
+	if err := o.op.Assemble(false); err != nil {
+		chk.Panic("%v\n", err)
+	}
+	o.tags = make([]int, len(tags))
+	copy(o.tags, tags)
+	o.opKey = opKey
+	o.Nassem++
+	return o.op
+}
+
+// Solve solves the problem with the current operator (see Get) and the given values of essential
+// boundary conditions; the factorisation of [Auu] is computed only in the first call after Get
+// has created the operator
+//   values -- [len(tags)] constant values at the edges with tags given to Get (same order)
+//   u      -- [nnodes] solution at all nodes
+func (o *ProblemCache) Solve(values []float64) (u []float64) {
+	if o.op == nil {
+		chk.Panic("Get must be called before Solve\n")
+	}
+	if len(values) != len(o.tags) {
+		chk.Panic("number of values must be equal to the number of tags. %d != %d\n", len(values), len(o.tags))
+	}
+	for k, tag := range o.tags {
+		o.op.UpdateEbc(tag, values[k], nil)
+	}
+	if o.op.solver == nil {
+		o.Nfact++
+	}
+	return o.op.ReapplyBcs()
+}
+
+// Free releases the factorisation of the current operator
+func (o *ProblemCache) Free() {
+	if o.op != nil {
+		o.op.Free()
+	}
+}
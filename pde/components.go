@@ -0,0 +1,69 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/gm"
+)
+
+// ConnectedComponents labels the connected components of the active nodes of a grid
+//
+//  Two active nodes are connected if they are neighbours in the FDM stencil; i.e. left/right,
+//  bottom/top (and behind/front in 3D). A domain with more than one component results in a
+//  singular system unless each component has at least one node with essential boundary condition.
+//
+//   Input:
+//     grid   -- the grid
+//     active -- [nnodes] flags indicating active nodes (e.g. not in holes)
+//   Output:
+//     labels -- [nnodes] component of each active node: 0, 1, ..., ncomponents-1; or -1 if inactive
+//               components are numbered in increasing order of their smallest node
+func ConnectedComponents(grid *gm.Grid, active []bool) (labels []int) {
+
+	// check
+	if len(active) != grid.Size() {
+		chk.Panic("size of active flags must be equal to the number of nodes. %d != %d\n", len(active), grid.Size())
+	}
+
+	// initialise labels
+	labels = make([]int, grid.Size())
+	for I := 0; I < grid.Size(); I++ {
+		labels[I] = -1
+	}
+
+	// flood fill
+	ndim := grid.Ndim()
+	ncomp := 0
+	stack := make([]int, 0, grid.Size())
+	for seed := 0; seed < grid.Size(); seed++ {
+		if !active[seed] || labels[seed] >= 0 {
+			continue
+		}
+		labels[seed] = ncomp
+		stack = append(stack[:0], seed)
+		for len(stack) > 0 {
+			I := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			m, n, p := grid.IndexItoMNP(I)
+			idx := []int{m, n, p}
+			for dim := 0; dim < ndim; dim++ {
+				for _, delta := range []int{-1, +1} {
+					idx[dim] += delta
+					if idx[dim] >= 0 && idx[dim] < grid.Npts(dim) {
+						J := grid.IndexMNPtoI(idx[0], idx[1], idx[2])
+						if active[J] && labels[J] < 0 {
+							labels[J] = ncomp
+							stack = append(stack, J)
+						}
+					}
+					idx[dim] -= delta
+				}
+			}
+		}
+		ncomp++
+	}
+	return
+}
@@ -0,0 +1,234 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// ConvergenceTable holds the results of a convergence study
+type ConvergenceTable struct {
+	H     []float64 // [nres] grid spacing (largest along all directions)
+	ErrL2 []float64 // [nres] L2 norm of error
+	Order []float64 // [nres] observed order between consecutive resolutions; Order[0] = 0
+	Slope float64   // estimated order: least-squares slope of log(ErrL2) versus log(H)
+}
+
+// ConvergenceStudy solves a problem with the FDM Laplacian on a sequence of uniform 2D grids and
+// measures the errors with respect to the exact solution
+//
+//   The exact solution is prescribed at all boundaries. The L2 norm of the error is computed
+//   with the trapezoidal rule (see GridQuadrature)
+//
+//   Input:
+//     opName   -- "laplacian" (5-point stencil) or "laplacian9" (compact 9-point stencil; see Mehrstellen)
+//     params   -- parameters of the operator; see NewFdmLaplacian
+//     xmin     -- [2] min coordinates of the domain
+//     xmax     -- [2] max coordinates of the domain
+//     ndivList -- [nres] number of divisions along each direction; e.g. {8, 16, 32}
+//     uExact   -- exact solution u({x})
+//     source   -- source term s({x}) consistent with uExact [may be nil]
+//   Output:
+//     table -- spacings, errors and observed orders
+func ConvergenceStudy(opName string, params dbf.Params, xmin, xmax []float64, ndivList []int, uExact, source fun.Svs) (table *ConvergenceTable) {
+
+	// check
+	if opName != "laplacian" && opName != "laplacian9" {
+		chk.Panic("operator name %q is invalid. options: \"laplacian\" or \"laplacian9\"\n", opName)
+	}
+	if len(xmin) != 2 || len(xmax) != 2 {
+		chk.Panic("ConvergenceStudy works in 2D only. len(xmin)=%d and len(xmax)=%d are invalid\n", len(xmin), len(xmax))
+	}
+	if len(ndivList) < 2 {
+		chk.Panic("at least two resolutions are required. len(ndivList)=%d is invalid\n", len(ndivList))
+	}
+
+	// solve for each resolution
+	nres := len(ndivList)
+	table = &ConvergenceTable{H: make([]float64, nres), ErrL2: make([]float64, nres), Order: make([]float64, nres)}
+	for k, ndiv := range ndivList {
+
+		// grid
+		for i := 0; i < 2; i++ {
+			table.H[k] = math.Max(table.H[k], (xmax[i]-xmin[i])/float64(ndiv))
+		}
+		g := new(gm.Grid)
+		g.RectGenUniform(xmin, xmax, []int{ndiv + 1, ndiv + 1})
+
+		// operator
+		s := NewFdmLaplacian(params, g, source)
+		s.Mehrstellen = opName == "laplacian9"
+		for _, tag := range []int{10, 11, 20, 21} {
+			s.AddEbc(tag, 0, uExact)
+		}
+
+		// solve
+		if err := s.Assemble(false); err != nil {
+			chk.Panic("%v\n", err)
+		}
+		u, _ := s.SolveSteady(false)
+
+		// error
+		w := GridQuadrature(g)
+		for I := 0; I < g.Size(); I++ {
+			e := u[I] - uExact(g.Node(I), 0)
+			table.ErrL2[k] += w[I] * e * e
+		}
+		table.ErrL2[k] = math.Sqrt(table.ErrL2[k])
+		if k > 0 {
+			table.Order[k] = math.Log(table.ErrL2[k-1]/table.ErrL2[k]) / math.Log(table.H[k-1]/table.H[k])
+		}
+	}
+
+	// least-squares slope
+	var sx, sy, sxx, sxy float64
+	for k := 0; k < nres; k++ {
+		x, y := math.Log(table.H[k]), math.Log(table.ErrL2[k])
+		sx += x
+		sy += y
+		sxx += x * x
+		sxy += x * y
+	}
+	n := float64(nres)
+	table.Slope = (n*sxy - sx*sy) / (n*sxx - sx*sx)
+	return
+}
+
+// String returns a formatted table
+func (o *ConvergenceTable) String() (l string) {
+	l = io.Sf("%12s%14s%8s\n", "h", "L2 error", "order")
+	for k := range o.H {
+		if k == 0 {
+			l += io.Sf("%12.5e%14.5e%8s\n", o.H[k], o.ErrL2[k], "-")
+		} else {
+			l += io.Sf("%12.5e%14.5e%8.3f\n", o.H[k], o.ErrL2[k], o.Order[k])
+		}
+	}
+	l += io.Sf("estimated order = %.3f\n", o.Slope)
+	return
+}
+
+// TruncationError computes the local truncation error of the discrete operator at each node
+//
+//   τ[I] = (A⋅ū)[I] - L{u}({x}_I)
+//
+//   where ū is the exact function sampled at the nodes (see DiscreteSource) and L{u} is the
+//   analytic value of the operator. The equations of boundary nodes use mirrored (ghost) nodes;
+//   thus, τ is O(1/h) there unless the normal derivative vanishes. This helps to localise where a
+//   stencil is inconsistent
+//
+//   Input:
+//     op     -- the operator; Assemble must be called first
+//     grid   -- the grid of the operator
+//     uExact -- exact function u({x},t) sampled at t = 0
+//     lExact -- analytic value of the operator L{u}({x},t) at t = 0
+//   Output:
+//     tau -- [nnodes] truncation error; zero at nodes with prescribed values
+//
+//   NOTE: the correction of the RHS of the Mehrstellen stencil is not considered; i.e. τ = O(h²)
+//         is obtained for this stencil as well
+func TruncationError(op *FdmLaplacian, grid *gm.Grid, uExact, lExact fun.Svs) (tau []float64) {
+	if grid != op.Grid {
+		chk.Panic("grid must be the grid of the operator\n")
+	}
+	tau = op.DiscreteSource(uExact)
+	for _, I := range op.Eqs.UtoF {
+		tau[I] -= lExact(grid.Node(I), 0)
+	}
+	return
+}
+
+// CheckSelfAdjoint checks whether the reduced system (after the essential boundary conditions have
+// been applied) is self-adjoint; i.e. symmetric. This helps to find asymmetric handling of
+// boundary conditions. For three pairs of random vectors {u} and {v}, the violation is
+//
+//               |{u}ᵀ⋅[Auu]⋅{v} - {v}ᵀ⋅[Auu]⋅{u}|
+//    viol = ———————————————————————————————————————————
+//            ‖{u}‖⋅‖[Auu]⋅{v}‖ + ‖{v}‖⋅‖[Auu]⋅{u}‖
+//
+//   Input:
+//     e   -- equations with assembled [Auu]; e.g. FdmLaplacian.Eqs after Assemble
+//     tol -- tolerance on the violation
+//   Output:
+//     ok        -- viol ≤ tol for all pairs
+//     violation -- maximum violation
+//
+//   NOTE: the random vectors are generated with a fixed seed; thus, the results are reproducible
+func CheckSelfAdjoint(e *la.Equations, tol float64) (ok bool, violation float64) {
+	if e.Auu == nil {
+		chk.Panic("the matrix Auu of the equations must be assembled\n")
+	}
+	a := e.Auu.ToMatrix(nil)
+	rng := rand.New(rand.NewSource(1234))
+	u := la.NewVector(e.Nu)
+	v := la.NewVector(e.Nu)
+	au := la.NewVector(e.Nu)
+	av := la.NewVector(e.Nu)
+	for trial := 0; trial < 3; trial++ {
+		for i := 0; i < e.Nu; i++ {
+			u[i] = rng.Float64()*2 - 1
+			v[i] = rng.Float64()*2 - 1
+		}
+		la.SpMatVecMul(au, 1, a, u)
+		la.SpMatVecMul(av, 1, a, v)
+		scale := u.Norm()*av.Norm() + v.Norm()*au.Norm()
+		if scale == 0 {
+			continue
+		}
+		violation = math.Max(violation, math.Abs(la.VecDot(u, av)-la.VecDot(v, au))/scale)
+	}
+	return violation <= tol, violation
+}
+
+// BilinearForm computes the bilinear form a(u,v) = {u}ᵀ⋅[Auu]⋅{v} with the assembled (sparse)
+// matrix; e.g. the energy inner product of the (negative definite) Laplacian for orthogonality
+// checks and error analyses of Galerkin-style methods
+//
+//   Input:
+//     e -- equations with assembled [Auu]; e.g. FdmLaplacian.Eqs after Assemble
+//     u -- [Nu] values of the first field at the nodes without prescribed values; or
+//          [nnodes] values at all nodes (the prescribed values are then skipped)
+//     v -- [Nu] or [nnodes] values of the second field (see u)
+//   Output:
+//     res -- {u}ᵀ⋅[Auu]⋅{v}
+//
+//   NOTE: the matrix is not converted to a dense one; [Auu] does not need to be symmetric, thus
+//         a(u,v) ≠ a(v,u) in general (see CheckSelfAdjoint)
+func BilinearForm(e *la.Equations, u, v []float64) (res float64) {
+	if e.Auu == nil {
+		chk.Panic("the matrix Auu of the equations must be assembled\n")
+	}
+	uu, vu := reducedField(e, u, "u"), reducedField(e, v, "v")
+	av := la.NewVector(e.Nu)
+	la.SpMatVecMul(av, 1, e.Auu.ToMatrix(nil), vu)
+	return la.VecDot(uu, av)
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// reducedField returns the values of a field at the nodes without prescribed values
+//   u -- [Nu] reduced values (returned as is) or [nnodes] values at all nodes
+func reducedField(e *la.Equations, u []float64, name string) (uu la.Vector) {
+	switch len(u) {
+	case e.Nu:
+		return u
+	case e.Nu + e.Nk:
+		uu = la.NewVector(e.Nu)
+		for i, I := range e.UtoF {
+			uu[i] = u[I]
+		}
+		return
+	}
+	chk.Panic("length of %s must be equal to Nu = %d or nnodes = %d. %d is invalid\n", name, e.Nu, e.Nu+e.Nk, len(u))
+	return
+}
@@ -0,0 +1,111 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/la"
+)
+
+// PrecondFastPoisson implements a preconditioner (see la.ConjGrad) that solves the Poisson equation
+// with constant coefficients and homogeneous Dirichlet conditions on a uniform 2D grid
+//
+//   M = kx δx² + ky δy²     at the interior nodes
+//
+//   where δx² and δy² are the central second-difference operators. The discrete sine vectors
+//   diagonalise δx² and δy² (see LaplaceEigenmode); thus, with the orthonormal and symmetric
+//   matrices Sx and Sy of sine vectors, z = M⁻¹⋅r is computed directly by
+//
+//    Z = Sy ⋅ [(Sy ⋅ R ⋅ Sx) ⊘ Λ] ⋅ Sx     with     Λ[j][i] = kx λx[i] + ky λy[j]
+//
+//   where R and Z are r and z arranged as [ny-2][nx-2] matrices and ⊘ is the element-wise division.
+//   The cost is O(N⋅(nx+ny)) with N = (nx-2)⋅(ny-2). This is useful for variable-coefficient
+//   problems, where M with mean coefficients is spectrally close to the operator
+//
+//   NOTE: the unknowns must be the interior nodes in lexicographic order; e.g. the u-system of
+//         FdmLaplacian with essential conditions on all edges (default ordering)
+type PrecondFastPoisson struct {
+	nx, ny int        // number of interior nodes along x and y
+	sx, sy *la.Matrix // [nx][nx] and [ny][ny] sine vectors
+	lam    *la.Matrix // [ny][nx] eigenvalues of M
+	tmp    *la.Matrix // [ny][nx] workspace
+	rmat   *la.Matrix // [ny][nx] workspace
+}
+
+// NewPrecondFastPoisson returns a new fast Poisson preconditioner
+//   grid   -- uniform 2D grid with at least 3 points along each direction
+//   kx, ky -- (constant, positive) coefficients of M; e.g. mean values of a coefficient field
+func NewPrecondFastPoisson(grid *gm.Grid, kx, ky float64) (o *PrecondFastPoisson) {
+	if grid.Ndim() != 2 {
+		chk.Panic("PrecondFastPoisson works in 2D only\n")
+	}
+	if grid.Npts(0) < 3 || grid.Npts(1) < 3 {
+		chk.Panic("grid must have at least 3 points along each direction. npts = (%d, %d)\n", grid.Npts(0), grid.Npts(1))
+	}
+	o = new(PrecondFastPoisson)
+	o.nx, o.ny = grid.Npts(0)-2, grid.Npts(1)-2
+	dx := grid.Xlen(0) / float64(o.nx+1)
+	dy := grid.Xlen(1) / float64(o.ny+1)
+	var λx, λy []float64
+	o.sx, λx = sineVectors(o.nx, dx)
+	o.sy, λy = sineVectors(o.ny, dy)
+	o.lam = la.NewMatrix(o.ny, o.nx)
+	for j := 0; j < o.ny; j++ {
+		for i := 0; i < o.nx; i++ {
+			o.lam.Set(j, i, kx*λx[i]+ky*λy[j])
+		}
+	}
+	o.tmp = la.NewMatrix(o.ny, o.nx)
+	o.rmat = la.NewMatrix(o.ny, o.nx)
+	return
+}
+
+// Apply computes z := M⁻¹ ⋅ r
+func (o *PrecondFastPoisson) Apply(z, r la.Vector) {
+	if len(r) != o.nx*o.ny || len(z) != o.nx*o.ny {
+		chk.Panic("sizes of vectors must be equal to %d. len(z)=%d, len(r)=%d\n", o.nx*o.ny, len(z), len(r))
+	}
+	for j := 0; j < o.ny; j++ {
+		for i := 0; i < o.nx; i++ {
+			o.rmat.Set(j, i, r[i+j*o.nx])
+		}
+	}
+	la.MatMatMul(o.tmp, 1, o.sy, o.rmat) // Sy⋅R
+	la.MatMatMul(o.rmat, 1, o.tmp, o.sx) // (Sy⋅R)⋅Sx
+	for k, l := range o.lam.Data {
+		o.rmat.Data[k] /= l
+	}
+	la.MatMatMul(o.tmp, 1, o.sy, o.rmat)
+	la.MatMatMul(o.rmat, 1, o.tmp, o.sx)
+	for j := 0; j < o.ny; j++ {
+		for i := 0; i < o.nx; i++ {
+			z[i+j*o.nx] = o.rmat.Get(j, i)
+		}
+	}
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// sineVectors returns the orthonormal eigenvectors (columns of s) and eigenvalues of δ² with n
+// interior nodes and homogeneous Dirichlet conditions
+//
+//   s[i][k] = √(2/(n+1)) ⋅ sin(π⋅(i+1)⋅(k+1)/(n+1))     λ[k] = -4/h² ⋅ sin²(π⋅(k+1)/(2⋅(n+1)))
+//
+func sineVectors(n int, h float64) (s *la.Matrix, λ []float64) {
+	s = la.NewMatrix(n, n)
+	λ = make([]float64, n)
+	c := math.Sqrt(2.0 / float64(n+1))
+	for k := 0; k < n; k++ {
+		for i := 0; i < n; i++ {
+			s.Set(i, k, c*math.Sin(math.Pi*float64((i+1)*(k+1))/float64(n+1)))
+		}
+		sk := math.Sin(math.Pi * float64(k+1) / (2.0 * float64(n+1)))
+		λ[k] = -4.0 * sk * sk / (h * h)
+	}
+	return
+}
@@ -7,11 +7,18 @@
 package pde
 
 import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"time"
+
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/fun"
 	"github.com/cpmech/gosl/fun/dbf"
 	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
 	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
 )
 
 // FdmLaplacian implements the Finite Difference (FDM) Laplacian operator (2D or 3D)
@@ -20,32 +27,198 @@ import (
 //    L{u} = kx ———  +  ky ———  +  kz ———
 //              ∂x²        ∂y²        ∂z²
 //
+//  In 2D, the conductivity tensor may be a full (symmetric) 2×2 tensor; e.g. for anisotropic media
+//  with principal axes not aligned with the grid. In this case kx ≡ kxx and ky ≡ kyy and
+//
+//              ∂²u         ∂²u          ∂²u
+//    L{u} = kx ———  +  2 kxy ————  +  ky ———
+//              ∂x²         ∂x∂y         ∂y²
+//
+//  where the cross derivative is discretised with the 4 diagonal neighbours
+//
+//  Natural boundary conditions prescribe the normal flux density entering the domain, qn = k ∂u/∂n,
+//  where n is the outward normal. They are imposed with ghost nodes; i.e. the RHS of a boundary
+//  node receives -2⋅qn/h, where h is the spacing normal to the boundary. Boundaries without
+//  essential or natural conditions are impermeable (qn = 0)
+//
+//  Robin conditions a⋅u + b⋅∂u/∂n = c are imposed with ghost nodes as well, with qn = k⋅(c - a⋅u)/b;
+//  i.e. the diagonal receives -2⋅k⋅a/(b⋅h) and the RHS receives -2⋅k⋅c/(b⋅h) (see RobinBcs)
+//
+//  The compact 9-point (Mehrstellen) stencil may be used instead of the 5-point stencil (see
+//  Mehrstellen). It is fourth-order accurate for steady problems if the RHS is also corrected:
+//
+//    [kx δx² + ky δy² + (kx Δy² + ky Δx²)/12 δx² δy²]{u} = [1 + Δx²/12 δx² + Δy²/12 δy²]{s}
+//
+//  where δx² and δy² are the central second-difference operators. The correction of the source
+//  term is computed internally. The reaction term is weighted as the source term
+//
+//  A reaction (absorption) term -kr⋅u may be added to L{u}, where kr is either a constant or a
+//  function of the coordinates kr({x}) (see Reaction). The term is added to the diagonal; thus,
+//  the operator remains symmetric and negative definite if kr ≥ 0
+//
+//  On stretched grids (e.g. RectSet2d with non-uniform coordinates), the 5-point stencil uses the
+//  three-point formula with the backward and forward spacings of each node (see Grid.Spacings);
+//  the matrix is then not symmetric. The Mehrstellen stencil and kxy require uniform grids
+//
 type FdmLaplacian struct {
-	Kx       float64        // isotropic coefficient x
-	Ky       float64        // isotropic coefficient y
-	Kz       float64        // isotropic coefficient z
-	Grid     *gm.Grid       // grid
-	Source   fun.Svs        // source term function s({x},t)
-	EssenBcs *BoundaryConds // essential boundary conditions
-	Eqs      *la.Equations  // equations
-	bcsReady bool           // boundary conditions are set
+	Kx          float64         // isotropic coefficient x
+	Ky          float64         // isotropic coefficient y
+	Kz          float64         // isotropic coefficient z
+	Kxy         float64         // off-diagonal coefficient xy of conductivity tensor (2D only)
+	Kr          float64         // reaction coefficient (constant)
+	Reaction    fun.Svs         // reaction coefficient kr({x}) [may be nil ⇒ Kr is used]
+	Grid        *gm.Grid        // grid
+	Source      fun.Svs         // source term function s({x},t)
+	EssenBcs    *BoundaryConds  // essential boundary conditions
+	NaturBcs    *BoundaryConds  // natural boundary conditions: flux density qn = k ∂u/∂n entering the domain
+	RobinBcs    *RobinBcs       // Robin (mixed) boundary conditions: a⋅u + b⋅∂u/∂n = c
+	Eqs         *la.Equations   // equations
+	Logger      Logger          // logger for messages [may be nil ⇒ LoggerPf]
+	Ordering    string          // numbering of unknowns: "lex" (lexicographic; default) or "redblack"
+	Mehrstellen bool            // use the compact 9-point stencil with corrected RHS (2D only; kxy must be zero)
+	Float32     bool            // assemble and solve in single precision; e.g. for very large grids (2D only)
+	MaxDense    int             // maximum number of unknowns of dense systems (see DenseSystem) [0 ⇒ DefaultMaxDense]
+	bcsReady    bool            // boundary conditions are set
+	nmol        int             // number of entries in molecule used to allocate equations
+	solver      la.SparseSolver // factorised [Auu] for ReapplyBcs [may be nil]
+	srcVals     []float64       // [nnodes] sampled source values (see SetSourceVector) [may be nil]
+	pointSrc    []float64       // [nnodes] distributed point sources (see AddPointSource) [may be nil]
+	kField      []float64       // [nnodes] coefficient field (see AssembleWithCoeffField) [may be nil]
+	kTensor     [][]float64     // [3][nnodes] kxx, kxy and kyy at nodes (see AssembleWithTensorField) [may be nil]
+	jumps       []*fdmJump      // [nnodes] corrections of RHS due to interface conditions [may be nil]
+	auu32       *la.CCMatrix32  // [Nu][Nu] single precision matrix (see Float32) [may be nil]
+	auk32       *la.CCMatrix32  // [Nu][Nk] single precision matrix (see Float32) [may be nil]
+	aukMat      *la.CCMatrix    // [Nu][Nk] cached [Auk] (see ApplyBoundaryCorrection) [may be nil]
+	auuMat      *la.CCMatrix    // [Nu][Nu] cached [Auu] for the residual of SolveSteady [may be nil]
+	decoupled   []int           // nodes whose equations are not coupled to other unknowns (see DecoupledNodes)
+	info        SolveInfo       // information on the latest solution (see SolveInfo)
+	hasIntegral bool            // the integral constraint is set (see SetIntegralConstraint)
+	integral    float64         // prescribed value of ∫u dΩ (see SetIntegralConstraint)
+	multiplier  float64         // Lagrange multiplier of the integral constraint (see SetIntegralConstraint)
+	penalty     float64         // penalty of essential conditions [0 ⇒ elimination] (see UsePenaltyBC)
+	sdf         bool            // the domain is restricted by a signed distance function (see SetDomainSDF)
+
+	// named operators (see AddOperator)
+	operators map[string]*fdmOperator
+}
+
+// DefaultMaxDense is the default maximum number of unknowns of dense systems (≈ 32 MB; see DenseSystem)
+const DefaultMaxDense = 2000
+
+// DenseLimitError indicates that the dense system would be too large (see DenseSystem)
+type DenseLimitError struct {
+	N     int // number of unknowns
+	Limit int // maximum number of unknowns
+}
+
+// Bytes returns the memory required by the dense [N][N] matrix
+func (o *DenseLimitError) Bytes() int {
+	return 8 * o.N * o.N
+}
+
+// Error returns the error message
+func (o *DenseLimitError) Error() string {
+	return io.Sf("dense system with %d unknowns exceeds the limit of %d: the matrix would require %.1f MB (see MaxDense)", o.N, o.Limit, float64(o.Bytes())/(1024*1024))
+}
+
+// NonFiniteError indicates that an entry of the assembled matrix is NaN or ±Inf (see Assemble);
+// e.g. due to a division by zero in a variable coefficient
+type NonFiniteError struct {
+	Node        int       // node (row) of the equation
+	Column      int       // node (column) of the entry
+	Value       float64   // value of the entry
+	Coefficient string    // description of the offending coefficient
+	X           []float64 // coordinates of node
+}
+
+// Error returns the error message
+func (o *NonFiniteError) Error() string {
+	return io.Sf("non-finite value %g in the equation of node %d at x = %v (column %d) due to %s", o.Value, o.Node, o.X, o.Column, o.Coefficient)
+}
+
+// SolveOptions holds the options of iterative solvers (see SolveIterative)
+type SolveOptions struct {
+	Tol     float64 // tolerance on the residual norm relative to the norm of the RHS [0 ⇒ 1e-10; or 1e-6 with Float32]
+	MaxIt   int     // maximum number of iterations [0 ⇒ 10⋅Nu]
+	Method  string  // "gmres", "cg" or "auto" (cg if [Auu] is symmetric; gmres otherwise) ["" ⇒ "gmres"]
+	Precond string  // preconditioner: "none", "jacobi" or "ssor" (symmetric Gauss-Seidel) ["" ⇒ "jacobi"]
+	Restart int     // number of GMRES iterations before restarting [0 ⇒ 30]
+
+	// multi-field problems (see BlockOperator.SolveIterative)
+	FieldTol []float64 // [nfields] tolerances on the residual norms of each field relative to the norm of the RHS of the field [may be nil ⇒ Tol]
+
+	// initial guess (warm start)
+	Guess []float64 // [nnodes] initial values at all nodes; e.g. the previous solution [may be nil ⇒ zero]
+}
+
+// SolveInfo holds information on how the system was solved (see FdmLaplacian.SolveInfo)
+type SolveInfo struct {
+	Method   string        // "umfpack" (direct), "cg", "gmres" or "bicgstab32" (single precision)
+	Precond  string        // preconditioner of iterative methods: "none", "jacobi" or "ssor" ["" ⇒ direct]
+	Nit      int           // number of iterations [0 ⇒ direct]
+	Residual float64       // final relative residual ‖bu - Auu⋅xu‖ / ‖bu‖
+	FactTime time.Duration // time spent factorising [Auu] (direct) or building the preconditioner
+
+	// history of iterative methods
+	History *ConvergenceHistory // residuals at each iteration (cg and gmres) [nil ⇒ direct or bicgstab32]
+}
+
+// ConvergenceHistory holds the residuals of iterative solvers at each iteration; e.g. for logging
+// the behaviour of solvers in experiments (see WriteJSON)
+//
+//   The residuals of all methods are the relative 2-norms ‖{bu} - [Auu]⋅{xu}‖ / ‖{bu}‖ of the
+//   reduced system; thus, the histories of different methods can be compared directly
+type ConvergenceHistory struct {
+	Method     string    `json:"method"`     // method; e.g. "cg", "gmres" or "multigrid"
+	Initial    float64   `json:"initial"`    // relative residual before the first iteration
+	Iterations []int     `json:"iterations"` // [nit] iteration numbers: 1, 2, ..., nit
+	Residuals  []float64 `json:"residuals"`  // [nit] relative residuals after each iteration
+	Times      []float64 `json:"times"`      // [nit] elapsed times (seconds) after each iteration
+	start      time.Time // time when the history was started
+}
+
+// newConvergenceHistory returns a new history starting now
+func newConvergenceHistory(method string, initial float64) (o *ConvergenceHistory) {
+	return &ConvergenceHistory{Method: method, Initial: initial, start: time.Now()}
+}
+
+// Append records the residual after iteration nit
+func (o *ConvergenceHistory) Append(nit int, residual float64) {
+	o.Iterations = append(o.Iterations, nit)
+	o.Residuals = append(o.Residuals, residual)
+	o.Times = append(o.Times, time.Since(o.start).Seconds())
+}
+
+// WriteJSON writes the history to a json file
+func (o *ConvergenceHistory) WriteJSON(dirout, fnkey string) {
+	b, err := json.Marshal(o)
+	if err != nil {
+		chk.Panic("%v\n", err)
+	}
+	io.WriteBytesToFileVD(dirout, fnkey+".json", b)
+}
+
+// fdmJump holds the contributions of interface conditions to the RHS of a node
+type fdmJump struct {
+	u [3]float64 // ±[u]/h² along each direction (multiplied by the coefficient k of that direction)
+	q float64    // [k ∂u/∂n]/(2h)
+}
+
+// fdmOperator holds the coefficients of a named operator
+type fdmOperator struct {
+	kx, ky, kz, kxy, kr float64 // coefficients
+	reaction            fun.Svs // reaction coefficient kr({x}) [may be nil]
 }
 
 // NewFdmLaplacian creates a new FDM Laplacian operator with given parameters
 func NewFdmLaplacian(params dbf.Params, grid *gm.Grid, source fun.Svs) (o *FdmLaplacian) {
 	o = new(FdmLaplacian)
-	err := params.ConnectSetOpt(
-		[]*float64{&o.Kx, &o.Ky, &o.Kz},
-		[]string{"kx", "ky", "kz"},
-		[]bool{false, false, true},
-		"FdmLaplacian",
-	)
-	if err != "" {
-		chk.Panic(err)
-	}
+	connectFdmParams(params, &o.Kx, &o.Ky, &o.Kz, &o.Kxy, &o.Kr)
 	o.Grid = grid
 	o.Source = source
 	o.EssenBcs = NewBoundaryCondsGrid(grid, 1) // 1:maxNdof
+	o.NaturBcs = NewBoundaryCondsGrid(grid, 1) // 1:maxNdof
+	o.RobinBcs = NewRobinBcsGrid(grid)
 	o.bcsReady = false
 	return
 }
@@ -59,6 +232,385 @@ func (o *FdmLaplacian) AddEbc(tag int, cvalue float64, fvalue fun.Svs) {
 	o.EssenBcs.AddUsingTag(tag, 0, cvalue, fvalue)
 }
 
+// AddNbc adds natural boundary condition (flux density entering the domain) given tag of edge or face
+//   tag    -- edge or face tag in grid
+//   cvalue -- constant value [optional]; or
+//   fvalue -- function value [optional]
+//   NOTE: (1) use NaturBcs.SetTotalFlux to prescribe the total flux over an edge instead and
+//             NaturBcs.SetSymmetryPlane to mark symmetry planes
+//         (2) at corners shared by two edges with natural conditions, the value set last is
+//             used for both edges
+func (o *FdmLaplacian) AddNbc(tag int, cvalue float64, fvalue fun.Svs) {
+	o.NaturBcs.AddUsingTag(tag, 0, cvalue, fvalue)
+}
+
+// SetBcs replaces the boundary conditions of the operator by conditions created elsewhere; e.g.
+// shared by operators on the same grid. The conditions must have been created for the grid of the
+// operator (or an equivalent grid; see BoundaryConds.CheckGrid)
+//   essen -- essential boundary conditions [may be nil ⇒ none]
+//   natur -- natural boundary conditions [may be nil ⇒ none]
+//   robin -- Robin boundary conditions [may be nil ⇒ none]
+//   err   -- *GridMismatchError if any set was created for another grid; the conditions of the
+//            operator are not modified in this case
+//   NOTE: the equations are partitioned again by the next Assemble; the grids of the conditions
+//         are also checked then, in case the fields EssenBcs, NaturBcs or RobinBcs are replaced
+//         directly
+func (o *FdmLaplacian) SetBcs(essen, natur *BoundaryConds, robin *RobinBcs) (err error) {
+	if essen == nil {
+		essen = NewBoundaryCondsGrid(o.Grid, 1)
+	}
+	if natur == nil {
+		natur = NewBoundaryCondsGrid(o.Grid, 1)
+	}
+	if robin == nil {
+		robin = NewRobinBcsGrid(o.Grid)
+	}
+	if err = checkBcsGrid(o.Grid, essen, natur, robin); err != nil {
+		return
+	}
+	o.EssenBcs, o.NaturBcs, o.RobinBcs = essen, natur, robin
+	o.bcsReady = false
+	return
+}
+
+// AddEbcWhere adds essential boundary condition to the part of an edge or face where a predicate
+// is true; e.g. a clamped portion of an edge (see AddNbcWhere)
+//   tag    -- edge or face tag in grid
+//   cvalue -- constant value [optional]; or
+//   fvalue -- function value [optional]
+//   where  -- predicate selecting nodes by their coordinates; e.g. x[0] ≤ 1
+func (o *FdmLaplacian) AddEbcWhere(tag int, cvalue float64, fvalue fun.Svs, where func(x la.Vector) bool) {
+	o.bcsReady = false
+	o.EssenBcs.AddUsingTagWhere(tag, 0, cvalue, fvalue, where)
+}
+
+// AddNbcWhere adds natural boundary condition to the part of an edge or face where a predicate is true
+//   tag    -- edge or face tag in grid
+//   cvalue -- constant value [optional]; or
+//   fvalue -- function value [optional]
+//   where  -- predicate selecting nodes by their coordinates; e.g. x[0] > 1
+//   NOTE: essential conditions take precedence; i.e. a transition node selected by both AddEbcWhere
+//         and AddNbcWhere has a prescribed value. Nodes of the edge that are not selected by any
+//         predicate are impermeable (qn = 0)
+func (o *FdmLaplacian) AddNbcWhere(tag int, cvalue float64, fvalue fun.Svs, where func(x la.Vector) bool) {
+	o.NaturBcs.AddUsingTagWhere(tag, 0, cvalue, fvalue, where)
+}
+
+// AddEbcNodes adds essential boundary condition to a list of nodes; e.g. interior nodes (internal boundaries)
+//   nodes  -- indices of nodes in grid
+//   cvalue -- constant value [optional]; or
+//   fvalue -- function value [optional]
+func (o *FdmLaplacian) AddEbcNodes(nodes []int, cvalue float64, fvalue fun.Svs) {
+	o.bcsReady = false
+	o.EssenBcs.AddUsingNodes(nodes, 0, cvalue, fvalue)
+}
+
+// SetDomainSDF restricts the domain to the region where a signed-distance function is negative;
+// e.g. to approximate curved boundaries on the Cartesian grid
+//
+//   Nodes with sdf({x}) ≥ 0 are outside the domain and become inactive: they are treated as nodes
+//   with prescribed values (shifted Dirichlet condition). The value at each of these nodes is the
+//   boundary value at the closest point on the boundary {xb} = {x} - sdf({x})⋅∇sdf; thus, the
+//   outside neighbours of active nodes act as (first-order) ghost nodes
+//
+//   sdf    -- signed distance function: negative inside, positive outside
+//   cvalue -- constant value on the boundary [optional]; or
+//   fvalue -- function value on the boundary [optional]
+//   NOTE: the gradient of sdf is computed numerically
+func (o *FdmLaplacian) SetDomainSDF(sdf func(x []float64) float64, cvalue float64, fvalue fun.Svs) {
+	var nodes []int
+	for I := 0; I < o.Grid.Size(); I++ {
+		if sdf(o.Grid.Node(I)) >= 0 {
+			nodes = append(nodes, I)
+		}
+	}
+	if len(nodes) == o.Grid.Size() {
+		chk.Panic("all nodes are outside the domain defined by the signed distance function\n")
+	}
+	ndim := o.Grid.Ndim()
+	δ := 1e-7 * o.Grid.Xlen(0)
+	closest := func(x la.Vector) (xb la.Vector) {
+		xb = la.NewVector(ndim)
+		copy(xb, x)
+		d := sdf(x)
+		g := la.NewVector(ndim)
+		for i := 0; i < ndim; i++ {
+			xb[i] = x[i] + δ
+			g[i] = sdf(xb)
+			xb[i] = x[i] - δ
+			g[i] = (g[i] - sdf(xb)) / (2.0 * δ)
+			xb[i] = x[i]
+		}
+		gg := la.VecDot(g, g)
+		if gg > 0 {
+			la.VecAdd(xb, 1, x, -d/gg, g)
+		}
+		return
+	}
+	f := func(x la.Vector, t float64) float64 {
+		if fvalue == nil {
+			return cvalue
+		}
+		return fvalue(closest(x), t)
+	}
+	o.AddEbcNodes(nodes, 0, f)
+	o.sdf = true
+}
+
+// SetInterfaceCondition sets jump conditions at an interior interface between subdomains; e.g.
+// bimaterial problems with a prescribed contact resistance or a line source
+//
+//   [u] = u⁺ - u⁻ = uJump      and      [k ∂u/∂n] = k ∂u⁺/∂n - k ∂u⁻/∂n = qJump
+//
+//   The interface crosses the faces between each node I in nodes (side "-") and its neighbour
+//   J = I + e_dim (side "+") at the mid-points. The stencils are not changed; instead, the
+//   RHS of I and J are corrected as in the ghost fluid method (Liu, Fedkiw and Kang, 2000):
+//
+//          k⋅uJump   qJump                     k⋅uJump   qJump
+//    bI += ——————— + —————     and     bJ += - ——————— + —————
+//             h²      2⋅h                         h²      2⋅h
+//
+//   Thus, piecewise linear solutions (in the direction normal to the interface) are exact
+//
+//   Input:
+//     nodes -- nodes on the "-" side; i.e. adjacent to the interface with smaller coordinates
+//     dim   -- normal direction of the interface: 0 (x) or 1 (y)
+//     uJump -- jump of solution [u]
+//     qJump -- jump of flux [k ∂u/∂n] with n = e_dim; e.g. a line source of intensity -qJump
+//   NOTE: (1) conditions along the same faces are summed up
+//         (2) the Mehrstellen stencil and the coefficient field are not supported
+func (o *FdmLaplacian) SetInterfaceCondition(nodes []int, dim int, uJump, qJump float64) {
+	if dim < 0 || dim >= o.Grid.Ndim() {
+		chk.Panic("direction must be in [0, %d). dim=%d is invalid\n", o.Grid.Ndim(), dim)
+	}
+	if o.jumps == nil {
+		o.jumps = make([]*fdmJump, o.Grid.Size())
+	}
+	get := func(I int) *fdmJump {
+		if o.jumps[I] == nil {
+			o.jumps[I] = new(fdmJump)
+		}
+		return o.jumps[I]
+	}
+	h := o.Grid.Xlen(dim) / float64(o.Grid.Npts(dim)-1)
+	for _, I := range nodes {
+		idx := make([]int, 3)
+		idx[0], idx[1], idx[2] = o.Grid.IndexItoMNP(I)
+		if idx[dim] >= o.Grid.Npts(dim)-1 {
+			chk.Panic("node %d does not have a neighbour along direction %d\n", I, dim)
+		}
+		idx[dim]++
+		J := o.Grid.IndexMNPtoI(idx[0], idx[1], idx[2])
+		get(I).u[dim] += uJump / (h * h)
+		get(J).u[dim] -= uJump / (h * h)
+		get(I).q += qJump / (2 * h)
+		get(J).q += qJump / (2 * h)
+	}
+}
+
+// UpdateEbc updates the values of essential boundary conditions previously added with AddEbc
+//   tag    -- edge or face tag in grid
+//   cvalue -- constant value [optional]; or
+//   fvalue -- function value [optional]
+//   NOTE: the operator does not need to be assembled again; see ReapplyBcs
+func (o *FdmLaplacian) UpdateEbc(tag int, cvalue float64, fvalue fun.Svs) {
+	o.EssenBcs.Update(tag, 0, cvalue, fvalue)
+}
+
+// Free releases the linear solver allocated by ReapplyBcs or GreensFunctionColumn
+func (o *FdmLaplacian) Free() {
+	if o.solver != nil {
+		o.solver.Free()
+		o.solver = nil
+	}
+}
+
+// SetSourceVector sets source values sampled at the nodes; e.g. from measured data
+//   f -- [nnodes] source values in grid ordering [may be nil ⇒ remove sampled values]
+//   NOTE: (1) the values are added to the results of the Source function, if any
+//         (2) the FDM equations are written point-wise (per unit volume); thus, the values are
+//             used as they are, consistently with the Source function
+func (o *FdmLaplacian) SetSourceVector(f []float64) {
+	if f == nil {
+		o.srcVals = nil
+		return
+	}
+	if len(f) != o.Grid.Size() {
+		chk.Panic("size of source vector must be equal to the number of nodes. %d != %d\n", len(f), o.Grid.Size())
+	}
+	o.srcVals = make([]float64, len(f))
+	copy(o.srcVals, f)
+}
+
+// AddPointSource adds a concentrated source distributed to the corners of the enclosing cell
+// using bilinear (trilinear in 3D) weights; i.e. a discrete delta function
+//
+//   s_I = w_I ⋅ strength / V_I     with   Σ w_I = 1
+//
+//   where V_I is the area (volume) of the control volume around node I (see gm.Grid.ControlVolume);
+//   e.g. Δx ⋅ Δy at interior nodes of uniform grids
+//
+//   x        -- physical coordinates of the point; must be inside the grid (see gm.Grid.ToLocal)
+//   strength -- total strength; e.g. the injection rate of a well
+//   Output:
+//     nodes   -- the (up to 4 or 8) nodes receiving the source
+//     weights -- the corresponding weights w_I
+//   NOTE: (1) the source values are point-wise (per unit volume); thus, the total strength is
+//             recovered by integrating the source with the weights of GridQuadrature; also on
+//             stretched grids and at the boundaries
+//         (2) the sources accumulate; see ClearPointSources
+func (o *FdmLaplacian) AddPointSource(x []float64, strength float64) (nodes []int, weights []float64) {
+	ndim := o.Grid.Ndim()
+	if len(x) != ndim {
+		chk.Panic("size of x must be equal to the space dimension. %d != %d\n", len(x), ndim)
+	}
+	x = o.Grid.ToLocal(x)
+	idx := []int{0, 0, 0}    // index of the cell's bottom-left corner
+	xi := []float64{0, 0, 0} // local coordinates in [0,1]
+	for dim := 0; dim < ndim; dim++ {
+		if x[dim] < o.Grid.Xmin(dim) || x[dim] > o.Grid.Xmax(dim) {
+			chk.Panic("point %v is outside the grid\n", x)
+		}
+		X := o.Grid.Coords(dim)
+		k := 0
+		for k < len(X)-2 && x[dim] >= X[k+1] {
+			k++
+		}
+		idx[dim] = k
+		xi[dim] = (x[dim] - X[k]) / (X[k+1] - X[k])
+		if xi[dim] < 1e-12 || xi[dim] > 1-1e-12 { // on node, up to round-off (e.g. rotated grids)
+			xi[dim] = math.Floor(xi[dim] + 0.5)
+		}
+	}
+	if o.pointSrc == nil {
+		o.pointSrc = make([]float64, o.Grid.Size())
+	}
+	ncorners := 1 << uint(ndim)
+	for c := 0; c < ncorners; c++ {
+		w := 1.0
+		corner := []int{0, 0, 0}
+		for dim := 0; dim < ndim; dim++ {
+			if c&(1<<uint(dim)) != 0 {
+				corner[dim] = idx[dim] + 1
+				w *= xi[dim]
+			} else {
+				corner[dim] = idx[dim]
+				w *= 1 - xi[dim]
+			}
+		}
+		if w == 0 {
+			continue
+		}
+		I := o.Grid.IndexMNPtoI(corner[0], corner[1], corner[2])
+		o.pointSrc[I] += w * strength / o.Grid.ControlVolume(I)
+		nodes = append(nodes, I)
+		weights = append(weights, w)
+	}
+	return
+}
+
+// ClearPointSources removes all point sources added by AddPointSource
+func (o *FdmLaplacian) ClearPointSources() {
+	o.pointSrc = nil
+}
+
+// SetIntegralConstraint imposes the global constraint ∫u dΩ = value; e.g. to fix the additive
+// constant of the solution of problems with natural (Neumann) conditions on all boundaries
+//
+//   The constraint couples all unknowns and is imposed by SolveSteady via the bordered system
+//
+//     [ Auu  {w} ] { xu }   {       bu        }
+//     [ {w}ᵀ  0  ] { λ  } = { value - {w}ᵀ⋅xk }
+//
+//   where {w} are the weights of the trapezoidal rule (areas or volumes of the control volumes
+//   around nodes; see GridQuadrature) and λ is the Lagrange multiplier (see IntegralMultiplier)
+//
+//   NOTE: (1) the bordered matrix is non-singular if [Auu] is singular with the constant vector
+//             in its null space (pure Neumann problems), as well as in the regular case
+//         (2) the source (and fluxes) of pure Neumann problems do not need to be compatible; the
+//             multiplier absorbs the incompatibility; i.e. [Auu]⋅{xu} = {bu} - λ⋅{w}
+//         (3) only SolveSteady (in double precision) considers the constraint
+func (o *FdmLaplacian) SetIntegralConstraint(value float64) {
+	o.hasIntegral = true
+	o.integral = value
+}
+
+// ClearIntegralConstraint removes the constraint set by SetIntegralConstraint
+func (o *FdmLaplacian) ClearIntegralConstraint() {
+	o.hasIntegral = false
+	o.multiplier = 0
+}
+
+// IntegralMultiplier returns the Lagrange multiplier λ of the integral constraint computed by the
+// latest call to SolveSteady (see SetIntegralConstraint); e.g. λ ≈ 0 if the data are compatible
+func (o *FdmLaplacian) IntegralMultiplier() float64 {
+	return o.multiplier
+}
+
+// UsePenaltyBC sets SolveSteady to impose the essential conditions by the penalty method instead
+// of the elimination of the prescribed values; i.e. the full (square) system with all nodes is
+// solved without partitioning the unknowns
+//
+//   A[I][I] -= P     and     b[I] -= P ⋅ ū[I]      for all nodes I with prescribed values ū
+//
+//   The penalty is subtracted because the diagonal of the operator is negative; thus, the matrix
+//   remains (negative) definite. The values at the nodes with essential conditions are not exact
+//
+//   u[I] - ū[I] = O(|A[I][J]|/P)
+//
+//   penalty -- penalty P; e.g. 10⁸ times the largest coefficient of the stencil (≈ k/h²)
+//              [≤ 0 ⇒ use the elimination of prescribed values (default)]
+//
+//   NOTE: (1) 2D only; the equations in Eqs are numbered and assembled as usual (see Assemble),
+//             but not used by SolveSteady; the reactions are computed with the penalised values
+//         (2) the integral constraint and Float32 are not supported
+//         (3) large penalties deteriorate the conditioning of the matrix
+func (o *FdmLaplacian) UsePenaltyBC(penalty float64) {
+	o.penalty = math.Max(penalty, 0)
+}
+
+// AddOperator registers a named operator (set of coefficients) to be used with the same grid,
+// boundary conditions and source; see SwitchOperator
+//   name     -- name of operator; e.g. "laplacian" or "screened-poisson"
+//   params   -- coefficients: same as in NewFdmLaplacian
+//   reaction -- reaction coefficient kr({x}) [may be nil ⇒ "kr" (or zero) is used]
+func (o *FdmLaplacian) AddOperator(name string, params dbf.Params, reaction fun.Svs) {
+	op := &fdmOperator{reaction: reaction}
+	connectFdmParams(params, &op.kx, &op.ky, &op.kz, &op.kxy, &op.kr)
+	if o.operators == nil {
+		o.operators = make(map[string]*fdmOperator)
+	}
+	o.operators[name] = op
+}
+
+// SwitchOperator sets the coefficients of a named operator (see AddOperator) and assembles it
+//   name      -- name of operator. The directional operators "d2dx2" and "d2dy2" are also
+//                available (unless registered with AddOperator); e.g. for ADI or other
+//                operator-splitting schemes:
+//
+//                          ∂²u                    ∂²u
+//                 kx ⋅ ———        and        ky ⋅ ———
+//                          ∂x²                    ∂y²
+//
+//                their sum is the operator without kxy and reaction terms. The coefficients
+//                (e.g. Kx and Ky) are not modified by the directional operators
+//   reactions -- prepare for computation of RHS (see Assemble)
+//   err       -- *NonFiniteError if the matrix has non-finite entries (see Assemble)
+//   NOTE: the structure of equations is re-used unless the new operator requires a larger
+//         stencil (e.g. kxy ≠ 0 with a previous kxy = 0)
+func (o *FdmLaplacian) SwitchOperator(name string, reactions bool) (err error) {
+	op, ok := o.operators[name]
+	if !ok {
+		if dim, found := map[string]int{"d2dx2": 0, "d2dy2": 1}[name]; found {
+			return o.assembleDirectional(dim, reactions)
+		}
+		chk.Panic("cannot find operator named %q\n", name)
+	}
+	o.Kx, o.Ky, o.Kz, o.Kxy, o.Kr = op.kx, op.ky, op.kz, op.kxy, op.kr
+	o.Reaction = op.reaction
+	return o.Assemble(reactions)
+}
+
 // SetHbc sets homogeneous boundary conditions; i.e. all boundaries with zero EBC
 func (o *FdmLaplacian) SetHbc() {
 	if o.Grid.Ndim() == 2 {
@@ -78,58 +630,295 @@ func (o *FdmLaplacian) SetHbc() {
 
 // Assemble assembles operator into A matrix from [A] ⋅ {u} = {b}
 //  reactions -- prepare for computation of RHS
-func (o *FdmLaplacian) Assemble(reactions bool) {
+//  err       -- *NonFiniteError if an entry of the matrix is NaN or ±Inf; e.g. due to the reaction
+//               function or the coefficient field. The matrix must not be used then
+//  NOTE: if Float32 is set, only the single precision [Auu] and [Auk] are assembled (the matrices
+//        in Eqs are not allocated) and reactions cannot be computed; the functions requiring the
+//        double precision matrices (e.g. ReapplyBcs, DenseSystem or FdmTransientSolver) panic
+func (o *FdmLaplacian) Assemble(reactions bool) (err error) {
+	o.Free() // the factorisation becomes invalid
+	nmol := o.molSize()
 	if !o.bcsReady {
-		o.Eqs = la.NewEquations(o.Grid.Size(), o.EssenBcs.Nodes())
-		o.Eqs.Alloc([]int{5 * o.Eqs.Nu, 5 * o.Eqs.Nu, 5 * o.Eqs.Nk, 5 * o.Eqs.Nk}, reactions, true)
-		o.bcsReady = true
+		o.initEqs()
+	}
+	o.auu32, o.auk32, o.aukMat, o.auuMat = nil, nil, nil, nil
+	if o.Float32 {
+		return o.assemble32(nmol, reactions)
+	}
+	if nmol > o.nmol {
+		o.Eqs.Alloc([]int{nmol * o.Eqs.Nu, nmol * o.Eqs.Nu, nmol * o.Eqs.Nk, nmol * o.Eqs.Nk}, reactions, true)
+		o.nmol = nmol
 	}
 	o.Eqs.Start()
 	if o.Grid.Ndim() == 2 {
-		nx := o.Grid.Npts(0)
-		ny := o.Grid.Npts(1)
-		dx := o.Grid.Xlen(0) / float64(nx-1)
-		dy := o.Grid.Xlen(1) / float64(ny-1)
-		dx2 := dx * dx
-		dy2 := dy * dy
-		α := -2.0 * (o.Kx/dx2 + o.Ky/dy2)
-		β := o.Kx / dx2
-		γ := o.Ky / dy2
-		mol := []float64{α, β, β, γ, γ}
-		jays := make([]int, 5)
-		for I := 0; I < o.Eqs.N; I++ { // loop over all Nx*Ny equations
-			col := I % nx    // grid column number
-			row := I / nx    // grid row number
-			jays[0] = I      // current node
-			jays[1] = I - 1  // left node
-			jays[2] = I + 1  // right node
-			jays[3] = I - nx // bottom node
-			jays[4] = I + nx // top node
-			if col == 0 {
-				jays[1] = jays[2]
-			}
-			if col == nx-1 {
-				jays[2] = jays[1]
-			}
-			if row == 0 {
-				jays[3] = jays[4]
-			}
-			if row == ny-1 {
-				jays[4] = jays[3]
-			}
-			for k, J := range jays { // loop over non-zero columns
-				o.Eqs.Put(I, J, mol[k])
+		diag := make([]float64, o.Eqs.Nu) // diagonal of Auu
+		ncoup := make([]int, o.Eqs.Nu)    // number of non-zero off-diagonal entries of Auu
+		put := o.checkedPut(&err, func(I, J int, value float64) {
+			o.Eqs.Put(I, J, value)
+			if i := o.Eqs.FtoU[I]; i >= 0 {
+				if I == J {
+					diag[i] += value
+				} else if o.Eqs.FtoU[J] >= 0 && value != 0 {
+					ncoup[i]++
+				}
 			}
+		})
+		for I := 0; I < o.Eqs.N; I++ { // loop over all Nx*Ny equations
+			o.stencil2d(I, put)
+		}
+		if err != nil {
+			return
 		}
+		o.checkRows(diag, ncoup)
 		return
 	}
+	if o.Kxy != 0 {
+		chk.Panic("off-diagonal coefficient kxy is available in 2D only\n")
+	}
 	chk.Panic("TODO: Implement Assemble() in 3D\n")
+	return
+}
+
+// AssembleWithCoeffField assembles the operator with a variable coefficient given at the nodes;
+// e.g. a diffusion coefficient depending on another solved field (Picard iterations)
+//
+//             ∂  ⎛         ∂u ⎞     ∂  ⎛         ∂u ⎞
+//    L{u} =  —— ⎜ kx k({x}) —— ⎟ + —— ⎜ ky k({x}) —— ⎟
+//             ∂x ⎝         ∂x ⎠     ∂y ⎝         ∂y ⎠
+//
+//   The coefficient at the face between nodes I and J is the harmonic mean of the nodal values
+//
+//              2 k[I] k[J]
+//    k(I,J) = —————————————
+//              k[I] + k[J]
+//
+//   Input:
+//     kField    -- [nnodes] positive coefficient at nodes [may be nil ⇒ remove field; i.e. k = 1]
+//     reactions -- prepare for computation of RHS (see Assemble)
+//   Output:
+//     err -- *NonFiniteError if the matrix has non-finite entries; e.g. k[I] = ±Inf (see Assemble)
+//   NOTE: (1) 2D only; the Mehrstellen stencil and kxy are not supported
+//         (2) the field is used by subsequent calls to Assemble and Apply as well
+func (o *FdmLaplacian) AssembleWithCoeffField(kField []float64, reactions bool) (err error) {
+	if kField == nil {
+		o.kField = nil
+		return o.Assemble(reactions)
+	}
+	if o.Grid.Ndim() != 2 {
+		chk.Panic("AssembleWithCoeffField works in 2D only\n")
+	}
+	if len(kField) != o.Grid.Size() {
+		chk.Panic("size of coefficient field must be equal to the number of nodes. %d != %d\n", len(kField), o.Grid.Size())
+	}
+	o.kField = make([]float64, len(kField))
+	copy(o.kField, kField)
+	o.kTensor = nil
+	return o.Assemble(reactions)
+}
+
+// AssembleWithTensorField assembles the operator in divergence form with a full (symmetric)
+// conductivity tensor given at the nodes; e.g. for heterogeneous anisotropic media
+//
+//    L{u} = ∇ ⋅ (K({x}) ⋅ ∇u)     with     K = ┌ kxx  kxy ┐
+//                                               └ kxy  kyy ┘
+//
+//   The coefficients kxx and kyy at faces are the harmonic means of the nodal values (see
+//   AssembleWithCoeffField). The cross terms ∂(kxy ∂u/∂y)/∂x + ∂(kxy ∂u/∂x)/∂y are discretised by
+//   central differences with the nodal kxy; thus, the coefficient of the diagonal neighbour
+//   (m+a,n+b) with a,b = ±1 is
+//
+//        a⋅b
+//    ——————————— (kxy[m+a,n] + kxy[m,n+b])
+//     4 Δx Δy
+//
+//   and [Auu] is symmetric. Constant values are in the kernel of the operator (conservation)
+//
+//   Input:
+//     kxx, kxy, kyy -- [nnodes] components of the tensor at nodes; kxx and kyy must be positive
+//                      [all nil ⇒ remove field; i.e. use kx, ky and kxy]
+//     reactions     -- prepare for computation of RHS (see Assemble)
+//   Output:
+//     err -- *NonFiniteError if the matrix has non-finite entries (see Assemble)
+//   NOTE: (1) 2D only; the Mehrstellen stencil is not supported
+//         (2) the constant coefficients kx, ky and kxy are not used by the stencil
+//         (3) the field is used by subsequent calls to Assemble and Apply as well
+func (o *FdmLaplacian) AssembleWithTensorField(kxx, kxy, kyy []float64, reactions bool) (err error) {
+	if kxx == nil && kxy == nil && kyy == nil {
+		o.kTensor = nil
+		return o.Assemble(reactions)
+	}
+	if o.Grid.Ndim() != 2 {
+		chk.Panic("AssembleWithTensorField works in 2D only\n")
+	}
+	o.kTensor = make([][]float64, 3)
+	for i, k := range [][]float64{kxx, kxy, kyy} {
+		if len(k) != o.Grid.Size() {
+			chk.Panic("size of tensor components must be equal to the number of nodes. %d != %d\n", len(k), o.Grid.Size())
+		}
+		o.kTensor[i] = make([]float64, len(k))
+		copy(o.kTensor[i], k)
+	}
+	o.kField = nil
+	return o.Assemble(reactions)
+}
+
+// AssembleWithPrincipalField assembles the operator with a conductivity tensor whose principal
+// directions vary in space; e.g. for fiber-reinforced media where the fibers follow a vector field
+//
+//    K = R(θ) ⋅ ┌ k1  0  ┐ ⋅ Rᵀ(θ)   ⇒   kxx = k1 cos²θ + k2 sin²θ
+//               └ 0   k2 ┘                kyy = k1 sin²θ + k2 cos²θ
+//                                         kxy = (k1 - k2) sinθ cosθ
+//
+//   where θ is the angle between the x-axis and the principal direction of k1 at each node. The
+//   tensor is then assembled with AssembleWithTensorField; thus [Auu] is symmetric
+//
+//   Input:
+//     k1, k2    -- positive principal conductivities along and across the principal direction
+//     theta     -- [nnodes] angles (radians) of the principal direction at nodes; e.g. θ = atan2(dy, dx)
+//                  for a direction field {dx, dy}
+//     reactions -- prepare for computation of RHS (see Assemble)
+//   Output:
+//     err -- *NonFiniteError if the matrix has non-finite entries (see Assemble)
+//   NOTE: 2D only; the field is used by subsequent calls to Assemble and Apply as well
+func (o *FdmLaplacian) AssembleWithPrincipalField(k1, k2 float64, theta []float64, reactions bool) (err error) {
+	if k1 <= 0 || k2 <= 0 {
+		chk.Panic("principal conductivities must be positive. k1 = %g and k2 = %g are invalid\n", k1, k2)
+	}
+	if len(theta) != o.Grid.Size() {
+		chk.Panic("size of angles must be equal to the number of nodes. %d != %d\n", len(theta), o.Grid.Size())
+	}
+	kxx := make([]float64, len(theta))
+	kxy := make([]float64, len(theta))
+	kyy := make([]float64, len(theta))
+	for I, θ := range theta {
+		c, s := math.Cos(θ), math.Sin(θ)
+		kxx[I] = k1*c*c + k2*s*s
+		kyy[I] = k1*s*s + k2*c*c
+		kxy[I] = (k1 - k2) * s * c
+	}
+	return o.AssembleWithTensorField(kxx, kxy, kyy, reactions)
+}
+
+// AssembleStream computes the coefficients of the full system [A] (including the equations of
+// nodes with prescribed values) and calls put for each entry instead of assembling the matrices;
+// e.g. to route the entries to external sparse libraries
+//   put -- callback receiving the row I, column J (node numbers of the full system) and the value;
+//          entries may be repeated and must be summed up
+//   NOTE: (1) 2D only; Eqs is not created or modified
+//         (2) the sum of the entries is equal to the full matrix assembled by Assemble (see
+//             la.Equations.GetAmat); i.e. the essential conditions are handled by the caller
+func (o *FdmLaplacian) AssembleStream(put func(I, J int, value float64)) {
+	if o.Grid.Ndim() != 2 {
+		chk.Panic("AssembleStream works in 2D only\n")
+	}
+	o.molSize() // check options
+	for I := 0; I < o.Grid.Size(); I++ {
+		o.stencil2d(I, put)
+	}
+}
+
+// DecoupledNodes returns the nodes without prescribed values whose equations are not coupled to
+// other unknowns; i.e. the rows of [Auu] have only the diagonal and the values are determined by
+// the prescribed neighbours alone. For example, the interior node of a 3×3 grid with all borders
+// fixed or isolated nodes in heavily masked domains (see SetDomainSDF)
+//   NOTE: (1) computed by Assemble (2D; double precision only)
+//         (2) Assemble panics if any of these equations has a zero diagonal (singular system)
+func (o *FdmLaplacian) DecoupledNodes() (nodes []int) {
+	return o.decoupled
+}
+
+// SolveInfo returns information on the latest solution by SolveSteady or SolveIterative; i.e. the
+// method and preconditioner actually used, the number of iterations, the final residual and the
+// time spent factorising the matrix (or building the preconditioner)
+func (o *FdmLaplacian) SolveInfo() SolveInfo {
+	return o.info
+}
+
+// Apply computes {res} = [Auu]⋅{uu} without assembling [Auu] (matrix-free) (2D only)
+//   Input:
+//     uu -- [Nu] values at nodes without prescribed values (u-system; see Eqs.UtoF)
+//   Output:
+//     res -- [Nu] result; same as la.SpMatVecMul(res, 1, Auu, uu) with the assembled Auu
+//   NOTE: (1) the known values are not considered; i.e. they correspond to [Auk]⋅{uk}
+//         (2) Eqs is created (but not allocated) if the operator has not been assembled yet
+//         (3) this function can be used with matrix-free iterative solvers
+func (o *FdmLaplacian) Apply(res, uu la.Vector) {
+	o.initApply("Apply")
+	if len(uu) != o.Eqs.Nu || len(res) != o.Eqs.Nu {
+		chk.Panic("sizes of vectors must be equal to Nu = %d. len(res)=%d, len(uu)=%d\n", o.Eqs.Nu, len(res), len(uu))
+	}
+	for i := 0; i < o.Eqs.Nu; i++ {
+		res[i] = o.applyRow(o.Eqs.UtoF[i], uu)
+	}
+}
+
+// ApplyAt computes the entries of {res} = [Auu]⋅{uu} corresponding to a subset of nodes without
+// assembling [Auu] (matrix-free) (2D only); e.g. for localised updates near a moving source
+//   Input:
+//     nodes -- node numbers (full system) without prescribed values
+//     uu    -- [Nu] values at nodes without prescribed values (u-system; see Eqs.UtoF)
+//   Output:
+//     res -- [len(nodes)] result; i.e. res[k] = ([Auu]⋅{uu})[Eqs.FtoU[nodes[k]]]
+//   NOTE: only the values of the neighbours of nodes (in the stencil) are read from uu; thus, the
+//         cost is proportional to len(nodes) instead of Nu (see Apply)
+func (o *FdmLaplacian) ApplyAt(res, uu la.Vector, nodes []int) {
+	o.initApply("ApplyAt")
+	if len(uu) != o.Eqs.Nu || len(res) != len(nodes) {
+		chk.Panic("sizes of vectors must be equal to Nu = %d and len(nodes) = %d. len(uu)=%d, len(res)=%d\n", o.Eqs.Nu, len(nodes), len(uu), len(res))
+	}
+	for k, I := range nodes {
+		if I < 0 || I >= o.Grid.Size() {
+			chk.Panic("node %d is out of range [0, %d)\n", I, o.Grid.Size())
+		}
+		if o.Eqs.FtoU[I] < 0 {
+			chk.Panic("node %d has prescribed value; i.e. it does not correspond to an equation of [Auu]\n", I)
+		}
+		res[k] = o.applyRow(I, uu)
+	}
 }
 
 // SolveSteady solves steady problem
 //   Solves: [K]⋅{u} = {f} represented by [A]⋅{x} = {b}
+//   NOTE: (1) if Float32 is set, the system is solved in single precision by la.SpBiCGStab32
+//         (2) the bordered system is solved if the integral constraint is set; see
+//             SetIntegralConstraint
+//         (3) the full system with penalised essential conditions is solved if the penalty is
+//             set; see UsePenaltyBC
+//         (4) the residual of the direct solution (see SolveInfo) uses [Auu] in column-compressed
+//             form; it is converted in the first call only and discarded when the operator is
+//             assembled again
 func (o *FdmLaplacian) SolveSteady(reactions bool) (u, f []float64) {
-	o.Eqs.SolveOnce(o.calcXk, o.calcBu)
+	if o.penalty > 0 && (o.Float32 || o.hasIntegral) {
+		chk.Panic("the penalty method cannot be used with Float32 or the integral constraint\n")
+	}
+	if o.Float32 {
+		if o.hasIntegral {
+			chk.Panic("the integral constraint cannot be imposed in single precision\n")
+		}
+		if reactions {
+			chk.Panic("reactions cannot be computed in single precision\n")
+		}
+		u, _ = o.solveSteady32(1e-6, 10*o.Eqs.Nu, nil)
+		return
+	}
+	logf(o.Logger, "FdmLaplacian: solving system with Nu = %d unknown and Nk = %d known values\n", o.Eqs.Nu, o.Eqs.Nk)
+	if o.hasIntegral {
+		o.solveBordered()
+	} else if o.penalty > 0 {
+		o.solvePenalty()
+	} else {
+		tIni := time.Now()
+		solver := la.NewSparseSolver("umfpack")
+		defer solver.Free()
+		solver.Init(o.Eqs.Auu, false, false, "", "", nil)
+		solver.Fact()
+		o.info = SolveInfo{Method: "umfpack", FactTime: time.Since(tIni)}
+		o.Eqs.Solve(solver, 0, o.calcXk, o.calcBu)
+		if o.auuMat == nil {
+			o.auuMat = o.Eqs.Auu.ToMatrix(nil)
+		}
+		o.info.Residual = relResidual(o.auuMat, o.Eqs.Xu, o.Eqs.Bu)
+	}
 	u = make([]float64, o.Grid.Size())
 	o.Eqs.JoinVector(u, o.Eqs.Xu, o.Eqs.Xk)
 	if reactions {
@@ -144,25 +933,1631 @@ func (o *FdmLaplacian) SolveSteady(reactions bool) (u, f []float64) {
 	return
 }
 
-// auxiliary //////////////////////////////////////////////////////////////////////////////////////
-
-// calcXk calculates known {u} values (CalcXk in la.Equations)
-//  I -- node number
-//  t -- time
-func (o *FdmLaplacian) calcXk(I int, t float64) float64 {
-	_, val, available := o.EssenBcs.Value(I, 0, t)
-	if available {
-		return val
+// SolveIterative solves the steady problem with the restarted GMRES method (see la.Gmres); e.g. to
+// control the accuracy of the solution in convergence studies
+//   opts -- options [may be nil ⇒ defaults]
+//   u    -- [nnodes] solution at all nodes
+//   nit  -- number of iterations
+//   NOTE: (1) Assemble must be called first; panics if the method does not converge
+//         (2) if Float32 is set, la.SpBiCGStab32 (Jacobi-preconditioned) is used and Precond and
+//             Restart are ignored
+//         (3) with opts.Guess, the iterations start from the given values (e.g. the solution of
+//             the previous time step or nonlinear iteration); the values at nodes with prescribed
+//             values are ignored
+//         (4) the method and preconditioner actually used are given by SolveInfo; e.g. with
+//             opts.Method = "auto"
+//         (5) the relative residuals at each iteration are recorded in SolveInfo().History
+func (o *FdmLaplacian) SolveIterative(opts *SolveOptions) (u []float64, nit int) {
+	if o.Eqs == nil || (o.nmol == 0 && o.auu32 == nil) {
+		chk.Panic("operator must be assembled before calling SolveIterative\n")
 	}
-	return 0
+	var opt SolveOptions
+	if opts != nil {
+		opt = *opts
+	}
+	if opt.Tol <= 0 {
+		opt.Tol = 1e-10
+		if o.Float32 {
+			opt.Tol = 1e-6
+		}
+	}
+	if opt.MaxIt <= 0 {
+		opt.MaxIt = 10 * o.Eqs.Nu
+	}
+	if opt.Restart <= 0 {
+		opt.Restart = 30
+	}
+	if opt.Guess != nil && len(opt.Guess) != o.Grid.Size() {
+		chk.Panic("size of initial guess must be equal to the number of nodes. %d != %d\n", len(opt.Guess), o.Grid.Size())
+	}
+	if o.Float32 {
+		return o.solveSteady32(opt.Tol, opt.MaxIt, opt.Guess)
+	}
+	a := o.Eqs.Auu.ToMatrix(nil)
+	method := opt.Method
+	switch method {
+	case "", "gmres":
+		method = "gmres"
+	case "cg":
+	case "auto":
+		method = "gmres"
+		if spSymmetric(a) {
+			method = "cg"
+		}
+	default:
+		chk.Panic("method %q is invalid. options: \"gmres\", \"cg\" or \"auto\"\n", opt.Method)
+	}
+	tIni := time.Now()
+	var pc la.Preconditioner
+	switch opt.Precond {
+	case "none":
+	case "", "jacobi":
+		opt.Precond = "jacobi"
+		pc = la.NewPrecondJacobi(a)
+	case "ssor":
+		pc = la.NewPrecondSSOR(a, 1)
+	default:
+		chk.Panic("preconditioner %q is invalid. options: \"none\", \"jacobi\" or \"ssor\"\n", opt.Precond)
+	}
+	o.info = SolveInfo{Method: method, Precond: opt.Precond, FactTime: time.Since(tIni)}
+	logf(o.Logger, "FdmLaplacian: solving system iteratively (%s) with Nu = %d unknown and Nk = %d known values\n", method, o.Eqs.Nu, o.Eqs.Nk)
+	bu, xk := o.reducedRhs()
+	xu := la.NewVector(o.Eqs.Nu)
+	if opt.Guess != nil {
+		for i, I := range o.Eqs.UtoF {
+			xu[i] = opt.Guess[I]
+		}
+	}
+	hist := newConvergenceHistory(method, relResidual(a, xu, bu))
+	if method == "cg" {
+		nit = la.ConjGradMonitor(xu, a, bu, pc, opt.Tol, opt.MaxIt, hist.Append)
+	} else {
+		nit = la.GmresMonitor(xu, a, bu, pc, opt.Tol, opt.MaxIt, opt.Restart, hist.Append)
+	}
+	o.info.History = hist
+	o.info.Nit = nit
+	o.info.Residual = relResidual(a, xu, bu)
+	u = make([]float64, o.Grid.Size())
+	o.Eqs.JoinVector(u, xu, xk)
+	return
 }
 
-// calcBu calculates RHS vector (e.g. source) corresponding to known values of {u} (CalcBu in la.Equations)
-//  I -- node number
-//  t -- time
-func (o *FdmLaplacian) calcBu(I int, t float64) float64 {
-	if o.Source != nil {
-		return o.Source(o.Grid.Node(I), t)
+// SolveLeastSquares solves the steady problem in the least-squares sense; e.g. if both essential
+// and natural conditions are prescribed at the same boundary (over-determined problem) and the data
+// are inconsistent
+//
+//   min ‖A⋅u - b‖²   ⇒   Aᵀ⋅A⋅u = Aᵀ⋅b   (normal equations)
+//
+//   where the rows of the rectangular system A⋅u = b are: (1) the equations of the operator at
+//   all nodes, except at nodes with prescribed values through tags not shared by natural or Robin
+//   conditions; and (2) wEssen⋅u[I] = wEssen⋅ū[I] at all nodes with prescribed values ū
+//
+//   Input:
+//     wEssen -- weight of the equations of essential conditions [0 ⇒ 1]; a large weight enforces
+//               the prescribed values more strongly
+//   Output:
+//     u   -- [nnodes] solution at all nodes
+//     res -- norm of the residual ‖A⋅u - b‖
+//
+//   NOTE: (1) 2D only; the normal equations are solved by the sparse Cholesky factorisation
+//             with nested dissection ordering (see la.NestedDissection); thus, A must have full
+//             column rank; e.g. pure Neumann problems are not supported
+//         (2) the condition number is squared by the normal equations
+func (o *FdmLaplacian) SolveLeastSquares(wEssen float64) (u []float64, res float64) {
+	if o.Grid.Ndim() != 2 {
+		chk.Panic("SolveLeastSquares works in 2D only\n")
+	}
+	rows := o.lsqRows(wEssen)
+	n := o.Grid.Size()
+	nnz := 0
+	for _, r := range rows {
+		nnz += len(r.cols) * len(r.cols)
+	}
+	ata := la.NewTriplet(n, n, nnz)
+	atb := la.NewVector(n)
+	for _, r := range rows {
+		for a, ca := range r.cols {
+			atb[ca] += r.vals[a] * r.rhs
+			for b, cb := range r.cols {
+				ata.Put(ca, cb, r.vals[a]*r.vals[b])
+			}
+		}
+	}
+	m := ata.ToMatrix(nil)
+	perm := la.NestedDissection(m, nil) // graph-based: AᵀA couples nodes two points apart
+	bp, up := la.NewVector(n), la.NewVector(n)
+	la.VecPermute(bp, atb, perm)
+	la.CholeskyFactor(la.SpPermute(m, perm).ToMatrix(nil)).Solve(up, bp)
+	u = make([]float64, n)
+	la.VecPermuteInv(u, up, perm)
+	for _, r := range rows {
+		δ := -r.rhs
+		for a, c := range r.cols {
+			δ += r.vals[a] * u[c]
+		}
+		res += δ * δ
+	}
+	return u, math.Sqrt(res)
+}
+
+// ReapplyBcs solves the steady problem again after the values of essential boundary conditions
+// have been changed by UpdateEbc. The operator is not assembled again and the factorisation of
+// [Auu] is computed in the first call only; thus, subsequent calls only compute the RHS
+//
+//   {bu} = {s} - [Auk]⋅{xk}
+//
+//   NOTE: (1) the operator must be assembled first and the set of nodes with essential boundary
+//             conditions must not be changed (i.e. AddEbc must not be called after Assemble)
+//         (2) call Free() to release the linear solver
+func (o *FdmLaplacian) ReapplyBcs() (u []float64) {
+	o.checkDouble("ReapplyBcs")
+	if o.Eqs == nil || !o.bcsReady || o.nmol == 0 {
+		chk.Panic("operator must be assembled (again) before calling ReapplyBcs\n")
+	}
+	o.factorAuu()
+	o.Eqs.Solve(o.solver, 0, o.calcXk, o.calcBu)
+	u = make([]float64, o.Grid.Size())
+	o.Eqs.JoinVector(u, o.Eqs.Xu, o.Eqs.Xk)
+	return
+}
+
+// GreensFunctionColumn computes the discrete Green's function (impulse response) of node I; i.e.
+// the solution to a unit point source at I with homogeneous essential conditions
+//
+//   [Auu]⋅{gu} = {δ}     with     {gk} = {0}
+//
+//   Thus, {gu} is column FtoU[I] of [Auu]⁻¹ and the solution to any source is a combination of
+//   columns; e.g. for reduced models. The values are not positive since the operator is negative
+//   definite. The columns are symmetric, G(I,J) = G(J,I), if [Auu] is symmetric; e.g. with uniform
+//   grids and no natural conditions
+//
+//   Input:
+//     I -- node index
+//   Output:
+//     g -- [nnodes] Green's function; zero if I has a prescribed value
+//
+//   NOTE: (1) the operator must be assembled first; the source functions and the values of the
+//             essential, natural and Robin conditions (RHS) are ignored
+//         (2) the factorisation of [Auu] is computed in the first call only (see ReapplyBcs);
+//             thus, subsequent calls only perform the back-substitution. Call Free() to release
+//             the linear solver
+func (o *FdmLaplacian) GreensFunctionColumn(I int) (g []float64) {
+	if o.Eqs == nil || !o.bcsReady || o.nmol == 0 {
+		chk.Panic("operator must be assembled before calling GreensFunctionColumn\n")
+	}
+	if o.Float32 {
+		chk.Panic("GreensFunctionColumn is not available in single precision\n")
+	}
+	if I < 0 || I >= o.Grid.Size() {
+		chk.Panic("node index %d is out of range [0, %d)\n", I, o.Grid.Size())
+	}
+	g = make([]float64, o.Grid.Size())
+	i := o.Eqs.FtoU[I]
+	if i < 0 {
+		return
+	}
+	o.factorAuu()
+	δ := la.NewVector(o.Eqs.Nu)
+	δ[i] = 1
+	gu := la.NewVector(o.Eqs.Nu)
+	o.solver.Solve(gu, δ, false)
+	for j, J := range o.Eqs.UtoF {
+		g[J] = gu[j]
+	}
+	return
+}
+
+// SolveMultiRHS solves the u-system with multiple right-hand sides; e.g. for sensitivities or
+// Green's functions (see GreensFunctionColumn)
+//
+//   [Auu]⋅[X] = [B]
+//
+//   Input:
+//     B -- [Nu][ncols] right-hand sides (see Eqs.UtoF for the numbering)
+//   Output:
+//     X -- [Nu][ncols] solutions
+//
+//   NOTE: (1) the operator must be assembled first; the source functions and the values of the
+//             boundary conditions are ignored; i.e. the columns of B already contain all terms
+//         (2) [Auu] is factorised once (in the first call only; see ReapplyBcs) and the columns
+//             are back-substituted with the factors. The columns of matrices are contiguous
+//             (col-major); thus, no copies are made. Call Free() to release the linear solver
+func (o *FdmLaplacian) SolveMultiRHS(B *la.Matrix) (X *la.Matrix) {
+	if o.Eqs == nil || !o.bcsReady || o.nmol == 0 {
+		chk.Panic("operator must be assembled before calling SolveMultiRHS\n")
+	}
+	if o.Float32 {
+		chk.Panic("SolveMultiRHS is not available in single precision\n")
+	}
+	if B.M != o.Eqs.Nu {
+		chk.Panic("number of rows of B must be equal to Nu = %d. %d is invalid\n", o.Eqs.Nu, B.M)
+	}
+	o.factorAuu()
+	X = la.NewMatrix(B.M, B.N)
+	for j := 0; j < B.N; j++ {
+		o.solver.Solve(X.Col(j), B.Col(j), false)
+	}
+	return
+}
+
+// SolveConstrained solves the steady problem subject to a lower-bound constraint (obstacle problem)
+//
+//   Find {u} such that:  {u} ≥ {lower}  with  [K]⋅{u} = {f}  where {u} > {lower}
+//
+//   The reduced system is solved using the projected successive over-relaxation method
+//   (projected Gauss-Seidel with ω = 1.5); see la.SpProjGaussSeidel
+//
+//   Input:
+//     lower -- [nnodes] lower bound at each node of the grid
+//   Output:
+//     u -- [nnodes] solution at each node of the grid
+//
+//   NOTE: Assemble must be called first
+func (o *FdmLaplacian) SolveConstrained(lower []float64) (u []float64) {
+
+	// check
+	o.checkDouble("SolveConstrained")
+	if o.Eqs == nil || o.nmol == 0 {
+		chk.Panic("operator must be assembled before calling SolveConstrained\n")
+	}
+	if len(lower) != o.Grid.Size() {
+		chk.Panic("size of lower bound vector must be equal to the number of nodes. %d != %d\n", len(lower), o.Grid.Size())
+	}
+
+	// known values and right-hand side: bu -= Auk⋅xk
+	for i, I := range o.Eqs.KtoF {
+		o.Eqs.Xk[i] = o.calcXk(I, 0)
+	}
+	for i, I := range o.Eqs.UtoF {
+		o.Eqs.Bu[i] = o.calcBu(I, 0)
+	}
+	o.ApplyBoundaryCorrection(o.Eqs.Bu, o.Eqs.Xk)
+
+	// solve complementarity problem
+	lu := la.NewVector(o.Eqs.Nu)
+	for i, I := range o.Eqs.UtoF {
+		lu[i] = lower[I]
+	}
+	o.Eqs.Xu.Fill(0)
+	la.SpProjGaussSeidel(o.Eqs.Xu, o.Eqs.Auu.ToMatrix(nil), o.Eqs.Bu, lu, 1.5, 1e-12, 100000)
+
+	// results
+	u = make([]float64, o.Grid.Size())
+	o.Eqs.JoinVector(u, o.Eqs.Xu, o.Eqs.Xk)
+	return
+}
+
+// ApplyBoundaryCorrection corrects the RHS due to known values; e.g. for many right-hand sides or
+// optimisation loops where only the prescribed values change
+//
+//   {bu} -= [Auk]⋅{xk}
+//
+//   Input:
+//     bu -- [Nu] right-hand side corresponding to nodes without prescribed values
+//     xk -- [Nk] known values; e.g. Eqs.Xk
+//   Output:
+//     bu -- corrected right-hand side
+//
+//   NOTE: [Auk] is converted to column-compressed form in the first call only; the cached matrix
+//         is discarded when the operator is assembled again
+func (o *FdmLaplacian) ApplyBoundaryCorrection(bu, xk la.Vector) {
+	if o.Eqs == nil || o.nmol == 0 {
+		chk.Panic("operator must be assembled before calling ApplyBoundaryCorrection\n")
+	}
+	if len(bu) != o.Eqs.Nu || len(xk) != o.Eqs.Nk {
+		chk.Panic("sizes of vectors must be equal to Nu=%d and Nk=%d. len(bu)=%d, len(xk)=%d\n", o.Eqs.Nu, o.Eqs.Nk, len(bu), len(xk))
+	}
+	if o.Eqs.Nk == 0 {
+		return
+	}
+	if o.aukMat == nil {
+		o.aukMat = o.Eqs.Auk.ToMatrix(nil)
+	}
+	la.SpMatVecMulAdd(bu, -1.0, o.aukMat, xk)
+}
+
+// ResidualField computes the residual {r} = {b} - [A]⋅{u} of the equations of nodes without
+// prescribed values; e.g. to localise where an (iterative) solution is inaccurate
+//
+//   {ru} = {bu} - [Auu]⋅{uu} - [Auk]⋅{uk}
+//
+//   Input:
+//     u -- [nnodes] values at all nodes; the values at nodes with prescribed values are used as {uk}
+//   Output:
+//     r -- [nnodes] residual at all nodes; zero at nodes with prescribed values
+//
+//   NOTE: Assemble must be called first
+func (o *FdmLaplacian) ResidualField(u []float64) (r []float64) {
+	o.checkDouble("ResidualField")
+	if o.Eqs == nil || o.nmol == 0 {
+		chk.Panic("operator must be assembled before calling ResidualField\n")
+	}
+	if len(u) != o.Grid.Size() {
+		chk.Panic("size of u must be equal to the number of nodes. %d != %d\n", len(u), o.Grid.Size())
+	}
+	e := o.Eqs
+	uu := la.NewVector(e.Nu)
+	uk := la.NewVector(e.Nk)
+	e.SplitVector(uu, uk, u)
+	ru := la.NewVector(e.Nu)
+	for i, I := range e.UtoF {
+		ru[i] = o.calcBu(I, 0)
+	}
+	la.SpMatVecMulAdd(ru, -1, e.Auu.ToMatrix(nil), uu)
+	o.ApplyBoundaryCorrection(ru, uk)
+	r = make([]float64, o.Grid.Size())
+	e.JoinVector(r, ru, la.NewVector(e.Nk))
+	return
+}
+
+// ErrorEstimate computes a-posteriori bounds of the error of an approximate solution at each node
+// with respect to the exact solution of the PDE; i.e. including the algebraic error (with respect
+// to the solution of the discrete system; see ResidualField and GreensFunctionColumn) and the
+// discretisation error
+//
+//   {eu} = [Auu]⁻¹⋅({ru} + {τu})   ⇒   |e_i| ≤ Σ_j |G_ij|⋅(|r_j| + |τ_j|) = -([Auu]⁻¹⋅(|{ru}| + |{τu}|))_i
+//
+//   where the Green's function G = [Auu]⁻¹ is not positive (see NOTE 1). The truncation error τ of
+//   the 5-point stencil is estimated by the difference between the second differences with
+//   spacings 2h and h applied to u (along each direction)
+//
+//              δ²_2h u - δ²_h u     h²  ∂⁴u
+//    τ ≈ k ⋅ ———————————————— ≈  —— k ———     ⇒   τ ≈ k ⋅ (u[i-2] - 4u[i-1] + 6u[i] - 4u[i+1] + u[i+2]) / (12 h²)
+//                    3          12  ∂x⁴
+//
+//   with the five nodes shifted inwards near the borders. The estimate is multiplied by the safety
+//   factor 2 (as in the grid convergence index) to account for the higher-order terms. Thus, the
+//   bounds are computed with one back-substitution and they are sharp where the residual and ∂⁴u
+//   do not change sign
+//
+//   Input:
+//     u -- [nnodes] approximate solution; e.g. from SolveIterative. The values at nodes with
+//          prescribed values must be the prescribed ones
+//   Output:
+//     bound -- [nnodes] estimated bounds |u - uExact| ≤ bound; zero at nodes with prescribed values
+//
+//   NOTE: (1) the bounds of the algebraic error are conservative if -[Auu] is an M-matrix (G ≤ 0);
+//             e.g. with the 5-point stencil, positive coefficients and non-negative reactions.
+//             Otherwise, they are estimates only; e.g. with kxy ≠ 0 or the Mehrstellen stencil
+//         (2) the truncation term is an asymptotic estimate (h → 0) of the leading term; i.e. the
+//             solution must be resolved by the grid. It requires uniform grids with at least 5
+//             nodes along each direction; otherwise, or with the (fourth-order) Mehrstellen
+//             stencil, only the algebraic error is bounded
+//         (3) the factorisation of [Auu] is computed in the first call only (see ReapplyBcs)
+func (o *FdmLaplacian) ErrorEstimate(u []float64) (bound []float64) {
+	if o.Float32 {
+		chk.Panic("ErrorEstimate is not available in single precision\n")
+	}
+	r := o.ResidualField(u)
+	τ := o.truncationEstimate(u)
+	ru := la.NewVector(o.Eqs.Nu)
+	for i, I := range o.Eqs.UtoF {
+		ru[i] = math.Abs(r[I]) + 2.0*math.Abs(τ[I]) // 2: safety factor
+	}
+	o.factorAuu()
+	eu := la.NewVector(o.Eqs.Nu)
+	o.solver.Solve(eu, ru, false)
+	bound = make([]float64, o.Grid.Size())
+	for i, I := range o.Eqs.UtoF {
+		bound[I] = math.Abs(eu[i])
+	}
+	return
+}
+
+// Reactions computes the reactions at nodes with prescribed values after solving; i.e. the
+// (integrated) source needed to hold the prescribed values
+//
+//   {rk} = [W] ⋅ ([Aku]⋅{xu} + [Akk]⋅{xk} - {bk})
+//
+//   where {bk} are the source values at the prescribed nodes and [W] is the diagonal matrix with
+//   the quadrature weights (see GridQuadrature); thus, a positive reaction is a flux entering
+//   the domain and the reactions balance the sources:
+//
+//             ⌠
+//   Σ rk  +   │ s dΩ  =  0
+//             ⌡Ω
+//
+//   Output:
+//     r -- [nnodes] reactions at all nodes; zero at nodes without prescribed values
+//
+//   NOTE: (1) Assemble(true) and SolveSteady must be called first
+//         (2) the balance holds if the equations of boundary nodes are written with mirrored
+//             nodes only; e.g. it does not hold with kxy ≠ 0 or Robin conditions
+func (o *FdmLaplacian) Reactions() (r []float64) {
+	o.checkDouble("Reactions")
+	e := o.Eqs
+	if e == nil || o.nmol == 0 || e.Aku == nil {
+		chk.Panic("operator must be assembled with reactions = true and solved before calling Reactions\n")
+	}
+	r = make([]float64, o.Grid.Size())
+	if e.Nk == 0 {
+		return
+	}
+	rk := la.NewVector(e.Nk)
+	la.SpMatVecMul(rk, 1, e.Aku.ToMatrix(nil), e.Xu)
+	la.SpMatVecMulAdd(rk, 1, e.Akk.ToMatrix(nil), e.Xk)
+	weights := GridQuadrature(o.Grid)
+	for i, I := range e.KtoF {
+		r[I] = weights[I] * (rk[i] - o.calcBu(I, 0))
+	}
+	return
+}
+
+// DenseSystem returns the dense matrix and the right-hand side of the system [Auu]⋅{xu} = {bu};
+// e.g. for teaching or inspecting small problems
+//
+//   {bu} = {s} - [Auk]⋅{xk}   (with the contributions of natural and Robin conditions)
+//
+//   Output:
+//     A   -- [Nu][Nu] dense matrix [nil if err != nil]
+//     b   -- [Nu] right-hand side [nil if err != nil]
+//     err -- *DenseLimitError if Nu > MaxDense; i.e. the matrix is not allocated
+//
+//   NOTE: Assemble must be called first
+func (o *FdmLaplacian) DenseSystem() (A *la.Matrix, b la.Vector, err error) {
+	o.checkDouble("DenseSystem")
+	if o.Eqs == nil || o.nmol == 0 {
+		chk.Panic("operator must be assembled before calling DenseSystem\n")
+	}
+	limit := o.MaxDense
+	if limit <= 0 {
+		limit = DefaultMaxDense
+	}
+	if o.Eqs.Nu > limit {
+		return nil, nil, &DenseLimitError{N: o.Eqs.Nu, Limit: limit}
+	}
+	b, _ = o.reducedRhs()
+	return o.Eqs.Auu.ToDense(), b, nil
+}
+
+// AssembleFullWithDirichletRows assembles the full (square) system with identity rows for the nodes
+// with prescribed values instead of partitioning it into the u- and k-systems; e.g. for external
+// solvers expecting all degrees of freedom
+//
+//   [A]⋅{u} = {b}     with     A_IJ = δ_IJ  and  b_I = ū_I   at nodes with prescribed values
+//
+//   Output:
+//     a -- [nnodes][nnodes] matrix with all nodes (see Grid for the numbering)
+//     b -- [nnodes] right-hand side: {s} and the contributions of natural and Robin conditions at
+//          the other nodes
+//
+//   NOTE: (1) 2D only; the operator does not need to be assembled (Eqs is not modified)
+//         (2) the columns of prescribed nodes are kept in the other rows; thus, [A] is not
+//             symmetric even if [Auu] is. The solution is the same as with SolveSteady
+func (o *FdmLaplacian) AssembleFullWithDirichletRows() (a *la.Triplet, b la.Vector) {
+	if o.Grid.Ndim() != 2 {
+		chk.Panic("AssembleFullWithDirichletRows works in 2D only\n")
+	}
+	nmol := o.molSize()
+	if !o.bcsReady {
+		o.initEqs()
+	}
+	n := o.Grid.Size()
+	a = la.NewTriplet(n, n, nmol*n)
+	b = la.NewVector(n)
+	for I := 0; I < n; I++ {
+		if o.Eqs.FtoU[I] < 0 {
+			a.Put(I, I, 1)
+			b[I] = o.calcXk(I, 0)
+			continue
+		}
+		o.stencil2d(I, func(I, J int, value float64) { a.Put(I, J, value) })
+		b[I] = o.calcBu(I, 0)
+	}
+	return
+}
+
+// SolveDense solves the steady problem with the dense system (see DenseSystem)
+//   u   -- [nnodes] solution at all nodes [nil if err != nil]
+//   err -- *DenseLimitError if Nu > MaxDense
+func (o *FdmLaplacian) SolveDense() (u []float64, err error) {
+	A, b, err := o.DenseSystem()
+	if err != nil {
+		return nil, err
+	}
+	xu := la.NewVector(o.Eqs.Nu)
+	xk := la.NewVector(o.Eqs.Nk)
+	la.DenSolve(xu, A, b, false)
+	for i, I := range o.Eqs.KtoF {
+		xk[i] = o.calcXk(I, 0)
+	}
+	u = make([]float64, o.Grid.Size())
+	o.Eqs.JoinVector(u, xu, xk)
+	return
+}
+
+// DiscreteSource computes the source term that makes the sampled exact solution the exact solution of
+// the discrete problem; i.e. {f} = [K]⋅{u_exact} at nodes without prescribed values
+//
+//   This is useful for the method of manufactured solutions (MMS) because the discretisation
+//   error is removed and the remaining error comes from the boundary conditions (or the solver)
+//
+//   Input:
+//     uExact -- exact solution u({x},t) sampled at t = 0
+//   Output:
+//     f -- [nnodes] source term at all nodes; zero at nodes with prescribed values
+//
+//   NOTE: Assemble must be called first
+func (o *FdmLaplacian) DiscreteSource(uExact fun.Svs) (f []float64) {
+	o.checkDouble("DiscreteSource")
+	if o.Eqs == nil || o.nmol == 0 {
+		chk.Panic("Assemble must be called first\n")
+	}
+	uu := la.NewVector(o.Eqs.Nu)
+	uk := la.NewVector(o.Eqs.Nk)
+	for i, I := range o.Eqs.UtoF {
+		uu[i] = uExact(o.Grid.Node(I), 0)
+	}
+	for i, I := range o.Eqs.KtoF {
+		uk[i] = uExact(o.Grid.Node(I), 0)
+	}
+	fu := la.NewVector(o.Eqs.Nu)
+	la.SpMatVecMul(fu, 1, o.Eqs.Auu.ToMatrix(nil), uu)
+	if o.Eqs.Nk > 0 {
+		la.SpMatVecMulAdd(fu, 1, o.Eqs.Auk.ToMatrix(nil), uk)
+	}
+	f = make([]float64, o.Grid.Size())
+	o.Eqs.JoinVector(f, fu, la.NewVector(o.Eqs.Nk))
+	return
+}
+
+// Energy computes the discrete (Dirichlet) energy functional minimised by the solution of the
+// steady problem
+//
+//   E({u}) = ½ {uu}ᵀ⋅[W]⋅[K]⋅{uu} - {f}ᵀ⋅[W]⋅{uu}   with  [K] = -[Auu]  and  {f} = -({s} - [Auk]⋅{uk})
+//
+//           ⌠
+//          ≈│ (½ k |∇u|² + ½ kr u² + s u) dΩ
+//           ⌡Ω
+//
+//   where {uu} are the values at nodes without prescribed values and [W] is the diagonal matrix
+//   with the quadrature weights (see GridQuadrature). The weights make [W]⋅[K] symmetric because
+//   the equations of boundary nodes are written with mirrored nodes. The signs are changed
+//   because [Auu] is negative definite (A ≈ L{u}); thus, [W]⋅[K] is positive definite and
+//   {uu} = [Auu]⁻¹⋅{bu} is the minimiser of E
+//
+//   Input:
+//     u -- [nnodes] values at all nodes; the values at nodes with prescribed values are ignored
+//   NOTE: (1) the operator must be assembled first and the reaction coefficient must be non-negative
+//         (2) the Mehrstellen stencil, kxy ≠ 0 and the tensor field are not supported
+func (o *FdmLaplacian) Energy(u []float64) (energy float64) {
+	o.checkDouble("Energy")
+	if o.Eqs == nil || o.nmol == 0 {
+		chk.Panic("operator must be assembled before calling Energy\n")
+	}
+	if o.Mehrstellen || o.Kxy != 0 || o.kTensor != nil {
+		chk.Panic("Energy does not support the Mehrstellen stencil, kxy or the tensor field\n")
+	}
+	if len(u) != o.Grid.Size() {
+		chk.Panic("size of u must be equal to the number of nodes. %d != %d\n", len(u), o.Grid.Size())
+	}
+	e := o.Eqs
+	uu := la.NewVector(e.Nu)
+	bu := la.NewVector(e.Nu)
+	for i, I := range e.UtoF {
+		uu[i] = u[I]
+		bu[i] = o.calcBu(I, 0)
+	}
+	if e.Nk > 0 {
+		uk := la.NewVector(e.Nk)
+		for i, I := range e.KtoF {
+			uk[i] = o.calcXk(I, 0)
+		}
+		la.SpMatVecMulAdd(bu, -1, e.Auk.ToMatrix(nil), uk)
+	}
+	au := la.NewVector(e.Nu)
+	la.SpMatVecMul(au, 1, e.Auu.ToMatrix(nil), uu)
+	w := GridQuadrature(o.Grid)
+	for i, I := range e.UtoF {
+		energy += w[I] * (-0.5*au[i] + bu[i]) * uu[i]
+	}
+	return
+}
+
+// StencilAt returns the assembled coefficients of the equation (row) corresponding to a node
+//   Input:
+//     I -- node number
+//   Output:
+//     nodes -- neighbour nodes, including I, sorted in ascending order
+//     coefs -- corresponding coefficients; e.g. the contributions of mirrored nodes are summed up
+//   NOTE: (1) the operator must be assembled first
+//         (2) the stencil of nodes with prescribed values requires Assemble(true)
+func (o *FdmLaplacian) StencilAt(I int) (nodes []int, coefs []float64) {
+	if o.Eqs == nil || o.nmol == 0 {
+		chk.Panic("operator must be assembled before calling StencilAt\n")
+	}
+	if I < 0 || I >= o.Grid.Size() {
+		chk.Panic("node %d is out of range [0, %d)\n", I, o.Grid.Size())
+	}
+	return o.Eqs.GetRow(I)
+}
+
+// DumpEquations returns a formatted description of the partitioning of equations; e.g. for
+// debugging boundary conditions
+//
+//   The output contains the number of equations, the maps between the full system and the
+//   reduced u- and k-systems (UtoF, FtoU, KtoF and FtoK) and the sizes of the blocks of [A]
+//
+//   NOTE: (1) the equations are created by Assemble (or Apply)
+//         (2) the partitioning does not depend on the precision; in single precision (see Float32),
+//             only the compressed matrices are stored and the blocks are reported as not allocated
+func (o *FdmLaplacian) DumpEquations() (l string) {
+	if o.Eqs == nil {
+		chk.Panic("equations have not been created yet. call Assemble first\n")
+	}
+	e := o.Eqs
+	l = io.Sf("N  = %d\n", e.N)
+	l += io.Sf("Nu = %d\n", e.Nu)
+	l += io.Sf("Nk = %d\n", e.Nk)
+	l += io.Sf("UtoF = %v\n", e.UtoF)
+	l += io.Sf("FtoU = %v\n", e.FtoU)
+	l += io.Sf("KtoF = %v\n", e.KtoF)
+	l += io.Sf("FtoK = %v\n", e.FtoK)
+	block := func(name string, m, n int, t *la.Triplet) {
+		l += io.Sf("%s : %d × %d", name, m, n)
+		if t == nil {
+			l += " (not allocated)\n"
+			return
+		}
+		l += io.Sf(" (%d entries)\n", t.Len())
+	}
+	block("Auu", e.Nu, e.Nu, e.Auu)
+	block("Auk", e.Nu, e.Nk, e.Auk)
+	block("Aku", e.Nk, e.Nu, e.Aku)
+	block("Akk", e.Nk, e.Nk, e.Akk)
+	return
+}
+
+// EdgeFlux computes the total flux leaving the domain through an edge (2D only)
+//
+//          ⌠
+//   Q  =   │ -k ∂u/∂n dΓ       with  k = kx on vertical edges (10, 11) and k = ky on horizontal edges (20, 21)
+//          ⌡Γ
+//
+//   The normal derivative is computed with second-order one-sided differences and the integral is
+//   computed with the trapezoidal rule. Positive values mean that the flux leaves the domain
+//
+//   Input:
+//     tag -- edge tag: 10, 11, 20 or 21
+//     u   -- [nnodes] solution at all nodes
+//   NOTE: the cross term due to kxy is not considered
+func (o *FdmLaplacian) EdgeFlux(tag int, u []float64) (flux float64) {
+	if o.Grid.Ndim() != 2 {
+		chk.Panic("EdgeFlux works in 2D only\n")
+	}
+	if len(u) != o.Grid.Size() {
+		chk.Panic("size of u must be equal to the number of nodes. %d != %d\n", len(u), o.Grid.Size())
+	}
+	var dim int   // normal direction
+	var k float64 // coefficient
+	switch tag {
+	case 10, 11:
+		dim, k = 0, o.Kx
+	case 20, 21:
+		dim, k = 1, o.Ky
+	default:
+		chk.Panic("tag %d is invalid. tag must be 10, 11, 20 or 21\n", tag)
+	}
+	sign := -1.0 // sign of normal
+	if tag == 11 || tag == 21 {
+		sign = +1
+	}
+	nodes := o.Grid.EdgeGivenTag(tag)
+	h := o.Grid.Xlen(1-dim) / float64(len(nodes)-1) // spacing along edge
+	for i, I := range nodes {
+		w := h
+		if i == 0 || i == len(nodes)-1 {
+			w = h / 2.0
+		}
+		flux -= w * k * sign * gridDerivative(o.Grid, u, I, dim)
+	}
+	return
+}
+
+// AdjointSensitivity computes the gradient of a scalar output with respect to the prescribed
+// values (essential boundary conditions) using a single adjoint solve; e.g. for inverse problems
+//
+//   With {u} = {xu, xk} and [Auu]⋅{xu} = {bu} - [Auk]⋅{xk}, the gradient is
+//
+//     dJ      ∂J                                  T       ∂J
+//    ———— = ———— - [Auk]ᵀ⋅{λ}    where   [Auu] ⋅{λ} = ————
+//    d{xk}   ∂{uk}                                         ∂{uu}
+//
+//   Input:
+//     output -- computes the output J and its derivatives ∂J/∂u [nnodes] given the solution u [nnodes];
+//               e.g. J = EdgeFlux(tag, u)
+//   Output:
+//     J    -- output at the solution of the steady problem (see SolveSteady)
+//     grad -- [Nk] derivatives dJ/d{xk} of the output with respect to the prescribed values,
+//             following the order of Eqs.KtoF
+//
+//   NOTE: Assemble must be called first
+func (o *FdmLaplacian) AdjointSensitivity(output func(u []float64) (J float64, dJdu []float64)) (J float64, grad []float64) {
+
+	// solution and output
+	o.checkDouble("AdjointSensitivity")
+	if o.Eqs == nil || !o.bcsReady || o.nmol == 0 {
+		chk.Panic("operator must be assembled before calling AdjointSensitivity\n")
+	}
+	u, _ := o.SolveSteady(false)
+	J, dJdu := output(u)
+	if len(dJdu) != o.Grid.Size() {
+		chk.Panic("size of dJdu must be equal to the number of nodes. %d != %d\n", len(dJdu), o.Grid.Size())
+	}
+	gu := la.NewVector(o.Eqs.Nu)
+	grad = make([]float64, o.Eqs.Nk)
+	o.Eqs.SplitVector(gu, grad, dJdu)
+	if o.Eqs.Nk == 0 {
+		return
+	}
+
+	// adjoint solve: [Auu]ᵀ⋅{λ} = ∂J/∂{uu}
+	rows, cols, vals := o.Eqs.Auu.ToMatrix(nil).Triplets()
+	AuuT := la.NewTriplet(o.Eqs.Nu, o.Eqs.Nu, len(vals))
+	for k, v := range vals {
+		AuuT.Put(cols[k], rows[k], v)
+	}
+	λ := la.SpSolve(AuuT, gu)
+
+	// gradient: ∂J/∂{uk} - [Auk]ᵀ⋅{λ}
+	la.SpMatTrVecMulAdd(grad, -1, o.Eqs.Auk.ToMatrix(nil), λ)
+	return
+}
+
+// SensitivityToParam computes the derivative of the solution with respect to a scalar coefficient
+// of the operator; e.g. for parameter estimation
+//
+//   With the equations {r} = [A(p)]⋅{u} - {b(p)} = {0} of the nodes without prescribed values,
+//
+//           d{uu}       ∂{r}
+//    [Auu]⋅————— = - ————      and     d{uk}/dp = {0}
+//            dp         ∂p
+//
+//   The equations are affine in each coefficient (including the contributions of Robin conditions
+//   and interface conditions to the RHS); thus, ∂{r}/∂p is computed exactly from the stencils with
+//   p and p + Δp. The solution {u} and the derivatives are computed with the cached factorisation
+//   of [Auu] (see ReapplyBcs); i.e. with one extra back-substitution
+//
+//   Input:
+//     paramName -- "kx", "ky" (the directional coefficients), "kxy" or "kr"
+//   Output:
+//     dudp -- [nnodes] derivatives du/dp at all nodes of the grid
+//
+//   NOTE: (1) 2D only; the operator must be assembled first; call Free() to release the solver
+//         (2) the coefficients kx and ky must not be overridden by AssembleWithCoeffField or
+//             AssembleWithTensorField; likewise, kr by the Reaction function
+//         (3) the sensitivity to kxy is also available with kxy = 0 (uniform grids only)
+func (o *FdmLaplacian) SensitivityToParam(paramName string) (dudp []float64) {
+
+	// check
+	if o.Eqs == nil || !o.bcsReady || o.nmol == 0 {
+		chk.Panic("operator must be assembled before calling SensitivityToParam\n")
+	}
+	if o.Float32 {
+		chk.Panic("SensitivityToParam is not available in single precision\n")
+	}
+	var p *float64
+	switch paramName {
+	case "kx", "ky", "kxy":
+		if o.kField != nil || o.kTensor != nil {
+			chk.Panic("the sensitivity to %q is not available with coefficient fields\n", paramName)
+		}
+		p = map[string]*float64{"kx": &o.Kx, "ky": &o.Ky, "kxy": &o.Kxy}[paramName]
+	case "kr":
+		if o.Reaction != nil {
+			chk.Panic("the sensitivity to \"kr\" is not available with the Reaction function\n")
+		}
+		p = &o.Kr
+	default:
+		chk.Panic("parameter name %q is invalid. options: \"kx\", \"ky\", \"kxy\" or \"kr\"\n", paramName)
+	}
+	if paramName == "kxy" && o.Mehrstellen {
+		chk.Panic("the Mehrstellen stencil does not support the off-diagonal coefficient kxy\n")
+	}
+
+	// solution
+	u := o.ReapplyBcs()
+
+	// right-hand side: -∂{r}/∂p ≈ -({r}(p + Δp) - {r}(p))/Δp
+	p0 := *p
+	Δp := math.Max(1, math.Abs(p0))
+	rhs := la.NewVector(o.Eqs.Nu)
+	residual := func(sign float64) {
+		for i, I := range o.Eqs.UtoF {
+			o.stencil2d(I, func(_, J int, value float64) { rhs[i] -= sign * value * u[J] / Δp })
+			rhs[i] += sign * o.calcBu(I, 0) / Δp
+		}
+	}
+	*p = p0 + Δp
+	residual(+1)
+	*p = p0
+	residual(-1)
+
+	// derivatives
+	du := la.NewVector(o.Eqs.Nu)
+	o.solver.Solve(du, rhs, false)
+	dudp = make([]float64, o.Grid.Size())
+	for i, I := range o.Eqs.UtoF {
+		dudp[I] = du[i]
+	}
+	return
+}
+
+// WriteCSV writes a CSV file with the coordinates and values at all nodes of the grid
+//
+//  The columns are x,y,u (2D) or x,y,z,u (3D), with a header row, and the rows follow the
+//  node numbering of the grid (see gm.Grid.IndexItoMNP)
+//
+//  dirout -- directory for output. will be created
+//  fnkey  -- filename key (filename without extension). ".csv" will be added
+//  u      -- [nnodes] values at each node of the grid; e.g. from SolveSteady
+func (o *FdmLaplacian) WriteCSV(dirout, fnkey string, u []float64) {
+	if len(u) != o.Grid.Size() {
+		chk.Panic("size of u must be equal to the number of nodes. %d != %d\n", len(u), o.Grid.Size())
+	}
+	var buf bytes.Buffer
+	ndim := o.Grid.Ndim()
+	io.Ff(&buf, "%s,u\n", []string{"x,y", "x,y,z"}[ndim-2])
+	for I := 0; I < o.Grid.Size(); I++ {
+		x := o.Grid.Node(I)
+		for i := 0; i < ndim; i++ {
+			io.Ff(&buf, "%.17g,", x[i])
+		}
+		io.Ff(&buf, "%.17g\n", u[I])
+	}
+	io.WriteFileD(dirout, fnkey+".csv", &buf)
+}
+
+// WriteNpy writes a NumPy (.npy) file with the values at all nodes of the grid; e.g. for
+// post-processing in Python without the round-off of text files
+//
+//  The shape of the array is (ny, nx) in 2D or (nz, ny, nx) in 3D; i.e. a[n, m] = u[I] with
+//  I = m + n⋅nx (see gm.Grid.IndexMNPtoI). The file can be read back with io.ReadNpy
+//
+//  dirout -- directory for output. will be created
+//  fnkey  -- filename key (filename without extension). ".npy" will be added
+//  u      -- [nnodes] values at each node of the grid; e.g. from SolveSteady
+func (o *FdmLaplacian) WriteNpy(dirout, fnkey string, u []float64) {
+	if len(u) != o.Grid.Size() {
+		chk.Panic("size of u must be equal to the number of nodes. %d != %d\n", len(u), o.Grid.Size())
+	}
+	shape := []int{o.Grid.Npts(1), o.Grid.Npts(0)}
+	if o.Grid.Ndim() == 3 {
+		shape = []int{o.Grid.Npts(2), o.Grid.Npts(1), o.Grid.Npts(0)}
+	}
+	io.WriteNpyD(dirout, fnkey+".npy", shape, u)
+}
+
+// WriteHeatmapPNG writes a PNG image with the values at the nodes of a 2D grid mapped to colours;
+// e.g. for quick visual checks of the solution
+//
+//  Each node corresponds to one pixel; the first row of the image is at ymax (as in
+//  LoadCoeffRaster). The colours are scaled to the range [min(u), max(u)]
+//
+//  dirout   -- directory for output. will be created
+//  fnkey    -- filename key (filename without extension). ".png" will be added
+//  u        -- [nnodes] values at each node of the grid; e.g. from SolveSteady
+//  colormap -- "viridis" [default if empty] or "gray"
+func (o *FdmLaplacian) WriteHeatmapPNG(dirout, fnkey string, u []float64, colormap string) {
+	if o.Grid.Ndim() != 2 {
+		chk.Panic("WriteHeatmapPNG works in 2D only\n")
+	}
+	if len(u) != o.Grid.Size() {
+		chk.Panic("size of u must be equal to the number of nodes. %d != %d\n", len(u), o.Grid.Size())
+	}
+	writeHeatmapPng(dirout, fnkey, o.Grid, u, colormap)
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// connectFdmParams connects coefficients to parameters
+func connectFdmParams(params dbf.Params, kx, ky, kz, kxy, kr *float64) {
+	err := params.ConnectSetOpt(
+		[]*float64{kx, ky, kz, kxy, kr},
+		[]string{"kx", "ky", "kz", "kxy", "kr"},
+		[]bool{false, false, true, true, true},
+		"FdmLaplacian",
+	)
+	if err != "" {
+		chk.Panic(err)
+	}
+}
+
+// initApply checks the options and creates the equations for the matrix-free functions
+func (o *FdmLaplacian) initApply(fname string) {
+	if o.Grid.Ndim() != 2 {
+		chk.Panic("%s works in 2D only\n", fname)
+	}
+	o.molSize() // check options
+	if !o.bcsReady {
+		o.initEqs()
+	}
+}
+
+// applyRow computes ([Auu]⋅{uu})[i] with the stencil of node I = UtoF[i]
+func (o *FdmLaplacian) applyRow(I int, uu la.Vector) (res float64) {
+	o.stencil2d(I, func(_, J int, value float64) {
+		if j := o.Eqs.FtoU[J]; j >= 0 {
+			res += value * uu[j]
+		}
+	})
+	return
+}
+
+// initEqs creates the structure of equations (without allocating matrices)
+func (o *FdmLaplacian) initEqs() {
+	if err := checkBcsGrid(o.Grid, o.EssenBcs, o.NaturBcs, o.RobinBcs); err != nil {
+		chk.Panic("%v\n", err)
+	}
+	o.Eqs = la.NewEquations(o.Grid.Size(), o.EssenBcs.Nodes())
+	switch o.Ordering {
+	case "", "lex":
+	case "redblack":
+		o.Eqs.ReorderUnknowns(redBlack(o.Grid, o.Eqs.UtoF))
+	default:
+		chk.Panic("ordering %q is invalid. options: \"lex\" or \"redblack\"\n", o.Ordering)
+	}
+	o.bcsReady = true
+	o.nmol = 0 // not allocated yet
+	o.aukMat, o.auuMat = nil, nil
+}
+
+// assembleDirectional assembles the second-derivative part of the operator along dim (2D)
+func (o *FdmLaplacian) assembleDirectional(dim int, reactions bool) (err error) {
+	if o.Mehrstellen || o.kTensor != nil {
+		chk.Panic("directional operators are not available with the Mehrstellen stencil or the tensor field\n")
+	}
+	kx, ky, kxy, kr, reaction := o.Kx, o.Ky, o.Kxy, o.Kr, o.Reaction
+	if dim == 0 {
+		o.Ky = 0
+	} else {
+		o.Kx = 0
+	}
+	o.Kxy, o.Kr, o.Reaction = 0, 0, nil
+	err = o.Assemble(reactions)
+	o.Kx, o.Ky, o.Kxy, o.Kr, o.Reaction = kx, ky, kxy, kr, reaction
+	return
+}
+
+// assemble32 assembles the single precision [Auu] and [Auk] (2D)
+func (o *FdmLaplacian) assemble32(nmol int, reactions bool) (err error) {
+	if reactions {
+		chk.Panic("reactions cannot be computed in single precision\n")
+	}
+	if o.Grid.Ndim() != 2 {
+		chk.Panic("single precision assembly is available in 2D only\n")
+	}
+	auu := la.NewTriplet32(o.Eqs.Nu, o.Eqs.Nu, nmol*o.Eqs.Nu)
+	auk := la.NewTriplet32(o.Eqs.Nu, utl.Imax(o.Eqs.Nk, 1), nmol*o.Eqs.Nu)
+	for i, I := range o.Eqs.UtoF {
+		o.stencil2d(I, o.checkedPut(&err, func(_, J int, value float64) {
+			if j := o.Eqs.FtoU[J]; j >= 0 {
+				auu.Put(i, j, float32(value))
+			} else {
+				auk.Put(i, o.Eqs.FtoK[J], float32(value))
+			}
+		}))
+	}
+	if err != nil {
+		return
+	}
+	o.auu32 = auu.ToMatrix32()
+	if auk.Len() > 0 {
+		o.auk32 = auk.ToMatrix32()
+	}
+	return
+}
+
+// solveSteady32 solves the steady problem in single precision
+//   {bu} = {su} - [Auk]⋅{xk}  and  [Auu]⋅{xu} = {bu}
+//   guess -- [nnodes] initial values [may be nil ⇒ zero]
+func (o *FdmLaplacian) solveSteady32(tol float64, maxIt int, guess []float64) (u []float64, nit int) {
+	if o.auu32 == nil {
+		chk.Panic("the single precision matrices must be assembled first\n")
+	}
+	logf(o.Logger, "FdmLaplacian: solving system in single precision with Nu = %d unknown and Nk = %d known values\n", o.Eqs.Nu, o.Eqs.Nk)
+	xk := make([]float32, utl.Imax(o.Eqs.Nk, 1))
+	for k, I := range o.Eqs.KtoF {
+		xk[k] = float32(o.calcXk(I, 0))
+	}
+	bu := make([]float32, o.Eqs.Nu)
+	for i, I := range o.Eqs.UtoF {
+		bu[i] = float32(o.calcBu(I, 0))
+	}
+	if o.auk32 != nil {
+		la.SpMatVecMulAdd32(bu, -1, o.auk32, xk)
+	}
+	xu := make([]float32, o.Eqs.Nu)
+	if guess != nil {
+		for i, I := range o.Eqs.UtoF {
+			xu[i] = float32(guess[I])
+		}
+	}
+	nit = la.SpBiCGStab32(xu, o.auu32, bu, tol, maxIt)
+	r := make([]float32, o.Eqs.Nu)
+	copy(r, bu)
+	la.SpMatVecMulAdd32(r, -1, o.auu32, xu)
+	rnorm, bnorm := 0.0, 0.0
+	for i := range r {
+		rnorm += float64(r[i]) * float64(r[i])
+		bnorm += float64(bu[i]) * float64(bu[i])
+	}
+	if bnorm == 0 {
+		bnorm = 1
+	}
+	o.info = SolveInfo{Method: "bicgstab32", Precond: "jacobi", Nit: nit, Residual: math.Sqrt(rnorm / bnorm)}
+	u = make([]float64, o.Grid.Size())
+	for i, I := range o.Eqs.UtoF {
+		u[I] = float64(xu[i])
+	}
+	for _, I := range o.Eqs.KtoF {
+		u[I] = o.calcXk(I, 0)
+	}
+	return
+}
+
+// molSize returns the number of entries in molecule (including repetitions)
+func (o *FdmLaplacian) molSize() (nmol int) {
+	nmol = 5
+	if o.kField != nil && (o.Mehrstellen || o.Kxy != 0) {
+		chk.Panic("the coefficient field cannot be used with the Mehrstellen stencil or kxy\n")
+	}
+	if o.jumps != nil && (o.Mehrstellen || o.kField != nil || o.kTensor != nil) {
+		chk.Panic("interface conditions cannot be used with the Mehrstellen stencil or the coefficient fields\n")
+	}
+	if o.kTensor != nil && o.Mehrstellen {
+		chk.Panic("the tensor field cannot be used with the Mehrstellen stencil\n")
+	}
+	if (o.Mehrstellen || o.Kxy != 0 || o.kTensor != nil) && !uniformGrid(o.Grid) {
+		chk.Panic("the Mehrstellen stencil, kxy and the tensor field require uniform grids\n")
+	}
+	if o.Kxy != 0 || o.kTensor != nil {
+		nmol = 9
+	}
+	if o.Mehrstellen {
+		if len(o.RobinBcs.items) > 0 {
+			chk.Panic("the Mehrstellen stencil does not support Robin boundary conditions\n")
+		}
+		if o.Kxy != 0 {
+			chk.Panic("the Mehrstellen stencil does not support the off-diagonal coefficient kxy\n")
+		}
+		nmol = 13
+	}
+	if len(o.RobinBcs.items) > 0 {
+		nmol++ // diagonal of Robin conditions
+	}
+	return
+}
+
+// stencil2d computes the coefficients of the equation of node I (2D) and calls put(I, J, value)
+// for each (possibly repeated) entry; e.g. put = Eqs.Put
+func (o *FdmLaplacian) stencil2d(I int, put func(I, J int, value float64)) {
+	nx := o.Grid.Npts(0)
+	ny := o.Grid.Npts(1)
+	dx := o.Grid.Xlen(0) / float64(nx-1)
+	dy := o.Grid.Xlen(1) / float64(ny-1)
+	dx2 := dx * dx
+	dy2 := dy * dy
+	α := -2.0 * (o.Kx/dx2 + o.Ky/dy2)
+	c := (o.Kx*dy2 + o.Ky*dx2) / (12.0 * dx2 * dy2) // coefficient of δx² δy² (Mehrstellen)
+	col := I % nx                                   // grid column number
+	row := I / nx                                   // grid row number
+	jays := [5]int{I, I - 1, I + 1, I - nx, I + nx} // current, left, right, bottom and top nodes
+	if col == 0 {
+		jays[1] = jays[2]
+	}
+	if col == nx-1 {
+		jays[2] = jays[1]
+	}
+	if row == 0 {
+		jays[3] = jays[4]
+	}
+	if row == ny-1 {
+		jays[4] = jays[3]
+	}
+	geo := o.threePoint(I) // 1/h² on uniform grids
+	mol := [5]float64{-o.reaction(I), o.Kx * geo[1], o.Kx * geo[2], o.Ky * geo[3], o.Ky * geo[4]}
+	for k := 1; k < 5; k++ {
+		mol[0] -= mol[k]
+	}
+	if o.kField != nil { // harmonic mean at faces (mirrored nodes have the same coefficient)
+		mol[0] = -o.reaction(I)
+		for k := 1; k < 5; k++ {
+			kI, kJ := o.kField[I], o.kField[jays[k]]
+			mol[k] *= 2.0 * kI * kJ / (kI + kJ)
+			mol[0] -= mol[k]
+		}
+	}
+	if o.kTensor != nil { // harmonic means of kxx and kyy at faces
+		mol = [5]float64{-o.reaction(I), geo[1], geo[2], geo[3], geo[4]}
+		for k := 1; k < 5; k++ {
+			kk := o.kTensor[2*((k-1)/2)] // kxx for left and right; kyy for bottom and top
+			kI, kJ := kk[I], kk[jays[k]]
+			mol[k] *= 2.0 * kI * kJ / (kI + kJ)
+			mol[0] -= mol[k]
+		}
+	}
+	if o.Mehrstellen {
+		mol[0] = α + 4.0*c - 8.0*o.reaction(I)/12.0
+	}
+	for k, J := range jays { // loop over non-zero columns
+		put(I, J, mol[k])
+	}
+	if o.RobinBcs.Has(I) {
+		diag, _ := o.robin(I, 0)
+		put(I, I, diag)
+	}
+	if o.Kxy != 0 && o.kTensor == nil { // diagonal neighbours (mirrored at borders)
+		δ := o.Kxy / (2.0 * dx * dy)
+		put(I, mirroredNode(o.Grid, col, row, +1, +1), +δ)
+		put(I, mirroredNode(o.Grid, col, row, -1, -1), +δ)
+		put(I, mirroredNode(o.Grid, col, row, -1, +1), -δ)
+		put(I, mirroredNode(o.Grid, col, row, +1, -1), -δ)
+	}
+	if o.kTensor != nil { // cross terms with kxy at the other two nodes of each cell (mirrored at borders)
+		kxy := o.kTensor[1]
+		δ := 1.0 / (4.0 * dx * dy)
+		for _, ab := range [][2]int{{+1, +1}, {-1, -1}, {-1, +1}, {+1, -1}} {
+			a, b := ab[0], ab[1]
+			kk := kxy[mirroredNode(o.Grid, col, row, a, 0)] + kxy[mirroredNode(o.Grid, col, row, 0, b)]
+			put(I, mirroredNode(o.Grid, col, row, a, b), float64(a*b)*δ*kk)
+		}
+	}
+	if o.Mehrstellen { // remaining terms of δx² δy² and weighted reaction (mirrored at borders)
+		for k := 1; k < 5; k++ {
+			put(I, jays[k], -2.0*c-o.reaction(jays[k])/12.0)
+		}
+		put(I, mirroredNode(o.Grid, col, row, +1, +1), c)
+		put(I, mirroredNode(o.Grid, col, row, -1, -1), c)
+		put(I, mirroredNode(o.Grid, col, row, -1, +1), c)
+		put(I, mirroredNode(o.Grid, col, row, +1, -1), c)
+	}
+}
+
+// fdmLsqRow holds one row of the rectangular system of SolveLeastSquares
+type fdmLsqRow struct {
+	cols []int     // columns (nodes); without repetitions
+	vals []float64 // coefficients
+	rhs  float64   // right-hand side
+}
+
+// lsqRows computes the rows of the rectangular system of SolveLeastSquares
+func (o *FdmLaplacian) lsqRows(wEssen float64) (rows []*fdmLsqRow) {
+	if wEssen <= 0 {
+		wEssen = 1
+	}
+	covered := func(I int) bool { // the essential tags of I are shared by natural or Robin conditions
+		tags := o.EssenBcs.Tags(I)
+		if len(tags) == 0 {
+			return false
+		}
+		for _, tag := range tags {
+			ok := utl.IntIndexSmall(o.NaturBcs.Tags(I), tag) >= 0
+			o.RobinBcs.terms(I, func(dim int, item *robinItem) { ok = ok || item.tag == tag })
+			if !ok {
+				return false
+			}
+		}
+		return true
+	}
+	for I := 0; I < o.Grid.Size(); I++ {
+		if !o.EssenBcs.Has(I) || covered(I) {
+			r := &fdmLsqRow{rhs: o.calcBu(I, 0)}
+			o.stencil2d(I, func(_, J int, value float64) {
+				if k := utl.IntIndexSmall(r.cols, J); k >= 0 {
+					r.vals[k] += value
+					return
+				}
+				r.cols = append(r.cols, J)
+				r.vals = append(r.vals, value)
+			})
+			rows = append(rows, r)
+		}
+		if o.EssenBcs.Has(I) {
+			rows = append(rows, &fdmLsqRow{[]int{I}, []float64{wEssen}, wEssen * o.calcXk(I, 0)})
+		}
+	}
+	return
+}
+
+// checkRows records the equations of [Auu] without off-diagonal entries (see DecoupledNodes) and
+// panics if any of these equations has a zero diagonal (singular system)
+func (o *FdmLaplacian) checkRows(diag []float64, ncoup []int) {
+	o.decoupled = nil
+	var singular []int
+	for i, I := range o.Eqs.UtoF {
+		if ncoup[i] > 0 {
+			continue
+		}
+		if diag[i] == 0 {
+			singular = append(singular, I)
+		}
+		o.decoupled = append(o.decoupled, I)
+	}
+	if len(singular) > 0 {
+		chk.Panic("the system is singular: the equations of nodes %v have no non-zero coefficients; e.g. check the coefficients and the boundary conditions\n", singular)
+	}
+	if len(o.decoupled) > 0 {
+		logf(o.Logger, "FdmLaplacian: %d equations are not coupled to other unknowns (determined by the prescribed neighbours alone)\n", len(o.decoupled))
+	}
+}
+
+// factorAuu factorises [Auu] with the cached linear solver, if not factorised yet
+func (o *FdmLaplacian) factorAuu() {
+	o.checkDouble("the factorisation of [Auu]")
+	if o.solver == nil {
+		o.solver = la.NewSparseSolver("umfpack")
+		o.solver.Init(o.Eqs.Auu, false, false, "", "", nil)
+		o.solver.Fact()
+	}
+}
+
+// truncationEstimate estimates the truncation error of the 5-point stencil at the nodes with the
+// fourth differences of u (see ErrorEstimate) [all zero with the Mehrstellen stencil or
+// non-uniform grids]
+func (o *FdmLaplacian) truncationEstimate(u []float64) (τ []float64) {
+	g := o.Grid
+	τ = make([]float64, g.Size())
+	if o.Mehrstellen || !uniformGrid(g) {
+		return
+	}
+	for dim := 0; dim < g.Ndim(); dim++ {
+		npts := g.Npts(dim)
+		if npts < 5 {
+			continue
+		}
+		h := g.Xlen(dim) / float64(npts-1)
+		for I := range τ {
+			idx := make([]int, 3)
+			idx[0], idx[1], idx[2] = g.IndexItoMNP(I)
+			c := utl.Imin(utl.Imax(idx[dim], 2), npts-3) // centre of the five nodes
+			d4 := 0.0
+			for k, w := range []float64{1, -4, 6, -4, 1} {
+				jdx := []int{idx[0], idx[1], idx[2]}
+				jdx[dim] = c - 2 + k
+				d4 += w * u[g.IndexMNPtoI(jdx[0], jdx[1], jdx[2])]
+			}
+			τ[I] += o.coefAlong(dim, I) * d4 / (12.0 * h * h)
+		}
+	}
+	return
+}
+
+// coefAlong returns the diffusion coefficient along direction dim at node I
+func (o *FdmLaplacian) coefAlong(dim, I int) float64 {
+	if o.kTensor != nil {
+		return o.kTensor[2*dim][I]
+	}
+	k := []float64{o.Kx, o.Ky, o.Kz}[dim]
+	if o.kField != nil {
+		k *= o.kField[I]
+	}
+	return k
+}
+
+// checkDouble panics if Float32 is set; i.e. the double precision matrices in Eqs are not assembled
+// (or outdated)
+func (o *FdmLaplacian) checkDouble(fname string) {
+	if o.Float32 {
+		chk.Panic("%s is not available in single precision (see Float32)\n", fname)
+	}
+}
+
+// reducedRhs computes the RHS of the u-system {bu} = {s} - [Auk]⋅{xk} and the known values {xk}
+func (o *FdmLaplacian) reducedRhs() (bu, xk la.Vector) {
+	bu = la.NewVector(o.Eqs.Nu)
+	xk = la.NewVector(o.Eqs.Nk)
+	for i, I := range o.Eqs.UtoF {
+		bu[i] = o.calcBu(I, 0)
+	}
+	for i, I := range o.Eqs.KtoF {
+		xk[i] = o.calcXk(I, 0)
+	}
+	o.ApplyBoundaryCorrection(bu, xk)
+	return
+}
+
+// solveBordered solves the bordered system with the integral constraint (see SetIntegralConstraint)
+// and sets Eqs.Xu, Eqs.Xk and Eqs.Bk (if allocated) as Eqs.Solve does
+func (o *FdmLaplacian) solveBordered() {
+
+	// bordered matrix
+	nu := o.Eqs.Nu
+	w := gridVolumes(o.Grid)
+	rows, cols, vals := o.Eqs.Auu.ToMatrix(nil).Triplets()
+	a := la.NewTriplet(nu+1, nu+1, len(vals)+2*nu)
+	for k, v := range vals {
+		a.Put(rows[k], cols[k], v)
+	}
+	for i, I := range o.Eqs.UtoF {
+		a.Put(i, nu, w[I])
+		a.Put(nu, i, w[I])
+	}
+
+	// right-hand side
+	bu, xk := o.reducedRhs()
+	b := la.NewVector(nu + 1)
+	copy(b, bu)
+	b[nu] = o.integral
+	for i, I := range o.Eqs.KtoF {
+		b[nu] -= w[I] * xk[i]
+	}
+
+	// solve
+	tIni := time.Now()
+	solver := la.NewSparseSolver("umfpack")
+	defer solver.Free()
+	solver.Init(a, false, false, "", "", nil)
+	solver.Fact()
+	o.info = SolveInfo{Method: "umfpack", FactTime: time.Since(tIni)}
+	x := la.NewVector(nu + 1)
+	solver.Solve(x, b, false)
+	o.info.Residual = relResidual(a.ToMatrix(nil), x, b)
+	o.multiplier = x[nu]
+
+	// results
+	copy(o.Eqs.Xu, x[:nu])
+	copy(o.Eqs.Xk, xk)
+	if o.Eqs.Nk > 0 && o.Eqs.Aku != nil {
+		la.SpMatVecMul(o.Eqs.Bk, 1.0, o.Eqs.Aku.ToMatrix(nil), o.Eqs.Xu)
+		la.SpMatVecMulAdd(o.Eqs.Bk, 1.0, o.Eqs.Akk.ToMatrix(nil), o.Eqs.Xk)
+	}
+}
+
+// solvePenalty solves the full system with penalised essential conditions (see UsePenaltyBC) and
+// sets Eqs.Xu, Eqs.Xk and Eqs.Bk (if allocated) as Eqs.Solve does
+func (o *FdmLaplacian) solvePenalty() {
+
+	// full system
+	if o.Grid.Ndim() != 2 {
+		chk.Panic("the penalty method works in 2D only\n")
+	}
+	n := o.Grid.Size()
+	a := la.NewTriplet(n, n, o.molSize()*n)
+	b := la.NewVector(n)
+	for I := 0; I < n; I++ {
+		o.stencil2d(I, func(I, J int, value float64) { a.Put(I, J, value) })
+		b[I] = o.calcBu(I, 0)
+	}
+	for _, I := range o.Eqs.KtoF {
+		a.Put(I, I, -o.penalty)
+		b[I] -= o.penalty * o.calcXk(I, 0)
+	}
+
+	// solve
+	tIni := time.Now()
+	solver := la.NewSparseSolver("umfpack")
+	defer solver.Free()
+	solver.Init(a, false, false, "", "", nil)
+	solver.Fact()
+	o.info = SolveInfo{Method: "umfpack", FactTime: time.Since(tIni)}
+	x := la.NewVector(n)
+	solver.Solve(x, b, false)
+	o.info.Residual = relResidual(a.ToMatrix(nil), x, b)
+
+	// results
+	for i, I := range o.Eqs.UtoF {
+		o.Eqs.Xu[i] = x[I]
+	}
+	for i, I := range o.Eqs.KtoF {
+		o.Eqs.Xk[i] = x[I]
+	}
+	if o.Eqs.Nk > 0 && o.Eqs.Aku != nil {
+		la.SpMatVecMul(o.Eqs.Bk, 1.0, o.Eqs.Aku.ToMatrix(nil), o.Eqs.Xu)
+		la.SpMatVecMulAdd(o.Eqs.Bk, 1.0, o.Eqs.Akk.ToMatrix(nil), o.Eqs.Xk)
+	}
+}
+
+// relResidual returns the relative residual ‖b - a⋅x‖ / ‖b‖ (or ‖b - a⋅x‖ if b = 0)
+func relResidual(a *la.CCMatrix, x, b la.Vector) float64 {
+	r := b.GetCopy()
+	la.SpMatVecMulAdd(r, -1, a, x)
+	bnorm := b.Norm()
+	if bnorm == 0 {
+		bnorm = 1
+	}
+	return r.Norm() / bnorm
+}
+
+// spSymmetric returns whether a square sparse matrix is symmetric (to round-off)
+func spSymmetric(a *la.CCMatrix) bool {
+	rows, cols, vals := a.Triplets()
+	entries := make(map[[2]int]float64, len(vals))
+	amax := 0.0
+	for k, v := range vals {
+		entries[[2]int{rows[k], cols[k]}] = v
+		amax = math.Max(amax, math.Abs(v))
+	}
+	for key, v := range entries {
+		if math.Abs(v-entries[[2]int{key[1], key[0]}]) > 1e-12*amax {
+			return false
+		}
+	}
+	return true
+}
+
+// threePoint returns the factors of the three-point second-derivative stencils at node I (2D)
+//
+//    ∂²u       2     ⎛ u[I+1] - u[I]     u[I] - u[I-1] ⎞
+//    ——— ≈ ————————— ⎜ ————————————— - ————————————— ⎟
+//    ∂x²    h⁻ + h⁺  ⎝       h⁺               h⁻      ⎠
+//
+//   geo -- factors of the left, right, bottom and top nodes in geo[1:5]; geo[0] is not used.
+//          The spacings are given by Grid.Spacings; thus, the mirrored ghost nodes at borders
+//          are accounted for and geo = 1/h² on uniform grids
+func (o *FdmLaplacian) threePoint(I int) (geo [5]float64) {
+	for dim := 0; dim < 2; dim++ {
+		hm, hp := o.Grid.Spacings(dim, I)
+		h := o.Grid.Xlen(dim) / float64(o.Grid.Npts(dim)-1)
+		if math.Abs(hm-h) < 1e-10*h && math.Abs(hp-h) < 1e-10*h { // uniform: avoid round-off asymmetries
+			geo[1+2*dim], geo[2+2*dim] = 1/(h*h), 1/(h*h)
+			continue
+		}
+		geo[1+2*dim] = 2.0 / (hm * (hm + hp))
+		geo[2+2*dim] = 2.0 / (hp * (hm + hp))
+	}
+	return
+}
+
+// uniformGrid returns whether the spacings along each direction are constant (see threePoint)
+func uniformGrid(grid *gm.Grid) bool {
+	for dim := 0; dim < grid.Ndim(); dim++ {
+		X := grid.Coords(dim)
+		h := grid.Xlen(dim) / float64(len(X)-1)
+		for i := 1; i < len(X); i++ {
+			if math.Abs(X[i]-X[i-1]-h) >= 1e-10*h {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// redBlack sorts nodes with red nodes ((m+n+p) even) first, followed by black nodes, keeping the
+// relative order within each colour. With the 5-point (or 7-point) stencil, nodes of the same
+// colour are not coupled; thus, the diagonal blocks of Auu are diagonal
+func redBlack(grid *gm.Grid, nodes []int) (sorted []int) {
+	sorted = make([]int, 0, len(nodes))
+	for colour := 0; colour < 2; colour++ {
+		for _, I := range nodes {
+			m, n, p := grid.IndexItoMNP(I)
+			if (m+n+p)%2 == colour {
+				sorted = append(sorted, I)
+			}
+		}
+	}
+	return
+}
+
+// checkedPut returns put with a check of the values; i.e. err is set with the first non-finite value
+func (o *FdmLaplacian) checkedPut(err *error, put func(I, J int, value float64)) func(I, J int, value float64) {
+	return func(I, J int, value float64) {
+		if *err == nil && (math.IsNaN(value) || math.IsInf(value, 0)) {
+			*err = &NonFiniteError{I, J, value, o.nonFiniteCoefficient(I, J), o.Grid.Node(I)}
+		}
+		put(I, J, value)
+	}
+}
+
+// nonFiniteCoefficient finds the coefficient causing the non-finite entry (I,J) of the matrix; the
+// nodal coefficients of I, J and the neighbours of I are checked
+func (o *FdmLaplacian) nonFiniteCoefficient(I, J int) string {
+	bad := func(v float64) bool { return math.IsNaN(v) || math.IsInf(v, 0) }
+	nodes := []int{I, J}
+	m, n, _ := o.Grid.IndexItoMNP(I)
+	for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}, {-1, -1}, {1, -1}, {-1, 1}, {1, 1}} {
+		nodes = append(nodes, mirroredNode(o.Grid, m, n, d[0], d[1]))
+	}
+	for _, K := range nodes {
+		if v := o.reaction(K); bad(v) {
+			if o.Reaction != nil {
+				return io.Sf("reaction function kr = %g at node %d", v, K)
+			}
+			return io.Sf("reaction coefficient kr = %g", v)
+		}
+		if o.kField != nil && bad(o.kField[K]) {
+			return io.Sf("coefficient field k = %g at node %d", o.kField[K], K)
+		}
+		for c, name := range []string{"kxx", "kxy", "kyy"} {
+			if o.kTensor != nil && bad(o.kTensor[c][K]) {
+				return io.Sf("tensor field %s = %g at node %d", name, o.kTensor[c][K], K)
+			}
+		}
+	}
+	for c, v := range []float64{o.Kx, o.Ky, o.Kxy} {
+		if bad(v) {
+			return io.Sf("coefficient %s = %g", []string{"kx", "ky", "kxy"}[c], v)
+		}
+	}
+	if o.RobinBcs.Has(I) {
+		return "Robin condition"
+	}
+	return "grid spacing or harmonic mean of coefficients"
+}
+
+// reaction returns the reaction coefficient at node I
+func (o *FdmLaplacian) reaction(I int) float64 {
+	if o.Reaction != nil {
+		return o.Reaction(o.Grid.Node(I), 0)
+	}
+	return o.Kr
+}
+
+// source returns the source term at node I (function and sampled values)
+func (o *FdmLaplacian) source(I int, t float64) (res float64) {
+	if o.Source != nil {
+		res = o.Source(o.Grid.Node(I), t)
+	}
+	if o.srcVals != nil {
+		res += o.srcVals[I]
+	}
+	if o.pointSrc != nil {
+		res += o.pointSrc[I]
+	}
+	return
+}
+
+// calcXk calculates known {u} values (CalcXk in la.Equations)
+//  I -- node number
+//  t -- time
+func (o *FdmLaplacian) calcXk(I int, t float64) float64 {
+	_, val, available := o.EssenBcs.Value(I, 0, t)
+	if available {
+		return val
 	}
 	return 0
 }
+
+// calcBu calculates RHS vector (e.g. source) corresponding to known values of {u} (CalcBu in la.Equations)
+//  I -- node number
+//  t -- time
+func (o *FdmLaplacian) calcBu(I int, t float64) (res float64) {
+	res = o.source(I, t)
+	if o.Mehrstellen { // correction: [1 + Δx²/12 δx² + Δy²/12 δy²]{s} (mirrored at borders)
+		m, n, _ := o.Grid.IndexItoMNP(I)
+		res *= 8.0 / 12.0
+		res += o.source(mirroredNode(o.Grid, m, n, -1, 0), t) / 12.0
+		res += o.source(mirroredNode(o.Grid, m, n, +1, 0), t) / 12.0
+		res += o.source(mirroredNode(o.Grid, m, n, 0, -1), t) / 12.0
+		res += o.source(mirroredNode(o.Grid, m, n, 0, +1), t) / 12.0
+	}
+	if o.RobinBcs.Has(I) {
+		_, rhs := o.robin(I, t)
+		res += rhs
+	}
+	if o.jumps != nil && o.jumps[I] != nil {
+		jump := o.jumps[I]
+		res += o.Kx*jump.u[0] + o.Ky*jump.u[1] + o.Kz*jump.u[2] + jump.q
+	}
+	if o.NaturBcs.Has(I) {
+		_, qn, available := o.NaturBcs.Value(I, 0, t)
+		if available {
+			for _, tag := range o.NaturBcs.Tags(I) {
+				dim := tag/10 - 1 // normal direction
+				if o.Grid.Ndim() == 3 {
+					dim = tag/100 - 1
+				}
+				h, _ := o.Grid.Spacings(dim, I) // equal spacings at borders (mirrored)
+				res -= 2.0 * qn / h
+			}
+		}
+	}
+	return
+}
+
+// robin computes the contributions of Robin conditions at node I to the diagonal and to the RHS
+//  NOTE: a and b are evaluated at t = 0
+func (o *FdmLaplacian) robin(I int, t float64) (diag, rhs float64) {
+	x := o.Grid.Node(I)
+	o.RobinBcs.terms(I, func(dim int, item *robinItem) {
+		k := []float64{o.Kx, o.Ky, o.Kz}[dim]
+		h, _ := o.Grid.Spacings(dim, I)
+		a, b, c := item.a(x, 0), item.b(x, 0), item.c(x, t)
+		if b == 0 {
+			chk.Panic("coefficient b of Robin condition must not be zero (tag = %d, node = %d)\n", item.tag, I)
+		}
+		diag -= 2.0 * k * a / (b * h)
+		rhs -= 2.0 * k * c / (b * h)
+	})
+	return
+}
@@ -0,0 +1,494 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+)
+
+// FdmAdvDiff implements the Finite Difference (FDM) steady advection-diffusion operator (2D) with
+// constant velocity {v} = {vx, vy}
+//
+//              ∂²u        ∂²u        ∂u        ∂u
+//    L{u} = kx ———  +  ky ———  -  vx ——  -  vy ——      with   L{u} = s({x})
+//              ∂x²        ∂y²        ∂x        ∂y
+//
+//  The advective term is written in conservative form with the fluxes F = v⋅φ at the faces between
+//  nodes. The face values φ are given by the TVD (total variation diminishing) form
+//
+//    φ = u[U] + ½ ψ(r) (u[D] - u[U])     with     r = (u[U] - u[UU]) / (u[D] - u[U])
+//
+//  where D is the downstream node, U is the upstream node and UU is the node upstream of U. The
+//  flux limiter ψ is selected by Limiter:
+//
+//    "upwind"   -- ψ = 0 (first order; excessive numerical diffusion)
+//    "central"  -- ψ = 1 (second order; oscillations if the cell Péclet number v⋅h/k > 2)
+//    "minmod"   -- ψ = max(0, min(1, r))
+//    "vanleer"  -- ψ = (r + |r|) / (1 + |r|)
+//    "superbee" -- ψ = max(0, min(2r, 1), min(r, 2))
+//
+//  With the limiters, the operator is nonlinear and the residual {r} and the Jacobian must be
+//  evaluated at each Newton iteration (see Residual, Jacobian and Solve). Since the limiters are not
+//  smooth, Newton's method may cycle (e.g. with "superbee"); then, the line search should be used
+//  (see "linSearch" in num.NlSolver). The diffusive term uses the 5-point stencil with mirrored
+//  nodes at the borders (zero diffusive flux).
+//
+//  Alternatively, the advective term may be discretised by the compact fourth-order (Padé) scheme
+//  with Limiter = "compact". Along each grid line, the derivatives u' are the solution of the
+//  tridiagonal system
+//
+//    ¼ u'[i-1] + u'[i] + ¼ u'[i+1] = ¾ (u[i+1] - u[i-1]) / h                        (interior)
+//    u'[0] + 3 u'[1] = (-17/6 u[0] + 3/2 u[1] + 3/2 u[2] - 1/6 u[3]) / h               (closure)
+//
+//  where the closure at the last node is the mirrored one (with opposite signs). The closure is also
+//  fourth-order accurate; thus, the accuracy is uniform up to the boundaries (for smooth solutions).
+//  The derivatives are obtained from the matrix D = A⁻¹⋅B computed once; i.e. each equation depends
+//  on all nodes of the two grid lines crossing the node (denser coupling). The operator is then
+//  linear but not dissipative; thus, it is not suitable for profiles with steep gradients. At
+//  least 4 nodes along each direction with non-zero velocity are required.
+//
+//  The streamline-upwind Petrov-Galerkin (SUPG) stabilisation is selected with Limiter = "supg".
+//  The advective term is discretised by central differences and the equation is weighted by
+//  1 - τ {v}⋅∇; i.e. the artificial diffusion τ {v}⊗{v} along the streamlines is added and the
+//  source term is corrected consistently
+//
+//    L{u} + τ {v}⋅∇({v}⋅∇u) = s - τ {v}⋅∇s
+//
+//  with
+//
+//        ξx |vx| hx + ξy |vy| hy                              1             |v_i| h_i
+//    τ = ———————————————————————     with    ξ_i = coth Pe_i - ————    and    Pe_i = —————————
+//              2 (vx² + vy²)                                  Pe_i             2 k_i
+//
+//  where Pe_i is the cell Péclet number along each direction (ξ_i = 1 if k_i = 0). The cross term
+//  2 τ vx vy ∂²u/∂x∂y uses the 4-point stencil with mirrored nodes at the borders and ∇s is
+//  computed by central differences of the source function with the grid spacings. The operator is
+//  linear. In 1D (or flows aligned with the grid), the scheme is the optimal (Il'in-Allen-
+//  Southwell) scheme; i.e. the nodal values are exact for constant coefficients without source.
+//  In contrast to upwinding, no crosswind diffusion is added; thus, boundary layers are sharp, but
+//  small over- and undershoots may remain near layers that are not aligned with the streamlines.
+//
+//  NOTE: (1) only essential boundary conditions are supported; e.g. at the inflow boundaries.
+//            At the boundaries without prescribed values, the face values outside the domain are
+//            equal to the values at the boundary nodes (zero gradient; e.g. outflow boundaries)
+//        (2) the limiter is replaced by upwinding (ψ = 0) if UU is outside the domain (except
+//            with "supg")
+//        (3) the grid must be uniform along each direction since all stencils use the constant
+//            spacings h = Xlen/(npts-1); stretched grids (e.g. from RectSet2d) are rejected
+type FdmAdvDiff struct {
+	Kx       float64        // diffusion coefficient x
+	Ky       float64        // diffusion coefficient y
+	Vx       float64        // velocity x
+	Vy       float64        // velocity y
+	Limiter  string         // flux limiter: "upwind", "central", "minmod", "vanleer", "superbee"; or "compact" or "supg"
+	Grid     *gm.Grid       // grid
+	Source   fun.Svs        // source term function s({x},t) [may be nil]
+	EssenBcs *BoundaryConds // essential boundary conditions
+	Eqs      *la.Equations  // equations (numbering only; the matrices are not allocated)
+	u        []float64      // [nnodes] all values (workspace)
+	pade     [2]*la.Matrix  // [2][npts][npts] compact derivative matrices D = A⁻¹⋅B (see "compact") [may be nil]
+	supg     [3]float64     // artificial diffusion τ⋅{vx², vy², vx⋅vy} (see "supg")
+	tau      float64        // stabilisation parameter τ (see "supg")
+}
+
+// NewFdmAdvDiff creates a new FDM advection-diffusion operator with given parameters
+//   params  -- "kx", "ky", "vx" and "vy" [optional; default = 0]
+//   limiter -- flux limiter; see FdmAdvDiff
+//   source  -- source term function [optional]
+func NewFdmAdvDiff(params dbf.Params, grid *gm.Grid, limiter string, source fun.Svs) (o *FdmAdvDiff) {
+	o = new(FdmAdvDiff)
+	err := params.ConnectSetOpt(
+		[]*float64{&o.Kx, &o.Ky, &o.Vx, &o.Vy},
+		[]string{"kx", "ky", "vx", "vy"},
+		[]bool{true, true, true, true},
+		"FdmAdvDiff",
+	)
+	if err != "" {
+		chk.Panic(err)
+	}
+	if grid.Ndim() != 2 {
+		chk.Panic("FdmAdvDiff works in 2D only\n")
+	}
+	if !uniformGrid(grid) {
+		chk.Panic("FdmAdvDiff requires uniform grids\n")
+	}
+	if limiter != "compact" && limiter != "supg" {
+		limiterFunc(limiter) // check
+	}
+	o.Limiter = limiter
+	o.Grid = grid
+	o.Source = source
+	o.EssenBcs = NewBoundaryCondsGrid(grid, 1) // 1:maxNdof
+	return
+}
+
+// AddEbc adds essential boundary condition given tag of edge
+//   tag    -- edge tag in grid
+//   cvalue -- constant value [optional]; or
+//   fvalue -- function value [optional]
+func (o *FdmAdvDiff) AddEbc(tag int, cvalue float64, fvalue fun.Svs) {
+	o.Eqs = nil
+	o.EssenBcs.AddUsingTag(tag, 0, cvalue, fvalue)
+}
+
+// Residual computes the residual {r} = L{u} - {s} at nodes without prescribed values
+//   r  -- [Nu] residual
+//   uu -- [Nu] values at nodes without prescribed values
+//   NOTE: Residual and Jacobian can be given to num.NlSolver (see Solve)
+func (o *FdmAdvDiff) Residual(r, uu la.Vector) {
+	o.setValues(uu)
+	for i, I := range o.Eqs.UtoF {
+		r[i] = o.equation(I, nil)
+		if o.Source != nil {
+			r[i] -= o.source(I)
+		}
+	}
+}
+
+// Jacobian computes the Jacobian matrix [J] = ∂{r}/∂{uu}
+//   J  -- [Nu][Nu] Jacobian; the triplet is initialised if empty
+//   uu -- [Nu] values at nodes without prescribed values
+//   NOTE: the limiters are not differentiable at a few points (e.g. r = 1 for minmod); the
+//         one-sided derivatives are used there
+func (o *FdmAdvDiff) Jacobian(J *la.Triplet, uu la.Vector) {
+	o.setValues(uu)
+	if J.Max() == 0 {
+		nnz := 2 * 9 * o.Eqs.Nu // 3 diffusion entries and 2 faces with 3 nodes along each direction
+		if o.Limiter == "compact" {
+			nnz = (o.Grid.Npts(0) + o.Grid.Npts(1) + 6) * o.Eqs.Nu // all nodes on grid lines and diffusion
+		}
+		if o.Limiter == "supg" {
+			nnz += 4 * o.Eqs.Nu // cross derivative
+		}
+		J.Init(o.Eqs.Nu, o.Eqs.Nu, nnz)
+	}
+	J.Start()
+	for i, I := range o.Eqs.UtoF {
+		o.equation(I, func(K int, drdu float64) {
+			if k := o.Eqs.FtoU[K]; k >= 0 {
+				J.Put(i, k, drdu)
+			}
+		})
+	}
+}
+
+// Solve solves the nonlinear problem with Newton's method (see num.NlSolver)
+//   Input:
+//     u0     -- [nnodes] initial values [may be nil ⇒ solution with the "upwind" scheme]; the
+//               values at nodes with prescribed values are ignored
+//     prms   -- parameters of num.NlSolver; e.g. "atol", "rtol", "maxIt" [may be nil]
+//     silent -- do not show messages
+//   Output:
+//     u   -- [nnodes] solution at all nodes
+//     nit -- number of iterations
+func (o *FdmAdvDiff) Solve(u0 []float64, prms map[string]float64, silent bool) (u []float64, nit int) {
+	o.init()
+	uu := la.NewVector(o.Eqs.Nu)
+	if u0 == nil && o.Limiter != "upwind" && o.Limiter != "central" && o.Limiter != "compact" && o.Limiter != "supg" { // linear schemes converge in one iteration
+		limiter := o.Limiter
+		o.Limiter = "upwind"
+		u0, _ = o.Solve(nil, prms, true)
+		o.Limiter = limiter
+	}
+	if u0 != nil {
+		if len(u0) != o.Grid.Size() {
+			chk.Panic("size of u0 must be equal to the number of nodes. %d != %d\n", len(u0), o.Grid.Size())
+		}
+		for i, I := range o.Eqs.UtoF {
+			uu[i] = u0[I]
+		}
+	}
+	var solver num.NlSolver
+	solver.Init(o.Eqs.Nu, o.Residual, o.Jacobian, nil, false, false, prms)
+	defer solver.Free()
+	solver.Solve(uu, silent)
+	o.setValues(uu)
+	u = make([]float64, o.Grid.Size())
+	copy(u, o.u)
+	return u, solver.It
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// init creates the structure of equations
+func (o *FdmAdvDiff) init() {
+	if o.Eqs != nil {
+		return
+	}
+	o.Eqs = la.NewEquations(o.Grid.Size(), o.EssenBcs.Nodes())
+	o.u = make([]float64, o.Grid.Size())
+	for _, I := range o.Eqs.KtoF {
+		_, val, _ := o.EssenBcs.Value(I, 0, 0)
+		o.u[I] = val
+	}
+	if o.Limiter == "compact" {
+		for dim, v := range []float64{o.Vx, o.Vy} {
+			if v != 0 {
+				npts := o.Grid.Npts(dim)
+				o.pade[dim] = padeMatrix(npts, o.Grid.Xlen(dim)/float64(npts-1))
+			}
+		}
+	}
+	o.supg = [3]float64{}
+	o.tau = 0
+	if o.Limiter == "supg" && (o.Vx != 0 || o.Vy != 0) {
+		sum := 0.0
+		for dim, v := range []float64{o.Vx, o.Vy} {
+			if v == 0 {
+				continue
+			}
+			h := o.Grid.Xlen(dim) / float64(o.Grid.Npts(dim)-1)
+			ξ := 1.0
+			if k := []float64{o.Kx, o.Ky}[dim]; k > 0 {
+				pe := math.Abs(v) * h / (2 * k)
+				ξ = 1/math.Tanh(pe) - 1/pe
+			}
+			sum += ξ * math.Abs(v) * h
+		}
+		τ := sum / (2 * (o.Vx*o.Vx + o.Vy*o.Vy))
+		o.supg = [3]float64{τ * o.Vx * o.Vx, τ * o.Vy * o.Vy, τ * o.Vx * o.Vy}
+		o.tau = τ
+	}
+}
+
+// source computes the source term at node I; with "supg", the Petrov-Galerkin correction is
+// included; i.e. s - τ {v}⋅∇s
+func (o *FdmAdvDiff) source(I int) (res float64) {
+	x := o.Grid.Node(I)
+	res = o.Source(x, 0)
+	if o.tau == 0 {
+		return
+	}
+	xx := la.NewVector(2)
+	for dim, v := range []float64{o.Vx, o.Vy} {
+		if v == 0 {
+			continue
+		}
+		h := o.Grid.Xlen(dim) / float64(o.Grid.Npts(dim)-1)
+		copy(xx, x)
+		xx[dim] = x[dim] + h
+		sR := o.Source(xx, 0)
+		xx[dim] = x[dim] - h
+		sL := o.Source(xx, 0)
+		res -= o.tau * v * (sR - sL) / (2 * h)
+	}
+	return
+}
+
+// setValues sets the workspace with all values
+func (o *FdmAdvDiff) setValues(uu la.Vector) {
+	o.init()
+	if len(uu) != o.Eqs.Nu {
+		chk.Panic("size of uu must be equal to the number of unknowns. %d != %d\n", len(uu), o.Eqs.Nu)
+	}
+	for i, I := range o.Eqs.UtoF {
+		o.u[I] = uu[i]
+	}
+}
+
+// equation computes L{u} at node I
+//   deriv -- if not nil, is called with the derivatives of L{u} with respect to the node values
+func (o *FdmAdvDiff) equation(I int, deriv func(K int, drdu float64)) (res float64) {
+	m, n, _ := o.Grid.IndexItoMNP(I)
+	idx := []int{m, n}
+	for dim := 0; dim < 2; dim++ {
+		npts := o.Grid.Npts(dim)
+		h := o.Grid.Xlen(dim) / float64(npts-1)
+		k := []float64{o.Kx, o.Ky}[dim] + o.supg[dim]
+		v := []float64{o.Vx, o.Vy}[dim]
+		node := func(i int) int { // node at position i along dim; -1 if outside
+			if i < 0 || i >= npts {
+				return -1
+			}
+			jdx := []int{idx[0], idx[1]}
+			jdx[dim] = i
+			return o.Grid.IndexMNPtoI(jdx[0], jdx[1], 0)
+		}
+		i := idx[dim]
+		L, R := node(i-1), node(i+1)
+		if L < 0 {
+			L = R
+		}
+		if R < 0 {
+			R = L
+		}
+
+		// diffusion
+		c := k / (h * h)
+		res += c * (o.u[L] - 2*o.u[I] + o.u[R])
+		if deriv != nil {
+			deriv(L, c)
+			deriv(I, -2*c)
+			deriv(R, c)
+		}
+
+		// advection: -(F[i+½] - F[i-½])/h
+		if v == 0 {
+			continue
+		}
+		if o.Limiter == "compact" {
+			for j := 0; j < npts; j++ {
+				K, c := node(j), -v*o.pade[dim].Get(i, j)
+				res += c * o.u[K]
+				if deriv != nil {
+					deriv(K, c)
+				}
+			}
+			continue
+		}
+		for _, face := range [][2]int{{i, +1}, {i - 1, -1}} { // left node of face and sign
+			a := v / h * float64(-face[1])
+			s := 1 // direction of flow along the grid
+			if v < 0 {
+				s = -1
+			}
+			aa := face[0] // left node of face is U if v > 0
+			if s < 0 {
+				aa = face[0] + 1
+			}
+			U, D, UU := node(aa), node(aa+s), node(aa-s)
+			if U < 0 || D < 0 { // face outside the domain: φ = u[I]
+				res += a * o.u[I]
+				if deriv != nil {
+					deriv(I, a)
+				}
+				continue
+			}
+			φ, dφ := o.faceValue(U, D, UU)
+			res += a * φ
+			if deriv != nil {
+				deriv(U, a*dφ[0])
+				deriv(D, a*dφ[1])
+				if UU >= 0 {
+					deriv(UU, a*dφ[2])
+				}
+			}
+		}
+	}
+
+	// streamline diffusion: cross derivative 2⋅τ⋅vx⋅vy ∂²u/∂x∂y
+	if o.supg[2] != 0 {
+		hx := o.Grid.Xlen(0) / float64(o.Grid.Npts(0)-1)
+		hy := o.Grid.Xlen(1) / float64(o.Grid.Npts(1)-1)
+		c := 2 * o.supg[2] / (4 * hx * hy)
+		for _, d := range [][3]int{{1, 1, 1}, {1, -1, -1}, {-1, 1, -1}, {-1, -1, 1}} { // dm, dn and sign
+			K := mirroredNode(o.Grid, m, n, d[0], d[1])
+			res += float64(d[2]) * c * o.u[K]
+			if deriv != nil {
+				deriv(K, float64(d[2])*c)
+			}
+		}
+	}
+	return
+}
+
+// faceValue computes the TVD face value and its derivatives with respect to u[U], u[D] and u[UU]
+//   UU -- may be -1 ⇒ upwind (central differences with "supg")
+func (o *FdmAdvDiff) faceValue(U, D, UU int) (φ float64, dφ [3]float64) {
+	Δ := o.u[D] - o.u[U]
+	if o.Limiter == "supg" { // central differences everywhere (UU is not needed)
+		return o.u[U] + Δ/2, [3]float64{0.5, 0.5, 0}
+	}
+	if UU < 0 || (Δ == 0 && o.Limiter != "central") {
+		return o.u[U], [3]float64{1, 0, 0}
+	}
+	if o.Limiter == "central" {
+		return o.u[U] + Δ/2, [3]float64{0.5, 0.5, 0}
+	}
+	ψfcn := limiterFunc(o.Limiter)
+	r := (o.u[U] - o.u[UU]) / Δ
+	ψ, dψ := ψfcn(r)
+	φ = o.u[U] + ψ*Δ/2
+	dφ[0] = 1 - ψ/2 + dψ*(1+r)/2 // ∂r/∂u[U] = (1 + r)/Δ
+	dφ[1] = ψ/2 - dψ*r/2         // ∂r/∂u[D] = -r/Δ
+	dφ[2] = -dψ / 2              // ∂r/∂u[UU] = -1/Δ
+	return
+}
+
+// limiterFunc returns the flux limiter ψ(r) and its derivative
+func limiterFunc(name string) func(r float64) (ψ, dψ float64) {
+	switch name {
+	case "upwind":
+		return func(r float64) (float64, float64) { return 0, 0 }
+	case "central":
+		return func(r float64) (float64, float64) { return 1, 0 }
+	case "minmod":
+		return func(r float64) (float64, float64) {
+			switch {
+			case r <= 0:
+				return 0, 0
+			case r < 1:
+				return r, 1
+			}
+			return 1, 0
+		}
+	case "vanleer":
+		return func(r float64) (float64, float64) {
+			if r <= 0 {
+				return 0, 0
+			}
+			return 2 * r / (1 + r), 2 / ((1 + r) * (1 + r))
+		}
+	case "superbee":
+		return func(r float64) (float64, float64) {
+			switch {
+			case r <= 0:
+				return 0, 0
+			case r < 0.5:
+				return 2 * r, 2
+			case r < 1:
+				return 1, 0
+			case r < 2:
+				return r, 1
+			}
+			return 2, 0
+		}
+	}
+	chk.Panic("flux limiter %q is invalid. options: \"upwind\", \"central\", \"minmod\", \"vanleer\" or \"superbee\" (or the \"compact\" and \"supg\" schemes)\n", name)
+	return nil
+}
+
+// padeMatrix computes the matrix D = A⁻¹⋅B of the compact fourth-order first derivative u' = D⋅u
+// (see "compact" in FdmAdvDiff); the columns are obtained with the Thomas algorithm
+func padeMatrix(n int, h float64) (D *la.Matrix) {
+	if n < 4 {
+		chk.Panic("the compact scheme requires at least 4 nodes along each direction. %d is invalid\n", n)
+	}
+	B := la.NewMatrix(n, n)
+	for i := 1; i < n-1; i++ {
+		B.Set(i, i-1, -0.75/h)
+		B.Set(i, i+1, +0.75/h)
+	}
+	for j, c := range []float64{-17.0 / 6.0, 1.5, 1.5, -1.0 / 6.0} {
+		B.Set(0, j, c/h)
+		B.Set(n-1, n-1-j, -c/h)
+	}
+	lo, di, up := make([]float64, n), make([]float64, n), make([]float64, n)
+	for i := 0; i < n; i++ {
+		lo[i], di[i], up[i] = 0.25, 1, 0.25
+	}
+	up[0], lo[n-1] = 3, 3
+	D = la.NewMatrix(n, n)
+	c, d := make([]float64, n), make([]float64, n)
+	for j := 0; j < n; j++ {
+		c[0], d[0] = up[0]/di[0], B.Get(0, j)/di[0]
+		for i := 1; i < n; i++ {
+			den := di[i] - lo[i]*c[i-1]
+			c[i], d[i] = up[i]/den, (B.Get(i, j)-lo[i]*d[i-1])/den
+		}
+		D.Set(n-1, j, d[n-1])
+		for i := n - 2; i >= 0; i-- {
+			D.Set(i, j, d[i]-c[i]*D.Get(i+1, j))
+		}
+	}
+	return
+}
@@ -0,0 +1,191 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/la"
+)
+
+// FdmCylindrical implements the Finite Difference (FDM) axisymmetric Laplacian operator
+// ("laplacian-cylindrical") on an (r,z) grid; i.e. x ≡ r ≥ 0 and y ≡ z
+//
+//                 1  ∂  (   ∂u )        ∂²u
+//    L{u} = kr ⋅ —— —— ( r —— )  + kz ⋅ ————      with   L{u} = s(r,z)
+//                 r  ∂r (   ∂r )        ∂z²
+//
+//  The operator is discretised in conservative (finite volume) form on the rings around nodes,
+//  with faces at the mid-points between nodes and at the boundaries of the grid:
+//
+//            kr  [          u[i+1] - u[i]            u[i] - u[i-1] ]
+//    L_r = ———— [ r[i+½] ⋅ ————————————— - r[i-½] ⋅ ————————————— ]
+//          V[i]  [          r[i+1] - r[i]            r[i] - r[i-1] ]
+//
+//    V[i] = (r²[i+½] - r²[i-½]) / 2
+//
+//  On the axis (r = 0), the face r[-½] = 0 and V[0] = r²[½]/2 give the limiting stencil of
+//  (2 ∂²u/∂r²); i.e. 4⋅(u[1] - u[0])/h² with the symmetry condition ∂u/∂r = 0. The axial part
+//  L_z is computed likewise with unit weights of faces and V[n] = z[n+½] - z[n-½]. Stretched grids
+//  (RectSet2d) are supported
+//
+//  NOTE: (1) boundary nodes without essential conditions have zero flux (insulated boundary or
+//            axis of symmetry); the solution of quadratic fields in r and z is exact
+//        (2) the matrix is not symmetric; the symmetric form is obtained by multiplying the rows
+//            by the volumes V[i]
+type FdmCylindrical struct {
+	Kr       float64        // radial coefficient
+	Kz       float64        // axial coefficient
+	Grid     *gm.Grid       // grid: x ≡ r and y ≡ z
+	Source   fun.Svs        // source term function s({x},t) [may be nil]
+	EssenBcs *BoundaryConds // essential boundary conditions
+	Eqs      *la.Equations  // equations (numbering only; see Auu)
+	Auu      *la.Triplet    // [Nu][Nu] assembled matrix
+	Bu       la.Vector      // [Nu] right-hand side including the prescribed values
+}
+
+// NewFdmCylindrical creates a new FDM axisymmetric Laplacian operator with given parameters
+//   params -- "kr" (radial) and "kz" (axial) coefficients
+//   grid   -- 2D grid with rmin = xmin ≥ 0 (unrotated)
+//   source -- source term function [optional]
+func NewFdmCylindrical(params dbf.Params, grid *gm.Grid, source fun.Svs) (o *FdmCylindrical) {
+	o = new(FdmCylindrical)
+	err := params.ConnectSet(
+		[]*float64{&o.Kr, &o.Kz},
+		[]string{"kr", "kz"},
+		"FdmCylindrical",
+	)
+	if err != "" {
+		chk.Panic(err)
+	}
+	if grid.Ndim() != 2 || grid.Rotation() != 0 {
+		chk.Panic("FdmCylindrical works with unrotated 2D grids only\n")
+	}
+	if grid.Xmin(0) < 0 {
+		chk.Panic("radial coordinates must be non-negative. rmin = %g is invalid\n", grid.Xmin(0))
+	}
+	o.Grid = grid
+	o.Source = source
+	o.EssenBcs = NewBoundaryCondsGrid(grid, 1) // 1:maxNdof
+	return
+}
+
+// AddEbc adds essential boundary condition given tag of edge
+//   tag    -- edge tag in grid: 10 (rmin), 11 (rmax), 20 (zmin) or 21 (zmax)
+//   cvalue -- constant value [optional]; or
+//   fvalue -- function value [optional]
+func (o *FdmCylindrical) AddEbc(tag int, cvalue float64, fvalue fun.Svs) {
+	o.Eqs = nil
+	o.EssenBcs.AddUsingTag(tag, 0, cvalue, fvalue)
+}
+
+// Assemble assembles the system [Auu]⋅{uu} = {bu}
+func (o *FdmCylindrical) Assemble() {
+	R, Z := o.Grid.Coords(0), o.Grid.Coords(1)
+	rf, zf := fvFaces(R), fvFaces(Z)
+	vr := func(m int) float64 { return (rf[m+1]*rf[m+1] - rf[m]*rf[m]) / 2.0 } // ∫ r dr
+	vol := func(m, n int) float64 { return vr(m) * (zf[n+1] - zf[n]) }
+	area := func(dim, m, n, f int) float64 {
+		if dim == 0 {
+			return rf[f] * (zf[n+1] - zf[n])
+		}
+		return vr(m)
+	}
+	o.Eqs, o.Auu, o.Bu = fvAssemble(o.Grid, o.EssenBcs, o.Source, []float64{o.Kr, o.Kz}, vol, area)
+}
+
+// SolveSteady solves the system (assembling it if needed)
+//   u -- [nnodes] solution at all nodes
+func (o *FdmCylindrical) SolveSteady() (u []float64) {
+	if o.Eqs == nil {
+		o.Assemble()
+	}
+	return fvSolve(o.Grid, o.EssenBcs, o.Eqs, o.Auu, o.Bu)
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// fvFaces returns the coordinates of the faces of the control volumes around the nodes along one
+// direction; i.e. the mid-points between nodes and the first and last coordinates
+//   X  -- [npts] coordinates of nodes
+//   xf -- [npts+1] coordinates of faces; the control volume of node i is [xf[i], xf[i+1]]
+func fvFaces(X []float64) (xf []float64) {
+	n := len(X)
+	xf = make([]float64, n+1)
+	xf[0], xf[n] = X[0], X[n-1]
+	for i := 1; i < n; i++ {
+		xf[i] = (X[i-1] + X[i]) / 2.0
+	}
+	return
+}
+
+// fvAssemble assembles the (finite volume) system of a diffusion operator on a rectangular 2D grid
+// of curvilinear coordinates (e.g. cylindrical or spherical) with zero flux at boundaries without
+// essential conditions
+//
+//                1     [          u[J] - u[I]   ]
+//   L{u}[I] = ———— Σ  [ k ⋅ A ⋅ ——————————— ]     (sum over the neighbours J along each direction)
+//              V[I]  [              h       ]
+//
+//   k    -- [2] coefficients along each direction
+//   vol  -- volume V of the control volume of node (m,n)
+//   area -- area A of face f (along dim) of the control volume of node (m,n)
+func fvAssemble(grid *gm.Grid, ebcs *BoundaryConds, source fun.Svs, k []float64, vol func(m, n int) float64, area func(dim, m, n, f int) float64) (eqs *la.Equations, auu *la.Triplet, bu la.Vector) {
+	X := [][]float64{grid.Coords(0), grid.Coords(1)}
+	eqs = la.NewEquations(grid.Size(), ebcs.Nodes())
+	auu = la.NewTriplet(eqs.Nu, eqs.Nu, 5*eqs.Nu)
+	bu = la.NewVector(eqs.Nu)
+	for i, I := range eqs.UtoF {
+		if source != nil {
+			bu[i] = source(grid.Node(I), 0)
+		}
+		put := func(J int, value float64) {
+			if j := eqs.FtoU[J]; j >= 0 {
+				auu.Put(i, j, value)
+				return
+			}
+			_, val, _ := ebcs.Value(J, 0, 0)
+			bu[i] -= value * val
+		}
+		m, n, _ := grid.IndexItoMNP(I)
+		idx := []int{m, n}
+		v := vol(m, n)
+		diag := 0.0
+		for dim := 0; dim < 2; dim++ {
+			a := idx[dim]
+			for _, side := range []int{-1, 1} {
+				b := a + side
+				if b < 0 || b >= len(X[dim]) {
+					continue // zero flux
+				}
+				h := (X[dim][b] - X[dim][a]) * float64(side)
+				c := k[dim] * area(dim, m, n, a+(side+1)/2) / (h * v)
+				jdx := []int{m, n}
+				jdx[dim] = b
+				put(grid.IndexMNPtoI(jdx[0], jdx[1], 0), c)
+				diag -= c
+			}
+		}
+		auu.Put(i, i, diag)
+	}
+	return
+}
+
+// fvSolve solves the system assembled by fvAssemble
+//   u -- [nnodes] solution at all nodes
+func fvSolve(grid *gm.Grid, ebcs *BoundaryConds, eqs *la.Equations, auu *la.Triplet, bu la.Vector) (u []float64) {
+	uu := la.SpSolve(auu, bu)
+	u = make([]float64, grid.Size())
+	for i, I := range eqs.UtoF {
+		u[I] = uu[i]
+	}
+	for _, I := range eqs.KtoF {
+		_, val, _ := ebcs.Value(I, 0, 0)
+		u[I] = val
+	}
+	return
+}
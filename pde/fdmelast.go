@@ -0,0 +1,193 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/la"
+)
+
+// FdmElasticity implements the Finite Difference (FDM) linear elasticity operator (2D) for
+// isotropic materials; i.e. the Navier-Cauchy equations written in terms of displacements
+//
+//                      ∂²ux        ∂²ux            ∂²uy
+//    L{u}_x = (λ+2μ) ⋅ ———— + μ ⋅ ———— + (λ+μ) ⋅ ————
+//                      ∂x²         ∂y²             ∂x∂y
+//
+//                  ∂²uy            ∂²uy            ∂²ux
+//    L{u}_y = μ ⋅ ———— + (λ+2μ) ⋅ ———— + (λ+μ) ⋅ ————
+//                  ∂x²             ∂y²             ∂x∂y
+//
+//  where λ and μ are the Lamé coefficients computed from Young's modulus E and Poisson's ratio ν.
+//  The coupling between ux and uy comes from the cross derivatives (off-diagonal blocks).
+//
+//  NOTE: (1) the system is solved as L{u} = {s} where {s} is the source term; e.g. s = -body force
+//        (2) equations are numbered as I = 2⋅node + dof, with dof = 0 ⇒ ux and dof = 1 ⇒ uy
+//        (3) as in FdmLaplacian, boundary nodes without essential conditions use mirrored
+//            neighbours; thus, traction-free boundaries are not represented exactly
+//
+type FdmElasticity struct {
+	E           float64        // Young's modulus
+	Nu          float64        // Poisson's ratio
+	PlaneStress bool           // plane-stress instead of plane-strain
+	Grid        *gm.Grid       // grid
+	Source      []fun.Svs      // [ndof] source term functions s_i({x},t) [may be nil]
+	EssenBcs    *BoundaryConds // essential boundary conditions
+	Eqs         *la.Equations  // equations
+	bcsReady    bool           // boundary conditions are set
+}
+
+// NewFdmElasticity creates a new FDM linear elasticity operator with given parameters
+//   params      -- "E" and "nu"
+//   planeStress -- plane-stress (true) or plane-strain (false)
+//   source      -- [ndof] source term functions [optional]
+func NewFdmElasticity(params dbf.Params, grid *gm.Grid, planeStress bool, source []fun.Svs) (o *FdmElasticity) {
+	o = new(FdmElasticity)
+	err := params.ConnectSet(
+		[]*float64{&o.E, &o.Nu},
+		[]string{"E", "nu"},
+		"FdmElasticity",
+	)
+	if err != "" {
+		chk.Panic(err)
+	}
+	if grid.Ndim() != 2 {
+		chk.Panic("FdmElasticity works in 2D only\n")
+	}
+	o.PlaneStress = planeStress
+	o.Grid = grid
+	o.Source = source
+	o.EssenBcs = NewBoundaryCondsGrid(grid, 2) // 2:maxNdof
+	o.bcsReady = false
+	return
+}
+
+// Lame returns the Lamé coefficients λ and μ (λ is modified in plane-stress)
+func (o *FdmElasticity) Lame() (λ, μ float64) {
+	μ = o.E / (2.0 * (1.0 + o.Nu))
+	if o.PlaneStress {
+		λ = o.E * o.Nu / (1.0 - o.Nu*o.Nu)
+		return
+	}
+	λ = o.E * o.Nu / ((1.0 + o.Nu) * (1.0 - 2.0*o.Nu))
+	return
+}
+
+// AddEbc adds essential boundary condition given tag of edge
+//   tag    -- edge tag in grid
+//   dof    -- 0 ⇒ ux, 1 ⇒ uy
+//   cvalue -- constant value [optional]; or
+//   fvalue -- function value [optional]
+func (o *FdmElasticity) AddEbc(tag, dof int, cvalue float64, fvalue fun.Svs) {
+	o.bcsReady = false
+	o.EssenBcs.AddUsingTag(tag, dof, cvalue, fvalue)
+}
+
+// Assemble assembles operator into A matrix from [A] ⋅ {u} = {b}
+//  reactions -- prepare for computation of RHS
+func (o *FdmElasticity) Assemble(reactions bool) {
+	if !o.bcsReady {
+		o.Eqs = la.NewEquations(2*o.Grid.Size(), o.knownEqs())
+		o.Eqs.Alloc([]int{9 * o.Eqs.Nu, 9 * o.Eqs.Nu, 9 * o.Eqs.Nk, 9 * o.Eqs.Nk}, reactions, true)
+		o.bcsReady = true
+	}
+	o.Eqs.Start()
+	nx := o.Grid.Npts(0)
+	ny := o.Grid.Npts(1)
+	dx := o.Grid.Xlen(0) / float64(nx-1)
+	dy := o.Grid.Xlen(1) / float64(ny-1)
+	λ, μ := o.Lame()
+	cxx := [2]float64{(λ + 2.0*μ) / (dx * dx), μ / (dx * dx)} // [dof] coefficients of ∂²/∂x²
+	cyy := [2]float64{μ / (dy * dy), (λ + 2.0*μ) / (dy * dy)} // [dof] coefficients of ∂²/∂y²
+	cxy := (λ + μ) / (4.0 * dx * dy)                          // coefficient of ∂²/∂x∂y
+	for n := 0; n < ny; n++ {
+		for m := 0; m < nx; m++ {
+			I := o.Grid.IndexMNPtoI(m, n, 0)
+			for dof := 0; dof < 2; dof++ {
+				other := 1 - dof
+				row := 2*I + dof
+				o.Eqs.Put(row, 2*I+dof, -2.0*(cxx[dof]+cyy[dof]))
+				o.Eqs.Put(row, 2*o.neighbour(m, n, -1, 0)+dof, cxx[dof])
+				o.Eqs.Put(row, 2*o.neighbour(m, n, +1, 0)+dof, cxx[dof])
+				o.Eqs.Put(row, 2*o.neighbour(m, n, 0, -1)+dof, cyy[dof])
+				o.Eqs.Put(row, 2*o.neighbour(m, n, 0, +1)+dof, cyy[dof])
+				o.Eqs.Put(row, 2*o.neighbour(m, n, +1, +1)+other, +cxy)
+				o.Eqs.Put(row, 2*o.neighbour(m, n, +1, -1)+other, -cxy)
+				o.Eqs.Put(row, 2*o.neighbour(m, n, -1, +1)+other, -cxy)
+				o.Eqs.Put(row, 2*o.neighbour(m, n, -1, -1)+other, +cxy)
+			}
+		}
+	}
+}
+
+// SolveSteady solves steady problem
+//   Solves: [K]⋅{u} = {f} represented by [A]⋅{x} = {b}
+//   Output:
+//     u -- [2⋅nnodes] displacements (ux,uy) at each node
+//     f -- [2⋅nnodes] right-hand side (with reactions) if reactions == true
+func (o *FdmElasticity) SolveSteady(reactions bool) (u, f []float64) {
+	o.Eqs.SolveOnce(o.calcXk, o.calcBu)
+	u = make([]float64, o.Eqs.N)
+	o.Eqs.JoinVector(u, o.Eqs.Xu, o.Eqs.Xk)
+	if reactions {
+		f = make([]float64, o.Eqs.N)
+		if o.Eqs.Nk > 0 { // need to calc Bu again because it was modified
+			for i, I := range o.Eqs.UtoF {
+				o.Eqs.Bu[i] = o.calcBu(I, 0)
+			}
+		}
+		o.Eqs.JoinVector(f, o.Eqs.Bu, o.Eqs.Bk)
+	}
+	return
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// knownEqs returns the equations with prescribed displacements
+func (o *FdmElasticity) knownEqs() (kx []int) {
+	for _, n := range o.EssenBcs.Nodes() {
+		for dof := 0; dof < 2; dof++ {
+			if _, _, available := o.EssenBcs.Value(n, dof, 0); available {
+				kx = append(kx, 2*n+dof)
+			}
+		}
+	}
+	return
+}
+
+// neighbour returns the node at (m+dm, n+dn), mirroring indices that fall outside the grid
+func (o *FdmElasticity) neighbour(m, n, dm, dn int) int {
+	if m+dm < 0 || m+dm > o.Grid.Npts(0)-1 {
+		dm = -dm
+	}
+	if n+dn < 0 || n+dn > o.Grid.Npts(1)-1 {
+		dn = -dn
+	}
+	return o.Grid.IndexMNPtoI(m+dm, n+dn, 0)
+}
+
+// calcXk calculates known {u} values (CalcXk in la.Equations)
+//  I -- equation number
+//  t -- time
+func (o *FdmElasticity) calcXk(I int, t float64) float64 {
+	_, val, available := o.EssenBcs.Value(I/2, I%2, t)
+	if available {
+		return val
+	}
+	return 0
+}
+
+// calcBu calculates RHS vector (e.g. source) corresponding to known values of {u} (CalcBu in la.Equations)
+//  I -- equation number
+//  t -- time
+func (o *FdmElasticity) calcBu(I int, t float64) float64 {
+	if o.Source != nil && o.Source[I%2] != nil {
+		return o.Source[I%2](o.Grid.Node(I/2), t)
+	}
+	return 0
+}
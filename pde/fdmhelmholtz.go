@@ -0,0 +1,187 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/la"
+)
+
+// FdmHelmholtz implements the Finite Difference (FDM) Helmholtz operator (2D) with perfectly
+// matched layers (PML) for scattering and radiation problems
+//
+//            ∂²u     ∂²u
+//    L{u} = ———— + ———— + k² u      with   L{u} = s({x})
+//            ∂x²     ∂y²
+//
+//  where k = ω/c is the wavenumber. The solution u is complex (time-harmonic fields u⋅exp(-iωt)).
+//
+//  The PML are absorbing layers inside the grid, along the selected edges (see SetPml), where the
+//  coordinates are stretched into the complex plane; i.e. ∂/∂x is replaced by (1/γx) ∂/∂x with
+//
+//    γx = 1 + i σ(d)/k     with     σ(d) = σ0 (d/L)²
+//
+//  where d is the distance into the layer of thickness L and σ0 is the strength. Thus, the waves
+//  entering the layers decay as exp(-∫σ dx) without reflection at the interface (in the continuous
+//  limit). The waves reflected at the outer boundary return with the amplitude exp(-2σ0⋅L/3). The
+//  operator in the layers is discretised in conservative form
+//
+//    1   ∂   ( 1   ∂u )         1   [ u[i+1] - u[i]     u[i] - u[i-1] ]
+//    —— —— ( —— —— )   ≈   ——————— [ ————————————— -  ————————————— ]
+//    γx ∂x ( γx ∂x )       γx[i] h² [   γx[i+½]          γx[i-½]     ]
+//
+//  NOTE: (1) the values of the essential boundary conditions are real; e.g. u = 0 at the outer
+//            boundaries of the layers or the incident field at a boundary
+//        (2) as in FdmLaplacian, boundary nodes without essential conditions use mirrored
+//            neighbours (∂u/∂n = 0)
+type FdmHelmholtz struct {
+	K        float64        // wavenumber
+	Grid     *gm.Grid       // grid
+	Source   fun.Svs        // source term function s({x},t) [may be nil]
+	EssenBcs *BoundaryConds // essential boundary conditions
+	Eqs      *la.Equations  // equations (numbering only; the system is complex; see Auu)
+	Auu      *la.TripletC   // [Nu][Nu] assembled matrix
+	Bu       la.VectorC     // [Nu] right-hand side including the prescribed values
+	pmlThick [2][2]float64  // [dim][side] thickness of layers (zero ⇒ no layer)
+	pmlSigma [2][2]float64  // [dim][side] strength σ0 of layers
+}
+
+// NewFdmHelmholtz creates a new FDM Helmholtz operator with given parameters
+//   params -- "k" wavenumber
+//   source -- source term function [optional]
+func NewFdmHelmholtz(params dbf.Params, grid *gm.Grid, source fun.Svs) (o *FdmHelmholtz) {
+	o = new(FdmHelmholtz)
+	err := params.ConnectSet(
+		[]*float64{&o.K},
+		[]string{"k"},
+		"FdmHelmholtz",
+	)
+	if err != "" {
+		chk.Panic(err)
+	}
+	if grid.Ndim() != 2 {
+		chk.Panic("FdmHelmholtz works in 2D only\n")
+	}
+	if o.K <= 0 {
+		chk.Panic("wavenumber must be positive. k = %g is invalid\n", o.K)
+	}
+	o.Grid = grid
+	o.Source = source
+	o.EssenBcs = NewBoundaryCondsGrid(grid, 1) // 1:maxNdof
+	return
+}
+
+// AddEbc adds essential boundary condition given tag of edge
+//   tag    -- edge tag in grid
+//   cvalue -- constant value [optional]; or
+//   fvalue -- function value [optional]
+func (o *FdmHelmholtz) AddEbc(tag int, cvalue float64, fvalue fun.Svs) {
+	o.Eqs = nil
+	o.EssenBcs.AddUsingTag(tag, 0, cvalue, fvalue)
+}
+
+// SetPml sets a perfectly matched layer along an edge
+//   tag       -- edge tag in grid: 10, 11, 20 or 21
+//   thickness -- thickness L of layer (inside the grid); zero removes the layer
+//   strength  -- maximum absorption σ0 (at the edge); e.g. σ0 = 3⋅ln(1/R)/(2⋅L) for a
+//                theoretical reflection coefficient R
+func (o *FdmHelmholtz) SetPml(tag int, thickness, strength float64) {
+	dim, side := tag/10-1, tag%10
+	if dim < 0 || dim > 1 || side > 1 {
+		chk.Panic("tag %d is invalid\n", tag)
+	}
+	if thickness < 0 || thickness > o.Grid.Xlen(dim) {
+		chk.Panic("thickness of layer must be in [0, %g]. %g is invalid\n", o.Grid.Xlen(dim), thickness)
+	}
+	o.pmlThick[dim][side] = thickness
+	o.pmlSigma[dim][side] = strength
+	o.Eqs = nil
+}
+
+// Stretching returns the complex stretching factor γ along dim at coordinate x (see FdmHelmholtz)
+func (o *FdmHelmholtz) Stretching(dim int, x float64) complex128 {
+	σ := 0.0
+	for side, edge := range []float64{o.Grid.Xmin(dim), o.Grid.Xmax(dim)} {
+		L := o.pmlThick[dim][side]
+		if L == 0 {
+			continue
+		}
+		d := x - (edge - L) // distance into layer at max side
+		if side == 0 {
+			d = edge + L - x
+		}
+		if d > 0 {
+			σ += o.pmlSigma[dim][side] * (d / L) * (d / L)
+		}
+	}
+	return complex(1, σ/o.K)
+}
+
+// Assemble assembles the complex system [Auu]⋅{uu} = {bu}
+func (o *FdmHelmholtz) Assemble() {
+	o.Eqs = la.NewEquations(o.Grid.Size(), o.EssenBcs.Nodes())
+	o.Auu = la.NewTripletC(o.Eqs.Nu, o.Eqs.Nu, 5*o.Eqs.Nu)
+	o.Bu = la.NewVectorC(o.Eqs.Nu)
+	for i, I := range o.Eqs.UtoF {
+		x := o.Grid.Node(I)
+		if o.Source != nil {
+			o.Bu[i] = complex(o.Source(x, 0), 0)
+		}
+		put := func(J int, c complex128) {
+			if j := o.Eqs.FtoU[J]; j >= 0 {
+				o.Auu.Put(i, j, c)
+				return
+			}
+			_, val, _ := o.EssenBcs.Value(J, 0, 0)
+			o.Bu[i] -= c * complex(val, 0)
+		}
+		m, n, _ := o.Grid.IndexItoMNP(I)
+		idx := []int{m, n}
+		diag := complex(o.K*o.K, 0)
+		for dim := 0; dim < 2; dim++ {
+			npts := o.Grid.Npts(dim)
+			h := o.Grid.Xlen(dim) / float64(npts-1)
+			γ := o.Stretching(dim, x[dim])
+			γm, γp := o.Stretching(dim, x[dim]-h/2), o.Stretching(dim, x[dim]+h/2)
+			jdx := []int{m, n}
+			jdx[dim] = idx[dim] - 1
+			if idx[dim] == 0 { // mirrored
+				jdx[dim], γm = 1, γp
+			}
+			L := o.Grid.IndexMNPtoI(jdx[0], jdx[1], 0)
+			jdx[dim] = idx[dim] + 1
+			if idx[dim] == npts-1 { // mirrored
+				jdx[dim], γp = npts-2, γm
+			}
+			R := o.Grid.IndexMNPtoI(jdx[0], jdx[1], 0)
+			cL, cR := 1/(γ*γm*complex(h*h, 0)), 1/(γ*γp*complex(h*h, 0))
+			put(L, cL)
+			put(R, cR)
+			diag -= cL + cR
+		}
+		o.Auu.Put(i, i, diag)
+	}
+}
+
+// Solve solves the complex system (assembling it if needed)
+//   u -- [nnodes] solution at all nodes
+func (o *FdmHelmholtz) Solve() (u []complex128) {
+	if o.Eqs == nil {
+		o.Assemble()
+	}
+	uu := la.SpSolveC(o.Auu, o.Bu)
+	u = make([]complex128, o.Grid.Size())
+	for i, I := range o.Eqs.UtoF {
+		u[I] = uu[i]
+	}
+	for _, I := range o.Eqs.KtoF {
+		_, val, _ := o.EssenBcs.Value(I, 0, 0)
+		u[I] = complex(val, 0)
+	}
+	return
+}
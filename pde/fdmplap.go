@@ -0,0 +1,249 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+)
+
+// FdmPLaplacian implements the (nonlinear) Finite Difference (FDM) p-Laplacian operator (2D);
+// e.g. for the diffusion in non-Newtonian fluids
+//
+//    L{u} = ∇ ⋅ (|∇u|ᵖ⁻² ∇u)     with   L{u} = s({x})
+//
+//  The operator is written in conservative form with the fluxes q = |∇u|ᵖ⁻² ∂u/∂n computed at
+//  the mid-points of the edges between nodes. At the face between nodes (m,n) and (m+1,n):
+//
+//         u[m+1,n] - u[m,n]           u[m,n+1] + u[m+1,n+1] - u[m,n-1] - u[m+1,n-1]
+//    gx = —————————————————      gy = —————————————————————————————————————————————
+//                Δx                                      4 Δy
+//
+//    qx = (ε² + gx² + gy²)⁽ᵖ⁻²⁾ᐟ² ⋅ gx
+//
+//  Thus, the coefficient depends on the local gradient and the residual {r} = L{u} - {s} and the
+//  Jacobian [J] = ∂{r}/∂{u} must be evaluated at each Newton iteration (see Residual, Jacobian
+//  and Solve). With p = 2, the standard 5-point Laplacian (kx = ky = 1) is recovered
+//
+//  NOTE: (1) the regularisation ε > 0 is required if p < 2 and the gradient may vanish
+//        (2) only essential boundary conditions are supported: all boundary nodes must have
+//            prescribed values
+//
+type FdmPLaplacian struct {
+	P        float64        // exponent p > 1
+	Eps      float64        // regularisation ε ≥ 0
+	Grid     *gm.Grid       // grid
+	Source   fun.Svs        // source term function s({x},t) [may be nil]
+	EssenBcs *BoundaryConds // essential boundary conditions
+	Eqs      *la.Equations  // equations (numbering only; the matrices are not allocated)
+	u        []float64      // [nnodes] all values (workspace)
+}
+
+// NewFdmPLaplacian creates a new FDM p-Laplacian operator with given parameters
+//   params -- "p" and "eps" [optional; default = 0]
+//   source -- source term function [optional]
+func NewFdmPLaplacian(params dbf.Params, grid *gm.Grid, source fun.Svs) (o *FdmPLaplacian) {
+	o = new(FdmPLaplacian)
+	err := params.ConnectSetOpt(
+		[]*float64{&o.P, &o.Eps},
+		[]string{"p", "eps"},
+		[]bool{false, true},
+		"FdmPLaplacian",
+	)
+	if err != "" {
+		chk.Panic(err)
+	}
+	if o.P <= 1 {
+		chk.Panic("exponent p must be greater than 1. p=%g is invalid\n", o.P)
+	}
+	if grid.Ndim() != 2 {
+		chk.Panic("FdmPLaplacian works in 2D only\n")
+	}
+	o.Grid = grid
+	o.Source = source
+	o.EssenBcs = NewBoundaryCondsGrid(grid, 1) // 1:maxNdof
+	return
+}
+
+// AddEbc adds essential boundary condition given tag of edge
+//   tag    -- edge tag in grid
+//   cvalue -- constant value [optional]; or
+//   fvalue -- function value [optional]
+func (o *FdmPLaplacian) AddEbc(tag int, cvalue float64, fvalue fun.Svs) {
+	o.Eqs = nil
+	o.EssenBcs.AddUsingTag(tag, 0, cvalue, fvalue)
+}
+
+// Residual computes the residual {r} = L{u} - {s} at nodes without prescribed values
+//   r  -- [Nu] residual
+//   uu -- [Nu] values at nodes without prescribed values
+//   NOTE: Residual and Jacobian can be given to num.NlSolver (see Solve)
+func (o *FdmPLaplacian) Residual(r, uu la.Vector) {
+	o.setValues(uu)
+	hx, hy := o.spacing()
+	for i, I := range o.Eqs.UtoF {
+		m, n, _ := o.Grid.IndexItoMNP(I)
+		r[i] = (o.flux(m, n, 0, nil)-o.flux(m-1, n, 0, nil))/hx +
+			(o.flux(m, n, 1, nil)-o.flux(m, n-1, 1, nil))/hy
+		if o.Source != nil {
+			r[i] -= o.Source(o.Grid.Node(I), 0)
+		}
+	}
+}
+
+// Jacobian computes the Jacobian matrix [J] = ∂{r}/∂{uu}
+//   J  -- [Nu][Nu] Jacobian; the triplet is initialised if empty
+//   uu -- [Nu] values at nodes without prescribed values
+func (o *FdmPLaplacian) Jacobian(J *la.Triplet, uu la.Vector) {
+	o.setValues(uu)
+	if J.Max() == 0 {
+		J.Init(o.Eqs.Nu, o.Eqs.Nu, 4*6*o.Eqs.Nu) // 4 faces with 6 nodes each
+	}
+	J.Start()
+	hx, hy := o.spacing()
+	for i, I := range o.Eqs.UtoF {
+		m, n, _ := o.Grid.IndexItoMNP(I)
+		put := func(coef float64) func(K int, dqdu float64) {
+			return func(K int, dqdu float64) {
+				if k := o.Eqs.FtoU[K]; k >= 0 {
+					J.Put(i, k, coef*dqdu)
+				}
+			}
+		}
+		o.flux(m, n, 0, put(1/hx))
+		o.flux(m-1, n, 0, put(-1/hx))
+		o.flux(m, n, 1, put(1/hy))
+		o.flux(m, n-1, 1, put(-1/hy))
+	}
+}
+
+// Solve solves the nonlinear problem with Newton's method (see num.NlSolver)
+//   Input:
+//     u0     -- [nnodes] initial values [may be nil ⇒ solution with p = 2]; the values at nodes
+//               with prescribed values are ignored
+//     prms   -- parameters of num.NlSolver; e.g. "atol", "rtol", "maxIt" [may be nil]
+//     silent -- do not show messages
+//   Output:
+//     u   -- [nnodes] solution at all nodes
+//     nit -- number of iterations
+func (o *FdmPLaplacian) Solve(u0 []float64, prms map[string]float64, silent bool) (u []float64, nit int) {
+	o.init()
+	uu := la.NewVector(o.Eqs.Nu)
+	if u0 == nil && o.P != 2 { // the Jacobian is singular if the gradient vanishes and ε = 0
+		p := o.P
+		o.P = 2
+		u0, _ = o.Solve(nil, prms, true)
+		o.P = p
+	}
+	if u0 != nil {
+		if len(u0) != o.Grid.Size() {
+			chk.Panic("size of u0 must be equal to the number of nodes. %d != %d\n", len(u0), o.Grid.Size())
+		}
+		for i, I := range o.Eqs.UtoF {
+			uu[i] = u0[I]
+		}
+	}
+	var solver num.NlSolver
+	solver.Init(o.Eqs.Nu, o.Residual, o.Jacobian, nil, false, false, prms)
+	defer solver.Free()
+	solver.Solve(uu, silent)
+	o.setValues(uu)
+	u = make([]float64, o.Grid.Size())
+	copy(u, o.u)
+	return u, solver.It
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// init creates the structure of equations and checks the boundary conditions
+func (o *FdmPLaplacian) init() {
+	if o.Eqs != nil {
+		return
+	}
+	for _, tag := range []int{10, 11, 20, 21} {
+		for _, I := range o.Grid.Boundary(tag) {
+			if !o.EssenBcs.Has(I) {
+				chk.Panic("FdmPLaplacian requires essential boundary conditions at all boundary nodes. node %d has none\n", I)
+			}
+		}
+	}
+	o.Eqs = la.NewEquations(o.Grid.Size(), o.EssenBcs.Nodes())
+	o.u = make([]float64, o.Grid.Size())
+	for _, I := range o.Eqs.KtoF {
+		_, val, _ := o.EssenBcs.Value(I, 0, 0)
+		o.u[I] = val
+	}
+}
+
+// setValues sets the workspace with all values
+func (o *FdmPLaplacian) setValues(uu la.Vector) {
+	o.init()
+	if len(uu) != o.Eqs.Nu {
+		chk.Panic("size of uu must be equal to the number of unknowns. %d != %d\n", len(uu), o.Eqs.Nu)
+	}
+	for i, I := range o.Eqs.UtoF {
+		o.u[I] = uu[i]
+	}
+}
+
+// spacing returns the grid spacing along x and y
+func (o *FdmPLaplacian) spacing() (hx, hy float64) {
+	hx = o.Grid.Xlen(0) / float64(o.Grid.Npts(0)-1)
+	hy = o.Grid.Xlen(1) / float64(o.Grid.Npts(1)-1)
+	return
+}
+
+// flux computes the flux at the face between nodes (m,n) and (m,n)+e_dim
+//   deriv -- if not nil, is called with the derivatives of the flux with respect to the values
+//            at the nodes defining the face gradient
+func (o *FdmPLaplacian) flux(m, n, dim int, deriv func(K int, dqdu float64)) (q float64) {
+
+	// nodes: A and B define the face; the others define the tangential derivative
+	hx, hy := o.spacing()
+	hn, ht := hx, hy
+	dm, dn := 1, 0 // normal
+	tm, tn := 0, 1 // tangential
+	if dim == 1 {
+		hn, ht = hy, hx
+		dm, dn, tm, tn = 0, 1, 1, 0
+	}
+	node := func(i, j int) int { return o.Grid.IndexMNPtoI(i, j, 0) }
+	A, B := node(m, n), node(m+dm, n+dn)
+	Ap, Bp := node(m+tm, n+tn), node(m+dm+tm, n+dn+tn)
+	Am, Bm := node(m-tm, n-tn), node(m+dm-tm, n+dn-tn)
+
+	// gradient and flux
+	u := o.u
+	gn := (u[B] - u[A]) / hn
+	gt := (u[Ap] + u[Bp] - u[Am] - u[Bm]) / (4 * ht)
+	s2 := o.Eps*o.Eps + gn*gn + gt*gt
+	k := math.Pow(s2, (o.P-2)/2)
+	q = k * gn
+
+	// derivatives
+	if deriv != nil {
+		dk := 0.0 // (p-2)⋅(ε² + |g|²)^((p-4)/2)
+		if s2 > 0 && o.P != 2 {
+			dk = (o.P - 2) * math.Pow(s2, (o.P-4)/2)
+		}
+		dqdgn := k + dk*gn*gn
+		dqdgt := dk * gn * gt
+		deriv(A, -dqdgn/hn)
+		deriv(B, dqdgn/hn)
+		for _, K := range []int{Ap, Bp} {
+			deriv(K, dqdgt/(4*ht))
+		}
+		for _, K := range []int{Am, Bm} {
+			deriv(K, -dqdgt/(4*ht))
+		}
+	}
+	return
+}
@@ -0,0 +1,117 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/la"
+)
+
+// FdmSpherical implements the Finite Difference (FDM) Laplacian operator in spherical coordinates
+// ("laplacian-spherical") on an (r,θ) grid without dependence on the azimuth; i.e. x ≡ r ≥ 0 and
+// y ≡ θ (polar angle) with 0 ≤ θ ≤ π
+//
+//                 1   ∂  (    ∂u )          1        ∂  (        ∂u )
+//    L{u} = kr ⋅ ——— —— ( r² —— )  + kt ⋅ ————————— —— ( sin θ —— )      with   L{u} = s(r,θ)
+//                 r²  ∂r (    ∂r )        r² sin θ  ∂θ (        ∂θ )
+//
+//  As in FdmCylindrical, the operator is discretised in conservative (finite volume) form on the
+//  control volumes around nodes (per unit azimuth)
+//
+//    V = (r³[i+½] - r³[i-½])/3 ⋅ (cos θ[j-½] - cos θ[j+½])
+//
+//  with radial faces of area r²[i±½]⋅(cos θ[j-½] - cos θ[j+½]) and polar faces of area
+//  sin θ[j±½]⋅(r[i+½] - r[i-½]). Radially symmetric problems are solved with a few points along θ
+//  (e.g. 3) since the solution does not depend on θ
+//
+//  Origin and axis:
+//    (1) at r = 0, the area of the inner face vanishes and V = r³[½]/3 ⋅ (...); thus, the
+//        limiting stencil 6⋅(u[1] - u[0])/h² of (3 ∂²u/∂r²) with ∂u/∂r = 0 is obtained. The
+//        nodes at r = 0 (one per θ) represent the same point; they are coupled via the polar
+//        faces and have approximately the same value; if the solution depends on θ, the error
+//        at these nodes is first-order (second-order elsewhere)
+//    (2) at θ = 0 and θ = π (the axis), the area of the outer polar face vanishes (sin θ = 0);
+//        i.e. symmetry conditions ∂u/∂θ = 0 with no boundary conditions required
+//
+//  NOTE: boundary nodes without essential conditions have zero flux; the radial solution u = r²
+//        (with uniform source) is reproduced exactly
+type FdmSpherical struct {
+	Kr       float64        // radial coefficient
+	Kt       float64        // polar coefficient
+	Grid     *gm.Grid       // grid: x ≡ r and y ≡ θ
+	Source   fun.Svs        // source term function s({x},t) [may be nil]
+	EssenBcs *BoundaryConds // essential boundary conditions
+	Eqs      *la.Equations  // equations (numbering only; see Auu)
+	Auu      *la.Triplet    // [Nu][Nu] assembled matrix
+	Bu       la.Vector      // [Nu] right-hand side including the prescribed values
+}
+
+// NewFdmSpherical creates a new FDM spherical Laplacian operator with given parameters
+//   params -- "kr" (radial) and "kt" (polar) coefficients; e.g. kr = kt for isotropic diffusion
+//   grid   -- 2D grid with rmin = xmin ≥ 0 and 0 ≤ θ ≤ π along y (unrotated)
+//   source -- source term function [optional]
+func NewFdmSpherical(params dbf.Params, grid *gm.Grid, source fun.Svs) (o *FdmSpherical) {
+	o = new(FdmSpherical)
+	err := params.ConnectSet(
+		[]*float64{&o.Kr, &o.Kt},
+		[]string{"kr", "kt"},
+		"FdmSpherical",
+	)
+	if err != "" {
+		chk.Panic(err)
+	}
+	if grid.Ndim() != 2 || grid.Rotation() != 0 {
+		chk.Panic("FdmSpherical works with unrotated 2D grids only\n")
+	}
+	if grid.Xmin(0) < 0 {
+		chk.Panic("radial coordinates must be non-negative. rmin = %g is invalid\n", grid.Xmin(0))
+	}
+	if grid.Xmin(1) < 0 || grid.Xmax(1) > math.Pi*(1+1e-15) {
+		chk.Panic("polar angles must be in [0, π]. [%g, %g] is invalid\n", grid.Xmin(1), grid.Xmax(1))
+	}
+	o.Grid = grid
+	o.Source = source
+	o.EssenBcs = NewBoundaryCondsGrid(grid, 1) // 1:maxNdof
+	return
+}
+
+// AddEbc adds essential boundary condition given tag of edge
+//   tag    -- edge tag in grid: 10 (rmin), 11 (rmax), 20 (θmin) or 21 (θmax)
+//   cvalue -- constant value [optional]; or
+//   fvalue -- function value [optional]
+func (o *FdmSpherical) AddEbc(tag int, cvalue float64, fvalue fun.Svs) {
+	o.Eqs = nil
+	o.EssenBcs.AddUsingTag(tag, 0, cvalue, fvalue)
+}
+
+// Assemble assembles the system [Auu]⋅{uu} = {bu}
+func (o *FdmSpherical) Assemble() {
+	R, T := o.Grid.Coords(0), o.Grid.Coords(1)
+	rf, tf := fvFaces(R), fvFaces(T)
+	vr := func(m int) float64 { return (rf[m+1]*rf[m+1]*rf[m+1] - rf[m]*rf[m]*rf[m]) / 3.0 } // ∫ r² dr
+	vt := func(n int) float64 { return math.Cos(tf[n]) - math.Cos(tf[n+1]) }                 // ∫ sin θ dθ
+	vol := func(m, n int) float64 { return vr(m) * vt(n) }
+	area := func(dim, m, n, f int) float64 {
+		if dim == 0 {
+			return rf[f] * rf[f] * vt(n)
+		}
+		return math.Sin(tf[f]) * (rf[m+1] - rf[m])
+	}
+	o.Eqs, o.Auu, o.Bu = fvAssemble(o.Grid, o.EssenBcs, o.Source, []float64{o.Kr, o.Kt}, vol, area)
+}
+
+// SolveSteady solves the system (assembling it if needed)
+//   u -- [nnodes] solution at all nodes
+func (o *FdmSpherical) SolveSteady() (u []float64) {
+	if o.Eqs == nil {
+		o.Assemble()
+	}
+	return fvSolve(o.Grid, o.EssenBcs, o.Eqs, o.Auu, o.Bu)
+}
@@ -0,0 +1,390 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/la"
+)
+
+// Divergence computes the divergence of a node-based vector field on a 2D grid
+//
+//            ∂fx     ∂fy
+//   div f = ———— + ————
+//            ∂x      ∂y
+//
+//   The derivatives are computed with central differences at interior nodes and second-order
+//   one-sided differences at the boundaries (first-order if there are only two nodes along a
+//   direction). Thus, the result is exact for quadratic fields.
+//
+//   Input:
+//     grid   -- 2D grid
+//     fx, fy -- [nnodes] components of the vector field at each node
+//   Output:
+//     div -- [nnodes] divergence at each node
+//
+//   NOTE: the spacings along each direction are the actual distances between nodes; i.e. stretched
+//         grids (e.g. RectSet2d) are supported
+func Divergence(grid *gm.Grid, fx, fy []float64) (div []float64) {
+	if grid.Ndim() != 2 {
+		chk.Panic("Divergence works in 2D only\n")
+	}
+	if len(fx) != grid.Size() || len(fy) != grid.Size() {
+		chk.Panic("size of field components must be equal to the number of nodes (%d). len(fx)=%d, len(fy)=%d\n", grid.Size(), len(fx), len(fy))
+	}
+	div = make([]float64, grid.Size())
+	for I := 0; I < grid.Size(); I++ {
+		div[I] = gridDerivative(grid, fx, I, 0) + gridDerivative(grid, fy, I, 1)
+	}
+	return
+}
+
+// GridQuadrature returns the weights of the (composite) trapezoidal rule at the nodes of a 2D or
+// 3D grid, such that
+//
+//   ⌠                 nnodes-1
+//   │ f({x}) dΩ  ≈     Σ    weights[I] ⋅ f[I]
+//   ⌡Ω                I = 0
+//
+//   The weights are the products of 1D trapezoidal weights; thus, they are equal to the area (or
+//   volume) of the control volume around each node (see gm.Grid.ControlVolume), i.e. halved at
+//   edges (faces) and quartered at corners in 2D; e.g. hx⋅hy/4 at the corners of uniform grids
+//   with anisotropic cells (hx ≠ hy). The rule is exact for (multi-)linear functions
+//
+//   NOTE: the spacings along each direction are the actual distances between nodes; i.e. stretched
+//         grids (e.g. RectSet2d) are supported
+func GridQuadrature(grid *gm.Grid) (weights []float64) {
+	return gridVolumes(grid)
+}
+
+// LaplaceEigenmode returns the (m,n) eigenmode of the FDM Laplacian on a uniform 2D grid with
+// homogeneous Dirichlet boundary conditions and unit coefficients (kx = ky = 1)
+//
+//   φ(x,y) = sin(m⋅π⋅x̄/Lx) ⋅ sin(n⋅π⋅ȳ/Ly)      with  x̄ = x - xmin  and  ȳ = y - ymin
+//
+//   The sampled mode is an exact eigenvector of the 5-point stencil at interior nodes with the
+//   discrete eigenvalue
+//
+//         4          m⋅π⋅Δx       4          n⋅π⋅Δy
+//   λ = - ——— ⋅ sin²(——————)  -  ——— ⋅ sin²(——————)   ≈  -(m⋅π/Lx)² - (n⋅π/Ly)²   [O(h²)]
+//         Δx²        2⋅Lx        Δy²        2⋅Ly
+//
+//   Input:
+//     grid -- 2D grid
+//     m, n -- wave numbers along x and y; 1 ≤ m < nx-1 and 1 ≤ n < ny-1 (otherwise the mode vanishes)
+//   Output:
+//     eigenvalue  -- discrete eigenvalue λ
+//     eigenvector -- [nnodes] sampled mode at all nodes (zero at the boundaries)
+//
+//   NOTE: the grid must be uniform along each direction; the sampled modes of stretched grids
+//         (e.g. RectSet2d) are not eigenvectors of the operator and thus such grids are rejected
+func LaplaceEigenmode(grid *gm.Grid, m, n int) (eigenvalue float64, eigenvector []float64) {
+	if grid.Ndim() != 2 {
+		chk.Panic("LaplaceEigenmode works in 2D only\n")
+	}
+	if !uniformGrid(grid) {
+		chk.Panic("LaplaceEigenmode requires uniform grids\n")
+	}
+	nx, ny := grid.Npts(0), grid.Npts(1)
+	if m < 1 || m >= nx-1 || n < 1 || n >= ny-1 {
+		chk.Panic("wave numbers must satisfy 1 ≤ m < %d and 1 ≤ n < %d. m=%d, n=%d is invalid\n", nx-1, ny-1, m, n)
+	}
+	lx, ly := grid.Xlen(0), grid.Xlen(1)
+	dx, dy := lx/float64(nx-1), ly/float64(ny-1)
+	sx := math.Sin(float64(m) * math.Pi * dx / (2.0 * lx))
+	sy := math.Sin(float64(n) * math.Pi * dy / (2.0 * ly))
+	eigenvalue = -4.0*sx*sx/(dx*dx) - 4.0*sy*sy/(dy*dy)
+	eigenvector = make([]float64, grid.Size())
+	for I := 0; I < grid.Size(); I++ {
+		i, j, _ := grid.IndexItoMNP(I)
+		if i == 0 || i == nx-1 || j == 0 || j == ny-1 {
+			continue // exactly zero at boundaries
+		}
+		x := float64(i) * dx
+		y := float64(j) * dy
+		eigenvector[I] = math.Sin(float64(m)*math.Pi*x/lx) * math.Sin(float64(n)*math.Pi*y/ly)
+	}
+	return
+}
+
+// CompareSolutions finds the maximum absolute difference between two node-based fields
+//   Input:
+//     a, b -- [nnodes] fields; e.g. solutions before and after a refactoring
+//     grid -- the grid
+//   Output:
+//     maxDiff -- max(|a[I] - b[I]|); or NaN if a value is NaN or ±Inf
+//     node    -- index of node where the maximum difference occurs (the first one if repeated);
+//                or the first node with a NaN or ±Inf value
+//     x       -- coordinates of node
+func CompareSolutions(a, b []float64, grid *gm.Grid) (maxDiff float64, node int, x la.Vector) {
+	if len(a) != grid.Size() || len(b) != grid.Size() {
+		chk.Panic("size of fields must be equal to the number of nodes (%d). len(a)=%d, len(b)=%d\n", grid.Size(), len(a), len(b))
+	}
+	for I := 0; I < len(a); I++ {
+		if math.IsNaN(a[I]) || math.IsInf(a[I], 0) || math.IsNaN(b[I]) || math.IsInf(b[I], 0) {
+			return math.NaN(), I, grid.Node(I)
+		}
+		if diff := math.Abs(a[I] - b[I]); diff > maxDiff {
+			maxDiff, node = diff, I
+		}
+	}
+	x = grid.Node(node)
+	return
+}
+
+// SolutionTotalVariation computes the total variation of a node-based field; e.g. to detect
+// numerical oscillations (Gibbs phenomenon) caused by unstable stencils
+//
+//   TV = Σ |u[J] - u[I]|   for all pairs of neighbour nodes (I,J) along each direction
+//
+//   NOTE: the total variation of a monotone 1D field is |u[last] - u[first]|
+func SolutionTotalVariation(grid *gm.Grid, u []float64) (tv float64) {
+	if len(u) != grid.Size() {
+		chk.Panic("size of field must be equal to the number of nodes. %d != %d\n", len(u), grid.Size())
+	}
+	for I := 0; I < grid.Size(); I++ {
+		gridNeighbours(grid, I, true, func(J int) {
+			tv += math.Abs(u[J] - u[I])
+		})
+	}
+	return
+}
+
+// LocalExtremaCount counts the interior nodes where a field has a local extremum; i.e. the value
+// is not smaller (or not greater) than the values at the 2⋅ndim neighbour nodes along the grid
+// directions and differs from at least one of them. Solutions of diffusion problems without
+// sources have no interior extrema (maximum principle); thus, a large count indicates oscillations
+func LocalExtremaCount(grid *gm.Grid, u []float64) (count int) {
+	if len(u) != grid.Size() {
+		chk.Panic("size of field must be equal to the number of nodes. %d != %d\n", len(u), grid.Size())
+	}
+	for I := 0; I < grid.Size(); I++ {
+		ngreater, nsmaller, nneigh := 0, 0, 0
+		gridNeighbours(grid, I, false, func(J int) {
+			nneigh++
+			if u[J] > u[I] {
+				ngreater++
+			}
+			if u[J] < u[I] {
+				nsmaller++
+			}
+		})
+		if nneigh < 2*grid.Ndim() { // boundary node
+			continue
+		}
+		if (ngreater == 0 && nsmaller > 0) || (nsmaller == 0 && ngreater > 0) {
+			count++
+		}
+	}
+	return
+}
+
+// CellPeclet computes the cell Peclet numbers of advection-diffusion problems; e.g. to find the
+// regions of the grid where upwinding (see FdmAdvDiff) or refinement is needed
+//
+//            ____________________            |vx|⋅hx          |vy|⋅hy
+//   Pe = √  Peₓ² + Pe_y²      with    Peₓ = ———————   Pe_y = ———————
+//                                               kx               ky
+//
+//   where hx and hy are the sides of each cell and vx and vy are the averages of the velocities
+//   at the corners of the cell. Thus, Pe = |v|⋅h/k with uniform h and isotropic k. Central
+//   differences of the advection terms are free of oscillations if Peₓ ≤ 2 and Pe_y ≤ 2
+//
+//   Input:
+//     grid   -- 2D grid; stretched grids (RectSet2d) are supported
+//     vx, vy -- [nnodes] components of the velocity at each node
+//     kx, ky -- diffusion coefficients along x and y (≥ 0)
+//   Output:
+//     pe    -- [ncells] Peclet number of each cell; cell (m,n) has index m + n⋅(nx-1) [+Inf if the
+//              diffusion coefficient along a direction with non-zero velocity is zero]
+//     peMax -- maximum Peclet number
+func CellPeclet(grid *gm.Grid, vx, vy []float64, kx, ky float64) (pe []float64, peMax float64) {
+	if kx < 0 || ky < 0 {
+		chk.Panic("diffusion coefficients must be non-negative. kx=%g, ky=%g is invalid\n", kx, ky)
+	}
+	ratio := func(v, h, k float64) float64 { // |v|⋅h/k
+		if v == 0 {
+			return 0
+		}
+		if k == 0 {
+			return math.Inf(1)
+		}
+		return math.Abs(v) * h / k
+	}
+	pe = gridCellValues(grid, vx, vy, func(vx, vy, hx, hy float64) float64 {
+		return math.Hypot(ratio(vx, hx, kx), ratio(vy, hy, ky))
+	})
+	for _, v := range pe {
+		peMax = math.Max(peMax, v)
+	}
+	return
+}
+
+// CellCFL computes the cell Courant-Friedrichs-Lewy numbers of advection problems; e.g. to check
+// the stability of explicit time-stepping schemes (the explicit upwind scheme is stable if C ≤ 1)
+//
+//            |vx|⋅Δt     |vy|⋅Δt
+//   C  =  ———————— + ————————
+//              hx          hy
+//
+//   Input:
+//     grid   -- 2D grid; stretched grids (RectSet2d) are supported
+//     vx, vy -- [nnodes] components of the velocity at each node (averaged at the cells)
+//     dt     -- time step
+//   Output:
+//     cfl    -- [ncells] CFL number of each cell; cell (m,n) has index m + n⋅(nx-1)
+//     cflMax -- maximum CFL number
+func CellCFL(grid *gm.Grid, vx, vy []float64, dt float64) (cfl []float64, cflMax float64) {
+	cfl = gridCellValues(grid, vx, vy, func(vx, vy, hx, hy float64) float64 {
+		return math.Abs(vx)*dt/hx + math.Abs(vy)*dt/hy
+	})
+	for _, v := range cfl {
+		cflMax = math.Max(cflMax, v)
+	}
+	return
+}
+
+// FieldStats holds statistics of a node-based field over the grid (see Stats)
+type FieldStats struct {
+	Min     float64   // minimum value
+	Max     float64   // maximum value
+	Mean    float64   // mean value weighted by the control volumes: ∫u dΩ / ∫dΩ
+	Rms     float64   // root-mean-square value weighted by the control volumes: sqrt(∫u² dΩ / ∫dΩ)
+	NodeMin int       // index of node where the minimum occurs (the first one if repeated)
+	NodeMax int       // index of node where the maximum occurs (the first one if repeated)
+	Xmin    la.Vector // coordinates of NodeMin
+	Xmax    la.Vector // coordinates of NodeMax
+}
+
+// Stats computes the statistics of a solution over the grid; e.g. for quick summaries of results
+//
+//   The integrals of the mean and RMS values are computed with the trapezoidal rule; i.e. the
+//   weights are the areas (volumes) of the control volumes around nodes, which are computed from
+//   the actual coordinates; thus, stretched grids (RectSet2d or RectSet3d) are supported
+//
+//   u -- [nnodes] solution at all nodes
+func (o *FdmLaplacian) Stats(u []float64) (stats *FieldStats) {
+	g := o.Grid
+	if len(u) != g.Size() {
+		chk.Panic("size of field must be equal to the number of nodes. %d != %d\n", len(u), g.Size())
+	}
+	w := gridVolumes(g)
+	stats = &FieldStats{Min: u[0], Max: u[0]}
+	vol := 0.0
+	for I, v := range u {
+		if v < stats.Min {
+			stats.Min, stats.NodeMin = v, I
+		}
+		if v > stats.Max {
+			stats.Max, stats.NodeMax = v, I
+		}
+		stats.Mean += w[I] * v
+		stats.Rms += w[I] * v * v
+		vol += w[I]
+	}
+	stats.Mean /= vol
+	stats.Rms = math.Sqrt(stats.Rms / vol)
+	stats.Xmin, stats.Xmax = g.Node(stats.NodeMin), g.Node(stats.NodeMax)
+	return
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// gridVolumes returns the areas (volumes) of the control volumes around the nodes (see
+// gm.Grid.ControlVolume); i.e. the weights of the trapezoidal rule of rectangular (possibly
+// stretched) grids
+func gridVolumes(grid *gm.Grid) (weights []float64) {
+	weights = make([]float64, grid.Size())
+	for I := range weights {
+		weights[I] = grid.ControlVolume(I)
+	}
+	return
+}
+
+// gridCellValues calls fcn with the velocity averaged at the corners and the sides of each cell
+// of a 2D grid and returns the results [ncells]; cell (m,n) has index m + n⋅(nx-1)
+func gridCellValues(grid *gm.Grid, vx, vy []float64, fcn func(vx, vy, hx, hy float64) float64) (res []float64) {
+	if grid.Ndim() != 2 {
+		chk.Panic("cell numbers work in 2D only\n")
+	}
+	if len(vx) != grid.Size() || len(vy) != grid.Size() {
+		chk.Panic("size of velocity components must be equal to the number of nodes (%d). len(vx)=%d, len(vy)=%d\n", grid.Size(), len(vx), len(vy))
+	}
+	X, Y := grid.Coords(0), grid.Coords(1)
+	nx, ny := len(X), len(Y)
+	res = make([]float64, (nx-1)*(ny-1))
+	for n := 0; n < ny-1; n++ {
+		for m := 0; m < nx-1; m++ {
+			corners := []int{m + n*nx, m + 1 + n*nx, m + (n+1)*nx, m + 1 + (n+1)*nx}
+			ux, uy := 0.0, 0.0
+			for _, I := range corners {
+				ux += vx[I] / 4.0
+				uy += vy[I] / 4.0
+			}
+			res[m+n*(nx-1)] = fcn(ux, uy, X[m+1]-X[m], Y[n+1]-Y[n])
+		}
+	}
+	return
+}
+
+// gridDerivative computes ∂f/∂x_dim at node I using three-point central differences at interior
+// nodes and one-sided differences at boundaries; the spacings are the actual distances between
+// nodes (see gm.Grid.Spacings); i.e. stretched grids are supported
+//
+//            -h⁺                h⁺ - h⁻              h⁻
+//   f' = ——————————— f₋₁  +  ——————— f₀  +  ——————————— f₊₁     (interior; h⁻ = h⁺ ⇒ central)
+//        h⁻ (h⁻ + h⁺)          h⁻ h⁺          h⁺ (h⁻ + h⁺)
+//
+func gridDerivative(grid *gm.Grid, f []float64, I, dim int) float64 {
+	npts := grid.Npts(dim)
+	idx := make([]int, 3)
+	idx[0], idx[1], idx[2] = grid.IndexItoMNP(I)
+	index := func(k int) int { // index of node at offset k along dim
+		jdx := []int{idx[0], idx[1], idx[2]}
+		jdx[dim] += k
+		return grid.IndexMNPtoI(jdx[0], jdx[1], jdx[2])
+	}
+	node := func(k int) float64 { // value at offset k along dim
+		return f[index(k)]
+	}
+	i := idx[dim]
+	hm, hp := grid.Spacings(dim, I)
+	switch {
+	case npts == 2:
+		return (node(1-i) - node(-i)) / hp
+	case i == 0: // a = x₁ - x₀; b = x₂ - x₁
+		a := hp
+		_, b := grid.Spacings(dim, index(1))
+		return -(2*a+b)/(a*(a+b))*node(0) + (a+b)/(a*b)*node(1) - a/(b*(a+b))*node(2)
+	case i == npts-1: // a = xₙ - xₙ₋₁; b = xₙ₋₁ - xₙ₋₂
+		a := hm
+		b, _ := grid.Spacings(dim, index(-1))
+		return (2*a+b)/(a*(a+b))*node(0) - (a+b)/(a*b)*node(-1) + a/(b*(a+b))*node(-2)
+	}
+	return -hp/(hm*(hm+hp))*node(-1) + (hp-hm)/(hm*hp)*node(0) + hm/(hp*(hm+hp))*node(1)
+}
+
+// gridNeighbours calls fcn with the neighbours of node I along each direction
+//   forwardOnly -- only the neighbours with larger indices; i.e. each pair is visited once
+func gridNeighbours(grid *gm.Grid, I int, forwardOnly bool, fcn func(J int)) {
+	idx := make([]int, 3)
+	idx[0], idx[1], idx[2] = grid.IndexItoMNP(I)
+	for dim := 0; dim < grid.Ndim(); dim++ {
+		for _, k := range []int{1, -1} {
+			if k < 0 && forwardOnly {
+				continue
+			}
+			jdx := []int{idx[0], idx[1], idx[2]}
+			jdx[dim] += k
+			if jdx[dim] < 0 || jdx[dim] >= grid.Npts(dim) {
+				continue
+			}
+			fcn(grid.IndexMNPtoI(jdx[0], jdx[1], jdx[2]))
+		}
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// FluxField holds a locally conservative flux field reconstructed from a FDM solution (2D)
+//
+//   The flux densities q = k ∇u are defined at the faces of the control volumes (dual cells)
+//   around the nodes; i.e. the faces are at the mid-points between nodes and at the boundary of
+//   the grid. Within each control volume, qx varies linearly along x and qy varies linearly along
+//   y (lowest-order Raviart-Thomas field); thus, the normal components are continuous across the
+//   faces (H(div)-conforming) and the divergence is constant in each control volume
+type FluxField struct {
+	Grid *gm.Grid    // grid
+	Qx   [][]float64 // [ny][nx+1] kx ∂u/∂x at faces normal to x; face m is between nodes m-1 and m
+	Qy   [][]float64 // [ny+1][nx] ky ∂u/∂y at faces normal to y; face n is between nodes n-1 and n
+	xf   [][]float64 // [2][npts+1] coordinates of faces along each direction
+}
+
+// ReconstructFlux reconstructs the flux field of a solution such that the discrete conservation
+// holds exactly on the control volume of each node without prescribed value (see FluxField)
+//
+//   div{q} = s + kr⋅u     (i.e. L{u} = s; see FdmLaplacian)
+//
+//   The fluxes at the interior faces are given by the differences of the nodal values; e.g.
+//   qx = kx (u[m] - u[m-1]) / (x[m] - x[m-1]). At the boundary, the fluxes are given by the
+//   natural (qn = k ∂u/∂n) or Robin conditions (zero if none is given). Thus, for the solution of
+//   the discrete system, the divergence is equal to the discrete source; whereas differentiating
+//   the solution at the nodes does not satisfy the balance
+//
+//   Input:
+//     op -- assembled operator (2D); the 5-point stencil with constant coefficients (and reaction)
+//           is supported only; i.e. not Mehrstellen, kxy, coefficient fields or interfaces
+//     u  -- [nnodes] solution at all nodes
+//   Output:
+//     q -- flux field
+//
+//   NOTE: at boundary faces of nodes with prescribed values, the fluxes are the fluxes of the
+//         neighbouring interior faces (the balance does not hold on these control volumes; see
+//         Reactions instead)
+func ReconstructFlux(op *FdmLaplacian, u []float64) (q *FluxField) {
+
+	// check
+	g := op.Grid
+	if g.Ndim() != 2 || g.Rotation() != 0 {
+		chk.Panic("ReconstructFlux works with unrotated 2D grids only\n")
+	}
+	if op.Mehrstellen || op.Kxy != 0 || op.kField != nil || op.kTensor != nil || op.jumps != nil {
+		chk.Panic("ReconstructFlux does not support the Mehrstellen stencil, kxy, coefficient fields or interface conditions\n")
+	}
+	if op.Eqs == nil || !op.bcsReady {
+		chk.Panic("operator must be assembled before calling ReconstructFlux\n")
+	}
+	if len(u) != g.Size() {
+		chk.Panic("size of u must be equal to the number of nodes. %d != %d\n", len(u), g.Size())
+	}
+
+	// faces
+	q = &FluxField{Grid: g, xf: make([][]float64, 2)}
+	npts := []int{g.Npts(0), g.Npts(1)}
+	for dim := 0; dim < 2; dim++ {
+		X := g.Coords(dim)
+		q.xf[dim] = append(q.xf[dim], X[0])
+		for i := 1; i < npts[dim]; i++ {
+			q.xf[dim] = append(q.xf[dim], (X[i-1]+X[i])/2)
+		}
+		q.xf[dim] = append(q.xf[dim], X[npts[dim]-1])
+	}
+	q.Qx = utl.Alloc(npts[1], npts[0]+1)
+	q.Qy = utl.Alloc(npts[1]+1, npts[0])
+
+	// fluxes
+	for dim := 0; dim < 2; dim++ {
+		X := g.Coords(dim)
+		k := []float64{op.Kx, op.Ky}[dim]
+		set := func(m, n, f int, value float64) { // f: face index along dim
+			if dim == 0 {
+				q.Qx[n][f] = value
+			} else {
+				q.Qy[f][m] = value
+			}
+		}
+		for I := 0; I < g.Size(); I++ {
+			m, n, _ := g.IndexItoMNP(I)
+			i := []int{m, n}[dim]
+
+			// interior face between i-1 and i
+			if i > 0 {
+				J := g.IndexMNPtoI(m-1+dim, n-dim, 0)
+				set(m, n, i, k*(u[I]-u[J])/(X[i]-X[i-1]))
+			}
+
+			// boundary faces
+			if op.Eqs.FtoU[I] < 0 {
+				continue // prescribed: set below
+			}
+			for side, at := range []bool{i == 0, i == npts[dim]-1} {
+				if at {
+					sign := []float64{-1, 1}[side] // normal
+					set(m, n, i+side, sign*op.boundaryFlux(I, dim, side, u[I]))
+				}
+			}
+		}
+
+		// prescribed nodes: extrapolated along dim
+		for _, I := range op.Eqs.KtoF {
+			m, n, _ := g.IndexItoMNP(I)
+			i := []int{m, n}[dim]
+			if i == 0 && npts[dim] > 1 {
+				set(m, n, 0, q.get(dim, m, n, 1))
+			}
+			if i == npts[dim]-1 && npts[dim] > 1 {
+				set(m, n, i+1, q.get(dim, m, n, i))
+			}
+		}
+	}
+	return
+}
+
+// Divergence computes the divergence of the flux field in the control volume of each node
+//   div -- [nnodes] divergence
+func (o *FluxField) Divergence() (div []float64) {
+	div = make([]float64, o.Grid.Size())
+	for I := range div {
+		m, n, _ := o.Grid.IndexItoMNP(I)
+		div[I] = (o.Qx[n][m+1]-o.Qx[n][m])/(o.xf[0][m+1]-o.xf[0][m]) + (o.Qy[n+1][m]-o.Qy[n][m])/(o.xf[1][n+1]-o.xf[1][n])
+	}
+	return
+}
+
+// At evaluates the flux field at a point (inside the grid)
+//   x  -- coordinates
+//   qx -- x-component of flux
+//   qy -- y-component of flux
+func (o *FluxField) At(x la.Vector) (qx, qy float64) {
+	cell := func(dim int) (i int, ξ float64) {
+		F := o.xf[dim]
+		i = utl.Imin(utl.Imax(sort.SearchFloat64s(F, x[dim])-1, 0), len(F)-2)
+		ξ = (x[dim] - F[i]) / (F[i+1] - F[i])
+		return
+	}
+	m, ξ := cell(0)
+	n, η := cell(1)
+	qx = (1-ξ)*o.Qx[n][m] + ξ*o.Qx[n][m+1]
+	qy = (1-η)*o.Qy[n][m] + η*o.Qy[n+1][m]
+	return
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// get returns the flux along dim at face f of the control volume of node (m,n)
+func (o *FluxField) get(dim, m, n, f int) float64 {
+	if dim == 0 {
+		return o.Qx[n][f]
+	}
+	return o.Qy[f][m]
+}
+
+// boundaryFlux returns the flux density qn = k ∂u/∂n at the boundary face of node I normal to dim
+// on the given side (0: min, 1: max) due to the natural and Robin conditions (zero if none)
+func (o *FdmLaplacian) boundaryFlux(I, dim, side int, uI float64) (qn float64) {
+	tag := 10*(dim+1) + side
+	if o.NaturBcs.Has(I) {
+		if _, val, available := o.NaturBcs.Value(I, 0, 0); available {
+			for _, t := range o.NaturBcs.Tags(I) {
+				if t == tag {
+					qn += val
+				}
+			}
+		}
+	}
+	x := o.Grid.Node(I)
+	o.RobinBcs.terms(I, func(d int, item *robinItem) {
+		if item.tag == tag {
+			k := []float64{o.Kx, o.Ky}[d]
+			qn += k * (item.c(x, 0) - item.a(x, 0)*uI) / item.b(x, 0)
+		}
+	})
+	return
+}
@@ -0,0 +1,29 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import "github.com/cpmech/gosl/io"
+
+// Logger defines an interface to print (progress) messages from solvers. An instance may be
+// injected into the solvers in order to capture or suppress their output
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// LoggerPf implements Logger with io.Pf; i.e. messages are printed if io.Verbose is true
+type LoggerPf struct{}
+
+// Printf prints message using io.Pf
+func (o LoggerPf) Printf(format string, args ...interface{}) {
+	io.Pf(format, args...)
+}
+
+// logf prints message using logger; or io.Pf if logger is nil
+func logf(logger Logger, format string, args ...interface{}) {
+	if logger == nil {
+		logger = LoggerPf{}
+	}
+	logger.Printf(format, args...)
+}
@@ -0,0 +1,125 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/la"
+)
+
+// EdgeGradient returns the (mimetic) gradient operator mapping node values to edge values on a
+// rectangular grid (2D or 3D)
+//
+//           u[B] - u[A]
+//   g[e] = —————————————     where A and B are the first and second nodes of edge e
+//              h[e]
+//
+//   The edges are numbered by direction (x first, then y and z) and, within each direction, in the
+//   order of their first nodes (see GridEdges). The length h[e] is the distance between the nodes
+//
+//   Output:
+//     G -- [nedges][nnodes] gradient operator
+func EdgeGradient(g *gm.Grid) (G *la.Triplet) {
+	A, B, h := GridEdges(g)
+	G = la.NewTriplet(len(A), g.Size(), 2*len(A))
+	for e := range A {
+		G.Put(e, A[e], -1.0/h[e])
+		G.Put(e, B[e], +1.0/h[e])
+	}
+	return
+}
+
+// EdgeDivergence returns the (mimetic) divergence operator mapping edge values to node values on
+// a rectangular grid (2D or 3D); i.e. the negative adjoint of EdgeGradient: D = -Gᵀ
+//
+//            q[e⁺] - q[e⁻]
+//   d[I] = Σ —————————————     for each direction, where e⁻ and e⁺ are the edges before and after I
+//                 h[e]
+//
+//   The fluxes through the missing edges at the boundaries are zero (impermeable boundaries)
+//
+//   Output:
+//     D -- [nnodes][nedges] divergence operator
+func EdgeDivergence(g *gm.Grid) (D *la.Triplet) {
+	A, B, h := GridEdges(g)
+	D = la.NewTriplet(g.Size(), len(A), 2*len(A))
+	for e := range A {
+		D.Put(A[e], e, +1.0/h[e])
+		D.Put(B[e], e, -1.0/h[e])
+	}
+	return
+}
+
+// MimeticLaplacian assembles the Laplacian as the composition of EdgeDivergence and EdgeGradient
+//
+//   L = D ⋅ K ⋅ G     with     K = diag(k[e])  and  k[e] = kx, ky or kz according to the direction
+//
+//   Thus, L is exactly symmetric and negative semi-definite (with constant functions in the null
+//   space); i.e. negative definite after the elimination of prescribed values. At interior nodes,
+//   L is the standard 5-point (2D) or 7-point (3D) stencil. At boundary nodes, L corresponds to the
+//   impermeable condition with the flux balance of the half cell divided by the full spacing; i.e.
+//   half of the ghost-node stencil of FdmLaplacian
+//
+//   Input:
+//     g -- rectangular grid
+//     k -- [ndim] coefficients kx, ky (and kz)
+//   Output:
+//     L -- [nnodes][nnodes] Laplacian
+func MimeticLaplacian(g *gm.Grid, k []float64) (L *la.Triplet) {
+	if len(k) != g.Ndim() {
+		chk.Panic("the number of coefficients must be equal to ndim = %d. len(k) = %d is invalid\n", g.Ndim(), len(k))
+	}
+	A, B, h := GridEdges(g)
+	L = la.NewTriplet(g.Size(), g.Size(), 4*len(A))
+	for e := range A {
+		c := k[edgeDirection(g, e)] / (h[e] * h[e])
+		L.Put(A[e], A[e], -c)
+		L.Put(A[e], B[e], +c)
+		L.Put(B[e], A[e], +c)
+		L.Put(B[e], B[e], -c)
+	}
+	return
+}
+
+// GridEdges returns the edges between neighbouring nodes of a rectangular grid (see EdgeGradient)
+//   A, B -- [nedges] first and second nodes of each edge; B follows A along the edge direction
+//   h    -- [nedges] lengths of edges
+func GridEdges(g *gm.Grid) (A, B []int, h []float64) {
+	npts := []int{g.Npts(0), g.Npts(1), g.Npts(2)} // npts[2] = 1 in 2D
+	for dim := 0; dim < g.Ndim(); dim++ {
+		for p := 0; p < npts[2]; p++ {
+			for n := 0; n < npts[1]; n++ {
+				for m := 0; m < npts[0]; m++ {
+					idx := []int{m, n, p}
+					if idx[dim] == npts[dim]-1 {
+						continue
+					}
+					I := g.IndexMNPtoI(m, n, p)
+					idx[dim]++
+					_, hp := g.Spacings(dim, I)
+					A = append(A, I)
+					B = append(B, g.IndexMNPtoI(idx[0], idx[1], idx[2]))
+					h = append(h, hp)
+				}
+			}
+		}
+	}
+	return
+}
+
+// edgeDirection returns the direction of edge e (see GridEdges)
+func edgeDirection(g *gm.Grid, e int) (dim int) {
+	npts := []int{g.Npts(0), g.Npts(1), g.Npts(2)} // npts[2] = 1 in 2D
+	for dim = 0; dim < g.Ndim(); dim++ {
+		nedges := (npts[0] * npts[1] * npts[2] / npts[dim]) * (npts[dim] - 1)
+		if e < nedges {
+			return
+		}
+		e -= nedges
+	}
+	chk.Panic("edge %d is out of range\n", e)
+	return
+}
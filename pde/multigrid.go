@@ -0,0 +1,566 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/la"
+)
+
+// GridTransfer defines the transfer (restriction/prolongation) operators between a fine grid and
+// a coarse grid in multigrid. The coarse grid is obtained by removing every other node; i.e. the
+// coarse node (M,N) coincides with the fine node (2M,2N). With semi-coarsening, the nodes are
+// removed along one direction only; e.g. (M,N) coincides with (2M,N). The vectors hold values at
+// all nodes.
+type GridTransfer interface {
+	Restrict(fine, coarse la.Vector, gFine, gCoarse *gm.Grid) // coarse := R ⋅ fine
+	Prolong(coarse, fine la.Vector, gCoarse, gFine *gm.Grid)  // fine := P ⋅ coarse
+}
+
+// TransferFullWeighting implements full-weighting restriction and bilinear prolongation
+//
+//   Restriction stencil:         Prolongation: bilinear interpolation
+//           ┌          ┐
+//       1   │ 1  2  1  │
+//      ———  │ 2  4  2  │
+//       16  │ 1  2  1  │
+//           └          ┘
+//
+//  NOTE: fine nodes outside the grid are mirrored with respect to the boundary
+type TransferFullWeighting struct{}
+
+// TransferInjection implements injection restriction (coarse value := fine value) and bilinear prolongation
+type TransferInjection struct{}
+
+// Restrict implements full-weighting restriction
+//   NOTE: with semi-coarsening, the 1D weights [1 2 1]/4 are used along the coarsened direction
+func (o TransferFullWeighting) Restrict(fine, coarse la.Vector, gFine, gCoarse *gm.Grid) {
+	fx, fy := coarseningFactors(gFine, gCoarse)
+	rx, ry := fx-1, fy-1 // radius of stencil: 0 or 1
+	den := float64(fx * fx * fy * fy)
+	for N := 0; N < gCoarse.Npts(1); N++ {
+		for M := 0; M < gCoarse.Npts(0); M++ {
+			m, n := fx*M, fy*N
+			sum := 0.0
+			for dn := -ry; dn <= ry; dn++ {
+				for dm := -rx; dm <= rx; dm++ {
+					w := float64((fx - dm*dm) * (fy - dn*dn)) // 4, 2 or 1
+					sum += w * fine[mirroredNode(gFine, m, n, dm, dn)]
+				}
+			}
+			coarse[gCoarse.IndexMNPtoI(M, N, 0)] = sum / den
+		}
+	}
+}
+
+// Prolong implements bilinear prolongation
+func (o TransferFullWeighting) Prolong(coarse, fine la.Vector, gCoarse, gFine *gm.Grid) {
+	prolongBilinear(coarse, fine, gCoarse, gFine)
+}
+
+// Restrict implements injection restriction
+func (o TransferInjection) Restrict(fine, coarse la.Vector, gFine, gCoarse *gm.Grid) {
+	fx, fy := coarseningFactors(gFine, gCoarse)
+	for N := 0; N < gCoarse.Npts(1); N++ {
+		for M := 0; M < gCoarse.Npts(0); M++ {
+			coarse[gCoarse.IndexMNPtoI(M, N, 0)] = fine[gFine.IndexMNPtoI(fx*M, fy*N, 0)]
+		}
+	}
+}
+
+// Prolong implements bilinear prolongation
+func (o TransferInjection) Prolong(coarse, fine la.Vector, gCoarse, gFine *gm.Grid) {
+	prolongBilinear(coarse, fine, gCoarse, gFine)
+}
+
+// FdmMultigrid implements a geometric multigrid solver (V-cycles) for the FDM Laplacian (2D)
+//
+//  The hierarchy of grids is generated by coarsening the grid of the operator by a factor
+//  of 2 along each direction (re-discretisation). Thus, the number of intervals along each
+//  direction must be divisible by 2^(nlevels-1). The essential boundary conditions are
+//  applied to the nodes of the coarse grids coinciding with prescribed nodes of the fine grid.
+//  The grid of the operator must be uniform (along each direction); stretched grids (e.g. from
+//  RectSet2d) are rejected since the coarse grids are generated with uniform spacings. The
+//  stencils of all levels are built from the constant coefficients kx and ky; thus, only
+//  essential and natural boundary conditions are supported: the operators with kxy, reaction
+//  terms, Robin conditions, coefficient fields, penalty, interface conditions, signed distance
+//  functions or the Mehrstellen stencil are rejected.
+//
+//  For anisotropic operators, the point smoothers only smooth the error along the direction of
+//  strong coupling; thus, full coarsening stalls. In this case, semi-coarsening may be used (see
+//  NewFdmMultigridSemiCoarsening): at each level, only the direction with the strongest coupling
+//  k/h² is coarsened (both directions are coarsened if the couplings differ by less than 2×).
+//
+//  The smoother is selected by Smoother:
+//    "gs"    -- (lexicographic) Gauss-Seidel method [default]
+//    "cheby" -- Chebyshev polynomial (Jacobi-preconditioned); i.e. only matrix-vector products are
+//               required. The degree of the polynomial is equal to the number of smoothing iterations
+//               and the eigenvalues of D⁻¹⋅A targeted by the polynomial are in [EigMin, EigMax]
+//
+//  The coarsest level is solved by performing many Gauss-Seidel iterations.
+type FdmMultigrid struct {
+
+	// configuration
+	Transfer    GridTransfer // restriction and prolongation operators
+	Smoother    string       // smoother: "gs" or "cheby"
+	NpreSmooth  int          // number of pre-smoothing iterations
+	NpostSmooth int          // number of post-smoothing iterations
+	NcoarseIt   int          // number of Gauss-Seidel iterations at the coarsest level
+	Tol         float64      // tolerance on the residual norm relative to the initial residual norm
+	MaxIt       int          // maximum number of V-cycles
+	Logger      Logger       // logger for messages [may be nil ⇒ LoggerPf]
+	EigMax      float64      // Chebyshev: upper bound of eigenvalues of D⁻¹⋅A [0 ⇒ spectral radius estimate]
+	EigMin      float64      // Chebyshev: lower bound of eigenvalues of D⁻¹⋅A to be damped [0 ⇒ EigMax/4]
+
+	// results
+	Residuals []float64           // history of residual norms (max norm); Residuals[0] is the initial residual
+	History   *ConvergenceHistory // relative residuals (2-norm) and times of each V-cycle (see ConvergenceHistory)
+
+	// internal
+	op     *FdmLaplacian // operator on the finest grid
+	levels []*mgLevel    // [nlevels] levels; 0 is the finest
+}
+
+// mgLevel holds data for one level of multigrid
+type mgLevel struct {
+	grid    *gm.Grid  // grid
+	fixed   []bool    // [nnodes] node has prescribed value
+	α, β, γ float64   // stencil coefficients: centre, x-neighbours and y-neighbours
+	u, b, r la.Vector // [nnodes] solution (or correction), right-hand side and residual
+	d       la.Vector // [nnodes] Chebyshev: update direction
+}
+
+// NewFdmMultigridSolver creates a new multigrid solver for the FDM Laplacian operator
+//   op       -- the operator (on the finest grid) with essential boundary conditions already set
+//   nlevels  -- number of levels (≥ 2)
+//   transfer -- transfer operators [may be nil ⇒ TransferFullWeighting]
+func NewFdmMultigridSolver(op *FdmLaplacian, nlevels int, transfer GridTransfer) (o *FdmMultigrid) {
+	return newFdmMultigrid(op, nlevels, transfer, false)
+}
+
+// NewFdmMultigridSemiCoarsening creates a new multigrid solver for the FDM Laplacian operator
+// using semi-coarsening; e.g. for strongly anisotropic operators
+//   op       -- the operator (on the finest grid) with essential boundary conditions already set
+//   nlevels  -- number of levels (≥ 2)
+//   transfer -- transfer operators [may be nil ⇒ TransferFullWeighting]
+//   NOTE: the number of intervals along the coarsened directions must be even at each level, with
+//         at least 2 intervals on the coarsest grid; the other directions are not restricted
+func NewFdmMultigridSemiCoarsening(op *FdmLaplacian, nlevels int, transfer GridTransfer) (o *FdmMultigrid) {
+	return newFdmMultigrid(op, nlevels, transfer, true)
+}
+
+// newFdmMultigrid creates a new multigrid solver with full coarsening or semi-coarsening
+func newFdmMultigrid(op *FdmLaplacian, nlevels int, transfer GridTransfer, semi bool) (o *FdmMultigrid) {
+
+	// check
+	if op.Grid.Ndim() != 2 {
+		chk.Panic("FdmMultigrid works in 2D only\n")
+	}
+	if !uniformGrid(op.Grid) {
+		chk.Panic("FdmMultigrid requires uniform grids\n")
+	}
+	if op.Kxy != 0 {
+		chk.Panic("FdmMultigrid does not support the off-diagonal coefficient kxy\n")
+	}
+	if op.Mehrstellen {
+		chk.Panic("FdmMultigrid does not support the Mehrstellen stencil\n")
+	}
+	if op.Kr != 0 || op.Reaction != nil {
+		chk.Panic("FdmMultigrid does not support the reaction term\n")
+	}
+	if len(op.RobinBcs.items) > 0 {
+		chk.Panic("FdmMultigrid does not support Robin boundary conditions\n")
+	}
+	if op.kField != nil || op.kTensor != nil {
+		chk.Panic("FdmMultigrid does not support the coefficient fields\n")
+	}
+	if op.penalty > 0 {
+		chk.Panic("FdmMultigrid does not support the penalty method for essential conditions\n")
+	}
+	if op.jumps != nil {
+		chk.Panic("FdmMultigrid does not support interface conditions\n")
+	}
+	if op.sdf {
+		chk.Panic("FdmMultigrid does not support domains restricted by signed distance functions\n")
+	}
+	if nlevels < 2 {
+		chk.Panic("the number of levels must be at least 2. nlevels=%d is invalid\n", nlevels)
+	}
+	factor := 1 << uint(nlevels-1)
+	for i := 0; i < 2 && !semi; i++ {
+		if (op.Grid.Npts(i)-1)%factor != 0 || (op.Grid.Npts(i)-1)/factor < 2 {
+			chk.Panic("the number of intervals along direction %d (=%d) must be divisible by 2^(nlevels-1)=%d with at least 2 intervals on the coarsest grid\n", i, op.Grid.Npts(i)-1, factor)
+		}
+	}
+
+	// configuration
+	o = new(FdmMultigrid)
+	o.Transfer = transfer
+	if o.Transfer == nil {
+		o.Transfer = TransferFullWeighting{}
+	}
+	o.Smoother = "gs"
+	o.NpreSmooth = 2
+	o.NpostSmooth = 2
+	o.NcoarseIt = 50
+	o.Tol = 1e-10
+	o.MaxIt = 100
+
+	// levels
+	o.op = op
+	o.levels = make([]*mgLevel, nlevels)
+	xmin := []float64{op.Grid.Xmin(0), op.Grid.Xmin(1)}
+	xmax := []float64{op.Grid.Xmax(0), op.Grid.Xmax(1)}
+	for l := 0; l < nlevels; l++ {
+		var g *gm.Grid
+		fx, fy := 2, 2 // coarsening factors
+		if l == 0 {
+			g = op.Grid
+		} else {
+			fine := o.levels[l-1]
+			if semi {
+				if fine.β > 2.0*fine.γ {
+					fy = 1
+				} else if fine.γ > 2.0*fine.β {
+					fx = 1
+				}
+				for i, f := range []int{fx, fy} {
+					nint := fine.grid.Npts(i) - 1
+					if f == 2 && (nint%2 != 0 || nint/2 < 2) {
+						chk.Panic("cannot coarsen direction %d at level %d: the number of intervals (=%d) must be even with at least 2 intervals on the coarse grid\n", i, l, nint)
+					}
+				}
+			}
+			g = new(gm.Grid)
+			g.RectGenUniform(xmin, xmax, []int{(fine.grid.Npts(0)-1)/fx + 1, (fine.grid.Npts(1)-1)/fy + 1})
+		}
+		lev := &mgLevel{grid: g, fixed: make([]bool, g.Size())}
+		lev.u = la.NewVector(g.Size())
+		lev.b = la.NewVector(g.Size())
+		lev.r = la.NewVector(g.Size())
+		lev.d = la.NewVector(g.Size())
+		dx := g.Xlen(0) / float64(g.Npts(0)-1)
+		dy := g.Xlen(1) / float64(g.Npts(1)-1)
+		lev.β = op.Kx / (dx * dx)
+		lev.γ = op.Ky / (dy * dy)
+		lev.α = -2.0 * (lev.β + lev.γ)
+		if l == 0 {
+			for I := 0; I < g.Size(); I++ {
+				lev.fixed[I] = op.EssenBcs.Has(I)
+			}
+		} else {
+			fine := o.levels[l-1]
+			for N := 0; N < g.Npts(1); N++ {
+				for M := 0; M < g.Npts(0); M++ {
+					lev.fixed[g.IndexMNPtoI(M, N, 0)] = fine.fixed[fine.grid.IndexMNPtoI(fx*M, fy*N, 0)]
+				}
+			}
+		}
+		o.levels[l] = lev
+	}
+	return
+}
+
+// Solve solves the problem [K]⋅{u} = {f} using V-cycles
+//   Output:
+//     u   -- [nnodes] solution at all nodes of the grid
+//     nit -- number of V-cycles performed
+//   NOTE: panics if the residual cannot be reduced by Tol after MaxIt cycles
+func (o *FdmMultigrid) Solve() (u []float64, nit int) {
+
+	// check
+	if o.Smoother != "gs" && o.Smoother != "cheby" {
+		chk.Panic("smoother %q is not available. options are \"gs\" and \"cheby\"\n", o.Smoother)
+	}
+
+	// initial values and right-hand side
+	fine := o.levels[0]
+	for I := 0; I < fine.grid.Size(); I++ {
+		fine.u[I] = 0
+		fine.b[I] = 0
+		if fine.fixed[I] {
+			fine.u[I] = o.op.calcXk(I, 0)
+		} else {
+			fine.b[I] = o.op.calcBu(I, 0)
+		}
+	}
+
+	// iterations
+	o.Residuals = []float64{fine.residual()}
+	r0 := o.Residuals[0]
+	bnorm := fine.r.Norm() // the initial values are zero at free nodes; thus {r} = {bu}
+	if bnorm == 0 {
+		bnorm = 1
+	}
+	o.History = newConvergenceHistory("multigrid", fine.r.Norm()/bnorm)
+	if r0 == 0 {
+		return fine.u.GetCopy(), 0
+	}
+	for nit = 1; nit <= o.MaxIt; nit++ {
+		o.vcycle(0)
+		o.Residuals = append(o.Residuals, fine.residual())
+		o.History.Append(nit, fine.r.Norm()/bnorm)
+		logf(o.Logger, "FdmMultigrid: cycle %3d: residual = %g\n", nit, o.Residuals[nit])
+		if o.Residuals[nit] <= o.Tol*r0 {
+			return fine.u.GetCopy(), nit
+		}
+	}
+	chk.Panic("multigrid did not converge after %d V-cycles. residual = %g\n", o.MaxIt, o.Residuals[o.MaxIt])
+	return
+}
+
+// vcycle performs one V-cycle starting at level l
+func (o *FdmMultigrid) vcycle(l int) {
+	lev := o.levels[l]
+	if l == len(o.levels)-1 {
+		lev.smoothGS(o.NcoarseIt)
+		return
+	}
+	o.smooth(lev, o.NpreSmooth)
+	lev.residual()
+	coarse := o.levels[l+1]
+	o.Transfer.Restrict(lev.r, coarse.b, lev.grid, coarse.grid)
+	for I := 0; I < coarse.grid.Size(); I++ {
+		coarse.u[I] = 0
+		if coarse.fixed[I] {
+			coarse.b[I] = 0
+		}
+	}
+	o.vcycle(l + 1)
+	o.Transfer.Prolong(coarse.u, lev.r, coarse.grid, lev.grid) // using r as workspace
+	for I := 0; I < lev.grid.Size(); I++ {
+		if !lev.fixed[I] {
+			lev.u[I] += lev.r[I]
+		}
+	}
+	o.smooth(lev, o.NpostSmooth)
+}
+
+// smooth applies the selected smoother to level
+func (o *FdmMultigrid) smooth(lev *mgLevel, nit int) {
+	if o.Smoother == "cheby" {
+		λmax := o.EigMax
+		if λmax == 0 {
+			λmax = lev.spectralRadius()
+		}
+		λmin := o.EigMin
+		if λmin == 0 {
+			λmin = λmax / 4.0
+		}
+		lev.smoothCheby(nit, λmin, λmax)
+		return
+	}
+	lev.smoothGS(nit)
+}
+
+// spectralRadius returns an estimate (Gershgorin bound) of the spectral radius of D⁻¹⋅A
+func (o *mgLevel) spectralRadius() float64 {
+	return (math.Abs(o.α) + 2.0*math.Abs(o.β) + 2.0*math.Abs(o.γ)) / math.Abs(o.α)
+}
+
+// smoothCheby applies the Chebyshev polynomial of degree nit for D⁻¹⋅A targeting the eigenvalues in [λmin, λmax]
+func (o *mgLevel) smoothCheby(nit int, λmin, λmax float64) {
+	if nit < 1 {
+		return
+	}
+	θ := (λmax + λmin) / 2.0
+	δ := (λmax - λmin) / 2.0
+	σ := θ / δ
+	ρ0 := 1.0 / σ
+	o.residual()
+	for I := 0; I < o.grid.Size(); I++ {
+		o.d[I] = o.r[I] / (o.α * θ)
+	}
+	for k := 0; k < nit; k++ {
+		for I := 0; I < o.grid.Size(); I++ {
+			o.u[I] += o.d[I]
+		}
+		if k == nit-1 {
+			break
+		}
+		o.residual()
+		ρ1 := 1.0 / (2.0*σ - ρ0)
+		for I := 0; I < o.grid.Size(); I++ {
+			o.d[I] = ρ1*ρ0*o.d[I] + 2.0*ρ1*o.r[I]/(o.α*δ)
+		}
+		ρ0 = ρ1
+	}
+}
+
+// smoothGS performs Gauss-Seidel iterations at free nodes
+func (o *mgLevel) smoothGS(nit int) {
+	nx, ny := o.grid.Npts(0), o.grid.Npts(1)
+	for it := 0; it < nit; it++ {
+		for n := 0; n < ny; n++ {
+			for m := 0; m < nx; m++ {
+				I := o.grid.IndexMNPtoI(m, n, 0)
+				if o.fixed[I] {
+					continue
+				}
+				sum := o.β*(o.u[mirroredNode(o.grid, m, n, -1, 0)]+o.u[mirroredNode(o.grid, m, n, +1, 0)]) +
+					o.γ*(o.u[mirroredNode(o.grid, m, n, 0, -1)]+o.u[mirroredNode(o.grid, m, n, 0, +1)])
+				o.u[I] = (o.b[I] - sum) / o.α
+			}
+		}
+	}
+}
+
+// residual computes r = b - A⋅u at free nodes (zero at fixed nodes) and returns its max norm
+func (o *mgLevel) residual() (rmax float64) {
+	nx, ny := o.grid.Npts(0), o.grid.Npts(1)
+	for n := 0; n < ny; n++ {
+		for m := 0; m < nx; m++ {
+			I := o.grid.IndexMNPtoI(m, n, 0)
+			o.r[I] = 0
+			if o.fixed[I] {
+				continue
+			}
+			au := o.α*o.u[I] +
+				o.β*(o.u[mirroredNode(o.grid, m, n, -1, 0)]+o.u[mirroredNode(o.grid, m, n, +1, 0)]) +
+				o.γ*(o.u[mirroredNode(o.grid, m, n, 0, -1)]+o.u[mirroredNode(o.grid, m, n, 0, +1)])
+			o.r[I] = o.b[I] - au
+			rmax = math.Max(rmax, math.Abs(o.r[I]))
+		}
+	}
+	return
+}
+
+// TwoGridCorrect performs one coarse-grid correction step; i.e. restricts the residual, solves the
+// coarse problem directly, prolongs the coarse solution and corrects the fine approximation
+//
+//   Input:
+//     fine     -- operator on the fine grid (2D)
+//     coarse   -- operator on the coarse grid; the coarse nodes must coincide with every other node
+//                 of the fine grid and Assemble must be called first
+//     transfer -- transfer operators [may be nil ⇒ TransferFullWeighting]
+//     u        -- [nnodes fine] approximation
+//     residual -- [nnodes fine] residual r = b - A⋅u (zero at nodes with prescribed values)
+//   Output:
+//     u -- corrected approximation (nodes with prescribed values of the fine grid are not modified)
+//
+//   NOTE: the coarse problem is solved with homogeneous essential boundary conditions
+func TwoGridCorrect(fine, coarse *FdmLaplacian, transfer GridTransfer, u, residual la.Vector) {
+
+	// check
+	if fine.Grid.Ndim() != 2 || coarse.Grid.Ndim() != 2 {
+		chk.Panic("TwoGridCorrect works in 2D only\n")
+	}
+	for i := 0; i < 2; i++ {
+		if fine.Grid.Npts(i)-1 != 2*(coarse.Grid.Npts(i)-1) {
+			chk.Panic("the number of intervals of the fine grid along direction %d must be twice the number of intervals of the coarse grid. %d != 2 × %d\n", i, fine.Grid.Npts(i)-1, coarse.Grid.Npts(i)-1)
+		}
+	}
+	if len(u) != fine.Grid.Size() || len(residual) != fine.Grid.Size() {
+		chk.Panic("vectors must have length equal to the number of nodes of the fine grid (%d). len(u)=%d, len(residual)=%d\n", fine.Grid.Size(), len(u), len(residual))
+	}
+	if coarse.Eqs == nil {
+		chk.Panic("Assemble must be called for the coarse operator first\n")
+	}
+	if transfer == nil {
+		transfer = TransferFullWeighting{}
+	}
+
+	// restrict
+	rc := la.NewVector(coarse.Grid.Size())
+	transfer.Restrict(residual, rc, fine.Grid, coarse.Grid)
+
+	// solve coarse problem
+	calcXk := func(I int, t float64) float64 { return 0 }
+	calcBu := func(I int, t float64) float64 { return rc[I] }
+	coarse.Eqs.SolveOnce(calcXk, calcBu)
+	ec := la.NewVector(coarse.Grid.Size())
+	coarse.Eqs.JoinVector(ec, coarse.Eqs.Xu, coarse.Eqs.Xk)
+
+	// prolong and correct
+	ef := la.NewVector(fine.Grid.Size())
+	transfer.Prolong(ec, ef, coarse.Grid, fine.Grid)
+	for I := 0; I < fine.Grid.Size(); I++ {
+		if !fine.EssenBcs.Has(I) {
+			u[I] += ef[I]
+		}
+	}
+}
+
+// RestrictSolution transfers a node-based field from a fine grid onto a coarse grid using
+// full-weighting restriction (see TransferFullWeighting); e.g. for diagnostics or output at
+// lower resolution
+//
+//   Input:
+//     fineGrid   -- fine 2D grid
+//     coarseGrid -- coarse 2D grid covering the same domain with half the number of intervals
+//     uFine      -- [fineGrid.Size()] field at the nodes of the fine grid
+//   Output:
+//     uCoarse -- [coarseGrid.Size()] restricted field
+//
+//   NOTE: the fine nodes outside the grid are mirrored; thus, the restricted values at
+//         boundaries are O(h) away from the fine values unless the normal derivative vanishes
+func RestrictSolution(fineGrid, coarseGrid *gm.Grid, uFine []float64) (uCoarse []float64) {
+	if fineGrid.Ndim() != 2 || coarseGrid.Ndim() != 2 {
+		chk.Panic("RestrictSolution works in 2D only\n")
+	}
+	for i := 0; i < 2; i++ {
+		if fineGrid.Npts(i)-1 != 2*(coarseGrid.Npts(i)-1) {
+			chk.Panic("the number of intervals of the fine grid along direction %d must be twice the number of intervals of the coarse grid. %d != 2 × %d\n", i, fineGrid.Npts(i)-1, coarseGrid.Npts(i)-1)
+		}
+		if fineGrid.Xmin(i) != coarseGrid.Xmin(i) || fineGrid.Xmax(i) != coarseGrid.Xmax(i) {
+			chk.Panic("grids must cover the same domain along direction %d. [%g, %g] != [%g, %g]\n", i, fineGrid.Xmin(i), fineGrid.Xmax(i), coarseGrid.Xmin(i), coarseGrid.Xmax(i))
+		}
+	}
+	if len(uFine) != fineGrid.Size() {
+		chk.Panic("size of field must be equal to the number of nodes of the fine grid. %d != %d\n", len(uFine), fineGrid.Size())
+	}
+	uCoarse = make([]float64, coarseGrid.Size())
+	TransferFullWeighting{}.Restrict(uFine, uCoarse, fineGrid, coarseGrid)
+	return
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// mirroredNode returns the node at (m+dm, n+dn) of a 2D grid, mirroring indices that fall outside the grid
+func mirroredNode(g *gm.Grid, m, n, dm, dn int) int {
+	if m+dm < 0 || m+dm > g.Npts(0)-1 {
+		dm = -dm
+	}
+	if n+dn < 0 || n+dn > g.Npts(1)-1 {
+		dn = -dn
+	}
+	return g.IndexMNPtoI(m+dm, n+dn, 0)
+}
+
+// coarseningFactors returns the ratios (1 or 2) between the numbers of intervals of two grids
+func coarseningFactors(gFine, gCoarse *gm.Grid) (fx, fy int) {
+	f := []int{0, 0}
+	for i := 0; i < 2; i++ {
+		nf, nc := gFine.Npts(i)-1, gCoarse.Npts(i)-1
+		switch {
+		case nf == nc:
+			f[i] = 1
+		case nf == 2*nc:
+			f[i] = 2
+		default:
+			chk.Panic("the number of intervals of the fine grid along direction %d must be equal to or twice the number of intervals of the coarse grid. %d, %d is invalid\n", i, nf, nc)
+		}
+	}
+	return f[0], f[1]
+}
+
+// prolongBilinear implements the bilinear interpolation from coarse to fine grid (linear along
+// the coarsened direction with semi-coarsening)
+func prolongBilinear(coarse, fine la.Vector, gCoarse, gFine *gm.Grid) {
+	fx, fy := coarseningFactors(gFine, gCoarse)
+	for n := 0; n < gFine.Npts(1); n++ {
+		for m := 0; m < gFine.Npts(0); m++ {
+			M0, N0 := m/fx, n/fy
+			M1, N1 := (m+fx-1)/fx, (n+fy-1)/fy
+			fine[gFine.IndexMNPtoI(m, n, 0)] = 0.25 * (coarse[gCoarse.IndexMNPtoI(M0, N0, 0)] +
+				coarse[gCoarse.IndexMNPtoI(M1, N0, 0)] +
+				coarse[gCoarse.IndexMNPtoI(M0, N1, 0)] +
+				coarse[gCoarse.IndexMNPtoI(M1, N1, 0)])
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+)
+
+// OversetSolver solves a steady problem on overlapping (overset or Chimera) grids; e.g. complex
+// geometries composed of simple rectangular grids
+//
+//   The interface nodes of each grid (see AddInterface) receive essential conditions with the
+//   values interpolated (bilinearly) from the solution on another grid (donor) containing them.
+//   The problems are then solved in turn with the latest values of the other grids (alternating
+//   Schwarz method) until the solutions do not change; i.e. the convergence is geometric with a
+//   rate depending on the size of the overlap
+//
+//   NOTE: (1) the grids must be unrotated rectilinear 2D grids (e.g. RectGenUniform or RectSet2d)
+//         (2) if the nodes of the grids coincide in the overlap, the coupled solution is equal to
+//             the solution on a single grid covering the whole domain
+//         (3) remember to call Free() to release allocated resources
+type OversetSolver struct {
+	Ops    []*FdmLaplacian // operators on each grid with the physical boundary conditions set
+	U      [][]float64     // [ngrids][nnodes] solutions
+	Tol    float64         // tolerance on the largest change of the solutions between iterations
+	MaxIt  int             // maximum number of iterations
+	Nit    int             // number of iterations performed by the last Solve
+	Logger Logger          // logger for messages [may be nil ⇒ LoggerPf]
+}
+
+// NewOversetSolver creates a new solver on overlapping grids
+//   ops -- operators on each grid; at least two
+func NewOversetSolver(ops ...*FdmLaplacian) (o *OversetSolver) {
+	if len(ops) < 2 {
+		chk.Panic("at least two grids are required. %d is invalid\n", len(ops))
+	}
+	for i, op := range ops {
+		if op.Grid.Ndim() != 2 || op.Grid.Rotation() != 0 {
+			chk.Panic("OversetSolver works with unrotated 2D grids only. grid %d is invalid\n", i)
+		}
+	}
+	o = &OversetSolver{Ops: ops, Tol: 1e-10, MaxIt: 100}
+	o.U = make([][]float64, len(ops))
+	for i, op := range ops {
+		o.U[i] = make([]float64, op.Grid.Size())
+	}
+	return
+}
+
+// AddInterface sets the nodes of an edge of a grid lying strictly inside another grid as
+// interface nodes; i.e. their values are interpolated from that grid (donor)
+//   igrid -- index of grid in Ops
+//   tag   -- edge tag in grid
+//   NOTE: (1) call after setting the physical conditions; the interpolated values replace the
+//             existing conditions at the interface nodes and the other nodes of the edge keep
+//             their conditions
+//         (2) the first grid in Ops containing the node is the donor
+func (o *OversetSolver) AddInterface(igrid, tag int) {
+	g := o.Ops[igrid].Grid
+	var nodes []int
+	for _, I := range g.Boundary(tag) {
+		donor := o.donor(igrid, g.Node(I))
+		if donor >= 0 {
+			nodes = append(nodes, I)
+		}
+	}
+	if len(nodes) == 0 {
+		chk.Panic("edge %d of grid %d does not have nodes inside other grids\n", tag, igrid)
+	}
+	interp := func(x la.Vector, t float64) float64 {
+		d := o.donor(igrid, x)
+		return bilinearInterp(o.Ops[d].Grid.Coords(0), o.Ops[d].Grid.Coords(1), o.U[d], x)
+	}
+	o.Ops[igrid].AddEbcNodes(nodes, 0, interp)
+}
+
+// Solve assembles the operators and iterates until the largest change of the solutions is
+// smaller than Tol⋅(1 + max|u|). The values in U are used as initial guess. Panics if the
+// iterations do not converge
+func (o *OversetSolver) Solve() {
+	for _, op := range o.Ops {
+		if err := op.Assemble(false); err != nil {
+			chk.Panic("%v\n", err)
+		}
+	}
+	for o.Nit = 1; o.Nit <= o.MaxIt; o.Nit++ {
+		change, umax := 0.0, 0.0
+		for i, op := range o.Ops {
+			u := op.ReapplyBcs()
+			for I, v := range u {
+				change = math.Max(change, math.Abs(v-o.U[i][I]))
+				umax = math.Max(umax, math.Abs(v))
+			}
+			o.U[i] = u
+		}
+		logf(o.Logger, "OversetSolver: iteration %d: change = %g\n", o.Nit, change)
+		if change <= o.Tol*(1.0+umax) {
+			return
+		}
+	}
+	chk.Panic("OversetSolver did not converge after %d iterations\n", o.MaxIt)
+}
+
+// Free releases allocated resources
+func (o *OversetSolver) Free() {
+	for _, op := range o.Ops {
+		op.Free()
+	}
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// donor returns the first grid (other than igrid) containing x strictly inside; or -1 if none
+func (o *OversetSolver) donor(igrid int, x la.Vector) int {
+	for d, op := range o.Ops {
+		if d == igrid {
+			continue
+		}
+		g := op.Grid
+		inside := true
+		for dim := 0; dim < 2; dim++ {
+			tol := 1e-10 * g.Xlen(dim)
+			if x[dim] <= g.Xmin(dim)+tol || x[dim] >= g.Xmax(dim)-tol {
+				inside = false
+			}
+		}
+		if inside {
+			return d
+		}
+	}
+	return -1
+}
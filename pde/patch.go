@@ -0,0 +1,114 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// SolvePatch solves a refined subproblem on a rectangular patch of the domain (local refinement)
+// with essential conditions interpolated from the coarse solution
+//
+//   The patch is enlarged to the nearest lines of the coarse grid; then, each coarse interval
+//   within the patch is divided into refine intervals. Thus, the coarse nodes are also nodes of
+//   the patch grid and stretched coarse grids are supported. The values at the boundary of the
+//   patch are given by the bilinear interpolation of the coarse solution
+//
+//   Input:
+//     coarse  -- assembled coarse operator (2D)
+//     uCoarse -- [nnodes] coarse solution [may be nil ⇒ computed with coarse.SolveSteady]
+//     xmin    -- [2] min x-y values of patch
+//     xmax    -- [2] max x-y values of patch
+//     refine  -- refinement factor ≥ 1
+//   Output:
+//     patch -- assembled operator on the patch grid; see patch.Grid
+//     u     -- [patch.Grid.Size()] solution on the patch
+//
+//   NOTE: (1) the coefficients, the reaction and the source functions of the coarse operator are
+//             used on the patch; the sampled sources (SetSourceVector and AddPointSource) and the
+//             coefficient fields are not transferred
+//         (2) the interpolated values are also prescribed where the patch touches the boundary of
+//             the domain; i.e. natural conditions are not transferred
+func SolvePatch(coarse *FdmLaplacian, uCoarse, xmin, xmax []float64, refine int) (patch *FdmLaplacian, u []float64) {
+
+	// check
+	g := coarse.Grid
+	if g.Ndim() != 2 || g.Rotation() != 0 {
+		chk.Panic("SolvePatch works with unrotated 2D grids only\n")
+	}
+	if len(xmin) != 2 || len(xmax) != 2 {
+		chk.Panic("the patch box must be 2D. len(xmin)=%d, len(xmax)=%d\n", len(xmin), len(xmax))
+	}
+	if refine < 1 {
+		chk.Panic("refinement factor must be at least 1. refine = %d is invalid\n", refine)
+	}
+
+	// coarse solution
+	if uCoarse == nil {
+		uCoarse, _ = coarse.SolveSteady(false)
+	}
+	if len(uCoarse) != g.Size() {
+		chk.Panic("size of uCoarse must be equal to the number of nodes. %d != %d\n", len(uCoarse), g.Size())
+	}
+
+	// patch grid
+	coords := make([][]float64, 2)
+	for dim := 0; dim < 2; dim++ {
+		X := g.Coords(dim)
+		i0 := sort.SearchFloat64s(X, xmin[dim]+1e-12*g.Xlen(dim)) - 1 // last line ≤ xmin
+		i1 := sort.SearchFloat64s(X, xmax[dim]-1e-12*g.Xlen(dim))     // first line ≥ xmax
+		i0, i1 = utl.Imax(i0, 0), utl.Imin(i1, len(X)-1)
+		if i1 <= i0 {
+			chk.Panic("the patch box must overlap the domain and have a positive size along direction %d\n", dim)
+		}
+		for i := i0; i < i1; i++ {
+			for k := 0; k < refine; k++ {
+				coords[dim] = append(coords[dim], X[i]+(X[i+1]-X[i])*float64(k)/float64(refine))
+			}
+		}
+		coords[dim] = append(coords[dim], X[i1])
+	}
+	pg := new(gm.Grid)
+	pg.RectSet2d(coords[0], coords[1])
+
+	// operator
+	params := dbf.Params{{N: "kx", V: coarse.Kx}, {N: "ky", V: coarse.Ky}, {N: "kxy", V: coarse.Kxy}, {N: "kr", V: coarse.Kr}}
+	patch = NewFdmLaplacian(params, pg, coarse.Source)
+	patch.Reaction = coarse.Reaction
+	patch.Logger = coarse.Logger
+	patch.Ordering = coarse.Ordering
+	X, Y := g.Coords(0), g.Coords(1)
+	value := func(x la.Vector, t float64) float64 { return bilinearInterp(X, Y, uCoarse, x) }
+	for _, tag := range []int{10, 11, 20, 21} {
+		patch.AddEbc(tag, 0, value)
+	}
+
+	// solve
+	if err := patch.Assemble(false); err != nil {
+		chk.Panic("%v\n", err)
+	}
+	u, _ = patch.SolveSteady(false)
+	return
+}
+
+// bilinearInterp interpolates the node values of a rectangular grid with coordinates X and Y
+func bilinearInterp(X, Y, u []float64, x la.Vector) float64 {
+	cell := func(C []float64, c float64) (i int, ξ float64) {
+		i = utl.Imin(utl.Imax(sort.SearchFloat64s(C, c)-1, 0), len(C)-2)
+		ξ = math.Max(0, math.Min(1, (c-C[i])/(C[i+1]-C[i])))
+		return
+	}
+	i, ξ := cell(X, x[0])
+	j, η := cell(Y, x[1])
+	nx := len(X)
+	return (1-ξ)*(1-η)*u[i+j*nx] + ξ*(1-η)*u[i+1+j*nx] + (1-ξ)*η*u[i+(j+1)*nx] + ξ*η*u[i+1+(j+1)*nx]
+}
@@ -0,0 +1,138 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+)
+
+// edgeTags maps names of edges of rectangular grids to tags
+var edgeTags = map[string]int{"xmin": 10, "xmax": 11, "ymin": 20, "ymax": 21, "left": 10, "right": 11, "bottom": 20, "top": 21}
+
+// SolvePoisson solves the Poisson equation on a rectangle (2D) in one call
+//
+//    ∂²u     ∂²u
+//    ———  +  ———  =  s({x})
+//    ∂x²     ∂y²
+//
+//   using the FDM Laplacian on a uniform grid (see FdmLaplacian)
+//
+//   Input:
+//     xmin   -- [2] min coordinates
+//     xmax   -- [2] max coordinates
+//     ndiv   -- [2] number of divisions along each direction; i.e. npts = ndiv + 1
+//     source -- source term s({x}) [may be nil ⇒ Laplace equation]
+//     bc     -- prescribed values of u on edges. Keys: "xmin", "xmax", "ymin", "ymax" (aliases:
+//               "left", "right", "bottom", "top"). Edges that are not given have zero normal
+//               derivative
+//   Output:
+//     u    -- [nnodes] solution at the nodes of the grid
+//     grid -- the grid; e.g. to get the coordinates of nodes
+//
+//   Example:
+//     u, g := SolvePoisson([]float64{0, 0}, []float64{1, 1}, []int{10, 10}, nil,
+//         map[string]float64{"left": 1, "right": 0})
+func SolvePoisson(xmin, xmax []float64, ndiv []int, source fun.Svs, bc map[string]float64) (u []float64, grid *gm.Grid) {
+
+	// check
+	if len(xmin) != 2 || len(xmax) != 2 || len(ndiv) != 2 {
+		chk.Panic("SolvePoisson works in 2D only. len(xmin)=%d, len(xmax)=%d and len(ndiv)=%d are invalid\n", len(xmin), len(xmax), len(ndiv))
+	}
+	if len(bc) == 0 {
+		chk.Panic("at least one edge must have a prescribed value\n")
+	}
+
+	// grid
+	grid = new(gm.Grid)
+	grid.RectGenUniform(xmin, xmax, []int{ndiv[0] + 1, ndiv[1] + 1})
+
+	// operator and boundary conditions (sorted names: the last value prescribed at a corner wins)
+	op := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, grid, source)
+	names := make([]string, 0, len(bc))
+	for name := range bc {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		tag, ok := edgeTags[name]
+		if !ok {
+			chk.Panic("edge name %q is invalid\n", name)
+		}
+		op.AddEbc(tag, bc[name], nil)
+	}
+
+	// solve
+	if err := op.Assemble(false); err != nil {
+		chk.Panic("%v\n", err)
+	}
+	u, _ = op.SolveSteady(false)
+	return
+}
+
+// SolveBiharmonicMixed solves the biharmonic equation (2D) with the mixed formulation; i.e. two
+// Poisson problems with the same FDM Laplacian operator instead of the 13-point stencil
+//
+//    L{L{u}} = s({x})    ⇒    L{w} = s({x})   and then   L{u} = w
+//
+//   where L{u} = kx⋅∂²u/∂x² + ky⋅∂²u/∂y² (see FdmLaplacian). For example, the deflection u of a
+//   thin plate with flexural rigidity D loaded by q({x}) is obtained with kx = ky = 1 and s = q/D;
+//   then, w = ∇²u is related to the sum of the bending moments: Mx + My = -D⋅(1+ν)⋅w
+//
+//   The auxiliary field w has essential conditions at the same edges as u. Therefore, the edges
+//   are simply supported (hinged): u and ∇²u are prescribed (e.g. zero deflection and moment).
+//   Edges without conditions have ∂u/∂n = 0 and ∂w/∂n = 0 (as in FdmLaplacian); i.e. symmetry
+//   planes. Clamped edges (u and ∂u/∂n prescribed) cannot be handled since the two problems are
+//   not decoupled in that case
+//
+//   Input:
+//     params -- parameters of the Laplacian: "kx" and "ky"; see NewFdmLaplacian
+//     grid   -- 2D grid
+//     source -- source term s({x}) [may be nil]
+//     tags   -- edges with prescribed u and w; e.g. {10, 11, 20, 21} for a simply supported
+//               rectangular plate
+//     uValue -- prescribed u on the edges [may be nil ⇒ zero]
+//     wValue -- prescribed w = L{u} on the edges [may be nil ⇒ zero]
+//   Output:
+//     u -- [nnodes] solution
+//     w -- [nnodes] auxiliary field L{u}
+//
+//   NOTE: the matrix is factorised once. With u = w = 0 at all edges of uniform grids, the
+//         discrete solution is the same as the solution of the 13-point stencil with antisymmetric
+//         ghost values at the edges (u[-1] = -u[1]); but the system is better conditioned
+func SolveBiharmonicMixed(params dbf.Params, grid *gm.Grid, source fun.Svs, tags []int, uValue, wValue fun.Svs) (u, w []float64) {
+
+	// check
+	if grid.Ndim() != 2 {
+		chk.Panic("SolveBiharmonicMixed works in 2D only\n")
+	}
+	if len(tags) == 0 {
+		chk.Panic("at least one edge must have prescribed values\n")
+	}
+
+	// auxiliary field: L{w} = s
+	op := NewFdmLaplacian(params, grid, source)
+	defer op.Free()
+	for _, tag := range tags {
+		op.AddEbc(tag, 0, wValue)
+	}
+	if err := op.Assemble(false); err != nil {
+		chk.Panic("%v\n", err)
+	}
+	w = op.ReapplyBcs()
+
+	// solution: L{u} = w
+	op.Source = nil
+	op.SetSourceVector(w)
+	for _, tag := range tags {
+		op.UpdateEbc(tag, 0, uValue)
+	}
+	u = op.ReapplyBcs()
+	return
+}
@@ -0,0 +1,182 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/utl"
+)
+
+// LoadCoeffRaster loads a coefficient field defined on a 2D raster; e.g. for heterogeneous media
+// obtained from images (micro-CT scans)
+//
+//   The raster covers the whole domain; i.e. the first and last columns are at xmin and xmax and
+//   the first and last rows are at ymax and ymin (the first row is the top of the image). If the
+//   size of the raster is different from the number of nodes, the values are resampled with
+//   bilinear interpolation
+//
+//   Input:
+//     filename -- "*.png" file with a (grayscale) image: the intensities are mapped to [0, 1];
+//                 otherwise, text file with one row of float values per line; all rows must have
+//                 the same number of values. Empty lines and lines starting with "#" are skipped
+//     grid     -- 2D grid
+//   Output:
+//     kField -- [nnodes] values at nodes; e.g. for AssembleWithCoeffField
+//
+//   NOTE: intensities may be mapped to the actual range of coefficients with kmin + (kmax-kmin)⋅k
+func LoadCoeffRaster(filename string, grid *gm.Grid) (kField []float64) {
+
+	// check
+	if grid.Ndim() != 2 {
+		chk.Panic("LoadCoeffRaster works in 2D only\n")
+	}
+
+	// load raster
+	var raster [][]float64
+	if strings.HasSuffix(strings.ToLower(filename), ".png") {
+		raster = readGrayPng(filename)
+	} else {
+		raster = readTextRaster(filename)
+	}
+	nrow := len(raster)
+	if nrow < 1 {
+		chk.Panic("raster in file <%s> is empty\n", filename)
+	}
+	ncol := len(raster[0])
+
+	// sample (or resample) at nodes
+	nx, ny := grid.Npts(0), grid.Npts(1)
+	kField = make([]float64, grid.Size())
+	for n := 0; n < ny; n++ {
+		for m := 0; m < nx; m++ {
+			c := rasterCoord(m, nx, ncol)
+			r := rasterCoord(ny-1-n, ny, nrow)
+			c0, r0 := int(c), int(r)
+			c1, r1 := utl.Imin(c0+1, ncol-1), utl.Imin(r0+1, nrow-1)
+			s, t := c-float64(c0), r-float64(r0)
+			kField[grid.IndexMNPtoI(m, n, 0)] = (1-s)*(1-t)*raster[r0][c0] + s*(1-t)*raster[r0][c1] +
+				(1-s)*t*raster[r1][c0] + s*t*raster[r1][c1]
+		}
+	}
+	return
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// rasterCoord maps the index i of one of npts nodes to the (fractional) index of one of n pixels
+func rasterCoord(i, npts, n int) float64 {
+	if npts == n {
+		return float64(i)
+	}
+	return math.Min(float64(i)*float64(n-1)/float64(npts-1), float64(n-1))
+}
+
+// readTextRaster reads a text file with one row of values per line; all rows must have the same
+// number of values
+func readTextRaster(filename string) (raster [][]float64) {
+	ncol := 0
+	for i, line := range strings.Split(string(io.ReadFile(filename)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if raster == nil {
+			ncol = len(fields)
+		}
+		if len(fields) != ncol {
+			chk.Panic("row %d (line %d) of raster in file <%s> has %d values; but the first row has %d\n", len(raster)+1, i+1, filename, len(fields), ncol)
+		}
+		row := make([]float64, ncol)
+		for j, f := range fields {
+			v, err := strconv.ParseFloat(f, 64)
+			if err != nil {
+				chk.Panic("cannot parse value %q in row %d (line %d) of raster in file <%s>\n", f, len(raster)+1, i+1, filename)
+			}
+			row[j] = v
+		}
+		raster = append(raster, row)
+	}
+	return
+}
+
+// readGrayPng reads a png file and returns the intensities in [0, 1]
+func readGrayPng(filename string) (raster [][]float64) {
+	file, err := os.Open(filename)
+	if err != nil {
+		chk.Panic("cannot open file <%s>\n", filename)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		chk.Panic("cannot decode png file <%s>: %v\n", filename, err)
+	}
+	b := img.Bounds()
+	raster = make([][]float64, b.Dy())
+	for r := 0; r < b.Dy(); r++ {
+		raster[r] = make([]float64, b.Dx())
+		for c := 0; c < b.Dx(); c++ {
+			g := color.Gray16Model.Convert(img.At(b.Min.X+c, b.Min.Y+r)).(color.Gray16)
+			raster[r][c] = float64(g.Y) / 65535.0
+		}
+	}
+	return
+}
+
+// viridisColours holds samples of the viridis colour map at t = 0, 1/8, 2/8, ..., 1
+var viridisColours = [][3]float64{
+	{68, 1, 84}, {71, 44, 122}, {59, 82, 139}, {44, 113, 142}, {33, 145, 140},
+	{39, 173, 129}, {94, 201, 98}, {170, 220, 50}, {253, 231, 37},
+}
+
+// mapColour maps t ∈ [0, 1] to a colour
+func mapColour(colormap string, t float64) color.RGBA {
+	t = math.Max(0, math.Min(1, t))
+	switch colormap {
+	case "", "viridis":
+		s := t * float64(len(viridisColours)-1)
+		k := utl.Imin(int(s), len(viridisColours)-2)
+		s -= float64(k)
+		a, b := viridisColours[k], viridisColours[k+1]
+		channel := func(i int) uint8 { return uint8(math.Floor((1-s)*a[i] + s*b[i] + 0.5)) }
+		return color.RGBA{R: channel(0), G: channel(1), B: channel(2), A: 255}
+	case "gray":
+		g := uint8(math.Floor(255*t + 0.5))
+		return color.RGBA{R: g, G: g, B: g, A: 255}
+	}
+	chk.Panic("colormap %q is invalid. options: \"viridis\" or \"gray\"\n", colormap)
+	return color.RGBA{}
+}
+
+// writeHeatmapPng writes a png file with the values at the nodes of a 2D grid (one pixel per node)
+func writeHeatmapPng(dirout, fnkey string, grid *gm.Grid, u []float64, colormap string) {
+	umin, umax := utl.MinMax(u)
+	nx, ny := grid.Npts(0), grid.Npts(1)
+	img := image.NewRGBA(image.Rect(0, 0, nx, ny))
+	for n := 0; n < ny; n++ {
+		for m := 0; m < nx; m++ {
+			t := 0.0
+			if umax > umin {
+				t = (u[grid.IndexMNPtoI(m, n, 0)] - umin) / (umax - umin)
+			}
+			img.SetRGBA(m, ny-1-n, mapColour(colormap, t))
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		chk.Panic("cannot encode png file: %v\n", err)
+	}
+	io.WriteFileD(dirout, fnkey+".png", &buf)
+}
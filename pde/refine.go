@@ -0,0 +1,110 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/gm"
+)
+
+// DirectionalIndicators computes error indicators of the intervals of a rectilinear 2D grid along
+// each direction from the jumps of the directional derivatives at the nodes
+//
+//              │ u[i+1] - u[i]     u[i] - u[i-1] │
+//    J_d[i] =  │ ————————————— - ————————————— │       (along direction d)
+//              │     h[i]            h[i-1]      │
+//
+//    η_d[k] = h[k] ⋅ max(J_d[k], J_d[k+1])     (max over the nodes on lines k and k+1)
+//
+//   Thus, η_d ≈ h² |∂²u/∂x_d²| estimates the interpolation error along d and directional features,
+//   such as boundary layers, give large indicators in one direction only
+//
+//   Input:
+//     grid -- rectilinear 2D grid (e.g. RectGenUniform or RectSet2d; unrotated)
+//     u    -- [nnodes] values at nodes
+//   Output:
+//     eta -- [2][npts(dim)-1] indicators of the intervals along each direction
+func DirectionalIndicators(grid *gm.Grid, u []float64) (eta [][]float64) {
+	if grid.Ndim() != 2 || grid.Rotation() != 0 {
+		chk.Panic("DirectionalIndicators works with unrotated 2D grids only\n")
+	}
+	if len(u) != grid.Size() {
+		chk.Panic("size of u must be equal to the number of nodes. %d != %d\n", len(u), grid.Size())
+	}
+	npts := []int{grid.Npts(0), grid.Npts(1)}
+	eta = make([][]float64, 2)
+	for dim := 0; dim < 2; dim++ {
+		X := grid.Coords(dim)
+		jump := make([]float64, npts[dim]) // max jump over line i
+		for I := 0; I < grid.Size(); I++ {
+			m, n, _ := grid.IndexItoMNP(I)
+			idx := []int{m, n}
+			i := idx[dim]
+			if i == 0 || i == npts[dim]-1 {
+				continue
+			}
+			idx[dim] = i - 1
+			L := grid.IndexMNPtoI(idx[0], idx[1], 0)
+			idx[dim] = i + 1
+			R := grid.IndexMNPtoI(idx[0], idx[1], 0)
+			J := math.Abs((u[R]-u[I])/(X[i+1]-X[i]) - (u[I]-u[L])/(X[i]-X[i-1]))
+			jump[i] = math.Max(jump[i], J)
+		}
+		eta[dim] = make([]float64, npts[dim]-1)
+		for k := 0; k < npts[dim]-1; k++ {
+			eta[dim][k] = (X[k+1] - X[k]) * math.Max(jump[k], jump[k+1])
+		}
+	}
+	return
+}
+
+// RefineAnisotropic refines a rectilinear 2D grid by bisecting the intervals with large
+// directional indicators (see DirectionalIndicators)
+//
+//   The intervals with η_d[k] ≥ frac⋅max(η) are bisected, where the maximum is taken over both
+//   directions. Thus, the splitting is anisotropic: the intervals along one direction are refined
+//   only if the solution varies sharply along that direction; e.g. a thin layer aligned with x
+//   leads to the refinement of the y-intervals across the layer only
+//
+//   Input:
+//     grid -- rectilinear 2D grid (unrotated)
+//     u    -- [nnodes] values at nodes; e.g. a solution on grid
+//     frac -- fraction of the largest indicator; 0 < frac ≤ 1
+//   Output:
+//     refined -- new grid with the same limits; the nodes of grid are also nodes of refined
+//     nsplit  -- [2] number of bisected intervals along each direction
+//
+//   NOTE: the operators must be created again on the refined grid; e.g. the solution may be
+//         transferred with the bilinear interpolation to be used as initial guess
+func RefineAnisotropic(grid *gm.Grid, u []float64, frac float64) (refined *gm.Grid, nsplit []int) {
+	if frac <= 0 || frac > 1 {
+		chk.Panic("frac must be in (0, 1]. %g is invalid\n", frac)
+	}
+	eta := DirectionalIndicators(grid, u)
+	etaMax := 0.0
+	for dim := 0; dim < 2; dim++ {
+		for _, e := range eta[dim] {
+			etaMax = math.Max(etaMax, e)
+		}
+	}
+	nsplit = make([]int, 2)
+	coords := make([][]float64, 2)
+	for dim := 0; dim < 2; dim++ {
+		X := grid.Coords(dim)
+		for k, e := range eta[dim] {
+			coords[dim] = append(coords[dim], X[k])
+			if etaMax > 0 && e >= frac*etaMax {
+				coords[dim] = append(coords[dim], (X[k]+X[k+1])/2)
+				nsplit[dim]++
+			}
+		}
+		coords[dim] = append(coords[dim], X[len(X)-1])
+	}
+	refined = new(gm.Grid)
+	refined.RectSet2d(coords[0], coords[1])
+	return
+}
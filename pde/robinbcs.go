@@ -0,0 +1,105 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/la"
+)
+
+// RobinBcs holds data for prescribing Robin (mixed) boundary conditions on the edges or faces of a grid
+//
+//   a({x}) ⋅ u  +  b({x}) ⋅ ∂u/∂n  =  c({x},t)      where n is the outward normal
+//
+//   For example, a convective (heat transfer) condition  -k ∂u/∂n = h⋅(u - u∞)  corresponds to
+//   a = h, b = k and c = h⋅u∞. The coefficients a and b are evaluated at t = 0 because they
+//   contribute to the matrix; c may depend on time
+type RobinBcs struct {
+	grid  *gm.Grid     // the grid
+	items []*robinItem // conditions (one per tag)
+	n2i   [][]int      // [nnodes] indices of items at each node; e.g. two items at corners
+}
+
+// robinItem holds the coefficients of a Robin condition assigned to a tag
+type robinItem struct {
+	tag     int     // edge or face tag
+	a, b, c fun.Svs // coefficients
+}
+
+// NewRobinBcsGrid returns a new structure using Grid
+func NewRobinBcsGrid(grid *gm.Grid) (o *RobinBcs) {
+	o = new(RobinBcs)
+	o.grid = grid
+	o.n2i = make([][]int, grid.Size())
+	return
+}
+
+// SetInGrid sets Robin condition with constant coefficients on edge or face with given tag
+//   tag     -- edge or face tag
+//   a, b, c -- coefficients in a⋅u + b⋅∂u/∂n = c; b must not be zero
+func (o *RobinBcs) SetInGrid(tag int, a, b, c float64) {
+	o.SetInGridFunc(tag,
+		func(x la.Vector, t float64) float64 { return a },
+		func(x la.Vector, t float64) float64 { return b },
+		func(x la.Vector, t float64) float64 { return c },
+	)
+}
+
+// SetInGridFunc sets Robin condition with coefficients given by functions evaluated at each
+// boundary node; e.g. position-dependent convective coefficients
+//   tag     -- edge or face tag
+//   a, b, c -- coefficients in a⋅u + b⋅∂u/∂n = c; b must not be zero
+//   NOTE: the condition replaces a previous one with the same tag
+func (o *RobinBcs) SetInGridFunc(tag int, a, b, c fun.Svs) {
+	if a == nil || b == nil || c == nil {
+		chk.Panic("all coefficient functions must be given\n")
+	}
+	for _, item := range o.items {
+		if item.tag == tag {
+			item.a, item.b, item.c = a, b, c
+			return
+		}
+	}
+	nodes := o.grid.Boundary(tag)
+	if len(nodes) == 0 {
+		chk.Panic("cannot find nodes with tag = %d\n", tag)
+	}
+	idx := len(o.items)
+	o.items = append(o.items, &robinItem{tag, a, b, c})
+	for _, I := range nodes {
+		o.n2i[I] = append(o.n2i[I], idx)
+	}
+}
+
+// CheckGrid checks whether the conditions were created for the given grid (see BoundaryConds.CheckGrid)
+//   err -- *GridMismatchError if the grids are different
+func (o *RobinBcs) CheckGrid(grid *gm.Grid) (err error) {
+	if reason := gridDifference(o.grid, grid); reason != "" {
+		return &GridMismatchError{"Robin", reason}
+	}
+	return nil
+}
+
+// Has tells whether node has Robin condition or not
+func (o *RobinBcs) Has(node int) bool {
+	return len(o.n2i[node]) > 0
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// terms calls fcn for each condition at node (more than one at corners)
+//   dim -- direction normal to the edge or face
+func (o *RobinBcs) terms(node int, fcn func(dim int, item *robinItem)) {
+	for _, idx := range o.n2i[node] {
+		item := o.items[idx]
+		dim := item.tag/10 - 1
+		if o.grid.Ndim() == 3 {
+			dim = item.tag/100 - 1
+		}
+		fcn(dim, item)
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// AssembleShifted assembles the shifted operator [Auu] - σ⋅[Muu] directly from the stencil; e.g.
+// for shift-invert eigenvalue solvers and generalized problems [Auu]⋅{xu} = λ⋅[Muu]⋅{xu}
+//
+//   Input:
+//     op       -- FDM Laplacian operator with the essential boundary conditions already set (2D)
+//     sigma    -- shift σ
+//     massKind -- "identity":   [M] = [I]; i.e. standard problems
+//                 "lumped":     [M] = diag(V) with the areas of the control volumes around nodes
+//                               (see AssembleMassLumped)
+//                 "consistent": consistent mass matrix (see AssembleMassConsistent)
+//   Output:
+//     a -- [Nu][Nu] shifted matrix (see op.Eqs for the numbering)
+//
+//   NOTE: (1) the operator does not need to be assembled; however, Eqs is created if needed and
+//             the matrices in Eqs are not modified
+//         (2) the rows of nodes with prescribed values are excluded; i.e. the eigenvectors vanish
+//             at these nodes
+func AssembleShifted(op *FdmLaplacian, sigma float64, massKind string) (a *la.Triplet) {
+
+	// check
+	if op.Grid.Ndim() != 2 {
+		chk.Panic("AssembleShifted works in 2D only\n")
+	}
+	var mass []float64
+	switch massKind {
+	case "identity", "consistent":
+	case "lumped":
+		mass = gridVolumes(op.Grid)
+	default:
+		chk.Panic("mass kind %q is invalid. options: \"identity\", \"lumped\" or \"consistent\"\n", massKind)
+	}
+
+	// equations
+	nmol := op.molSize()
+	if !op.bcsReady {
+		op.initEqs()
+	}
+	eqs := op.Eqs
+
+	// assemble
+	a = la.NewTriplet(eqs.Nu, eqs.Nu, (nmol+9)*eqs.Nu)
+	for i, I := range eqs.UtoF {
+		op.stencil2d(I, func(_, J int, value float64) {
+			if j := eqs.FtoU[J]; j >= 0 {
+				a.Put(i, j, value)
+			}
+		})
+		if massKind == "consistent" {
+			op.massConsistent(I, func(J int, value float64) {
+				if j := eqs.FtoU[J]; j >= 0 {
+					a.Put(i, j, -sigma*value)
+				}
+			})
+			continue
+		}
+		m := 1.0
+		if mass != nil {
+			m = mass[I]
+		}
+		a.Put(i, i, -sigma*m)
+	}
+	return
+}
+
+// OperatorSpectrum computes all eigenvalues of the matrix [Auu] of small systems with the dense
+// eigenvalue solver (see la.EigenVal); e.g. for teaching or to analyse the stability of
+// time-stepping schemes: the forward Euler method applied to du/dt = [Auu]⋅{u} is stable if
+// |1 + Δt⋅λ| ≤ 1 for all eigenvalues; i.e. Δt ≤ 2/|λ| for real and negative eigenvalues
+//
+//   Input:
+//     e -- equations with assembled [Auu]; e.g. FdmLaplacian.Eqs after Assemble
+//   Output:
+//     lambda -- [Nu] eigenvalues sorted by increasing real parts (and imaginary parts of
+//               complex conjugate pairs) [nil if err != nil]
+//     err    -- *DenseLimitError if Nu > DefaultMaxDense; i.e. the dense matrix is not allocated
+//
+//   NOTE: the eigenvalues of the FDM Laplacian are real and negative (the operator is negative
+//         definite) with essential conditions; with natural conditions, the matrix is not
+//         symmetric but has real eigenvalues (it is similar to a symmetric matrix)
+func OperatorSpectrum(e *la.Equations) (lambda la.VectorC, err error) {
+	if e.Auu == nil {
+		chk.Panic("[Auu] must be assembled before calling OperatorSpectrum\n")
+	}
+	if e.Nu > DefaultMaxDense {
+		return nil, &DenseLimitError{N: e.Nu, Limit: DefaultMaxDense}
+	}
+	lambda = la.NewVectorC(e.Nu)
+	la.EigenVal(lambda, e.Auu.ToDense(), false)
+	sort.Slice(lambda, func(i, j int) bool {
+		if real(lambda[i]) != real(lambda[j]) {
+			return real(lambda[i]) < real(lambda[j])
+		}
+		return imag(lambda[i]) < imag(lambda[j])
+	})
+	return
+}
+
+// AssembleMassLumped assembles the lumped (diagonal) mass matrix of the grid; i.e. the areas (or
+// volumes) of the control volumes around the nodes given by gm.Grid.ControlVolume
+//
+//   M_II = V[I] = ∫ φ_I dΩ     (row sums of the consistent mass matrix; see AssembleMassConsistent)
+//
+//   Output:
+//     m -- [nnodes][nnodes] diagonal mass matrix (all nodes)
+//
+//   NOTE: the control volumes of stretched grids are not equal to h^d; the sum of the diagonal is
+//         equal to the area (volume) of rectangular domains
+func (o *FdmLaplacian) AssembleMassLumped() (m *la.Triplet) {
+	nnodes := o.Grid.Size()
+	m = la.NewTriplet(nnodes, nnodes, nnodes)
+	for I, v := range gridVolumes(o.Grid) {
+		m.Put(I, I, v)
+	}
+	return
+}
+
+// AssembleMassConsistent assembles the consistent mass matrix of the grid; i.e. the integrals of
+// the products of the (tensor-product) piecewise linear interpolation functions of the nodes
+//
+//           ⌠
+//    M_IJ = │ φ_I φ_J dΩ     with     φ_I({x}) = φ_m(x) ⋅ φ_n(y) [⋅ φ_p(z)]
+//           ⌡Ω
+//
+//   The integrals are the products of the 1D integrals, which are computed exactly by Simpson's
+//   rule on each interval; i.e. h/3 on the diagonal (per interval) and h/6 off the diagonal. The
+//   matrix is symmetric positive-definite with the bandwidth of the 9-point (27-point) stencil. The
+//   row sums are equal to the weights of the trapezoidal rule; i.e. the lumped mass (see
+//   GridQuadrature) since the functions are a partition of unity
+//
+//   Output:
+//     m -- [nnodes][nnodes] mass matrix (all nodes)
+//
+//   NOTE: rectilinear grids only (e.g. RectGenUniform or RectSet2d); see AssembleShifted for the
+//         part corresponding to the nodes without prescribed values
+func (o *FdmLaplacian) AssembleMassConsistent() (m *la.Triplet) {
+	nnodes := o.Grid.Size()
+	ncol := 9
+	if o.Grid.Ndim() == 3 {
+		ncol = 27
+	}
+	m = la.NewTriplet(nnodes, nnodes, ncol*nnodes)
+	for I := 0; I < nnodes; I++ {
+		o.massConsistent(I, func(J int, value float64) { m.Put(I, J, value) })
+	}
+	return
+}
+
+// massConsistent calls put(J, M_IJ) for the non-zero entries of row I of the consistent mass matrix
+func (o *FdmLaplacian) massConsistent(I int, put func(J int, value float64)) {
+	g := o.Grid
+	ndim := g.Ndim()
+	entry := func(dim, i, j int) float64 { // 1D mass matrix
+		if dim >= ndim {
+			return 1
+		}
+		X := g.Coords(dim)
+		switch j - i {
+		case -1:
+			return (X[i] - X[j]) / 6.0
+		case 1:
+			return (X[j] - X[i]) / 6.0
+		}
+		res := 0.0
+		if i > 0 {
+			res += (X[i] - X[i-1]) / 3.0
+		}
+		if i < len(X)-1 {
+			res += (X[i+1] - X[i]) / 3.0
+		}
+		return res
+	}
+	npts := []int{g.Npts(0), 1, 1}
+	for dim := 1; dim < ndim; dim++ {
+		npts[dim] = g.Npts(dim)
+	}
+	m, n, p := g.IndexItoMNP(I)
+	for c := utl.Imax(p-1, 0); c <= utl.Imin(p+1, npts[2]-1); c++ {
+		for b := utl.Imax(n-1, 0); b <= utl.Imin(n+1, npts[1]-1); b++ {
+			for a := utl.Imax(m-1, 0); a <= utl.Imin(m+1, npts[0]-1); a++ {
+				put(g.IndexMNPtoI(a, b, c), entry(0, m, a)*entry(1, n, b)*entry(2, p, c))
+			}
+		}
+	}
+}
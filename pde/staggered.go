@@ -0,0 +1,154 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/la"
+)
+
+// StaggeredGradient computes the gradient of a cell-based scalar field on a staggered (MAC) grid
+//
+//              p[i,j] - p[i-1,j]                 p[i,j] - p[i,j-1]
+//   gx[i,j] = ———————————————————     gy[i,j] = ———————————————————
+//                     Δx                                Δy
+//
+//   The components are computed at the faces between cells; they are zero at the boundary faces
+//   because the normal component of the vector field (e.g. velocity) is prescribed there. With
+//   this choice, the discrete gradient is the negative adjoint of StaggeredDivergence for vector
+//   fields with zero normal components at the boundary: ⟨p, div w⟩ = -⟨grad p, w⟩
+//
+//   Input:
+//     sg -- staggered grid
+//     p  -- [ncells] scalar field at the centres of cells; e.g. pressure
+//   Output:
+//     gx -- [nfacesX] x-component at vertical faces
+//     gy -- [nfacesY] y-component at horizontal faces
+func StaggeredGradient(sg *gm.StaggeredGrid, p []float64) (gx, gy []float64) {
+	if len(p) != sg.Ncells() {
+		chk.Panic("size of scalar field must be equal to the number of cells. %d != %d\n", len(p), sg.Ncells())
+	}
+	nx, ny := sg.Nx(), sg.Ny()
+	gx = make([]float64, sg.NfacesX())
+	gy = make([]float64, sg.NfacesY())
+	for j := 0; j < ny; j++ {
+		for i := 1; i < nx; i++ {
+			gx[sg.FaceXindex(i, j)] = (p[sg.CellIndex(i, j)] - p[sg.CellIndex(i-1, j)]) / sg.Dx()
+		}
+	}
+	for j := 1; j < ny; j++ {
+		for i := 0; i < nx; i++ {
+			gy[sg.FaceYindex(i, j)] = (p[sg.CellIndex(i, j)] - p[sg.CellIndex(i, j-1)]) / sg.Dy()
+		}
+	}
+	return
+}
+
+// StaggeredDivergence computes the divergence of a face-based vector field on a staggered (MAC) grid
+//
+//                u[i+1,j] - u[i,j]     v[i,j+1] - v[i,j]
+//   div[i,j] =  ——————————————————— + ———————————————————
+//                       Δx                    Δy
+//
+//   Input:
+//     sg -- staggered grid
+//     u  -- [nfacesX] x-component at vertical faces (including the boundary faces)
+//     v  -- [nfacesY] y-component at horizontal faces (including the boundary faces)
+//   Output:
+//     div -- [ncells] divergence at the centres of cells
+func StaggeredDivergence(sg *gm.StaggeredGrid, u, v []float64) (div []float64) {
+	if len(u) != sg.NfacesX() || len(v) != sg.NfacesY() {
+		chk.Panic("sizes of components must be equal to the numbers of faces (%d, %d). len(u)=%d, len(v)=%d\n", sg.NfacesX(), sg.NfacesY(), len(u), len(v))
+	}
+	nx, ny := sg.Nx(), sg.Ny()
+	div = make([]float64, sg.Ncells())
+	for j := 0; j < ny; j++ {
+		for i := 0; i < nx; i++ {
+			div[sg.CellIndex(i, j)] = (u[sg.FaceXindex(i+1, j)]-u[sg.FaceXindex(i, j)])/sg.Dx() +
+				(v[sg.FaceYindex(i, j+1)]-v[sg.FaceYindex(i, j)])/sg.Dy()
+		}
+	}
+	return
+}
+
+// ProjectDivergenceFree corrects a velocity field on a staggered (MAC) grid to make it
+// divergence-free (pressure-projection step of Chorin's method)
+//
+//   The pressure is the solution of the Poisson equation with homogeneous Neumann conditions
+//
+//                        1
+//   div(grad p) = ———— div(w*)       and then      w = w* - Δt grad p
+//                   Δt
+//
+//   where w* = (u*, v*) is the intermediate velocity field. The discrete Laplacian is the product
+//   of StaggeredDivergence and StaggeredGradient; thus, div(w) = 0 up to round-off errors. The
+//   pressure is determined up to a constant; it is set to zero at cell (0,0)
+//
+//   Input:
+//     sg -- staggered grid
+//     u  -- [nfacesX] x-component of velocity at vertical faces
+//     v  -- [nfacesY] y-component of velocity at horizontal faces
+//     dt -- time step Δt
+//   Output:
+//     u, v -- corrected velocity; the components at boundary faces are not changed
+//     p    -- [ncells] pressure at the centres of cells
+//
+//   NOTE: the net flux through the boundary must be zero; otherwise, no divergence-free field with
+//         the same boundary values exists. In this case, the mean of div(w*) is removed; i.e. the
+//         divergence of the corrected field is equal to the mean of div(w*)
+func ProjectDivergenceFree(sg *gm.StaggeredGrid, u, v []float64, dt float64) (p []float64) {
+
+	// right-hand side with zero mean
+	rhs := StaggeredDivergence(sg, u, v)
+	mean := 0.0
+	for _, val := range rhs {
+		mean += val
+	}
+	mean /= float64(len(rhs))
+	for k := range rhs {
+		rhs[k] = (rhs[k] - mean) / dt
+	}
+
+	// Laplacian with homogeneous Neumann conditions; pressure at cell 0 is prescribed
+	nx, ny := sg.Nx(), sg.Ny()
+	ax, ay := 1.0/(sg.Dx()*sg.Dx()), 1.0/(sg.Dy()*sg.Dy())
+	eqs := la.NewEquations(sg.Ncells(), []int{0})
+	eqs.Alloc([]int{5 * eqs.Nu, 5 * eqs.Nu, 0, 0}, false, true)
+	for j := 0; j < ny; j++ {
+		for i := 0; i < nx; i++ {
+			I := sg.CellIndex(i, j)
+			diag := 0.0
+			if i > 0 {
+				diag -= ax
+				eqs.Put(I, sg.CellIndex(i-1, j), ax)
+			}
+			if i < nx-1 {
+				diag -= ax
+				eqs.Put(I, sg.CellIndex(i+1, j), ax)
+			}
+			if j > 0 {
+				diag -= ay
+				eqs.Put(I, sg.CellIndex(i, j-1), ay)
+			}
+			if j < ny-1 {
+				diag -= ay
+				eqs.Put(I, sg.CellIndex(i, j+1), ay)
+			}
+			eqs.Put(I, I, diag)
+		}
+	}
+
+	// solve
+	eqs.SolveOnce(nil, func(I int, t float64) float64 { return rhs[I] })
+	p = make([]float64, sg.Ncells())
+	eqs.JoinVector(p, eqs.Xu, eqs.Xk)
+
+	// correct velocity
+	gx, gy := StaggeredGradient(sg, p)
+	la.Axpy(-dt, gx, u)
+	la.Axpy(-dt, gy, v)
+	return
+}
@@ -0,0 +1,69 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func TestBcSet01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("BcSet01. dirichlet, neumann and robin conditions on one problem")
+
+	// u = x² + y is reproduced exactly by FDM (including the ghost nodes):  L{u} = 2
+	ana := func(x la.Vector, t float64) float64 { return x[0]*x[0] + x[1] }
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{9, 7})
+	op := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, func(x la.Vector, t float64) float64 { return 2 })
+	defer op.Free()
+
+	// previous conditions are replaced
+	op.SetHbc()
+
+	// conditions
+	bcs := NewBcSet()
+	bcs.Add(10, "dirichlet", nil, ana)
+	bcs.Add(21, "dirichlet", nil, ana)
+	bcs.Add(11, "neumann", dbf.Params{{N: "qn", V: 2}}, nil) // qn = ∂u/∂x @ x = 1
+	bcs.Add(20, "robin", dbf.Params{{N: "a", V: 1}, {N: "b", V: 1}}, func(x la.Vector, t float64) float64 {
+		return ana(x, t) - 1 // u + ∂u/∂n = u - ∂u/∂y @ y = 0
+	})
+	op.ApplyBcs(bcs)
+	op.Assemble(false)
+	chk.Int(tst, "Nk", op.Eqs.Nk, 7+9-1)
+
+	// solution
+	u, _ := op.SolveSteady(false)
+	for I := 0; I < g.Size(); I++ {
+		chk.Float64(tst, io.Sf("u @ %d", I), 1e-13, u[I], ana(g.Node(I), 0))
+	}
+}
+
+func TestBcSet02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("BcSet02. panics")
+
+	check := func(kind string, params dbf.Params) {
+		defer func() {
+			if err := recover(); err == nil {
+				tst.Errorf("kind %q with params %v should panic\n", kind, params)
+			}
+		}()
+		NewBcSet().Add(10, kind, params, nil)
+	}
+	check("periodic", nil)
+	check("dirichlet", nil)
+	check("neumann", dbf.Params{{N: "u", V: 1}})
+	check("robin", dbf.Params{{N: "a", V: 1}, {N: "c", V: 1}})
+	check("robin", dbf.Params{{N: "a", V: 1}, {N: "b", V: 0}, {N: "c", V: 1}})
+}
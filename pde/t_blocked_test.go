@@ -0,0 +1,77 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func TestBlocked01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Blocked01. blocked assembly and matvec")
+
+	// operator with diagonal neighbours (kxy), reaction and essential conditions
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{9, 7})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 2}, {N: "kxy", V: 0.3}, {N: "kr", V: 0.5}}, g, nil)
+	s.AddEbc(10, 0, nil)
+	s.AddEbc(21, 1, nil)
+	s.Assemble(false)
+
+	// blocks
+	bg := gm.NewBlockedGrid(g, []int{3, 2}, 1)
+	blocks := s.AssembleBlocks(bg)
+
+	// assembled entries
+	Aglobal := la.NewTriplet(s.Eqs.Nu, s.Eqs.Nu, s.Eqs.Auu.Len())
+	for _, blk := range blocks {
+		rows, cols, vals := blk.A.ToMatrix(nil).Triplets()
+		for k, v := range vals {
+			i := s.Eqs.FtoU[blk.Block.Nodes[rows[k]]]
+			j := s.Eqs.FtoU[blk.Block.Nodes[cols[k]]]
+			Aglobal.Put(i, j, v)
+		}
+	}
+	chk.Deep2(tst, "Auu", 0, Aglobal.ToDense().GetDeep2(), s.Eqs.Auu.ToDense().GetDeep2())
+
+	// monolithic matvec
+	uu := la.NewVector(s.Eqs.Nu)
+	for i := range uu {
+		uu[i] = math.Sin(float64(i)) + 2
+	}
+	correct := la.NewVector(s.Eqs.Nu)
+	la.SpTriMatVecMul(correct, s.Eqs.Auu, uu)
+
+	// blocked matvec: owned values, halo exchange and products
+	u := make([]float64, g.Size())
+	uk := la.NewVector(s.Eqs.Nk)
+	uk.Fill(99) // ignored
+	s.Eqs.JoinVector(u, uu, uk)
+	local := bg.Scatter(make([]float64, g.Size()))
+	for b, blk := range bg.Blocks {
+		for l, I := range blk.Nodes[:blk.Nowned] {
+			local[b][l] = u[I]
+		}
+	}
+	bg.Exchange(local)
+	y := make([][]float64, len(blocks))
+	for b, blk := range blocks {
+		y[b] = make([]float64, blk.Block.Nowned)
+		blk.MatVec(y[b], local[b])
+	}
+	res := la.NewVector(s.Eqs.Nu)
+	s.Eqs.SplitVector(res, uk, bg.Gather(y))
+	chk.Array(tst, "y @ prescribed", 0, uk, nil)
+	io.Pforan("max(y) = %g\n", res.Largest(1))
+	chk.Array(tst, "y", 0, res, correct)
+}
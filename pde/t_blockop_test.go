@@ -0,0 +1,256 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func TestBlockOp01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("BlockOp01. coupled diffusion system with two fields")
+
+	// grid
+	nx, ny := 7, 6
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{nx, ny})
+	hx, hy := 1.0/float64(nx-1), 1.0/float64(ny-1)
+
+	// field u:  ∇²u - u + 0.2⋅∇²v + 0.5⋅v = 1 + x   with u = 1 @ x = 0 and qn = 0.5 @ x = 1
+	s0 := func(x la.Vector, t float64) float64 { return 1 + x[0] }
+	A := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}, {N: "kr", V: 1}}, g, s0)
+	A.AddEbc(10, 1, nil)
+	A.AddNbc(11, 0.5, nil)
+	B := NewFdmLaplacian(dbf.Params{{N: "kx", V: 0.2}, {N: "ky", V: 0.2}, {N: "kr", V: -0.5}}, g, nil)
+
+	// field v:  0.3⋅u + 2⋅∇²v = y   with v = 0 @ y = 0 and v + ∂v/∂n = 0.2 @ y = 1
+	s1 := func(x la.Vector, t float64) float64 { return x[1] }
+	C := NewFdmLaplacian(dbf.Params{{N: "kx", V: 0}, {N: "ky", V: 0}, {N: "kr", V: -0.3}}, g, nil)
+	D := NewFdmLaplacian(dbf.Params{{N: "kx", V: 2}, {N: "ky", V: 2}}, g, s1)
+	D.AddEbc(20, 0, nil)
+	D.RobinBcs.SetInGrid(21, 1, 1, 0.2)
+
+	// block solve
+	op := NewBlockOperator([][]*FdmLaplacian{{A, B}, {C, D}})
+	op.Assemble(false)
+	u, _ := op.SolveSteady(false)
+	chk.Int(tst, "Nk", op.Eqs.Nk, ny+nx)
+
+	// monolithic assembly: dense full system with identity rows for prescribed values
+	nn := g.Size()
+	M := la.NewMatrix(2*nn, 2*nn)
+	b := la.NewVector(2 * nn)
+	for i, row := range [][]*FdmLaplacian{{A, B}, {C, D}} {
+		for j, blk := range row {
+			blk.AssembleStream(func(I, J int, value float64) { M.Add(I+i*nn, J+j*nn, value) })
+		}
+	}
+	for I := 0; I < nn; I++ {
+		x := g.Node(I)
+		m, n, _ := g.IndexItoMNP(I)
+		b[I] = s0(x, 0)
+		b[nn+I] = s1(x, 0)
+		if m == nx-1 {
+			b[I] -= 2 * 0.5 / hx // natural condition
+		}
+		if n == ny-1 {
+			b[nn+I] -= 2 * 2 * 0.2 / (1 * hy) // Robin condition: -2⋅k⋅c/(b⋅h)
+		}
+	}
+	prescribe := func(row int, value float64) {
+		for col := 0; col < 2*nn; col++ {
+			M.Set(row, col, 0)
+		}
+		M.Set(row, row, 1)
+		b[row] = value
+	}
+	for I := 0; I < nn; I++ {
+		m, n, _ := g.IndexItoMNP(I)
+		if m == 0 {
+			prescribe(I, 1)
+		}
+		if n == 0 {
+			prescribe(nn+I, 0)
+		}
+	}
+	uRef := la.NewVector(2 * nn)
+	la.DenSolve(uRef, M, b, false)
+
+	// compare
+	io.Pforan("u(1,1) = %g, v(1,1) = %g\n", op.Field(u, 0)[nn-1], op.Field(u, 1)[nn-1])
+	chk.Array(tst, "u", 1e-12, op.Field(u, 0), uRef[:nn])
+	chk.Array(tst, "v", 1e-12, op.Field(u, 1), uRef[nn:])
+}
+
+func TestBlockOp02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("BlockOp02. reaction coupling matrix")
+
+	// grid
+	nx, ny := 6, 5
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{nx, ny})
+	nn := g.Size()
+
+	// fields u and v:  ∇²{u} - R⋅{u} = {s}  with u = 1 @ x = 0 and v = 0 @ y = 0
+	R := la.NewMatrixDeep2([][]float64{
+		{1.0, 0.5},
+		{-0.3, 2.0},
+	})
+	s0 := func(x la.Vector, t float64) float64 { return 1 + x[0] }
+	s1 := func(x la.Vector, t float64) float64 { return x[1] }
+	diagonal := func() (A, D *FdmLaplacian) {
+		A = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, s0)
+		A.AddEbc(10, 1, nil)
+		D = NewFdmLaplacian(dbf.Params{{N: "kx", V: 2}, {N: "ky", V: 2}}, g, s1)
+		D.AddEbc(20, 0, nil)
+		return
+	}
+	A, D := diagonal()
+	op := NewBlockOperator([][]*FdmLaplacian{{A, nil}, {nil, D}})
+	op.Kr = R
+	op.Assemble(true) // with kparts for GetAmat
+	u, _ := op.SolveSteady(false)
+
+	// the block-diagonal entries of the reaction part are -R
+	Am := op.Eqs.GetAmat().ToDense()
+	A0, D0 := diagonal()
+	op0 := NewBlockOperator([][]*FdmLaplacian{{A0, nil}, {nil, D0}})
+	op0.Assemble(true)
+	Am0 := op0.Eqs.GetAmat().ToDense()
+	I := 2 + 2*nx // interior node
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			chk.Float64(tst, io.Sf("-R%d%d", i, j), 1e-13, Am.Get(I+i*nn, I+j*nn)-Am0.Get(I+i*nn, I+j*nn), -R.Get(i, j))
+		}
+	}
+
+	// equivalent coupling via sub-operators (see BlockOp01)
+	A1, D1 := diagonal()
+	A1.Kr, D1.Kr = R.Get(0, 0), R.Get(1, 1)
+	B1 := NewFdmLaplacian(dbf.Params{{N: "kx", V: 0}, {N: "ky", V: 0}, {N: "kr", V: R.Get(0, 1)}}, g, nil)
+	C1 := NewFdmLaplacian(dbf.Params{{N: "kx", V: 0}, {N: "ky", V: 0}, {N: "kr", V: R.Get(1, 0)}}, g, nil)
+	op1 := NewBlockOperator([][]*FdmLaplacian{{A1, B1}, {C1, D1}})
+	op1.Assemble(false)
+	u1, _ := op1.SolveSteady(false)
+	io.Pforan("u(1,1) = %g, v(1,1) = %g\n", op.Field(u, 0)[nn-1], op.Field(u, 1)[nn-1])
+	chk.Array(tst, "u", 1e-12, u, u1)
+
+	// coupling matrix depending on the coordinates
+	A2, D2 := diagonal()
+	op2 := NewBlockOperator([][]*FdmLaplacian{{A2, nil}, {nil, D2}})
+	op2.Reaction = func(R *la.Matrix, x la.Vector) {
+		R.Set(0, 0, 1+x[0])
+		R.Set(0, 1, x[1])
+		R.Set(1, 0, 0)
+		R.Set(1, 1, 2)
+	}
+	op2.Assemble(true)
+	Am2 := op2.Eqs.GetAmat().ToDense()
+	x := g.Node(I)
+	chk.Float64(tst, "-R00(x)", 1e-13, Am2.Get(I, I)-Am0.Get(I, I), -(1 + x[0]))
+	chk.Float64(tst, "-R01(x)", 1e-15, Am2.Get(I, I+nn), -x[1])
+	chk.Float64(tst, "-R10(x)", 1e-15, Am2.Get(I+nn, I), 0)
+}
+
+func TestBlockOp03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("BlockOp03. iterative solution with tolerances per field")
+
+	// fields with disparate scales: temperature ~1e3 and displacement ~1e-3
+	//   ∇²T = -1e4                     with T = 0 @ x = 0 and x = 1
+	//   ∇²v - 1e-6⋅v + 1e-8⋅T = -1e-3  with v = 0 @ y = 0
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{17, 17})
+	newOperator := func() *BlockOperator {
+		A := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, func(x la.Vector, t float64) float64 { return -1e4 })
+		A.AddEbc(10, 0, nil)
+		A.AddEbc(11, 0, nil)
+		C := NewFdmLaplacian(dbf.Params{{N: "kx", V: 0}, {N: "ky", V: 0}, {N: "kr", V: -1e-8}}, g, nil)
+		D := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}, {N: "kr", V: 1e-6}}, g, func(x la.Vector, t float64) float64 { return -1e-3 })
+		D.AddEbc(20, 0, nil)
+		op := NewBlockOperator([][]*FdmLaplacian{{A, nil}, {C, D}})
+		op.Assemble(false)
+		return op
+	}
+	op := newOperator()
+	uRef, _ := op.SolveSteady(false)
+	nn := g.Size()
+	io.Pforan("max(T) = %g, max(v) = %g\n", la.Vector(uRef[:nn]).Max(), la.Vector(uRef[nn:]).Max())
+
+	// relative errors of fields
+	fieldErrors := func(u []float64) (errs []float64) {
+		for f := 0; f < 2; f++ {
+			diff := la.NewVector(nn)
+			for I := 0; I < nn; I++ {
+				diff[I] = op.Field(u, f)[I] - op.Field(uRef, f)[I]
+			}
+			errs = append(errs, diff.Norm()/la.Vector(op.Field(uRef, f)).Norm())
+		}
+		return
+	}
+
+	// right-hand side (the prescribed values are zero)
+	bu := la.NewVector(op.Eqs.Nu)
+	for i, I := range op.Eqs.UtoF {
+		bu[i] = op.calcBu(I, 0)
+	}
+	a := op.Eqs.Auu.ToMatrix(nil)
+
+	// a single tolerance relative to the full RHS does not resolve the small field
+	xu := la.NewVector(op.Eqs.Nu)
+	la.Gmres(xu, a, bu, la.NewPrecondJacobi(a), 1e-8, 10*op.Eqs.Nu, 40)
+	ulo := make([]float64, op.Eqs.N)
+	for i, I := range op.Eqs.UtoF {
+		ulo[I] = xu[i]
+	}
+	errs := fieldErrors(ulo)
+	io.Pforan("global tolerance: errors = %.2e\n", errs)
+	if errs[1] < 1e-5 {
+		tst.Errorf("the small field should not be resolved with the global tolerance: error = %g\n", errs[1])
+	}
+
+	// tolerances per field
+	tols := []float64{1e-8, 1e-8}
+	u, nit := op.SolveIterative(&SolveOptions{FieldTol: tols, Restart: 40})
+	errs = fieldErrors(u)
+	io.Pforan("nit = %d, residuals = %.2e, errors = %.2e\n", nit, op.Residuals, errs)
+	for f, tol := range tols {
+		if op.Residuals[f] > tol {
+			tst.Errorf("field %d: residual %g should be smaller than %g\n", f, op.Residuals[f], tol)
+		}
+		if errs[f] > 1e3*tol {
+			tst.Errorf("field %d: error %g is too large\n", f, errs[f])
+		}
+	}
+
+	// check residuals of fields
+	r := la.NewVector(op.Eqs.Nu)
+	for i, I := range op.Eqs.UtoF {
+		xu[i] = u[I]
+	}
+	copy(r, bu)
+	la.SpMatVecMulAdd(r, -1, a, xu)
+	for f := 0; f < 2; f++ {
+		var rf, bf float64
+		for i, I := range op.Eqs.UtoF {
+			if I/nn == f {
+				rf += r[i] * r[i]
+				bf += bu[i] * bu[i]
+			}
+		}
+		chk.Float64(tst, io.Sf("residual of field %d", f), 1e-12, op.Residuals[f], math.Sqrt(rf/bf))
+	}
+}
@@ -194,3 +194,209 @@ func TestBryConds05(tst *testing.T) {
 	e.ndof = 1
 	e.AddUsingTag(0, 0, 0, nil)
 }
+
+func TestBryConds06(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("BryConds06. DumpNodes")
+
+	// 4x4 grid (as in Fdm02)
+	//    12  13  14  15
+	//     8   9  10  11
+	//     4   5   6   7
+	//     0   1   2   3
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{3, 3}, []int{4, 4})
+
+	// function-based boundary conditions
+	e := NewBoundaryCondsGrid(g, 1)
+	e.AddUsingTag(10, 0, 1.0, nil)                                                     // left
+	e.AddUsingTag(11, 0, 2.0, nil)                                                     // right
+	e.AddUsingTag(20, 0, 0, func(x la.Vector, t float64) float64 { return x[0] + 10 }) // bottom
+	e.AddUsingTag(21, 0, 0, func(x la.Vector, t float64) float64 { return x[0] + 20 }) // top
+
+	// dump
+	list := e.DumpNodes(0)
+	for _, d := range list {
+		io.Pf("node=%2d dof=%d tags=%v x=%v value=%g\n", d.Node, d.Dof, d.Tags, d.X, d.Value)
+	}
+
+	// check
+	chk.Int(tst, "len(list)", len(list), 12)
+	nodes := make([]int, len(list))
+	for i, d := range list {
+		nodes[i] = d.Node
+	}
+	chk.Ints(tst, "nodes", nodes, []int{0, 1, 2, 3, 4, 7, 8, 11, 12, 13, 14, 15})
+
+	// corners: the last added tag sets the value
+	corners := map[int]int{0: 0, 3: 3, 12: 8, 15: 11} // node => position in list
+	xcorner := map[int][]float64{0: {0, 0}, 3: {3, 0}, 12: {0, 3}, 15: {3, 3}}
+	tcorner := map[int][]int{0: {10, 20}, 3: {11, 20}, 12: {10, 21}, 15: {11, 21}}
+	vcorner := map[int]float64{0: 10, 3: 13, 12: 20, 15: 23}
+	for n, i := range corners {
+		d := list[i]
+		chk.Int(tst, "node", d.Node, n)
+		chk.Ints(tst, io.Sf("tags @ %d", n), d.Tags, tcorner[n])
+		chk.Array(tst, io.Sf("x @ %d", n), 1e-15, d.X, xcorner[n])
+		chk.Float64(tst, io.Sf("value @ %d", n), 1e-15, d.Value, vcorner[n])
+	}
+
+	// other nodes
+	chk.Float64(tst, "value @ 4", 1e-15, list[4].Value, 1)
+	chk.Float64(tst, "value @ 7", 1e-15, list[5].Value, 2)
+	chk.Float64(tst, "value @ 13", 1e-15, list[9].Value, 21)
+}
+
+func TestBryConds07(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("BryConds07. AddUsingNodes")
+
+	// 3x3 grid
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 2}, []int{3, 3})
+
+	// interior node and corner node (with tag)
+	bcs := NewBoundaryCondsGrid(g, 1)
+	bcs.AddUsingTag(10, 0, 1.0, nil)
+	bcs.AddUsingNodes([]int{4, 6}, 0, 2.0, nil)
+	io.Pf("%v", bcs.Print())
+	chk.Ints(tst, "nodes", bcs.Nodes(), []int{0, 3, 4, 6})
+	chk.Ints(tst, "tags @ 4", bcs.Tags(4), []int{})
+	chk.Ints(tst, "tags @ 6", bcs.Tags(6), []int{10})
+	_, val, available := bcs.Value(4, 0, 0)
+	if !available {
+		tst.Errorf("value @ 4 should be available\n")
+		return
+	}
+	chk.Float64(tst, "value @ 4", 1e-17, val, 2.0)
+	_, val, _ = bcs.Value(6, 0, 0)
+	chk.Float64(tst, "value @ 6", 1e-17, val, 2.0)
+
+	// normal at interior node is zero
+	N := la.NewVector(2)
+	bcs.NormalGrid(N, 4)
+	chk.Array(tst, "N @ 4", 1e-17, N, []float64{0, 0})
+}
+
+func TestBryConds08(tst *testing.T) {
+	//verbose()
+	chk.PrintTitle("BryConds08. panic in AddUsingNodes. wrong node")
+	defer chk.RecoverTstPanicIsOK(tst)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 2}, []int{3, 3})
+	bcs := NewBoundaryCondsGrid(g, 1)
+	bcs.AddUsingNodes([]int{9}, 0, 2.0, nil)
+}
+
+func TestBryConds09(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("BryConds09. SetTotalFlux")
+
+	// 2D: length of edge 20 is 4
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{4, 2}, []int{5, 3})
+	bcs := NewBoundaryCondsGrid(g, 1)
+	bcs.SetTotalFlux(20, 0, 10)
+	for _, n := range g.EdgeGivenTag(20) {
+		_, val, _ := bcs.Value(n, 0, 0)
+		chk.Float64(tst, io.Sf("qn @ %d", n), 1e-15, val, 2.5)
+	}
+
+	// 3D: area of face 300 is 4⋅2 = 8
+	g3 := new(gm.Grid)
+	g3.RectGenUniform([]float64{0, 0, 0}, []float64{4, 2, 1}, []int{3, 3, 3})
+	bcs = NewBoundaryCondsGrid(g3, 1)
+	bcs.SetTotalFlux(300, 0, 10)
+	for _, n := range g3.Boundary(300) {
+		_, val, _ := bcs.Value(n, 0, 0)
+		chk.Float64(tst, io.Sf("qn @ %d", n), 1e-15, val, 1.25)
+	}
+
+	// invalid tag
+	defer chk.RecoverTstPanicIsOK(tst)
+	bcs.SetTotalFlux(12, 0, 1)
+}
+
+func TestBryConds10(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("BryConds10. AddUsingTagWhere")
+
+	// split edge 20 at x = 2
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{4, 2}, []int{5, 3})
+	bcs := NewBoundaryCondsGrid(g, 1)
+	bcs.AddUsingTagWhere(20, 0, 1, nil, func(x la.Vector) bool { return x[0] < 2 })
+	bcs.AddUsingTagWhere(20, 0, 2, nil, func(x la.Vector) bool { return x[0] >= 2 })
+	chk.Ints(tst, "nodes", bcs.Nodes(), []int{0, 1, 2, 3, 4})
+	for n := 0; n < 5; n++ {
+		tags, val, _ := bcs.Value(n, 0, 0)
+		chk.Ints(tst, io.Sf("tags @ %d", n), tags, []int{20})
+		chk.Float64(tst, io.Sf("value @ %d", n), 1e-15, val, []float64{1, 1, 2, 2, 2}[n])
+	}
+}
+
+func TestBryConds11(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("BryConds11. SetAtPoint")
+
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{4, 2}, []int{5, 3})
+	bcs := NewBoundaryCondsGrid(g, 1)
+	node := bcs.SetAtPoint([]float64{2.1, 0.95}, 0, 123, nil, 0.2)
+	io.Pforan("node = %v\n", node)
+	chk.Int(tst, "node", node, g.IndexMNPtoI(2, 1, 0))
+	chk.Ints(tst, "nodes", bcs.Nodes(), []int{7})
+	tags, val, available := bcs.Value(7, 0, 0)
+	if !available {
+		tst.Errorf("value at node 7 should be available\n")
+		return
+	}
+	chk.Ints(tst, "tags", tags, nil)
+	chk.Float64(tst, "value", 1e-15, val, 123)
+
+	// boundary node with function value
+	node = bcs.SetAtPoint([]float64{4, 0}, 0, 0, func(x la.Vector, t float64) float64 { return x[0] + t }, 1e-10)
+	chk.Int(tst, "node", node, 4)
+	_, val, _ = bcs.Value(4, 0, 1)
+	chk.Float64(tst, "value", 1e-15, val, 5)
+}
+
+func TestBryConds12(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("BryConds12. SetAtPoint: point too far")
+
+	defer chk.RecoverTstPanicIsOK(tst)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{4, 2}, []int{5, 3})
+	bcs := NewBoundaryCondsGrid(g, 1)
+	bcs.SetAtPoint([]float64{2.5, 0.5}, 0, 123, nil, 0.2)
+}
+
+func TestBryConds13(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("BryConds13. SetSymmetryPlane")
+
+	// corner 4 is shared with edge 11
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{4, 2}, []int{5, 3})
+	bcs := NewBoundaryCondsGrid(g, 1)
+	bcs.AddUsingTag(11, 0, 3, nil)
+	bcs.SetSymmetryPlane(20, 0)
+	chk.Ints(tst, "nodes", bcs.Nodes(), []int{0, 1, 2, 3, 4, 9, 14})
+	for n := 0; n < 5; n++ {
+		tags, val, _ := bcs.Value(n, 0, 0)
+		chk.Ints(tst, io.Sf("tags @ %d", n), tags, [][]int{{20}, {20}, {20}, {20}, {11}}[n])
+		chk.Float64(tst, io.Sf("value @ %d", n), 1e-15, val, []float64{0, 0, 0, 0, 3}[n])
+	}
+
+	// invalid tag
+	defer chk.RecoverTstPanicIsOK(tst)
+	bcs.SetSymmetryPlane(22, 0)
+}
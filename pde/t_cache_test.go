@@ -0,0 +1,76 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+func TestCache01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Cache01. problem cache with sweeps over BC values and kx")
+
+	// sweep over BC values: u = v ⋅ x / 2 with u(0) = 0 and u(2) = v (insulated y-edges)
+	cache := new(ProblemCache)
+	defer cache.Free()
+	xmin, xmax, npts := []float64{0, 0}, []float64{2, 1}, []int{9, 5}
+	p := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}
+	for _, v := range []float64{1, -2, 3.5, 10} {
+		op := cache.Get("laplacian", p, xmin, xmax, npts, []int{10, 11})
+		u := cache.Solve([]float64{0, v})
+		for I := 0; I < op.Grid.Size(); I++ {
+			x := op.Grid.Node(I)
+			chk.Float64(tst, io.Sf("u(%g,%g) with v = %g", x[0], x[1], v), 1e-13, u[I], v*x[0]/2)
+		}
+	}
+	io.Pforan("Ngrids = %d, Nassem = %d, Nfact = %d\n", cache.Ngrids, cache.Nassem, cache.Nfact)
+	chk.Int(tst, "Ngrids", cache.Ngrids, 1)
+	chk.Int(tst, "Nassem", cache.Nassem, 1)
+	chk.Int(tst, "Nfact", cache.Nfact, 1)
+
+	// changing the source does not invalidate the factorisation: u = x² with L{u} = 2⋅kx
+	op := cache.Get("laplacian", p, xmin, xmax, npts, []int{10, 11})
+	op.Source = func(x la.Vector, t float64) float64 { return 2 }
+	u := cache.Solve([]float64{0, 4})
+	for I := 0; I < op.Grid.Size(); I++ {
+		x := op.Grid.Node(I)
+		chk.Float64(tst, io.Sf("u(%g,%g) with source", x[0], x[1]), 1e-13, u[I], x[0]*x[0])
+	}
+	op.Source = nil
+	chk.Int(tst, "Nfact (source)", cache.Nfact, 1)
+
+	// sweep over kx: new operator and factorisation each time; the grid is re-used
+	for k, kx := range []float64{0.5, 2, 4} {
+		p = dbf.Params{{N: "kx", V: kx}, {N: "ky", V: 1}}
+		op = cache.Get("laplacian", p, xmin, xmax, npts, []int{10, 11})
+		chk.Float64(tst, "Kx", 1e-15, op.Kx, kx)
+		cache.Solve([]float64{0, 1})
+		cache.Solve([]float64{0, 2})
+		chk.Int(tst, io.Sf("Nfact (kx = %g)", kx), cache.Nfact, 2+k)
+	}
+	chk.Int(tst, "Ngrids", cache.Ngrids, 1)
+	chk.Int(tst, "Nassem", cache.Nassem, 4)
+
+	// new geometry and new tags
+	cache.Get("laplacian", p, xmin, []float64{3, 1}, npts, []int{10, 11})
+	chk.Int(tst, "Ngrids (geometry)", cache.Ngrids, 2)
+	cache.Get("laplacian", p, xmin, []float64{3, 1}, npts, []int{10, 11, 20})
+	u = cache.Solve([]float64{1, 1, 1})
+	chk.Int(tst, "Ngrids (tags)", cache.Ngrids, 2)
+	chk.Int(tst, "Nassem (tags)", cache.Nassem, 6)
+	chk.Int(tst, "Nfact (tags)", cache.Nfact, 5)
+	chk.Array(tst, "u = 1", 1e-13, u, utl.Ones(len(u)))
+
+	// wrong number of values
+	defer chk.RecoverTstPanicIsOK(tst)
+	cache.Solve([]float64{1, 1})
+}
@@ -0,0 +1,85 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+)
+
+func TestComponents01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Components01. split masked domain")
+
+	// 5x4 grid with inactive middle column (m = 2)
+	//
+	//   15  16  ..  18  19
+	//   10  11  ..  13  14
+	//    5   6  ..   8   9
+	//    0   1  ..   3   4
+	//
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{4, 3}, []int{5, 4})
+	active := make([]bool, g.Size())
+	for I := 0; I < g.Size(); I++ {
+		m, _, _ := g.IndexItoMNP(I)
+		active[I] = m != 2
+	}
+
+	// check
+	labels := ConnectedComponents(g, active)
+	io.Pforan("labels = %v\n", labels)
+	chk.Ints(tst, "labels", labels, []int{
+		0, 0, -1, 1, 1,
+		0, 0, -1, 1, 1,
+		0, 0, -1, 1, 1,
+		0, 0, -1, 1, 1,
+	})
+
+	// reconnect using one node of the middle column
+	active[g.IndexMNPtoI(2, 3, 0)] = true
+	labels = ConnectedComponents(g, active)
+	for I := 0; I < g.Size(); I++ {
+		if active[I] {
+			chk.Int(tst, io.Sf("label @ %d", I), labels[I], 0)
+		}
+	}
+
+	// diagonal neighbours are not connected
+	for I := 0; I < g.Size(); I++ {
+		m, n, _ := g.IndexItoMNP(I)
+		active[I] = (m == 0 && n == 0) || (m == 1 && n == 1)
+	}
+	labels = ConnectedComponents(g, active)
+	chk.Int(tst, "label @ 0", labels[0], 0)
+	chk.Int(tst, "label @ 6", labels[6], 1)
+}
+
+func TestComponents02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Components02. 3D")
+
+	// 3x3x3 grid with inactive middle plane (p = 1)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0, 0}, []float64{1, 1, 1}, []int{3, 3, 3})
+	active := make([]bool, g.Size())
+	for I := 0; I < g.Size(); I++ {
+		_, _, p := g.IndexItoMNP(I)
+		active[I] = p != 1
+	}
+
+	// check
+	labels := ConnectedComponents(g, active)
+	for I := 0; I < g.Size(); I++ {
+		_, _, p := g.IndexItoMNP(I)
+		correct := []int{0, -1, 1}[p]
+		chk.Int(tst, io.Sf("label @ %d", I), labels[I], correct)
+	}
+}
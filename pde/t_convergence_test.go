@@ -0,0 +1,209 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func TestConvergence01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Convergence01. Laplacian with smooth solution")
+
+	// u = sin(πx)⋅cosh(y) + x⋅y²  ⇒  ∇²u = (1 - π²)⋅sin(πx)⋅cosh(y) + 2x
+	uExact := func(x la.Vector, t float64) float64 {
+		return math.Sin(math.Pi*x[0])*math.Cosh(x[1]) + x[0]*x[1]*x[1]
+	}
+	source := func(x la.Vector, t float64) float64 {
+		return (1-math.Pi*math.Pi)*math.Sin(math.Pi*x[0])*math.Cosh(x[1]) + 2*x[0]
+	}
+	p := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}
+	xmin, xmax := []float64{0, 0}, []float64{1, 1}
+
+	// 5-point stencil
+	table := ConvergenceStudy("laplacian", p, xmin, xmax, []int{8, 16, 32}, uExact, source)
+	io.Pf("%v", table)
+	chk.Array(tst, "h", 1e-15, table.H, []float64{1.0 / 8, 1.0 / 16, 1.0 / 32})
+	chk.Float64(tst, "order[1]", 0.05, table.Order[1], 2)
+	chk.Float64(tst, "order[2]", 0.05, table.Order[2], 2)
+	chk.Float64(tst, "slope", 0.05, table.Slope, 2)
+
+	// compact 9-point stencil
+	table = ConvergenceStudy("laplacian9", p, xmin, xmax, []int{8, 16, 32}, uExact, source)
+	io.Pf("%v", table)
+	chk.Float64(tst, "slope (laplacian9)", 0.1, table.Slope, 4)
+
+	// invalid input
+	check := func(msg, opName string, xmin, xmax []float64) {
+		defer func() {
+			if err := recover(); err == nil {
+				tst.Errorf("%s: should have panicked\n", msg)
+			}
+		}()
+		ConvergenceStudy(opName, p, xmin, xmax, []int{8, 16}, uExact, source)
+	}
+	check("invalid operator", "biharmonic", xmin, xmax)
+	check("different lengths", "laplacian", xmin, []float64{1, 1, 1})
+	check("3D", "laplacian", []float64{0, 0, 0}, []float64{1, 1, 1})
+}
+
+func TestConvergence02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Convergence02. truncation error of Laplacian")
+
+	// u = x² + x⋅y + 3y²  ⇒  kx⋅∂²u/∂x² + ky⋅∂²u/∂y² = 2⋅kx + 6⋅ky
+	uExact := func(x la.Vector, t float64) float64 {
+		return x[0]*x[0] + x[0]*x[1] + 3*x[1]*x[1]
+	}
+	lExact := func(x la.Vector, t float64) float64 {
+		return 2*1 + 6*2 // kx = 1 and ky = 2
+	}
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{5, 5})
+	op := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 2}}, g, nil)
+	op.AddEbc(21, 0, uExact) // top
+	op.Assemble(false)
+	tau := TruncationError(op, g, uExact, lExact)
+	io.Pforan("τ = %v\n", tau)
+
+	// interior nodes: exact for quadratic functions
+	h := 0.25
+	for j := 0; j < 5; j++ {
+		for i := 0; i < 5; i++ {
+			I := g.IndexMNPtoI(i, j, 0)
+			y := float64(j) * h
+			switch {
+			case j == 4: // prescribed
+				chk.Float64(tst, "τ (top)", 1e-15, tau[I], 0)
+			case i > 0 && i < 4 && j > 0:
+				chk.Float64(tst, "τ (interior)", 1e-12, tau[I], 0)
+			case i == 0 && j > 0: // left: mirrored node ⇒ τ = 2⋅kx⋅(∂u/∂x)/h with ∂u/∂x = y
+				chk.Float64(tst, "τ (left)", 1e-12, tau[I], 2*y/h)
+			}
+		}
+	}
+
+	// wrong grid
+	defer chk.RecoverTstPanicIsOK(tst)
+	TruncationError(op, new(gm.Grid), uExact, lExact)
+}
+
+func TestConvergence03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Convergence03. CheckSelfAdjoint")
+
+	// Dirichlet-Laplacian is symmetric
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{9, 6})
+	p := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 2}}
+	s := NewFdmLaplacian(p, g, nil)
+	for _, tag := range []int{10, 11, 20, 21} {
+		s.AddEbc(tag, 0, nil)
+	}
+	s.Assemble(false)
+	ok, viol := CheckSelfAdjoint(s.Eqs, 1e-14)
+	io.Pforan("Dirichlet: ok = %v, violation = %g\n", ok, viol)
+	if !ok {
+		tst.Errorf("Dirichlet-Laplacian should be self-adjoint. violation = %g\n", viol)
+	}
+
+	// the equations of boundary nodes with ghost nodes are not symmetric
+	s = NewFdmLaplacian(p, g, nil)
+	s.AddEbc(10, 0, nil)
+	s.Assemble(false)
+	ok, viol = CheckSelfAdjoint(s.Eqs, 1e-14)
+	io.Pforan("ghost nodes: ok = %v, violation = %g\n", ok, viol)
+	if ok {
+		tst.Errorf("operator with ghost nodes should not be self-adjoint. violation = %g\n", viol)
+	}
+
+	// intentionally asymmetrised operator
+	s = NewFdmLaplacian(p, g, nil)
+	for _, tag := range []int{10, 11, 20, 21} {
+		s.AddEbc(tag, 0, nil)
+	}
+	s.Assemble(false)
+	s.Eqs.Auu.Put(0, 1, 1e-3)
+	ok, viol = CheckSelfAdjoint(s.Eqs, 1e-14)
+	io.Pforan("asymmetrised: ok = %v, violation = %g\n", ok, viol)
+	if ok {
+		tst.Errorf("asymmetrised operator should not be self-adjoint. violation = %g\n", viol)
+	}
+}
+
+func TestConvergence04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Convergence04. BilinearForm of discrete eigenmodes")
+
+	// Dirichlet-Laplacian on [0,2]×[0,1]
+	nx, ny := 11, 7
+	lx, ly := 2.0, 1.0
+	kx, ky := 1.0, 2.0
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{lx, ly}, []int{nx, ny})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: kx}, {N: "ky", V: ky}}, g, nil)
+	for _, tag := range []int{10, 11, 20, 21} {
+		s.AddEbc(tag, 0, nil)
+	}
+	s.Assemble(false)
+
+	// eigenmodes sin(mπx/lx)⋅sin(nπy/ly) are exact eigenvectors of the discrete operator
+	hx, hy := lx/float64(nx-1), ly/float64(ny-1)
+	mode := func(m, n int) (phi []float64) {
+		phi = make([]float64, g.Size())
+		for I := range phi {
+			x := g.Node(I)
+			phi[I] = math.Sin(float64(m)*math.Pi*x[0]/lx) * math.Sin(float64(n)*math.Pi*x[1]/ly)
+		}
+		return
+	}
+	lambda := func(m, n int) float64 {
+		sx := math.Sin(float64(m) * math.Pi * hx / (2 * lx))
+		sy := math.Sin(float64(n) * math.Pi * hy / (2 * ly))
+		return -4*kx*sx*sx/(hx*hx) - 4*ky*sy*sy/(hy*hy)
+	}
+	phi11, phi21, phi12 := mode(1, 1), mode(2, 1), mode(1, 2)
+
+	// orthogonality
+	a1121 := BilinearForm(s.Eqs, phi11, phi21)
+	a1112 := BilinearForm(s.Eqs, phi11, phi12)
+	io.Pforan("a(φ11,φ21) = %g, a(φ11,φ12) = %g\n", a1121, a1112)
+	chk.Float64(tst, "a(φ11,φ21)", 1e-12, a1121, 0)
+	chk.Float64(tst, "a(φ11,φ12)", 1e-12, a1112, 0)
+
+	// energy of mode: λ⋅‖φ‖²
+	a1111 := BilinearForm(s.Eqs, phi11, phi11)
+	norm2 := 0.0
+	for _, I := range s.Eqs.UtoF {
+		norm2 += phi11[I] * phi11[I]
+	}
+	io.Pforan("a(φ11,φ11) = %g\n", a1111)
+	chk.Float64(tst, "a(φ11,φ11)", 1e-11, a1111, lambda(1, 1)*norm2)
+	if a1111 >= 0 {
+		tst.Errorf("a(φ11,φ11) should be negative. %g is invalid\n", a1111)
+	}
+
+	// reduced vectors give the same result
+	u := la.NewVector(s.Eqs.Nu)
+	for i, I := range s.Eqs.UtoF {
+		u[i] = phi11[I]
+	}
+	chk.Float64(tst, "a(φ11,φ11) reduced", 1e-15, BilinearForm(s.Eqs, u, u), a1111)
+
+	// wrong length
+	defer chk.RecoverTstPanicIsOK(tst)
+	BilinearForm(s.Eqs, phi11[1:], phi11)
+}
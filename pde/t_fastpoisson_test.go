@@ -0,0 +1,114 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// precondJacobi implements the Jacobi (diagonal) preconditioner for comparisons
+type precondJacobi struct {
+	diag la.Vector
+}
+
+func (o *precondJacobi) Apply(z, r la.Vector) {
+	for i := range r {
+		z[i] = r[i] / o.diag[i]
+	}
+}
+
+func TestFastPoisson01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("FastPoisson01. exact inverse with constant coefficients")
+
+	// Dirichlet problem
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{11, 7})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1.5}, {N: "ky", V: 0.5}}, g, nil)
+	for _, tag := range []int{10, 11, 20, 21} {
+		s.AddEbc(tag, 0, nil)
+	}
+	s.Assemble(false)
+
+	// M⁻¹⋅(A⋅v) = v
+	pc := NewPrecondFastPoisson(g, 1.5, 0.5)
+	a := s.Eqs.Auu.ToMatrix(nil)
+	v := la.NewVector(s.Eqs.Nu)
+	for i := range v {
+		v[i] = math.Sin(float64(i)) + 0.5
+	}
+	av := la.NewVector(s.Eqs.Nu)
+	la.SpMatVecMul(av, 1, a, v)
+	z := la.NewVector(s.Eqs.Nu)
+	pc.Apply(z, av)
+	chk.Array(tst, "M⁻¹⋅A⋅v", 1e-12, z, v)
+
+	// CG converges in one iteration
+	b := la.NewVector(s.Eqs.Nu)
+	b.Fill(1)
+	x := la.NewVector(s.Eqs.Nu)
+	nit := la.ConjGrad(x, a, b, pc, 1e-10, 10)
+	chk.Int(tst, "nit", nit, 1)
+}
+
+func TestFastPoisson02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("FastPoisson02. preconditioner for variable coefficients")
+
+	// mildly variable coefficient
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{41, 41})
+	kField := make([]float64, g.Size())
+	kmean := 0.0
+	for I := 0; I < g.Size(); I++ {
+		x := g.Node(I)
+		kField[I] = 1 + 0.3*math.Sin(math.Pi*x[0])*math.Sin(math.Pi*x[1])
+		kmean += kField[I] / float64(g.Size())
+	}
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, func(x la.Vector, t float64) float64 { return -1 })
+	for _, tag := range []int{10, 11, 20, 21} {
+		s.AddEbc(tag, 0, nil)
+	}
+	s.AssembleWithCoeffField(kField, false)
+	uref, _ := s.SolveSteady(false)
+
+	// right-hand side and matrix
+	a := s.Eqs.Auu.ToMatrix(nil)
+	b := la.NewVector(s.Eqs.Nu)
+	b.Fill(-1)
+	jac := &precondJacobi{la.NewVector(s.Eqs.Nu)}
+	for i, I := range s.Eqs.UtoF {
+		nodes, coefs := s.StencilAt(I)
+		for k, J := range nodes {
+			if J == I {
+				jac.diag[i] = coefs[k]
+			}
+		}
+	}
+
+	// CG with Jacobi and fast Poisson preconditioners
+	xJ := la.NewVector(s.Eqs.Nu)
+	nitJ := la.ConjGrad(xJ, a, b, jac, 1e-10, 2000)
+	xP := la.NewVector(s.Eqs.Nu)
+	nitP := la.ConjGrad(xP, a, b, NewPrecondFastPoisson(g, kmean, kmean), 1e-10, 2000)
+	io.Pforan("nit: Jacobi = %d, fast Poisson = %d\n", nitJ, nitP)
+	if nitP > 20 || 5*nitP > nitJ {
+		tst.Errorf("fast Poisson preconditioner should converge much faster: nit = %d (Jacobi: %d)\n", nitP, nitJ)
+	}
+
+	// solutions
+	u := make([]float64, g.Size())
+	s.Eqs.JoinVector(u, xP, s.Eqs.Xk)
+	chk.Array(tst, "u", 1e-9, u, uref)
+}
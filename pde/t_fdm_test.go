@@ -5,6 +5,9 @@
 package pde
 
 import (
+	"encoding/json"
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/cpmech/gosl/chk"
@@ -13,6 +16,8 @@ import (
 	"github.com/cpmech/gosl/io"
 	"github.com/cpmech/gosl/la"
 	"github.com/cpmech/gosl/plt"
+	"github.com/cpmech/gosl/rnd"
+	"github.com/cpmech/gosl/utl"
 )
 
 func TestFdm01a(tst *testing.T) {
@@ -199,3 +204,2920 @@ func TestFdm03(tst *testing.T) {
 		plt.Save("/tmp/gosl/pde", "fdm03")
 	}
 }
+
+func TestFdm04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm04. obstacle problem (1D)")
+
+	// solve problem
+	//    ∂²u
+	//    ——— = 0 where u > ψ   with   u ≥ ψ   u(0,y)=0   u(1,y)=0
+	//    ∂x²
+	// with
+	//    ψ(x) = h - c⋅(x - ½)²
+	// the solution is (a is the contact point and s = ψ(a)/a the slope of the free part):
+	//    u(x) = s⋅x for x < a;   u(x) = ψ(x) for a ≤ x ≤ 1-a;   u(x) = s⋅(1-x) for x > 1-a
+
+	// grid: 1D strip with zero-flux top and bottom
+	nx := 41
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 0.1}, []int{nx, 3})
+
+	// obstacle and analytical solution
+	h, c := 0.25, 8.0
+	ψ := func(x float64) float64 { return h - c*(x-0.5)*(x-0.5) }
+	d := (-c + math.Sqrt(c*c-4.0*c*h)) / (2.0 * c)
+	a := 0.5 + d
+	slope := ψ(a) / a
+	ana := func(x float64) float64 {
+		if x < a {
+			return slope * x
+		}
+		if x > 1.0-a {
+			return slope * (1.0 - x)
+		}
+		return ψ(x)
+	}
+
+	// solver
+	p := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}
+	s := NewFdmLaplacian(p, g, nil)
+	s.AddEbc(10, 0.0, nil) // left
+	s.AddEbc(11, 0.0, nil) // right
+	s.Assemble(false)
+
+	// solve
+	lower := make([]float64, g.Size())
+	for I := 0; I < g.Size(); I++ {
+		lower[I] = ψ(g.Node(I)[0])
+	}
+	u := s.SolveConstrained(lower)
+
+	// check
+	for I := 0; I < g.Size(); I++ {
+		x := g.Node(I)[0]
+		if u[I] < lower[I]-1e-14 {
+			tst.Errorf("constraint is violated at node %d: u=%g < ψ=%g\n", I, u[I], lower[I])
+			return
+		}
+		chk.AnaNum(tst, io.Sf("u(%.3f)", x), 5e-3, u[I], ana(x), chk.Verbose)
+	}
+	chk.Float64(tst, "u(½)", 1e-14, u[g.IndexMNPtoI(nx/2, 1, 0)], h)
+
+	// not assembled yet
+	defer chk.RecoverTstPanicIsOK(tst)
+	NewFdmLaplacian(p, g, nil).SolveConstrained(lower)
+}
+
+func TestFdm05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm05. Dirichlet conditions at interior nodes (baffle)")
+
+	// 7x5 grid with baffle @ row n=2 and columns m=2,3,4
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{6, 4}, []int{7, 5})
+	baffle := []int{g.IndexMNPtoI(2, 2, 0), g.IndexMNPtoI(3, 2, 0), g.IndexMNPtoI(4, 2, 0)}
+
+	// operator
+	p := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}
+	s := NewFdmLaplacian(p, g, nil)
+	s.SetHbc()
+	s.AddEbcNodes(baffle, 1.0, nil)
+
+	// check equations
+	s.Assemble(false)
+	chk.Int(tst, "Nk", s.Eqs.Nk, 2*7+2*3+3)
+	for _, I := range baffle {
+		if s.Eqs.FtoU[I] >= 0 {
+			tst.Errorf("node %d should be in the prescribed set\n", I)
+		}
+	}
+
+	// solve
+	u, _ := s.SolveSteady(false)
+	io.Pforan("u = %v\n", u)
+	for _, I := range baffle {
+		chk.Float64(tst, io.Sf("u @ baffle %d", I), 1e-17, u[I], 1.0)
+	}
+
+	// symmetry
+	uu := g.MapMeshgrid2d(u)
+	for n := 0; n < 5; n++ {
+		for m := 0; m < 7; m++ {
+			chk.Float64(tst, io.Sf("u[%d][%d] symmetric", n, m), 1e-14, uu[n][m], uu[n][6-m])
+			chk.Float64(tst, io.Sf("u[%d][%d] symmetric", n, m), 1e-14, uu[n][m], uu[4-n][m])
+		}
+	}
+
+	// linear field is reproduced exactly
+	ana := func(x la.Vector, t float64) float64 { return 1.0 + 2.0*x[0] - x[1] }
+	s = NewFdmLaplacian(p, g, nil)
+	for _, tag := range []int{10, 11, 20, 21} {
+		s.AddEbc(tag, 0, ana)
+	}
+	s.AddEbcNodes(baffle, 0, ana)
+	s.Assemble(false)
+	u, _ = s.SolveSteady(false)
+	for I := 0; I < g.Size(); I++ {
+		chk.AnaNum(tst, io.Sf("u @ %d", I), 1e-13, u[I], ana(g.Node(I), 0), chk.Verbose)
+	}
+}
+
+func TestFdm06(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm06. full conductivity tensor (cross term)")
+
+	// principal conductivities k1, k2 along axes rotated by θ = 45°
+	//   kxx = k1⋅cos²θ + k2⋅sin²θ
+	//   kyy = k1⋅sin²θ + k2⋅cos²θ
+	//   kxy = (k1 - k2)⋅sinθ⋅cosθ
+	rotate := func(k1, k2, θ float64) dbf.Params {
+		c, s := math.Cos(θ), math.Sin(θ)
+		return dbf.Params{
+			{N: "kx", V: k1*c*c + k2*s*s},
+			{N: "ky", V: k1*s*s + k2*c*c},
+			{N: "kxy", V: (k1 - k2) * s * c},
+		}
+	}
+
+	// 6x5 grid
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2.5, 1}, []int{6, 5})
+
+	// anisotropic medium: the stencil is exact for quadratic solutions
+	//   u = x² + x⋅y - 2 y²   ⇒   L{u} = 2 kxx + 2 kxy - 4 kyy
+	p := rotate(3.0, 1.0, math.Pi/4.0)
+	kxx, kyy, kxy := p[0].V, p[1].V, p[2].V
+	ana := func(x la.Vector, t float64) float64 { return x[0]*x[0] + x[0]*x[1] - 2.0*x[1]*x[1] }
+	src := func(x la.Vector, t float64) float64 { return 2.0*kxx + 2.0*kxy - 4.0*kyy }
+	s := NewFdmLaplacian(p, g, src)
+	for _, tag := range []int{10, 11, 20, 21} {
+		s.AddEbc(tag, 0, ana)
+	}
+
+	// symmetry
+	s.Assemble(false)
+	Duu := s.Eqs.Auu.ToDense()
+	chk.Deep2(tst, "Auu == Auuᵀ", 1e-15, Duu.GetDeep2(), Duu.GetTranspose().GetDeep2())
+	// node 14 @ (2,2): node 21 @ (3,3) and node 19 @ (1,3)
+	chk.Float64(tst, "A[14][21]", 1e-14, Duu.Get(s.Eqs.FtoU[14], s.Eqs.FtoU[21]), kxy/(2.0*0.5*0.25))
+	chk.Float64(tst, "A[14][19]", 1e-14, Duu.Get(s.Eqs.FtoU[14], s.Eqs.FtoU[19]), -kxy/(2.0*0.5*0.25))
+
+	// solve
+	u, _ := s.SolveSteady(false)
+	for I := 0; I < g.Size(); I++ {
+		chk.AnaNum(tst, io.Sf("u @ %d", I), 1e-13, u[I], ana(g.Node(I), 0), chk.Verbose)
+	}
+
+	// rotated isotropic tensor is equivalent to the isotropic operator
+	p = rotate(2.0, 2.0, math.Pi/4.0)
+	chk.Float64(tst, "kxy", 1e-15, p[2].V, 0)
+	src = func(x la.Vector, t float64) float64 { return math.Sin(x[0]) * x[1] }
+	s = NewFdmLaplacian(p, g, src)
+	s.SetHbc()
+	s.Assemble(false)
+	u, _ = s.SolveSteady(false)
+	iso := NewFdmLaplacian(dbf.Params{{N: "kx", V: 2}, {N: "ky", V: 2}}, g, src)
+	iso.SetHbc()
+	iso.Assemble(false)
+	uiso, _ := iso.SolveSteady(false)
+	chk.Array(tst, "u(rotated isotropic)", 1e-14, u, uiso)
+}
+
+func TestFdm07(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm07. write CSV file")
+
+	// Laplace problem of Fdm02
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{3, 3}, []int{4, 4})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	s.AddEbc(10, 1.0, nil) // left
+	s.AddEbc(11, 2.0, nil) // right
+	s.AddEbc(20, 1.0, nil) // bottom
+	s.AddEbc(21, 2.0, nil) // top
+	s.Assemble(false)
+	u, _ := s.SolveSteady(false)
+
+	// write
+	s.WriteCSV("/tmp/gosl/pde", "fdm07", u)
+
+	// read back
+	var header string
+	var table [][]float64
+	io.ReadLines("/tmp/gosl/pde/fdm07.csv", func(idx int, line string) (stop bool) {
+		if idx == 0 {
+			header = line
+			return
+		}
+		table = append(table, io.SplitFloats(strings.Replace(line, ",", " ", -1)))
+		return
+	})
+
+	// check
+	chk.String(tst, header, "x,y,u")
+	chk.Int(tst, "number of rows", len(table), g.Size())
+	for I, row := range table {
+		x := g.Node(I)
+		chk.Array(tst, io.Sf("row %d", I), 1e-17, row, []float64{x[0], x[1], u[I]})
+	}
+}
+
+func TestFdm08(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm08. discrete source (manufactured solution)")
+
+	// exact solution (not reproduced by the 5-point stencil)
+	uExact := func(x la.Vector, t float64) float64 {
+		return math.Exp(x[0]) * math.Sin(2.0*x[1]) * (1.0 + x[0]*x[1])
+	}
+
+	// 7x6 grid with essential conditions from exact solution (left, right, bottom); top is mirrored
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1.5, 1}, []int{7, 6})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 2}}, g, nil)
+	s.AddEbc(10, 0, uExact)
+	s.AddEbc(11, 0, uExact)
+	s.AddEbc(20, 0, uExact)
+	s.Assemble(false)
+
+	// discrete source
+	f := s.DiscreteSource(uExact)
+	for _, I := range s.Eqs.KtoF {
+		chk.Float64(tst, io.Sf("f @ %d", I), 1e-17, f[I], 0)
+	}
+
+	// solve with discrete source
+	s.Eqs.SolveOnce(s.calcXk, func(I int, t float64) float64 { return f[I] })
+	u := make([]float64, g.Size())
+	s.Eqs.JoinVector(u, s.Eqs.Xu, s.Eqs.Xk)
+
+	// check
+	for I := 0; I < g.Size(); I++ {
+		chk.AnaNum(tst, io.Sf("u @ %d", I), 1e-13, u[I], uExact(g.Node(I), 0), chk.Verbose)
+	}
+}
+
+func TestFdm09(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm09. spatially varying reaction (absorption) term")
+
+	// 5x4 grid
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1.5}, []int{5, 4})
+
+	// operator with absorption kr(x,y) = 1 + x⋅y
+	kr := func(x la.Vector, t float64) float64 { return 1.0 + x[0]*x[1] }
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 2}}, g, nil)
+	s.Reaction = kr
+	s.SetHbc()
+	s.Assemble(false)
+
+	// check diagonal
+	dx, dy := 0.5, 0.5
+	α := -2.0 * (1.0/(dx*dx) + 2.0/(dy*dy))
+	Duu := s.Eqs.Auu.ToDense()
+	for i, I := range s.Eqs.UtoF {
+		chk.Float64(tst, io.Sf("A[%d][%d]", I, I), 1e-15, Duu.Get(i, i), α-kr(g.Node(I), 0))
+	}
+	chk.Deep2(tst, "Auu == Auuᵀ", 1e-15, Duu.GetDeep2(), Duu.GetTranspose().GetDeep2())
+
+	// constant coefficient (1D strip): u = sin(πx) ⇒ s = -(π² + kr)⋅sin(πx)
+	g = new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 0.1}, []int{41, 3})
+	s = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}, {N: "kr", V: 3}}, g, func(x la.Vector, t float64) float64 {
+		return -(math.Pi*math.Pi + 3.0) * math.Sin(math.Pi*x[0])
+	})
+	s.AddEbc(10, 0, nil)
+	s.AddEbc(11, 0, nil)
+	s.Assemble(false)
+	u, _ := s.SolveSteady(false)
+	for I := 0; I < g.Size(); I++ {
+		chk.AnaNum(tst, io.Sf("u @ %d", I), 1e-3, u[I], math.Sin(math.Pi*g.Node(I)[0]), chk.Verbose)
+	}
+}
+
+func TestFdm10(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm10. update and reapply boundary conditions")
+
+	// 6x5 grid
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{5, 4}, []int{6, 5})
+
+	// operator with source
+	p := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}
+	source := func(x la.Vector, t float64) float64 { return x[0] - x[1] }
+	s := NewFdmLaplacian(p, g, source)
+	defer s.Free()
+	s.AddEbc(10, 1.0, nil) // left
+	s.AddEbc(11, 2.0, nil) // right
+	s.AddEbc(21, 3.0, nil) // top
+
+	// first solve
+	s.Assemble(false)
+	u0 := s.ReapplyBcs()
+	uref, _ := s.SolveSteady(false)
+	chk.Array(tst, "u0", 1e-13, u0, uref)
+
+	// update top edge and reapply
+	s.UpdateEbc(21, 0, func(x la.Vector, t float64) float64 { return math.Sin(x[0]) })
+	u1 := s.ReapplyBcs()
+
+	// fresh solve
+	sNew := NewFdmLaplacian(p, g, source)
+	sNew.AddEbc(10, 1.0, nil)
+	sNew.AddEbc(11, 2.0, nil)
+	sNew.AddEbc(21, 0, func(x la.Vector, t float64) float64 { return math.Sin(x[0]) })
+	sNew.Assemble(false)
+	u1ref, _ := sNew.SolveSteady(false)
+	io.Pforan("u1 = %v\n", u1)
+	chk.Array(tst, "u1", 1e-13, u1, u1ref)
+
+	// corner nodes of top edge received the new value
+	top := g.EdgeGivenTag(21)
+	chk.Float64(tst, "u @ top-left", 1e-15, u1[top[0]], 0)
+	chk.Float64(tst, "u @ top-right", 1e-15, u1[top[len(top)-1]], math.Sin(5))
+
+	// update of unknown tag
+	defer chk.RecoverTstPanicIsOK(tst)
+	s.UpdateEbc(20, 0, nil)
+}
+
+func TestFdm11(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm11. edge fluxes and conservation")
+
+	// solve problem
+	//    ∂²u     ∂²u
+	//    ———  +  ——— = 0    with   u = exp(x)⋅sin(y) on all boundaries
+	//    ∂x²     ∂y²
+
+	// exact flux leaving the domain through each edge
+	//   left:   +∫ sin(y) dy           = 1 - cos(1)
+	//   right:  -∫ e⋅sin(y) dy         = e⋅(cos(1) - 1)
+	//   bottom: +∫ exp(x) dx           = e - 1
+	//   top:    -∫ exp(x)⋅cos(1) dx    = -cos(1)⋅(e - 1)
+	c1, e := math.Cos(1), math.E
+	correct := map[int]float64{10: 1 - c1, 11: e * (c1 - 1), 20: e - 1, 21: -c1 * (e - 1)}
+
+	// grid and operator
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{41, 41})
+	p := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}
+	s := NewFdmLaplacian(p, g, nil)
+	uana := func(x la.Vector, t float64) float64 { return math.Exp(x[0]) * math.Sin(x[1]) }
+	for _, tag := range []int{10, 11, 20, 21} {
+		s.AddEbc(tag, 0, uana)
+	}
+	s.Assemble(false)
+	u, _ := s.SolveSteady(false)
+
+	// check fluxes
+	net := 0.0
+	for _, tag := range []int{10, 11, 20, 21} {
+		q := s.EdgeFlux(tag, u)
+		io.Pforan("Q(%d) = %23.15e  (correct = %23.15e)\n", tag, q, correct[tag])
+		chk.Float64(tst, io.Sf("Q(%d)", tag), 1e-3, q, correct[tag])
+		net += q
+	}
+
+	// conservation: net flux is zero (no source), up to the discretisation error
+	io.Pforan("net flux = %g\n", net)
+	chk.Float64(tst, "net flux", 1e-3, net, 0)
+}
+
+func TestFdm12(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm12. switching between named operators")
+
+	// grid, source and boundary conditions
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{9, 7})
+	source := func(x la.Vector, t float64) float64 { return -1.0 - x[0] }
+	setBcs := func(s *FdmLaplacian) {
+		s.AddEbc(10, 1.0, nil)
+		s.AddEbc(11, 0.0, nil)
+		s.AddEbc(20, 0.0, nil)
+	}
+
+	// parameters
+	pLap := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}
+	pScr := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}, {N: "kr", V: 10}}
+	pAni := dbf.Params{{N: "kx", V: 2}, {N: "ky", V: 1}, {N: "kxy", V: 0.5}}
+
+	// solver with named operators
+	s := NewFdmLaplacian(pLap, g, source)
+	setBcs(s)
+	s.AddOperator("laplacian", pLap, nil)
+	s.AddOperator("screened-poisson", pScr, nil)
+	s.AddOperator("anisotropic", pAni, nil)
+
+	// check each operator against a dedicated solver
+	for _, name := range []string{"screened-poisson", "laplacian", "anisotropic", "screened-poisson"} {
+		s.SwitchOperator(name, false)
+		u, _ := s.SolveSteady(false)
+		p := map[string]dbf.Params{"laplacian": pLap, "screened-poisson": pScr, "anisotropic": pAni}[name]
+		sRef := NewFdmLaplacian(p, g, source)
+		setBcs(sRef)
+		sRef.Assemble(false)
+		uRef, _ := sRef.SolveSteady(false)
+		io.Pforan("%-16s: u[30] = %g\n", name, u[30])
+		chk.Array(tst, "u("+name+")", 1e-14, u, uRef)
+	}
+
+	// unknown operator
+	defer chk.RecoverTstPanicIsOK(tst)
+	s.SwitchOperator("helmholtz", false)
+}
+
+func TestFdm13(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm13. stencil at node")
+
+	// 5x5 grid with unit spacing
+	//
+	//   20  21  22  23  24
+	//   15  16  17  18  19
+	//   10  11  12  13  14
+	//    5   6   7   8   9
+	//    0   1   2   3   4
+	//
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{4, 4}, []int{5, 5})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	s.AddEbc(10, 0, nil) // left
+	s.Assemble(true)
+
+	// interior node: L{u} ≈ ∇²u ⇒ center = -4 and neighbours = +1; i.e. the negative of the
+	// usual {4, -1, -1, -1, -1} stencil of -∇²u
+	nodes, coefs := s.StencilAt(12)
+	io.Pforan("nodes = %v\ncoefs = %v\n", nodes, coefs)
+	chk.Ints(tst, "nodes @ 12", nodes, []int{7, 11, 12, 13, 17})
+	chk.Array(tst, "coefs @ 12", 1e-15, coefs, []float64{1, 1, -4, 1, 1})
+
+	// right boundary (mirrored left neighbour)
+	nodes, coefs = s.StencilAt(14)
+	chk.Ints(tst, "nodes @ 14", nodes, []int{9, 13, 14, 19})
+	chk.Array(tst, "coefs @ 14", 1e-15, coefs, []float64{1, 2, -4, 1})
+
+	// node with prescribed value
+	nodes, coefs = s.StencilAt(10)
+	chk.Ints(tst, "nodes @ 10", nodes, []int{5, 10, 11, 15})
+	chk.Array(tst, "coefs @ 10", 1e-15, coefs, []float64{1, -4, 2, 1})
+}
+
+func TestFdm14(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm14. natural boundary condition with total flux")
+
+	// solve problem
+	//       ∂²u              ∂u                          ∂u
+	//    kx ——— = 0    with  ——(x,0) = 0 = ——(x,1)       u(0,y) = 0
+	//       ∂x²              ∂y            ∂y
+	//
+	//    and total flux Q entering the domain through the right edge (x = 2)
+	//
+	//    solution: u = Q⋅x / (kx⋅Ly)
+
+	// grid and operator
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{11, 6})
+	kx, Q := 2.0, 3.0
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: kx}, {N: "ky", V: 1}}, g, nil)
+	s.AddEbc(10, 0, nil)
+	s.NaturBcs.SetTotalFlux(11, 0, Q)
+	s.Assemble(false)
+
+	// RHS contributions weighted by the area of the control volume of each node sum up to the
+	// total flux; the sign is negative because A ≈ ∇² is on the left-hand side of A⋅u = b
+	area := GridQuadrature(g)
+	sum := 0.0
+	for _, I := range s.Eqs.UtoF {
+		sum += area[I] * s.calcBu(I, 0)
+	}
+	io.Pforan("Σ area⋅bu = %g\n", sum)
+	chk.Float64(tst, "Σ area⋅bu", 1e-14, sum, -Q)
+
+	// solution
+	u, _ := s.SolveSteady(false)
+	uana := make([]float64, g.Size())
+	for I := 0; I < g.Size(); I++ {
+		uana[I] = Q * g.Node(I)[0] / (kx * 1.0)
+	}
+	chk.Array(tst, "u", 1e-13, u, uana)
+
+	// flux leaving through the right edge
+	chk.Float64(tst, "EdgeFlux(11)", 1e-13, s.EdgeFlux(11, u), -Q)
+}
+
+func TestFdm15(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm15. source from sampled values")
+
+	// grid, parameters and source function
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 2}, []int{7, 9})
+	p := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 3}}
+	source := func(x la.Vector, t float64) float64 { return math.Cos(x[0]) * x[1] }
+
+	// function-based source
+	sf := NewFdmLaplacian(p, g, source)
+	sf.SetHbc()
+	sf.Assemble(false)
+	uf, _ := sf.SolveSteady(false)
+
+	// sampled source
+	f := make([]float64, g.Size())
+	for I := 0; I < g.Size(); I++ {
+		f[I] = source(g.Node(I), 0)
+	}
+	sv := NewFdmLaplacian(p, g, nil)
+	sv.SetHbc()
+	sv.SetSourceVector(f)
+	f[0] = 123 // the values are copied
+	sv.Assemble(false)
+	uv, _ := sv.SolveSteady(false)
+	chk.Array(tst, "u", 1e-15, uv, uf)
+
+	// both sources are added (homogeneous boundary conditions ⇒ twice the solution)
+	f[0] = source(g.Node(0), 0)
+	sf.SetSourceVector(f)
+	u2, _ := sf.SolveSteady(false)
+	for I := 0; I < g.Size(); I++ {
+		uf[I] *= 2
+	}
+	chk.Array(tst, "u (function + sampled)", 1e-15, u2, uf)
+
+	// wrong size
+	defer chk.RecoverTstPanicIsOK(tst)
+	sv.SetSourceVector(f[1:])
+}
+
+func TestFdm16(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm16. red-black ordering")
+
+	// grid, parameters and source
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{8, 7})
+	p := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 2}}
+	source := func(x la.Vector, t float64) float64 { return x[0]*x[0] - x[1] }
+
+	// lexicographic
+	sl := NewFdmLaplacian(p, g, source)
+	sl.AddEbc(10, 1, nil)
+	sl.AddEbc(21, 0, func(x la.Vector, t float64) float64 { return x[0] })
+	sl.Assemble(false)
+	ul, _ := sl.SolveSteady(false)
+
+	// red-black
+	sr := NewFdmLaplacian(p, g, source)
+	sr.Ordering = "redblack"
+	sr.AddEbc(10, 1, nil)
+	sr.AddEbc(21, 0, func(x la.Vector, t float64) float64 { return x[0] })
+	sr.Assemble(false)
+	ur, _ := sr.SolveSteady(false)
+
+	// red unknowns come first
+	nred := 0
+	for i, I := range sr.Eqs.UtoF {
+		m, n, _ := g.IndexItoMNP(I)
+		if (m+n)%2 == 0 {
+			chk.Int(tst, io.Sf("red unknown %d", i), i, nred)
+			nred++
+		}
+	}
+	io.Pforan("Nu = %d, nred = %d\n", sr.Eqs.Nu, nred)
+
+	// red-red and black-black blocks are diagonal
+	D := sr.Eqs.Auu.ToDense()
+	for i := 0; i < sr.Eqs.Nu; i++ {
+		for j := 0; j < sr.Eqs.Nu; j++ {
+			if i != j && (i < nred) == (j < nred) && D.Get(i, j) != 0 {
+				tst.Errorf("Auu[%d][%d] = %g should be zero\n", i, j, D.Get(i, j))
+			}
+		}
+	}
+
+	// solution in grid ordering (unpermuted by JoinVector) matches the lexicographic one
+	chk.Array(tst, "u", 1e-13, ur, ul)
+	for i, I := range sr.Eqs.UtoF {
+		chk.Float64(tst, io.Sf("Xu[%d]", i), 1e-13, sr.Eqs.Xu[i], sl.Eqs.Xu[sl.Eqs.FtoU[I]])
+	}
+
+	// invalid ordering
+	defer chk.RecoverTstPanicIsOK(tst)
+	s := NewFdmLaplacian(p, g, nil)
+	s.Ordering = "random"
+	s.Assemble(false)
+}
+
+func TestFdm17(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm17. Mehrstellen stencil: fourth-order convergence")
+
+	// solve problem
+	//       ∂²u        ∂²u
+	//    kx ———  +  ky ——— - kr⋅u = s    with   u = uana on all boundaries   (0 ≤ x ≤ 1; 0 ≤ y ≤ 2)
+	//       ∂x²        ∂y²
+	//
+	//    uana = sin(2x)⋅exp(y)  ⇒  s = (ky - 4⋅kx - kr)⋅uana
+	kx, ky, kr := 1.0, 3.0, 0.0
+	uana := func(x la.Vector, t float64) float64 { return math.Sin(2*x[0]) * math.Exp(x[1]) }
+	source := func(x la.Vector, t float64) float64 { return (ky - 4*kx - kr) * uana(x, t) }
+
+	// maximum error
+	calcError := func(N int, mehrstellen bool) (emax float64) {
+		g := new(gm.Grid)
+		g.RectGenUniform([]float64{0, 0}, []float64{1, 2}, []int{N + 1, 2*N + 1})
+		s := NewFdmLaplacian(dbf.Params{{N: "kx", V: kx}, {N: "ky", V: ky}, {N: "kr", V: kr}}, g, source)
+		s.Mehrstellen = mehrstellen
+		for _, tag := range []int{10, 11, 20, 21} {
+			s.AddEbc(tag, 0, uana)
+		}
+		s.Assemble(false)
+		u, _ := s.SolveSteady(false)
+		for I := 0; I < g.Size(); I++ {
+			emax = math.Max(emax, math.Abs(u[I]-uana(g.Node(I), 0)))
+		}
+		return
+	}
+
+	// convergence rates
+	for _, mehrstellen := range []bool{false, true} {
+		e1, e2 := calcError(8, mehrstellen), calcError(16, mehrstellen)
+		rate := math.Log2(e1 / e2)
+		io.Pforan("Mehrstellen = %5v: emax(8) = %.3e, emax(16) = %.3e, rate = %.2f\n", mehrstellen, e1, e2, rate)
+		if mehrstellen {
+			chk.Float64(tst, "rate (Mehrstellen)", 0.1, rate, 4)
+		} else {
+			chk.Float64(tst, "rate (5-point)", 0.1, rate, 2)
+		}
+	}
+
+	// with reaction term
+	kr = 5.0
+	e1, e2 := calcError(8, true), calcError(16, true)
+	rate := math.Log2(e1 / e2)
+	io.Pforan("with reaction:      emax(8) = %.3e, emax(16) = %.3e, rate = %.2f\n", e1, e2, rate)
+	chk.Float64(tst, "rate (Mehrstellen with reaction)", 0.1, rate, 4)
+}
+
+func TestFdm18(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm18. matrix-free application of operator")
+
+	// grid and random vector
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{9, 6})
+	rnd.Init(1234)
+
+	// check options
+	for _, opt := range []string{"5-point", "kxy", "reaction", "Mehrstellen", "redblack"} {
+		p := dbf.Params{{N: "kx", V: 1.5}, {N: "ky", V: 0.5}}
+		if opt == "kxy" {
+			p = append(p, &dbf.P{N: "kxy", V: 0.3})
+		}
+		s := NewFdmLaplacian(p, g, nil)
+		if opt == "reaction" {
+			s.Reaction = func(x la.Vector, t float64) float64 { return 1 + x[0]*x[1] }
+		}
+		s.Mehrstellen = opt == "Mehrstellen"
+		if opt == "redblack" {
+			s.Ordering = "redblack"
+		}
+		s.AddEbc(10, 1, nil)
+		s.AddEbc(20, 0, nil)
+
+		// matrix-free (before assembling)
+		uu := la.NewVector(g.Size() - len(s.EssenBcs.Nodes()))
+		rnd.Float64s(uu, -1, 1)
+		res := la.NewVector(len(uu))
+		s.Apply(res, uu)
+
+		// assembled
+		s.Assemble(false)
+		ref := la.NewVector(s.Eqs.Nu)
+		la.SpMatVecMul(ref, 1, s.Eqs.Auu.ToMatrix(nil), uu)
+		io.Pforan("%-12s: ‖res - ref‖ = %g\n", opt, res.NormDiff(ref))
+		chk.Array(tst, "Apply ("+opt+")", 1e-13, res, ref)
+	}
+}
+
+func TestFdm19(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm19. disk domain via signed distance function")
+
+	// disk of radius 1 and harmonic solution u = exp(x)⋅sin(y)
+	sdf := func(x []float64) float64 { return math.Sqrt(x[0]*x[0]+x[1]*x[1]) - 1.0 }
+	uExact := func(x la.Vector, t float64) float64 { return math.Exp(x[0]) * math.Sin(x[1]) }
+
+	// errors at active nodes for increasing resolution. The error of the ghost values is smaller
+	// than h⋅max|∇u| and max|∇u| = e on the disk; thus, by the maximum principle, err ≤ e⋅h
+	var errs, hs []float64
+	for _, ndiv := range []int{40, 80, 160} {
+		g := new(gm.Grid)
+		g.RectGenUniform([]float64{-1.2, -1.2}, []float64{1.2, 1.2}, []int{ndiv + 1, ndiv + 1})
+		s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+		s.SetDomainSDF(sdf, 0, uExact)
+		s.Assemble(false)
+		u, _ := s.SolveSteady(false)
+		maxErr := 0.0
+		for _, I := range s.Eqs.UtoF {
+			x := g.Node(I)
+			if sdf(x) >= 0 {
+				tst.Errorf("node %d is outside the domain and must not be unknown\n", I)
+			}
+			maxErr = math.Max(maxErr, math.Abs(u[I]-uExact(x, 0)))
+		}
+		h := 2.4 / float64(ndiv)
+		io.Pforan("h = %.4f  max error = %.3e  error/h = %.3f\n", h, maxErr, maxErr/h)
+		if maxErr > math.E*h {
+			tst.Errorf("error must be smaller than e⋅h (first-order). %g > %g\n", maxErr, math.E*h)
+		}
+		errs = append(errs, maxErr)
+		hs = append(hs, h)
+	}
+	for k := 1; k < len(errs); k++ {
+		order := math.Log(errs[k-1]/errs[k]) / math.Log(hs[k-1]/hs[k])
+		io.Pforan("order = %.3f\n", order)
+		chk.Float64(tst, "order", 0.2, order, 1)
+	}
+
+	// all nodes outside
+	defer chk.RecoverTstPanicIsOK(tst)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{2, 2}, []float64{3, 3}, []int{3, 3})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	s.SetDomainSDF(sdf, 0, nil)
+}
+
+func TestFdm20(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm20. dump equations")
+
+	// same setup as Fdm03: 5x5 grid with homogeneous boundary conditions
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{5, 5})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	s.SetHbc()
+	s.Assemble(false)
+
+	// check output
+	l := s.DumpEquations()
+	io.Pf("%s", l)
+	lines := strings.Split(strings.TrimSpace(l), "\n")
+	chk.Strings(tst, "dump", lines, []string{
+		"N  = 25",
+		"Nu = 9",
+		"Nk = 16",
+		"UtoF = [6 7 8 11 12 13 16 17 18]",
+		"FtoU = [-1 -1 -1 -1 -1 -1 0 1 2 -1 -1 3 4 5 -1 -1 6 7 8 -1 -1 -1 -1 -1 -1]",
+		"KtoF = [0 1 2 3 4 5 9 10 14 15 19 20 21 22 23 24]",
+		"FtoK = [0 1 2 3 4 5 -1 -1 -1 6 7 -1 -1 -1 8 9 -1 -1 -1 10 11 12 13 14 15]",
+		"Auu : 9 × 9 (33 entries)",
+		"Auk : 9 × 16 (12 entries)",
+		"Aku : 16 × 9 (not allocated)",
+		"Akk : 16 × 16 (not allocated)",
+	})
+
+	// maps must match the equations
+	chk.Ints(tst, "UtoF", s.Eqs.UtoF, []int{6, 7, 8, 11, 12, 13, 16, 17, 18})
+	chk.Ints(tst, "KtoF", s.Eqs.KtoF, []int{0, 1, 2, 3, 4, 5, 9, 10, 14, 15, 19, 20, 21, 22, 23, 24})
+
+	// not assembled yet
+	defer chk.RecoverTstPanicIsOK(tst)
+	NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil).DumpEquations()
+}
+
+func TestFdm21(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm21. assemble with coefficient field")
+
+	// grid and spatially-varying field
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{5, 3})
+	kField := make([]float64, g.Size())
+	for I := 0; I < g.Size(); I++ {
+		x := g.Node(I)
+		kField[I] = 1 + x[0]*x[0] + 3*x[1]
+	}
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 2}, {N: "ky", V: 1}}, g, nil)
+	s.AddEbc(10, 0, nil)
+	s.AssembleWithCoeffField(kField, true)
+
+	// face coefficients
+	dx, dy := 0.5, 0.5
+	harm := func(I, J int) float64 { return 2 * kField[I] * kField[J] / (kField[I] + kField[J]) }
+	I := 7 // interior node (m=2, n=1)
+	nodes, coefs := s.StencilAt(I)
+	chk.Ints(tst, "nodes", nodes, []int{2, 6, 7, 8, 12})
+	kw, ke := 2*harm(I, 6)/(dx*dx), 2*harm(I, 8)/(dx*dx)
+	ks, kn := harm(I, 2)/(dy*dy), harm(I, 12)/(dy*dy)
+	io.Pforan("k @ faces: w=%g e=%g s=%g n=%g\n", kw, ke, ks, kn)
+	chk.Array(tst, "coefs", 1e-14, coefs, []float64{ks, kw, -(kw + ke + ks + kn), ke, kn})
+
+	// boundary node: mirrored neighbour has the same face coefficient
+	nodes, coefs = s.StencilAt(14) // top-right corner
+	chk.Ints(tst, "nodes @ 14", nodes, []int{9, 13, 14})
+	kw, ks = 2*harm(14, 13)/(dx*dx), harm(14, 9)/(dy*dy)
+	chk.Array(tst, "coefs @ 14", 1e-14, coefs, []float64{2 * ks, 2 * kw, -2 * (kw + ks)})
+
+	// unit field recovers the constant-coefficient operator
+	ones := make([]float64, g.Size())
+	for I := range ones {
+		ones[I] = 1
+	}
+	s.AssembleWithCoeffField(ones, false)
+	a := s.Eqs.Auu.ToDense()
+	s.AssembleWithCoeffField(nil, false)
+	chk.Deep2(tst, "Auu", 1e-14, a.GetDeep2(), s.Eqs.Auu.ToDense().GetDeep2())
+
+	// wrong size
+	defer chk.RecoverTstPanicIsOK(tst)
+	s.AssembleWithCoeffField([]float64{1, 2}, false)
+}
+
+func TestFdm22(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm22. energy functional")
+
+	// Poisson problem with non-homogeneous boundary conditions
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{7, 6})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 2}, {N: "kr", V: 0.5}}, g, func(x la.Vector, t float64) float64 {
+		return -1 - x[0]*x[1]
+	})
+	s.AddEbc(10, 1, nil)
+	s.AddEbc(21, 0, func(x la.Vector, t float64) float64 { return x[0] * x[0] })
+	s.Assemble(false)
+	u, _ := s.SolveSteady(false)
+	e0 := s.Energy(u)
+	io.Pforan("E(u) = %v\n", e0)
+
+	// perturbed solutions: E(u + ε⋅v) - E(u) = ½ ε² vᵀ⋅[W]⋅[K]⋅v > 0
+	rnd.Init(4321)
+	auu := s.Eqs.Auu.ToMatrix(nil)
+	w := GridQuadrature(g)
+	for _, ε := range []float64{1e-3, 1e-1, 1} {
+		vu := la.NewVector(s.Eqs.Nu)
+		rnd.Float64s(vu, -1, 1)
+		up := make([]float64, len(u))
+		copy(up, u)
+		for i, I := range s.Eqs.UtoF {
+			up[I] += ε * vu[i]
+		}
+		e1 := s.Energy(up)
+		kv := la.NewVector(s.Eqs.Nu)
+		la.SpMatVecMul(kv, -1, auu, vu)
+		for i, I := range s.Eqs.UtoF {
+			kv[i] *= w[I]
+		}
+		io.Pforan("ε = %5g: E(u+εv) = %v\n", ε, e1)
+		if e1 <= e0 {
+			tst.Errorf("energy of perturbed solution must be greater than energy of solution: %g ≤ %g\n", e1, e0)
+		}
+		chk.Float64(tst, "E(u+εv)-E(u)", 1e-10, e1-e0, 0.5*ε*ε*la.VecDot(vu, kv))
+	}
+
+	// Mehrstellen is not supported
+	defer chk.RecoverTstPanicIsOK(tst)
+	s.Mehrstellen = true
+	s.Energy(u)
+}
+
+func TestFdm23(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm23. Robin conditions with position-dependent coefficients")
+
+	// u = exp(x)⋅sin(y) on [0,1]×[0,1] with Dirichlet conditions on the left, bottom and top,
+	// and a convective condition ∂u/∂n + h(y)⋅u = c(y) on the right (x = 1), with h(y) = 1 + 2y
+	uExact := func(x la.Vector, t float64) float64 { return math.Exp(x[0]) * math.Sin(x[1]) }
+	hcoef := func(x la.Vector, t float64) float64 { return 1 + 2*x[1] }
+	one := func(x la.Vector, t float64) float64 { return 1 }
+	ccoef := func(x la.Vector, t float64) float64 { // c = h⋅u + ∂u/∂x @ x = 1
+		return hcoef(x, t)*uExact(x, t) + math.Exp(x[0])*math.Sin(x[1])
+	}
+
+	// errors for two resolutions
+	var errs []float64
+	for _, n := range []int{11, 21} {
+		g := new(gm.Grid)
+		g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{n, n})
+		s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+		s.AddEbc(10, 0, uExact)
+		s.AddEbc(20, 0, uExact)
+		s.AddEbc(21, 0, uExact)
+		s.RobinBcs.SetInGridFunc(11, hcoef, one, ccoef)
+		s.Assemble(false)
+		u, _ := s.SolveSteady(false)
+		maxErr := 0.0
+		for I := 0; I < g.Size(); I++ {
+			maxErr = math.Max(maxErr, math.Abs(u[I]-uExact(g.Node(I), 0)))
+		}
+		io.Pforan("n = %d: max error = %g\n", n, maxErr)
+		errs = append(errs, maxErr)
+
+		// diagonal contribution varies along the edge
+		if n == 11 {
+			h := 0.1
+			for _, I := range []int{21, 65, 98} { // nodes on right edge: y = 0.1, 0.5 and 0.8
+				nodes, coefs := s.StencilAt(I)
+				y := g.Node(I)[1]
+				chk.Ints(tst, "nodes", nodes, []int{I - 11, I - 1, I, I + 11})
+				chk.Float64(tst, io.Sf("diag @ %d", I), 1e-12, coefs[2], -4/(h*h)-2*(1+2*y)/h)
+			}
+		}
+	}
+	chk.Float64(tst, "error ratio", 0.3, errs[0]/errs[1], 4) // second-order
+
+	// constant coefficients are the same as natural conditions if a = 0
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{6, 6})
+	s1 := NewFdmLaplacian(dbf.Params{{N: "kx", V: 2}, {N: "ky", V: 1}}, g, nil)
+	s1.AddEbc(10, 1, nil)
+	s1.RobinBcs.SetInGrid(11, 0, 1, -0.5) // ∂u/∂n = -0.5 ⇒ qn = k ∂u/∂n = -1
+	s1.Assemble(false)
+	u1, _ := s1.SolveSteady(false)
+	s2 := NewFdmLaplacian(dbf.Params{{N: "kx", V: 2}, {N: "ky", V: 1}}, g, nil)
+	s2.AddEbc(10, 1, nil)
+	s2.AddNbc(11, -1, nil)
+	s2.Assemble(false)
+	u2, _ := s2.SolveSteady(false)
+	chk.Array(tst, "Robin(a=0) vs natural", 1e-13, u1, u2)
+
+	// b = 0 is invalid
+	defer chk.RecoverTstPanicIsOK(tst)
+	s1.RobinBcs.SetInGrid(11, 1, 0, 0)
+	s1.Assemble(false)
+}
+
+func TestFdm24(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm24. essential and natural conditions on parts of the same edge")
+
+	// u = 1 + x + 2y on [0,2]×[0,1]; the bottom edge is clamped for x ≤ 1 and has the natural
+	// condition qn = k ∂u/∂n = -2 (with n = -ey) for x ≥ 1; the transition node is clamped
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{9, 5})
+	uExact := func(x la.Vector, t float64) float64 { return 1 + x[0] + 2*x[1] }
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	s.AddEbc(10, 0, uExact)
+	s.AddEbc(11, 0, uExact)
+	s.AddEbc(21, 0, uExact)
+	s.AddEbcWhere(20, 0, uExact, func(x la.Vector) bool { return x[0] <= 1 })
+	s.AddNbcWhere(20, -2, nil, func(x la.Vector) bool { return x[0] >= 1 })
+	s.Assemble(false)
+	u, _ := s.SolveSteady(false)
+
+	// partitioning of the bottom edge (nodes 0..8; the transition node is 4; 8 is on the right edge)
+	for I := 0; I <= 8; I++ {
+		essential := I <= 4 || I == 8
+		if s.EssenBcs.Has(I) != essential {
+			tst.Errorf("node %d: essential condition should be %v\n", I, essential)
+		}
+		if (s.Eqs.FtoU[I] < 0) != essential {
+			tst.Errorf("node %d: prescribed value should be %v\n", I, essential)
+		}
+	}
+	chk.Ints(tst, "natural tags @ 6", s.NaturBcs.Tags(6), []int{20})
+
+	// solution
+	for I := 0; I < g.Size(); I++ {
+		chk.Float64(tst, io.Sf("u @ %d", I), 1e-13, u[I], uExact(g.Node(I), 0))
+	}
+
+	// empty subset
+	defer chk.RecoverTstPanicIsOK(tst)
+	s.AddEbcWhere(20, 0, nil, func(x la.Vector) bool { return x[0] > 10 })
+}
+
+func TestFdm25(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm25. directional operators d2dx2 and d2dy2")
+
+	// operator with essential, natural and Robin conditions
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{5, 4})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 2}, {N: "ky", V: 3}, {N: "kr", V: 0.5}}, g, nil)
+	s.AddEbc(10, 1, nil)
+	s.AddNbc(11, 2, nil)
+	s.RobinBcs.SetInGrid(21, 1, 2, 0)
+
+	// full operator without reaction
+	s.Kr = 0
+	s.Assemble(true)
+	full := [4]*la.Matrix{s.Eqs.Auu.ToDense(), s.Eqs.Auk.ToDense(), s.Eqs.Aku.ToDense(), s.Eqs.Akk.ToDense()}
+	s.Kr = 0.5
+
+	// directional operators
+	s.SwitchOperator("d2dx2", true)
+	ax := [4]*la.Matrix{s.Eqs.Auu.ToDense(), s.Eqs.Auk.ToDense(), s.Eqs.Aku.ToDense(), s.Eqs.Akk.ToDense()}
+	s.SwitchOperator("d2dy2", true)
+	ay := [4]*la.Matrix{s.Eqs.Auu.ToDense(), s.Eqs.Auk.ToDense(), s.Eqs.Aku.ToDense(), s.Eqs.Akk.ToDense()}
+	for k, name := range []string{"Auu", "Auk", "Aku", "Akk"} {
+		sum := la.NewMatrix(full[k].M, full[k].N)
+		la.MatAdd(sum, 1, ax[k], 1, ay[k])
+		chk.Deep2(tst, name+": d2dx2 + d2dy2 == full", 1e-13, sum.GetDeep2(), full[k].GetDeep2())
+	}
+
+	// coefficients are not modified
+	chk.Float64(tst, "kx", 1e-17, s.Kx, 2)
+	chk.Float64(tst, "ky", 1e-17, s.Ky, 3)
+	chk.Float64(tst, "kr", 1e-17, s.Kr, 0.5)
+
+	// the stencil of d2dx2 at interior node 6 couples only the x-neighbours (zero entries are kept)
+	s.SwitchOperator("d2dx2", false)
+	nodes, coefs := s.StencilAt(6)
+	chk.Ints(tst, "nodes", nodes, []int{1, 5, 6, 7, 11})
+	chk.Array(tst, "coefs", 1e-14, coefs, []float64{0, 2 / 0.25, -4 / 0.25, 2 / 0.25, 0})
+
+	// not available with the Mehrstellen stencil
+	s.Mehrstellen = true
+	defer chk.RecoverTstPanicIsOK(tst)
+	s.SwitchOperator("d2dy2", false)
+}
+
+func TestFdm26(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm26. interface with jumps of solution and flux")
+
+	// grid with the interface at x = 0.45 (between columns 4 and 5)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 0.4}, []int{11, 5})
+	kx := 2.0
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: kx}, {N: "ky", V: 1}}, g, nil)
+	s.AddEbc(10, 0, nil)
+	s.AddEbc(11, 1, nil)
+	var nodes []int
+	for n := 0; n < g.Npts(1); n++ {
+		nodes = append(nodes, g.IndexMNPtoI(4, n, 0))
+	}
+	uJump, qJump := 0.3, 1.2
+	s.SetInterfaceCondition(nodes, 0, uJump, qJump)
+	s.Assemble(false)
+	u, _ := s.SolveSteady(false)
+
+	// exact: u⁻ = g⁻⋅x and u⁺ = 1 + g⁺⋅(x - 1) with kx⋅(g⁺ - g⁻) = qJump and u⁺ - u⁻ = uJump at x = 0.45
+	gMinus := 1 - uJump - 0.55*qJump/kx
+	gPlus := gMinus + qJump/kx
+	uExact := func(x float64) float64 {
+		if x < 0.45 {
+			return gMinus * x
+		}
+		return 1 + gPlus*(x-1)
+	}
+	for I := 0; I < g.Size(); I++ {
+		chk.Float64(tst, io.Sf("u @ %d", I), 1e-13, u[I], uExact(g.Node(I)[0]))
+	}
+
+	// derivative discontinuity
+	h := 0.1
+	n := 2
+	dudxL := (u[g.IndexMNPtoI(4, n, 0)] - u[g.IndexMNPtoI(3, n, 0)]) / h
+	dudxR := (u[g.IndexMNPtoI(6, n, 0)] - u[g.IndexMNPtoI(5, n, 0)]) / h
+	io.Pforan("∂u/∂x: %v (left), %v (right)\n", dudxL, dudxR)
+	chk.Float64(tst, "[k ∂u/∂x]", 1e-12, kx*(dudxR-dudxL), qJump)
+
+	// invalid node (no neighbour on the right)
+	defer chk.RecoverTstPanicIsOK(tst)
+	s.SetInterfaceCondition([]int{10}, 0, uJump, qJump)
+}
+
+func TestFdm27(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm27. adjoint sensitivity with respect to prescribed values")
+
+	// Laplace problem with essential conditions on the left, right and top edges and zero flux
+	// at the bottom edge; the output is the flux through the left edge
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{9, 6})
+	p := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 0.5}}
+	s := NewFdmLaplacian(p, g, func(x la.Vector, t float64) float64 { return x[0] })
+	defer s.Free()
+	ubc := func(x la.Vector, t float64) float64 { return math.Sin(x[0]) + x[1]*x[1] }
+	for _, tag := range []int{10, 11, 21} {
+		s.AddEbc(tag, 0, ubc)
+	}
+	s.AddNbc(20, 0, nil)
+	s.Assemble(false)
+
+	// outputs: flux through the left edge (linear) and sum of squares (nonlinear)
+	fluxOutput := func(u []float64) (J float64, dJdu []float64) {
+		J = s.EdgeFlux(10, u)
+		dJdu = make([]float64, len(u))
+		e := make([]float64, len(u))
+		for I := range e {
+			e[I] = 1
+			dJdu[I] = s.EdgeFlux(10, e)
+			e[I] = 0
+		}
+		return
+	}
+	squaresOutput := func(u []float64) (J float64, dJdu []float64) {
+		dJdu = make([]float64, len(u))
+		for I, v := range u {
+			J += v * v / 2
+			dJdu[I] = v
+		}
+		return
+	}
+
+	// check with finite differences
+	δ := 1e-4
+	for k, output := range []func(u []float64) (float64, []float64){fluxOutput, squaresOutput} {
+		J, grad := s.AdjointSensitivity(output)
+		io.Pforan("J = %v\n", J)
+		chk.Int(tst, "len(grad)", len(grad), s.Eqs.Nk)
+		for i, I := range s.Eqs.KtoF {
+			_, val, _ := s.EssenBcs.Value(I, 0, 0)
+			s.EssenBcs.AddUsingNodes([]int{I}, 0, val+δ, nil)
+			up, _ := s.SolveSteady(false)
+			Jp, _ := output(up)
+			s.EssenBcs.AddUsingNodes([]int{I}, 0, val-δ, nil)
+			um, _ := s.SolveSteady(false)
+			Jm, _ := output(um)
+			s.EssenBcs.AddUsingNodes([]int{I}, 0, val, nil)
+			chk.AnaNum(tst, io.Sf("output %d: dJ/du @ %d", k, I), 1e-8, grad[i], (Jp-Jm)/(2*δ), chk.Verbose)
+		}
+	}
+}
+
+func TestFdm28(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm28. assemble with tensor field")
+
+	// grid and spatially-varying (positive-definite) tensor field
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{9, 6})
+	kxx := make([]float64, g.Size())
+	kxy := make([]float64, g.Size())
+	kyy := make([]float64, g.Size())
+	for I := 0; I < g.Size(); I++ {
+		x := g.Node(I)
+		kxx[I] = 1 + x[0]*x[0]
+		kxy[I] = 0.3 * math.Sin(x[0]+2*x[1])
+		kyy[I] = 2 + x[1]
+	}
+	p := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}
+
+	// symmetry with essential conditions on all edges
+	s := NewFdmLaplacian(p, g, nil)
+	for _, tag := range []int{10, 11, 20, 21} {
+		s.AddEbc(tag, 0, nil)
+	}
+	s.AssembleWithTensorField(kxx, kxy, kyy, false)
+	a := s.Eqs.Auu.ToDense()
+	chk.Deep2(tst, "Auu == Auuᵀ", 1e-15, a.GetDeep2(), a.GetTranspose().GetDeep2())
+
+	// coefficients of the diagonal neighbours of an interior node
+	I := g.IndexMNPtoI(3, 2, 0)
+	nodes, coefs := s.StencilAt(I)
+	chk.Int(tst, "len(nodes)", len(nodes), 9)
+	δ := 1.0 / (4 * 0.25 * 0.2)
+	node := func(m, n int) int { return g.IndexMNPtoI(m, n, 0) }
+	correct := map[int]float64{
+		node(4, 3): +δ * (kxy[node(4, 2)] + kxy[node(3, 3)]),
+		node(2, 1): +δ * (kxy[node(2, 2)] + kxy[node(3, 1)]),
+		node(2, 3): -δ * (kxy[node(2, 2)] + kxy[node(3, 3)]),
+		node(4, 1): -δ * (kxy[node(4, 2)] + kxy[node(3, 1)]),
+	}
+	for k, J := range nodes {
+		if c, ok := correct[J]; ok {
+			chk.Float64(tst, io.Sf("A[%d][%d]", I, J), 1e-14, coefs[k], c)
+		}
+	}
+
+	// conservation: constant values are in the kernel (row sums of [Auu Auk] vanish) and the flux
+	// leaving a node enters its neighbours (column sums of Auu vanish away from the boundary)
+	ak := s.Eqs.Auk.ToDense()
+	for i, I := range s.Eqs.UtoF {
+		row, col := 0.0, 0.0
+		for j := 0; j < a.N; j++ {
+			row += a.Get(i, j)
+			col += a.Get(j, i)
+		}
+		for j := 0; j < ak.N; j++ {
+			row += ak.Get(i, j)
+		}
+		chk.Float64(tst, io.Sf("row sum @ %d", I), 1e-12, row, 0)
+		if m, n, _ := g.IndexItoMNP(I); m > 1 && m < 7 && n > 1 && n < 4 {
+			chk.Float64(tst, io.Sf("column sum @ %d", I), 1e-12, col, 0)
+		}
+	}
+
+	// constant tensor recovers the constant-coefficient operator (also at boundaries)
+	s = NewFdmLaplacian(p, g, nil)
+	for I := 0; I < g.Size(); I++ {
+		kxx[I], kxy[I], kyy[I] = 2, 0.5, 3
+	}
+	s.AssembleWithTensorField(kxx, kxy, kyy, false)
+	a = s.Eqs.Auu.ToDense()
+	sc := NewFdmLaplacian(dbf.Params{{N: "kx", V: 2}, {N: "ky", V: 3}, {N: "kxy", V: 0.5}}, g, nil)
+	sc.Assemble(false)
+	chk.Deep2(tst, "Auu", 1e-13, a.GetDeep2(), sc.Eqs.Auu.ToDense().GetDeep2())
+
+	// wrong size
+	defer chk.RecoverTstPanicIsOK(tst)
+	s.AssembleWithTensorField(kxx, kxy, []float64{1, 2}, false)
+}
+
+func TestFdm29(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm29. streaming assembly")
+
+	// operator with cross term and Robin condition
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{7, 5})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 2}, {N: "kxy", V: 0.3}, {N: "kr", V: 0.5}}, g, nil)
+	s.AddEbc(10, 1, nil)
+	s.AddNbc(20, 2, nil)
+	s.RobinBcs.SetInGrid(11, 2, 1, 3)
+
+	// streamed entries
+	streamed := make(map[[2]int]float64)
+	s.AssembleStream(func(I, J int, value float64) {
+		streamed[[2]int{I, J}] += value
+	})
+	if s.Eqs != nil {
+		tst.Errorf("Eqs should not be created by AssembleStream\n")
+		return
+	}
+
+	// compare with assembled matrix
+	s.Assemble(true)
+	a := s.Eqs.GetAmat().ToDense()
+	b := la.NewMatrix(g.Size(), g.Size())
+	for ij, v := range streamed {
+		b.Add(ij[0], ij[1], v)
+	}
+	io.Pforan("number of streamed entries (unique) = %d\n", len(streamed))
+	chk.Deep2(tst, "A", 1e-15, b.GetDeep2(), a.GetDeep2())
+}
+
+func TestFdm30(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm30. residual field")
+
+	// Poisson problem with essential and natural conditions
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{9, 6})
+	source := func(x la.Vector, t float64) float64 { return x[0]*x[1] - 1 }
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 2}}, g, source)
+	s.AddEbc(10, 1, nil)
+	s.AddEbc(21, 0, func(x la.Vector, t float64) float64 { return math.Cos(x[0]) })
+	s.AddNbc(11, 0.5, nil)
+	s.Assemble(false)
+	u, _ := s.SolveSteady(false)
+
+	// converged solution
+	r := s.ResidualField(u)
+	chk.Int(tst, "len(r)", len(r), g.Size())
+	chk.Array(tst, "r", 1e-12, r, nil)
+
+	// perturbed solution: the residual is localised at the perturbed node and its neighbours
+	I := g.IndexMNPtoI(4, 2, 0)
+	u[I] += 0.1
+	r = s.ResidualField(u)
+	nodes, coefs := s.StencilAt(I)
+	correct := make([]float64, g.Size())
+	for k, J := range nodes {
+		correct[J] = -0.1 * coefs[k] // A is symmetric in the interior
+	}
+	io.Pforan("r @ perturbed node = %v\n", r[I])
+	chk.Array(tst, "r (perturbed)", 1e-12, r, correct)
+	for _, K := range s.Eqs.KtoF {
+		if r[K] != 0 {
+			tst.Errorf("residual at node %d with prescribed value should be zero\n", K)
+			return
+		}
+	}
+}
+
+func TestFdm31(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm31. single precision assembly and solution")
+
+	// problem with essential, natural and Robin conditions
+	run := func(single bool) (u []float64, s *FdmLaplacian) {
+		g := new(gm.Grid)
+		g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{21, 11})
+		source := func(x la.Vector, t float64) float64 { return x[0]*x[1] - 1 }
+		s = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 2}, {N: "kxy", V: 0.3}}, g, source)
+		s.Float32 = single
+		s.AddEbc(10, 1, nil)
+		s.AddEbc(21, 0, func(x la.Vector, t float64) float64 { return math.Cos(x[0]) })
+		s.AddNbc(11, 0.5, nil)
+		s.RobinBcs.SetInGrid(20, 2, 1, 3)
+		s.Assemble(false)
+		u, _ = s.SolveSteady(false)
+		return
+	}
+	u64, s64 := run(false)
+	u32, s32 := run(true)
+
+	// solutions
+	if s32.Eqs.Auu != nil {
+		tst.Errorf("double precision matrices should not be allocated\n")
+		return
+	}
+	umax := la.Vector(u64).Largest(1)
+	io.Pforan("max(|u|) = %v\n", umax)
+	for I := range u64 {
+		chk.Float64(tst, io.Sf("u%d", I), 1e-5*umax, u32[I], u64[I])
+	}
+
+	// accuracy: relative residual of the single precision solution computed in double precision
+	// (the reduced RHS is the residual of the prescribed values with zero unknown values)
+	u0 := make([]float64, len(u64))
+	for _, I := range s64.Eqs.KtoF {
+		u0[I] = u64[I]
+	}
+	rel := la.Vector(s64.ResidualField(u32)).Norm() / la.Vector(s64.ResidualField(u0)).Norm()
+	io.Pforan("relative residual (float32 solution) = %v\n", rel)
+	if rel > 1e-5 {
+		tst.Errorf("relative residual of single precision solution is too large: %g\n", rel)
+	}
+
+	// the partitioning of equations is available
+	lines32 := strings.Split(s32.DumpEquations(), "\n")
+	lines64 := strings.Split(s64.DumpEquations(), "\n")
+	chk.Strings(tst, "maps", lines32[:7], lines64[:7])
+	chk.String(tst, lines32[7], io.Sf("Auu : %d × %d (not allocated)", s64.Eqs.Nu, s64.Eqs.Nu))
+
+	// functions requiring the double precision matrices are not available
+	check := func(fname string, fcn func()) {
+		defer func() {
+			if err := recover(); err == nil {
+				tst.Errorf("%s should panic in single precision\n", fname)
+			}
+		}()
+		fcn()
+	}
+	check("ReapplyBcs", func() { s32.ReapplyBcs() })
+	check("DenseSystem", func() { s32.DenseSystem() })
+	check("ResidualField", func() { s32.ResidualField(u32) })
+	check("Energy", func() { s32.Energy(u32) })
+	check("SolveConstrained", func() { s32.SolveConstrained(u32) })
+	check("NewFdmTransientSolver", func() { NewFdmTransientSolver(s32, 0.5, nil) })
+	check("NewBlockOperator", func() { NewBlockOperator([][]*FdmLaplacian{{s32}}) })
+
+	// reactions are not available
+	defer chk.RecoverTstPanicIsOK(tst)
+	s32.SolveSteady(true)
+}
+
+func TestFdm32(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm32. point source")
+
+	// grid with Δx = 0.25 and Δy = 0.5
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 2}, []int{9, 5})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	s.SetHbc()
+
+	// unit source inside cell with corners (2,1), (3,1), (2,2) and (3,2)
+	nodes, weights := s.AddPointSource([]float64{0.6, 0.8}, 1)
+	io.Pforan("nodes   = %v\n", nodes)
+	io.Pforan("weights = %v\n", weights)
+	chk.Ints(tst, "nodes", nodes, []int{
+		g.IndexMNPtoI(2, 1, 0), g.IndexMNPtoI(3, 1, 0), g.IndexMNPtoI(2, 2, 0), g.IndexMNPtoI(3, 2, 0),
+	})
+	chk.Array(tst, "weights", 1e-15, weights, []float64{0.6 * 0.4, 0.4 * 0.4, 0.6 * 0.6, 0.4 * 0.6})
+	chk.Float64(tst, "Σw", 1e-15, la.Vector(weights).Accum(), 1)
+
+	// total strength is conserved
+	total := 0.0
+	w := GridQuadrature(g)
+	for I := 0; I < g.Size(); I++ {
+		total += s.source(I, 0) * w[I]
+	}
+	chk.Float64(tst, "total", 1e-15, total, 1)
+
+	// point on a node
+	s.ClearPointSources()
+	nodes, weights = s.AddPointSource([]float64{2, 1}, 3)
+	chk.Ints(tst, "nodes (on node)", nodes, []int{g.IndexMNPtoI(8, 2, 0)})
+	chk.Array(tst, "weights (on node)", 1e-15, weights, []float64{1})
+	chk.Float64(tst, "s @ node", 1e-15, s.source(nodes[0], 0), 3/(0.125*0.5)) // half control volume at x = xmax
+
+	// solution is symmetric about the point source at the centre
+	s.ClearPointSources()
+	s.AddPointSource([]float64{1, 1}, 1)
+	s.Assemble(false)
+	u, _ := s.SolveSteady(false)
+	for m := 0; m < 9; m++ {
+		chk.Float64(tst, io.Sf("u(%d)", m), 1e-14, u[g.IndexMNPtoI(m, 2, 0)], u[g.IndexMNPtoI(8-m, 2, 0)])
+	}
+
+	// stretched grid: points inside a cell at the boundary and on an interior node
+	gs := new(gm.Grid)
+	gs.RectSet2d([]float64{0, 0.1, 0.3, 0.7, 1.5}, []float64{0, 0.2, 0.5, 1})
+	ss := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, gs, nil)
+	ss.AddPointSource([]float64{1.3, 0.1}, 2)
+	ss.AddPointSource([]float64{0.3, 0.5}, 0.5)
+	total = 0.0
+	w = GridQuadrature(gs)
+	for I := 0; I < gs.Size(); I++ {
+		total += ss.source(I, 0) * w[I]
+	}
+	chk.Float64(tst, "total (stretched)", 1e-14, total, 2.5)
+	I := gs.IndexMNPtoI(2, 2, 0)
+	chk.Float64(tst, "s @ node (stretched)", 1e-13, ss.source(I, 0), 0.5/((0.4/2+0.2/2)*(0.5/2+0.3/2)))
+
+	// point outside grid
+	defer chk.RecoverTstPanicIsOK(tst)
+	s.AddPointSource([]float64{2.1, 1}, 1)
+}
+
+func TestFdm33(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm33. reactions at nodes with prescribed values")
+
+	// Laplace problem: u = x + 2y on the left and bottom edges and u = 0 on the right edge
+	run := func(source func(x la.Vector, t float64) float64) (s *FdmLaplacian, u, r []float64) {
+		g := new(gm.Grid)
+		g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{9, 6})
+		s = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 3}}, g, source)
+		lin := func(x la.Vector, t float64) float64 { return x[0] + 2*x[1] }
+		s.AddEbc(10, 0, lin)
+		s.AddEbc(20, 0, lin)
+		s.AddEbc(11, 0, nil)
+		s.Assemble(true)
+		u, _ = s.SolveSteady(false)
+		r = s.Reactions()
+		return
+	}
+	s, u, r := run(nil)
+	io.Pforan("r = %v\n", r)
+	for _, I := range s.Eqs.UtoF {
+		if r[I] != 0 {
+			tst.Errorf("reaction at node %d without prescribed value should be zero\n", I)
+			return
+		}
+	}
+	chk.Float64(tst, "Σr", 1e-12, la.Vector(r).Accum(), 0)
+
+	// reactions equal the fluxes from the residuals of the full system
+	e := s.Eqs
+	A := e.GetAmat().ToMatrix(nil)
+	Au := la.NewVector(s.Grid.Size())
+	la.SpMatVecMul(Au, 1, A, u)
+	w := GridQuadrature(s.Grid)
+	for _, I := range e.KtoF {
+		chk.Float64(tst, io.Sf("r%d", I), 1e-12, r[I], w[I]*Au[I])
+	}
+
+	// with a constant source: Σr = -∫s dΩ = -2
+	_, _, r = run(func(x la.Vector, t float64) float64 { return 1 })
+	chk.Float64(tst, "Σr (source)", 1e-12, la.Vector(r).Accum(), -2)
+}
+
+func TestFdm34(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm34. rotated grid")
+
+	// problem defined in the local frame
+	//   ∂²u/∂xl² + ∂²u/∂yl² = xl⋅yl   with  u = sin(xl) + yl² on the boundary
+	src := func(xl la.Vector) float64 { return xl[0] * xl[1] }
+	ebc := func(xl la.Vector) float64 { return math.Sin(xl[0]) + xl[1]*xl[1] }
+	solve := func(g *gm.Grid, source, value func(x la.Vector, t float64) float64) (s *FdmLaplacian, u []float64) {
+		s = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, source)
+		for _, tag := range []int{10, 11, 20, 21} {
+			s.AddEbc(tag, 0, value)
+		}
+		s.Assemble(false)
+		u, _ = s.SolveSteady(false)
+		return
+	}
+
+	// unrotated
+	g0 := new(gm.Grid)
+	g0.RectGenUniform([]float64{1, 1}, []float64{2, 2}, []int{9, 9})
+	_, u0 := solve(g0,
+		func(x la.Vector, t float64) float64 { return src(x) },
+		func(x la.Vector, t float64) float64 { return ebc(x) })
+
+	// rotated by 45°: the functions receive physical coordinates
+	g := new(gm.Grid)
+	g.RectGenRotated([]float64{1, 1}, []float64{2, 2}, []int{9, 9}, math.Pi/4)
+	chk.Array(tst, "x(8,0)", 1e-15, g.Node(g.IndexMNPtoI(8, 0, 0)), []float64{1 + math.Sqrt2/2, 1 + math.Sqrt2/2})
+	s, u := solve(g,
+		func(x la.Vector, t float64) float64 { return src(g.ToLocal(x)) },
+		func(x la.Vector, t float64) float64 { return ebc(g.ToLocal(x)) })
+	chk.Array(tst, "u(rotated)", 1e-13, u, u0)
+
+	// point source given in physical coordinates
+	nodes, _ := s.AddPointSource(g.Node(g.IndexMNPtoI(3, 5, 0)), 1)
+	chk.Ints(tst, "point source node", nodes, []int{g.IndexMNPtoI(3, 5, 0)})
+
+	// linear field in physical coordinates is reproduced exactly
+	_, u = solve(g, nil, func(x la.Vector, t float64) float64 { return 2*x[0] - x[1] })
+	for I := 0; I < g.Size(); I++ {
+		x := g.Node(I)
+		chk.Float64(tst, io.Sf("u%d", I), 1e-13, u[I], 2*x[0]-x[1])
+	}
+}
+
+func TestFdm35(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm35. cached boundary correction")
+
+	// operator
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{7, 6})
+	source := func(x la.Vector, t float64) float64 { return 1 + x[0] }
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 2}, {N: "ky", V: 1}}, g, source)
+	s.AddEbc(10, 1, nil)
+	s.AddEbc(11, 0, func(x la.Vector, t float64) float64 { return x[1] * x[1] })
+	s.AddNbc(20, 0.5, nil)
+	s.Assemble(false)
+	e := s.Eqs
+
+	// fresh correction: {s} - [Auk]⋅{xk}
+	fresh := func(xk la.Vector) (bu la.Vector) {
+		bu = la.NewVector(e.Nu)
+		for i, I := range e.UtoF {
+			bu[i] = s.calcBu(I, 0)
+		}
+		la.SpMatVecMulAdd(bu, -1, e.Auk.ToMatrix(nil), xk)
+		return
+	}
+	xk := la.NewVector(e.Nk)
+	correct := func() (bu la.Vector) {
+		for i, I := range e.KtoF {
+			xk[i] = s.calcXk(I, 0)
+		}
+		bu = la.NewVector(e.Nu)
+		for i, I := range e.UtoF {
+			bu[i] = s.calcBu(I, 0)
+		}
+		s.ApplyBoundaryCorrection(bu, xk)
+		return
+	}
+
+	// first call caches [Auk]
+	bu := correct()
+	chk.Array(tst, "bu", 1e-15, bu, fresh(xk))
+	cached := s.aukMat
+	if cached == nil {
+		tst.Errorf("[Auk] should be cached\n")
+		return
+	}
+
+	// only xk changes
+	s.UpdateEbc(10, -3, nil)
+	s.UpdateEbc(11, 0, func(x la.Vector, t float64) float64 { return math.Sin(x[1]) })
+	bu = correct()
+	chk.Array(tst, "bu (new xk)", 1e-15, bu, fresh(xk))
+	if s.aukMat != cached {
+		tst.Errorf("cached [Auk] should be reused\n")
+		return
+	}
+
+	// the corrected RHS gives the solution
+	xu := la.SpSolve(e.Auu, bu)
+	u, _ := s.SolveSteady(false)
+	uu := la.NewVector(e.Nu)
+	e.SplitVector(uu, la.NewVector(e.Nk), u)
+	chk.Array(tst, "xu", 1e-13, xu, uu)
+
+	// assembling again discards the cache
+	s.Assemble(false)
+	if s.aukMat != nil {
+		tst.Errorf("cached [Auk] should be discarded by Assemble\n")
+	}
+}
+
+func TestFdm36(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm36. write solution in NumPy format")
+
+	// solution
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{3, 1}, []int{4, 3})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	s.AddEbc(10, 0, nil)
+	s.AddEbc(11, 3, nil)
+	s.Assemble(false)
+	u, _ := s.SolveSteady(false) // u = x
+	s.WriteNpy("/tmp/gosl/pde", "fdm36", u)
+
+	// header
+	b := io.ReadFile("/tmp/gosl/pde/fdm36.npy")
+	chk.String(tst, string(b[:6]), "\x93NUMPY")
+	hlen := int(b[8]) + 256*int(b[9])
+	header := string(b[10 : 10+hlen])
+	io.Pforan("header = %q\n", header)
+	chk.String(tst, header[:59], "{'descr': '<f8', 'fortran_order': False, 'shape': (3, 4), }")
+	chk.Int(tst, "size of data", len(b)-10-hlen, 8*12)
+
+	// values: a[n, m] = x
+	shape, a := io.ReadNpy("/tmp/gosl/pde/fdm36.npy")
+	chk.Ints(tst, "shape", shape, []int{3, 4})
+	chk.Array(tst, "a", 1e-15, a, u)
+	chk.Float64(tst, "a[0, 3]", 1e-14, a[3], 3)
+	chk.Float64(tst, "a[2, 1]", 1e-14, a[2*4+1], 1)
+}
+
+func TestFdm37(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm37. stretched grid")
+
+	// the three-point formula is exact for quadratic functions on non-uniform grids
+	//   ∂²u/∂x² + ∂²u/∂y² = 6   with   u = x² + 2 y²
+	g := new(gm.Grid)
+	g.RectSet2d([]float64{0, 0.05, 0.15, 0.35, 0.7, 1}, []float64{0, 0.5, 0.8, 1, 1.1})
+	exact := func(x la.Vector, t float64) float64 { return x[0]*x[0] + 2*x[1]*x[1] }
+	source := func(x la.Vector, t float64) float64 { return 6 }
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, source)
+	for _, tag := range []int{10, 11, 20, 21} {
+		s.AddEbc(tag, 0, exact)
+	}
+	s.Assemble(false)
+	u, _ := s.SolveSteady(false)
+	for I := 0; I < g.Size(); I++ {
+		chk.Float64(tst, io.Sf("u%d", I), 1e-13, u[I], exact(g.Node(I), 0))
+	}
+
+	// stencil of node (1,1): h⁻x = 0.05, h⁺x = 0.1, h⁻y = 0.5 and h⁺y = 0.3
+	nodes, coefs := s.StencilAt(g.IndexMNPtoI(1, 1, 0))
+	io.Pforan("coefs = %v\n", coefs)
+	coef := make(map[int]float64)
+	for k, J := range nodes {
+		coef[J] += coefs[k]
+	}
+	chk.Float64(tst, "left", 1e-12, coef[g.IndexMNPtoI(0, 1, 0)], 2/(0.05*0.15))
+	chk.Float64(tst, "right", 1e-12, coef[g.IndexMNPtoI(2, 1, 0)], 2/(0.1*0.15))
+	chk.Float64(tst, "bottom", 1e-12, coef[g.IndexMNPtoI(1, 0, 0)], 2/(0.5*0.8))
+	chk.Float64(tst, "top", 1e-12, coef[g.IndexMNPtoI(1, 2, 0)], 2/(0.3*0.8))
+}
+
+func TestFdm38(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm38. dense system with size limit")
+
+	// small grid ⇒ 4×4 = 16 unknowns
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{6, 5})
+	source := func(x la.Vector, t float64) float64 { return x[0] - x[1] }
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 2}}, g, source)
+	s.AddEbc(10, 1, nil)
+	s.AddEbc(11, 0, func(x la.Vector, t float64) float64 { return x[1] })
+	s.AddEbc(20, 0, nil)
+	s.AddNbc(21, 0.5, nil)
+	s.Assemble(false)
+	A, b, err := s.DenseSystem()
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	chk.Int(tst, "M", A.M, s.Eqs.Nu)
+	chk.Int(tst, "len(b)", len(b), s.Eqs.Nu)
+	chk.Deep2(tst, "A", 1e-15, A.GetDeep2(), s.Eqs.Auu.ToDense().GetDeep2())
+	uDense, err := s.SolveDense()
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	u, _ := s.SolveSteady(false)
+	chk.Array(tst, "u(dense)", 1e-13, uDense, u)
+
+	// large grid with the default limit
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{50, 50})
+	s = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	s.AddEbc(10, 0, nil)
+	s.Assemble(false)
+	A, b, err = s.DenseSystem()
+	io.Pforan("err = %v\n", err)
+	if err == nil || A != nil || b != nil {
+		tst.Errorf("DenseSystem should have failed\n")
+		return
+	}
+	e, ok := err.(*DenseLimitError)
+	if !ok {
+		tst.Errorf("error should be *DenseLimitError\n")
+		return
+	}
+	chk.Int(tst, "N", e.N, 49*50)
+	chk.Int(tst, "limit", e.Limit, DefaultMaxDense)
+	chk.Int(tst, "bytes", e.Bytes(), 8*2450*2450)
+	if !strings.Contains(err.Error(), "45.8 MB") {
+		tst.Errorf("error message should contain the memory estimate\n")
+	}
+
+	// configured limit
+	s.MaxDense = 2450
+	if _, _, err = s.DenseSystem(); err != nil {
+		tst.Errorf("%v\n", err)
+	}
+	s.MaxDense = 2449
+	if _, err = s.SolveDense(); err == nil {
+		tst.Errorf("SolveDense should have failed\n")
+	}
+}
+
+func TestFdm39(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm39. iterative solution with options")
+
+	// operator on stretched grid (non-symmetric matrix)
+	g := new(gm.Grid)
+	X, Y := make([]float64, 21), make([]float64, 17)
+	for i := range X {
+		X[i] = math.Pow(float64(i)/20, 1.5)
+	}
+	for j := range Y {
+		Y[j] = 2 * math.Pow(float64(j)/16, 1.3)
+	}
+	g.RectSet2d(X, Y)
+	source := func(x la.Vector, t float64) float64 { return 1 + x[0]*x[1] }
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 2}, {N: "ky", V: 1}}, g, source)
+	s.AddEbc(10, 0, nil)
+	s.AddEbc(11, 0, func(x la.Vector, t float64) float64 { return x[1] })
+	s.AddNbc(20, 0.5, nil)
+	s.Assemble(false)
+	uRef, _ := s.SolveSteady(false)
+
+	// relative residual of the u-system
+	A, b, _ := s.DenseSystem()
+	residual := func(u []float64) float64 {
+		r := la.NewVector(s.Eqs.Nu)
+		for i := range s.Eqs.UtoF {
+			r[i] = b[i]
+			for j, J := range s.Eqs.UtoF {
+				r[i] -= A.Get(i, j) * u[J]
+			}
+		}
+		return r.Norm() / b.Norm()
+	}
+
+	// loose and tight tolerances
+	var nits []int
+	var ress []float64
+	for _, tol := range []float64{1e-3, 1e-10} {
+		u, nit := s.SolveIterative(&SolveOptions{Tol: tol, MaxIt: 2000, Precond: "ssor", Restart: 20})
+		res := residual(u)
+		io.Pforan("tol = %g: nit = %d, residual = %g\n", tol, nit, res)
+		if res > tol {
+			tst.Errorf("residual %g is greater than the tolerance %g\n", res, tol)
+			return
+		}
+		nits = append(nits, nit)
+		ress = append(ress, res)
+		if tol == 1e-10 {
+			chk.Array(tst, "u(tight)", 1e-8, u, uRef)
+		}
+	}
+	if nits[0] >= nits[1] || ress[0] < 1e-8 {
+		tst.Errorf("the loose tolerance should give fewer iterations and a larger residual\n")
+	}
+
+	// defaults
+	u, nit := s.SolveIterative(nil)
+	io.Pforan("defaults: nit = %d, residual = %g\n", nit, residual(u))
+	chk.Array(tst, "u(defaults)", 1e-8, u, uRef)
+
+	// not converged
+	defer chk.RecoverTstPanicIsOK(tst)
+	s.SolveIterative(&SolveOptions{MaxIt: 3, Precond: "none"})
+}
+
+func TestFdm40(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm40. least-squares solution of over-determined problem")
+
+	// essential conditions everywhere and Cauchy data at the left edge; u = x + 2y
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{6, 6})
+	exact := func(x la.Vector, t float64) float64 { return x[0] + 2*x[1] }
+	setup := func(qLeft float64) (s *FdmLaplacian) {
+		s = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+		for _, tag := range []int{10, 11, 20, 21} {
+			s.AddEbc(tag, 0, exact)
+		}
+		s.AddNbc(10, qLeft, nil)
+		s.Assemble(false)
+		return
+	}
+
+	// consistent data ⇒ exact solution with zero residual
+	s := setup(-1)
+	u, res := s.SolveLeastSquares(0)
+	io.Pforan("consistent: res = %g\n", res)
+	chk.Float64(tst, "res", 1e-10, res, 0)
+	for I := 0; I < g.Size(); I++ {
+		chk.Float64(tst, io.Sf("u%d", I), 1e-10, u[I], exact(g.Node(I), 0))
+	}
+
+	// inconsistent data
+	s = setup(-0.7)
+	u, res = s.SolveLeastSquares(0)
+	rows := s.lsqRows(0)
+	residual := func(v []float64) (r []float64) {
+		r = make([]float64, len(rows))
+		for i, row := range rows {
+			r[i] = -row.rhs
+			for k, c := range row.cols {
+				r[i] += row.vals[k] * v[c]
+			}
+		}
+		return
+	}
+	r := residual(u)
+	chk.Float64(tst, "res", 1e-14, res, la.Vector(r).Norm())
+	io.Pforan("inconsistent: res = %g (%d equations, %d unknowns)\n", res, len(rows), g.Size())
+	if len(rows) <= g.Size() || res < 1e-3 {
+		tst.Errorf("the system should be over-determined and inconsistent\n")
+		return
+	}
+
+	// normal equations: Aᵀ⋅(A⋅u - b) = 0
+	atr := la.NewVector(g.Size())
+	for i, row := range rows {
+		for k, c := range row.cols {
+			atr[c] += row.vals[k] * r[i]
+		}
+	}
+	chk.Float64(tst, "‖Aᵀ⋅r‖", 1e-10, atr.Norm(), 0)
+
+	// perturbed solutions and standard solution have larger residuals
+	rnd.Init(1234)
+	for trial := 0; trial < 10; trial++ {
+		v := make([]float64, len(u))
+		for I := range v {
+			v[I] = u[I] + rnd.Float64(-1e-3, 1e-3)
+		}
+		if resv := la.Vector(residual(v)).Norm(); resv < res {
+			tst.Errorf("residual of perturbed solution is smaller: %g < %g\n", resv, res)
+			return
+		}
+	}
+	uStd, _ := s.SolveSteady(false)
+	resStd := la.Vector(residual(uStd)).Norm()
+	io.Pforan("standard solution: res = %g\n", resStd)
+	if resStd <= res {
+		tst.Errorf("residual of standard solution should be larger: %g ≤ %g\n", resStd, res)
+	}
+
+	// a large weight enforces the prescribed values
+	errEbc := func(v []float64) (err float64) {
+		for _, I := range s.EssenBcs.Nodes() {
+			err = math.Max(err, math.Abs(v[I]-exact(g.Node(I), 0)))
+		}
+		return
+	}
+	uw, _ := s.SolveLeastSquares(1e3)
+	io.Pforan("error at prescribed nodes: w = 1: %g, w = 1000: %g\n", errEbc(u), errEbc(uw))
+	if errEbc(uw) > 1e-2*errEbc(u) {
+		tst.Errorf("large weight should enforce the prescribed values\n")
+	}
+}
+
+func TestFdm41(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm41. Green's function columns")
+
+	// operator with homogeneous essential conditions
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{7, 6})
+	source := func(x la.Vector, t float64) float64 { return x[0] * (1 - x[1]) }
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 2}}, g, source)
+	for _, tag := range []int{10, 11, 20, 21} {
+		s.AddEbc(tag, 0, nil)
+	}
+	s.Assemble(false)
+	defer s.Free()
+
+	// columns
+	G := make([][]float64, g.Size())
+	for I := 0; I < g.Size(); I++ {
+		G[I] = s.GreensFunctionColumn(I)
+	}
+
+	// symmetry and sign
+	for _, I := range s.Eqs.UtoF {
+		for _, J := range s.Eqs.UtoF {
+			chk.Float64(tst, io.Sf("G(%d,%d) = G(%d,%d)", I, J, J, I), 1e-15, G[J][I], G[I][J])
+			if G[J][I] > 0 {
+				tst.Errorf("G(%d,%d) = %g should not be positive\n", I, J, G[J][I])
+				return
+			}
+		}
+	}
+	for _, I := range s.Eqs.KtoF {
+		chk.Array(tst, io.Sf("G(:,%d)", I), 1e-15, G[I], nil)
+	}
+
+	// [Auu]⋅{gu} = {δ}
+	i := s.Eqs.Nu / 2
+	gu := la.NewVector(s.Eqs.Nu)
+	for j, J := range s.Eqs.UtoF {
+		gu[j] = G[s.Eqs.UtoF[i]][J]
+	}
+	δ := la.NewVector(s.Eqs.Nu)
+	s.Apply(δ, gu)
+	δ[i] -= 1
+	chk.Float64(tst, "|Auu⋅gu - δ|", 1e-13, δ.Largest(1), 0)
+
+	// superposition
+	u, _ := s.SolveSteady(false)
+	for I := 0; I < g.Size(); I++ {
+		uI := 0.0
+		for _, J := range s.Eqs.UtoF {
+			uI += G[J][I] * source(g.Node(J), 0)
+		}
+		chk.Float64(tst, io.Sf("u%d", I), 1e-14, uI, u[I])
+	}
+}
+
+func TestFdm42(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm42. solution on half domain with symmetry plane")
+
+	// symmetric problem about x = 0
+	source := func(x la.Vector, t float64) float64 { return 1 + x[0]*x[0]*x[1] }
+	top := func(x la.Vector, t float64) float64 { return 1 - x[0]*x[0] }
+	params := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 0.5}}
+	setup := func(xmin float64, nx int) (s *FdmLaplacian) {
+		g := new(gm.Grid)
+		g.RectGenUniform([]float64{xmin, 0}, []float64{1, 1}, []int{nx, 9})
+		s = NewFdmLaplacian(params, g, source)
+		s.AddEbc(11, 0, nil)
+		s.AddEbc(20, 0, nil)
+		s.AddEbc(21, 0, top)
+		if xmin < 0 {
+			s.AddEbc(10, 0, nil)
+		} else {
+			s.NaturBcs.SetSymmetryPlane(10, 0)
+		}
+		s.Assemble(false)
+		return
+	}
+
+	// full and half domains
+	full, half := setup(-1, 21), setup(0, 11)
+	uFull, _ := full.SolveSteady(false)
+	uHalf, _ := half.SolveSteady(false)
+	for I := 0; I < half.Grid.Size(); I++ {
+		m, n, _ := half.Grid.IndexItoMNP(I)
+		J := full.Grid.IndexMNPtoI(m+10, n, 0)
+		chk.Float64(tst, io.Sf("u%d", I), 1e-14, uHalf[I], uFull[J])
+	}
+}
+
+func TestFdm43(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm43. a-posteriori error bounds")
+
+	// u = x² + y² + x⋅y is reproduced exactly by FDM:  L{u} = 1⋅2 + 2⋅2 = 6
+	ana := func(x la.Vector, t float64) float64 { return x[0]*x[0] + x[1]*x[1] + x[0]*x[1] }
+	source := func(x la.Vector, t float64) float64 { return 6 }
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{13, 11})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 2}}, g, source)
+	defer s.Free()
+	for _, tag := range []int{10, 11, 20, 21} {
+		s.AddEbc(tag, 0, ana)
+	}
+	s.Assemble(false)
+
+	// inaccurate iterative solutions
+	for _, tol := range []float64{1e-2, 1e-4} {
+		u, nit := s.SolveIterative(&SolveOptions{Tol: tol, Precond: "none"})
+		bound := s.ErrorEstimate(u)
+		emax, bmax := 0.0, 0.0
+		for I := 0; I < g.Size(); I++ {
+			e := math.Abs(u[I] - ana(g.Node(I), 0))
+			if e > bound[I]*(1+1e-10)+1e-14 {
+				tst.Errorf("tol = %g: error at node %d is greater than the bound: %g > %g\n", tol, I, e, bound[I])
+				return
+			}
+			emax, bmax = math.Max(emax, e), math.Max(bmax, bound[I])
+		}
+		io.Pforan("tol = %g: nit = %d, max error = %.3e, max bound = %.3e\n", tol, nit, emax, bmax)
+		if bmax > 100*emax {
+			tst.Errorf("tol = %g: bound is too conservative: %g ≫ %g\n", tol, bmax, emax)
+		}
+	}
+
+	// direct solution
+	u, _ := s.SolveSteady(false)
+	bound := s.ErrorEstimate(u)
+	chk.Array(tst, "bound (direct)", 1e-12, bound, nil)
+
+	// non-polynomial solution: u = sin(2x)⋅exp(y) with L{u} = (-4⋅1 + 2)⋅u; i.e. the bounds include
+	// the discretisation error
+	ana = func(x la.Vector, t float64) float64 { return math.Sin(2*x[0]) * math.Exp(x[1]) }
+	source = func(x la.Vector, t float64) float64 { return -2 * ana(x, t) }
+	for _, npts := range []int{9, 17, 33} {
+		g = new(gm.Grid)
+		g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{npts, npts})
+		s = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 2}}, g, source)
+		for _, tag := range []int{10, 11, 20, 21} {
+			s.AddEbc(tag, 0, ana)
+		}
+		s.Assemble(false)
+		for _, tol := range []float64{1e-6, 0} {
+			if tol == 0 {
+				u, _ = s.SolveSteady(false)
+			} else {
+				u, _ = s.SolveIterative(&SolveOptions{Tol: tol, Precond: "none"})
+			}
+			bound = s.ErrorEstimate(u)
+			emax, bmax := 0.0, 0.0
+			for I := 0; I < g.Size(); I++ {
+				e := math.Abs(u[I] - ana(g.Node(I), 0))
+				if e > bound[I] {
+					tst.Errorf("npts = %d, tol = %g: error at node %d is greater than the bound: %g > %g\n", npts, tol, I, e, bound[I])
+					return
+				}
+				emax, bmax = math.Max(emax, e), math.Max(bmax, bound[I])
+			}
+			io.Pforan("npts = %2d, tol = %g: max error = %.3e, max bound = %.3e\n", npts, tol, emax, bmax)
+			if bmax > 4*emax {
+				tst.Errorf("npts = %d, tol = %g: bound is too conservative: %g ≫ %g\n", npts, tol, bmax, emax)
+			}
+		}
+		s.Free()
+	}
+}
+
+func TestFdm44(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm44. grid with one interior node (2×2 cells)")
+
+	// all borders fixed: u = x + 2y  ⇒  u(½,½) = 1.5 with L{u} = 0
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{3, 3})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 3}}, g, nil)
+	defer s.Free()
+	ana := func(x la.Vector, t float64) float64 { return x[0] + 2*x[1] }
+	for _, tag := range []int{10, 11, 20, 21} {
+		s.AddEbc(tag, 0, ana)
+	}
+	s.Assemble(false)
+	chk.Int(tst, "Nu", s.Eqs.Nu, 1)
+	chk.Ints(tst, "decoupled", s.DecoupledNodes(), []int{4})
+	chk.Deep2(tst, "Auu", 1e-13, s.Eqs.Auu.ToDense().GetDeep2(), [][]float64{{-2 * (1 + 3) / 0.25}})
+	u, _ := s.SolveSteady(false)
+	chk.Float64(tst, "u(½,½)", 1e-14, u[4], 1.5)
+
+	// coupled unknowns on larger grids
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{4, 3})
+	s = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 3}}, g, nil)
+	s.SetHbc()
+	s.Assemble(false)
+	chk.Ints(tst, "decoupled", s.DecoupledNodes(), nil)
+}
+
+func TestFdm45(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm45. panic on singular rows")
+
+	// no coefficients and no reaction: the interior equation is empty
+	defer chk.RecoverTstPanicIsOK(tst)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{3, 3})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 0}, {N: "ky", V: 0}}, g, nil)
+	s.SetHbc()
+	s.Assemble(false)
+}
+
+func TestFdm46(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm46. warm start of iterative solver")
+
+	// operator with time-dependent-like source
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{21, 21})
+	amp := 1.0
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, func(x la.Vector, t float64) float64 {
+		return amp * (1 + x[0]*x[1])
+	})
+	defer s.Free()
+	s.AddEbc(10, 0.0, nil)
+	s.AddEbc(11, 1.0, nil)
+	s.Assemble(false)
+
+	// previous solution and slightly perturbed problem
+	uOld, _ := s.SolveIterative(&SolveOptions{Tol: 1e-12})
+	amp = 1.001
+	uCold, nitCold := s.SolveIterative(&SolveOptions{Tol: 1e-10})
+	uWarm, nitWarm := s.SolveIterative(&SolveOptions{Tol: 1e-10, Guess: uOld})
+	io.Pforan("cold: nit = %d\n", nitCold)
+	io.Pforan("warm: nit = %d\n", nitWarm)
+	chk.Array(tst, "u(warm)", 1e-8, uWarm, uCold)
+	if nitWarm >= nitCold {
+		tst.Errorf("warm start should take fewer iterations: %d ≥ %d\n", nitWarm, nitCold)
+	}
+
+	// wrong size
+	defer chk.RecoverTstPanicIsOK(tst)
+	s.SolveIterative(&SolveOptions{Guess: uOld[1:]})
+}
+
+func TestFdm47(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm47. operator on grid loaded from file")
+
+	// procedural and loaded grids
+	g := new(gm.Grid)
+	g.RectSet2d([]float64{0, 0.1, 0.3, 0.6, 1.0}, []float64{0, 0.25, 0.4, 1.0})
+	g.WriteStructured("/tmp/gosl/pde", "fdm47")
+	h := gm.LoadStructuredGrid("/tmp/gosl/pde/fdm47.json")
+
+	// operators
+	source := func(x la.Vector, t float64) float64 { return x[0] * x[1] }
+	assemble := func(grid *gm.Grid) (s *FdmLaplacian) {
+		s = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1.5}, {N: "ky", V: 0.5}}, grid, source)
+		s.AddEbc(10, 1.0, nil)
+		s.AddNbc(21, 0.2, nil)
+		s.Assemble(false)
+		return
+	}
+	a, b := assemble(g), assemble(h)
+	defer a.Free()
+	defer b.Free()
+	chk.Deep2(tst, "Auu", 1e-15, b.Eqs.Auu.ToDense().GetDeep2(), a.Eqs.Auu.ToDense().GetDeep2())
+	chk.Deep2(tst, "Auk", 1e-15, b.Eqs.Auk.ToDense().GetDeep2(), a.Eqs.Auk.ToDense().GetDeep2())
+	ua, _ := a.SolveSteady(false)
+	ub, _ := b.SolveSteady(false)
+	chk.Array(tst, "u", 1e-15, ub, ua)
+}
+
+func TestFdm48(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm48. information on solution method")
+
+	// operator
+	source := func(x la.Vector, t float64) float64 { return 1 + x[0] }
+	solve := func(g *gm.Grid, opts *SolveOptions) (info SolveInfo) {
+		s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 2}}, g, source)
+		defer s.Free()
+		s.AddEbc(10, 0, nil)
+		s.AddEbc(11, 0, nil)
+		s.AddEbc(20, 0, nil)
+		s.AddEbc(21, 1, nil)
+		s.Assemble(false)
+		if opts == nil {
+			s.SolveSteady(false)
+		} else {
+			s.SolveIterative(opts)
+		}
+		info = s.SolveInfo()
+		io.Pforan("%+v\n", info)
+		return
+	}
+
+	// direct
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{11, 11})
+	info := solve(g, nil)
+	chk.String(tst, info.Method, "umfpack")
+	chk.String(tst, info.Precond, "")
+	chk.Int(tst, "nit (direct)", info.Nit, 0)
+	if info.Residual > 1e-13 || info.FactTime <= 0 {
+		tst.Errorf("direct solution: residual = %g and time = %v are incorrect\n", info.Residual, info.FactTime)
+	}
+
+	// the matrix of the residual is converted once per assembly
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	defer s.Free()
+	s.SetHbc()
+	s.Assemble(false)
+	s.SolveSteady(false)
+	cached := s.auuMat
+	s.UpdateEbc(10, 1, nil)
+	s.SolveSteady(false)
+	if cached == nil || s.auuMat != cached {
+		tst.Errorf("cached [Auu] should be reused by SolveSteady\n")
+	}
+	if s.SolveInfo().Residual > 1e-13 {
+		tst.Errorf("direct solution (new bcs): residual = %g is incorrect\n", s.SolveInfo().Residual)
+	}
+	s.Assemble(false)
+	if s.auuMat != nil {
+		tst.Errorf("cached [Auu] should be discarded by Assemble\n")
+	}
+
+	// symmetric negative-definite Laplacian (all edges prescribed; i.e. no ghost nodes)
+	info = solve(g, &SolveOptions{Method: "auto", Tol: 1e-10})
+	chk.String(tst, info.Method, "cg")
+	chk.String(tst, info.Precond, "jacobi")
+	if info.Nit < 1 || info.Residual > 1e-10 {
+		tst.Errorf("cg: nit = %d and residual = %g are incorrect\n", info.Nit, info.Residual)
+	}
+
+	// non-symmetric matrix due to the stretched grid
+	g.RectSet2d([]float64{0, 0.1, 0.3, 0.6, 1.0}, []float64{0, 0.2, 0.3, 0.7, 1.0})
+	info = solve(g, &SolveOptions{Method: "auto", Precond: "ssor", Tol: 1e-10})
+	chk.String(tst, info.Method, "gmres")
+	chk.String(tst, info.Precond, "ssor")
+	if info.Nit < 1 || info.Residual > 1e-10 {
+		tst.Errorf("gmres: nit = %d and residual = %g are incorrect\n", info.Nit, info.Residual)
+	}
+
+	// default method
+	info = solve(g, nil)
+	chk.String(tst, info.Method, "umfpack")
+	info = solve(g, &SolveOptions{Precond: "none"})
+	chk.String(tst, info.Method, "gmres")
+	chk.String(tst, info.Precond, "none")
+}
+
+func TestFdm49(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm49. integral constraint with natural conditions on all edges")
+
+	// grid
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{21, 21})
+	w := GridQuadrature(g)
+	integral := func(u []float64) (res float64) {
+		for I, v := range u {
+			res += w[I] * v
+		}
+		return
+	}
+
+	// ∇²u = s with ∂u/∂n = 0 @ all edges and ∫u dΩ = 3  ⇒  u = cos(πx)⋅cos(πy) + 3
+	uana := func(x la.Vector) float64 { return math.Cos(math.Pi*x[0])*math.Cos(math.Pi*x[1]) + 3 }
+	source := func(x la.Vector, t float64) float64 { return -2 * math.Pi * math.Pi * (uana(x) - 3) }
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, source)
+	s.SetIntegralConstraint(3)
+	s.Assemble(false)
+	chk.Int(tst, "Nk", s.Eqs.Nk, 0)
+	u, _ := s.SolveSteady(false)
+	io.Pforan("∫u dΩ = %v, λ = %v\n", integral(u), s.IntegralMultiplier())
+	chk.Float64(tst, "∫u dΩ", 1e-12, integral(u), 3)
+	chk.Float64(tst, "λ (compatible source)", 1e-10, s.IntegralMultiplier(), 0)
+	for I, v := range u {
+		chk.Float64(tst, "u", 5e-3, v, uana(g.Node(I)))
+	}
+	if s.SolveInfo().Residual > 1e-12 {
+		tst.Errorf("residual = %g is incorrect\n", s.SolveInfo().Residual)
+	}
+
+	// incompatible source: wᵀ⋅Auu = 0  ⇒  λ = wᵀ⋅bu / wᵀ⋅w
+	s.Source = func(x la.Vector, t float64) float64 { return 1 }
+	s.SetIntegralConstraint(-1)
+	u, _ = s.SolveSteady(false)
+	ww := 0.0
+	for _, v := range w {
+		ww += v * v
+	}
+	io.Pforan("∫u dΩ = %v, λ = %v\n", integral(u), s.IntegralMultiplier())
+	chk.Float64(tst, "∫u dΩ", 1e-12, integral(u), -1)
+	chk.Float64(tst, "λ (incompatible source)", 1e-8, s.IntegralMultiplier(), 1/ww)
+
+	// with prescribed values, the constraint is also satisfied
+	s.Source = nil
+	s.AddEbc(10, 1, nil)
+	s.SetIntegralConstraint(0.5)
+	s.Assemble(false)
+	u, _ = s.SolveSteady(false)
+	chk.Float64(tst, "∫u dΩ (with Ebc)", 1e-12, integral(u), 0.5)
+
+	// clear
+	s.ClearIntegralConstraint()
+	u, _ = s.SolveSteady(false)
+	for _, v := range u {
+		chk.Float64(tst, "u (without constraint)", 1e-12, v, 1)
+	}
+	chk.Float64(tst, "λ", 1e-15, s.IntegralMultiplier(), 0)
+}
+
+func TestFdm50(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm50. sensitivity of the solution to the coefficients")
+
+	// stretched grid: u = 1 @ x = 0, qn = 0.5 @ x = 1, u + 2⋅∂u/∂n = 0.3 @ y = 1 and source
+	g := new(gm.Grid)
+	g.RectSet2d([]float64{0, 0.1, 0.25, 0.45, 0.7, 1.0}, []float64{0, 0.2, 0.35, 0.6, 0.8, 1.0})
+	source := func(x la.Vector, t float64) float64 { return 1 + x[0]*x[1] }
+	solver := func(kx, ky, kr float64) (s *FdmLaplacian) {
+		s = NewFdmLaplacian(dbf.Params{{N: "kx", V: kx}, {N: "ky", V: ky}, {N: "kr", V: kr}}, g, source)
+		s.AddEbc(10, 1, nil)
+		s.AddNbc(11, 0.5, nil)
+		s.RobinBcs.SetInGrid(21, 1, 2, 0.3)
+		s.Assemble(false)
+		return
+	}
+	solve := func(kx, ky, kr float64) (u []float64) {
+		s := solver(kx, ky, kr)
+		u, _ = s.SolveSteady(false)
+		return
+	}
+
+	// compare with central differences
+	kx, ky, kr := 1.5, 0.8, 0.4
+	s := solver(kx, ky, kr)
+	defer s.Free()
+	h := 1e-4
+	for _, key := range []string{"kx", "ky", "kr"} {
+		dudp := s.SensitivityToParam(key)
+		var ua, ub []float64
+		switch key {
+		case "kx":
+			ua, ub = solve(kx+h, ky, kr), solve(kx-h, ky, kr)
+		case "ky":
+			ua, ub = solve(kx, ky+h, kr), solve(kx, ky-h, kr)
+		case "kr":
+			ua, ub = solve(kx, ky, kr+h), solve(kx, ky, kr-h)
+		}
+		num := make([]float64, g.Size())
+		for I := range num {
+			num[I] = (ua[I] - ub[I]) / (2 * h)
+		}
+		io.Pforan("du/d%s @ (1,1) = %v (numerical = %v)\n", key, dudp[g.Size()-1], num[g.Size()-1])
+		chk.Array(tst, "du/d"+key, 1e-8, dudp, num)
+		for I := 0; I < g.Size(); I += g.Npts(0) {
+			chk.Float64(tst, "du/d"+key+" @ x = 0", 1e-15, dudp[I], 0)
+		}
+	}
+	chk.Float64(tst, "kx (restored)", 1e-15, s.Kx, kx)
+
+	// kxy on uniform grid (kxy = 0 allowed)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{7, 7})
+	solverKxy := func(kxy float64) (s *FdmLaplacian) {
+		s = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}, {N: "kxy", V: kxy}}, g, source)
+		s.AddEbc(10, 0, nil)
+		s.AddEbc(21, 1, nil)
+		s.Assemble(false)
+		return
+	}
+	for _, kxy := range []float64{0, 0.3} {
+		s := solverKxy(kxy)
+		dudp := s.SensitivityToParam("kxy")
+		s.Free()
+		ua, _ := solverKxy(kxy + h).SolveSteady(false)
+		ub, _ := solverKxy(kxy - h).SolveSteady(false)
+		for I := range dudp {
+			chk.Float64(tst, io.Sf("du/dkxy (kxy = %g)", kxy), 1e-8, dudp[I], (ua[I]-ub[I])/(2*h))
+		}
+	}
+}
+
+func TestFdm51(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm51. essential conditions imposed by the penalty method")
+
+	// ∇²u = s with u = sin(πy) @ x = 0, u = 1 @ y = 0, u + ∂u/∂n = 0.3 @ y = 1 and qn = 0.5 @ x = 1
+	g := new(gm.Grid)
+	g.RectSet2d([]float64{0, 0.1, 0.25, 0.45, 0.7, 1.0}, []float64{0, 0.2, 0.35, 0.6, 0.8, 1.0})
+	source := func(x la.Vector, t float64) float64 { return 1 + x[0]*x[1] }
+	solver := func() (s *FdmLaplacian) {
+		s = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 2}}, g, source)
+		s.AddEbc(10, 0, func(x la.Vector, t float64) float64 { return math.Sin(math.Pi * x[1]) })
+		s.AddEbc(20, 1, nil)
+		s.AddNbc(11, 0.5, nil)
+		s.RobinBcs.SetInGrid(21, 1, 1, 0.3)
+		s.Assemble(true)
+		return
+	}
+
+	// elimination
+	uRef, fRef := solver().SolveSteady(true)
+
+	// penalty method: the error decreases as 1/P
+	s := solver()
+	var errs []float64
+	for _, penalty := range []float64{1e6, 1e8, 1e10} {
+		s.UsePenaltyBC(penalty)
+		u, f := s.SolveSteady(true)
+		diff := 0.0
+		for I := range u {
+			diff = math.Max(diff, math.Abs(u[I]-uRef[I]))
+		}
+		io.Pforan("P = %g: max|u - uRef| = %g\n", penalty, diff)
+		errs = append(errs, diff)
+		if diff > 1e3/penalty { // O(|A[I][J]|/P) with |A[I][J]| ~ k/h² ~ 10²
+			tst.Errorf("error with P = %g is too large: %g\n", penalty, diff)
+		}
+		chk.Float64(tst, "u @ (0,1) (prescribed)", 1e4/penalty, u[g.Npts(0)], math.Sin(math.Pi*0.2))
+		chk.Array(tst, "reactions", 1e6/penalty, f, fRef)
+	}
+	chk.Float64(tst, "error ratio (P = 10⁶ and 10⁸)", 1, errs[0]/errs[1], 100)
+	chk.Float64(tst, "error ratio (P = 10⁸ and 10¹⁰)", 1, errs[1]/errs[2], 100)
+
+	// back to elimination
+	s.UsePenaltyBC(0)
+	u, _ := s.SolveSteady(false)
+	chk.Array(tst, "u (elimination)", 1e-15, u, uRef)
+}
+
+func TestFdm52(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm52. boundary conditions created for another grid")
+
+	// conditions on 3×3 grid
+	g3 := new(gm.Grid)
+	g3.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{3, 3})
+	ebcs := NewBoundaryCondsGrid(g3, 1)
+	ebcs.AddUsingTag(10, 0, 1, nil)
+
+	// solver on 4×4 grid
+	g4 := new(gm.Grid)
+	g4.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{4, 4})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g4, nil)
+	s.AddEbc(11, 0, nil)
+	err := s.SetBcs(ebcs, nil, nil)
+	io.Pforan("err = %v\n", err)
+	if err == nil {
+		tst.Errorf("SetBcs should return an error\n")
+		return
+	}
+	chk.String(tst, err.Error(), "essential boundary conditions were created for another grid: the grid of the conditions has 3×3 points but the grid of the operator has 4×4 points")
+	e, ok := err.(*GridMismatchError)
+	if !ok {
+		tst.Errorf("error should be a *GridMismatchError\n")
+		return
+	}
+	chk.String(tst, e.Kind, "essential")
+	chk.Ints(tst, "nodes (not modified)", s.EssenBcs.Nodes(), []int{3, 7, 11, 15})
+
+	// grid with the same number of points but different coordinates
+	g4b := new(gm.Grid)
+	g4b.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{4, 4})
+	err = s.SetBcs(nil, nil, NewRobinBcsGrid(g4b))
+	io.Pforan("err = %v\n", err)
+	if err == nil {
+		tst.Errorf("SetBcs should return an error\n")
+		return
+	}
+	chk.String(tst, err.(*GridMismatchError).Kind, "Robin")
+
+	// equivalent grid (generated again with the same data)
+	g4c := new(gm.Grid)
+	g4c.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{4, 4})
+	ebcs = NewBoundaryCondsGrid(g4c, 1)
+	ebcs.AddUsingTag(10, 0, 1, nil)
+	err = s.SetBcs(ebcs, nil, nil)
+	if err != nil {
+		tst.Errorf("SetBcs failed: %v\n", err)
+		return
+	}
+	s.Assemble(false)
+	u, _ := s.SolveSteady(false)
+	for I := 0; I < g4.Size(); I++ {
+		chk.Float64(tst, "u", 1e-14, u[I], 1)
+	}
+
+	// conditions replaced directly
+	defer chk.RecoverTstPanicIsOK(tst)
+	s.EssenBcs = NewBoundaryCondsGrid(g3, 1)
+	s.EssenBcs.AddUsingTag(10, 0, 1, nil)
+	s.bcsReady = false
+	s.Assemble(false)
+}
+
+func TestFdm53(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm53. convergence histories of iterative solvers")
+
+	// Dirichlet-Laplacian: symmetric negative definite
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{17, 17})
+	source := func(x la.Vector, t float64) float64 { return 1 + x[0]*x[1]*x[1] }
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, source)
+	s.SetHbc()
+	s.Assemble(false)
+
+	// conjugate gradient
+	_, nit := s.SolveIterative(&SolveOptions{Method: "cg", Precond: "none"})
+	hist := s.SolveInfo().History
+	io.Pforan("cg: nit = %d, residuals = %v\n", nit, hist.Residuals)
+	chk.String(tst, hist.Method, "cg")
+	chk.Int(tst, "len(residuals)", len(hist.Residuals), nit)
+	chk.Int(tst, "len(times)", len(hist.Times), nit)
+	chk.Ints(tst, "iterations", hist.Iterations, utl.IntRange2(1, nit+1))
+	chk.Float64(tst, "initial residual", 1e-15, hist.Initial, 1)
+	for k := 1; k < nit; k++ { // not guaranteed by CG in general (the error in the energy norm is), but true here
+		if hist.Residuals[k] >= hist.Residuals[k-1] {
+			tst.Errorf("residuals must decrease: r[%d] = %g ≥ r[%d] = %g\n", k, hist.Residuals[k], k-1, hist.Residuals[k-1])
+		}
+		if hist.Times[k] < hist.Times[k-1] {
+			tst.Errorf("times must not decrease\n")
+		}
+	}
+	if hist.Residuals[nit-1] > 1e-10 {
+		tst.Errorf("final residual = %g is incorrect\n", hist.Residuals[nit-1])
+	}
+
+	// json file
+	dir := "/tmp/gosl/pde"
+	hist.WriteJSON(dir, "history-cg")
+	var loaded ConvergenceHistory
+	err := json.Unmarshal(io.ReadFile(dir+"/history-cg.json"), &loaded)
+	if err != nil {
+		tst.Errorf("cannot read json file: %v\n", err)
+		return
+	}
+	chk.String(tst, loaded.Method, "cg")
+	chk.Ints(tst, "iterations (json)", loaded.Iterations, hist.Iterations)
+	chk.Array(tst, "residuals (json)", 1e-15, loaded.Residuals, hist.Residuals)
+
+	// gmres: non-increasing residuals
+	_, nit = s.SolveIterative(&SolveOptions{Method: "gmres"})
+	hist = s.SolveInfo().History
+	chk.Int(tst, "len(residuals) (gmres)", len(hist.Residuals), nit)
+	for k := 1; k < nit; k++ {
+		if hist.Residuals[k] > hist.Residuals[k-1] {
+			tst.Errorf("gmres residuals must not increase: r[%d] = %g > r[%d] = %g\n", k, hist.Residuals[k], k-1, hist.Residuals[k-1])
+		}
+	}
+
+	// multigrid
+	mg := NewFdmMultigridSolver(s, 3, nil)
+	umg, nit := mg.Solve()
+	chk.String(tst, mg.History.Method, "multigrid")
+	chk.Int(tst, "len(residuals) (multigrid)", len(mg.History.Residuals), nit)
+	chk.Float64(tst, "initial residual (multigrid)", 1e-15, mg.History.Initial, 1)
+	bu, _ := s.reducedRhs() // relative residual of the reduced system as with cg
+	uu := la.NewVector(s.Eqs.Nu)
+	for i, I := range s.Eqs.UtoF {
+		uu[i] = umg[I]
+	}
+	chk.Float64(tst, "final residual (multigrid)", 1e-12, mg.History.Residuals[nit-1], relResidual(s.Eqs.Auu.ToMatrix(nil), uu, bu))
+
+	// direct solver: no history
+	s.SolveSteady(false)
+	if s.SolveInfo().History != nil {
+		tst.Errorf("direct solver should not have a history\n")
+	}
+}
+
+func TestFdm54(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm54. operator applied at a subset of nodes")
+
+	// operator with mixed derivative and reaction
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{11, 8})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1.5}, {N: "ky", V: 0.5}, {N: "kxy", V: 0.3}}, g, nil)
+	s.Reaction = func(x la.Vector, t float64) float64 { return 1 + x[0]*x[1] }
+	s.AddEbc(10, 1, nil)
+	s.AddEbc(21, 0, nil)
+	s.Assemble(false)
+
+	// full matrix-vector product with random vector
+	rnd.Init(4321)
+	uu := la.NewVector(s.Eqs.Nu)
+	rnd.Float64s(uu, -1, 1)
+	full := la.NewVector(s.Eqs.Nu)
+	la.SpMatVecMul(full, 1, s.Eqs.Auu.ToMatrix(nil), uu)
+
+	// nodes around a point source, including nodes at the borders
+	var nodes []int
+	for _, I := range s.Eqs.UtoF {
+		x := g.Node(I)
+		if math.Hypot(x[0]-1.8, x[1]-0.3) < 0.45 || I == 1 || I == g.Size()-g.Npts(0)-1 {
+			nodes = append(nodes, I)
+		}
+	}
+	res := la.NewVector(len(nodes))
+	s.ApplyAt(res, uu, nodes)
+	ref := la.NewVector(len(nodes))
+	for k, I := range nodes {
+		ref[k] = full[s.Eqs.FtoU[I]]
+	}
+	io.Pforan("%d nodes: ‖res - ref‖ = %g\n", len(nodes), res.NormDiff(ref))
+	chk.Array(tst, "ApplyAt", 1e-13, res, ref)
+
+	// nodes with prescribed values are not in [Auu]
+	defer chk.RecoverTstPanicIsOK(tst)
+	s.ApplyAt(la.NewVector(1), uu, []int{0})
+}
+
+func TestFdm55(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm55. multiple right-hand sides")
+
+	// operator with natural condition (non-symmetric matrix)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{9, 7})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 2}}, g, func(x la.Vector, t float64) float64 { return x[0] * x[1] })
+	s.AddEbc(10, 1, nil)
+	s.AddEbc(20, 0, nil)
+	s.AddNbc(11, 0.5, nil)
+	s.Assemble(false)
+	nu := s.Eqs.Nu
+
+	// columns: reduced RHS of the problem, unit point source and random vector
+	bu, _ := s.reducedRhs()
+	I := 4 + 3*g.Npts(0)
+	rnd.Init(1357)
+	B := la.NewMatrix(nu, 3)
+	copy(B.Col(0), bu)
+	B.Set(s.Eqs.FtoU[I], 1, 1)
+	rnd.Float64s(B.Col(2), -1, 1)
+	X := s.SolveMultiRHS(B)
+	defer s.Free()
+
+	// compare with independent solves
+	for j := 0; j < 3; j++ {
+		chk.Array(tst, io.Sf("X[:,%d]", j), 1e-13, X.Col(j), la.SpSolve(s.Eqs.Auu, B.GetCol(j)))
+	}
+	u, _ := s.SolveSteady(false)
+	gI := s.GreensFunctionColumn(I)
+	for i, J := range s.Eqs.UtoF {
+		chk.Float64(tst, io.Sf("u[%d]", J), 1e-13, X.Get(i, 0), u[J])
+		chk.Float64(tst, io.Sf("G[%d]", J), 1e-15, X.Get(i, 1), gI[J])
+	}
+}
+
+func TestFdm56(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm56. non-finite entries during assembly")
+
+	// reaction function with division by zero at the centre of the grid
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{-1, -1}, []float64{1, 1}, []int{5, 5})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	s.Reaction = func(x la.Vector, t float64) float64 { return 1 / (x[0]*x[0] + x[1]*x[1]) }
+	s.SetHbc()
+	err := s.Assemble(false)
+	if err == nil {
+		tst.Errorf("Assemble should fail with infinite reaction coefficient\n")
+		return
+	}
+	io.Pforan("%v\n", err)
+	nf, ok := err.(*NonFiniteError)
+	if !ok {
+		tst.Errorf("error should be *NonFiniteError\n")
+		return
+	}
+	chk.Int(tst, "node", nf.Node, 12)
+	chk.Array(tst, "x", 1e-15, nf.X, []float64{0, 0})
+	chk.String(tst, nf.Coefficient, "reaction function kr = +Inf at node 12")
+
+	// coefficient field with a zero denominator at one node
+	kField := make([]float64, g.Size())
+	for I := range kField {
+		x := g.Node(I)
+		kField[I] = 1 / (x[0] - 0.5)
+	}
+	s.Reaction = nil
+	err = s.AssembleWithCoeffField(kField, false)
+	io.Pforan("%v\n", err)
+	if nf, ok = err.(*NonFiniteError); !ok {
+		tst.Errorf("error should be *NonFiniteError\n")
+		return
+	}
+	chk.String(tst, nf.Coefficient, "coefficient field k = +Inf at node 3")
+
+	// finite coefficients
+	for I := range kField {
+		kField[I] = 2
+	}
+	if err = s.AssembleWithCoeffField(kField, false); err != nil {
+		tst.Errorf("%v\n", err)
+	}
+}
+
+func TestFdm57(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm57. full system with identity rows for prescribed values")
+
+	// operator with essential, natural and Robin conditions and source
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{9, 6})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 0.5}, {N: "kxy", V: 0.2}}, g, func(x la.Vector, t float64) float64 { return 1 + x[0] })
+	s.AddEbc(10, 0, func(x la.Vector, t float64) float64 { return math.Sin(x[1]) })
+	s.AddEbc(20, 1, nil)
+	s.AddNbc(11, -0.5, nil)
+	s.RobinBcs.SetInGrid(21, 1, 2, 0.3)
+	A, b := s.AssembleFullWithDirichletRows()
+	Ad := A.ToDense()
+	chk.Int(tst, "nrow", Ad.M, g.Size())
+
+	// identity rows
+	for _, I := range s.EssenBcs.Nodes() {
+		for J := 0; J < g.Size(); J++ {
+			if J != I && Ad.Get(I, J) != 0 {
+				tst.Errorf("row %d should have zero off-diagonal entries\n", I)
+				return
+			}
+		}
+		chk.Float64(tst, io.Sf("A[%d][%d]", I, I), 1e-15, Ad.Get(I, I), 1)
+	}
+
+	// compare with partitioned solve
+	u := la.SpSolve(A, b)
+	s.Assemble(false)
+	uRef, _ := s.SolveSteady(false)
+	chk.Array(tst, "u", 1e-12, u, uRef)
+
+	// Robin conditions on all edges without prescribed values (no free slots from identity rows)
+	s = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 0.5}, {N: "kxy", V: 0.2}}, g, func(x la.Vector, t float64) float64 { return 1 + x[0] })
+	for _, tag := range []int{10, 11, 20, 21} {
+		s.RobinBcs.SetInGrid(tag, 1, 2, 0.3)
+	}
+	A, b = s.AssembleFullWithDirichletRows()
+	u = la.SpSolve(A, b)
+	s.Assemble(false)
+	uRef, _ = s.SolveSteady(false)
+	chk.Array(tst, "u (Robin)", 1e-12, u, uRef)
+}
+
+func TestFdm58(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm58. principal directions varying in space (circumferential fibers)")
+
+	// grid away from the origin with fibers along the circumferential direction
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{1, 1}, []float64{3, 3}, []int{21, 21})
+	theta := make([]float64, g.Size())
+	for I := range theta {
+		x := g.Node(I)
+		theta[I] = math.Atan2(x[1], x[0]) + math.Pi/2
+	}
+	k1, k2 := 5.0, 0.5
+	p := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}
+
+	// symmetry with essential conditions on all edges
+	s := NewFdmLaplacian(p, g, nil)
+	for _, tag := range []int{10, 11, 20, 21} {
+		s.AddEbc(tag, 0, nil)
+	}
+	err := s.AssembleWithPrincipalField(k1, k2, theta, false)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	a := s.Eqs.Auu.ToDense()
+	chk.Deep2(tst, "Auu == Auuᵀ", 1e-13, a.GetDeep2(), a.GetTranspose().GetDeep2())
+
+	// rotated tensor at a node on the diagonal (θ = 3π/4)
+	I := g.IndexMNPtoI(10, 10, 0)
+	chk.Float64(tst, "kxx", 1e-14, s.kTensor[0][I], (k1+k2)/2)
+	chk.Float64(tst, "kxy", 1e-14, s.kTensor[1][I], -(k1-k2)/2)
+	chk.Float64(tst, "kyy", 1e-14, s.kTensor[2][I], (k1+k2)/2)
+
+	// anisotropic diffusion: radial fields diffuse with k2 only; i.e. L{r²} = 4⋅k2 and
+	// circumferential fields are in the kernel; i.e. L{atan2(y,x)} = 0 (away from the boundaries)
+	s = NewFdmLaplacian(p, g, nil)
+	s.AssembleWithPrincipalField(k1, k2, theta, false)
+	rr, th := la.NewVector(s.Eqs.Nu), la.NewVector(s.Eqs.Nu)
+	for i, I := range s.Eqs.UtoF {
+		x := g.Node(I)
+		rr[i] = x[0]*x[0] + x[1]*x[1]
+		th[i] = math.Atan2(x[1], x[0])
+	}
+	lrr, lth := la.NewVector(s.Eqs.Nu), la.NewVector(s.Eqs.Nu)
+	s.Apply(lrr, rr)
+	s.Apply(lth, th)
+	errRR, errTH := 0.0, 0.0
+	for i, I := range s.Eqs.UtoF {
+		if m, n, _ := g.IndexItoMNP(I); m > 0 && m < 20 && n > 0 && n < 20 {
+			errRR = math.Max(errRR, math.Abs(lrr[i]-4*k2))
+			errTH = math.Max(errTH, math.Abs(lth[i]))
+		}
+	}
+	io.Pforan("max |L{r²} - 4⋅k2| = %v\n", errRR)
+	io.Pforan("max |L{θ}|        = %v\n", errTH)
+	chk.Float64(tst, "L{r²}", 2e-2, errRR, 0)
+	chk.Float64(tst, "L{θ}", 1e-2, errTH, 0)
+
+	// uniform angle recovers the constant (rotated) tensor
+	for I := range theta {
+		theta[I] = math.Pi / 6
+	}
+	s.AssembleWithPrincipalField(k1, k2, theta, false)
+	c, sn := math.Cos(math.Pi/6), math.Sin(math.Pi/6)
+	sc := NewFdmLaplacian(dbf.Params{{N: "kx", V: k1*c*c + k2*sn*sn}, {N: "ky", V: k1*sn*sn + k2*c*c}, {N: "kxy", V: (k1 - k2) * sn * c}}, g, nil)
+	sc.Assemble(false)
+	chk.Deep2(tst, "Auu", 1e-12, s.Eqs.Auu.ToDense().GetDeep2(), sc.Eqs.Auu.ToDense().GetDeep2())
+
+	// wrong size
+	defer chk.RecoverTstPanicIsOK(tst)
+	s.AssembleWithPrincipalField(k1, k2, []float64{0, 1}, false)
+}
+
+func TestFdm59(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm59. Robin and natural conditions only (no prescribed values)")
+
+	// u = 5 - x with qn = k ∂u/∂n = 1 at x = 0 and u + ∂u/∂n = 3 at x = 1 (impermeable y-edges)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{6, 5})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	s.AddNbc(10, 1, nil)
+	s.RobinBcs.SetInGrid(11, 1, 1, 3)
+	err := s.Assemble(false)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	chk.Int(tst, "Nk", s.Eqs.Nk, 0)
+	u, _ := s.SolveSteady(false)
+	for I := 0; I < g.Size(); I++ {
+		chk.Float64(tst, io.Sf("u @ %d", I), 1e-13, u[I], 5-g.Node(I)[0])
+	}
+
+	// cross term (9-point stencil)
+	s.Kxy = 0.2
+	err = s.Assemble(false)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	u, _ = s.SolveSteady(false)
+	for I := 0; I < g.Size(); I++ {
+		chk.Float64(tst, io.Sf("u(kxy) @ %d", I), 1e-12, u[I], 5-g.Node(I)[0])
+	}
+}
+
+func TestFdm60(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fdm60. stencils requiring uniform grids")
+
+	// stretched grid
+	g := new(gm.Grid)
+	g.RectSet2d([]float64{0, 0.1, 0.3, 0.6, 1}, []float64{0, 0.25, 0.5, 0.75, 1})
+	check := func(kind string, setup func(s *FdmLaplacian)) {
+		s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+		s.SetHbc()
+		defer func() {
+			if err := recover(); err == nil {
+				tst.Errorf("%s with stretched grid should panic\n", kind)
+			}
+		}()
+		setup(s)
+		s.Assemble(false)
+	}
+	check("Mehrstellen", func(s *FdmLaplacian) { s.Mehrstellen = true })
+	check("kxy", func(s *FdmLaplacian) { s.Kxy = 0.2 })
+	check("tensor field", func(s *FdmLaplacian) {
+		k := utl.Ones(g.Size())
+		s.AssembleWithTensorField(k, make([]float64, g.Size()), k, false)
+	})
+
+	// the 5-point stencil is available
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	s.SetHbc()
+	err := s.Assemble(false)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+	}
+}
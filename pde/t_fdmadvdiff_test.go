@@ -0,0 +1,293 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+)
+
+func TestFdmAdvDiff01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("FdmAdvDiff01. advection of step profile")
+
+	// oblique flow with inflow at the left and bottom edges: the step at (0, 0.3) is advected
+	// along the characteristic y = 0.3 + x⋅tan(θ)
+	θ := math.Pi / 6
+	vx, vy := math.Cos(θ), math.Sin(θ)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{41, 41})
+	exact := func(x la.Vector) float64 {
+		if x[1] > 0.3+x[0]*math.Tan(θ) {
+			return 1
+		}
+		return 0
+	}
+	errL1 := func(u []float64) (err float64) {
+		for I := 0; I < g.Size(); I++ {
+			err += math.Abs(u[I]-exact(g.Node(I))) / float64(g.Size())
+		}
+		return
+	}
+	overshoot := func(u []float64) float64 {
+		return math.Max(la.Vector(u).Max()-1, -la.Vector(u).Min())
+	}
+	solve := func(limiter string) (u []float64) {
+		params := dbf.Params{{N: "kx", V: 1e-3}, {N: "ky", V: 1e-3}, {N: "vx", V: vx}, {N: "vy", V: vy}}
+		op := NewFdmAdvDiff(params, g, limiter, nil)
+		op.AddEbc(10, 0, func(x la.Vector, t float64) float64 { return exact(x) })
+		op.AddEbc(20, 0, nil)
+		u, nit := op.Solve(nil, map[string]float64{"maxIt": 50, "linSearch": 1}, !chk.Verbose)
+		io.Pforan("%-9s nit = %2d, overshoot = %.4f, TV = %7.3f, L1 error = %.4f\n",
+			limiter, nit, overshoot(u), SolutionTotalVariation(g, u), errL1(u))
+		return
+	}
+
+	// reference schemes: central oscillates since the cell Péclet number is 25
+	uCen := solve("central")
+	uUpw := solve("upwind")
+	if overshoot(uCen) < 0.1 {
+		tst.Errorf("the central scheme should oscillate\n")
+		return
+	}
+
+	// TVD schemes: (almost) no new extrema and sharper than upwind. NOTE: the limiters are applied
+	// along each direction; thus, small overshoots remain with the compressive superbee limiter
+	for _, limiter := range []string{"minmod", "vanleer", "superbee"} {
+		u := solve(limiter)
+		if overshoot(u) > 0.01 || overshoot(u) > overshoot(uCen)/20 {
+			tst.Errorf("%s: overshoot should be much smaller than with central: %g (central: %g)\n", limiter, overshoot(u), overshoot(uCen))
+		}
+		if SolutionTotalVariation(g, u) > SolutionTotalVariation(g, uCen) {
+			tst.Errorf("%s: total variation should be smaller than with central\n", limiter)
+		}
+		if errL1(u) > 0.8*errL1(uUpw) {
+			tst.Errorf("%s: scheme should be sharper than upwind: L1 error = %g > 0.8⋅%g\n", limiter, errL1(u), errL1(uUpw))
+		}
+	}
+}
+
+func TestFdmAdvDiff02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("FdmAdvDiff02. Jacobian and limiters")
+
+	// operator with source and smooth solution
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{7, 6})
+	params := dbf.Params{{N: "kx", V: 0.01}, {N: "ky", V: 0.02}, {N: "vx", V: 1}, {N: "vy", V: -0.5}}
+	op := NewFdmAdvDiff(params, g, "vanleer", func(x la.Vector, t float64) float64 { return x[0] })
+	op.AddEbc(10, 0, func(x la.Vector, t float64) float64 { return math.Sin(3 * x[1]) })
+	op.AddEbc(21, 1, nil)
+	op.init()
+
+	// compare with numerical Jacobian at random state
+	uu := la.NewVector(op.Eqs.Nu)
+	for i := range uu {
+		uu[i] = math.Cos(float64(3*i)) + float64(i%3)
+	}
+	var J la.Triplet
+	op.Jacobian(&J, uu)
+	Jana := J.ToDense()
+	r := la.NewVector(op.Eqs.Nu)
+	for j := 0; j < op.Eqs.Nu; j++ {
+		for i := 0; i < op.Eqs.Nu; i++ {
+			dnum := num.DerivCen5(uu[j], 1e-3, func(x float64) float64 {
+				tmp := uu[j]
+				uu[j] = x
+				op.Residual(r, uu)
+				uu[j] = tmp
+				return r[i]
+			})
+			chk.AnaNum(tst, io.Sf("J%d%d", i, j), 1e-7, Jana.Get(i, j), dnum, false)
+		}
+	}
+
+	// limiters
+	ψ, dψ := limiterFunc("superbee")(0.25)
+	chk.Float64(tst, "superbee(0.25)", 1e-15, ψ, 0.5)
+	chk.Float64(tst, "dsuperbee(0.25)", 1e-15, dψ, 2)
+	ψ, _ = limiterFunc("vanleer")(1)
+	chk.Float64(tst, "vanleer(1)", 1e-15, ψ, 1)
+	ψ, _ = limiterFunc("minmod")(3)
+	chk.Float64(tst, "minmod(3)", 1e-15, ψ, 1)
+
+	// stretched grids are rejected
+	func() {
+		defer func() {
+			if err := recover(); err == nil {
+				tst.Errorf("stretched grid should panic\n")
+			}
+		}()
+		gs := new(gm.Grid)
+		gs.RectSet2d([]float64{0, 0.1, 0.3, 0.6, 1}, []float64{0, 0.25, 0.5, 0.75, 1})
+		NewFdmAdvDiff(params, gs, "upwind", nil)
+	}()
+	defer chk.RecoverTstPanicIsOK(tst)
+	NewFdmAdvDiff(params, g, "koren", nil)
+}
+
+func TestFdmAdvDiff03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("FdmAdvDiff03. compact fourth-order scheme")
+
+	// derivatives of cubic polynomials are exact
+	D := padeMatrix(7, 0.5)
+	f, df := la.NewVector(7), la.NewVector(7)
+	for i := 0; i < 7; i++ {
+		x := 0.5 * float64(i)
+		f[i] = 1 + x - 2*x*x + x*x*x
+	}
+	la.MatVecMul(df, 1, D, f)
+	for i := 0; i < 7; i++ {
+		x := 0.5 * float64(i)
+		chk.Float64(tst, io.Sf("f'(%g)", x), 1e-13, df[i], 1-4*x+3*x*x)
+	}
+
+	// pure advection of smooth profile with inflow at the left and bottom edges
+	vx, vy := 1.0, 0.5
+	exact := func(x la.Vector, t float64) float64 { return math.Sin(2*x[0]+x[1]) + math.Cos(x[0]*x[1]) }
+	source := func(x la.Vector, t float64) float64 { // s = -vx ∂u/∂x - vy ∂u/∂y
+		c := math.Cos(2*x[0] + x[1])
+		s := math.Sin(x[0] * x[1])
+		return -vx*(2*c-x[1]*s) - vy*(c-x[0]*s)
+	}
+	maxError := func(scheme string, n int) (err float64) {
+		g := new(gm.Grid)
+		g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{n, n})
+		op := NewFdmAdvDiff(dbf.Params{{N: "vx", V: vx}, {N: "vy", V: vy}}, g, scheme, source)
+		op.AddEbc(10, 0, exact)
+		op.AddEbc(20, 0, exact)
+		u, _ := op.Solve(nil, nil, true)
+		for I := 0; I < g.Size(); I++ {
+			err = math.Max(err, math.Abs(u[I]-exact(g.Node(I), 0)))
+		}
+		return
+	}
+
+	// convergence rate
+	errPrev := 0.0
+	for _, n := range []int{9, 17, 33} {
+		err := maxError("compact", n)
+		if errPrev > 0 {
+			rate := math.Log2(errPrev / err)
+			io.Pforan("n = %2d, error = %.3e, rate = %.2f\n", n, err, rate)
+			if rate < 3.7 {
+				tst.Errorf("rate of convergence should be about 4. %g is too low\n", rate)
+			}
+		}
+		errPrev = err
+	}
+
+	// much more accurate than the second-order central scheme
+	errCentral := maxError("central", 33)
+	io.Pforan("central: n = 33, error = %.3e\n", errCentral)
+	if errPrev > errCentral/1000 {
+		tst.Errorf("compact scheme should be much more accurate than central: %g > %g/1000\n", errPrev, errCentral)
+	}
+}
+
+func TestFdmAdvDiff04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("FdmAdvDiff04. streamline-upwind stabilisation (supg)")
+
+	// boundary layer at the outflow x = 1 with cell Péclet number v⋅h/k = 5:
+	//   u = (exp(x⋅v/k) - 1) / (exp(v/k) - 1)
+	k := 0.01
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 0.1}, []int{21, 3})
+	exact := func(x float64) float64 { return math.Expm1(x/k) / math.Expm1(1/k) }
+	solve := func(scheme string) (u []float64, errMax float64, monotone bool) {
+		op := NewFdmAdvDiff(dbf.Params{{N: "kx", V: k}, {N: "ky", V: k}, {N: "vx", V: 1}}, g, scheme, nil)
+		op.AddEbc(10, 0, nil)
+		op.AddEbc(11, 1, nil)
+		u, _ = op.Solve(nil, nil, true)
+		monotone = true
+		for I := 0; I < g.Size(); I++ {
+			x := g.Node(I)
+			errMax = math.Max(errMax, math.Abs(u[I]-exact(x[0])))
+			if m, _, _ := g.IndexItoMNP(I); m > 0 && u[I] < u[I-1] {
+				monotone = false
+			}
+		}
+		io.Pforan("%-7s error = %.3e, monotone = %v, u(0.9) = %8.5f (exact = %.5f)\n", scheme, errMax, monotone, u[18], exact(0.9))
+		return
+	}
+	_, errSupg, monoSupg := solve("supg")
+	_, errUpw, _ := solve("upwind")
+	_, _, monoCen := solve("central")
+	if monoCen {
+		tst.Errorf("the central scheme should oscillate\n")
+	}
+	if !monoSupg {
+		tst.Errorf("supg: solution should be monotone\n")
+	}
+	if errSupg > 1e-12 {
+		tst.Errorf("supg: nodal values should be exact: error = %g\n", errSupg)
+	}
+	if errUpw < 0.05 {
+		tst.Errorf("upwind should smear the boundary layer: error = %g\n", errUpw)
+	}
+
+	// oblique flow with uniform source and boundary layers at the outflow edges (u = 0 everywhere)
+	g2 := new(gm.Grid)
+	g2.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{21, 21})
+	θ := math.Pi / 6
+	params := dbf.Params{{N: "kx", V: 1e-3}, {N: "ky", V: 1e-3}, {N: "vx", V: math.Cos(θ)}, {N: "vy", V: math.Sin(θ)}}
+	undershoot := func(scheme string) float64 {
+		op := NewFdmAdvDiff(params, g2, scheme, func(x la.Vector, t float64) float64 { return -1 })
+		for _, tag := range []int{10, 11, 20, 21} {
+			op.AddEbc(tag, 0, nil)
+		}
+		u, _ := op.Solve(nil, nil, true)
+		io.Pforan("%-7s min(u) = %9.5f, max(u) = %.5f\n", scheme, la.Vector(u).Min(), la.Vector(u).Max())
+		return -la.Vector(u).Min()
+	}
+	uCen, uSupg := undershoot("central"), undershoot("supg")
+	if uCen < 0.05 {
+		tst.Errorf("the central scheme should oscillate: undershoot = %g\n", uCen)
+	}
+	if uSupg > 0.01 || uSupg > uCen/20 {
+		tst.Errorf("supg: undershoot should be much smaller than with central: %g (central: %g)\n", uSupg, uCen)
+	}
+
+	// Petrov-Galerkin correction of the source: r = L{0} - (s - τ vx ∂s/∂x) with s = 2x + y
+	pe := 0.05 / (2 * k)
+	τ := (1/math.Tanh(pe) - 1/pe) * 0.05 / 2
+	opS := NewFdmAdvDiff(dbf.Params{{N: "kx", V: k}, {N: "ky", V: k}, {N: "vx", V: 1}}, g, "supg", func(x la.Vector, t float64) float64 { return 2*x[0] + x[1] })
+	opS.AddEbc(10, 0, nil)
+	opS.init()
+	rS := la.NewVector(opS.Eqs.Nu)
+	opS.Residual(rS, la.NewVector(opS.Eqs.Nu))
+	for i, I := range opS.Eqs.UtoF {
+		x := g.Node(I)
+		chk.Float64(tst, io.Sf("r%d", i), 1e-14, rS[i], -(2*x[0] + x[1] - 2*τ))
+	}
+
+	// Jacobian with the cross derivative (the operator is linear)
+	op := NewFdmAdvDiff(dbf.Params{{N: "kx", V: 0.01}, {N: "ky", V: 0.02}, {N: "vx", V: 1}, {N: "vy", V: -0.5}}, g2, "supg", nil)
+	op.AddEbc(10, 0, nil)
+	op.init()
+	uu := la.NewVector(op.Eqs.Nu)
+	for i := range uu {
+		uu[i] = math.Cos(float64(3 * i))
+	}
+	var J la.Triplet
+	op.Jacobian(&J, uu)
+	r, Ju := la.NewVector(op.Eqs.Nu), la.NewVector(op.Eqs.Nu)
+	op.Residual(r, uu)
+	la.SpMatVecMul(Ju, 1, J.ToMatrix(nil), uu)
+	chk.Array(tst, "J⋅u = r", 1e-12, Ju, r)
+}
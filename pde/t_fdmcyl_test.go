@@ -0,0 +1,76 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func TestFdmCyl01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("FdmCyl01. radial conduction in hollow cylinder")
+
+	// u(a) = 1 and u(b) = 0 with insulated ends:  u = ln(b/r) / ln(b/a)
+	a, b := 0.5, 2.0
+	ana := func(r float64) float64 { return math.Log(b/r) / math.Log(b/a) }
+	solve := func(nr int) (err float64) {
+		g := new(gm.Grid)
+		g.RectGenUniform([]float64{a, 0}, []float64{b, 1}, []int{nr, 3})
+		op := NewFdmCylindrical(dbf.Params{{N: "kr", V: 1.5}, {N: "kz", V: 1}}, g, nil)
+		op.AddEbc(10, 1, nil)
+		op.AddEbc(11, 0, nil)
+		u := op.SolveSteady()
+		for I := 0; I < g.Size(); I++ {
+			err = math.Max(err, math.Abs(u[I]-ana(g.Node(I)[0])))
+		}
+		return
+	}
+	e1, e2 := solve(11), solve(21)
+	io.Pforan("errors = %g, %g (ratio = %g)\n", e1, e2, e1/e2)
+	if e2 > 1e-3 {
+		tst.Errorf("error is too large: %g\n", e2)
+	}
+	chk.Float64(tst, "ratio (second order)", 0.3, e1/e2, 4)
+}
+
+func TestFdmCyl02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("FdmCyl02. solid cylinder with axis singularity")
+
+	// u = r² + z² is reproduced exactly:  L{u} = 4⋅kr + 2⋅kz
+	kr, kz := 2.0, 0.5
+	ana := func(x la.Vector, t float64) float64 { return x[0]*x[0] + x[1]*x[1] }
+	g := new(gm.Grid)
+	g.RectSet2d([]float64{0, 0.1, 0.25, 0.5, 0.8, 1.0}, []float64{0, 0.3, 0.5, 1.0})
+	op := NewFdmCylindrical(dbf.Params{{N: "kr", V: kr}, {N: "kz", V: kz}}, g, func(x la.Vector, t float64) float64 {
+		return 4*kr + 2*kz
+	})
+	op.AddEbc(11, 0, ana)
+	op.AddEbc(21, 0, ana)
+	u := op.SolveSteady()
+	for I := 0; I < g.Size(); I++ {
+		chk.Float64(tst, io.Sf("u @ %d", I), 1e-13, u[I], ana(g.Node(I), 0))
+	}
+
+	// limiting stencil at the axis: 4⋅kr/h² on a uniform grid
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{5, 3})
+	op = NewFdmCylindrical(dbf.Params{{N: "kr", V: kr}, {N: "kz", V: 0}}, g, nil)
+	op.AddEbc(11, 0, nil)
+	op.Assemble()
+	A := op.Auu.ToDense()
+	h := 0.25
+	chk.Float64(tst, "A[0,0]", 1e-12, A.Get(0, 0), -4*kr/(h*h))
+	chk.Float64(tst, "A[0,1]", 1e-12, A.Get(0, 1), 4*kr/(h*h))
+	chk.Float64(tst, "A[1,0]", 1e-12, A.Get(1, 0), kr*0.5/(h*h)) // r[½]/(r[1]⋅h²)
+}
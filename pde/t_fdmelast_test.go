@@ -0,0 +1,141 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func TestFdmElast01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("FdmElast01. uniaxial tension")
+
+	// solve problem with σx = σ and σy = τxy = 0, thus (plane-stress):
+	//    ux = σ⋅x / E   and   uy = -ν⋅σ⋅y / E
+	// and (plane-strain):
+	//    ux = (1-ν²)⋅σ⋅x / E   and   uy = -ν⋅(1+ν)⋅σ⋅y / E
+
+	// 5x4 grid
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{5, 4})
+
+	// parameters
+	E, ν, σ := 1000.0, 0.25, 10.0
+	p := dbf.Params{{N: "E", V: E}, {N: "nu", V: ν}}
+
+	for _, planeStress := range []bool{true, false} {
+
+		// analytical solution (linear field)
+		cx, cy := σ/E, -ν*σ/E
+		if !planeStress {
+			cx, cy = (1.0-ν*ν)*σ/E, -ν*(1.0+ν)*σ/E
+		}
+		ana := func(x la.Vector, dof int) float64 {
+			if dof == 0 {
+				return cx * x[0]
+			}
+			return cy * x[1]
+		}
+
+		// operator
+		s := NewFdmElasticity(p, g, planeStress, nil)
+		for _, tag := range []int{10, 11, 20, 21} {
+			s.AddEbc(tag, 0, 0, func(x la.Vector, t float64) float64 { return ana(x, 0) })
+			s.AddEbc(tag, 1, 0, func(x la.Vector, t float64) float64 { return ana(x, 1) })
+		}
+
+		// assemble and check symmetry of the coupled operator
+		s.Assemble(false)
+		chk.Int(tst, "Nu", s.Eqs.Nu, 2*3*2)
+		Duu := s.Eqs.Auu.ToDense()
+		chk.Deep2(tst, "Auu == Auuᵀ", 1e-12, Duu.GetDeep2(), Duu.GetTranspose().GetDeep2())
+
+		// check off-diagonal coupling: node 6 @ (1,1); ux-row with uy-column of node 12 @ (2,2)
+		λ, μ := s.Lame()
+		dx, dy := 0.5, 1.0/3.0
+		i, j := s.Eqs.FtoU[2*6+0], s.Eqs.FtoU[2*12+1]
+		chk.Float64(tst, "cxy", 1e-12, Duu.Get(i, j), (λ+μ)/(4.0*dx*dy))
+
+		// solve
+		u, _ := s.SolveSteady(false)
+		io.Pforan("u = %v\n", u)
+		for I := 0; I < g.Size(); I++ {
+			x := g.Node(I)
+			chk.AnaNum(tst, io.Sf("ux @ %d", I), 1e-14, u[2*I+0], ana(x, 0), chk.Verbose)
+			chk.AnaNum(tst, io.Sf("uy @ %d", I), 1e-14, u[2*I+1], ana(x, 1), chk.Verbose)
+		}
+	}
+}
+
+func TestFdmElast02(tst *testing.T) {
+	//verbose()
+	chk.PrintTitle("FdmElast02. panic on params")
+	defer chk.RecoverTstPanicIsOK(tst)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{3, 3})
+	NewFdmElasticity(dbf.Params{{N: "E", V: 1}}, g, true, nil)
+}
+
+func TestFdmElast03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("FdmElast03. laterally constrained layer (plane-stress versus plane-strain)")
+
+	// layer with ux = 0 everywhere and uy = 0 at the bottom and top loaded by the body force γ
+	// (downwards); i.e. (λ+2μ)⋅uy'' = γ, thus
+	//    uy = γ⋅y⋅(y - 1) / (2⋅M)   and   σx = K0⋅σy
+	// with the constrained modulus M and the coefficient K0 (lateral to vertical stress):
+	//    plane-stress: M = E / (1-ν²)              and   K0 = ν
+	//    plane-strain: M = E⋅(1-ν) / ((1+ν)⋅(1-2ν))  and   K0 = ν / (1-ν)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{5, 5})
+	E, ν, γ := 1000.0, 0.25, 20.0
+	p := dbf.Params{{N: "E", V: E}, {N: "nu", V: ν}}
+	var uyMid [2]float64
+	for k, planeStress := range []bool{true, false} {
+		M, K0 := E/(1-ν*ν), ν
+		if !planeStress {
+			M, K0 = E*(1-ν)/((1+ν)*(1-2*ν)), ν/(1-ν)
+		}
+
+		// operator
+		s := NewFdmElasticity(p, g, planeStress, []fun.Svs{nil, func(x la.Vector, t float64) float64 { return γ }})
+		for _, tag := range []int{10, 11, 20, 21} {
+			s.AddEbc(tag, 0, 0, nil)
+		}
+		s.AddEbc(20, 1, 0, nil)
+		s.AddEbc(21, 1, 0, nil)
+		s.Assemble(false)
+		chk.Int(tst, "Nu", s.Eqs.Nu, 3*3+5*3) // interior ux and uy of the 3 inner rows
+
+		// solve (the discrete solution is exact since uy is quadratic)
+		u, _ := s.SolveSteady(false)
+		for I := 0; I < g.Size(); I++ {
+			x := g.Node(I)
+			chk.AnaNum(tst, io.Sf("ux @ %d", I), 1e-15, u[2*I+0], 0, chk.Verbose)
+			chk.AnaNum(tst, io.Sf("uy @ %d", I), 1e-15, u[2*I+1], γ*x[1]*(x[1]-1)/(2*M), chk.Verbose)
+		}
+
+		// stresses at (1,¼): εx = 0 and εy = ∂uy/∂y (central differences)
+		λ, μ := s.Lame()
+		I, dy := g.IndexMNPtoI(2, 1, 0), 0.25
+		εy := (u[2*g.IndexMNPtoI(2, 2, 0)+1] - u[2*g.IndexMNPtoI(2, 0, 0)+1]) / (2 * dy)
+		σx, σy := λ*εy, (λ+2*μ)*εy
+		io.Pforan("planeStress = %v: uy(1,¼) = %g, σx/σy = %g\n", planeStress, u[2*I+1], σx/σy)
+		chk.Float64(tst, "σx/σy", 1e-15, σx/σy, K0)
+		uyMid[k] = u[2*I+1]
+	}
+
+	// the plane-strain condition stiffens the layer
+	chk.Float64(tst, "uy(plane-strain)/uy(plane-stress)", 1e-14, uyMid[1]/uyMid[0], (1-2*ν)/((1-ν)*(1-ν)))
+}
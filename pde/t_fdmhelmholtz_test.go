@@ -0,0 +1,65 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+)
+
+func TestFdmHelmholtz01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("FdmHelmholtz01. plane wave absorbed by PML")
+
+	// plane wave generated at x = 0 (u = 1) travelling to the right: the physical domain is
+	// [0, 1] and the layer is [1, 1.5] with u = 0 at the outer boundary; the top and bottom
+	// edges are mirrored; thus, the problem is one-dimensional
+	k := 2 * math.Pi * 5 // wavelength = 0.2
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1.5, 0.02}, []int{151, 3})
+	h := 0.01
+	kh := math.Acos(1-k*k*h*h/2) / h // discrete wavenumber
+
+	// reflection coefficient from u = A⋅exp(i kh x) + B⋅exp(-i kh x) in the physical domain
+	reflection := func(pml bool) (R float64) {
+		op := NewFdmHelmholtz(dbf.Params{{N: "k", V: k}}, g, nil)
+		op.AddEbc(10, 1, nil)
+		op.AddEbc(11, 0, nil)
+		if pml {
+			L := 0.5
+			op.SetPml(11, L, 3*math.Log(1e6)/(2*L))
+		}
+		u := op.Solve()
+		x1, x2 := g.Node(20)[0], g.Node(35)[0]
+		e := func(x float64) complex128 { return cmplx.Exp(complex(0, kh*x)) }
+		det := e(x1)/e(x2) - e(x2)/e(x1)
+		A := (u[20]/e(x2) - u[35]/e(x1)) / det
+		B := (e(x1)*u[35] - e(x2)*u[20]) / det
+		for _, I := range []int{50, 70, 100} { // check fit
+			x := g.Node(I)[0]
+			chk.Complex128(tst, io.Sf("u%d", I), 1e-9, u[I], A*e(x)+B/e(x))
+		}
+		return cmplx.Abs(B) / cmplx.Abs(A)
+	}
+
+	// rigid wall: total reflection
+	R := reflection(false)
+	io.Pforan("without PML: R = %g\n", R)
+	chk.Float64(tst, "R (wall)", 1e-8, R, 1)
+
+	// absorbing layer
+	R = reflection(true)
+	io.Pforan("with PML:    R = %g\n", R)
+	if R > 1e-4 {
+		tst.Errorf("reflection coefficient with PML should be small. R = %g > 1e-4\n", R)
+	}
+}
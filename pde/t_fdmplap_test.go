@@ -0,0 +1,121 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+)
+
+func TestFdmPLap01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("FdmPLap01. p = 2 recovers the Laplacian")
+
+	// grid and source
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{9, 9})
+	source := func(x la.Vector, t float64) float64 {
+		return -10 * math.Sin(math.Pi*x[0]) * x[1]
+	}
+	ebc := func(x la.Vector, t float64) float64 {
+		return x[0] + 2*x[1]
+	}
+
+	// linear operator
+	lap := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, source)
+	for _, tag := range []int{10, 11, 20, 21} {
+		lap.AddEbc(tag, 0, ebc)
+	}
+	lap.Assemble(false)
+	uLin, _ := lap.SolveSteady(false)
+
+	// p-Laplacian with p = 2: one Newton iteration (linear problem)
+	op := NewFdmPLaplacian(dbf.Params{{N: "p", V: 2}}, g, source)
+	for _, tag := range []int{10, 11, 20, 21} {
+		op.AddEbc(tag, 0, ebc)
+	}
+	u, nit := op.Solve(nil, nil, !chk.Verbose)
+	io.Pforan("nit = %v\n", nit)
+	chk.Array(tst, "u", 1e-12, u, uLin)
+}
+
+func TestFdmPLap02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("FdmPLap02. p = 3 with manufactured solution")
+
+	// u = x² + y²  ⇒  ∇u = 2{x,y}  ⇒  ∇⋅(|∇u|∇u) = 12 r   with   r = √(x²+y²)
+	uExact := func(x la.Vector, t float64) float64 {
+		return x[0]*x[0] + x[1]*x[1]
+	}
+	source := func(x la.Vector, t float64) float64 {
+		return 12 * math.Sqrt(x[0]*x[0]+x[1]*x[1])
+	}
+
+	// convergence
+	var errs []float64
+	for _, npts := range []int{9, 17} {
+		g := new(gm.Grid)
+		g.RectGenUniform([]float64{1, 1}, []float64{2, 2}, []int{npts, npts})
+		op := NewFdmPLaplacian(dbf.Params{{N: "p", V: 3}}, g, source)
+		for _, tag := range []int{10, 11, 20, 21} {
+			op.AddEbc(tag, 0, uExact)
+		}
+
+		// check Jacobian at initial state
+		uu := la.NewVector(op.Grid.Size() - len(op.EssenBcs.Nodes()))
+		for i := range uu {
+			uu[i] = 2 + 0.1*math.Sin(float64(i))
+		}
+		var J, Jnum la.Triplet
+		op.Jacobian(&J, uu)
+		r := la.NewVector(len(uu))
+		op.Residual(r, uu)
+		num.Jacobian(&Jnum, op.Residual, uu, r, la.NewVector(len(uu)))
+		Ja, Jn := J.ToDense(), Jnum.ToDense()
+		Jmax, diff := Ja.Largest(1), 0.0
+		for k := range Ja.Data {
+			diff = math.Max(diff, math.Abs(Ja.Data[k]-Jn.Data[k]))
+		}
+		io.Pforan("max |J - Jnum| / max |J| = %v\n", diff/Jmax)
+		if diff/Jmax > 1e-6 {
+			tst.Errorf("analytical and numerical Jacobians differ. relative difference = %g\n", diff/Jmax)
+		}
+
+		// solve
+		u, nit := op.Solve(nil, nil, !chk.Verbose)
+		maxErr := 0.0
+		for I := 0; I < g.Size(); I++ {
+			maxErr = math.Max(maxErr, math.Abs(u[I]-uExact(g.Node(I), 0)))
+		}
+		io.Pforan("npts = %d  nit = %d  error = %v\n", npts, nit, maxErr)
+		errs = append(errs, maxErr)
+	}
+	if errs[0] > 1e-4 {
+		tst.Errorf("error is too large: %g\n", errs[0])
+	}
+	chk.Float64(tst, "error ratio (second order)", 0.4, errs[0]/errs[1], 4)
+}
+
+func TestFdmPLap03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("FdmPLap03. missing boundary conditions")
+
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{5, 5})
+	op := NewFdmPLaplacian(dbf.Params{{N: "p", V: 3}}, g, nil)
+	op.AddEbc(10, 0, nil)
+	defer chk.RecoverTstPanicIsOK(tst)
+	op.Solve(nil, nil, true)
+}
@@ -0,0 +1,95 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func TestFdmSph01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("FdmSph01. radial diffusion in hollow sphere")
+
+	// u(a) = 1 and u(b) = 0:  u = (1/r - 1/b) / (1/a - 1/b)
+	a, b := 0.5, 2.0
+	ana := func(r float64) float64 { return (1/r - 1/b) / (1/a - 1/b) }
+	solve := func(nr int) (err float64) {
+		g := new(gm.Grid)
+		g.RectGenUniform([]float64{a, 0}, []float64{b, math.Pi}, []int{nr, 5})
+		op := NewFdmSpherical(dbf.Params{{N: "kr", V: 1}, {N: "kt", V: 1}}, g, nil)
+		op.AddEbc(10, 1, nil)
+		op.AddEbc(11, 0, nil)
+		u := op.SolveSteady()
+		for I := 0; I < g.Size(); I++ {
+			err = math.Max(err, math.Abs(u[I]-ana(g.Node(I)[0])))
+		}
+		return
+	}
+	e1, e2 := solve(11), solve(21)
+	io.Pforan("errors = %g, %g (ratio = %g)\n", e1, e2, e1/e2)
+	if e2 > 2e-3 {
+		tst.Errorf("error is too large: %g\n", e2)
+	}
+	chk.Float64(tst, "ratio (second order)", 0.3, e1/e2, 4)
+}
+
+func TestFdmSph02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("FdmSph02. solid sphere with origin")
+
+	// u = r² is reproduced exactly:  L{u} = 6⋅kr
+	kr := 1.5
+	ana := func(x la.Vector, t float64) float64 { return x[0] * x[0] }
+	g := new(gm.Grid)
+	g.RectSet2d([]float64{0, 0.1, 0.25, 0.5, 0.8, 1.0}, []float64{0, 1, 2, math.Pi})
+	op := NewFdmSpherical(dbf.Params{{N: "kr", V: kr}, {N: "kt", V: 0.7}}, g, func(x la.Vector, t float64) float64 {
+		return 6 * kr
+	})
+	op.AddEbc(11, 0, ana)
+	u := op.SolveSteady()
+	for I := 0; I < g.Size(); I++ {
+		chk.Float64(tst, io.Sf("u @ %d", I), 1e-13, u[I], ana(g.Node(I), 0))
+	}
+
+	// limiting stencil at the origin: 6⋅kr/h² on a uniform grid (no polar terms)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, math.Pi}, []int{5, 3})
+	op = NewFdmSpherical(dbf.Params{{N: "kr", V: kr}, {N: "kt", V: 0}}, g, nil)
+	op.AddEbc(11, 0, nil)
+	op.Assemble()
+	A := op.Auu.ToDense()
+	h := 0.25
+	chk.Float64(tst, "A[0,0]", 1e-12, A.Get(0, 0), -6*kr/(h*h))
+	chk.Float64(tst, "A[0,1]", 1e-12, A.Get(0, 1), 6*kr/(h*h))
+
+	// harmonic function u = r⋅cos θ (i.e. z)
+	nr, nt := 21, 31
+	g.RectGenUniform([]float64{0, 0}, []float64{1, math.Pi}, []int{nr, nt})
+	z := func(x la.Vector, t float64) float64 { return x[0] * math.Cos(x[1]) }
+	op = NewFdmSpherical(dbf.Params{{N: "kr", V: 1}, {N: "kt", V: 1}}, g, nil)
+	op.AddEbc(11, 0, z)
+	u = op.SolveSteady()
+	err, errOrigin := 0.0, 0.0
+	for I := 0; I < g.Size(); I++ {
+		e := math.Abs(u[I] - z(g.Node(I), 0))
+		if m, _, _ := g.IndexItoMNP(I); m == 0 {
+			errOrigin = math.Max(errOrigin, e)
+			continue
+		}
+		err = math.Max(err, e)
+	}
+	io.Pforan("error (u = z) = %g (origin: %g)\n", err, errOrigin)
+	if err > 1e-3 || errOrigin > 2e-2 {
+		tst.Errorf("error is too large: %g (origin: %g)\n", err, errOrigin)
+	}
+}
@@ -0,0 +1,451 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func TestFields01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fields01. divergence with constant value")
+
+	// grid
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{-1, 0}, []float64{2, 1}, []int{7, 5})
+
+	// vector field: f = {2x + y², x² - y} ⇒ div f = 2 - 1 = 1
+	fx := make([]float64, g.Size())
+	fy := make([]float64, g.Size())
+	for I := 0; I < g.Size(); I++ {
+		x := g.Node(I)
+		fx[I] = 2.0*x[0] + x[1]*x[1]
+		fy[I] = x[0]*x[0] - x[1]
+	}
+
+	// check interior nodes
+	div := Divergence(g, fx, fy)
+	io.Pforan("div = %v\n", div)
+	for I := 0; I < g.Size(); I++ {
+		m, n, _ := g.IndexItoMNP(I)
+		if m > 0 && m < g.Npts(0)-1 && n > 0 && n < g.Npts(1)-1 {
+			chk.Float64(tst, io.Sf("div @ %d", I), 1e-14, div[I], 1)
+		}
+	}
+
+	// one-sided differences are also exact for quadratic fields
+	correct := make([]float64, g.Size())
+	for I := 0; I < g.Size(); I++ {
+		correct[I] = 1
+	}
+	chk.Array(tst, "div", 1e-13, div, correct)
+}
+
+func TestFields02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fields02. divergence with two nodes along x")
+
+	// grid
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{2, 3})
+
+	// vector field: f = {3x, -y} ⇒ div f = 2
+	fx := make([]float64, g.Size())
+	fy := make([]float64, g.Size())
+	for I := 0; I < g.Size(); I++ {
+		x := g.Node(I)
+		fx[I] = 3.0 * x[0]
+		fy[I] = -x[1]
+	}
+	div := Divergence(g, fx, fy)
+	chk.Array(tst, "div", 1e-14, div, []float64{2, 2, 2, 2, 2, 2})
+}
+
+func TestFields03(tst *testing.T) {
+	//verbose()
+	chk.PrintTitle("Fields03. divergence: panic on wrong size")
+	defer chk.RecoverTstPanicIsOK(tst)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{3, 3})
+	Divergence(g, make([]float64, 9), make([]float64, 8))
+}
+
+func TestFields04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fields04. Laplace eigenmodes")
+
+	// grid and operator
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{-1, 0}, []float64{1, 3}, []int{21, 31})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	s.SetHbc()
+	s.Assemble(false)
+	auu := s.Eqs.Auu.ToMatrix(nil)
+
+	// check modes
+	for _, mn := range [][]int{{1, 1}, {2, 1}, {3, 5}} {
+		m, n := mn[0], mn[1]
+		lambda, phi := LaplaceEigenmode(g, m, n)
+
+		// A⋅φ = λ⋅φ at interior nodes
+		phiU := la.NewVector(s.Eqs.Nu)
+		phiK := la.NewVector(s.Eqs.Nk)
+		s.Eqs.SplitVector(phiU, phiK, phi)
+		chk.Float64(tst, "φ at boundaries", 1e-15, phiK.Norm(), 0)
+		res := la.NewVector(s.Eqs.Nu)
+		la.SpMatVecMul(res, 1, auu, phiU)
+		la.Axpy(-lambda, phiU, res)
+		io.Pforan("(%d,%d): λ = %g, ‖A⋅φ - λ⋅φ‖ = %g\n", m, n, lambda, res.Norm())
+		chk.Float64(tst, "‖A⋅φ - λ⋅φ‖", 1e-11, res.Norm(), 0)
+
+		// discrete eigenvalue approximates the continuous one to O(h²)
+		kx := float64(m) * math.Pi / 2.0
+		ky := float64(n) * math.Pi / 3.0
+		h2 := 0.1 * 0.1
+		chk.Float64(tst, "λ", h2*math.Pow(kx*kx+ky*ky, 2)/12.0, lambda, -kx*kx-ky*ky)
+	}
+
+	// stretched grids are rejected
+	func() {
+		defer func() {
+			if err := recover(); err == nil {
+				tst.Errorf("stretched grid should panic\n")
+			}
+		}()
+		gs := new(gm.Grid)
+		gs.RectSet2d([]float64{0, 0.1, 0.3, 0.6, 1}, []float64{0, 0.25, 0.5, 0.75, 1})
+		LaplaceEigenmode(gs, 1, 1)
+	}()
+
+	// invalid wave number (vanishing mode)
+	defer chk.RecoverTstPanicIsOK(tst)
+	LaplaceEigenmode(g, 20, 1)
+}
+
+func TestFields05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fields05. grid quadrature")
+
+	// 2D grid
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{-1, 2}, []float64{3, 3}, []int{5, 3})
+	w := GridQuadrature(g)
+	io.Pforan("w = %v\n", w)
+	chk.Array(tst, "w", 1e-15, w, []float64{
+		0.125, 0.25, 0.25, 0.25, 0.125,
+		0.250, 0.50, 0.50, 0.50, 0.250,
+		0.125, 0.25, 0.25, 0.25, 0.125,
+	})
+
+	// integrate constant and linear functions: area = 4, centroid = (1, 2.5)
+	integrate := func(f func(x la.Vector) float64) (res float64) {
+		for I := 0; I < g.Size(); I++ {
+			res += w[I] * f(g.Node(I))
+		}
+		return
+	}
+	chk.Float64(tst, "∫ 3 dΩ", 1e-14, integrate(func(x la.Vector) float64 { return 3 }), 12)
+	chk.Float64(tst, "∫ (1 + 2x - y) dΩ", 1e-14, integrate(func(x la.Vector) float64 { return 1 + 2*x[0] - x[1] }), 4*(1+2*1-2.5))
+	chk.Float64(tst, "∫ x⋅y dΩ", 1e-14, integrate(func(x la.Vector) float64 { return x[0] * x[1] }), 4*1*2.5)
+
+	// 3D grid: volume = 2⋅3⋅4
+	g3 := new(gm.Grid)
+	g3.RectGenUniform([]float64{0, 0, 0}, []float64{2, 3, 4}, []int{3, 4, 5})
+	w3 := GridQuadrature(g3)
+	sum := 0.0
+	for _, v := range w3 {
+		sum += v
+	}
+	chk.Float64(tst, "volume", 1e-14, sum, 24)
+}
+
+func TestFields06(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fields06. compare solutions")
+
+	// grid and solution
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{5, 3})
+	a := make([]float64, g.Size())
+	for I := 0; I < g.Size(); I++ {
+		x := g.Node(I)
+		a[I] = x[0]*x[0] + x[1]
+	}
+
+	// perturbed solution
+	b := make([]float64, g.Size())
+	copy(b, a)
+	b[3] += 1e-6
+	b[7] -= 3e-6
+	b[12] += 2e-6
+
+	// check
+	maxDiff, node, x := CompareSolutions(a, b, g)
+	io.Pforan("maxDiff = %g @ node %d, x = %v\n", maxDiff, node, x)
+	chk.Float64(tst, "maxDiff", 1e-15, maxDiff, 3e-6)
+	chk.Int(tst, "node", node, 7)
+	chk.Array(tst, "x", 1e-15, x, []float64{1, 0.5})
+
+	// equal solutions
+	maxDiff, node, _ = CompareSolutions(a, a, g)
+	chk.Float64(tst, "maxDiff (equal)", 1e-15, maxDiff, 0)
+	chk.Int(tst, "node (equal)", node, 0)
+
+	// NaN and Inf values are reported at the first node
+	c := make([]float64, g.Size())
+	copy(c, a)
+	c[9], c[4] = math.NaN(), math.Inf(1)
+	maxDiff, node, _ = CompareSolutions(a, c, g)
+	if !math.IsNaN(maxDiff) {
+		tst.Errorf("maxDiff should be NaN: %g\n", maxDiff)
+	}
+	chk.Int(tst, "node (Inf)", node, 4)
+	maxDiff, node, _ = CompareSolutions(c, c, g)
+	if !math.IsNaN(maxDiff) {
+		tst.Errorf("maxDiff should be NaN with equal fields: %g\n", maxDiff)
+	}
+	chk.Int(tst, "node (Inf, equal)", node, 4)
+}
+
+func TestFields07(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fields07. total variation and local extrema")
+
+	// steady advection-diffusion a⋅u' = u'' with u(0) = 0 and u(1) = 1 and cell Péclet number
+	// a⋅h = 10: the central scheme oscillates, whereas the upwind scheme is monotone
+	nx, pe := 21, 10.0
+	solve := func(upwind bool) (u []float64) {
+		n := nx - 2 // interior nodes
+		A := la.NewMatrix(n, n)
+		b := la.NewVector(n)
+		cw, cc, ce := -1-pe/2, 2.0, -1+pe/2 // central
+		if upwind {
+			cw, cc, ce = -1-pe, 2+pe, -1.0
+		}
+		for i := 0; i < n; i++ {
+			A.Set(i, i, cc)
+			if i > 0 {
+				A.Set(i, i-1, cw)
+			}
+			if i < n-1 {
+				A.Set(i, i+1, ce)
+			}
+		}
+		b[n-1] = -ce // u(1) = 1
+		x := la.NewVector(n)
+		la.DenSolve(x, A, b, false)
+		u = append(append([]float64{0}, x...), 1)
+		return
+	}
+
+	// 2D grid with u = u(x)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 0.1}, []int{nx, 3})
+	toGrid := func(u1d []float64) (u []float64) {
+		u = make([]float64, g.Size())
+		for I := 0; I < g.Size(); I++ {
+			m, _, _ := g.IndexItoMNP(I)
+			u[I] = u1d[m]
+		}
+		return
+	}
+	uUpw := toGrid(solve(true))
+	uCen := toGrid(solve(false))
+
+	// check
+	tvUpw, tvCen := SolutionTotalVariation(g, uUpw), SolutionTotalVariation(g, uCen)
+	neUpw, neCen := LocalExtremaCount(g, uUpw), LocalExtremaCount(g, uCen)
+	io.Pforan("upwind:  TV = %v  extrema = %d\n", tvUpw, neUpw)
+	io.Pforan("central: TV = %v  extrema = %d\n", tvCen, neCen)
+	chk.Float64(tst, "TV (upwind; monotone)", 1e-14, tvUpw, 3) // 3 rows with |u(1) - u(0)| = 1
+	chk.Int(tst, "extrema (upwind)", neUpw, 0)
+	if tvCen <= tvUpw {
+		tst.Errorf("the oscillatory solution should have larger total variation. %g <= %g\n", tvCen, tvUpw)
+	}
+	if neCen == 0 {
+		tst.Errorf("the oscillatory solution should have local extrema\n")
+	}
+}
+
+func TestFields08(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fields08. solution statistics on stretched grid")
+
+	// u = 1 + 2⋅x is reproduced exactly by FDM
+	X := []float64{0, 0.05, 0.15, 0.3, 0.5, 1.0}
+	Y := []float64{0, 0.4, 0.6, 1.0}
+	g := new(gm.Grid)
+	g.RectSet2d(X, Y)
+	op := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	defer op.Free()
+	op.AddEbc(10, 1, nil)
+	op.AddEbc(11, 3, nil)
+	op.Assemble(false)
+	u, _ := op.SolveSteady(false)
+
+	// statistics
+	stats := op.Stats(u)
+	io.Pforan("stats = %+v\n", *stats)
+	chk.Float64(tst, "min", 1e-14, stats.Min, 1)
+	chk.Float64(tst, "max", 1e-14, stats.Max, 3)
+	chk.Int(tst, "node(min)", stats.NodeMin, 0)
+	chk.Int(tst, "node(max)", stats.NodeMax, len(X)-1)
+	chk.Array(tst, "x(min)", 1e-15, stats.Xmin, []float64{0, 0})
+	chk.Array(tst, "x(max)", 1e-15, stats.Xmax, []float64{1, 0})
+	chk.Float64(tst, "mean", 1e-14, stats.Mean, 2) // the trapezoidal rule is exact for linear fields
+
+	// RMS: trapezoidal rule along x
+	sum := 0.0
+	for i := 1; i < len(X); i++ {
+		ua, ub := 1+2*X[i-1], 1+2*X[i]
+		sum += (X[i] - X[i-1]) * (ua*ua + ub*ub) / 2
+	}
+	chk.Float64(tst, "rms", 1e-14, stats.Rms, math.Sqrt(sum))
+}
+
+func TestFields09(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fields09. cell Peclet and CFL numbers")
+
+	// uniform grid with constant velocity
+	nx, ny := 6, 4
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2.5, 1.5}, []int{nx, ny})
+	h := 0.5
+	v := []float64{3, 4} // |v| = 5
+	vx, vy := make([]float64, g.Size()), make([]float64, g.Size())
+	for I := range vx {
+		vx[I], vy[I] = v[0], v[1]
+	}
+	k := 0.2
+	pe, peMax := CellPeclet(g, vx, vy, k, k)
+	io.Pforan("Pe = %v\n", pe)
+	chk.Int(tst, "ncells", len(pe), (nx-1)*(ny-1))
+	for _, val := range pe {
+		chk.Float64(tst, "Pe = |v|⋅h/k", 1e-14, val, 5*h/k)
+	}
+	chk.Float64(tst, "max(Pe)", 1e-14, peMax, 5*h/k)
+
+	// CFL
+	dt := 0.05
+	cfl, cflMax := CellCFL(g, vx, vy, dt)
+	for _, val := range cfl {
+		chk.Float64(tst, "C", 1e-14, val, (3+4)*dt/h)
+	}
+	chk.Float64(tst, "max(C)", 1e-14, cflMax, 0.7)
+
+	// stretched grid and variable velocity along x: vx = x, vy = 0
+	X := []float64{0, 0.1, 0.3, 1.0}
+	g.RectSet2d(X, []float64{0, 1})
+	vx, vy = make([]float64, g.Size()), make([]float64, g.Size())
+	for I := range vx {
+		vx[I] = g.Node(I)[0]
+	}
+	pe, peMax = CellPeclet(g, vx, vy, 0.5, 0)
+	for m := 0; m < len(X)-1; m++ {
+		hx := X[m+1] - X[m]
+		vc := (X[m] + X[m+1]) / 2
+		chk.Float64(tst, io.Sf("Pe[%d]", m), 1e-15, pe[m], vc*hx/0.5)
+	}
+	chk.Float64(tst, "max(Pe) (stretched)", 1e-15, peMax, 0.65*0.7/0.5)
+	cfl, _ = CellCFL(g, vx, vy, 0.1)
+	chk.Float64(tst, "C[2]", 1e-15, cfl[2], 0.65*0.1/0.7)
+
+	// pure advection
+	_, peMax = CellPeclet(g, vx, vy, 0, 1)
+	if !math.IsInf(peMax, 1) {
+		tst.Errorf("Peclet number without diffusion should be +Inf. %g is incorrect\n", peMax)
+	}
+}
+
+func TestFields10(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fields10. quadrature and lumped mass with anisotropic cells")
+
+	// uniform grid with hx = 2⋅hy: area = 6⋅1.5
+	hx, hy := 1.5, 0.75
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{-2, 1}, []float64{4, 2.5}, []int{5, 3})
+	area := 6 * 1.5
+	w := GridQuadrature(g)
+	io.Pforan("w = %v\n", w)
+	chk.Float64(tst, "w(corner)", 1e-15, w[0], hx*hy/4)
+	chk.Float64(tst, "w(edge x)", 1e-15, w[1], hx*hy/2)
+	chk.Float64(tst, "w(edge y)", 1e-15, w[5], hx*hy/2)
+	chk.Float64(tst, "w(interior)", 1e-15, w[6], hx*hy)
+	integrate := func(w []float64, f func(x la.Vector) float64) (res float64) {
+		for I := 0; I < g.Size(); I++ {
+			res += w[I] * f(g.Node(I))
+		}
+		return
+	}
+	chk.Float64(tst, "∫ 2 dΩ", 1e-14, integrate(w, func(x la.Vector) float64 { return 2 }), 2*area)
+	chk.Float64(tst, "∫ x⋅y dΩ", 1e-14, integrate(w, func(x la.Vector) float64 { return x[0] * x[1] }), area*1*1.75)
+
+	// lumped mass
+	op := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	M := op.AssembleMassLumped().ToDense()
+	sum := 0.0
+	for I := 0; I < g.Size(); I++ {
+		chk.Float64(tst, io.Sf("M[%d][%d]", I, I), 1e-15, M.Get(I, I), w[I])
+		sum += M.Get(I, I)
+	}
+	chk.Float64(tst, "Σ M_II", 1e-14, sum, area)
+
+	// stretched grid with anisotropic cells: area = 3⋅0.5
+	g.RectSet2d([]float64{0, 0.5, 1.5, 3}, []float64{0, 0.1, 0.5})
+	w = GridQuadrature(g)
+	chk.Float64(tst, "w(corner) (stretched)", 1e-15, w[0], 0.5*0.1/4)
+	chk.Float64(tst, "w(last corner) (stretched)", 1e-15, w[g.Size()-1], 1.5*0.4/4)
+	chk.Float64(tst, "∫ 2 dΩ (stretched)", 1e-14, integrate(w, func(x la.Vector) float64 { return 2 }), 2*1.5)
+	chk.Float64(tst, "∫ x⋅y dΩ (stretched)", 1e-14, integrate(w, func(x la.Vector) float64 { return x[0] * x[1] }), 1.5*1.5*0.25)
+	op = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	M = op.AssembleMassLumped().ToDense()
+	sum = 0.0
+	for I := 0; I < g.Size(); I++ {
+		sum += M.Get(I, I)
+	}
+	chk.Float64(tst, "Σ M_II (stretched)", 1e-14, sum, 1.5)
+}
+
+func TestFields11(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Fields11. divergence on a stretched grid")
+
+	// grid
+	g := new(gm.Grid)
+	g.RectSet2d([]float64{0, 0.1, 0.3, 0.6, 1}, []float64{0, 0.05, 0.2, 0.5, 0.7, 1})
+
+	// vector field: f = {x² + xy, y² - 3x²y} ⇒ div f = 2x + y + 2y - 3x² (exact; quadratic along each line)
+	fx := make([]float64, g.Size())
+	fy := make([]float64, g.Size())
+	for I := 0; I < g.Size(); I++ {
+		x := g.Node(I)
+		fx[I] = x[0]*x[0] + x[0]*x[1]
+		fy[I] = x[1]*x[1] - 3*x[0]*x[0]*x[1]
+	}
+	div := Divergence(g, fx, fy)
+	for I := 0; I < g.Size(); I++ {
+		x := g.Node(I)
+		chk.AnaNum(tst, io.Sf("div @ %d", I), 1e-13, div[I], 2*x[0]+3*x[1]-3*x[0]*x[0], chk.Verbose)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func TestFlux01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Flux01. conservative flux reconstruction")
+
+	// stretched grid
+	X := []float64{0, 0.1, 0.25, 0.45, 0.7, 1.0}
+	Y := []float64{0, 0.2, 0.3, 0.5, 0.8}
+	g := new(gm.Grid)
+	g.RectSet2d(X, Y)
+
+	// operator with reaction, natural and Robin conditions
+	source := func(x la.Vector, t float64) float64 { return 1 + math.Sin(3*x[0])*x[1] }
+	op := NewFdmLaplacian(dbf.Params{{N: "kx", V: 2}, {N: "ky", V: 0.5}, {N: "kr", V: 0.7}}, g, source)
+	defer op.Free()
+	op.AddEbc(10, 1, nil)
+	op.AddNbc(11, 0.3, nil)
+	op.RobinBcs.SetInGrid(20, 1.5, 2, 0.4)
+	op.Assemble(false)
+	u, _ := op.SolveSteady(false)
+
+	// divergence equals the discrete source on the control volumes of the unknown nodes
+	q := ReconstructFlux(op, u)
+	div := q.Divergence()
+	for _, I := range op.Eqs.UtoF {
+		chk.Float64(tst, io.Sf("div @ %d", I), 1e-12, div[I], source(g.Node(I), 0)+0.7*u[I])
+	}
+
+	// boundary fluxes
+	for n := range Y {
+		chk.Float64(tst, io.Sf("qx @ right face %d", n), 1e-15, q.Qx[n][len(X)], 0.3)
+	}
+	for m := 1; m < len(X); m++ {
+		I := g.IndexMNPtoI(m, 0, 0)
+		chk.Float64(tst, io.Sf("qy @ bottom face %d", m), 1e-15, q.Qy[0][m], -0.5*(0.4-1.5*u[I])/2)
+	}
+	for m := range X {
+		chk.Float64(tst, io.Sf("qy @ top face %d", m), 1e-15, q.Qy[len(Y)][m], 0)
+	}
+
+	// normal components are continuous at the faces
+	for n, y := range Y {
+		for m := 1; m < len(X); m++ {
+			xf := (X[m-1] + X[m]) / 2
+			qxL, _ := q.At([]float64{xf - 1e-12, y})
+			qxR, _ := q.At([]float64{xf + 1e-12, y})
+			chk.Float64(tst, io.Sf("qx(L) @ face %d,%d", m, n), 1e-10, qxL, q.Qx[n][m])
+			chk.Float64(tst, io.Sf("qx(R) @ face %d,%d", m, n), 1e-10, qxR, q.Qx[n][m])
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// bufLogger implements Logger by writing messages to a buffer
+type bufLogger struct {
+	buf bytes.Buffer
+}
+
+func (o *bufLogger) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(&o.buf, format, args...)
+}
+
+func TestLogger01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Logger01. capture messages from solvers")
+
+	// grid and operator
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{9, 9})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, func(x la.Vector, t float64) float64 { return -1 })
+	s.SetHbc()
+
+	// steady solver
+	log := new(bufLogger)
+	s.Logger = log
+	s.Assemble(false)
+	s.SolveSteady(false)
+	io.Pforan("%s", log.buf.String())
+	chk.String(tst, log.buf.String(), "FdmLaplacian: solving system with Nu = 49 unknown and Nk = 32 known values\n")
+
+	// multigrid
+	log.buf.Reset()
+	mg := NewFdmMultigridSolver(s, 3, nil)
+	mg.Logger = log
+	_, nit := mg.Solve()
+	lines := strings.Split(strings.TrimSpace(log.buf.String()), "\n")
+	chk.Int(tst, "number of lines (multigrid)", len(lines), nit)
+	chk.String(tst, lines[0][:28], "FdmMultigrid: cycle   1: res")
+
+	// transient
+	log.buf.Reset()
+	sol := NewFdmTransientSolver(s, 1, nil)
+	defer sol.Free()
+	sol.Logger = log
+	sol.Solve(0.3, 0.1)
+	io.Pforan("%s", log.buf.String())
+	lines = strings.Split(strings.TrimSpace(log.buf.String()), "\n")
+	chk.Int(tst, "number of lines (transient)", len(lines), len(sol.Times))
+	chk.String(tst, lines[0], "FdmTransientSolver: t = 0.1, Δt = 0.1")
+}
+
+func TestLogger02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Logger02. solvers without logger print with io.Pf")
+
+	// run solvers and capture standard output
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{9, 9})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, func(x la.Vector, t float64) float64 { return -1 })
+	s.SetHbc()
+	s.Assemble(false)
+	run := func(verbose bool) (out string) {
+		verb := io.Verbose
+		io.Verbose = verbose
+		stdout := os.Stdout
+		r, w, err := os.Pipe()
+		if err != nil {
+			tst.Errorf("%v\n", err)
+			return
+		}
+		os.Stdout = w
+		sol := NewFdmTransientSolver(s, 1, nil)
+		sol.Solve(0.3, 0.1)
+		sol.Free()
+		w.Close()
+		os.Stdout = stdout
+		io.Verbose = verb
+		var buf bytes.Buffer
+		buf.ReadFrom(r)
+		return buf.String()
+	}
+
+	// default: same messages as LoggerPf
+	lines := strings.Split(strings.TrimSpace(run(true)), "\n")
+	chk.Int(tst, "number of lines", len(lines), 3)
+	chk.String(tst, lines[0], "FdmTransientSolver: t = 0.1, Δt = 0.1")
+
+	// io.Pf is silent if io.Verbose is false
+	chk.String(tst, run(false), "")
+}
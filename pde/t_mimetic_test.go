@@ -0,0 +1,92 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+func TestMimetic01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Mimetic01. div∘grad Laplacian")
+
+	// grid and operators
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 2}, []int{6, 5})
+	nn := g.Size()
+	G := EdgeGradient(g).ToDense()
+	D := EdgeDivergence(g).ToDense()
+	ne := 5*5 + 6*4
+	chk.Int(tst, "nedges", G.M, ne)
+	mGt := G.GetTranspose()
+	for k := range mGt.Data {
+		mGt.Data[k] = -mGt.Data[k]
+	}
+	chk.Deep2(tst, "D == -Gᵀ", 1e-15, D.GetDeep2(), mGt.GetDeep2())
+
+	// gradient of linear function
+	u := la.NewVector(nn)
+	for I := 0; I < nn; I++ {
+		x := g.Node(I)
+		u[I] = 2*x[0] - 3*x[1]
+	}
+	grad := la.NewVector(ne)
+	la.MatVecMul(grad, 1, G, u)
+	chk.Array(tst, "∂u/∂x", 1e-14, grad[:25], utl.Vals(25, 2))
+	chk.Array(tst, "∂u/∂y", 1e-14, grad[25:], utl.Vals(24, -3))
+
+	// composition
+	kx, ky := 2.0, 0.5
+	K := la.NewMatrix(ne, ne)
+	for e := 0; e < ne; e++ {
+		K.Set(e, e, ky)
+		if e < 25 {
+			K.Set(e, e, kx)
+		}
+	}
+	KG := la.NewMatrix(ne, nn)
+	la.MatMatMul(KG, 1, K, G)
+	DKG := la.NewMatrix(nn, nn)
+	la.MatMatMul(DKG, 1, D, KG)
+	L := MimeticLaplacian(g, []float64{kx, ky}).ToDense()
+	chk.Deep2(tst, "L == D⋅K⋅G", 1e-13, L.GetDeep2(), DKG.GetDeep2())
+
+	// exactly symmetric
+	chk.Deep2(tst, "L == Lᵀ", 0, L.GetDeep2(), L.GetTranspose().GetDeep2())
+
+	// interior rows equal the 5-point stencil
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: kx}, {N: "ky", V: ky}}, g, nil)
+	s.Assemble(false)
+	F := s.Eqs.Auu.ToDense()
+	for I := 0; I < nn; I++ {
+		if kind, _ := g.ClassifyNode(I); kind != gm.InteriorNode {
+			continue
+		}
+		for J := 0; J < nn; J++ {
+			chk.Float64(tst, io.Sf("L[%d][%d]", I, J), 1e-12, L.Get(I, J), F.Get(I, J))
+		}
+	}
+
+	// negative definite after prescribing the value at node 0
+	A := la.NewMatrix(nn-1, nn-1)
+	for i := 1; i < nn; i++ {
+		for j := 1; j < nn; j++ {
+			A.Set(i-1, j-1, -L.Get(i, j))
+		}
+	}
+	x, b := la.NewVector(nn-1), la.NewVector(nn-1)
+	b.Fill(1)
+	if err := la.SolveRealLinSysSPD(x, A, b); err != nil {
+		tst.Errorf("-L should be positive definite: %v\n", err)
+	}
+}
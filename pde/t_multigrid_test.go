@@ -0,0 +1,333 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+func TestMultigrid01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Multigrid01. Poisson: full-weighting versus injection")
+
+	// solve problem
+	//    ∂²u     ∂²u
+	//    ———  +  ——— = -2π²⋅sin(πx)⋅sin(πy)    with   u = 0 on all boundaries
+	//    ∂x²     ∂y²
+
+	// 33x33 grid
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{33, 33})
+
+	// operator
+	p := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}
+	s := NewFdmLaplacian(p, g, func(x la.Vector, t float64) float64 {
+		return -2.0 * math.Pi * math.Pi * math.Sin(math.Pi*x[0]) * math.Sin(math.Pi*x[1])
+	})
+	s.SetHbc()
+
+	// reference solution
+	s.Assemble(false)
+	uref, _ := s.SolveSteady(false)
+
+	// with 1 pre-smoothing and no post-smoothing iterations, the high-frequency
+	// components of the residual are not damped enough for injection to work well
+
+	// full-weighting (default)
+	mg := NewFdmMultigridSolver(s, 4, nil)
+	mg.NpreSmooth, mg.NpostSmooth = 1, 0
+	ufw, nitFw := mg.Solve()
+	io.Pforan("full-weighting: nit = %d\n", nitFw)
+	chk.Array(tst, "u(full-weighting)", 1e-9, ufw, uref)
+
+	// injection
+	mg = NewFdmMultigridSolver(s, 4, TransferInjection{})
+	mg.NpreSmooth, mg.NpostSmooth = 1, 0
+	uin, nitIn := mg.Solve()
+	io.Pforan("injection:      nit = %d\n", nitIn)
+	chk.Array(tst, "u(injection)", 1e-9, uin, uref)
+
+	// injection converges more slowly
+	if nitIn <= nitFw {
+		tst.Errorf("injection should take more V-cycles than full-weighting: %d ≤ %d\n", nitIn, nitFw)
+	}
+}
+
+func TestMultigrid02(tst *testing.T) {
+	//verbose()
+	chk.PrintTitle("Multigrid02. panic on number of levels")
+	defer chk.RecoverTstPanicIsOK(tst)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{9, 9})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	NewFdmMultigridSolver(s, 4, nil) // 8 intervals ⇒ 1 interval on the coarsest grid
+}
+
+func TestMultigrid03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Multigrid03. Poisson: Chebyshev versus Gauss-Seidel smoother")
+
+	// 33x33 grid
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{33, 33})
+
+	// operator
+	p := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}
+	s := NewFdmLaplacian(p, g, func(x la.Vector, t float64) float64 {
+		return -2.0 * math.Pi * math.Pi * math.Sin(math.Pi*x[0]) * math.Sin(math.Pi*x[1])
+	})
+	s.SetHbc()
+
+	// reference solution
+	s.Assemble(false)
+	uref, _ := s.SolveSteady(false)
+
+	// convergence factor per unit work; i.e. per smoothing iteration (Gauss-Seidel sweep or
+	// matrix-vector product of the Chebyshev polynomial; both visit all non-zero entries once)
+	factor := func(mg *FdmMultigrid, nit int) float64 {
+		return math.Pow(mg.Residuals[nit]/mg.Residuals[0], 1.0/float64(nit*(mg.NpreSmooth+mg.NpostSmooth)))
+	}
+
+	// Gauss-Seidel with 8 sweeps
+	mg := NewFdmMultigridSolver(s, 4, nil)
+	mg.NpreSmooth, mg.NpostSmooth = 8, 8
+	ugs, nitGs := mg.Solve()
+	facGs := factor(mg, nitGs)
+	io.Pforan("Gauss-Seidel: nit = %d, factor per sweep = %.4f\n", nitGs, facGs)
+	chk.Array(tst, "u(Gauss-Seidel)", 1e-9, ugs, uref)
+
+	// Chebyshev of degree 8 (same work) with eigenvalues of D⁻¹⋅A in (0,2) = (0,λmax); the
+	// polynomial targets [λmax/30, 1.1⋅λmax]
+	//  NOTE: with low degrees (e.g. 3), Gauss-Seidel reduces the residual faster per unit of work
+	mg = NewFdmMultigridSolver(s, 4, nil)
+	mg.Smoother = "cheby"
+	mg.NpreSmooth, mg.NpostSmooth = 8, 8
+	mg.EigMin, mg.EigMax = 2.0/30.0, 1.1*2.0
+	uch, nitCh := mg.Solve()
+	facCh := factor(mg, nitCh)
+	io.Pforan("Chebyshev:    nit = %d, factor per matvec = %.4f\n", nitCh, facCh)
+	chk.Array(tst, "u(Chebyshev)", 1e-9, uch, uref)
+	if facCh > facGs || nitCh > nitGs {
+		tst.Errorf("Chebyshev smoother should reduce the residual at least as fast as Gauss-Seidel at the same work: factor = %g (Gauss-Seidel: %g), nit = %d (Gauss-Seidel: %d)\n", facCh, facGs, nitCh, nitGs)
+	}
+
+	// Chebyshev with spectral radius estimate
+	mg = NewFdmMultigridSolver(s, 4, nil)
+	mg.Smoother = "cheby"
+	uch, _ = mg.Solve()
+	chk.Array(tst, "u(Chebyshev: estimate)", 1e-9, uch, uref)
+}
+
+func TestMultigrid04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Multigrid04. two-grid correction of smooth error")
+
+	// fine (17x17) and coarse (9x9) grids
+	gf := new(gm.Grid)
+	gc := new(gm.Grid)
+	gf.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{17, 17})
+	gc.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{9, 9})
+
+	// operators
+	p := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}
+	source := func(x la.Vector, t float64) float64 { return -1.0 }
+	fine := NewFdmLaplacian(p, gf, source)
+	coarse := NewFdmLaplacian(p, gc, nil)
+	fine.SetHbc()
+	coarse.SetHbc()
+	fine.Assemble(false)
+	coarse.Assemble(false)
+
+	// discrete solution
+	uref, _ := fine.SolveSteady(false)
+
+	// approximation with smooth error: e = sin(πx)⋅sin(πy)
+	e := la.NewVector(gf.Size())
+	for I := 0; I < gf.Size(); I++ {
+		x := gf.Node(I)
+		e[I] = math.Sin(math.Pi*x[0]) * math.Sin(math.Pi*x[1])
+	}
+	u := la.NewVector(gf.Size())
+	la.VecAdd(u, 1, uref, 1, e)
+
+	// residual: r = b - A⋅u = -A⋅e (at unknown nodes)
+	eu := la.NewVector(fine.Eqs.Nu)
+	ru := la.NewVector(fine.Eqs.Nu)
+	fine.Eqs.SplitVector(eu, la.NewVector(fine.Eqs.Nk), e)
+	la.SpMatVecMul(ru, -1, fine.Eqs.Auu.ToMatrix(nil), eu)
+	r := la.NewVector(gf.Size())
+	fine.Eqs.JoinVector(r, ru, la.NewVector(fine.Eqs.Nk))
+
+	// correct
+	TwoGridCorrect(fine, coarse, nil, u, r)
+
+	// check
+	ratio := u.NormDiff(uref) / e.Norm()
+	io.Pforan("‖e_new‖ / ‖e‖ = %g\n", ratio)
+	if ratio > 0.1 {
+		tst.Errorf("two-grid correction should reduce the smooth error by a factor of at least 10. ratio = %g\n", ratio)
+	}
+}
+
+func TestMultigrid05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Multigrid05. restriction of solution")
+
+	// smooth field with zero normal derivatives at boundaries
+	f := func(x la.Vector) float64 {
+		return math.Cos(math.Pi*x[0]) * math.Cos(math.Pi*x[1])
+	}
+	gFine := new(gm.Grid)
+	gFine.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{17, 17})
+	gCoarse := new(gm.Grid)
+	gCoarse.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{9, 9})
+	uFine := make([]float64, gFine.Size())
+	for I := 0; I < gFine.Size(); I++ {
+		uFine[I] = f(gFine.Node(I))
+	}
+	uCoarse := RestrictSolution(gFine, gCoarse, uFine)
+	chk.Int(tst, "len(uCoarse)", len(uCoarse), 81)
+
+	// full weighting (exact for this field): R{f} = f ⋅ ((1 + cos(πh)) / 2)²
+	h := 1.0 / 16.0
+	c := (1 + math.Cos(math.Pi*h)) / 2
+	maxErr := 0.0
+	for I := 0; I < gCoarse.Size(); I++ {
+		x := gCoarse.Node(I)
+		maxErr = math.Max(maxErr, math.Abs(uCoarse[I]-f(x)))
+		chk.Float64(tst, "R{f}", 1e-15, uCoarse[I], f(x)*c*c)
+	}
+	io.Pforan("max |R{f} - f| = %v\n", maxErr)
+	if maxErr > 0.02 {
+		tst.Errorf("restricted values should approximate the function at coarse nodes. max error = %g\n", maxErr)
+	}
+
+	// incompatible grids
+	defer chk.RecoverTstPanicIsOK(tst)
+	RestrictSolution(gFine, gFine, uFine)
+}
+
+func TestMultigrid06(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Multigrid06. anisotropic Laplacian: semi-coarsening versus full coarsening")
+
+	// strongly anisotropic operator with kx/ky = 1000; the y-direction has an odd number of
+	// intervals; thus, it cannot be coarsened by full coarsening
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{33, 24})
+	p := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 0.001}}
+	source := func(x la.Vector, t float64) float64 {
+		return -math.Sin(math.Pi*x[0]) * math.Sin(math.Pi*x[1])
+	}
+	s := NewFdmLaplacian(p, g, source)
+	s.SetHbc()
+	s.Assemble(false)
+	uref, _ := s.SolveSteady(false)
+
+	// semi-coarsening: only x is coarsened (33 → 17 → 9 → 5 nodes)
+	mg := NewFdmMultigridSemiCoarsening(s, 4, nil)
+	chk.Ints(tst, "coarsest npts", []int{mg.levels[3].grid.Npts(0), mg.levels[3].grid.Npts(1)}, []int{5, 24})
+	usc, nitSc := mg.Solve()
+	io.Pforan("semi-coarsening: nit = %d\n", nitSc)
+	chk.Array(tst, "u(semi-coarsening)", 1e-9, usc, uref)
+
+	// full coarsening (with an even number of intervals along y) stalls: the asymptotic
+	// convergence factor is close to one
+	g2 := new(gm.Grid)
+	g2.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{33, 33})
+	s2 := NewFdmLaplacian(p, g2, source)
+	s2.SetHbc()
+	mg = NewFdmMultigridSolver(s2, 4, nil)
+	mg.MaxIt = 20
+	converged := func() (yes bool) {
+		defer func() {
+			if err := recover(); err != nil {
+				yes = false
+			}
+		}()
+		mg.Solve()
+		return true
+	}
+	if converged() {
+		tst.Errorf("full coarsening should not converge in %d V-cycles\n", mg.MaxIt)
+		return
+	}
+	factor := mg.Residuals[20] / mg.Residuals[19]
+	io.Pforan("full coarsening: convergence factor = %v\n", factor)
+	if factor < 0.8 {
+		tst.Errorf("full coarsening should stall. convergence factor = %g\n", factor)
+	}
+
+	// semi-coarsening with the same grid
+	mg = NewFdmMultigridSemiCoarsening(s2, 4, nil)
+	_, nit := mg.Solve()
+	factor = mg.Residuals[nit] / mg.Residuals[nit-1]
+	io.Pforan("semi-coarsening (33×33): nit = %d, convergence factor = %v\n", nit, factor)
+	if factor > 0.1 {
+		tst.Errorf("semi-coarsening should converge fast. convergence factor = %g\n", factor)
+	}
+}
+
+func TestMultigrid07(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Multigrid07. panic on unsupported operators")
+
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{9, 9})
+	check := func(kind string, setup func(s *FdmLaplacian)) {
+		defer func() {
+			if err := recover(); err == nil {
+				tst.Errorf("%s should panic\n", kind)
+			}
+		}()
+		s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+		setup(s)
+		NewFdmMultigridSolver(s, 2, nil)
+	}
+	ones, zeros := utl.Ones(g.Size()), make([]float64, g.Size())
+	check("Robin conditions", func(s *FdmLaplacian) { s.RobinBcs.SetInGrid(10, 1, 1, 0) })
+	check("coefficient field", func(s *FdmLaplacian) { s.SetHbc(); s.AssembleWithCoeffField(ones, false) })
+	check("tensor field", func(s *FdmLaplacian) { s.SetHbc(); s.AssembleWithTensorField(ones, zeros, ones, false) })
+	check("principal field", func(s *FdmLaplacian) { s.SetHbc(); s.AssembleWithPrincipalField(2, 1, ones, false) })
+	check("penalty", func(s *FdmLaplacian) { s.SetHbc(); s.UsePenaltyBC(1e8) })
+	check("interface", func(s *FdmLaplacian) { s.SetHbc(); s.SetInterfaceCondition([]int{g.IndexMNPtoI(4, 4, 0)}, 0, 1, 0) })
+	check("signed distance function", func(s *FdmLaplacian) {
+		s.SetDomainSDF(func(x []float64) float64 { return math.Hypot(x[0]-0.5, x[1]-0.5) - 0.4 }, 0, nil)
+	})
+
+	// stretched grid (8 intervals along each direction): full and semi-coarsening
+	gs := new(gm.Grid)
+	gs.RectSet2d([]float64{0, 0.05, 0.15, 0.3, 0.5, 0.65, 0.8, 0.9, 1}, []float64{0, 0.125, 0.25, 0.375, 0.5, 0.625, 0.75, 0.875, 1})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, gs, nil)
+	s.SetHbc()
+	for _, semi := range []bool{false, true} {
+		func() {
+			defer func() {
+				if err := recover(); err == nil {
+					tst.Errorf("stretched grid should panic (semi-coarsening = %v)\n", semi)
+				}
+			}()
+			if semi {
+				NewFdmMultigridSemiCoarsening(s, 2, nil)
+			} else {
+				NewFdmMultigridSolver(s, 2, nil)
+			}
+		}()
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func TestOverset01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Overset01. two overlapping grids covering an L-shape")
+
+	// L-shaped domain: [0,2]×[0,1] ∪ [0,1]×[0,2] with ∇²u = -1 and u = 0 on the boundary
+	params := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}
+	source := func(x la.Vector, t float64) float64 { return -1 }
+
+	// grid A: [0,2]×[0,1] and grid B: [0,1]×[0.5,2] (overlap [0,1]×[0.5,1])
+	ga, gb := new(gm.Grid), new(gm.Grid)
+	ga.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{21, 11})
+	gb.RectGenUniform([]float64{0, 0.5}, []float64{1, 2}, []int{11, 16})
+	opa := NewFdmLaplacian(params, ga, source)
+	opb := NewFdmLaplacian(params, gb, source)
+	opa.SetHbc()
+	opb.SetHbc()
+
+	// coupled solution
+	sol := NewOversetSolver(opa, opb)
+	defer sol.Free()
+	sol.AddInterface(0, 21) // top of A inside B
+	sol.AddInterface(1, 20) // bottom of B inside A
+	sol.AddInterface(1, 11) // right of B inside A
+	sol.Solve()
+	io.Pforan("nit = %d\n", sol.Nit)
+	if sol.Nit < 3 {
+		tst.Errorf("coupling should require iterations. nit = %d\n", sol.Nit)
+	}
+
+	// reference: single grid [0,2]×[0,2] with nodes outside the L-shape inactive
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 2}, []int{21, 21})
+	op := NewFdmLaplacian(params, g, source)
+	op.SetHbc()
+	box := func(x []float64, xmin, xmax []float64) float64 {
+		return math.Max(math.Max(xmin[0]-x[0], x[0]-xmax[0]), math.Max(xmin[1]-x[1], x[1]-xmax[1]))
+	}
+	op.SetDomainSDF(func(x []float64) float64 {
+		return math.Min(box(x, []float64{0, 0}, []float64{2, 1}), box(x, []float64{0, 0}, []float64{1, 2}))
+	}, 0, nil)
+	op.Assemble(false)
+	uRef, _ := op.SolveSteady(false)
+
+	// compare
+	for igrid, gg := range []*gm.Grid{ga, gb} {
+		for I := 0; I < gg.Size(); I++ {
+			x := gg.Node(I)
+			J := g.IndexMNPtoI(int(math.Round(x[0]/0.1)), int(math.Round(x[1]/0.1)), 0)
+			chk.Float64(tst, io.Sf("u%d @ %d", igrid, I), 1e-9, sol.U[igrid][I], uRef[J])
+		}
+	}
+
+	// the solutions agree in the overlap
+	for I := 0; I < gb.Size(); I++ {
+		x := gb.Node(I)
+		if x[1] <= 1 {
+			J := ga.IndexMNPtoI(int(math.Round(x[0]/0.1)), int(math.Round(x[1]/0.1)), 0)
+			chk.Float64(tst, io.Sf("uB - uA @ %d", I), 1e-9, sol.U[1][I], sol.U[0][J])
+		}
+	}
+}
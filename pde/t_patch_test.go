@@ -0,0 +1,71 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func TestPatch01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Patch01. refined patch around localized feature")
+
+	// narrow Gaussian bump: u = exp(-r²/σ²)  ⇒  ∇²u = (4r²/σ⁴ - 4/σ²)⋅u
+	σ := 0.08
+	exact := func(x la.Vector, t float64) float64 {
+		r2 := (x[0]-0.5)*(x[0]-0.5) + (x[1]-0.5)*(x[1]-0.5)
+		return math.Exp(-r2 / (σ * σ))
+	}
+	source := func(x la.Vector, t float64) float64 {
+		r2 := (x[0]-0.5)*(x[0]-0.5) + (x[1]-0.5)*(x[1]-0.5)
+		return (4*r2/math.Pow(σ, 4) - 4/(σ*σ)) * exact(x, t)
+	}
+
+	// coarse problem
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{17, 17})
+	coarse := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, source)
+	for _, tag := range []int{10, 11, 20, 21} {
+		coarse.AddEbc(tag, 0, exact)
+	}
+	coarse.Assemble(false)
+	uCoarse, _ := coarse.SolveSteady(false)
+
+	// patch: the box is enlarged to the coarse lines 0.25 and 0.75
+	patch, u := SolvePatch(coarse, uCoarse, []float64{0.27, 0.3}, []float64{0.73, 0.7}, 4)
+	pg := patch.Grid
+	chk.Int(tst, "patch nx", pg.Npts(0), 8*4+1)
+	chk.Int(tst, "patch ny", pg.Npts(1), 8*4+1)
+	chk.Float64(tst, "patch xmin", 1e-15, pg.Xmin(0), 0.25)
+	chk.Float64(tst, "patch ymax", 1e-15, pg.Xmax(1), 0.75)
+
+	// errors in the patch
+	errCoarse, errPatch := 0.0, 0.0
+	for I := 0; I < g.Size(); I++ {
+		x := g.Node(I)
+		if x[0] >= 0.25 && x[0] <= 0.75 && x[1] >= 0.25 && x[1] <= 0.75 {
+			errCoarse = math.Max(errCoarse, math.Abs(uCoarse[I]-exact(x, 0)))
+		}
+	}
+	for I := 0; I < pg.Size(); I++ {
+		errPatch = math.Max(errPatch, math.Abs(u[I]-exact(pg.Node(I), 0)))
+	}
+	io.Pforan("max error: coarse = %g, patch = %g\n", errCoarse, errPatch)
+	if errPatch > errCoarse/4 {
+		tst.Errorf("patch solution should be more accurate than the coarse solution: %g > %g/4\n", errPatch, errCoarse)
+	}
+
+	// coarse solution computed internally and patch touching the boundary
+	_, u = SolvePatch(coarse, nil, []float64{0, 0}, []float64{1, 1}, 1)
+	chk.Array(tst, "refine = 1 over the whole domain", 1e-13, u, uCoarse)
+}
@@ -0,0 +1,154 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func TestPoisson01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Poisson01. one-call solver: ZZ p1342 Example 2 (see Fdm03)")
+
+	// solve
+	source := func(X la.Vector, t float64) float64 {
+		x, y := X[0], X[1]
+		xx, yy := x*x, y*x
+		xxx, yyy := xx*x, yy*y
+		return 14.0*yyy - (16.0-12.0*x)*yy - (-42.0*xx+54.0*x-2.0)*y + 4.0*xxx - 16.0*xx + 12.0*x
+	}
+	bc := map[string]float64{"left": 0, "right": 0, "bottom": 0, "top": 0}
+	u, g := SolvePoisson([]float64{0, 0}, []float64{1, 1}, []int{4, 4}, source, bc)
+	chk.Int(tst, "nnodes", len(u), 25)
+
+	// check
+	ana := func(X []float64) float64 {
+		x, y := X[0], X[1]
+		return x * (1.0 - x) * y * (1.0 - y) * (1.0 + 2.0*x + 7.0*y)
+	}
+	for n := 0; n < g.Npts(1); n++ {
+		for m := 0; m < g.Npts(0); m++ {
+			chk.AnaNum(tst, "u", 0.021, u[g.IndexMNPtoI(m, n, 0)], ana(g.X(m, n, 0)), chk.Verbose)
+		}
+	}
+
+	// Laplace equation with linear solution: u = 1 - x (top and bottom are insulated)
+	u, g = SolvePoisson([]float64{0, 0}, []float64{1, 2}, []int{4, 6}, nil, map[string]float64{"xmin": 1, "xmax": 0})
+	for I := 0; I < g.Size(); I++ {
+		chk.AnaNum(tst, "u", 1e-13, u[I], 1.0-g.Node(I)[0], false)
+	}
+}
+
+func TestPoisson02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Poisson02. invalid edge name and 3D")
+
+	check := func(msg string, xmin, xmax []float64, ndiv []int, bc map[string]float64) {
+		defer func() {
+			if err := recover(); err == nil {
+				tst.Errorf("%s: should have panicked\n", msg)
+			}
+		}()
+		SolvePoisson(xmin, xmax, ndiv, nil, bc)
+	}
+	check("invalid edge name", []float64{0, 0}, []float64{1, 1}, []int{4, 4}, map[string]float64{"front": 0})
+	check("3D", []float64{0, 0, 0}, []float64{1, 1, 1}, []int{4, 4, 4}, map[string]float64{"xmin": 0})
+}
+
+func TestPoisson03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Poisson03. biharmonic equation with mixed formulation (simply supported plate)")
+
+	// square plate with D = 1 and uniform load q = 1 (simply supported): u = ∇²u = 0 @ edges
+	ndiv := 10
+	h := 1.0 / float64(ndiv)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{ndiv + 1, ndiv + 1})
+	p := dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}
+	tags := []int{10, 11, 20, 21}
+	q := func(x la.Vector, t float64) float64 { return 1 }
+	u, w := SolveBiharmonicMixed(p, g, q, tags, nil, nil)
+
+	// direct solution with the 13-point stencil and antisymmetric ghost values
+	nint := ndiv - 1 // interior nodes along each direction
+	A := la.NewMatrix(nint*nint, nint*nint)
+	b := la.NewVector(nint * nint)
+	for j := 1; j < ndiv; j++ {
+		for i := 1; i < ndiv; i++ {
+			row := (i - 1) + (j-1)*nint
+			b[row] = h * h * h * h
+			put := func(a, c int, coef float64) {
+				sign := 1.0
+				if a == -1 || a == ndiv+1 {
+					a, sign = mirrorIndex(a, ndiv), -sign
+				}
+				if c == -1 || c == ndiv+1 {
+					c, sign = mirrorIndex(c, ndiv), -sign
+				}
+				if a == 0 || a == ndiv || c == 0 || c == ndiv {
+					return // u = 0
+				}
+				A.Add(row, (a-1)+(c-1)*nint, sign*coef)
+			}
+			put(i, j, 20)
+			for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+				put(i+d[0], j+d[1], -8)
+				put(i+2*d[0], j+2*d[1], 1)
+			}
+			for _, d := range [][2]int{{-1, -1}, {1, -1}, {-1, 1}, {1, 1}} {
+				put(i+d[0], j+d[1], 2)
+			}
+		}
+	}
+	uu := la.NewVector(nint * nint)
+	la.DenSolve(uu, A, b, false)
+	for j := 1; j < ndiv; j++ {
+		for i := 1; i < ndiv; i++ {
+			chk.Float64(tst, "u (13-point)", 1e-15, u[g.IndexMNPtoI(i, j, 0)], uu[(i-1)+(j-1)*nint])
+		}
+	}
+	mid := g.IndexMNPtoI(ndiv/2, ndiv/2, 0)
+	io.Pforan("u(½,½) = %v (Navier: 0.00406235)\n", u[mid])
+	chk.Float64(tst, "u(½,½) (Navier solution)", 1e-5, u[mid], 0.00406235)
+	chk.Float64(tst, "w(0,0)", 1e-15, w[0], 0)
+
+	// sinusoidal load: eigenmode of the discrete Laplacian ⇒ u = q/λ² and w = q/λ with
+	// λ = -8⋅sin²(πh/2)/h² (≈ -2π²; i.e. u ≈ q/(4π⁴))
+	π := math.Pi
+	sxy := func(x la.Vector) float64 { return math.Sin(π*x[0]) * math.Sin(π*x[1]) }
+	q = func(x la.Vector, t float64) float64 { return sxy(x) }
+	u, w = SolveBiharmonicMixed(p, g, q, tags, nil, nil)
+	λ := -8 * math.Pow(math.Sin(π*h/2), 2) / (h * h)
+	for I := 0; I < g.Size(); I++ {
+		x := g.Node(I)
+		chk.Float64(tst, "u (sinusoidal load)", 1e-15, u[I], sxy(x)/(λ*λ))
+		chk.Float64(tst, "w (sinusoidal load)", 1e-15, w[I], sxy(x)/λ)
+	}
+	chk.Float64(tst, "u(½,½) (sinusoidal load)", 5e-5, u[mid], 1/(4*π*π*π*π))
+
+	// symmetry planes: quarter plate with x, y ∈ [½, 1] and ∂u/∂n = ∂w/∂n = 0 @ x = ½ and y = ½
+	gq := new(gm.Grid)
+	gq.RectGenUniform([]float64{0.5, 0.5}, []float64{1, 1}, []int{ndiv/2 + 1, ndiv/2 + 1})
+	uq, _ := SolveBiharmonicMixed(p, gq, func(x la.Vector, t float64) float64 { return 1 }, []int{11, 21}, nil, nil)
+	chk.Float64(tst, "u(½,½) (quarter plate)", 1e-5, uq[0], 0.00406235)
+}
+
+// mirrorIndex returns the index mirrored at the first (0) or last (n) index of a line of nodes
+func mirrorIndex(a, n int) int {
+	if a < 0 {
+		return -a
+	}
+	return 2*n - a
+}
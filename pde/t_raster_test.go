@@ -0,0 +1,150 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+)
+
+func TestRaster01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Raster01. coefficients from text raster")
+
+	// raster with k = 1 + x + 2y on [0,3]×[0,2]; the first row is at the top (y = 2)
+	buf := new(bytes.Buffer)
+	for r := 0; r < 3; r++ {
+		y := float64(2 - r)
+		for c := 0; c < 4; c++ {
+			x := float64(c)
+			io.Ff(buf, "%g ", 1+x+2*y)
+		}
+		io.Ff(buf, "\n")
+	}
+	io.WriteFileD("/tmp/gosl/pde", "raster01.txt", buf)
+
+	// same size
+	k := func(x []float64) float64 { return 1 + x[0] + 2*x[1] }
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{3, 2}, []int{4, 3})
+	kField := LoadCoeffRaster("/tmp/gosl/pde/raster01.txt", g)
+	for I := 0; I < g.Size(); I++ {
+		chk.Float64(tst, io.Sf("k @ %v", g.Node(I)), 1e-15, kField[I], k(g.Node(I)))
+	}
+
+	// resampled (bilinear interpolation is exact for this field)
+	g.RectGenUniform([]float64{0, 0}, []float64{3, 2}, []int{7, 5})
+	kField = LoadCoeffRaster("/tmp/gosl/pde/raster01.txt", g)
+	for I := 0; I < g.Size(); I++ {
+		chk.Float64(tst, io.Sf("k @ %v", g.Node(I)), 1e-14, kField[I], k(g.Node(I)))
+	}
+
+	// comments and empty lines are skipped; rows with missing values are rejected
+	io.WriteStringToFileD("/tmp/gosl/pde", "raster01b.txt", "# k\n1 2\n\n3 4\n")
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{2, 2})
+	kField = LoadCoeffRaster("/tmp/gosl/pde/raster01b.txt", g)
+	chk.Array(tst, "k (2×2)", 1e-15, kField, []float64{3, 4, 1, 2})
+	defer chk.RecoverTstPanicIsOK(tst)
+	io.WriteStringToFileD("/tmp/gosl/pde", "raster01c.txt", "1 2 3\n4 5\n6 7 8\n")
+	LoadCoeffRaster("/tmp/gosl/pde/raster01c.txt", g)
+}
+
+func TestRaster02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Raster02. coefficients from grayscale image")
+
+	// 3×2 image (top row first)
+	img := image.NewGray(image.Rect(0, 0, 3, 2))
+	vals := [][]uint8{{0, 51, 102}, {153, 204, 255}}
+	for r := 0; r < 2; r++ {
+		for c := 0; c < 3; c++ {
+			img.SetGray(c, r, color.Gray{Y: vals[r][c]})
+		}
+	}
+	os.MkdirAll("/tmp/gosl/pde", 0777)
+	file, err := os.Create("/tmp/gosl/pde/raster02.png")
+	if err != nil {
+		tst.Errorf("cannot create file: %v\n", err)
+		return
+	}
+	png.Encode(file, img)
+	file.Close()
+
+	// check: node (m,n) corresponds to pixel (m, 1-n)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{3, 2})
+	kField := LoadCoeffRaster("/tmp/gosl/pde/raster02.png", g)
+	io.Pforan("k = %v\n", kField)
+	chk.Array(tst, "k", 1e-15, kField, []float64{0.6, 0.8, 1, 0, 0.2, 0.4})
+}
+
+func TestRaster03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Raster03. heatmap of solution")
+
+	// linear solution u = 2 + x: Laplace problem with u = 2 (left) and u = 6 (right)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{4, 1}, []int{5, 3})
+	s := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	s.AddEbc(10, 2, nil)
+	s.AddEbc(11, 6, nil)
+	s.Assemble(false)
+	u, _ := s.SolveSteady(false)
+
+	// read image
+	read := func(colormap string) image.Image {
+		s.WriteHeatmapPNG("/tmp/gosl/pde", "raster03", u, colormap)
+		file, err := os.Open("/tmp/gosl/pde/raster03.png")
+		if err != nil {
+			tst.Errorf("cannot open file: %v\n", err)
+			return nil
+		}
+		defer file.Close()
+		img, err := png.Decode(file)
+		if err != nil {
+			tst.Errorf("cannot decode file: %v\n", err)
+			return nil
+		}
+		return img
+	}
+	check := func(img image.Image, c, r int, correct color.RGBA) {
+		cr, cg, cb, _ := img.At(c, r).RGBA()
+		res := []int{int(cr >> 8), int(cg >> 8), int(cb >> 8)}
+		chk.Ints(tst, io.Sf("pixel(%d,%d)", c, r), res, []int{int(correct.R), int(correct.G), int(correct.B)})
+	}
+
+	// grayscale: one pixel per node and intensities proportional to x
+	img := read("gray")
+	chk.Int(tst, "width", img.Bounds().Dx(), 5)
+	chk.Int(tst, "height", img.Bounds().Dy(), 3)
+	for c, y := range []uint8{0, 64, 128, 191, 255} {
+		for r := 0; r < 3; r++ {
+			check(img, c, r, color.RGBA{R: y, G: y, B: y})
+		}
+	}
+
+	// viridis: dark purple, teal and yellow at min, mid and max
+	img = read("")
+	check(img, 0, 0, color.RGBA{R: 68, G: 1, B: 84})
+	check(img, 2, 1, color.RGBA{R: 33, G: 145, B: 140})
+	check(img, 4, 2, color.RGBA{R: 253, G: 231, B: 37})
+	check(img, 1, 2, color.RGBA{R: 59, G: 82, B: 139})
+
+	// invalid colormap
+	defer chk.RecoverTstPanicIsOK(tst)
+	s.WriteHeatmapPNG("/tmp/gosl/pde", "raster03", u, "jet")
+}
@@ -0,0 +1,104 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func TestRefine01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Refine01. anisotropic refinement of thin x-aligned layer")
+
+	// solution with a thin layer @ y = ½:  u = tanh((y-½)/δ)  ⇒  ∂²u/∂y² = -2⋅u⋅(1-u²)/δ²
+	δ := 0.02
+	ana := func(x la.Vector, t float64) float64 { return math.Tanh((x[1] - 0.5) / δ) }
+	source := func(x la.Vector, t float64) float64 {
+		u := ana(x, t)
+		return -2.0 * u * (1.0 - u*u) / (δ * δ)
+	}
+
+	// adaptive loop
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{9, 9})
+	var errs []float64
+	for cycle := 0; cycle < 6; cycle++ {
+
+		// solve
+		op := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, source)
+		op.AddEbc(20, 0, ana) // the left and right edges are impermeable (∂u/∂x = 0)
+		op.AddEbc(21, 0, ana)
+		op.Assemble(false)
+		u, _ := op.SolveSteady(false)
+		op.Free()
+		e := 0.0
+		for I := 0; I < g.Size(); I++ {
+			e = math.Max(e, math.Abs(u[I]-ana(g.Node(I), 0)))
+		}
+		errs = append(errs, e)
+
+		// refine
+		var nsplit []int
+		g, nsplit = RefineAnisotropic(g, u, 0.25)
+		io.Pforan("cycle %d: error = %.3e, nsplit = %v, npts = %d x %d\n", cycle, e, nsplit, g.Npts(0), g.Npts(1))
+		if nsplit[0] != 0 {
+			tst.Errorf("x-intervals should not be refined: nsplit = %v\n", nsplit)
+			return
+		}
+		if nsplit[1] < 1 {
+			tst.Errorf("y-intervals across the layer should be refined: nsplit = %v\n", nsplit)
+			return
+		}
+	}
+
+	// x-lines are unchanged and the error decreases
+	chk.Int(tst, "npts(x)", g.Npts(0), 9)
+	if errs[len(errs)-1] > errs[0]/20 {
+		tst.Errorf("error should decrease: initial = %g, final = %g\n", errs[0], errs[len(errs)-1])
+	}
+
+	// the y-intervals concentrate across the layer
+	Y := g.Coords(1)
+	hLayer, hFar := math.Inf(1), 0.0
+	for k := 0; k < len(Y)-1; k++ {
+		h := Y[k+1] - Y[k]
+		if math.Abs((Y[k]+Y[k+1])/2-0.5) < 2*δ {
+			hLayer = math.Min(hLayer, h)
+		}
+		if math.Abs((Y[k]+Y[k+1])/2-0.5) > 0.25 {
+			hFar = math.Max(hFar, h)
+		}
+	}
+	io.Pforan("h(layer) = %g, h(far) = %g\n", hLayer, hFar)
+	if hLayer > hFar/16 {
+		tst.Errorf("refinement should concentrate across the layer: h(layer) = %g, h(far) = %g\n", hLayer, hFar)
+	}
+}
+
+func TestRefine02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Refine02. directional indicators")
+
+	// u = x² + 3⋅y  ⇒  slopes along x = a + b on intervals [a, b]; jumps = 0.3, 0.5, 0.7; η_y = 0
+	g := new(gm.Grid)
+	g.RectSet2d([]float64{0, 0.1, 0.3, 0.6, 1.0}, []float64{0, 0.5, 1})
+	u := make([]float64, g.Size())
+	for I := 0; I < g.Size(); I++ {
+		x := g.Node(I)
+		u[I] = x[0]*x[0] + 3*x[1]
+	}
+	eta := DirectionalIndicators(g, u)
+	chk.Array(tst, "η_x", 1e-14, eta[0], []float64{0.1 * 0.3, 0.2 * 0.5, 0.3 * 0.7, 0.4 * 0.7})
+	chk.Array(tst, "η_y", 1e-14, eta[1], []float64{0, 0})
+}
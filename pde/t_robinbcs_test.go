@@ -0,0 +1,48 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/gm"
+)
+
+func TestRobinBcs01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("RobinBcs01. set in grid")
+
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{3, 3})
+	r := NewRobinBcsGrid(g)
+	r.SetInGrid(11, 1, 2, 3)
+	r.SetInGrid(21, 4, 5, 6)
+	r.SetInGrid(11, 7, 8, 9) // replaces previous
+
+	// nodes
+	for I := 0; I < g.Size(); I++ {
+		onEdge := I == 2 || I == 5 || I >= 6
+		if r.Has(I) != onEdge {
+			tst.Errorf("Has(%d) should be %v\n", I, onEdge)
+		}
+	}
+
+	// terms at corner
+	var dims []int
+	var coefs []float64
+	r.terms(8, func(dim int, item *robinItem) {
+		x := g.Node(8)
+		dims = append(dims, dim)
+		coefs = append(coefs, item.a(x, 0), item.b(x, 0), item.c(x, 0))
+	})
+	chk.Ints(tst, "dims @ 8", dims, []int{0, 1})
+	chk.Array(tst, "coefs @ 8", 1e-15, coefs, []float64{7, 8, 9, 4, 5, 6})
+
+	// invalid tag
+	defer chk.RecoverTstPanicIsOK(tst)
+	r.SetInGrid(30, 1, 1, 1)
+}
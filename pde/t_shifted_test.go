@@ -0,0 +1,234 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+func TestShifted01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Shifted01. shifted operator A - σM")
+
+	// grid and operator
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{7, 5})
+	op := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1.5}, {N: "ky", V: 0.5}}, g, nil)
+	op.AddEbc(10, 0.0, nil)
+	op.AddEbc(21, 1.0, nil)
+
+	// shifted operators before assembling the Laplacian
+	σ := 2.5
+	aI := AssembleShifted(op, σ, "identity").ToDense()
+	aM := AssembleShifted(op, σ, "lumped").ToDense()
+
+	// Laplacian and mass
+	op.Assemble(false)
+	L := op.Eqs.Auu.ToDense()
+	w := GridQuadrature(g)
+	nu := op.Eqs.Nu
+	chk.Int(tst, "nrows", aI.M, nu)
+
+	// diagonals
+	dI, dIcor := make([]float64, nu), make([]float64, nu)
+	dM, dMcor := make([]float64, nu), make([]float64, nu)
+	for i, I := range op.Eqs.UtoF {
+		dI[i], dIcor[i] = aI.Get(i, i), L.Get(i, i)-σ
+		dM[i], dMcor[i] = aM.Get(i, i), L.Get(i, i)-σ*w[I]
+	}
+	io.Pforan("diag(A - σI) = %v\n", dI)
+	chk.Array(tst, "diag(A - σI)", 1e-14, dI, dIcor)
+	chk.Array(tst, "diag(A - σM)", 1e-14, dM, dMcor)
+
+	// off-diagonal entries
+	for i := 0; i < nu; i++ {
+		for j := 0; j < nu; j++ {
+			if i != j {
+				chk.Float64(tst, io.Sf("aI[%d,%d]", i, j), 1e-15, aI.Get(i, j), L.Get(i, j))
+				chk.Float64(tst, io.Sf("aM[%d,%d]", i, j), 1e-15, aM.Get(i, j), L.Get(i, j))
+			}
+		}
+	}
+}
+
+func TestShifted02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Shifted02. panic on mass kind")
+
+	defer chk.RecoverTstPanicIsOK(tst)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{3, 3})
+	op := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	AssembleShifted(op, 1, "diagonal")
+}
+
+func TestShifted03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Shifted03. consistent mass matrix")
+
+	// grid and operator
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{6, 4})
+	op := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	op.AddEbc(10, 0.0, nil)
+	M := op.AssembleMassConsistent().ToDense()
+	nn := g.Size()
+
+	// row sums equal the lumped mass
+	w := GridQuadrature(g)
+	sums := make([]float64, nn)
+	for I := 0; I < nn; I++ {
+		for J := 0; J < nn; J++ {
+			sums[I] += M.Get(I, J)
+		}
+	}
+	io.Pforan("row sums = %v\n", sums)
+	chk.Array(tst, "row sums", 1e-15, sums, w)
+
+	// symmetric positive-definite
+	chk.Deep2(tst, "M = Mᵀ", 1e-17, M.GetDeep2(), M.GetTranspose().GetDeep2())
+	x := la.NewVector(nn)
+	if err := la.SolveRealLinSysSPD(x, M, la.NewVectorSlice(w)); err != nil {
+		tst.Errorf("mass matrix should be positive-definite: %v\n", err)
+		return
+	}
+	chk.Array(tst, "M⁻¹⋅w", 1e-13, x, la.NewVectorSlice(utl.Ones(nn)))
+
+	// entries (uniform grid): (h/6)⋅(h/6) at the corners of the 9-point stencil
+	hx, hy := 0.4, 1.0/3.0
+	I := g.IndexMNPtoI(2, 1, 0)
+	chk.Float64(tst, "M[I][I]", 1e-15, M.Get(I, I), (2*hx/3)*(2*hy/3))
+	chk.Float64(tst, "M[I][I+nx+1]", 1e-15, M.Get(I, I+7), (hx/6)*(hy/6))
+	chk.Float64(tst, "M[I][I+2]", 1e-15, M.Get(I, I+2), 0)
+
+	// shifted operator
+	σ := 1.5
+	aC := AssembleShifted(op, σ, "consistent").ToDense()
+	aI := AssembleShifted(op, 0, "identity").ToDense()
+	for i, I := range op.Eqs.UtoF {
+		for j, J := range op.Eqs.UtoF {
+			chk.Float64(tst, io.Sf("aC[%d,%d]", i, j), 1e-15, aC.Get(i, j), aI.Get(i, j)-σ*M.Get(I, J))
+		}
+	}
+}
+
+func TestShifted04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Shifted04. spectrum of small operators")
+
+	// Laplacian with homogeneous Dirichlet conditions (interior nodes only)
+	nx, ny := 7, 6
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{2, 1}, []int{nx, ny})
+	op := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	op.SetHbc()
+	op.Assemble(false)
+	lambda, err := OperatorSpectrum(op.Eqs)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	chk.Int(tst, "number of eigenvalues", len(lambda), (nx-2)*(ny-2))
+
+	// analytic discrete eigenvalues
+	var ana []float64
+	for m := 1; m < nx-1; m++ {
+		for n := 1; n < ny-1; n++ {
+			λ, _ := LaplaceEigenmode(g, m, n)
+			ana = append(ana, λ)
+		}
+	}
+	sort.Float64s(ana)
+	re, im := make([]float64, len(lambda)), make([]float64, len(lambda))
+	for i, λ := range lambda {
+		re[i], im[i] = real(λ), imag(λ)
+	}
+	io.Pforan("λ = %v\n", re)
+	chk.Array(tst, "λ", 1e-12, re, ana)
+	chk.Array(tst, "imag(λ)", 1e-12, im, nil)
+	if re[len(re)-1] >= 0 {
+		tst.Errorf("all eigenvalues must be negative. max(λ) = %g\n", re[len(re)-1])
+	}
+
+	// natural conditions on all edges: non-symmetric matrix with real eigenvalues and λmax = 0
+	op = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	op.Assemble(false)
+	lambda, _ = OperatorSpectrum(op.Eqs)
+	chk.Int(tst, "number of eigenvalues (natural)", len(lambda), nx*ny)
+	for _, λ := range lambda {
+		chk.Float64(tst, "imag(λ) (natural)", 1e-10, imag(λ), 0)
+	}
+	chk.Float64(tst, "max(λ) (natural)", 1e-10, real(lambda[len(lambda)-1]), 0)
+	if real(lambda[len(lambda)-2]) >= -1e-10 {
+		tst.Errorf("the zero eigenvalue must be simple. λ = %v\n", lambda[len(lambda)-2])
+	}
+
+	// size limit
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{46, 46})
+	op = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	op.Assemble(false)
+	lambda, err = OperatorSpectrum(op.Eqs)
+	if _, ok := err.(*DenseLimitError); !ok || lambda != nil {
+		tst.Errorf("OperatorSpectrum should return a DenseLimitError for Nu = %d\n", op.Eqs.Nu)
+	}
+}
+
+func TestShifted05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Shifted05. lumped mass matrix on stretched grid")
+
+	// grid and operator
+	g := new(gm.Grid)
+	g.RectSet2d([]float64{0, 0.1, 0.3, 0.7, 1.5}, []float64{-1, 0, 0.5, 2})
+	op := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	op.AddEbc(10, 0.0, nil)
+	M := op.AssembleMassLumped().ToDense()
+	nn := g.Size()
+
+	// diagonal equals the control volumes; sum equals the area
+	area := 0.0
+	for I := 0; I < nn; I++ {
+		chk.Float64(tst, io.Sf("M[%d][%d]", I, I), 1e-15, M.Get(I, I), g.ControlVolume(I))
+		for J := 0; J < nn; J++ {
+			if J != I && M.Get(I, J) != 0 {
+				tst.Errorf("lumped mass matrix must be diagonal. M[%d][%d] = %g\n", I, J, M.Get(I, J))
+			}
+		}
+		area += M.Get(I, I)
+	}
+	io.Pforan("ΣM = %v\n", area)
+	chk.Float64(tst, "ΣM", 1e-14, area, 1.5*3)
+
+	// row sums of the consistent mass matrix
+	Mc := op.AssembleMassConsistent().ToDense()
+	for I := 0; I < nn; I++ {
+		sum := 0.0
+		for J := 0; J < nn; J++ {
+			sum += Mc.Get(I, J)
+		}
+		chk.Float64(tst, io.Sf("Σ_J Mc[%d][J]", I), 1e-15, sum, M.Get(I, I))
+	}
+
+	// shifted operator
+	σ := 1.5
+	aL := AssembleShifted(op, σ, "lumped").ToDense()
+	aI := AssembleShifted(op, 0, "identity").ToDense()
+	for i, I := range op.Eqs.UtoF {
+		chk.Float64(tst, io.Sf("aL[%d,%d]", i, i), 1e-13, aL.Get(i, i), aI.Get(i, i)-σ*g.ControlVolume(I))
+	}
+}
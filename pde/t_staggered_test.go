@@ -0,0 +1,137 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/rnd"
+)
+
+func TestStaggered01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Staggered01. gradient and divergence are negative adjoints")
+
+	// grid and operators as matrices (applied to unit vectors)
+	sg := gm.NewStaggeredGrid([]float64{0, 0}, []float64{2, 1}, 4, 3)
+	nc, nu, nv := sg.Ncells(), sg.NfacesX(), sg.NfacesY()
+	G := la.NewMatrix(nu+nv, nc) // gradient
+	D := la.NewMatrix(nc, nu+nv) // divergence
+	for k := 0; k < nc; k++ {
+		p := make([]float64, nc)
+		p[k] = 1
+		gx, gy := StaggeredGradient(sg, p)
+		for i, val := range append(gx, gy...) {
+			G.Set(i, k, val)
+		}
+	}
+	for k := 0; k < nu+nv; k++ {
+		w := make([]float64, nu+nv)
+		w[k] = 1
+		for i, val := range StaggeredDivergence(sg, w[:nu], w[nu:]) {
+			D.Set(i, k, val)
+		}
+	}
+
+	// div = -gradᵀ at interior faces; boundary faces have zero gradient
+	boundary := func(k int) bool {
+		if k < nu {
+			i := k % (sg.Nx() + 1)
+			return i == 0 || i == sg.Nx()
+		}
+		j := (k - nu) / sg.Nx()
+		return j == 0 || j == sg.Ny()
+	}
+	for k := 0; k < nu+nv; k++ {
+		for i := 0; i < nc; i++ {
+			if boundary(k) {
+				chk.Float64(tst, io.Sf("G[%d][%d]", k, i), 1e-15, G.Get(k, i), 0)
+			} else {
+				chk.Float64(tst, io.Sf("D[%d][%d]", i, k), 1e-15, D.Get(i, k), -G.Get(k, i))
+			}
+		}
+	}
+
+	// inner products with random fields (zero normal components at boundary)
+	rnd.Init(1357)
+	p := make([]float64, nc)
+	w := make([]float64, nu+nv)
+	rnd.Float64s(p, -1, 1)
+	rnd.Float64s(w, -1, 1)
+	for k := range w {
+		if boundary(k) {
+			w[k] = 0
+		}
+	}
+	div := StaggeredDivergence(sg, w[:nu], w[nu:])
+	gx, gy := StaggeredGradient(sg, p)
+	lhs := la.VecDot(p, div)
+	rhs := -la.VecDot(gx, w[:nu]) - la.VecDot(gy, w[nu:])
+	io.Pforan("⟨p, div w⟩ = %v, -⟨grad p, w⟩ = %v\n", lhs, rhs)
+	chk.Float64(tst, "⟨p, div w⟩", 1e-13, lhs, rhs)
+
+	// wrong size
+	defer chk.RecoverTstPanicIsOK(tst)
+	StaggeredDivergence(sg, w, w)
+}
+
+func TestStaggered02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Staggered02. pressure projection")
+
+	// intermediate velocity on [0,1]²: u* = sin(πx)⋅y (zero at left and right) and v* = x⋅y at
+	// interior faces (zero at bottom and top) ⇒ zero net flux through the boundary
+	sg := gm.NewStaggeredGrid([]float64{0, 0}, []float64{1, 1}, 16, 12)
+	u := make([]float64, sg.NfacesX())
+	v := make([]float64, sg.NfacesY())
+	for j := 0; j < sg.Ny(); j++ {
+		for i := 0; i <= sg.Nx(); i++ {
+			x := sg.FaceXcentre(i, j)
+			u[sg.FaceXindex(i, j)] = math.Sin(math.Pi*x[0]) * x[1]
+		}
+	}
+	for j := 1; j < sg.Ny(); j++ { // v = 0 at bottom and top
+		for i := 0; i < sg.Nx(); i++ {
+			x := sg.FaceYcentre(i, j)
+			v[sg.FaceYindex(i, j)] = x[0] * x[1]
+		}
+	}
+	div0 := StaggeredDivergence(sg, u, v)
+	io.Pforan("max |div(w*)| = %g\n", la.Vector(div0).Largest(1))
+	u0 := make([]float64, len(u))
+	copy(u0, u)
+
+	// project
+	dt := 0.1
+	p := ProjectDivergenceFree(sg, u, v, dt)
+	div := StaggeredDivergence(sg, u, v)
+	io.Pforan("max |div(w)|  = %g\n", la.Vector(div).Largest(1))
+	chk.Array(tst, "div(w)", 1e-11, div, nil)
+	chk.Float64(tst, "p[0]", 1e-15, p[0], 0)
+
+	// boundary values are not changed
+	for j := 0; j < sg.Ny(); j++ {
+		for _, i := range []int{0, sg.Nx()} {
+			k := sg.FaceXindex(i, j)
+			chk.Float64(tst, "u @ boundary", 1e-15, u[k], u0[k])
+		}
+	}
+
+	// projection of a divergence-free field does not change it
+	u1 := make([]float64, len(u))
+	v1 := make([]float64, len(v))
+	copy(u1, u)
+	copy(v1, v)
+	ProjectDivergenceFree(sg, u1, v1, dt)
+	chk.Array(tst, "u (idempotent)", 1e-11, u1, u)
+	chk.Array(tst, "v (idempotent)", 1e-11, v1, v)
+}
@@ -0,0 +1,505 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun/dbf"
+	"github.com/cpmech/gosl/gm"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+func TestTransient01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Transient01. heat equation (1D strip). Crank-Nicolson")
+
+	// solve problem
+	//    ∂u     ∂²u
+	//    —— =  ———     with   u(0,t) = u(1,t) = 0   and   u(x,0) = sin(πx)
+	//    ∂t     ∂x²
+	//
+	//  solution: u(x,t) = exp(-π²t)⋅sin(πx)
+
+	// 21x3 grid; top and bottom edges are mirrored ⇒ 1D problem
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 0.1}, []int{21, 3})
+
+	// operator and solver
+	op := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	op.AddEbc(10, 0.0, nil)
+	op.AddEbc(11, 0.0, nil)
+	sol := NewFdmTransientSolver(op, 0.5, func(x la.Vector, t float64) float64 { return math.Sin(math.Pi * x[0]) })
+	defer sol.Free()
+
+	// solve
+	tf := 0.1
+	sol.Solve(tf, 0.005)
+	chk.Float64(tst, "time", 1e-15, sol.Time, tf)
+	chk.Int(tst, "nsteps", len(sol.Times), 20)
+	for I := 0; I < g.Size(); I++ {
+		x := g.Node(I)
+		chk.AnaNum(tst, io.Sf("u @ %d", I), 2e-3, sol.U[I], math.Exp(-math.Pi*math.Pi*tf)*math.Sin(math.Pi*x[0]), chk.Verbose)
+	}
+
+	// the round-off errors of the accumulated time do not cause a tiny last step
+	sol.Solve(1.1, 0.1)
+	chk.Float64(tst, "time", 1e-14, sol.Time, 1.1)
+	chk.Int(tst, "nsteps", len(sol.Times), 30)
+	chk.Array(tst, "Δt", 1e-15, sol.DtHist[20:], utl.Vals(10, 0.1))
+}
+
+func TestTransient02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Transient02. adaptive time stepping with fast initial transient")
+
+	// same problem as in Transient01 with u(x,0) = sin(πx) + sin(8πx)
+	//  solution: u(x,t) = exp(-π²t)⋅sin(πx) + exp(-64π²t)⋅sin(8πx)
+
+	// 41x3 grid
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 0.1}, []int{41, 3})
+
+	// operator and solver
+	op := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	op.AddEbc(10, 0.0, nil)
+	op.AddEbc(11, 0.0, nil)
+	sol := NewFdmTransientSolver(op, 1.0, func(x la.Vector, t float64) float64 {
+		return math.Sin(math.Pi*x[0]) + math.Sin(8.0*math.Pi*x[0])
+	})
+	defer sol.Free()
+
+	// solve
+	tf := 0.2
+	sol.SetAdaptive(1e-3, 1e-4, 1e-6, 0.05)
+	sol.Solve(tf, 1e-5)
+	n := len(sol.Times)
+	io.Pforan("nsteps = %d, nrej = %d\n", n, sol.Nrej)
+	io.Pforan("dt: first = %g, last = %g\n", sol.DtHist[0], sol.DtHist[n-2])
+	chk.Float64(tst, "time", 1e-15, sol.Time, tf)
+
+	// step sizes grow as the solution smooths out
+	if sol.DtHist[n-2] < 100*sol.DtHist[0] {
+		tst.Errorf("time step should grow: first = %g, last = %g\n", sol.DtHist[0], sol.DtHist[n-2])
+	}
+
+	// estimated errors are under tolerance
+	for k, err := range sol.ErrEst {
+		if err > 1 {
+			tst.Errorf("estimated error of step %d is greater than tolerance: %g\n", k, err)
+		}
+	}
+
+	// solution
+	for I := 0; I < g.Size(); I++ {
+		x := g.Node(I)
+		ana := math.Exp(-math.Pi*math.Pi*tf)*math.Sin(math.Pi*x[0]) + math.Exp(-64*math.Pi*math.Pi*tf)*math.Sin(8*math.Pi*x[0])
+		chk.AnaNum(tst, io.Sf("u @ %d", I), 5e-3, sol.U[I], ana, chk.Verbose)
+	}
+}
+
+func TestTransient03(tst *testing.T) {
+	//verbose()
+	chk.PrintTitle("Transient03. panic on θ")
+	defer chk.RecoverTstPanicIsOK(tst)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 1}, []int{3, 3})
+	op := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	NewFdmTransientSolver(op, -0.5, nil)
+}
+
+func TestTransient04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Transient04. checkpoints and restart")
+
+	// same problem as in Transient01
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 0.1}, []int{21, 3})
+	newOp := func() (op *FdmLaplacian) {
+		op = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+		op.AddEbc(10, 0.0, nil)
+		op.AddEbc(11, 0.0, nil)
+		return
+	}
+	uIni := func(x la.Vector, t float64) float64 { return math.Sin(math.Pi * x[0]) }
+	dt := 0.01
+
+	// uninterrupted run with 10 steps
+	ref := NewFdmTransientSolver(newOp(), 0.5, uIni)
+	defer ref.Free()
+	for k := 0; k < 10; k++ {
+		ref.Step(dt)
+	}
+
+	// run with checkpoint at step 5; killed after step 7
+	dir := "/tmp/gosl/pde/checkpoints"
+	os.RemoveAll(dir)
+	sol := NewFdmTransientSolver(newOp(), 0.5, uIni)
+	sol.SetCheckpoint(dir, 5)
+	for k := 0; k < 7; k++ {
+		sol.Step(dt)
+	}
+	sol.Free()
+
+	// resume from latest checkpoint and run the remaining steps
+	res := ResumeFdmTransientSolver(newOp(), 0.5, dir)
+	defer res.Free()
+	chk.Int(tst, "nsteps @ checkpoint", res.Nsteps, 5)
+	chk.Float64(tst, "time @ checkpoint", 1e-15, res.Time, 5*dt)
+	for res.Nsteps < 10 {
+		res.Step(dt)
+	}
+	chk.Float64(tst, "final time", 1e-15, res.Time, ref.Time)
+	chk.Array(tst, "final state", 1e-15, res.U, ref.U)
+
+	// missing checkpoints
+	defer chk.RecoverTstPanicIsOK(tst)
+	ResumeFdmTransientSolver(newOp(), 0.5, "/tmp/gosl/pde/no-checkpoints-here")
+}
+
+func TestTransient05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Transient05. periodic steady state of forced heat equation (1D strip)")
+
+	// solve problem
+	//    ∂u     ∂²u
+	//    —— =  ——— - s   with   s = -sin(πx)⋅cos(ωt)   and   u(0,t) = u(1,t) = 0
+	//    ∂t     ∂x²
+	//
+	//  periodic solution: u(x,t) = a(t)⋅sin(πx) with
+	//
+	//           π²⋅cos(ωt) + ω⋅sin(ωt)
+	//    a(t) = ——————————————————————
+	//                  π⁴ + ω²
+	//
+	//  sin(πx) is also an eigenvector of the discrete operator with eigenvalue -λh, where
+	//  λh = 4/h²⋅sin²(πh/2) ≈ π²(1 - π²h²/12); thus, the periodic solution of the semi-discrete
+	//  problem is given by a(t) with π² replaced by λh. The remaining error is due to the time
+	//  discretisation (Crank-Nicolson) only; whereas the spatial error (≈ 3.5e-5 with h = 1/40)
+	//  would dominate the comparison with the exact solution
+	ω := 2.0 * math.Pi
+	period := 2.0 * math.Pi / ω
+	h := 1.0 / 40.0
+	λh := 4.0 / (h * h) * math.Pow(math.Sin(math.Pi*h/2), 2)
+	a := func(t float64) float64 { return (λh*math.Cos(ω*t) + ω*math.Sin(ω*t)) / (λh*λh + ω*ω) }
+
+	// 41x3 grid
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 0.1}, []int{41, 3})
+
+	// operator and solver (initial state far from the periodic state)
+	source := func(x la.Vector, t float64) float64 { return -math.Sin(math.Pi*x[0]) * math.Cos(ω*t) }
+	op := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, source)
+	op.AddEbc(10, 0.0, nil)
+	op.AddEbc(11, 0.0, nil)
+	sol := NewFdmTransientSolver(op, 0.5, func(x la.Vector, t float64) float64 { return math.Sin(math.Pi * x[0]) })
+	defer sol.Free()
+
+	// periodic state at t = 0
+	dt := period / 200
+	nit := sol.PeriodicSteadyState(period, dt)
+	io.Pforan("nit = %d\n", nit)
+	chk.Float64(tst, "time", 1e-15, sol.Time, 0)
+	for I := 0; I < g.Size(); I++ {
+		x := g.Node(I)
+		chk.AnaNum(tst, io.Sf("u(t=0) @ %d", I), 5e-6, sol.U[I], a(0)*math.Sin(math.Pi*x[0]), chk.Verbose)
+	}
+
+	// marching one period recovers the same state
+	u0 := sol.U.GetCopy()
+	sol.Solve(period, dt)
+	chk.Array(tst, "u(T) == u(0)", 1e-11, sol.U, u0)
+
+	// periodic state at another time
+	sol.Solve(1.25*period, dt)
+	sol.PeriodicSteadyState(period, dt)
+	u1 := sol.U.GetCopy()
+	for I := 0; I < g.Size(); I++ {
+		x := g.Node(I)
+		chk.AnaNum(tst, io.Sf("u(t=T/4) @ %d", I), 5e-6, u1[I], a(sol.Time)*math.Sin(math.Pi*x[0]), chk.Verbose)
+	}
+}
+
+func TestTransient06(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Transient06. time history of adaptive run")
+
+	// 21x3 grid
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 0.1}, []int{21, 3})
+
+	// operator and solver
+	op := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	op.AddEbc(10, 0.0, nil)
+	op.AddEbc(11, 0.0, nil)
+	sol := NewFdmTransientSolver(op, 0.5, func(x la.Vector, t float64) float64 {
+		return math.Sin(math.Pi*x[0]) + math.Sin(4.0*math.Pi*x[0])
+	})
+	defer sol.Free()
+
+	// empty history
+	if sol.TimeHistory() != nil {
+		tst.Errorf("history should be empty before solving\n")
+		return
+	}
+
+	// solve in two stages
+	tf := 0.1
+	sol.SetAdaptive(1e-4, 1e-5, 1e-7, 0.02)
+	sol.Solve(tf/2, 1e-4)
+	sol.Solve(tf, sol.DtHist[len(sol.DtHist)-1])
+	times := sol.TimeHistory()
+	n := len(sol.Times)
+	io.Pforan("nsteps = %d, nrej = %d\n", n, sol.Nrej)
+	chk.Int(tst, "len(times)", len(times), n+1)
+	chk.Int(tst, "len(DtHist)", len(sol.DtHist), n)
+	chk.Int(tst, "len(Nsolve)", len(sol.Nsolve), n)
+
+	// times increase monotonically, consistently with the step sizes, and end at tf
+	chk.Float64(tst, "t0", 1e-15, times[0], 0)
+	chk.Float64(tst, "tf", 1e-15, times[n], tf)
+	for k := 0; k < n; k++ {
+		if times[k+1] <= times[k] {
+			tst.Errorf("times must increase monotonically: t[%d] = %g, t[%d] = %g\n", k, times[k], k+1, times[k+1])
+			return
+		}
+		chk.Float64(tst, io.Sf("Δt[%d]", k), 1e-15, times[k+1]-times[k], sol.DtHist[k])
+	}
+
+	// number of linear solves: three per attempt
+	nsol := 0
+	for k, ns := range sol.Nsolve {
+		if ns < 3 || ns%3 != 0 {
+			tst.Errorf("number of linear solves of step %d is invalid: %d\n", k, ns)
+		}
+		nsol += ns
+	}
+	chk.Int(tst, "total linear solves", nsol, 3*(n+sol.Nrej))
+}
+
+func TestTransient07(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Transient07. Strang splitting of reaction-diffusion")
+
+	// ∂u/∂t = ∇²u - k(x)⋅u   with u = 1 @ x = 0 and u = 0 @ x = 1
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 0.1}, []int{21, 3})
+	k := func(x la.Vector, t float64) float64 { return 2 + 6*x[0] }
+	uIni := func(x la.Vector, t float64) float64 { return 1 - x[0] + math.Sin(math.Pi*x[0]) }
+	newOp := func() (op *FdmLaplacian) {
+		op = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+		op.AddEbc(10, 1.0, nil)
+		op.AddEbc(11, 0.0, nil)
+		return
+	}
+
+	// reference: unsplit Crank-Nicolson with small Δt
+	tf := 0.2
+	opRef := newOp()
+	opRef.Reaction = k
+	ref := NewFdmTransientSolver(opRef, 0.5, uIni)
+	defer ref.Free()
+	ref.Solve(tf, 1e-4)
+
+	// exact reaction sub-step: u := u⋅exp(-k⋅Δt)
+	react := func(u la.Vector, t, dt float64) {
+		for I := range u {
+			u[I] *= math.Exp(-k(g.Node(I), t) * dt)
+		}
+	}
+
+	// splitting with decreasing Δt
+	var errs []float64
+	for _, dt := range []float64{0.04, 0.02, 0.01} {
+		sol := NewFdmTransientSolver(newOp(), 0.5, uIni)
+		for sol.Time < tf-1e-12 {
+			sol.SplitStep(dt, react)
+		}
+		chk.Float64(tst, "u @ x = 0", 1e-15, sol.U[0], 1)
+		chk.Float64(tst, "u @ x = 1", 1e-15, sol.U[20], 0)
+		err, _, _ := CompareSolutions(sol.U, ref.U, g)
+		io.Pforan("Δt = %g: error = %g\n", dt, err)
+		errs = append(errs, err)
+		sol.Free()
+	}
+	chk.Float64(tst, "ratio 1 (second order)", 0.3, errs[0]/errs[1], 4)
+	chk.Float64(tst, "ratio 2 (second order)", 0.3, errs[1]/errs[2], 4)
+}
+
+func TestTransient08(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Transient08. forward Euler with sub-steps to satisfy the stability limit")
+
+	// same problem as in Transient01 with u(x,0) = sin(πx) + 10⁻³⋅sin(19πx)
+	//  solution: u(x,t) = exp(-π²t)⋅sin(πx) + 10⁻³⋅exp(-361π²t)⋅sin(19πx)
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 0.1}, []int{21, 3})
+	op := NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+	op.AddEbc(10, 0.0, nil)
+	op.AddEbc(11, 0.0, nil)
+	uIni := func(x la.Vector, t float64) float64 { return math.Sin(math.Pi*x[0]) + 1e-3*math.Sin(19*math.Pi*x[0]) }
+	uAna := func(x la.Vector, t float64) float64 { return math.Exp(-math.Pi*math.Pi*t) * math.Sin(math.Pi*x[0]) }
+
+	// Gershgorin bound: 4/Δx² + 4/Δy² (the mirrored rows of the top and bottom edges have 2/Δy²)
+	dx, dy := 1.0/20.0, 0.1/2.0
+	ρ := 4.0/(dx*dx) + 4.0/(dy*dy)
+	dtCrit := 2.0 / ρ
+
+	// large time step without sub-steps: the high-frequency mode is amplified
+	tf, dt := 0.1, 0.02
+	sol := NewFdmTransientSolver(op, 0, uIni)
+	defer sol.Free()
+	sol.Solve(tf, dt)
+	io.Pforan("without sub-steps: max|u| = %g\n", sol.U.Largest(1))
+	if sol.U.Largest(1) < 1e3 {
+		tst.Errorf("forward Euler with Δt = %g ≫ %g should be unstable\n", dt, dtCrit)
+	}
+
+	// with sub-steps
+	sol2 := NewFdmTransientSolver(op, 0, uIni)
+	defer sol2.Free()
+	sol2.EnforceStability(true)
+	sol2.Solve(tf, dt)
+	nsub := int(math.Ceil(dt / dtCrit))
+	io.Pforan("with sub-steps: Δt_crit = %g, Nsub = %d, max|u| = %g\n", dtCrit, sol2.Nsub, sol2.U.Largest(1))
+	chk.Float64(tst, "time", 1e-15, sol2.Time, tf)
+	chk.Int(tst, "nsteps", len(sol2.Times), 5)
+	chk.Int(tst, "Nsub", sol2.Nsub, nsub)
+	chk.Ints(tst, "Nsolve", sol2.Nsolve, []int{nsub, nsub, nsub, nsub, nsub})
+	for I := 0; I < g.Size(); I++ {
+		x := g.Node(I)
+		chk.AnaNum(tst, io.Sf("u @ %d", I), 2e-3, sol2.U[I], uAna(x, tf), chk.Verbose)
+	}
+
+	// θ ≥ ½: no sub-steps
+	sol3 := NewFdmTransientSolver(op, 0.5, uIni)
+	defer sol3.Free()
+	sol3.EnforceStability(true)
+	sol3.Step(dt)
+	chk.Int(tst, "Nsub (Crank-Nicolson)", sol3.Nsub, 1)
+}
+
+func TestTransient09(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Transient09. stochastic forcing with seeded random numbers")
+
+	// same problem as in Transient01 with random forcing
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 0.1}, []int{21, 3})
+	newOp := func() (op *FdmLaplacian) {
+		op = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, nil)
+		op.AddEbc(10, 0.0, nil)
+		op.AddEbc(11, 0.0, nil)
+		return
+	}
+	uIni := func(x la.Vector, t float64) float64 { return math.Sin(math.Pi * x[0]) }
+	amp, dt, nsteps := 0.1, 0.01, 10
+	run := func(seed int64) (traj [][]float64) {
+		sol := NewFdmTransientSolver(newOp(), 0.5, uIni)
+		defer sol.Free()
+		sol.SetStochasticForcing(amp, seed)
+		for k := 0; k < nsteps; k++ {
+			sol.Step(dt)
+			traj = append(traj, sol.U.GetCopy())
+		}
+		return
+	}
+
+	// same seed: identical trajectories
+	traj1, traj2 := run(1234), run(1234)
+	for k := 0; k < nsteps; k++ {
+		chk.Array(tst, io.Sf("u @ step %d", k), 1e-15, traj2[k], traj1[k])
+	}
+
+	// different seed: different trajectories
+	traj3 := run(4321)
+	diff := 0.0
+	for I := range traj1[nsteps-1] {
+		diff = math.Max(diff, math.Abs(traj3[nsteps-1][I]-traj1[nsteps-1][I]))
+	}
+	io.Pforan("max|u(seed=4321) - u(seed=1234)| = %g\n", diff)
+	if diff < 1e-3 {
+		tst.Errorf("trajectories with different seeds should differ\n")
+	}
+
+	// run with checkpoints every 4 steps; killed after step 9
+	dir := "/tmp/gosl/pde/checkpoints-stochastic"
+	os.RemoveAll(dir)
+	sol := NewFdmTransientSolver(newOp(), 0.5, uIni)
+	sol.SetStochasticForcing(amp, 1234)
+	sol.SetCheckpoint(dir, 4)
+	for k := 0; k < 9; k++ {
+		sol.Step(dt)
+	}
+	sol.Free()
+
+	// resume: the random numbers continue from the checkpoint
+	res := ResumeFdmTransientSolver(newOp(), 0.5, dir)
+	defer res.Free()
+	chk.Int(tst, "nsteps @ checkpoint", res.Nsteps, 8)
+	for res.Nsteps < nsteps {
+		res.Step(dt)
+	}
+	chk.Array(tst, "final state", 1e-15, res.U, traj1[nsteps-1])
+
+	// adaptive stepping is not available
+	defer chk.RecoverTstPanicIsOK(tst)
+	sol4 := NewFdmTransientSolver(newOp(), 0.5, uIni)
+	defer sol4.Free()
+	sol4.SetStochasticForcing(amp, 1234)
+	sol4.SetAdaptive(1e-3, 1e-6, 1e-4, 0.1)
+	sol4.Solve(0.1, dt)
+}
+
+func TestTransient10(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Transient10. long-time solution equals the steady solution")
+
+	// solve problem
+	//    ∂u     ∂²u
+	//    —— =  ——— - s   with   s = -2,   u(0,t) = 0,   ∂u/∂x(1,t) = 1   and   u(x,0) = 0
+	//    ∂t     ∂x²
+	//
+	//  steady solution (SolveSteady: ∂²u/∂x² = s): u(x) = 3x - x² (exact with FDM)
+
+	// 21x3 grid
+	g := new(gm.Grid)
+	g.RectGenUniform([]float64{0, 0}, []float64{1, 0.1}, []int{21, 3})
+	newOp := func() (op *FdmLaplacian) {
+		op = NewFdmLaplacian(dbf.Params{{N: "kx", V: 1}, {N: "ky", V: 1}}, g, func(x la.Vector, t float64) float64 { return -2 })
+		op.AddEbc(10, 0.0, nil)
+		op.AddNbc(11, 1.0, nil)
+		return
+	}
+
+	// steady solution
+	op := newOp()
+	op.Assemble(false)
+	uSteady, _ := op.SolveSteady(false)
+	for I := 0; I < g.Size(); I++ {
+		x := g.Node(I)
+		chk.AnaNum(tst, io.Sf("u(steady) @ %d", I), 1e-12, uSteady[I], 3*x[0]-x[0]*x[0], chk.Verbose)
+	}
+
+	// transient solution (backward Euler) after a long time
+	sol := NewFdmTransientSolver(newOp(), 1, nil)
+	defer sol.Free()
+	sol.Solve(30, 0.5)
+	chk.Array(tst, "u(t=30) == u(steady)", 1e-12, sol.U, uSteady)
+}
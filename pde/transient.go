@@ -0,0 +1,649 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pde
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// FdmTransientSolver solves transient problems with the FDM Laplacian operator using the θ-method
+//
+//    ∂u
+//    ——  =  L{u} - s({x},t)      with     u({x},0) = u0({x})
+//    ∂t
+//
+//  The source term has the same sign as in FdmLaplacian.SolveSteady (L{u} = s); thus, the transient
+//  solution tends to the steady one if the source and boundary conditions do not depend on time.
+//  Note that a heat supply q must then be given as s = -q. The natural and Robin boundary
+//  conditions enter the right-hand side in the same way as in SolveSteady.
+//
+//  Time discretisation (θ = 1 ⇒ backward Euler; θ = ½ ⇒ Crank-Nicolson; θ = 0 ⇒ forward Euler):
+//
+//    u⁽ⁿ⁺¹⁾ - u⁽ⁿ⁾
+//    ————————————— = θ ⋅ (L{u⁽ⁿ⁺¹⁾} - s⁽ⁿ⁺¹⁾) + (1-θ) ⋅ (L{u⁽ⁿ⁾} - s⁽ⁿ⁾)
+//         Δt
+//
+//  The essential boundary conditions of the operator may depend on time.
+//
+//  Adaptive time stepping (see SetAdaptive): each step is computed with one full step and two half
+//  steps; the difference between both estimates the local truncation error. The step is accepted if
+//  the scaled RMS error (see la.VecRmsError) is smaller than one and the next Δt is computed from
+//
+//    Δt_new = Δt ⋅ min(5, max(0.2, 0.9 ⋅ err^(-1/(p+1))))     with  Δt_min ≤ Δt_new ≤ Δt_max
+//
+//  where p is the order of the method (p = 2 if θ = ½; p = 1 otherwise).
+//
+//  The method is unconditionally stable if θ ≥ ½. Otherwise, Δt ≤ 2/((1-2θ)⋅ρ), where ρ is the
+//  spectral radius of [Auu]; the requested steps may then be sub-divided automatically (see
+//  EnforceStability).
+//
+//  Reaction-diffusion problems may be solved by operator splitting (see SplitStep); i.e. the
+//  θ-method advances the diffusion (and source) terms, whereas the reaction terms are advanced by
+//  a given function; e.g. analytically.
+//
+//  A random forcing term (spatially white noise) may be added to the right-hand side at each step
+//  (see SetStochasticForcing); the random numbers are reproducible (seeded) and restartable.
+//
+//  Checkpoints with the time and the state may be written periodically (see SetCheckpoint); the
+//  solution can then be restarted from the latest checkpoint (see ResumeFdmTransientSolver).
+//
+//  NOTE: remember to call Free() to release allocated resources
+type FdmTransientSolver struct {
+
+	// input
+	Op    *FdmLaplacian // operator (assembled)
+	Theta float64       // θ-method coefficient
+
+	// configuration
+	Logger Logger // logger for messages [may be nil ⇒ LoggerPf]
+
+	// state
+	Time   float64   // current time
+	U      la.Vector // [nnodes] solution at all nodes
+	Nsteps int       // number of accepted steps
+
+	// results
+	Times  []float64 // times at the end of accepted steps
+	DtHist []float64 // [len(Times)] step sizes of accepted steps
+	ErrEst []float64 // [len(Times)] estimated (scaled) errors of accepted steps
+	Nsolve []int     // [len(Times)] linear solves of accepted steps, including the rejected attempts
+	Nrej   int       // number of rejected steps
+	Nsub   int       // number of sub-steps of the latest step (see EnforceStability)
+
+	// adaptive
+	adaptive bool    // use adaptive time stepping
+	rtol     float64 // relative tolerance
+	atol     float64 // absolute tolerance
+	dtMin    float64 // minimum Δt
+	dtMax    float64 // maximum Δt
+
+	// stability
+	stable bool    // sub-divide steps to satisfy the stability limit if θ < ½
+	rho    float64 // Gershgorin bound of the spectral radius of [Auu] [0 ⇒ not computed yet]
+
+	// stochastic forcing
+	noise bool       // add random forcing at each step
+	amp   float64    // amplitude of random forcing
+	seed  int64      // seed of random numbers
+	rng   *rand.Rand // random numbers generator; re-seeded at each step (see stepSeed)
+
+	// checkpoints
+	ckDir   string // directory for checkpoint files
+	ckEvery int    // write checkpoint every ckEvery accepted steps [0 ⇒ no checkpoints]
+
+	// internal
+	auu  *la.CCMatrix // [Nu][Nu] matrix
+	auk  *la.CCMatrix // [Nu][Nk] matrix [may be nil]
+	full *thetaSys    // linear system for full steps
+	half *thetaSys    // linear system for half steps (adaptive)
+	xu   la.Vector    // [Nu] unknown values at beginning of step
+	xk   la.Vector    // [Nk] known values
+	rhs  la.Vector    // [Nu] right-hand side
+	wu   la.Vector    // [Nu] workspace
+	wk   la.Vector    // [Nk] workspace
+	tIni float64      // time at the beginning of the recorded history
+	nsol int          // linear solves since the last recorded step
+}
+
+// ReactionStep advances the reaction sub-problem du/dt = R(u,{x},t) from t to t+dt in place
+//   u -- [nnodes] values at all nodes; the values at nodes with prescribed values are ignored
+type ReactionStep func(u la.Vector, t, dt float64)
+
+// thetaSys holds the factorised matrix [I/Δt - θ⋅Auu] for a given Δt
+type thetaSys struct {
+	dt     float64         // time step corresponding to factorisation
+	mat    *la.Triplet     // matrix
+	solver la.SparseSolver // linear solver
+}
+
+// NewFdmTransientSolver creates a new transient solver
+//   op    -- FDM Laplacian operator with essential boundary conditions already set
+//   theta -- θ-method coefficient; 0 ≤ θ ≤ 1
+//   uIni  -- initial values function u0({x}) [may be nil ⇒ zero]
+//   NOTE: the operator is assembled here
+func NewFdmTransientSolver(op *FdmLaplacian, theta float64, uIni fun.Svs) (o *FdmTransientSolver) {
+
+	// check
+	if theta < 0 || theta > 1 {
+		chk.Panic("θ must be in [0, 1]. θ = %g is invalid\n", theta)
+	}
+
+	if op.Mehrstellen {
+		chk.Panic("FdmTransientSolver does not support the Mehrstellen stencil\n")
+	}
+	op.checkDouble("FdmTransientSolver")
+
+	// data
+	o = new(FdmTransientSolver)
+	o.Op = op
+	o.Theta = theta
+	if err := o.Op.Assemble(false); err != nil {
+		chk.Panic("%v\n", err)
+	}
+	eqs := o.Op.Eqs
+	o.auu = eqs.Auu.ToMatrix(nil)
+	if eqs.Nk > 0 {
+		o.auk = eqs.Auk.ToMatrix(nil)
+	}
+	o.full = new(thetaSys)
+	o.half = new(thetaSys)
+	o.xu = la.NewVector(eqs.Nu)
+	o.xk = la.NewVector(eqs.Nk)
+	o.rhs = la.NewVector(eqs.Nu)
+	o.wu = la.NewVector(eqs.Nu)
+	o.wk = la.NewVector(eqs.Nk)
+
+	// initial values
+	o.U = la.NewVector(op.Grid.Size())
+	if uIni != nil {
+		for I := 0; I < op.Grid.Size(); I++ {
+			o.U[I] = uIni(op.Grid.Node(I), 0)
+		}
+	}
+	for i, I := range eqs.KtoF {
+		o.U[I] = op.calcXk(I, 0)
+		o.xk[i] = o.U[I]
+	}
+	return
+}
+
+// fdmCheckpoint holds the data written to checkpoint files
+type fdmCheckpoint struct {
+	Time   float64   // time
+	Nsteps int       // number of accepted steps
+	U      []float64 // [nnodes] solution at all nodes
+
+	// stochastic forcing (see SetStochasticForcing)
+	Noise bool    // random forcing is set
+	Amp   float64 // amplitude
+	Seed  int64   // seed
+}
+
+// ResumeFdmTransientSolver creates a new transient solver with the state from the latest
+// checkpoint file in a directory (see SetCheckpoint)
+//   op    -- FDM Laplacian operator; the same used in the interrupted run
+//   theta -- θ-method coefficient; 0 ≤ θ ≤ 1
+//   dir   -- directory with checkpoint files
+//   NOTE: (1) the checkpoint settings are not restored; call SetCheckpoint again if needed
+//         (2) the history (Times, DtHist, ErrEst, Nsolve) holds the steps after resuming only
+//         (3) the stochastic forcing is restored with its seed; since the random numbers of each
+//             step depend on the seed and the step number only, the resumed run reproduces the
+//             uninterrupted one without replaying the previous random numbers
+func ResumeFdmTransientSolver(op *FdmLaplacian, theta float64, dir string) (o *FdmTransientSolver) {
+	files, _ := filepath.Glob(filepath.Join(dir, "checkpoint_*.gob"))
+	if len(files) == 0 {
+		chk.Panic("cannot find checkpoint files in directory <%s>\n", dir)
+	}
+	sort.Strings(files) // the latest has the largest step number
+	var ck fdmCheckpoint
+	dec := utl.NewDecoder(bytes.NewReader(io.ReadFile(files[len(files)-1])), "gob")
+	if err := dec.Decode(&ck); err != nil {
+		chk.Panic("cannot decode checkpoint file <%s>:\n%v\n", files[len(files)-1], err)
+	}
+	o = NewFdmTransientSolver(op, theta, nil)
+	if len(ck.U) != len(o.U) {
+		chk.Panic("size of state in checkpoint (%d) is different than the number of nodes (%d)\n", len(ck.U), len(o.U))
+	}
+	o.Time = ck.Time
+	o.Nsteps = ck.Nsteps
+	copy(o.U, ck.U)
+	if ck.Noise {
+		o.SetStochasticForcing(ck.Amp, ck.Seed)
+	}
+	return
+}
+
+// Free releases allocated resources
+func (o *FdmTransientSolver) Free() {
+	o.full.free()
+	o.half.free()
+}
+
+// SetAdaptive sets adaptive time stepping
+//   rtol  -- relative tolerance
+//   atol  -- absolute tolerance
+//   dtMin -- minimum time step
+//   dtMax -- maximum time step
+func (o *FdmTransientSolver) SetAdaptive(rtol, atol, dtMin, dtMax float64) {
+	if rtol < 0 || atol < 0 || rtol+atol <= 0 {
+		chk.Panic("tolerances must be non-negative and not both zero. rtol=%g, atol=%g\n", rtol, atol)
+	}
+	if dtMin <= 0 || dtMax < dtMin {
+		chk.Panic("time step bounds must satisfy 0 < dtMin ≤ dtMax. dtMin=%g, dtMax=%g\n", dtMin, dtMax)
+	}
+	o.adaptive = true
+	o.rtol, o.atol = rtol, atol
+	o.dtMin, o.dtMax = dtMin, dtMax
+}
+
+// EnforceStability sets the automatic sub-division of time steps to satisfy the stability limit
+// of the θ-method with θ < ½ (conditionally stable)
+//
+//   Each requested Δt is divided into n equal sub-steps such that
+//
+//            2                                      nrows
+//    Δt/n ≤ ——————————      with   ρ ≤ ρ_G = max  (  Σ  |A_ij| )    (Gershgorin bound)
+//           (1-2θ)⋅ρ_G                       i       j
+//
+//   The sub-steps are transparent: Step, SplitStep and Solve advance the solution by the
+//   requested Δt and Nsub gives the number of sub-steps of the latest step
+//
+//   enforce -- enable or disable the sub-division; nothing is done if θ ≥ ½
+//   NOTE: (1) the eigenvalues of [Auu] are real and negative (see OperatorSpectrum); thus, the
+//             sub-steps are stable since ρ ≤ ρ_G
+//         (2) the adaptive steps and their error estimates (see SetAdaptive) include the
+//             sub-steps; i.e. the accuracy of the sub-divided steps controls the step size
+func (o *FdmTransientSolver) EnforceStability(enforce bool) {
+	o.stable = enforce
+	if enforce && o.rho == 0 {
+		rows, _, vals := o.auu.Triplets()
+		sums := make([]float64, o.Op.Eqs.Nu)
+		for k, v := range vals {
+			sums[rows[k]] += math.Abs(v)
+		}
+		for _, v := range sums {
+			o.rho = math.Max(o.rho, v)
+		}
+	}
+}
+
+// SetStochasticForcing adds a random forcing term to the right-hand side at each step; e.g. for
+// stochastic PDEs driven by spatially white noise
+//
+//    ∂u                     A
+//    ——  =  L{u} - s({x},t) + —— ⋅ ξ      with   ξ ~ N(0,1)  independent at each node and step
+//    ∂t                    √Δt
+//
+//   Thus, the random increment of each step is A⋅√Δt⋅ξ (Euler-Maruyama). The generator is
+//   re-seeded at each step with a seed derived from the given seed and the step number; the runs
+//   are then reproducible and can be restarted from checkpoint files (see SetCheckpoint)
+//
+//   amplitude -- amplitude A of the noise
+//   seed      -- seed of the random numbers generator
+//   NOTE: (1) the forcing is applied in Step, SplitStep and Solve (fixed time steps); each
+//             (sub-)step draws Nu random numbers
+//         (2) the adaptive time stepping (see SetAdaptive) is not available with stochastic
+//             forcing since the error estimates are meaningless
+func (o *FdmTransientSolver) SetStochasticForcing(amplitude float64, seed int64) {
+	o.noise = true
+	o.amp = amplitude
+	o.seed = seed
+	o.rng = rand.New(rand.NewSource(seed))
+}
+
+// SetCheckpoint sets the periodic writing of checkpoint files with the time and the state
+//   dir         -- directory for checkpoint files; will be created
+//   everyNsteps -- write checkpoint every everyNsteps accepted steps
+//   NOTE: the files are named "checkpoint_<step number>.gob"
+func (o *FdmTransientSolver) SetCheckpoint(dir string, everyNsteps int) {
+	if everyNsteps < 1 {
+		chk.Panic("everyNsteps must be at least 1. everyNsteps=%d is invalid\n", everyNsteps)
+	}
+	o.ckDir = dir
+	o.ckEvery = everyNsteps
+}
+
+// Step advances the solution by one (fixed) time step
+func (o *FdmTransientSolver) Step(dt float64) {
+	o.Op.Eqs.SplitVector(o.xu, o.xk, o.U)
+	o.march(o.full, o.xu, o.Time, dt)
+	o.Time += dt
+	o.Op.Eqs.JoinVector(o.U, o.xu, o.xk)
+	o.accepted()
+}
+
+// SplitStep advances the solution by one (fixed) time step with Strang splitting
+//
+//    u⁽ⁿ⁺¹⁾ = R(Δt/2) ∘ D(Δt) ∘ R(Δt/2) u⁽ⁿ⁾
+//
+//   where D is the θ-method step of ∂u/∂t = L{u} - s and R is the reaction step
+//   react -- reaction sub-step; called twice with Δt/2 (from t and from t+Δt/2)
+//   NOTE: the splitting is second-order accurate if θ = ½ and the reaction step is (at least)
+//         second-order accurate; the prescribed values are restored after the reaction steps
+func (o *FdmTransientSolver) SplitStep(dt float64, react ReactionStep) {
+	eqs := o.Op.Eqs
+	react(o.U, o.Time, dt/2)
+	eqs.SplitVector(o.xu, o.wk, o.U)
+	o.march(o.full, o.xu, o.Time, dt)
+	eqs.JoinVector(o.U, o.xu, o.xk)
+	react(o.U, o.Time+dt/2, dt/2)
+	for i, I := range eqs.KtoF {
+		o.U[I] = o.xk[i]
+	}
+	o.Time += dt
+	o.accepted()
+}
+
+// Solve advances the solution up to time tf
+//   tf -- final time
+//   dt -- time step; or initial time step if adaptive
+//   NOTE: the last step is shortened to reach tf exactly; however, the remainders smaller than
+//         1e-10⋅Δt (round-off errors of the accumulated time) do not cause an extra step
+func (o *FdmTransientSolver) Solve(tf, dt float64) {
+
+	// history
+	if len(o.Times) == 0 {
+		o.tIni = o.Time
+	}
+	o.nsol = 0
+
+	// fixed time steps (the round-off errors of the accumulated time must not cause a tiny last step)
+	if !o.adaptive {
+		nsteps := int(math.Ceil((tf-o.Time)/dt - 1e-10))
+		for k := 0; k < nsteps; k++ {
+			h := dt
+			if k == nsteps-1 && math.Abs(tf-o.Time-dt) > 1e-10*dt {
+				h = tf - o.Time
+			}
+			o.Step(h)
+			logf(o.Logger, "FdmTransientSolver: t = %g, Δt = %g\n", o.Time, h)
+			o.record(h, 0)
+		}
+		return
+	}
+
+	// auxiliary
+	if o.noise {
+		chk.Panic("adaptive time stepping is not available with stochastic forcing\n")
+	}
+	p := 1.0
+	if o.Theta == 0.5 {
+		p = 2.0
+	}
+	eqs := o.Op.Eqs
+	u1 := la.NewVector(eqs.Nu)
+	u2 := la.NewVector(eqs.Nu)
+	h := math.Max(o.dtMin, math.Min(dt, o.dtMax))
+
+	// adaptive time steps
+	for o.Time < tf {
+		last := o.Time+h >= tf-1e-10*h
+		if last {
+			h = tf - o.Time
+		}
+
+		// one full step and two half steps
+		eqs.SplitVector(o.xu, o.xk, o.U)
+		copy(u1, o.xu)
+		copy(u2, o.xu)
+		o.march(o.full, u1, o.Time, h)
+		o.march(o.half, u2, o.Time, h/2)
+		o.march(o.half, u2, o.Time+h/2, h/2)
+		err := la.VecRmsError(u1, u2, o.atol, o.rtol, u2)
+
+		// accept or reject
+		accept := err <= 1 || h <= o.dtMin
+		if accept {
+			o.Time += h
+			eqs.JoinVector(o.U, u2, o.xk)
+			o.accepted()
+			o.record(h, err)
+			logf(o.Logger, "FdmTransientSolver: t = %g, Δt = %g, error = %g\n", o.Time, h, err)
+			if last {
+				break
+			}
+		} else {
+			o.Nrej++
+			logf(o.Logger, "FdmTransientSolver: step rejected: Δt = %g, error = %g\n", h, err)
+		}
+
+		// next step size
+		fac := 5.0
+		if err > 0 {
+			fac = math.Min(5.0, math.Max(0.2, 0.9*math.Pow(err, -1.0/(p+1.0))))
+		}
+		h = math.Max(o.dtMin, math.Min(h*fac, o.dtMax))
+	}
+}
+
+// TimeHistory returns the times recorded by Solve: the time at the beginning of the history
+// followed by the times at the end of the accepted steps; i.e. len(Times)+1 values. The step
+// sizes, error estimates and number of linear solves per step are in DtHist, ErrEst and Nsolve
+//   NOTE: the systems are solved directly (factorised); thus, there are no inner iterations and
+//         Nsolve gives 1 per fixed step and 3 per adaptive attempt (one full and two half steps),
+//         times the number of sub-steps if the stability limit is enforced (see EnforceStability)
+func (o *FdmTransientSolver) TimeHistory() (times []float64) {
+	if len(o.Times) == 0 {
+		return
+	}
+	times = make([]float64, 1+len(o.Times))
+	times[0] = o.tIni
+	copy(times[1:], o.Times)
+	return
+}
+
+// PeriodicSteadyState computes the time-periodic state of a periodically forced problem directly
+// (without marching until the transients decay) by the shooting method
+//
+//   Find {u0} such that  Φ({u0}) = {u0}
+//
+//   where Φ is the monodromy map; i.e. Φ({u0}) gives the solution after one period starting from
+//   {u0} at the current time. Newton's method is applied to {r} = Φ({u0}) - {u0} with the
+//   Jacobian [J] = [M] - [I], where the columns of the monodromy matrix [M] = ∂Φ/∂{u0} are
+//   computed from the responses to unit perturbations of each unknown value
+//
+//   Input:
+//     period -- period T of the source term and boundary conditions
+//     dt     -- time step; adjusted such that T is an integer multiple of Δt
+//   Output:
+//     nit -- number of Newton iterations; U holds the periodic state at the current time
+//
+//   NOTE: (1) the time, number of steps and history are not changed
+//         (2) the one-period map is computed Nu+1 times; thus, this function is intended for
+//             problems with a moderate number of unknowns; e.g. 1D strips
+func (o *FdmTransientSolver) PeriodicSteadyState(period, dt float64) (nit int) {
+
+	// check
+	if period <= 0 || dt <= 0 {
+		chk.Panic("period and time step must be positive. period=%g, dt=%g\n", period, dt)
+	}
+	if o.noise {
+		chk.Panic("the periodic steady state is not available with stochastic forcing\n")
+	}
+	nsteps := int(math.Ceil(period/dt - 1e-10))
+	h := period / float64(nsteps)
+
+	// one-period map
+	eqs := o.Op.Eqs
+	t0 := o.Time
+	phi := func(res, x la.Vector) {
+		copy(res, x)
+		for k := 0; k < nsteps; k++ {
+			o.march(o.full, res, t0+float64(k)*h, h)
+		}
+	}
+
+	// Jacobian: [M] - [I]
+	x := la.NewVector(eqs.Nu)
+	r := la.NewVector(eqs.Nu)
+	eqs.SplitVector(x, o.xk, o.U)
+	phi(r, x)
+	jac := la.NewMatrix(eqs.Nu, eqs.Nu)
+	xp := la.NewVector(eqs.Nu)
+	rp := la.NewVector(eqs.Nu)
+	for j := 0; j < eqs.Nu; j++ {
+		copy(xp, x)
+		xp[j] += 1.0
+		phi(rp, xp)
+		for i := 0; i < eqs.Nu; i++ {
+			jac.Set(i, j, rp[i]-r[i])
+		}
+		jac.Add(j, j, -1)
+	}
+	lu := la.FactorizeLU(jac)
+
+	// Newton iterations
+	δ := la.NewVector(eqs.Nu)
+	for nit = 0; nit < 10; nit++ {
+		la.VecAdd(r, 1, r, -1, x) // r := Φ(x) - x
+		if r.Largest(1) <= 1e-12*(1.0+x.Largest(1)) {
+			break
+		}
+		lu.Solve(δ, r)
+		la.VecAdd(x, 1, x, -1, δ)
+		phi(r, x)
+		logf(o.Logger, "FdmTransientSolver: periodic steady state: iteration %d\n", nit+1)
+	}
+
+	// results
+	for i, I := range eqs.KtoF {
+		o.xk[i] = o.Op.calcXk(I, t0)
+	}
+	eqs.JoinVector(o.U, x, o.xk)
+	return
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// step computes xu := xu⁽ⁿ⁺¹⁾ given xu = xu⁽ⁿ⁾ @ time t. It sets o.xk to the known values @ t+dt
+//
+//   [I/Δt - θ⋅Auu]⋅xu⁽ⁿ⁺¹⁾ = xu⁽ⁿ⁾/Δt + (1-θ)⋅(Auu⋅xu⁽ⁿ⁾ + Auk⋅xk⁽ⁿ⁾ - bu⁽ⁿ⁾) + θ⋅(Auk⋅xk⁽ⁿ⁺¹⁾ - bu⁽ⁿ⁺¹⁾)
+//
+//   where bu holds the source and the boundary terms as in SolveSteady (see calcBu)
+//
+func (o *FdmTransientSolver) step(sys *thetaSys, xu la.Vector, t, dt float64) {
+
+	// matrix
+	sys.init(o.Op.Eqs.Auu, o.Op.Eqs.Nu, o.Theta, dt)
+
+	// explicit part
+	θ := o.Theta
+	eqs := o.Op.Eqs
+	for i, I := range eqs.UtoF {
+		o.rhs[i] = xu[i]/dt - (1.0-θ)*o.Op.calcBu(I, t) - θ*o.Op.calcBu(I, t+dt)
+	}
+	if θ < 1 {
+		la.SpMatVecMulAdd(o.rhs, 1.0-θ, o.auu, xu)
+	}
+	if o.auk != nil {
+		for i, I := range eqs.KtoF {
+			o.wk[i] = o.Op.calcXk(I, t)
+			o.xk[i] = o.Op.calcXk(I, t+dt)
+		}
+		if θ < 1 {
+			la.SpMatVecMulAdd(o.rhs, 1.0-θ, o.auk, o.wk)
+		}
+		la.SpMatVecMulAdd(o.rhs, θ, o.auk, o.xk)
+	}
+
+	// stochastic forcing
+	if o.noise {
+		scale := o.amp / math.Sqrt(dt)
+		for i := range o.rhs {
+			o.rhs[i] += scale * o.rng.NormFloat64()
+		}
+	}
+
+	// solve
+	sys.solver.Solve(o.wu, o.rhs, false)
+	copy(xu, o.wu)
+	o.nsol++
+}
+
+// march computes xu := xu @ t+dt with one step or, if the stability limit is enforced (see
+// EnforceStability), with the required number of equal sub-steps. It sets o.Nsub
+func (o *FdmTransientSolver) march(sys *thetaSys, xu la.Vector, t, dt float64) {
+	n := 1
+	if o.stable && o.Theta < 0.5 {
+		n = utl.Imax(1, int(math.Ceil(dt*(1.0-2.0*o.Theta)*o.rho/2.0-1e-12)))
+	}
+	h := dt / float64(n)
+	if o.noise {
+		o.rng.Seed(stepSeed(o.seed, o.Nsteps))
+	}
+	for k := 0; k < n; k++ {
+		o.step(sys, xu, t+float64(k)*h, h)
+	}
+	o.Nsub = n
+}
+
+// accepted counts an accepted step and writes a checkpoint file if needed
+func (o *FdmTransientSolver) accepted() {
+	o.Nsteps++
+	if o.ckEvery < 1 || o.Nsteps%o.ckEvery != 0 {
+		return
+	}
+	var buf bytes.Buffer
+	enc := utl.NewEncoder(&buf, "gob")
+	ck := &fdmCheckpoint{Time: o.Time, Nsteps: o.Nsteps, U: o.U, Noise: o.noise, Amp: o.amp, Seed: o.seed}
+	if err := enc.Encode(ck); err != nil {
+		chk.Panic("cannot encode checkpoint:\n%v\n", err)
+	}
+	io.WriteFileD(o.ckDir, io.Sf("checkpoint_%09d.gob", o.Nsteps), &buf)
+}
+
+// record appends an accepted step to the history
+func (o *FdmTransientSolver) record(dt, err float64) {
+	o.Times = append(o.Times, o.Time)
+	o.DtHist = append(o.DtHist, dt)
+	o.ErrEst = append(o.ErrEst, err)
+	o.Nsolve = append(o.Nsolve, o.nsol)
+	o.nsol = 0
+}
+
+// init assembles and factorises [I/Δt - θ⋅Auu] if dt has changed
+func (o *thetaSys) init(auu *la.Triplet, n int, θ, dt float64) {
+	if o.solver != nil && o.dt == dt {
+		return
+	}
+	o.free()
+	if o.mat == nil {
+		o.mat = la.NewTriplet(n, n, n+auu.Len())
+	}
+	id := new(la.Triplet)
+	la.SpTriSetDiag(id, n, 1)
+	la.SpTriAdd(o.mat, 1.0/dt, id, -θ, auu)
+	o.solver = la.NewSparseSolver("umfpack")
+	o.solver.Init(o.mat, false, false, "", "", nil)
+	o.solver.Fact()
+	o.dt = dt
+}
+
+// free releases the linear solver
+func (o *thetaSys) free() {
+	if o.solver != nil {
+		o.solver.Free()
+		o.solver = nil
+	}
+}
+
+// stepSeed returns the seed of the random numbers of a step; it mixes the seed and the step
+// number (SplitMix64 finaliser) such that the sequences of consecutive steps are uncorrelated
+func stepSeed(seed int64, step int) int64 {
+	z := uint64(seed) + uint64(step+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}